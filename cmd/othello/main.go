@@ -1,13 +1,36 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+	"github.com/chzyer/readline"
 	"github.com/danieleugenewilliams/othello-agent/internal/agent"
 	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/secrets"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/danieleugenewilliams/othello-agent/internal/tui"
 	"github.com/spf13/cobra"
 )
 
@@ -30,7 +53,38 @@ Features:
 - Terminal user interface
 - Conversation history
 - Configuration management`,
-	RunE: runInteractive,
+	PersistentPreRunE: beforeCommand,
+	RunE:              runInteractive,
+}
+
+// beforeCommand selects the config, and serves profiles when --pprof is set
+func beforeCommand(cmd *cobra.Command, args []string) error {
+	if err := selectConfig(cmd, args); err != nil {
+		return err
+	}
+	if addr, _ := cmd.Flags().GetString("pprof"); addr != "" {
+		listening, err := agent.StartProfiling(addr)
+		if err != nil {
+			return &exitError{code: 2, err: err}
+		}
+		fmt.Fprintf(os.Stderr, "Serving profiles at http://%s/debug/pprof/\n", listening)
+	}
+	return nil
+}
+
+// selectConfig makes the file named by --config the config file and the
+// profile named by --profile the active one, for the settings and mcp.json
+// every command reads
+func selectConfig(cmd *cobra.Command, args []string) error {
+	if path, _ := cmd.Flags().GetString("config"); path != "" {
+		if err := os.Setenv(config.ConfigFileEnvVar, path); err != nil {
+			return err
+		}
+	}
+	if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+		return os.Setenv(config.ProfileEnvVar, profile)
+	}
+	return nil
 }
 
 var versionCmd = &cobra.Command{
@@ -52,13 +106,29 @@ var configCmd = &cobra.Command{
 var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Display current configuration",
+	Long: `Display the current configuration.
+
+With --effective, every setting is listed with the source that supplied it
+(default, file, workspace, env, or flag), which helps explain why a setting
+isn't taking effect.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
+		cfg, err := config.LoadWithFlags(cmd.Flags())
 		if err != nil {
 			return fmt.Errorf("failed to load configuration: %w", err)
 		}
 
 		fmt.Printf("Configuration loaded from: %s\n", cfg.ConfigFile())
+		if profileFile := cfg.ProfileFile(); profileFile != "" {
+			fmt.Printf("Profile %s from: %s\n", cfg.Profile(), profileFile)
+		}
+		if workspaceFile := cfg.WorkspaceFile(); workspaceFile != "" {
+			fmt.Printf("Workspace overrides from: %s\n", workspaceFile)
+		}
+
+		if effective, _ := cmd.Flags().GetBool("effective"); effective {
+			return printEffectiveConfig(cfg)
+		}
+
 		fmt.Printf("\nModel Configuration:\n")
 		fmt.Printf("  Type: %s\n", cfg.Model.Type)
 		fmt.Printf("  Name: %s\n", cfg.Model.Name)
@@ -73,6 +143,532 @@ var configShowCmd = &cobra.Command{
 	},
 }
 
+// printEffectiveConfig lists every effective setting with the source that supplied it
+func printEffectiveConfig(cfg *config.Config) error {
+	fmt.Printf("Precedence (lowest to highest): %s\n\n", config.Precedence)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, setting := range cfg.EffectiveSettings() {
+		source := string(setting.Source)
+		if setting.Origin != "" {
+			source = fmt.Sprintf("%s (%s)", setting.Source, setting.Origin)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, secrets.Redact(fmt.Sprint(setting.Value)), source)
+	}
+	return w.Flush()
+}
+
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage configuration profiles",
+	Long: `Manage configuration profiles.
+
+A profile, such as work, personal, or experiments, has its own model, MCP
+servers, and data directory. Its settings are kept in
+~/.othello/profiles/<name>/config.yaml and override ~/.othello/config.yaml
+while it is active. Select a profile for one run with --profile or
+OTHELLO_PROFILE, or make it the default with switch.`,
+}
+
+var configProfilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles, marking the active one",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := config.ListProfiles()
+		if err != nil {
+			return err
+		}
+		active := config.ActiveProfile()
+		for _, name := range profiles {
+			marker := "  "
+			if name == active {
+				marker = "* "
+			}
+			dir, err := config.ProfileDir(name)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s%s\t%s\n", marker, name, dir)
+		}
+		return nil
+	},
+}
+
+var configProfilesCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.CreateProfile(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Created profile '%s': %s\n", args[0], path)
+		fmt.Printf("   Use it with `othello --profile %s`, or make it the default with `othello config profiles switch %s`\n", args[0], args[0])
+		return nil
+	},
+}
+
+var configProfilesSwitchCmd = &cobra.Command{
+	Use:   "switch <name>",
+	Short: "Use a profile by default; switch to default to go back",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.SwitchProfile(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Switched to profile '%s'\n", args[0])
+		if env := os.Getenv(config.ProfileEnvVar); env != "" && env != args[0] {
+			fmt.Printf("   %s=%s still selects '%s' in this shell\n", config.ProfileEnvVar, env, env)
+		}
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Long: `Print the effective value of a setting, such as ollama.host, from whichever
+source supplied it. Given a section, such as ollama, every setting in it is
+printed with its key.
+
+Examples:
+  othello config get ollama.host
+  othello config get model`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		settings, err := cfg.Lookup(args[0])
+		if err != nil {
+			return err
+		}
+		if len(settings) == 1 && settings[0].Key == strings.ToLower(args[0]) {
+			fmt.Println(secrets.Redact(fmt.Sprint(settings[0].Value)))
+			return nil
+		}
+		for _, setting := range settings {
+			fmt.Printf("%s: %s\n", setting.Key, secrets.Redact(fmt.Sprint(setting.Value)))
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting in the config file",
+	Long: `Change a setting in the config file, or in the active profile's config
+file when a profile is selected. The value is read as YAML, so numbers,
+booleans, durations, and lists such as [a, b] keep their types. Comments and
+the other settings in the file are kept.
+
+The file is only changed when the configuration is still valid with the new
+value; otherwise the error is printed and nothing is written.
+
+Examples:
+  othello config set model.name llama3.1:8b
+  othello config set model.temperature 0.3
+  othello config set tools.fetch.allowed_domains "[docs.python.org, '*.go.dev']"`,
+	Args:          cobra.ExactArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		path, err := cfg.SetValue(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Set %s in %s\n", strings.ToLower(args[0]), path)
+
+		// Say so when a higher-precedence source still wins
+		if updated, err := cfg.Reload(); err == nil {
+			source := updated.SourceOf(strings.ToLower(args[0]))
+			if source == config.SourceWorkspace || source == config.SourceEnv || source == config.SourceFlag {
+				fmt.Printf("   It is overridden by %s; see `othello config show --effective`\n", source)
+			}
+		}
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in your editor",
+	Long: `Open the config file, or the active profile's config file, in $VISUAL or
+$EDITOR (vi when neither is set). When you save and quit, the changes are
+checked; if the configuration isn't valid, the error is shown and you can
+edit again or discard the changes. The config file is only written once it
+is valid.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		path, err := cfg.EditableFile()
+		if err != nil {
+			return err
+		}
+		return editConfigFile(cfg, path)
+	},
+}
+
+// editConfigFile opens a copy of the config file at path in the user's
+// editor until the edited configuration is valid or the user gives up, and
+// then writes it to path
+func editConfigFile(cfg *config.Config, path string) error {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return editDraft(original, "othello-config-*.yaml", path, func(edited []byte) error {
+		return cfg.ReplaceFile(path, edited)
+	})
+}
+
+// editDraft opens a copy of original, named after pattern, in the user's
+// editor until save accepts the edited text or the user gives up. target
+// names what save writes.
+func editDraft(original []byte, pattern, target string, save func([]byte) error) error {
+	draft, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return fmt.Errorf("failed to create draft: %w", err)
+	}
+	defer os.Remove(draft.Name())
+	if _, err := draft.Write(original); err != nil {
+		draft.Close()
+		return fmt.Errorf("failed to write draft: %w", err)
+	}
+	draft.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	input := bufio.NewReader(os.Stdin)
+	for {
+		// The editor may be given with arguments, such as "code --wait"
+		parts := strings.Fields(editor)
+		editorCmd := exec.Command(parts[0], append(parts[1:], draft.Name())...)
+		editorCmd.Stdin, editorCmd.Stdout, editorCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := editorCmd.Run(); err != nil {
+			return fmt.Errorf("editor %s failed: %w", editor, err)
+		}
+
+		edited, err := os.ReadFile(draft.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read draft: %w", err)
+		}
+		if bytes.Equal(edited, original) {
+			fmt.Println("No changes made.")
+			return nil
+		}
+		err = save(edited)
+		if err == nil {
+			fmt.Printf("✅ Saved %s\n", target)
+			return nil
+		}
+
+		fmt.Printf("❌ The configuration isn't valid: %v\n", err)
+		fmt.Print("Edit again? [Y/n] ")
+		answer, _ := input.ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "n" || answer == "no" {
+			return fmt.Errorf("changes discarded; %s is unchanged", target)
+		}
+	}
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the config file",
+	Long: `Print the JSON Schema describing config files, for editors to offer
+completion and check settings as you type. Config files created by Othello
+already point editors that use yaml-language-server, such as VS Code with the
+YAML extension, at the published schema.
+
+Examples:
+  othello config schema > ~/.othello/config.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := config.ConfigSchemaJSON()
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(schema)
+		return err
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration files without starting anything",
+	Long: `Load the configuration and mcp.json of the active profile and check them:
+settings are valid, timeouts are sensible, and every MCP server has what it
+needs to start. Nothing is started and no network is used, so validate is
+suitable for CI. Use doctor to also check Ollama and the server commands.
+
+Exit status is 0 when the configuration is valid (warnings are allowed), 1
+when a check failed, and 2 when the configuration couldn't be loaded.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return &exitError{code: 2, err: fmt.Errorf("failed to load configuration: %w", err)}
+		}
+		return reportChecks(cmd, agent.ValidateConfig(cfg))
+	},
+}
+
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration and the services it points to",
+	Long: `Run the checks of validate, then check that Ollama answers at ollama.host
+and has model.name, and that the command of every MCP server is installed.
+Each problem is printed with what to do about it.
+
+Exit status is 0 when every check passed (warnings are allowed), 1 when a
+check failed, and 2 when the configuration couldn't be loaded.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return &exitError{code: 2, err: fmt.Errorf("failed to load configuration: %w", err)}
+		}
+		return reportChecks(cmd, agent.DiagnoseConfig(cmd.Context(), cfg))
+	},
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show the log file",
+	Long: `Show the last entries of the log file that logging.file names, by default
+~/.othello/logs/othello.log. With --follow, new entries are shown as they are
+written until Ctrl+C.
+
+--level shows entries at that level and above: debug, info, warn, or error.
+--component shows one component's entries, such as agent or mcp.
+
+Examples:
+  othello logs
+
+  # Follow MCP errors while reproducing a problem
+  othello logs -f --level error --component mcp
+
+  # Where the log file is
+  othello logs --path`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter := agent.LogFilter{}
+		filter.Level, _ = cmd.Flags().GetString("level")
+		filter.Component, _ = cmd.Flags().GetString("component")
+		if err := filter.Validate(); err != nil {
+			return &exitError{code: 2, err: err}
+		}
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		path, err := agent.LogFilePath(cfg.Logging.File)
+		if err != nil {
+			return err
+		}
+		if showPath, _ := cmd.Flags().GetBool("path"); showPath {
+			fmt.Println(path)
+			return nil
+		}
+
+		lines, _ := cmd.Flags().GetInt("lines")
+		follow, _ := cmd.Flags().GetBool("follow")
+		ctx, stop := signalContext(os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return agent.TailLog(ctx, path, lines, follow, filter, func(entry agent.LogEntry) {
+			fmt.Println(entry.Text)
+		})
+	},
+}
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Commands for reporting problems",
+}
+
+var debugBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Collect what a bug report needs into a zip file",
+	Long: `Collect what helps look into a problem into one zip file to attach to a
+bug report:
+
+- The report of "othello doctor": versions, checks, and whether each MCP server starts
+- The configuration, with server environments, headers, and URL credentials redacted
+- The latest entries of the log file, and the latest crash reports
+- With --turns, the latest turns of your conversations
+
+Conversations are only included when you ask for them with --turns. Paths in
+the bundle show your home directory as "~". Look through the files before
+sharing them.
+
+Examples:
+  othello debug bundle
+
+  # Include the last 5 conversation turns, and write the bundle to report.zip
+  othello debug bundle --turns 5 -o report.zip`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := agent.DebugBundleOptions{Version: version}
+		opts.LogLines, _ = cmd.Flags().GetInt("log-lines")
+		opts.Turns, _ = cmd.Flags().GetInt("turns")
+		if opts.LogLines < 0 || opts.Turns < 0 {
+			return &exitError{code: 2, err: fmt.Errorf("--log-lines and --turns can't be negative")}
+		}
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			output = "othello-debug-" + time.Now().Format("20060102-150405") + ".zip"
+		}
+		file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create bundle: %w", err)
+		}
+		fmt.Println("Collecting debug information...")
+		names, err := agent.WriteDebugBundle(cmd.Context(), file, cfg, opts)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(output)
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+
+		fmt.Printf("✅ Wrote debug bundle to %s\n", output)
+		for _, name := range names {
+			fmt.Printf("  %s\n", name)
+		}
+		return nil
+	},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the whole environment Othello runs in",
+	Long: `Check everything Othello depends on and print a report to share when
+asking for help:
+
+- The Othello and Go versions, and the OS
+- The configuration, as "othello config doctor" checks it
+- That Ollama runs, and the models it has
+- That every enabled MCP server starts, or answers at its URL, and lists its tools
+- The integrity of the history database
+- The free space in storage.data_dir
+
+Paths in the report show your home directory as "~".
+
+Exit status is 0 when every check passed (warnings are allowed), and 1 when a
+check failed or the configuration couldn't be loaded.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		report := agent.Doctor(cmd.Context(), version, cfg, err)
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			system := report.System
+			fmt.Println("Othello doctor report")
+			fmt.Printf("  othello   %s (commit %s, built %s)\n", system.Version, commit, date)
+			fmt.Printf("  go        %s %s/%s, %d CPUs\n", system.GoVersion, system.OS, system.Arch, system.CPUs)
+			if system.Profile != "" {
+				configFile := system.ConfigFile
+				if configFile == "" {
+					configFile = "none, using the defaults"
+				}
+				fmt.Printf("  config    %s (profile %s)\n", configFile, system.Profile)
+				fmt.Printf("  data dir  %s\n", system.DataDir)
+			}
+			fmt.Println()
+			printChecks(report.Checks)
+		}
+		if agent.ChecksFailed(report.Checks) {
+			return &exitError{code: 1, err: fmt.Errorf("environment check failed")}
+		}
+		return nil
+	},
+}
+
+// checkSymbols mark each check's outcome in the text output
+var checkSymbols = map[agent.CheckStatus]string{
+	agent.CheckPassed:  "✓",
+	agent.CheckWarning: "!",
+	agent.CheckFailed:  "✗",
+}
+
+// reportChecks prints checks, as text or with --json as one JSON object per
+// check, and returns an exit status of 1 when any failed
+func reportChecks(cmd *cobra.Command, checks []agent.ConfigCheck) error {
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, check := range checks {
+			if err := encoder.Encode(check); err != nil {
+				return err
+			}
+		}
+	} else {
+		printChecks(checks)
+	}
+	if agent.ChecksFailed(checks) {
+		return &exitError{code: 1, err: fmt.Errorf("configuration check failed")}
+	}
+	return nil
+}
+
+// printChecks prints each of checks with what to do about it, and a summary
+func printChecks(checks []agent.ConfigCheck) {
+	failed, warned := 0, 0
+	for _, check := range checks {
+		fmt.Printf("%s %s: %s\n", checkSymbols[check.Status], check.Name, check.Detail)
+		if check.Fix != "" {
+			fmt.Printf("    %s\n", check.Fix)
+		}
+		switch check.Status {
+		case agent.CheckFailed:
+			failed++
+		case agent.CheckWarning:
+			warned++
+		}
+	}
+	fmt.Printf("\n%d checks, %d failed, %d warnings\n", len(checks), failed, warned)
+}
+
 var configInitCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Create default configuration file",
@@ -84,13 +680,15 @@ var configInitCmd = &cobra.Command{
 var mcpCmd = &cobra.Command{
 	Use:   "mcp",
 	Short: "MCP server management commands",
-	Long:  "Manage Model Context Protocol (MCP) servers - add, remove, list, and view server configurations",
+	Long:  "Manage Model Context Protocol (MCP) servers - add, remove, update, enable, disable, list, and view server configurations",
 }
 
 var mcpAddCmd = &cobra.Command{
-	Use:   "add <name> <command> [args...]",
+	Use:   "add <name> (<command> [args...] | --url <url>)",
 	Short: "Add a new MCP server",
-	Long: `Add a new MCP server to mcp.json configuration.
+	Long: `Add a new MCP server to mcp.json configuration. Local servers are started
+with a command and talk over stdio; remote servers are reached over HTTP at
+--url, with any --header sent on every request.
 
 Examples:
   # Add filesystem server
@@ -100,20 +698,34 @@ Examples:
   othello mcp add memory npx @danieleugenewilliams/local-memory-server
 
   # Add custom server with environment variables
-  othello mcp add custom /usr/bin/python3 -m myserver --port 8080`,
-	Args: cobra.MinimumNArgs(2),
+  othello mcp add custom /usr/bin/python3 -m myserver --port 8080
+
+  # Add a remote HTTP server with an API key from the keychain
+  othello mcp add search --url https://mcp.example.com/mcp --header Authorization='Bearer ${secret:search-key}'`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if url, _ := cmd.Flags().GetString("url"); url != "" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(2)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
-		command := args[1]
-		serverArgs := args[2:]
 
 		// Get environment variables flag
 		envVars, _ := cmd.Flags().GetStringToString("env")
+		url, _ := cmd.Flags().GetString("url")
+		headers, _ := cmd.Flags().GetStringToString("header")
 
-		server := config.MCPServerConfig{
-			Command: command,
-			Args:    serverArgs,
-			Env:     envVars,
+		server := config.MCPServerConfig{Env: envVars}
+		if url != "" {
+			server.URL = url
+			server.Headers = headers
+		} else {
+			if len(headers) > 0 {
+				return fmt.Errorf("--header only applies to HTTP servers added with --url")
+			}
+			server.Command = args[1]
+			server.Args = args[2:]
 		}
 
 		if err := config.AddMCPServer(name, server); err != nil {
@@ -121,7 +733,14 @@ Examples:
 		}
 
 		fmt.Printf("✅ Successfully added MCP server '%s' to mcp.json\n", name)
-		fmt.Printf("   Command: %s %s\n", command, strings.Join(serverArgs, " "))
+		if url != "" {
+			fmt.Printf("   URL: %s\n", url)
+		} else {
+			fmt.Printf("   Command: %s %s\n", server.Command, strings.Join(server.Args, " "))
+		}
+		if len(headers) > 0 {
+			fmt.Printf("   Headers: %d\n", len(headers))
+		}
 		if len(envVars) > 0 {
 			fmt.Printf("   Environment variables: %d\n", len(envVars))
 		}
@@ -156,29 +775,43 @@ var mcpListCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to list MCP servers: %w", err)
 		}
+		path, err := config.MCPConfigPath()
+		if err != nil {
+			return err
+		}
 		
 		if len(servers) == 0 {
 			fmt.Println("No MCP servers configured.")
 			fmt.Println("\nTo add a server, use:")
 			fmt.Println("  othello mcp add <name> <command> [args...]")
-			fmt.Println("\nConfiguration will be stored in ~/.othello/mcp.json")
+			fmt.Printf("\nConfiguration will be stored in %s\n", path)
 			return nil
 		}
 
-		fmt.Printf("Configured MCP Servers (%d) in ~/.othello/mcp.json:\n\n", len(servers))
+		fmt.Printf("Configured MCP Servers (%d) in %s:\n\n", len(servers), path)
 		
 		i := 1
 		for name, server := range servers {
-			fmt.Printf("%d. %s\n", i, name)
-			fmt.Printf("   Command: %s", server.Command)
-			if len(server.Args) > 0 {
-				fmt.Printf(" %s", strings.Join(server.Args, " "))
+			fmt.Printf("%d. %s%s\n", i, name, disabledLabel(server.Enabled))
+			if server.URL != "" {
+				fmt.Printf("   URL: %s\n", server.URL)
+			} else {
+				fmt.Printf("   Command: %s", server.Command)
+				if len(server.Args) > 0 {
+					fmt.Printf(" %s", strings.Join(server.Args, " "))
+				}
+				fmt.Printf("\n")
+			}
+			if len(server.Headers) > 0 {
+				fmt.Printf("   Headers:\n")
+				for k, v := range server.Headers {
+					fmt.Printf("     %s: %s\n", k, config.MaskedValue(v))
+				}
 			}
-			fmt.Printf("\n")
 			if len(server.Env) > 0 {
 				fmt.Printf("   Environment:\n")
 				for k, v := range server.Env {
-					fmt.Printf("     %s=%s\n", k, v)
+					fmt.Printf("     %s=%s\n", k, config.MaskedValue(v))
 				}
 			}
 			if i < len(servers) {
@@ -209,46 +842,2107 @@ var mcpShowCmd = &cobra.Command{
 			return fmt.Errorf("server with name '%s' not found", name)
 		}
 
-		fmt.Printf("MCP Server: %s\n\n", name)
-		fmt.Printf("Command: %s", server.Command)
-		if len(server.Args) > 0 {
-			fmt.Printf(" %s", strings.Join(server.Args, " "))
+		fmt.Printf("MCP Server: %s%s%s\n\n", name, disabledLabel(server.Enabled), trustLabel(cmd, name))
+		if server.URL != "" {
+			fmt.Printf("URL: %s\n", server.URL)
+		} else {
+			fmt.Printf("Command: %s", server.Command)
+			if len(server.Args) > 0 {
+				fmt.Printf(" %s", strings.Join(server.Args, " "))
+			}
+			fmt.Printf("\n")
+		}
+		if server.Transport != "" {
+			fmt.Printf("Transport: %s\n", server.Transport)
+		}
+		if server.Timeout != "" {
+			fmt.Printf("Timeout: %s\n", server.Timeout)
 		}
-		fmt.Printf("\n")
 		
+		if len(server.Headers) > 0 {
+			fmt.Printf("Headers:\n")
+			for k, v := range server.Headers {
+				fmt.Printf("  %s: %s\n", k, config.MaskedValue(v))
+			}
+		}
 		if len(server.Env) > 0 {
 			fmt.Printf("Environment Variables:\n")
 			for k, v := range server.Env {
-				fmt.Printf("  %s=%s\n", k, v)
+				fmt.Printf("  %s=%s\n", k, config.MaskedValue(v))
 			}
 		}
 		
-		fmt.Printf("\nConfiguration file: ~/.othello/mcp.json\n")
+		if path, err := config.MCPConfigPath(); err == nil {
+			fmt.Printf("\nConfiguration file: %s\n", path)
+		}
 		
 		return nil
 	},
 }
 
-func init() {
-	rootCmd.AddCommand(versionCmd)
-	rootCmd.AddCommand(configCmd)
-	configCmd.AddCommand(configShowCmd)
-	configCmd.AddCommand(configInitCmd)
-	
-	// Add MCP command and subcommands
-	rootCmd.AddCommand(mcpCmd)
-	mcpCmd.AddCommand(mcpAddCmd)
-	mcpCmd.AddCommand(mcpRemoveCmd)
-	mcpCmd.AddCommand(mcpListCmd)
-	mcpCmd.AddCommand(mcpShowCmd)
-	
-	// Add flags for mcp add command (simplified for standard MCP format)
-	mcpAddCmd.Flags().StringToStringP("env", "e", nil, "Environment variables (key=value)")
+var mcpEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Edit an MCP server's configuration in your editor",
+	Long: `Open the mcp.json entry of an MCP server as JSON in $VISUAL or $EDITOR (vi
+when neither is set). When you save and quit, the changes are checked; if
+they aren't valid, the error is shown and you can edit again or discard them.
+
+To change a server without an editor, use "othello mcp update".`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		server, err := findMCPServer(name)
+		if err != nil {
+			return err
+		}
+		original, err := json.MarshalIndent(server, "", "  ")
+		if err != nil {
+			return err
+		}
+		target := fmt.Sprintf("server '%s'", name)
+		return editDraft(append(original, '\n'), "othello-mcp-*.json", target, func(edited []byte) error {
+			var updated config.MCPServerConfig
+			decoder := json.NewDecoder(bytes.NewReader(edited))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&updated); err != nil {
+				return fmt.Errorf("invalid JSON: %w", err)
+			}
+			return config.UpdateMCPServer(name, updated)
+		})
+	},
 }
 
-func main() {
-	if err := rootCmd.Execute(); err != nil {
+var mcpUpdateCmd = &cobra.Command{
+	Use:   "update <name>",
+	Short: "Change an MCP server's configuration",
+	Long: `Change fields of an MCP server in mcp.json without removing and adding it
+again. Fields that aren't given keep their values. Environment variables apply
+to servers started with a command; the URL and headers to HTTP servers.
+
+--json applies a JSON merge patch after the other flags: its fields replace
+the server's, and fields set to null are removed.
+
+Examples:
+  # Replace the arguments
+  othello mcp update filesystem --arg @modelcontextprotocol/server-filesystem --arg ~/notes
+
+  # Add an environment variable and remove another
+  othello mcp update github --env GITHUB_TOKEN='${secret:github-token}' --unset-env DEBUG
+
+  # Give a slow server more time
+  othello mcp update memory --timeout 2m
+
+  # Rotate the API key of an HTTP server
+  othello mcp update search --header Authorization='Bearer ${secret:search-key-2}'
+
+  # Patch fields non-interactively
+  othello mcp update memory --json '{"timeout": "1m", "env": {"DEBUG": null}}'`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		server, err := findMCPServer(name)
+		if err != nil {
+			return err
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("command") {
+			server.Command, _ = flags.GetString("command")
+		}
+		if flags.Changed("arg") {
+			server.Args, _ = flags.GetStringArray("arg")
+		}
+		if flags.Changed("env") {
+			envVars, _ := flags.GetStringToString("env")
+			if server.Env == nil {
+				server.Env = make(map[string]string, len(envVars))
+			}
+			maps.Copy(server.Env, envVars)
+		}
+		if flags.Changed("unset-env") {
+			unset, _ := flags.GetStringSlice("unset-env")
+			for _, key := range unset {
+				delete(server.Env, key)
+			}
+		}
+		if flags.Changed("url") {
+			server.URL, _ = flags.GetString("url")
+		}
+		if flags.Changed("header") {
+			headers, _ := flags.GetStringToString("header")
+			if server.Headers == nil {
+				server.Headers = make(map[string]string, len(headers))
+			}
+			maps.Copy(server.Headers, headers)
+		}
+		if flags.Changed("unset-header") {
+			unset, _ := flags.GetStringSlice("unset-header")
+			for _, key := range unset {
+				delete(server.Headers, key)
+			}
+		}
+		if flags.Changed("transport") {
+			server.Transport, _ = flags.GetString("transport")
+		}
+		if flags.Changed("timeout") {
+			timeout, _ := flags.GetDuration("timeout")
+			server.Timeout = timeout.String()
+		}
+		if flags.Changed("json") {
+			patch, _ := flags.GetString("json")
+			if server, err = config.PatchMCPServer(server, []byte(patch)); err != nil {
+				return err
+			}
+		}
+
+		if err := config.UpdateMCPServer(name, server); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Updated MCP server '%s' in mcp.json\n", name)
+		return nil
+	},
+}
+
+// findMCPServer returns the server name from mcp.json
+func findMCPServer(name string) (config.MCPServerConfig, error) {
+	servers, err := config.ListMCPServers()
+	if err != nil {
+		return config.MCPServerConfig{}, fmt.Errorf("failed to load MCP servers: %w", err)
+	}
+	server, exists := servers[name]
+	if !exists {
+		return config.MCPServerConfig{}, fmt.Errorf("server with name '%s' not found in mcp.json", name)
+	}
+	return server, nil
+}
+
+var mcpEnableCmd = &cobra.Command{
+	Use:   "enable <name>",
+	Short: "Switch a disabled MCP server back on",
+	Long: `Switch an MCP server that was disabled with "othello mcp disable" back on.
+The server is looked up in mcp.json, then in the mcp.servers list of the
+config files.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setServerEnabled(cmd, args[0], true)
+	},
+}
+
+var mcpDisableCmd = &cobra.Command{
+	Use:   "disable <name>",
+	Short: "Switch an MCP server off without removing it",
+	Long: `Switch an MCP server off, keeping its configuration, by setting
+enabled: false. The agent doesn't start disabled servers; turn the server back
+on with "othello mcp enable".
+
+The server is looked up in mcp.json, then in the mcp.servers list of the
+config files.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setServerEnabled(cmd, args[0], false)
+	},
+}
+
+// setServerEnabled switches the MCP server name on or off in the file that
+// configures it
+func setServerEnabled(cmd *cobra.Command, name string, enabled bool) error {
+	action := "Disabled"
+	if enabled {
+		action = "Enabled"
+	}
+
+	servers, err := config.ListMCPServers()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP servers: %w", err)
+	}
+	if _, exists := servers[name]; exists {
+		if err := config.SetMCPServerEnabled(name, enabled); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s MCP server '%s' in mcp.json\n", action, name)
+		return nil
+	}
+
+	cfg, err := config.LoadWithFlags(cmd.Flags())
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	path, err := cfg.SetServerEnabled(name, enabled)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ %s MCP server '%s' in %s\n", action, name, path)
+	return nil
+}
+
+var mcpTrustCmd = &cobra.Command{
+	Use:   "trust <name>",
+	Short: "Show or set how far the agent trusts a server's tools",
+	Long: `Show or set the trust given to a server, which the agent remembers in
+permissions.json in the data directory. It applies to the server's tools in
+the chat, "othello ask", workflows, and the HTTP API, and a running agent
+picks up changes before its next tool call.
+
+Levels:
+  blocked    No tool of the server may be called
+  read-only  Tools that only read run without approval; tools that delete
+             or change data are refused
+  ask        Every call waits for your approval in the chat
+  trusted    Every call runs without approval
+  default    Forget the decision, so agent.plan_approval decides again
+
+Built-in servers, such as othello-memory, can be given trust too.
+
+Examples:
+  othello mcp trust filesystem --level read-only
+  othello mcp trust community-tools --level blocked
+  othello mcp trust filesystem`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		path, err := permissionsPath(cmd)
+		if err != nil {
+			return err
+		}
+
+		levelName, _ := cmd.Flags().GetString("level")
+		if levelName == "" {
+			permissions, err := storage.LoadPermissions(path)
+			if err != nil {
+				return err
+			}
+			if level, ok := permissions.Trust(name); ok {
+				fmt.Printf("%s: %s (set %s)\n", name, level, permissions.Servers[name].Updated.Local().Format("2006-01-02 15:04"))
+			} else {
+				fmt.Printf("%s: no trust set; agent.plan_approval decides\n", name)
+			}
+			return nil
+		}
+
+		var level storage.TrustLevel
+		if levelName != "default" {
+			if level, err = storage.ParseTrustLevel(levelName); err != nil {
+				return &exitError{code: 2, err: err}
+			}
+		}
+		if err := storage.SetServerTrust(path, name, level); err != nil {
+			return err
+		}
+		if level == "" {
+			fmt.Printf("✅ Cleared the trust given to '%s'\n", name)
+		} else {
+			fmt.Printf("✅ Trusting '%s' as %s in %s\n", name, level, path)
+		}
+		return nil
+	},
+}
+
+// permissionsPath returns the permissions file in the data directory of the
+// configuration
+func permissionsPath(cmd *cobra.Command) (string, error) {
+	cfg, err := config.LoadWithFlags(cmd.Flags())
+	if err != nil {
+		return "", fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return storage.PermissionsPath(cfg.Storage.DataDir)
+}
+
+// trustLabel returns " (trust: level)" for a server given trust in the
+// permissions file, or "" when it was given none or the file can't be read
+func trustLabel(cmd *cobra.Command, name string) string {
+	path, err := permissionsPath(cmd)
+	if err != nil {
+		return ""
+	}
+	permissions, err := storage.LoadPermissions(path)
+	if err != nil {
+		return ""
+	}
+	if level, ok := permissions.Trust(name); ok {
+		return fmt.Sprintf(" (trust: %s)", level)
+	}
+	return ""
+}
+
+// disabledLabel returns " (disabled)" for a server switched off with enabled
+func disabledLabel(enabled *bool) string {
+	if enabled != nil && !*enabled {
+		return " (disabled)"
+	}
+	return ""
+}
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Secret management commands",
+	Long: `Store API keys, tokens, and other secrets in the OS keychain: the macOS
+Keychain, the Windows Credential Manager, or the Secret Service (libsecret) on
+Linux. Config values, such as a server's environment variables, refer to a
+stored secret as ${secret:name}.`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Store a secret in the OS keychain",
+	Long: `Store a secret in the OS keychain, replacing any earlier value. The value is
+prompted for without echoing it, or read from standard input when it is piped,
+so it doesn't end up in the shell history.
+
+Examples:
+  othello secret set github-token
+  pass show github/token | othello secret set github-token
+
+Then refer to it in the config:
+  env:
+    GITHUB_TOKEN: "${secret:github-token}"`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := secrets.ValidateName(name); err != nil {
+			return err
+		}
+
+		value, err := readPipedInput(true)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			fmt.Printf("Value for %s: ", name)
+			data, err := term.ReadPassword(os.Stdin.Fd())
+			fmt.Println()
+			if err != nil {
+				return fmt.Errorf("failed to read the value: %w", err)
+			}
+			value = string(data)
+		}
+		value = strings.TrimRight(value, "\r\n")
+		if value == "" {
+			return fmt.Errorf("no value given for %s", name)
+		}
+
+		if err := secrets.NewKeychain().Set(name, value); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Stored secret '%s'; refer to it as ${secret:%s}\n", name, name)
+		return nil
+	},
+}
+
+var secretDeleteCmd = &cobra.Command{
+	Use:           "delete <name>",
+	Short:         "Remove a secret from the OS keychain",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := secrets.NewKeychain().Delete(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Deleted secret '%s'\n", args[0])
+		return nil
+	},
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Conversation history commands",
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved conversations",
+	Long: `List saved conversations, most recently updated first, with their IDs for
+the other history commands.
+
+Examples:
+  othello history list
+
+  # Every conversation as JSON, for scripts
+  othello history list --limit 0 --json`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		store, err := openHistoryStore(cfg, false)
+		if err != nil || store == nil {
+			return err
+		}
+		defer store.Close()
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit <= 0 {
+			limit = -1 // SQLite reads a negative limit as none
+		}
+		conversations, err := store.ListConversations(limit, 0)
+		if err != nil {
+			return fmt.Errorf("failed to list conversations: %w", err)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			for _, conv := range conversations {
+				if err := encoder.Encode(conv); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if len(conversations) == 0 {
+			fmt.Println("No conversations saved.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tUPDATED\tMESSAGES\tTITLE")
+		for _, conv := range conversations {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", conv.ID, conv.UpdatedAt.Local().Format("2006-01-02 15:04"),
+				conv.MessageCount, strings.Join(strings.Fields(conv.Title), " "))
+		}
+		return w.Flush()
+	},
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <conversation-id>",
+	Short: "Print a saved conversation",
+	Long: `Print a saved conversation with its tool calls and results. A branch is
+printed with the messages it continues from.
+
+Examples:
+  othello history show conv_1712345678
+
+  # As JSON, or as an HTML page
+  othello history show conv_1712345678 --format json
+  othello history show conv_1712345678 --format html > conversation.html`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("format")
+		format, err := storage.ParseExportFormat(name)
+		if err != nil {
+			return &exitError{code: 2, err: err}
+		}
+		exporter, err := storage.NewExporter(format)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		store, err := openHistoryStore(cfg, false)
+		if err != nil || store == nil {
+			return err
+		}
+		defer store.Close()
+
+		conv, err := findConversation(store, args[0])
+		if err != nil {
+			return err
+		}
+		thread, err := store.GetThread(conv.ID)
+		if err != nil {
+			return fmt.Errorf("failed to read conversation: %w", err)
+		}
+		return exporter.Export(os.Stdout, conv, thread)
+	},
+}
+
+var historyDeleteCmd = &cobra.Command{
+	Use:   "delete <conversation-id>...",
+	Short: "Delete saved conversations",
+	Long: `Delete saved conversations with their messages and attachments. Branches
+of a deleted conversation are kept, with their own messages only.
+
+Examples:
+  othello history delete conv_1712345678
+
+  # Delete every conversation with "scratch" in its title
+  othello history list --limit 0 --json | jq -r 'select(.title | test("scratch")) | .id' | xargs othello history delete`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		store, err := openHistoryStore(cfg, false)
+		if err != nil || store == nil {
+			return err
+		}
+		defer store.Close()
+
+		// Check them all first so a typo deletes nothing
+		for _, id := range args {
+			if _, err := findConversation(store, id); err != nil {
+				return err
+			}
+		}
+		for _, id := range args {
+			if err := store.DeleteConversation(id); err != nil {
+				return fmt.Errorf("failed to delete conversation %s: %w", id, err)
+			}
+			fmt.Printf("✅ Deleted conversation %s\n", id)
+		}
+		return nil
+	},
+}
+
+var historyRenameCmd = &cobra.Command{
+	Use:   "rename <conversation-id> <title>",
+	Short: "Rename a saved conversation",
+	Long: `Set the title a saved conversation is listed under.
+
+Example:
+  othello history rename conv_1712345678 "Backup schedule"`,
+	Args:          cobra.ExactArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		title := strings.TrimSpace(args[1])
+		if title == "" {
+			return &exitError{code: 2, err: errors.New("title can't be empty")}
+		}
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		store, err := openHistoryStore(cfg, false)
+		if err != nil || store == nil {
+			return err
+		}
+		defer store.Close()
+
+		conv, err := findConversation(store, args[0])
+		if err != nil {
+			return err
+		}
+		if err := store.UpdateConversationTitle(conv.ID, title); err != nil {
+			return fmt.Errorf("failed to rename conversation: %w", err)
+		}
+		fmt.Printf("✅ Renamed conversation %s to %q\n", conv.ID, title)
+		return nil
+	},
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete conversations outside the retention limits",
+	Long: `Delete saved conversations that fall outside the retention limits in
+storage.retention, then vacuum the database to give the freed space back.
+
+Flags override the configured limits for this run; 0 removes a limit.
+Conversations that a kept branch continues from are always kept.
+
+Examples:
+  # Apply the configured limits
+  othello history prune
+
+  # Keep only the 100 most recent conversations
+  othello history prune --max-conversations 100
+
+  # Delete conversations untouched for 90 days
+  othello history prune --max-age 2160h`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		retention := cfg.Storage.Retention
+		if cmd.Flags().Changed("max-conversations") {
+			retention.MaxConversations, _ = cmd.Flags().GetInt("max-conversations")
+		}
+		if cmd.Flags().Changed("max-age") {
+			retention.MaxAge, _ = cmd.Flags().GetDuration("max-age")
+		}
+		if cmd.Flags().Changed("max-size-mb") {
+			retention.MaxSizeMB, _ = cmd.Flags().GetInt("max-size-mb")
+		}
+		policy := agent.RetentionPolicy(retention)
+		if policy == (storage.RetentionPolicy{}) {
+			fmt.Println("No retention limits are set; nothing to prune.")
+			fmt.Println("Set storage.retention in config.yaml or pass --max-conversations, --max-age, or --max-size-mb.")
+			return nil
+		}
+
+		store, err := openHistoryStore(cfg, false)
+		if err != nil || store == nil {
+			return err
+		}
+		defer store.Close()
+
+		result, err := store.Prune(policy)
+		if err != nil {
+			return fmt.Errorf("failed to prune history: %w", err)
+		}
+		fmt.Printf("Deleted %d conversations (%d messages)\n", result.Conversations, result.Messages)
+
+		if noVacuum, _ := cmd.Flags().GetBool("no-vacuum"); !noVacuum {
+			free, err := store.FreeSize()
+			if err != nil {
+				return err
+			}
+			if free > 0 {
+				if err := store.Vacuum(); err != nil {
+					return err
+				}
+				fmt.Printf("Vacuumed the database, reclaiming %.1f MB\n", float64(free)/(1<<20))
+			}
+		}
+		fmt.Printf("History now uses %.1f MB\n", float64(result.Size)/(1<<20))
+		return nil
+	},
+}
+
+var historyExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export every saved conversation",
+	Long: `Export every saved conversation, oldest first, to back it up, move it to
+another machine, or feed it to other tools. Tool calls and their results are
+included.
+
+JSONL writes one conversation per line with its messages and can be read back
+with "othello history import". Markdown writes a readable transcript of each
+conversation.
+
+Examples:
+  # Back up to a file
+  othello history export --output othello-history.jsonl
+
+  # Print Markdown transcripts
+  othello history export --format markdown`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("format")
+		format, err := storage.ParseArchiveFormat(name)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		store, err := openHistoryStore(cfg, false)
+		if err != nil || store == nil {
+			return err
+		}
+		defer store.Close()
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" || output == "-" {
+			_, err := store.ExportArchive(os.Stdout, format)
+			return err
+		}
+
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output, err)
+		}
+		count, err := store.ExportArchive(file, format)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to export history: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d conversations to %s\n", count, output)
+		return nil
+	},
+}
+
+var historyImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import conversations from a JSONL export",
+	Long: `Import the conversations in a JSONL file written by "othello history export".
+Use - to read from standard input. Conversations that are already saved are
+skipped, so importing the same file twice is harmless.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		input := os.Stdin
+		if args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", args[0], err)
+			}
+			defer file.Close()
+			input = file
+		}
+
+		store, err := openHistoryStore(cfg, true)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		result, err := store.ImportArchive(input)
+		if err != nil {
+			return fmt.Errorf("failed to import history: %w", err)
+		}
+		fmt.Printf("Imported %d conversations (%d messages)", result.Conversations, result.Messages)
+		if result.Skipped > 0 {
+			fmt.Printf(", skipped %d already saved", result.Skipped)
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "MCP tool commands",
+}
+
+var toolsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tools of the configured MCP servers",
+	Long: `Start the configured MCP servers and list their tools, grouped by server,
+with the built-in tools alongside them.
+
+Examples:
+  othello tools list
+
+  # Only one server's tools, as JSON
+  othello tools list --server filesystem --json`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		agentInstance, stop, err := startToolsAgent(cmd)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		server, _ := cmd.Flags().GetString("server")
+		var tools []mcp.Tool
+		for _, tool := range agentInstance.Tools() {
+			if server == "" || tool.ServerName == server {
+				tools = append(tools, tool)
+			}
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if tools == nil {
+				tools = []mcp.Tool{}
+			}
+			return encoder.Encode(tools)
+		}
+		if len(tools) == 0 {
+			if server != "" {
+				return fmt.Errorf("server %s has no tools, or isn't connected; run `othello doctor` to check it", server)
+			}
+			fmt.Println("No tools available. Add an MCP server with `othello mcp add`.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for i, tool := range tools {
+			if i == 0 || tool.ServerName != tools[i-1].ServerName {
+				if i > 0 {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintf(w, "%s:\n", tool.ServerName)
+			}
+			description, _, _ := strings.Cut(tool.Description, "\n")
+			fmt.Fprintf(w, "  %s\t%s\n", tool.Name, description)
+		}
+		return w.Flush()
+	},
+}
+
+var toolsDescribeCmd = &cobra.Command{
+	Use:   "describe <tool>",
+	Short: "Show a tool's description and parameters",
+	Long: `Show a tool's server, its description, and the JSON schema of its
+parameters.
+
+Examples:
+  othello tools describe read_file
+
+  # The tool as JSON
+  othello tools describe read_file --json`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		agentInstance, stop, err := startToolsAgent(cmd)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		tool, ok := agentInstance.Tool(args[0])
+		if !ok {
+			return fmt.Errorf("tool %s not found; see the available tools with `othello tools list`", args[0])
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(tool)
+		}
+		schema, err := json.MarshalIndent(tool.InputSchema, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format the schema: %w", err)
+		}
+		fmt.Printf("Name:        %s\n", tool.Name)
+		fmt.Printf("Server:      %s\n", tool.ServerName)
+		fmt.Printf("Description: %s\n", tool.Description)
+		fmt.Printf("Parameters:\n%s\n", schema)
+		return nil
+	},
+}
+
+var toolsCallCmd = &cobra.Command{
+	Use:   "call <tool>",
+	Short: "Call a tool directly",
+	Long: `Call a tool without the model and print its result. Arguments are given as
+--arg name=value, converted to the type the tool's schema gives the parameter,
+or all at once as a JSON object with --json; --arg values override those in
+--json. Arguments are checked against the schema before the call, and the
+call is recorded in the audit log.
+
+The result is printed as it is shown in chat; --raw prints the text the tool
+returned as it is, and --output json prints both with the server and duration.
+
+Exit status is 0 when the tool succeeded, 1 when it failed or wasn't found,
+and 2 when an argument or flag is invalid.
+
+Examples:
+  othello tools call read_file --arg path=README.md
+
+  othello tools call search_notes --json '{"query": "backups", "limit": 5}'
+
+  # The result as the tool returned it
+  othello tools call list_directory --arg path=. --raw`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output != "text" && output != "json" {
+			return &exitError{code: 2, err: fmt.Errorf("unknown output format %q; use text or json", output)}
+		}
+		agentInstance, stop, err := startToolsAgent(cmd)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		tool, ok := agentInstance.Tool(args[0])
+		if !ok {
+			return fmt.Errorf("tool %s not found; see the available tools with `othello tools list`", args[0])
+		}
+		arguments, err := toolCallArguments(cmd, tool)
+		if err != nil {
+			return &exitError{code: 2, err: err}
+		}
+
+		ctx, cancel := signalContext(os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		result, err := agentInstance.CallTool(ctx, tool.Name, arguments)
+		if errors.Is(err, mcp.ErrInvalidArguments) {
+			return &exitError{code: 2, err: err}
+		}
+		if err != nil {
+			return err
+		}
+		switch raw, _ := cmd.Flags().GetBool("raw"); {
+		case output == "json":
+			return json.NewEncoder(os.Stdout).Encode(result)
+		case raw:
+			fmt.Println(result.Raw)
+		default:
+			fmt.Println(result.Processed)
+		}
+		return nil
+	},
+}
+
+// startToolsAgent starts an agent with the configured MCP servers connected
+// and the built-in tools available. The returned function stops it.
+func startToolsAgent(cmd *cobra.Command) (*agent.Agent, func(), error) {
+	cfg, err := config.LoadWithFlags(cmd.Flags())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	agentInstance, err := agent.New(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+	if err := agentInstance.Start(context.Background()); err != nil {
+		return nil, nil, fmt.Errorf("failed to start agent: %w", err)
+	}
+	closeTools := agentInstance.OpenTools()
+	return agentInstance, func() {
+		closeTools()
+		agentInstance.Stop(context.Background())
+	}, nil
+}
+
+// toolCallArguments reads the arguments of tools call from --json and --arg
+func toolCallArguments(cmd *cobra.Command, tool mcp.Tool) (map[string]interface{}, error) {
+	arguments := map[string]interface{}{}
+	if data, _ := cmd.Flags().GetString("json"); data != "" {
+		if err := json.Unmarshal([]byte(data), &arguments); err != nil {
+			return nil, fmt.Errorf("--json must be a JSON object: %w", err)
+		}
+	}
+	pairs, _ := cmd.Flags().GetStringArray("arg")
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --arg %q; use name=value", pair)
+		}
+		converted, err := agent.ToolArgument(tool, name, value)
+		if err != nil {
+			return nil, err
+		}
+		arguments[name] = converted
+	}
+	return arguments, nil
+}
+
+var toolsHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the tool execution audit log",
+	Long: `Show recorded tool executions, most recent first: the server and tool,
+how long each call took, whether it succeeded, and how much it returned.
+
+Arguments are not stored. Each entry has a hash of its arguments instead, so
+repeated calls with the same arguments can be spotted.
+
+Examples:
+  # The last 50 executions
+  othello tools history
+
+  # Failed calls to one server in the last day
+  othello tools history --server filesystem --failed --since 24h`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		store, err := openHistoryStore(cfg, false)
+		if err != nil || store == nil {
+			return err
+		}
+		defer store.Close()
+
+		filter := storage.ToolExecutionFilter{}
+		filter.Server, _ = cmd.Flags().GetString("server")
+		filter.Tool, _ = cmd.Flags().GetString("tool")
+		filter.FailedOnly, _ = cmd.Flags().GetBool("failed")
+		filter.Limit, _ = cmd.Flags().GetInt("limit")
+		if since, _ := cmd.Flags().GetDuration("since"); since > 0 {
+			filter.Since = time.Now().Add(-since)
+		}
+		executions, err := store.ListToolExecutions(filter)
+		if err != nil {
+			return fmt.Errorf("failed to read the audit log: %w", err)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			for _, exec := range executions {
+				if err := encoder.Encode(exec); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if len(executions) == 0 {
+			fmt.Println("No tool executions recorded.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tSERVER\tTOOL\tDURATION\tSTATUS\tSIZE\tARGS\tERROR")
+		for _, exec := range executions {
+			status := "ok"
+			if !exec.Success {
+				status = "failed"
+			}
+			hash := exec.ArgumentsHash
+			if len(hash) > 12 {
+				hash = hash[:12]
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+				exec.ExecutedAt.Local().Format("2006-01-02 15:04:05"), exec.Server, exec.Tool,
+				exec.Duration.Round(time.Millisecond), status, exec.ResultSize, hash,
+				strings.Join(strings.Fields(exec.Error), " "))
+		}
+		return w.Flush()
+	},
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [conversation-id]",
+	Short: "Show token use, cost, model latency, and tool calls per conversation",
+	Long: `Show how saved conversations used the model: tokens sent and generated,
+the number of model calls and their average latency, and the number of tool
+calls. Conversations are listed most recently updated first, under a row of
+totals. Given a conversation ID, only that conversation is shown.
+
+When model.pricing is set, the cost of each conversation is shown too. With
+--tools, the tools called in the same conversations are listed instead, each
+with its share of the tokens of the model calls that called it.
+
+Examples:
+  # The 20 most recent conversations
+  othello stats
+
+  # Conversations from the last week, as JSON
+  othello stats --since 168h --json
+
+  # One conversation
+  othello stats conv_1712345678
+
+  # The tools that used the most tokens this week
+  othello stats --since 168h --tools`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		store, err := openHistoryStore(cfg, false)
+		if err != nil || store == nil {
+			return err
+		}
+		defer store.Close()
+		search := store.SearchManager()
+
+		var all []*storage.ConversationStats
+		if len(args) == 1 {
+			stats, err := search.ConversationStatistics(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read statistics: %w", err)
+			}
+			if stats == nil {
+				return fmt.Errorf("conversation %s not found", args[0])
+			}
+			all = append(all, stats)
+		} else {
+			filter := storage.StatsFilter{}
+			filter.Limit, _ = cmd.Flags().GetInt("limit")
+			if since, _ := cmd.Flags().GetDuration("since"); since > 0 {
+				filter.Since = time.Now().Add(-since)
+			}
+			all, err = search.ListConversationStatistics(filter)
+			if err != nil {
+				return fmt.Errorf("failed to read statistics: %w", err)
+			}
+		}
+		pricing := model.Pricing{
+			InputPerMillion:  cfg.Model.Pricing.InputPerMillion,
+			OutputPerMillion: cfg.Model.Pricing.OutputPerMillion,
+		}
+
+		if showTools, _ := cmd.Flags().GetBool("tools"); showTools {
+			if len(all) == 0 {
+				fmt.Println("No conversations recorded.")
+				return nil
+			}
+			ids := make([]string, len(all))
+			for i, stats := range all {
+				ids[i] = stats.ConversationID
+			}
+			tools, err := search.ToolStatistics(ids)
+			if err != nil {
+				return fmt.Errorf("failed to read tool statistics: %w", err)
+			}
+			return printToolStats(cmd, tools, pricing)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			for _, stats := range all {
+				if err := encoder.Encode(stats); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if len(all) == 0 {
+			fmt.Println("No conversations recorded.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		header := "CONVERSATION\tTITLE\tMESSAGES\tTOKENS IN\tTOKENS OUT\tMODEL CALLS\tAVG LATENCY\tTOOL CALLS"
+		if !pricing.Free() {
+			header += "\tCOST"
+		}
+		fmt.Fprintln(w, header)
+		row := func(id, title string, stats *storage.ConversationStats) {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%s\t%d", id, title,
+				stats.Messages, stats.TokensIn, stats.TokensOut, stats.ModelCalls,
+				stats.AverageLatency().Round(time.Millisecond), stats.ToolCalls)
+			if !pricing.Free() {
+				fmt.Fprintf(w, "\t$%.4f", pricing.Cost(stats.TokensIn, stats.TokensOut))
+			}
+			fmt.Fprintln(w)
+		}
+		for _, stats := range all {
+			row(stats.ConversationID, stats.Title, stats)
+		}
+		if len(all) > 1 {
+			total := storage.TotalStatistics(all)
+			row("TOTAL", fmt.Sprintf("%d conversations", len(all)), &total)
+		}
+		return w.Flush()
+	},
+}
+
+var indexCmd = &cobra.Command{
+	Use:   "index [dir...]",
+	Short: "Index project files so related parts are sent with each message",
+	Long: `Split the files of project directories into chunks, embed them with
+model.embedding_model, and save them in the conversation database. While the
+TUI runs, the parts of indexed files related to each message are sent to the
+model with it.
+
+Without directories, the directories in index.dirs are indexed, or the
+current directory when there are none. Running it again only embeds files
+that changed since, and drops files that were deleted. Hidden files, binary
+files, files over index.max_file_size, and files listed in .gitignore are
+skipped.
+
+Examples:
+  # Index the current directory
+  othello index
+
+  # Index two projects
+  othello index ~/code/api ~/code/web
+
+  # Remove a project from the index
+  othello index --clear ~/code/web`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		dirs := args
+		if len(dirs) == 0 {
+			dirs = cfg.Index.Dirs
+		}
+		if len(dirs) == 0 {
+			dirs = []string{"."}
+		}
+
+		store, err := openHistoryStore(cfg, true)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		ix := agent.ProjectIndexer(store, cfg)
+		if ix == nil {
+			return fmt.Errorf("indexing needs an embedding model; set model.embedding_model")
+		}
+
+		if clearIndex, _ := cmd.Flags().GetBool("clear"); clearIndex {
+			for _, dir := range dirs {
+				files, err := ix.Clear(dir)
+				if err != nil {
+					return fmt.Errorf("failed to clear index of %s: %w", dir, err)
+				}
+				fmt.Printf("Removed %d files of %s from the index\n", files, dir)
+			}
+			return nil
+		}
+
+		ctx, stop := signalContext(os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		for _, dir := range dirs {
+			fmt.Printf("Indexing %s...\n", dir)
+			result, err := ix.Index(ctx, dir)
+			if err != nil {
+				return fmt.Errorf("failed to index %s: %w", dir, err)
+			}
+			fmt.Printf("Indexed %s: %d files updated (%d chunks), %d unchanged, %d removed\n",
+				result.Root, result.Updated, result.Chunks, result.Unchanged, result.Removed)
+		}
+		return nil
+	},
+}
+
+// printToolStats lists the tools called, most tokens first, as a table or,
+// with --json, one JSON object per tool
+func printToolStats(cmd *cobra.Command, tools []*storage.ToolStats, pricing model.Pricing) error {
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, stats := range tools {
+			if err := encoder.Encode(stats); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(tools) == 0 {
+		fmt.Println("No tool calls recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "TOOL\tCALLS\tTOKENS IN\tTOKENS OUT"
+	if !pricing.Free() {
+		header += "\tCOST"
+	}
+	fmt.Fprintln(w, header)
+	for _, stats := range tools {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d", stats.Tool, stats.Calls, stats.TokensIn, stats.TokensOut)
+		if !pricing.Free() {
+			fmt.Fprintf(w, "\t$%.4f", pricing.Cost(stats.TokensIn, stats.TokensOut))
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the HTTP API without the TUI",
+	Long: `Serve a JSON API backed by the same agent, MCP servers, and conversation
+history as the TUI, so scripts and other applications can use Othello.
+
+Endpoints:
+  POST /chat             {"message": "...", "conversation_id": "..."}
+  GET  /conversations    ?limit=20&offset=0
+  GET  /conversations/ID the conversation and its messages
+  GET  /tools            the available tools
+  POST /tools/execute    {"name": "...", "arguments": {...}}
+
+The server listens on localhost unless --host is given. Set --token (or
+OTHELLO_API_TOKEN) to require "Authorization: Bearer <token>" on every request.
+Events such as failed tool calls are posted to the URLs in serve.webhooks.
+"othello serve slack" and "othello serve discord" answer chat messages instead.
+
+Examples:
+  othello serve --port 8080
+  curl -s localhost:8080/chat -d '{"message": "What tools do you have?"}'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = os.Getenv("OTHELLO_API_TOKEN")
+		}
+		return serveAgent(cmd, "Othello API", nil, func(a *agent.Agent, ctx context.Context, listener net.Listener) error {
+			return a.Serve(ctx, listener, token)
+		})
+	},
+}
+
+var serveSlackCmd = &cobra.Command{
+	Use:   "slack",
+	Short: "Answer messages in Slack channels",
+	Long: `Answer the messages of the Slack channels a Slack app is in. Point the app's
+Event Subscriptions at this server's URL and subscribe to message.channels
+(and message.im for direct messages, or app_mention to answer only mentions).
+Each channel has its own conversation, saved to the history like a chat in
+the TUI, and the reply is edited as tools run.
+
+Set serve.slack.bot_token to the app's bot token (with the chat:write scope)
+and serve.slack.signing_secret to its signing secret. Slack must reach the
+server, so listen on a public address or put a tunnel in front of it.
+
+Examples:
+  othello secret set slack-bot-token
+  othello secret set slack-signing-secret
+  othello serve slack --host 0.0.0.0 --port 3000`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return serveAgent(cmd, "Othello Slack bridge", agent.CheckSlackBridge, (*agent.Agent).ServeSlack)
+	},
+}
+
+var serveDiscordCmd = &cobra.Command{
+	Use:   "discord",
+	Short: "Answer a slash command in Discord channels",
+	Long: `Answer a Discord slash command, /ask by default (serve.discord.command). Set
+the application's Interactions Endpoint URL to this server's URL, and
+serve.discord.application_id and serve.discord.public_key to the values on
+its General Information page. Each channel has its own conversation, saved to
+the history like a chat in the TUI, and the reply is edited as tools run.
+
+Plain channel messages need Discord's gateway, which this bridge doesn't use;
+register the command once with a string option for the message:
+
+  curl -X POST https://discord.com/api/v10/applications/<application_id>/commands \
+    -H "Authorization: Bot <bot token>" -H "Content-Type: application/json" \
+    -d '{"name": "ask", "description": "Ask Othello",
+         "options": [{"type": 3, "name": "message", "description": "Your message", "required": true}]}'
+
+Examples:
+  othello serve discord --host 0.0.0.0 --port 3000`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return serveAgent(cmd, "Othello Discord bridge", agent.CheckDiscordBridge, (*agent.Agent).ServeDiscord)
+	},
+}
+
+// serveAgent starts an agent and runs serve with it on --host and --port
+// until interrupted. name says what is listening, and check, if any, is
+// asked first whether the configuration can run it.
+func serveAgent(cmd *cobra.Command, name string, check func(*config.Config) error, serve func(a *agent.Agent, ctx context.Context, listener net.Listener) error) error {
+	cfg, err := config.LoadWithFlags(cmd.Flags())
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if check != nil {
+		if err := check(cfg); err != nil {
+			return err
+		}
+	}
+	host, _ := cmd.Flags().GetString("host")
+	port, _ := cmd.Flags().GetInt("port")
+
+	agentInstance, err := agent.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create agent: %w", err)
+	}
+	ctx, stop := signalContext(os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := agentInstance.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start agent: %w", err)
+	}
+	defer agentInstance.Stop(context.Background())
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	fmt.Printf("%s listening on http://%s (Ctrl+C to stop)\n", name, listener.Addr())
+	return serve(agentInstance, ctx, listener)
+}
+
+var askCmd = &cobra.Command{
+	Use:   "ask [question]",
+	Short: "Answer one question without the TUI",
+	Long: `Send one question to the model, run any tools it calls, and print the
+answer to standard output. Text piped to standard input is sent along with the
+question, so othello ask fits in shell pipelines. With a question given,
+piped input that hasn't started within half a second is ignored, so an
+inherited pipe that never closes doesn't block; add - as an argument to wait
+for it. The exchange is saved to the conversation history like a chat in the
+TUI.
+
+With --template, the prompt template of that name in the templates directory
+of the data directory is sent, with its variables set by --var or taken from
+their defaults. A question given too is added after it.
+
+With --output json, one JSON object is printed with the answer, each tool call
+with its arguments, result or error, and duration, and the token usage.
+
+Exit status is 0 when the question was answered, 1 when it could not be (for
+example, the model is unreachable), and 2 when no question was given, the
+template can't be filled in, or a flag is invalid.
+
+Examples:
+  othello ask "What files are in my home directory?"
+
+  # Explain a log
+  cat error.log | othello ask "explain this"
+
+  # Wait for slow input, such as a build's output
+  make 2>&1 | othello ask "why did this fail?" -
+
+  # Fill in the review template (~/.othello/templates/review.md)
+  git diff | othello ask --template review --var focus=security
+
+  # The answer and tool call trace as JSON
+  othello ask --output json "Summarize today's notes" | jq -r .response
+
+  # See which tools would be called, without running them
+  othello ask --dry-run --output json "Delete my old notes"`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			// --json predates --output and is kept for scripts that use it
+			if cmd.Flags().Changed("output") && output != "json" {
+				return &exitError{code: 2, err: fmt.Errorf("--json conflicts with --output %s", output)}
+			}
+			output = "json"
+		}
+		if output != "text" && output != "json" {
+			return &exitError{code: 2, err: fmt.Errorf("unknown output format %q; use text or json", output)}
+		}
+		// "-" reads standard input to the end, however long it takes to arrive
+		readStdin := slices.Contains(args, "-")
+		args = slices.DeleteFunc(args, func(arg string) bool { return arg == "-" })
+		question := strings.Join(args, " ")
+		templateName, _ := cmd.Flags().GetString("template")
+		vars, _ := cmd.Flags().GetStringToString("var")
+		if templateName == "" && len(vars) > 0 {
+			return &exitError{code: 2, err: fmt.Errorf("--var needs --template")}
+		}
+		input, err := readPipedInput(readStdin || (strings.TrimSpace(question) == "" && templateName == ""))
+		if err != nil {
+			return err
+		}
+		if templateName == "" && strings.TrimSpace(question) == "" && strings.TrimSpace(input) == "" {
+			return &exitError{code: 2, err: fmt.Errorf("a question is required")}
+		}
+
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if templateName != "" {
+			prompt, err := fillPromptTemplate(cfg, templateName, vars)
+			if err != nil {
+				return &exitError{code: 2, err: err}
+			}
+			question = strings.TrimSpace(prompt + "\n\n" + question)
+		}
+		agentInstance, err := agent.New(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create agent: %w", err)
+		}
+		ctx, stop := signalContext(os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := agentInstance.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start agent: %w", err)
+		}
+		defer agentInstance.Stop(context.Background())
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			agentInstance.SetDryRun(true)
+		}
+
+		result, err := agentInstance.Ask(ctx, question, input)
+		if err != nil {
+			return err
+		}
+		if output == "json" {
+			return json.NewEncoder(os.Stdout).Encode(result)
+		}
+		fmt.Println(result.Response)
+		return nil
+	},
+}
+
+// fillPromptTemplate returns the prompt of the template called name, in the
+// data directory, with vars filled in
+func fillPromptTemplate(cfg *config.Config, name string, vars map[string]string) (string, error) {
+	dir, err := storage.TemplatePath(cfg.Storage.DataDir)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := agent.LoadPromptTemplate(dir, name)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.Fill(vars)
+}
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Chat with the agent",
+	Long: `Start a conversation with the agent. Without flags this opens the TUI, as
+running othello does.
+
+With --plain, a simple line-based chat is used instead of the full-screen TUI,
+for CI, dumb terminals, and screen readers. Each line you type is sent, and
+the tools the model called are listed before its answer, as plain text.
+Earlier prompts, including those typed in the TUI, can be recalled with the up
+arrow when the terminal supports it. Type /exit or press Ctrl+D to leave;
+Ctrl+C cancels an answer in progress.
+
+Examples:
+  othello chat --plain
+
+  # Continue a saved conversation
+  othello chat --plain --conversation conv_1712345678
+
+  # Send prompts from a file, one per line
+  othello chat --plain < prompts.txt`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if plain, _ := cmd.Flags().GetBool("plain"); !plain {
+			return runInteractive(cmd, args)
+		}
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		agentInstance, err := agent.New(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create agent: %w", err)
+		}
+		if err := agentInstance.Start(context.Background()); err != nil {
+			return fmt.Errorf("failed to start agent: %w", err)
+		}
+		defer agentInstance.Stop(context.Background())
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			agentInstance.SetDryRun(true)
+		}
+
+		conversationID, _ := cmd.Flags().GetString("conversation")
+		session, err := agentInstance.NewChatSession(conversationID)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		return runPlainChat(session, newLineReader(cfg.TUI.Accessible, session.RecentPrompts()))
+	},
+}
+
+// lineReader reads the lines typed into a plain chat
+type lineReader interface {
+	// ReadLine returns the next line, io.EOF at the end of input, or
+	// readline.ErrInterrupt when Ctrl+C is pressed
+	ReadLine() (string, error)
+	// Remember adds line to the history recalled with the up arrow
+	Remember(line string)
+	Close() error
+}
+
+// newLineReader returns a line editor with prompts as its history when
+// standard input and output are a terminal that supports one, and otherwise
+// reads plain lines from standard input. Screen reader users (tui.accessible)
+// get plain lines too, since a line editor redraws the line as it changes.
+func newLineReader(accessible bool, prompts []string) lineReader {
+	interactive := term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd())
+	if interactive && !accessible && os.Getenv("TERM") != "dumb" {
+		rl, err := readline.NewEx(&readline.Config{
+			Prompt:                 "> ",
+			DisableAutoSaveHistory: true,
+			HistoryLimit:           len(prompts) + 1000,
+		})
+		if err == nil {
+			for _, prompt := range prompts {
+				rl.SaveHistory(prompt)
+			}
+			return &editedLines{rl}
+		}
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxPipedInput)
+	return &plainLines{scanner: scanner, prompt: interactive}
+}
+
+// editedLines reads lines with a line editor
+type editedLines struct {
+	rl *readline.Instance
+}
+
+func (r *editedLines) ReadLine() (string, error) { return r.rl.Readline() }
+func (r *editedLines) Remember(line string)      { r.rl.SaveHistory(line) }
+func (r *editedLines) Close() error              { return r.rl.Close() }
+
+// plainLines reads lines as they are, printing a prompt when asked to
+type plainLines struct {
+	scanner *bufio.Scanner
+	prompt  bool
+}
+
+func (r *plainLines) ReadLine() (string, error) {
+	if r.prompt {
+		fmt.Print("> ")
+	}
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+func (r *plainLines) Remember(string) {}
+func (r *plainLines) Close() error   { return nil }
+
+// runPlainChat sends each line read to session and prints the answers until
+// the input ends or /exit is typed
+func runPlainChat(session *agent.ChatSession, lines lineReader) error {
+	defer lines.Close()
+
+	// The chat handles signals itself so that it always ends here, where the
+	// session is saved and closed: SIGTERM ends it, and so does Ctrl+C unless
+	// it cancels an answer
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	// Lines are read on request in the background, so a signal can end the
+	// chat while it waits for one
+	next := make(chan struct{})
+	read := make(chan plainChatLine, 1)
+	go func() {
+		for range next {
+			text, err := lines.ReadLine()
+			read <- plainChatLine{text: text, err: err}
+		}
+	}()
+	defer close(next)
+
+	fmt.Println("Othello chat. Type /exit or press Ctrl+D to leave.")
+chat:
+	for {
+		next <- struct{}{}
+		var input plainChatLine
+		select {
+		case input = <-read:
+		case <-signals:
+			fmt.Println()
+			break chat
+		}
+		line, err := input.text, input.err
+		if errors.Is(err, readline.ErrInterrupt) {
+			if line == "" {
+				break
+			}
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if line == "/exit" || line == "/quit" {
+			break
+		}
+		lines.Remember(line)
+
+		result, ended, err := answerPlainChat(session, line, signals)
+		if ended {
+			break
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		for _, call := range result.ToolCalls {
+			if call.Error != "" {
+				fmt.Printf("Tool %s failed: %s\n", call.Name, call.Error)
+			} else {
+				fmt.Printf("Tool %s finished in %s\n", call.Name, call.Duration)
+			}
+		}
+		fmt.Println(result.Response)
+		fmt.Println()
+	}
+	if id := session.ConversationID(); id != "" {
+		fmt.Printf("Conversation saved as %s\n", id)
+	}
+	return nil
+}
+
+// plainChatLine is a line read for a plain chat, or the error reading it
+type plainChatLine struct {
+	text string
+	err  error
+}
+
+// answerPlainChat sends line to session and waits for the answer. A signal
+// cancels the answer, and reports whether the chat should end: Ctrl+C only
+// cancels the answer, while SIGTERM ends the chat too.
+func answerPlainChat(session *agent.ChatSession, line string, signals <-chan os.Signal) (result *agent.ChatResult, ended bool, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		result, err = session.Send(ctx, line)
+	}()
+	select {
+	case <-done:
+	case sig := <-signals:
+		cancel()
+		<-done
+		ended = sig != os.Interrupt
+	}
+	return result, ended, err
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run <workflow.yaml>",
+	Short: "Run a workflow of prompts and tool calls",
+	Long: `Run the steps of a workflow file in order, without the TUI. A step either
+sends a prompt to the model or calls a tool with arguments. A named step's
+output becomes a variable that later steps use as {{.name}}, alongside the
+workflow's vars and any --var flags.
+
+  name: Release notes
+  vars:
+    since: 7 days ago
+  steps:
+    - name: changes
+      tool: git_log
+      arguments:
+        since: "{{.since}}"
+    - prompt: |
+        Write release notes for these changes:
+        {{.changes}}
+
+A failed step stops the workflow unless it has "optional: true". Progress is
+printed to standard error and the last step's output to standard output.
+
+Exit status is 0 when every required step succeeded, 1 when one failed, and 2
+when the workflow file or a flag is invalid.
+
+Examples:
+  othello run release-notes.yaml
+  othello run release-notes.yaml --var since="30 days ago" --output json`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output != "text" && output != "json" {
+			return &exitError{code: 2, err: fmt.Errorf("unknown output format %q; use text or json", output)}
+		}
+		vars, _ := cmd.Flags().GetStringToString("var")
+		workflow, err := agent.LoadWorkflow(args[0])
+		if err != nil {
+			return &exitError{code: 2, err: err}
+		}
+
+		cfg, err := config.LoadWithFlags(cmd.Flags())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		agentInstance, err := agent.New(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create agent: %w", err)
+		}
+		ctx, stop := signalContext(os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := agentInstance.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start agent: %w", err)
+		}
+		defer agentInstance.Stop(context.Background())
+
+		total := len(workflow.Steps)
+		result, err := agentInstance.RunWorkflow(ctx, workflow, vars, func(step agent.WorkflowStepResult) {
+			if output == "json" {
+				return
+			}
+			label := step.Name
+			if step.Tool != "" {
+				label = strings.TrimSpace(label + " " + step.Tool)
+			}
+			if label == "" {
+				label = "prompt"
+			}
+			if step.Error != "" {
+				fmt.Fprintf(os.Stderr, "✗ [%d/%d] %s (%s): %s\n", step.Step, total, label, step.Duration, step.Error)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "✓ [%d/%d] %s (%s)\n", step.Step, total, label, step.Duration)
+		})
+		if output == "json" {
+			if encodeErr := json.NewEncoder(os.Stdout).Encode(result); encodeErr != nil {
+				return encodeErr
+			}
+		} else if err == nil {
+			fmt.Println(result.Output)
+		}
+		return err
+	},
+}
+
+// maxPipedInput caps how much of standard input othello ask reads
+const maxPipedInput = 1 << 20
+
+// pipedInputWait is how long othello ask waits for piped input to start when
+// the question is given as arguments
+const pipedInputWait = 500 * time.Millisecond
+
+// readPipedInput reads standard input when it is a pipe or a file, and
+// returns nothing when it is a terminal. Unless wait is set, a pipe that has
+// nothing to read within pipedInputWait is ignored, so that a pipe inherited
+// from cron, a CI runner, or ssh, which is never closed, doesn't block. A
+// note on standard error says so, since input that starts later is lost.
+func readPipedInput(wait bool) (string, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return "", nil
+	}
+	var head []byte
+	if !wait && !info.Mode().IsRegular() {
+		first := make(chan pipedChunk, 1)
+		go func() {
+			buf := make([]byte, 32*1024)
+			n, err := os.Stdin.Read(buf)
+			first <- pipedChunk{data: buf[:n], err: err}
+		}()
+		select {
+		case chunk := <-first:
+			if errors.Is(chunk.err, io.EOF) {
+				return string(chunk.data), nil
+			}
+			if chunk.err != nil {
+				return "", fmt.Errorf("failed to read standard input: %w", chunk.err)
+			}
+			head = chunk.data
+		case <-time.After(pipedInputWait):
+			// The read is left waiting until othello exits
+			fmt.Fprintf(os.Stderr, "Note: standard input had nothing to read within %v and was ignored; add - as an argument to wait for it.\n", pipedInputWait)
+			return "", nil
+		}
+	}
+	data, err := io.ReadAll(io.LimitReader(io.MultiReader(bytes.NewReader(head), os.Stdin), maxPipedInput+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read standard input: %w", err)
+	}
+	if len(data) > maxPipedInput {
+		return "", fmt.Errorf("standard input is larger than %d MiB; pipe an excerpt instead, e.g. with tail", maxPipedInput>>20)
+	}
+	return string(data), nil
+}
+
+// pipedChunk is data read from standard input, or the error reading it
+type pipedChunk struct {
+	data []byte
+	err  error
+}
+
+// exitError makes othello exit with code rather than 1
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// openHistoryStore opens the conversation database in the configured data
+// directory. Unless create is set, a missing database is reported and nil is
+// returned without an error.
+func openHistoryStore(cfg *config.Config, create bool) (*storage.ConversationStore, error) {
+	path, err := storage.DatabasePath(cfg.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if !create {
+			fmt.Fprintln(os.Stderr, "No conversation history yet.")
+			return nil, nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create data directory: %w", err)
+		}
+	}
+	store, err := storage.NewConversationStore(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage: %w", err)
+	}
+	return store, nil
+}
+
+// findConversation returns the saved conversation id, or an error naming it
+// when there is none
+func findConversation(store *storage.ConversationStore, id string) (*storage.Conversation, error) {
+	conv, err := store.GetConversation(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversation: %w", err)
+	}
+	if conv == nil {
+		return nil, fmt.Errorf("conversation %s not found; see the saved ones with `othello history list`", id)
+	}
+	return conv, nil
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(debugCmd)
+	debugCmd.AddCommand(debugBundleCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configDoctorCmd)
+	configCmd.AddCommand(configProfilesCmd)
+	configProfilesCmd.AddCommand(configProfilesListCmd)
+	configProfilesCmd.AddCommand(configProfilesCreateCmd)
+	configProfilesCmd.AddCommand(configProfilesSwitchCmd)
+	
+	// Add MCP command and subcommands
+	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.AddCommand(mcpAddCmd)
+	mcpCmd.AddCommand(mcpRemoveCmd)
+	mcpCmd.AddCommand(mcpListCmd)
+	mcpCmd.AddCommand(mcpShowCmd)
+	mcpCmd.AddCommand(mcpEditCmd)
+	mcpCmd.AddCommand(mcpUpdateCmd)
+	mcpCmd.AddCommand(mcpEnableCmd)
+	mcpCmd.AddCommand(mcpDisableCmd)
+	mcpCmd.AddCommand(mcpTrustCmd)
+
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretDeleteCmd)
+	
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyDeleteCmd)
+	historyCmd.AddCommand(historyRenameCmd)
+	historyCmd.AddCommand(historyPruneCmd)
+	historyCmd.AddCommand(historyExportCmd)
+	historyCmd.AddCommand(historyImportCmd)
+	
+	rootCmd.AddCommand(toolsCmd)
+	toolsCmd.AddCommand(toolsListCmd)
+	toolsCmd.AddCommand(toolsDescribeCmd)
+	toolsCmd.AddCommand(toolsCallCmd)
+	toolsCmd.AddCommand(toolsHistoryCmd)
+	
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(indexCmd)
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.AddCommand(serveSlackCmd)
+	serveCmd.AddCommand(serveDiscordCmd)
+	rootCmd.AddCommand(askCmd)
+	rootCmd.AddCommand(chatCmd)
+	rootCmd.AddCommand(runCmd)
+	
+	configShowCmd.Flags().Bool("effective", false, "Show every effective setting and where its value came from")
+	configValidateCmd.Flags().Bool("json", false, "Print one JSON object per check")
+	configDoctorCmd.Flags().Bool("json", false, "Print one JSON object per check")
+	doctorCmd.Flags().Bool("json", false, "Print the report as one JSON object")
+	debugBundleCmd.Flags().StringP("output", "o", "", "File to write the bundle to (default othello-debug-<time>.zip)")
+	debugBundleCmd.Flags().Int("log-lines", 1000, "How many of the latest log entries to include")
+	debugBundleCmd.Flags().Int("turns", 0, "How many of the latest conversation turns to include; none unless set")
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep showing new entries as they are written")
+	logsCmd.Flags().IntP("lines", "n", 50, "Most earlier entries to show; 0 shows all")
+	logsCmd.Flags().String("level", "", "Only show entries at this level and above: debug, info, warn, or error")
+	logsCmd.Flags().String("component", "", "Only show entries of this component, such as agent or mcp")
+	logsCmd.Flags().Bool("path", false, "Print the path of the log file and exit")
+	
+	// Settings overrides that take precedence over config files and environment variables
+	rootCmd.PersistentFlags().String("model", "", "Model name to use (overrides config and workspace settings)")
+	rootCmd.PersistentFlags().String("config", "", "Config file to use instead of searching for config.yaml (default: $OTHELLO_CONFIG)")
+	rootCmd.PersistentFlags().String("profile", "", "Configuration profile to use (default: $OTHELLO_PROFILE, or the one switched to)")
+	rootCmd.PersistentFlags().String("pprof", "", "Serve Go profiles at this address, such as :6060, for diagnosing memory and goroutine growth")
+	
+	// Scripted TUI automation for demos and end-to-end tests
+	rootCmd.Flags().String("script", "", "Drive the TUI with simulated keystrokes from a script file")
+	
+	// Pick up where the last session left off
+	rootCmd.Flags().Bool("resume", false, "Restore the most recent conversation")
+	
+	// Try out new MCP servers without letting tools change anything
+	rootCmd.Flags().Bool("dry-run", false, "Show the tool calls the model makes, validated, without running them")
+	
+	historyListCmd.Flags().Int("limit", 20, "Most conversations to list; 0 lists all")
+	historyListCmd.Flags().Bool("json", false, "Print one JSON object per conversation")
+	historyShowCmd.Flags().String("format", "markdown", "Output format: markdown, json, or html")
+	historyPruneCmd.Flags().Int("max-conversations", 0, "Most conversations to keep (overrides storage.retention.max_conversations)")
+	historyPruneCmd.Flags().Duration("max-age", 0, "Delete conversations not updated for longer (overrides storage.retention.max_age)")
+	historyPruneCmd.Flags().Int("max-size-mb", 0, "Database size to stay under (overrides storage.retention.max_size_mb)")
+	historyPruneCmd.Flags().Bool("no-vacuum", false, "Leave freed space in the database file for reuse")
+	historyExportCmd.Flags().String("format", "jsonl", "Archive format: jsonl or markdown")
+	historyExportCmd.Flags().StringP("output", "o", "", "File to write (default: standard output)")
+	toolsListCmd.Flags().String("server", "", "Only list this server's tools")
+	toolsListCmd.Flags().Bool("json", false, "Print the tools, with their schemas, as JSON")
+	toolsDescribeCmd.Flags().Bool("json", false, "Print the tool as JSON")
+	toolsCallCmd.Flags().StringArray("arg", nil, "Set an argument (name=value); repeat for more")
+	toolsCallCmd.Flags().String("json", "", "Set the arguments from a JSON object")
+	toolsCallCmd.Flags().Bool("raw", false, "Print the text the tool returned, unprocessed")
+	toolsCallCmd.Flags().String("output", "text", "Output format: text, or json for the raw and processed result with the server and duration")
+	toolsHistoryCmd.Flags().String("server", "", "Only show executions on this server")
+	toolsHistoryCmd.Flags().String("tool", "", "Only show executions of this tool")
+	toolsHistoryCmd.Flags().Bool("failed", false, "Only show failed executions")
+	toolsHistoryCmd.Flags().Duration("since", 0, "Only show executions within this long, e.g. 24h")
+	toolsHistoryCmd.Flags().Int("limit", 50, "Most executions to show; 0 shows all")
+	toolsHistoryCmd.Flags().Bool("json", false, "Print one JSON object per execution")
+	statsCmd.Flags().Duration("since", 0, "Only show conversations updated within this long, e.g. 168h")
+	statsCmd.Flags().Int("limit", 20, "Most conversations to show; 0 shows all")
+	statsCmd.Flags().Bool("json", false, "Print one JSON object per conversation")
+	statsCmd.Flags().Bool("tools", false, "List the tools called in the conversations instead, with their share of the tokens")
+	indexCmd.Flags().Bool("clear", false, "Remove the directories from the index instead")
+	serveCmd.PersistentFlags().String("host", "127.0.0.1", "Address to listen on; use 0.0.0.0 to accept remote connections")
+	serveCmd.PersistentFlags().Int("port", 8080, "Port to listen on")
+	serveCmd.Flags().String("token", "", "Bearer token required on every request (default: $OTHELLO_API_TOKEN)")
+	askCmd.Flags().String("output", "text", "Output format: text, or json for the answer with its tool call trace and token use")
+	askCmd.Flags().Bool("dry-run", false, "Show the tool calls the model makes, validated, without running them")
+	askCmd.Flags().Bool("json", false, "Print the answer, tool calls, and token use as JSON")
+	askCmd.Flags().MarkDeprecated("json", "use --output json instead")
+	askCmd.Flags().String("template", "", "Send the prompt template with this name, filled in")
+	askCmd.Flags().StringToString("var", nil, "Set a template variable (name=value); repeat for more")
+	chatCmd.Flags().Bool("plain", false, "Use a line-based chat instead of the full-screen TUI")
+	chatCmd.Flags().String("conversation", "", "Continue the saved conversation with this ID (with --plain)")
+	chatCmd.Flags().Bool("dry-run", false, "Show the tool calls the model makes, validated, without running them")
+	runCmd.Flags().StringToString("var", nil, "Set a workflow variable (name=value); repeat for more")
+	runCmd.Flags().String("output", "text", "Output format: text, or json for every step's input, output, and timing")
+	
+	// Add flags for mcp add command (simplified for standard MCP format)
+	mcpAddCmd.Flags().StringToStringP("env", "e", nil, "Environment variables (key=value)")
+	mcpAddCmd.Flags().String("url", "", "URL of a remote server reached over HTTP, instead of a command")
+	mcpAddCmd.Flags().StringToString("header", nil, "HTTP header sent to a --url server (key=value); repeat for more")
+	mcpTrustCmd.Flags().String("level", "", "Trust to give the server: blocked, read-only, ask, trusted, or default")
+	mcpUpdateCmd.Flags().String("command", "", "Command that starts the server")
+	mcpUpdateCmd.Flags().StringArray("arg", nil, "Replace the arguments; repeat for each argument")
+	mcpUpdateCmd.Flags().StringToStringP("env", "e", nil, "Set environment variables (key=value), keeping the others")
+	mcpUpdateCmd.Flags().StringSlice("unset-env", nil, "Remove environment variables by name")
+	mcpUpdateCmd.Flags().String("url", "", "URL of an HTTP server")
+	mcpUpdateCmd.Flags().StringToString("header", nil, "Set HTTP headers (key=value), keeping the others")
+	mcpUpdateCmd.Flags().StringSlice("unset-header", nil, "Remove HTTP headers by name")
+	mcpUpdateCmd.Flags().String("transport", "", "Transport: stdio or http")
+	mcpUpdateCmd.Flags().Duration("timeout", 0, "Time allowed for the server to respond, such as 45s")
+	mcpUpdateCmd.Flags().String("json", "", "JSON merge patch to apply to the server's fields")
+}
+
+// signalContext returns a context that is cancelled by the first of sigs, so
+// a command can stop and clean up. The signals act as usual once it's
+// cancelled, so a second one ends a command that is slow to stop.
+func signalContext(sigs ...os.Signal) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), sigs...)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var exit *exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		os.Exit(1)
 	}
 }
@@ -256,23 +2950,102 @@ func main() {
 func runInteractive(cmd *cobra.Command, args []string) error {
 	fmt.Println("Starting Othello AI Agent...")
 	
-	cfg, err := config.Load()
+	// Load the automation script up front so syntax errors fail fast
+	var script *tui.Script
+	if scriptPath, _ := cmd.Flags().GetString("script"); scriptPath != "" {
+		loaded, err := tui.LoadScript(scriptPath)
+		if err != nil {
+			return fmt.Errorf("failed to load script: %w", err)
+		}
+		script = loaded
+	}
+	
+	cfg, err := config.LoadWithFlags(cmd.Flags())
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// Walk new users through setup when there is no config file yet
+	if !cfg.HasConfigFile() && script == nil {
+		completed, err := runSetupWizard(cfg)
+		if err != nil {
+			return err
+		}
+		if !completed {
+			fmt.Println("Setup cancelled. Run `othello` again to restart it, or `othello config init` to use the defaults.")
+			return nil
+		}
+		if cfg, err = config.LoadWithFlags(cmd.Flags()); err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+	}
+
 	// Create agent instance
 	agentInstance, err := agent.New(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create agent: %w", err)
 	}
 
-	// Start agent (initialize MCP connections)
+	// Start agent; MCP servers connect in the background while the TUI paints
+	agentInstance.ConnectServersInBackground()
 	ctx := context.Background()
 	if err := agentInstance.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start agent: %w", err)
 	}
+	// Disconnect the MCP servers however the TUI ends, including on SIGTERM
+	defer agentInstance.Stop(context.Background())
+
+	if resume, _ := cmd.Flags().GetBool("resume"); resume {
+		agentInstance.ResumeOnStart()
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		agentInstance.SetDryRun(true)
+	}
 
 	// Start TUI mode
-	return agentInstance.StartTUI()
-}
\ No newline at end of file
+	return agentInstance.StartTUIWithScript(script)
+}
+
+// runSetupWizard runs the first-run setup wizard and writes config.yaml from the
+// user's choices. It reports whether the configuration was written.
+func runSetupWizard(cfg *config.Config) (bool, error) {
+	caps := tui.DetectTerminalCapabilities(cfg.TUI.RenderMode)
+	if cfg.TUI.Accessible {
+		caps = tui.WithAccessibility(caps)
+	}
+	if !caps.Interactive {
+		// Nothing to ask without a terminal; carry on with the defaults
+		return true, nil
+	}
+	tui.ApplyColorProfile(caps)
+
+	recommended := config.RecommendedServers()
+	servers := make([]tui.SetupServer, len(recommended))
+	for i, server := range recommended {
+		servers[i] = tui.SetupServer{Name: server.Server.Name, Description: server.Description}
+	}
+
+	save := func(result tui.SetupResult) (string, error) {
+		opts := config.SetupOptions{OllamaHost: cfg.Ollama.Host, ModelName: result.Model}
+		for _, name := range result.Servers {
+			for _, server := range recommended {
+				if server.Server.Name == name {
+					opts.Servers = append(opts.Servers, server.Server)
+				}
+			}
+		}
+		return config.WriteInitialConfig(opts)
+	}
+
+	wizard := tui.NewSetupWizard(tui.StylesForCapabilities(tui.DefaultStyles(), caps), cfg.Ollama.Host, servers, save)
+	wizard.SetCapabilities(caps)
+
+	var opts []tea.ProgramOption
+	if caps.AltScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	if _, err := tea.NewProgram(wizard, opts...).Run(); err != nil {
+		return false, fmt.Errorf("failed to run setup wizard: %w", err)
+	}
+	return wizard.Completed(), nil
+}
@@ -3,18 +3,28 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/chaos"
 	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
 	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
 	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/secrets"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
 	"github.com/danieleugenewilliams/othello-agent/internal/tui"
 )
 
@@ -309,16 +319,41 @@ func transformMCPResponse(rawData interface{}) interface{} {
 	return rawData
 }
 
+// eventHistory is how many of the latest events a new subscriber receives,
+// so a TUI started after the servers connected still hears about them
+const eventHistory = 100
+
 // Agent represents the core agent instance
 type Agent struct {
 	config              *config.Config
+	configMu            sync.RWMutex // Guards the settings a config reload changes
 	logger              *log.Logger
+	mcpLogger           *agentLogger // Logs for MCP servers and tools at the configured level
 	model               model.Model     // For LLM-based metadata extraction
 	mcpRegistry         *mcp.ToolRegistry
 	mcpManager          *MCPManager
 	toolExecutor        *mcp.ToolExecutor
 	universalIntegration *UniversalAgentIntegration // Intelligent tool calling system
-	updateChan          chan interface{} // Channel for broadcasting status updates
+	bus                 *events.Bus      // Delivers agent events to subscribers
+	logStream           *logStreamer     // Tees log lines to the TUI activity pane
+	chaos               *chaos.Injector  // Fault injection for resilience testing (nil when disabled)
+	store               *storage.ConversationStore // Local database, open while the TUI runs
+	pruner              *pruner                    // Enforces history retention while the store is open (nil when disabled)
+	audit               *auditor                   // Records tool executions while the store is open
+	dryRun              atomic.Bool                // Validate tool calls without running them
+	contextFull         atomic.Bool                // The last request's prompt passed agent.compaction.threshold
+	memory              *localMemory               // Built-in memory while the store is open (nil when disabled)
+	project             *projectIndex              // Index of local project files while the store is open (nil without an embedding model)
+	resume              bool                       // Restore the most recent conversation when the TUI starts
+	resultTransformers  []ResultTransformer        // Registered for result pipelines in addition to the built-in ones
+	hooks               []registeredHook           // Registered with RegisterHook, run after the configured ones
+	responses           *responseCache             // Recent answers to repeated questions (nil when disabled)
+	crashes             *crashReporter             // Saves a report when the TUI or a background task panics
+	trust               *serverTrust               // Trust given to servers with othello mcp trust
+	toolSchemas         *toolSchemaCache           // The tools MCP servers offered last time (nil when unavailable)
+	connectInBackground bool                       // Start connects MCP servers without waiting for them
+	connecting          sync.WaitGroup             // MCP servers still connecting in the background
+	stopConnecting      context.CancelFunc         // Stops connecting MCP servers in the background (nil when none were)
 }
 
 // Interface defines the agent's public API
@@ -329,7 +364,7 @@ type Interface interface {
 	GetStatus() *Status
 	GetMCPServers() []ServerInfo
 	GetMCPTools(ctx context.Context) ([]tui.Tool, error)
-	SubscribeToUpdates() <-chan interface{}
+	Subscribe() *events.Subscription
 	ExecuteTool(ctx context.Context, toolName string, params map[string]interface{}) (*tui.ToolExecutionResult, error)
 }
 
@@ -354,26 +389,53 @@ func New(cfg *config.Config) (*Agent, error) {
 	}
 
 	// Initialize MCP registry with logger adapter
-	mcpLogger := &agentLogger{logger: logger}
+	mcpLogger := &agentLogger{logger: logger, component: "mcp"}
+	mcpLogger.setLevel(cfg.Logging.Level)
 	mcpRegistry := mcp.NewToolRegistry(mcpLogger)
 
 	// Initialize MCP manager
 	mcpManager := NewMCPManager(mcpRegistry, mcpLogger)
 
+	// Inject faults into tool calls and model requests when chaos mode is enabled
+	injector := chaos.New(cfg.Chaos)
+	mcpManager.SetFaultInjector(injector)
+
+	// Keep everything but Ollama off the network in offline mode
+	mcpManager.SetOffline(cfg.Agent.Offline)
+
 	// Initialize tool executor
 	toolExecutor := mcp.NewToolExecutor(mcpRegistry, mcpLogger)
 
 	agent := &Agent{
 		config:       cfg,
 		logger:       logger,
+		mcpLogger:    mcpLogger,
 		mcpRegistry:  mcpRegistry,
 		mcpManager:   mcpManager,
 		toolExecutor: toolExecutor,
-		updateChan:   make(chan interface{}, 100), // Buffered channel for updates
+		bus:          events.NewBus(eventHistory),
+		chaos:        injector,
+		audit:        &auditor{logger: logger},
+		trust:        newServerTrust(cfg.Storage.DataDir, logger),
 	}
+	agent.crashes = newCrashReporter(cfg, logger, agent.bus)
+	if cfg.Agent.ResponseCache.Enabled {
+		agent.responses = newResponseCache(cfg.Agent.ResponseCache.TTL, cfg.Agent.ResponseCache.MaxEntries)
+	}
+	toolExecutor.SetObserver(agent.observeExecution)
+	agent.registerSubAgents()
+	agent.registerShell()
+	agent.registerFiles()
+	agent.registerFetch()
+	agent.registerClipboard()
+
+	// Stream log lines to the TUI activity pane in addition to the log file,
+	// with the values of secrets masked in both
+	agent.logStream = &logStreamer{bus: agent.bus}
+	logger.SetOutput(secrets.RedactingWriter(io.MultiWriter(logger.Writer(), agent.logStream)))
 
 	// Set up the callback for MCP status updates
-	mcpManager.SetUpdateCallback(agent.broadcastUpdate)
+	mcpManager.SetUpdateCallback(agent.handleMCPUpdate)
 
 	return agent, nil
 }
@@ -381,12 +443,9 @@ func New(cfg *config.Config) (*Agent, error) {
 // setupFileLogger creates a file-based logger with the specified log file path
 func setupFileLogger(logFilePath string) (*log.Logger, error) {
 	// Expand tilde to home directory if present
-	if len(logFilePath) >= 2 && logFilePath[:2] == "~/" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		logFilePath = filepath.Join(homeDir, logFilePath[2:])
+	logFilePath, err := LogFilePath(logFilePath)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create the directory if it doesn't exist
@@ -409,19 +468,41 @@ func setupFileLogger(logFilePath string) (*log.Logger, error) {
 
 // agentLogger adapts standard log.Logger to the MCP Logger interface
 type agentLogger struct {
-	logger *log.Logger
+	logger    *log.Logger
+	level     atomic.Int32 // Least severe level written, from logLevels
+	component string       // Tags each message, such as "[MCP]", when set
+}
+
+// logLevels ranks the logging.level settings from least to most severe
+var logLevels = map[string]int32{"debug": 0, "info": 1, "warn": 2, "error": 3}
+
+// setLevel writes messages at level and above; an unknown level writes all
+func (a *agentLogger) setLevel(level string) {
+	a.level.Store(logLevels[level])
 }
 
 func (a *agentLogger) Info(msg string, args ...interface{}) {
-	a.logger.Printf("[INFO] "+msg, args...)
+	if a.level.Load() <= logLevels["info"] {
+		a.logger.Printf("[INFO] "+a.tag()+msg, args...)
+	}
 }
 
 func (a *agentLogger) Error(msg string, args ...interface{}) {
-	a.logger.Printf("[ERROR] "+msg, args...)
+	a.logger.Printf("[ERROR] "+a.tag()+msg, args...)
 }
 
 func (a *agentLogger) Debug(msg string, args ...interface{}) {
-	a.logger.Printf("[DEBUG] "+msg, args...)
+	if a.level.Load() <= logLevels["debug"] {
+		a.logger.Printf("[DEBUG] "+a.tag()+msg, args...)
+	}
+}
+
+// tag returns the component tag that starts each message
+func (a *agentLogger) tag() string {
+	if a.component == "" {
+		return ""
+	}
+	return "[" + strings.ToUpper(a.component) + "] "
 }
 
 // Start starts the agent with the given context
@@ -431,6 +512,20 @@ func (a *Agent) SetModel(m model.Model) {
 	a.logger.Printf("Model set for LLM-based metadata extraction")
 }
 
+// ModelSettings returns the effective model settings, including workspace overrides
+func (a *Agent) ModelSettings() tui.ModelSettings {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return tui.ModelSettings{
+		Host:          a.config.Ollama.Host,
+		Name:          a.config.Model.Name,
+		Temperature:   a.config.Model.Temperature,
+		MaxTokens:     a.config.Model.MaxTokens,
+		ContextLength: a.config.Model.ContextLength,
+		Transport:     a.modelTransport(),
+	}
+}
+
 func (a *Agent) Start(ctx context.Context) error {
 	a.logger.Println("Starting Othello AI Agent")
 	
@@ -439,31 +534,69 @@ func (a *Agent) Start(ctx context.Context) error {
 
 	// Load additional servers from mcp.json
 	mcpConfig, err := config.LoadMCPConfig()
+	var mcpServers []config.ServerConfig
+	if err == nil {
+		mcpServers, err = config.ConvertMCPToServerConfigs(mcpConfig)
+	}
 	if err != nil {
 		a.logger.Printf("Warning: Failed to load mcp.json: %v", err)
 	} else {
-		// Convert and merge MCP servers
-		mcpServers := config.ConvertMCPToServerConfigs(mcpConfig)
+		// Merge MCP servers
 		servers = append(servers, mcpServers...)
 		a.logger.Printf("Loaded %d servers from mcp.json", len(mcpServers))
 	}
 	
+	// Remember the tools servers offer, to have them at hand next time
+	a.toolSchemas, err = openToolSchemaCache(a.config)
+	if err != nil {
+		a.logger.Printf("Warning: %v", err)
+	}
+
 	// Initialize MCP servers
+	var enabled []config.ServerConfig
+	var offline []string
 	for _, serverCfg := range servers {
-		a.logger.Printf("Connecting to MCP server: %s", serverCfg.Name)
-		if err := a.mcpManager.AddServer(ctx, serverCfg); err != nil {
-			a.logger.Printf("Failed to connect to MCP server %s: %v", serverCfg.Name, err)
-			// Continue with other servers even if one fails
+		if !serverCfg.IsEnabled() {
+			a.logger.Printf("Skipping disabled MCP server: %s", serverCfg.Name)
 			continue
 		}
-		a.logger.Printf("Successfully connected to MCP server: %s", serverCfg.Name)
+		if a.config.Agent.Offline && !offlineAllows(serverCfg) {
+			a.logger.Printf("Skipping MCP server %s: offline mode runs only stdio servers", serverCfg.Name)
+			offline = append(offline, serverCfg.Name)
+			continue
+		}
+		enabled = append(enabled, serverCfg)
+	}
+	if len(offline) > 0 {
+		a.Notify(events.LevelWarning, "Offline mode: not connecting to %s", strings.Join(offline, ", "))
+	}
+	if a.connectInBackground {
+		a.connectServersInBackground(enabled)
+	} else {
+		for _, serverCfg := range enabled {
+			a.connectServer(ctx, serverCfg)
+		}
 	}
 
 	// Initialize Universal Agent Integration for intelligent tool calling
 	a.universalIntegration = NewUniversalAgentIntegration(a.mcpRegistry, a.model, &LoggerAdapter{Logger: a.logger})
+	a.universalIntegration.executor.SetObserver(a.observeExecution)
+	if intent := a.config.Agent.Intent; intent.Classifier == "model" {
+		a.universalIntegration.classifier.SetModel(a.intentModel(), intent.Timeout)
+	}
 	a.logger.Println("Universal Agent Integration initialized")
 
 	a.logger.Printf("Agent started with model: %s", a.config.Model.Name)
+	if a.chaos != nil {
+		a.logger.Printf("Chaos mode enabled: %s", a.chaos.Summary())
+		a.Notify(events.LevelWarning, "Chaos mode enabled: %s", a.chaos.Summary())
+	}
+	if a.config.Agent.Offline {
+		a.logger.Printf("Offline mode enabled: network access limited to %s", a.config.Ollama.Host)
+	}
+	if workspaceFile := a.config.WorkspaceFile(); workspaceFile != "" {
+		a.logger.Printf("Workspace overrides from %s: %v", workspaceFile, a.config.WorkspaceOverrides())
+	}
 	return nil
 }
 
@@ -471,6 +604,12 @@ func (a *Agent) Start(ctx context.Context) error {
 func (a *Agent) Stop(ctx context.Context) error {
 	a.logger.Println("Stopping Othello AI Agent")
 	
+	// Stop connecting servers still starting in the background
+	if a.stopConnecting != nil {
+		a.stopConnecting()
+		a.connecting.Wait()
+	}
+	
 	// Stop MCP connections
 	if err := a.mcpManager.Close(ctx); err != nil {
 		a.logger.Printf("Error stopping MCP connections: %v", err)
@@ -481,33 +620,254 @@ func (a *Agent) Stop(ctx context.Context) error {
 		a.mcpRegistry.Clear()
 	}
 	
+	// Close the database when a session that opened it didn't get to
+	a.closeStore()
+	
 	a.logger.Println("Agent stopped")
 	return nil
 }
 
 // StartTUI starts the terminal user interface
 func (a *Agent) StartTUI() error {
+	return a.StartTUIWithScript(nil)
+}
+
+// StartTUIWithScript starts the terminal user interface and, when script is
+// non-nil, drives it with the script's simulated keystrokes for demos and tests
+func (a *Agent) StartTUIWithScript(script *tui.Script) error {
 	a.logger.Println("Starting TUI mode")
 	
+	// Detect what the terminal can render and degrade gracefully
+	caps := tui.DetectTerminalCapabilities(a.config.TUI.RenderMode)
+	if a.config.TUI.Accessible {
+		caps = tui.WithAccessibility(caps)
+		a.logger.Println("Accessible mode enabled")
+	}
+	tui.ApplyColorProfile(caps)
+	if caps.Degraded() {
+		a.logger.Printf("Degraded terminal detected (alt screen: %t, unicode: %t), using simplified rendering", caps.AltScreen, caps.Unicode)
+	}
+	
+	// Open the local database for conversations and prompt history; the TUI still works without it
+	if err := a.openStore(); err != nil {
+		a.logger.Printf("Warning: Failed to open storage: %v", err)
+		a.Notify(events.LevelWarning, "Storage unavailable, conversations will not be saved")
+	} else {
+		defer a.closeStore()
+	}
+	
 	// Create TUI application with agent integration
 	keymap := tui.DefaultKeyMap()
-	styles := tui.DefaultStyles()
+	styles := tui.StylesForCapabilities(tui.DefaultStyles(), caps)
 	app := tui.NewApplicationWithAgent(keymap, styles, a)
+	app.SetCapabilities(caps)
+	if a.resume {
+		app.ResumeConversation()
+	}
 	
 	// Run the TUI
-	program := tea.NewProgram(
-		app,
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(),
-	)
+	var opts []tea.ProgramOption
+	if caps.AltScreen {
+		opts = append(opts, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	}
+	program := tea.NewProgram(crashGuard{model: app, crashes: a.crashes}, opts...)
+	
+	a.logStream.enabled.Store(true)
+	defer a.logStream.enabled.Store(false)
+	
+	// Apply changes to the config files while the TUI runs
+	defer a.watchConfig()()
+
+	// Log abnormal memory and goroutine growth over a long session
+	defer a.watchResources()()
+	
+	scriptErr := make(chan error, 1)
+	if script != nil {
+		a.logger.Printf("Running TUI script with %d steps", len(script.Steps))
+		go func() {
+			defer a.crashes.recover("the TUI script")
+			err := script.Run(program.Send)
+			scriptErr <- err
+			if err != nil {
+				a.logger.Printf("TUI script failed: %v", err)
+				program.Quit()
+			}
+		}()
+	}
 	
 	if _, err := program.Run(); err != nil {
+		// A SIGINT sent to the process ends the TUI like quitting does
+		if errors.Is(err, tea.ErrInterrupted) {
+			return nil
+		}
+		if crashed := a.crashes.crashError(err); crashed != err {
+			return crashed
+		}
 		return fmt.Errorf("failed to run TUI: %w", err)
 	}
 	
+	if script != nil {
+		select {
+		case err := <-scriptErr:
+			if err != nil {
+				return fmt.Errorf("script failed: %w", err)
+			}
+		default:
+			// The program exited before the script finished (e.g. the user quit)
+		}
+	}
+	
+	return nil
+}
+
+// openStore opens the database in the configured data directory
+func (a *Agent) openStore() error {
+	path, err := storage.DatabasePath(a.config.Storage.DataDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	store, err := storage.NewConversationStore(path)
+	if err != nil {
+		return err
+	}
+	a.store = store
+	a.audit.store.Store(store)
+	a.logger.Printf("Opened storage at %s", path)
+	a.pruner = startPruning(store, a.config.Storage.Retention, a.logger, a.bus, a.crashes)
+	a.openMemory()
+	a.openProjectIndex()
 	return nil
 }
 
+// closeStore closes the database opened by openStore, unless it's closed
+func (a *Agent) closeStore() {
+	if a.store == nil {
+		return
+	}
+	a.closeProjectIndex()
+	a.closeMemory()
+	if a.pruner != nil {
+		a.pruner.Stop()
+		a.pruner = nil
+	}
+	a.audit.store.Store(nil)
+	if err := a.store.Close(); err != nil {
+		a.logger.Printf("Error closing storage: %v", err)
+	}
+	a.store = nil
+}
+
+// ConversationStore returns where chat messages are saved, or nil when storage
+// is unavailable
+func (a *Agent) ConversationStore() tui.ConversationStore {
+	if a.store == nil {
+		return nil
+	}
+	return a.store
+}
+
+// ToolAuditLog returns where tool executions are recorded, or nil when storage
+// is unavailable
+func (a *Agent) ToolAuditLog() tui.ToolAuditLog {
+	if a.store == nil {
+		return nil
+	}
+	return a.store
+}
+
+// ConversationStatistics returns the usage recorded per conversation, or nil
+// when storage is unavailable
+func (a *Agent) ConversationStatistics() tui.ConversationStatistics {
+	if a.store == nil {
+		return nil
+	}
+	return a.store.SearchManager()
+}
+
+// IncludePinnedMessages reports whether pinned messages are sent with every request
+func (a *Agent) IncludePinnedMessages() bool {
+	return a.config.Model.IncludePinned
+}
+
+// MaxIterations is the most times a message's tool results go back to the
+// model before they are shown as the answer
+func (a *Agent) MaxIterations() int {
+	return a.config.Agent.MaxIterations
+}
+
+// TurnTimeout is the time one message's model requests, tool calls, and
+// result processing may take together; 0 allows any
+func (a *Agent) TurnTimeout() time.Duration {
+	return a.config.Agent.TurnTimeout
+}
+
+// noteContextUsage tells the user when a request's prompt of promptTokens
+// fills the context window past agent.compaction.threshold, where older
+// messages start being compacted. It tells them again only once a prompt
+// has fallen below it.
+func (a *Agent) noteContextUsage(promptTokens int) {
+	window, threshold := a.config.Model.ContextLength, a.config.Agent.Compaction.Threshold
+	if window <= 0 || threshold <= 0 || promptTokens <= 0 {
+		return
+	}
+	full := float64(promptTokens) / float64(window)
+	if full < threshold {
+		a.contextFull.Store(false)
+		return
+	}
+	if !a.contextFull.Swap(true) {
+		a.Notify(events.LevelWarning, "Context %.0f%% full; older messages will be compacted", full*100)
+	}
+}
+
+// ContextCompaction returns the fraction of the context window at which the
+// chat compacts the messages of a request, and how many of the latest
+// messages it keeps as they are
+func (a *Agent) ContextCompaction() (float64, int) {
+	return a.config.Agent.Compaction.Threshold, a.config.Agent.Compaction.KeepRecent
+}
+
+// Personas returns the personas the chat can switch between and the name of
+// the one it starts with
+func (a *Agent) Personas() ([]tui.Persona, string) {
+	personas := make([]tui.Persona, len(a.config.Agent.Personas))
+	for i, p := range a.config.Agent.Personas {
+		personas[i] = tui.Persona{Name: p.Name, SystemPrompt: p.SystemPrompt, Tools: p.Tools, Temperature: p.Temperature}
+	}
+	return personas, a.config.Agent.Persona
+}
+
+// SessionMode returns the mode new conversations start in
+func (a *Agent) SessionMode() string {
+	return a.config.Agent.Mode
+}
+
+// Pricing returns what the configured model charges, for showing what
+// requests cost
+func (a *Agent) Pricing() model.Pricing {
+	return model.Pricing{
+		InputPerMillion:  a.config.Model.Pricing.InputPerMillion,
+		OutputPerMillion: a.config.Model.Pricing.OutputPerMillion,
+	}
+}
+
+// ResumeOnStart makes the next TUI session restore the most recent conversation
+func (a *Agent) ResumeOnStart() {
+	a.resume = true
+}
+
+// PromptStore returns where the chat input's prompt history is kept, or nil
+// when storage is unavailable
+func (a *Agent) PromptStore() tui.PromptStore {
+	if a.store == nil {
+		return nil
+	}
+	return a.store
+}
+
 // GetStatus returns the current agent status
 func (a *Agent) GetStatus() *Status {
 	return &Status{
@@ -570,9 +930,10 @@ func (a *Agent) GetMCPToolsAsDefinitions(ctx context.Context) ([]model.ToolDefin
 	return definitions, nil
 }
 
-// SubscribeToUpdates returns a channel for receiving status updates
-func (a *Agent) SubscribeToUpdates() <-chan interface{} {
-	return a.updateChan
+// Subscribe returns a subscription to the agent's events, starting with the
+// latest eventHistory of them
+func (a *Agent) Subscribe() *events.Subscription {
+	return a.bus.Subscribe()
 }
 
 // ExecuteTool executes an MCP tool with the given parameters
@@ -582,7 +943,7 @@ func (a *Agent) ExecuteTool(ctx context.Context, toolName string, params map[str
 	// Get the tool schema for validation
 	tool, exists := a.mcpRegistry.GetTool(toolName)
 	if !exists {
-		err := fmt.Errorf("tool '%s' not found", toolName)
+		err := fmt.Errorf("%w: %s", mcp.ErrToolNotFound, toolName)
 		a.logger.Printf("Tool not found: %s", toolName)
 		return &tui.ToolExecutionResult{
 			ToolName: toolName,
@@ -601,7 +962,7 @@ func (a *Agent) ExecuteTool(ctx context.Context, toolName string, params map[str
 		return &tui.ToolExecutionResult{
 			ToolName: toolName,
 			Success:  false,
-			Error:    fmt.Sprintf("Invalid parameters: %v", err),
+			Error:    fmt.Errorf("%w: %w", mcp.ErrInvalidArguments, err).Error(),
 		}, nil
 	}
 	
@@ -619,7 +980,8 @@ func (a *Agent) ExecuteTool(ctx context.Context, toolName string, params map[str
 	a.logger.Printf("Tool %s executed successfully", toolName)
 	
 	// Process the result into a natural language summary
-	processor := &ToolResultProcessor{}
+	processor := a.resultProcessor()
+	processor.SummarizeOver = 0 // Results run directly are shown in full
 
 	// Use universal MCP processor directly with the ToolResult
 	processedResult, err := processor.ProcessToolResult(ctx, toolName, result.Result, "")
@@ -642,33 +1004,108 @@ func (a *Agent) ExecuteTool(ctx context.Context, toolName string, params map[str
 // ProcessToolResult processes tool results using the intelligent result processor
 func (a *Agent) ProcessToolResult(ctx context.Context, toolName string, result *mcp.ExecuteResult, userQuery string) (string, error) {
 	// Use universal MCP processor directly with the ToolResult
-	processor := &ToolResultProcessor{Logger: a.logger}
+	processor := a.resultProcessor()
+	processor.SummarizeOver = 0 // Results run directly are shown in full
 	return processor.ProcessToolResult(ctx, toolName, result.Result, userQuery)
 }
 
+// RegisterResultTransformer makes t available to the result pipelines in
+// agent.result_pipelines. Register transformers before running any tools.
+func (a *Agent) RegisterResultTransformer(t ResultTransformer) {
+	a.resultTransformers = append(a.resultTransformers, t)
+}
+
+// resultProcessor returns a processor with the configured pipelines and the
+// registered transformers
+func (a *Agent) resultProcessor() *ToolResultProcessor {
+	processor := &ToolResultProcessor{
+		Logger:        a.logger,
+		Model:         a.model,
+		Pipelines:     a.config.Agent.ResultPipelines,
+		SummarizeOver: a.config.Agent.SummarizeOver,
+	}
+	for _, t := range a.resultTransformers {
+		processor.Register(t)
+	}
+	return processor
+}
+
 // ExecuteToolUnified provides a single, consistent pathway for tool execution
-// This method replaces the dual pathways (direct + chat) with unified processing
+// This method replaces the dual pathways (direct + chat) with unified processing.
+// Failed calls aren't retried, since no model made them.
 func (a *Agent) ExecuteToolUnified(ctx context.Context, toolName string, params map[string]interface{}, userContext string) (string, error) {
 	// Use the enhanced version with empty conversation context for backward compatibility
 	convContext := &model.ConversationContext{
 		UserQuery:   userContext,
 		SessionType: "chat",
 	}
-	return a.ExecuteToolUnifiedWithContext(ctx, toolName, params, convContext)
+	return a.executeToolUnified(ctx, toolName, params, convContext)
 }
 
-// ExecuteToolUnifiedWithContext provides tool execution with conversation context for intelligent responses
+// ExecuteToolUnifiedWithContext provides tool execution with conversation context for intelligent responses.
+// When the model's arguments are rejected, the model is asked to correct them, up to agent.tool_retries times.
 func (a *Agent) ExecuteToolUnifiedWithContext(ctx context.Context, toolName string, params map[string]interface{}, convContext *model.ConversationContext) (string, error) {
+	run, err := a.runToolWithRetries(ctx, toolName, params, convContext)
+	if err != nil {
+		return "", err
+	}
+	return a.finishToolCall(ctx, run, a.processToolRun(ctx, run, convContext), convContext), nil
+}
+
+// runToolWithRetries runs a tool, asking the model to correct the call when
+// the tool rejects it, up to agent.tool_retries times
+func (a *Agent) runToolWithRetries(ctx context.Context, toolName string, params map[string]interface{}, convContext *model.ConversationContext) (*toolRun, error) {
+	run, err := a.runTool(ctx, toolName, params, convContext)
+	retries := a.config.Agent.ToolRetries
+	var rejected *toolCallError
+	for attempt := 1; attempt <= retries && errors.As(err, &rejected) && a.model != nil; attempt++ {
+		a.logger.Printf("Asking the model to correct its call to %s (attempt %d of %d): %v", toolName, attempt, retries, err)
+		corrected, fixErr := a.correctToolCall(ctx, toolName, params, err, convContext)
+		if fixErr != nil {
+			a.logger.Printf("No corrected call to %s: %v", toolName, fixErr)
+			break
+		}
+		params = corrected
+		run, err = a.runTool(ctx, toolName, params, convContext)
+	}
+	return run, err
+}
+
+// executeToolUnified runs a tool and processes its result for the user. Calls
+// the tool rejects fail with a *toolCallError.
+func (a *Agent) executeToolUnified(ctx context.Context, toolName string, params map[string]interface{}, convContext *model.ConversationContext) (string, error) {
+	run, err := a.runTool(ctx, toolName, params, convContext)
+	if err != nil {
+		return "", err
+	}
+	return a.finishToolCall(ctx, run, a.processToolRun(ctx, run, convContext), convContext), nil
+}
+
+// toolRun is a tool call that has been made, waiting for its result to be
+// processed for the user
+type toolRun struct {
+	name   string
+	params map[string]interface{} // Arguments the tool was called with, after the hooks
+	key    map[string]interface{} // Arguments the model sent, which identify repeated calls
+	result *mcp.ToolResult
+	output string // Set instead of result when the call didn't reach the tool
+	done   bool   // Whether output is final: a repeated call or a dry run
+}
+
+// runTool validates a tool call and runs it, without processing its result.
+// Calls the tool rejects fail with a *toolCallError.
+func (a *Agent) runTool(ctx context.Context, toolName string, params map[string]interface{}, convContext *model.ConversationContext) (*toolRun, error) {
 	a.logger.Printf("Executing tool (unified with context): %s with params: %+v", toolName, params)
 	a.logger.Printf("Conversation context: %d history messages, query: %s", len(convContext.History), convContext.UserQuery)
 	log.Printf("🚀 UNIFIED EXECUTION STARTED (with context): %s", toolName)
+	a.logBudget(ctx, "calling "+toolName)
 
 	// Get the tool schema for validation
 	tool, exists := a.mcpRegistry.GetTool(toolName)
 	if !exists {
-		err := fmt.Errorf("tool '%s' not found", toolName)
+		err := fmt.Errorf("%w: %s", mcp.ErrToolNotFound, toolName)
 		a.logger.Printf("Tool not found: %s", toolName)
-		return "", err
+		return nil, err
 	}
 
 	// Validate the tool call before execution
@@ -678,59 +1115,140 @@ func (a *Agent) ExecuteToolUnifiedWithContext(ctx context.Context, toolName stri
 	}
 	if err := ValidateToolCall(toolCall, tool); err != nil {
 		a.logger.Printf("Tool validation failed for %s: %v", toolName, err)
-		return "", fmt.Errorf("invalid parameters: %v", err)
+		return nil, &toolCallError{err: fmt.Errorf("%w: %w", mcp.ErrInvalidArguments, err)}
+	}
+	if output, ok := a.repeatedCall(turnResults(convContext), toolName, params); ok {
+		return &toolRun{name: toolName, output: output, done: true}, nil
+	}
+	if err := a.checkToolPolicies(ctx, toolName, params, turnCalls(convContext)); err != nil {
+		a.logger.Printf("Tool call refused: %v", err)
+		return nil, err
+	}
+	run := &toolRun{name: toolName, key: params} // Repeated calls are recognized by the arguments the model sent
+	params, err := a.beforeToolCall(ctx, toolName, params)
+	if err != nil {
+		return nil, err
+	}
+	// Arguments a hook changed are checked again, so a hook can't slip a
+	// call past the schema or the argument policies
+	if !reflect.DeepEqual(params, run.key) {
+		toolCall.Arguments = params
+		if err := ValidateToolCall(toolCall, tool); err != nil {
+			a.logger.Printf("Arguments from the pre_tool_call hooks for %s are invalid: %v", toolName, err)
+			return nil, fmt.Errorf("%w: the pre_tool_call hooks gave %s: %w", mcp.ErrInvalidArguments, toolName, err)
+		}
+		if err := a.checkArgumentPolicies(toolName, params); err != nil {
+			a.logger.Printf("Tool call refused after the pre_tool_call hooks: %v", err)
+			return nil, err
+		}
+	}
+	run.params = params
+	if a.dryRun.Load() {
+		a.logger.Printf("Dry run: skipped %s", toolName)
+		run.output, run.done = dryRunResult(toolName, params), true
+		return run, nil
 	}
 
-	// Execute the tool using the tool executor
-	result, err := a.toolExecutor.Execute(ctx, toolName, params)
+	// Execute the tool using the tool executor, showing any output its
+	// server streams before the result
+	started := time.Now()
+	streamed := mcp.WithToolOutput(ctx, func(text string) {
+		a.bus.Publish(events.ToolOutputEvent{Tool: toolName, Text: text})
+	})
+	result, err := a.toolExecutor.Execute(streamed, toolName, params)
+	executed := events.ToolExecutedEvent{Tool: toolName, Duration: time.Since(started)}
 	if err != nil {
 		a.logger.Printf("Tool execution failed for %s: %v", toolName, err)
-		return "", err
+		executed.Error = err.Error()
+		a.bus.Publish(executed)
+		return nil, err
 	}
+	if result.Result != nil && result.Result.IsError {
+		a.logger.Printf("Tool %s reported an error", toolName)
+		executed.Error = toolResultText(result.Result)
+		a.bus.Publish(executed)
+		return nil, &toolCallError{err: errors.New(executed.Error)}
+	}
+	executed.Success = true
+	a.bus.Publish(executed)
+	a.recordInverse(convContext, toolName, params, result.Result)
 
 	a.logger.Printf("Tool %s executed successfully (unified with context)", toolName)
+	run.result = result.Result
+	return run, nil
+}
 
-	// Use enhanced MCP processor with conversation context and model for LLM-based extraction
-	processor := &ToolResultProcessor{
-		Logger: a.logger,
-		Model:  a.model,
+// processToolRun processes the result of run for the user, neutralizing any
+// instructions it holds for the model, and sets the follow-ups and full
+// result of convContext. It only reads the rest of convContext, so runs can
+// be processed concurrently with copies of it.
+func (a *Agent) processToolRun(ctx context.Context, run *toolRun, convContext *model.ConversationContext) string {
+	if run.done {
+		return run.output
 	}
-	a.logger.Printf("[UNIFIED] About to call processor with toolName=%s and conversation context", toolName)
-	processedResult, err := processor.ProcessToolResultWithContext(ctx, toolName, result.Result, convContext)
+	a.logBudget(ctx, "processing the result of "+run.name)
+
+	// Use enhanced MCP processor with conversation context and model for LLM-based extraction
+	processor := a.resultProcessor()
+	a.logger.Printf("[UNIFIED] About to call processor with toolName=%s and conversation context", run.name)
+	processedResult, err := processor.ProcessToolResultWithContext(ctx, run.name, run.result, convContext)
 	a.logger.Printf("[UNIFIED] Context-aware processor returned result length=%d, error=%v", len(processedResult), err)
 	if err != nil {
 		// Log error but don't fail - use a basic fallback
-		a.logger.Printf("Warning: Failed to process result for %s: %v", toolName, err)
-		if result.Result != nil && len(result.Result.Content) > 0 {
-			processedResult = result.Result.Content[0].Text
-		} else {
-			processedResult = "Tool executed successfully but couldn't process the result."
-		}
+		a.logger.Printf("Warning: Failed to process result for %s: %v", run.name, err)
+		processedResult = unprocessedResult(run.result)
+	}
+	return a.guardResult(run.name, processedResult)
+}
+
+// unprocessedResult is shown for a tool result that couldn't be processed
+func unprocessedResult(result *mcp.ToolResult) string {
+	if result != nil && len(result.Content) > 0 {
+		return result.Content[0].Text
+	}
+	return "Tool executed successfully but couldn't process the result."
+}
+
+// finishToolCall records run in convContext once its result is processed and
+// returns the result to show
+func (a *Agent) finishToolCall(ctx context.Context, run *toolRun, processedResult string, convContext *model.ConversationContext) string {
+	if run.done {
+		return processedResult
 	}
 
 	// Update conversation context with this tool usage
 	if convContext.PreviousTools == nil {
 		convContext.PreviousTools = make([]string, 0)
 	}
-	convContext.PreviousTools = append(convContext.PreviousTools, toolName)
-
-	// Broadcast unified tool execution update
-	a.broadcastUpdate(tui.ToolExecutedUnifiedMsg{
-		ToolName: toolName,
-		Result:   processedResult,
-		Success:  true,
-	})
+	convContext.PreviousTools = append(convContext.PreviousTools, run.name)
+	processedResult = a.afterToolCall(ctx, run.name, run.params, processedResult)
+	rememberCall(turnResults(convContext), run.name, run.key, processedResult)
 
-	return processedResult, nil
+	return processedResult
 }
 
-// broadcastUpdate sends an update to all subscribers (non-blocking)
-func (a *Agent) broadcastUpdate(update interface{}) {
-	select {
-	case a.updateChan <- update:
-		// Update sent successfully
-	default:
-		// Channel is full, drop the update to avoid blocking
-		a.logger.Printf("Warning: Update channel full, dropping update")
+// handleMCPUpdate publishes MCP manager updates as events
+func (a *Agent) handleMCPUpdate(update interface{}) {
+	switch u := update.(type) {
+	case ServerStatusUpdate:
+		a.bus.Publish(events.ServerStatusEvent{
+			Server:    u.ServerName,
+			Connected: u.Connected,
+			ToolCount: u.ToolCount,
+			Error:     secrets.Redact(u.Error),
+		})
+	case ToolUpdate:
+		a.bus.Publish(events.ToolsChangedEvent{
+			Server:  u.ServerName,
+			Added:   u.Added,
+			Removed: u.Removed,
+		})
 	}
+}
+
+// Notify publishes a short message for the user, which the TUI shows as a toast
+func (a *Agent) Notify(level events.Level, format string, args ...interface{}) {
+	text := secrets.Redact(fmt.Sprintf(format, args...))
+	a.logger.Printf("Notification: %s", text)
+	a.bus.Publish(events.NotificationEvent{Text: text, Level: level})
 }
\ No newline at end of file
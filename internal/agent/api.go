@@ -0,0 +1,647 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+const (
+	// apiHistoryMessages is how many earlier messages of a conversation are
+	// sent to the model with a chat request
+	apiHistoryMessages = 20
+
+	// apiMaxRequestSize caps the size of a request body
+	apiMaxRequestSize = 1 << 20
+
+	// apiDefaultLimit is how many conversations GET /conversations returns
+	// without a limit
+	apiDefaultLimit = 20
+
+	// apiShutdownTimeout is how long Serve waits for requests in flight
+	apiShutdownTimeout = 10 * time.Second
+
+	// apiTitleLength limits the title taken from a conversation's first message
+	apiTitleLength = 60
+)
+
+// errStorageUnavailable is reported by endpoints that need the database when
+// it could not be opened
+var errStorageUnavailable = errors.New("storage is unavailable")
+
+// chatRequest is the body of POST /chat
+type chatRequest struct {
+	ConversationID string   `json:"conversation_id,omitempty"` // continue this conversation; empty starts a new one
+	Message        string   `json:"message"`
+	Temperature    *float64 `json:"temperature,omitempty"` // overrides the configured temperature
+}
+
+// ChatResult is a model's answer to a message, as returned by POST /chat and
+// Ask
+type ChatResult struct {
+	ConversationID string           `json:"conversation_id,omitempty"` // empty when the exchange was not saved
+	Response       string           `json:"response"`
+	ToolCalls      []ToolCallResult `json:"tool_calls,omitempty"`
+	Usage          model.Usage      `json:"usage"`
+}
+
+// ToolCallResult describes a tool the model called while answering
+type ToolCallResult struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Result    string                 `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Duration  string                 `json:"duration,omitempty"`
+}
+
+// toolExecuteRequest is the body of POST /tools/execute
+type toolExecuteRequest struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// toolExecuteResponse is the reply to POST /tools/execute
+type toolExecuteResponse struct {
+	Tool     string      `json:"tool"`
+	Success  bool        `json:"success"`
+	Result   interface{} `json:"result,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Duration string      `json:"duration,omitempty"`
+}
+
+// apiTool describes a tool in GET /tools
+type apiTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Server      string                 `json:"server"`
+	InputSchema map[string]interface{} `json:"input_schema,omitempty"`
+}
+
+// apiServer answers HTTP API requests with an agent
+type apiServer struct {
+	agent *Agent
+	token string // bearer token every request must carry; empty allows all
+}
+
+// APIHandler returns the HTTP API. When token is not empty, requests must
+// send it as a bearer token.
+func (a *Agent) APIHandler(token string) http.Handler {
+	s := &apiServer{agent: a, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /chat", s.chat)
+	mux.HandleFunc("GET /conversations", s.listConversations)
+	mux.HandleFunc("GET /conversations/{id}", s.getConversation)
+	mux.HandleFunc("GET /tools", s.listTools)
+	mux.HandleFunc("POST /tools/execute", s.executeTool)
+	mux.HandleFunc("POST /v1/chat/completions", s.chatCompletions)
+	mux.HandleFunc("GET /v1/models", s.listModels)
+	return s.authorize(mux)
+}
+
+// Serve answers HTTP API requests on listener until ctx is done, then waits
+// for those in flight. Conversations are saved to the same database as the
+// TUI's.
+func (a *Agent) Serve(ctx context.Context, listener net.Listener, token string) error {
+	return a.serveHTTP(ctx, listener, "API server", a.APIHandler(token), nil)
+}
+
+// serveHTTP runs the server called name, answering with handler on listener
+// until ctx is done. After the requests in flight, it waits for drain, if
+// any, so that work they started in the background can still be saved.
+func (a *Agent) serveHTTP(ctx context.Context, listener net.Listener, name string, handler http.Handler, drain func()) error {
+	a.logger.Printf("Starting %s on %s", name, listener.Addr())
+	defer a.startWebhooks()() // First, so the first pruning of history is sent
+	defer a.startHeadless()()
+	defer a.watchConfig()()
+	defer a.watchResources()()
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("%s failed: %w", name, err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), apiShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop %s: %w", name, err)
+	}
+	if drain != nil {
+		drain()
+	}
+	a.logger.Printf("%s stopped", name)
+	return nil
+}
+
+// startHeadless prepares the agent to answer without the TUI: it creates the
+// configured model unless one was set, opens storage, keeps the tool
+// pipeline's debug output in the log file. The returned function undoes it.
+func (a *Agent) startHeadless() func() {
+	if a.model == nil {
+		settings := a.ModelSettings()
+		m := model.NewOllamaModel(settings.Host, settings.Name)
+		if settings.Transport != nil {
+			m.SetTransport(settings.Transport)
+		}
+		a.SetModel(a.WrapModel(m))
+	}
+
+	opened := true
+	if err := a.openStore(); err != nil {
+		a.logger.Printf("Warning: Failed to open storage: %v", err)
+		opened = false
+	}
+
+	previousOutput := log.Writer()
+	log.SetOutput(a.logger.Writer())
+
+	return func() {
+		log.SetOutput(previousOutput)
+		if opened {
+			a.closeStore()
+		}
+	}
+}
+
+// generateOptions returns the configured generation settings
+func (a *Agent) generateOptions() model.GenerateOptions {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return model.GenerateOptions{
+		Temperature:   a.config.Model.Temperature,
+		MaxTokens:     a.config.Model.MaxTokens,
+		ContextLength: a.config.Model.ContextLength,
+	}
+}
+
+// authorize rejects requests without the bearer token
+func (s *apiServer) authorize(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAPIError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// chat handles POST /chat: the message is sent to the model with the
+// conversation's recent messages, any tools it calls are run, and the
+// exchange is saved
+func (s *apiServer) chat(w http.ResponseWriter, r *http.Request) {
+	var req chatRequest
+	if err := decodeAPIRequest(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		writeAPIError(w, http.StatusBadRequest, errors.New("message is required"))
+		return
+	}
+
+	a := s.agent
+	var conv *storage.Conversation
+	var history []*storage.Message
+	if req.ConversationID != "" {
+		if a.store == nil {
+			writeAPIError(w, http.StatusServiceUnavailable, errStorageUnavailable)
+			return
+		}
+		var err error
+		if conv, err = a.store.GetConversation(req.ConversationID); err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if conv == nil {
+			writeAPIError(w, http.StatusNotFound, fmt.Errorf("conversation %s not found", req.ConversationID))
+			return
+		}
+		if history, err = a.store.GetThread(conv.ID); err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	options := a.generateOptions()
+	if req.Temperature != nil {
+		options.Temperature = *req.Temperature
+	}
+
+	reply, err := a.respond(r.Context(), conv, history, req.Message, options)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, reply)
+}
+
+// respond sends text to the model with the conversation's recent messages,
+// runs any tools it calls and sends their results back until it answers, and
+// saves the exchange, starting a conversation when conv is nil. All of it
+// shares the agent.turn_timeout budget.
+func (a *Agent) respond(ctx context.Context, conv *storage.Conversation, history []*storage.Message, text string, options model.GenerateOptions) (*ChatResult, error) {
+	turnStarted := time.Now()
+	ctx, cancel := model.WithTurnBudget(ctx, a.config.Agent.TurnTimeout)
+	defer cancel()
+	messages := a.compactMessages(ctx, a.chatMessages(ctx, conv, history, text))
+	tools, err := a.GetMCPToolsAsDefinitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	started := time.Now()
+	response, err := a.model.ChatWithTools(ctx, messages, tools, options)
+	if err != nil {
+		return nil, fmt.Errorf("model request failed: %w", a.turnError(ctx, err))
+	}
+	latency := responseLatency(response, started)
+	a.publishUsage("request", response, latency)
+
+	reply := &ChatResult{Response: response.Content, Usage: response.Usage}
+	// Send tool results back until the model answers without calling tools;
+	// the results of the last round allowed are the answer
+	turnCalls, turnResults := make(map[string]int), make(map[string]string)
+	for round := 0; len(response.ToolCalls) > 0; round++ {
+		reportTurnProgress(ctx, response.ToolCalls)
+		outputs, results := a.runToolCalls(ctx, response.ToolCalls, messages, text, turnCalls, turnResults)
+		reply.ToolCalls = append(reply.ToolCalls, results...)
+		if round == a.config.Agent.MaxIterations {
+			reply.Response = combineToolResults(outputs)
+			break
+		}
+
+		messages = a.compactMessages(ctx, append(messages, model.ToolResultMessages(response.Content, response.ToolCalls, outputs)...))
+		started = time.Now()
+		response, err = a.model.ChatWithTools(ctx, messages, tools, options)
+		if err != nil {
+			return nil, fmt.Errorf("model request failed: %w", a.turnError(ctx, err))
+		}
+		took := responseLatency(response, started)
+		a.publishUsage("request", response, took)
+		latency += took
+		reply.Usage.PromptTokens += response.Usage.PromptTokens
+		reply.Usage.CompletionTokens += response.Usage.CompletionTokens
+		reply.Usage.TotalTokens += response.Usage.TotalTokens
+		reply.Response = response.Content
+	}
+
+	// The answer is returned even if it can't be saved
+	if a.store != nil {
+		id, err := a.saveExchange(conv, text, reply, latency)
+		if err != nil {
+			a.logger.Printf("Warning: Failed to save conversation: %v", err)
+		}
+		reply.ConversationID = id
+	}
+	a.bus.Publish(events.ConversationCompletedEvent{
+		ConversationID: reply.ConversationID,
+		ToolCalls:      len(reply.ToolCalls),
+		TotalTokens:    reply.Usage.TotalTokens,
+		Duration:       time.Since(turnStarted),
+	})
+	return reply, nil
+}
+
+// compactMessages summarizes the older of messages once they come close to
+// the context window, as agent.compaction says. Messages that can't be
+// compacted are sent as they are.
+func (a *Agent) compactMessages(ctx context.Context, messages []model.Message) []model.Message {
+	compactor := &model.ContextCompactor{
+		Model:      a.model,
+		Limit:      a.config.Model.ContextLength,
+		Threshold:  a.config.Agent.Compaction.Threshold,
+		KeepRecent: a.config.Agent.Compaction.KeepRecent,
+	}
+	if !compactor.NeedsCompaction(messages) {
+		return messages
+	}
+	compacted, err := compactor.Compact(ctx, messages, nil)
+	if err != nil {
+		a.logger.Printf("Warning: Failed to compact %d messages: %v", len(messages), err)
+		return messages
+	}
+	a.logger.Printf("Compacted %d messages into %d", len(messages), len(compacted))
+	return compacted
+}
+
+// responseLatency is how long the model took for response, timed from
+// started when the model doesn't report it
+func responseLatency(response *model.Response, started time.Time) time.Duration {
+	if response.Duration > 0 {
+		return response.Duration
+	}
+	return time.Since(started)
+}
+
+// publishUsage publishes the tokens response used as an
+// events.TokenUsageEvent. source says what made the request.
+func (a *Agent) publishUsage(source string, response *model.Response, duration time.Duration) {
+	a.bus.Publish(events.TokenUsageEvent{
+		Source:           source,
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+		TotalTokens:      response.Usage.TotalTokens,
+		Duration:         duration,
+	})
+}
+
+// chatMessages builds the messages sent for text: a system message with the
+// conversation's summary and any recalled memories, the conversation's
+// recent messages, and text itself
+func (a *Agent) chatMessages(ctx context.Context, conv *storage.Conversation, history []*storage.Message, text string) []model.Message {
+	var system []string
+	if conv != nil && conv.Summary != "" {
+		system = append(system, "Summary of the conversation so far:\n"+conv.Summary)
+	}
+	if limit := a.MemoryRecallLimit(); a.memory != nil && limit > 0 {
+		if recalled, err := a.memory.Recall(ctx, text, limit); err == nil && len(recalled) > 0 {
+			lines := []string{"Facts you remember that may be relevant:"}
+			for _, r := range recalled {
+				lines = append(lines, fmt.Sprintf("- [%s] %s", r.Memory.Topic, r.Memory.Content))
+			}
+			system = append(system, strings.Join(lines, "\n"))
+		}
+	}
+
+	var messages []model.Message
+	if len(system) > 0 {
+		messages = append(messages, model.Message{Role: "system", Content: strings.Join(system, "\n\n")})
+	}
+	var recent []model.Message
+	for i := len(history) - 1; i >= 0 && len(recent) < apiHistoryMessages; i-- {
+		msg := history[i]
+		if (msg.Role == "user" || msg.Role == "assistant") && msg.Content != "" {
+			recent = append([]model.Message{{Role: msg.Role, Content: msg.Content}}, recent...)
+		}
+	}
+	messages = append(messages, recent...)
+	return append(messages, model.Message{Role: "user", Content: text})
+}
+
+// runToolCalls runs the tools the model called and returns what each one
+// produced, as the chat view shows it, along with the details of each call.
+// turnCalls counts the request's tool calls across rounds, and turnResults
+// keeps their results so repeated calls aren't run again.
+func (a *Agent) runToolCalls(ctx context.Context, calls []model.ToolCall, history []model.Message, query string, turnCalls map[string]int, turnResults map[string]string) ([]string, []ToolCallResult) {
+	convContext := &model.ConversationContext{
+		History:     history,
+		UserQuery:   query,
+		SessionType: "api",
+		Entities:    model.NewEntityStore(),
+		TurnCalls:   turnCalls,
+		TurnResults: turnResults,
+	}
+
+	results := make([]ToolCallResult, 0, len(calls))
+	texts := make([]string, 0, len(calls))
+	for i, outcome := range a.ExecuteToolCallsWithContext(ctx, calls, convContext) {
+		call := calls[i]
+		result := ToolCallResult{Name: call.Name, Arguments: call.Arguments}
+		result.Duration = outcome.Duration.Round(time.Millisecond).String()
+		if outcome.Err != nil {
+			result.Error = outcome.Err.Error()
+			texts = append(texts, fmt.Sprintf("❌ Tool %s failed: %v", call.Name, outcome.Err))
+		} else {
+			result.Result = outcome.Output
+			texts = append(texts, outcome.Output)
+		}
+		results = append(results, result)
+	}
+	return texts, results
+}
+
+// combineToolResults combines the outputs of tool calls into one answer
+func combineToolResults(texts []string) string {
+	if len(texts) == 1 {
+		return texts[0]
+	}
+	return "I've executed several tools to help you:\n\n" + strings.Join(texts, "\n\n")
+}
+
+// saveExchange saves a chat request and its reply, starting a conversation
+// when conv is nil, and returns the conversation's ID
+func (a *Agent) saveExchange(conv *storage.Conversation, text string, reply *ChatResult, latency time.Duration) (string, error) {
+	var id string
+	if conv != nil {
+		id = conv.ID
+	} else {
+		id = fmt.Sprintf("conv_%d", time.Now().UnixNano())
+		if _, err := a.store.CreateConversation(id, apiConversationTitle(text)); err != nil {
+			return "", err
+		}
+	}
+
+	messages := []*storage.Message{{Role: "user", Content: text}}
+	for _, call := range reply.ToolCalls {
+		stored := &storage.Message{
+			Role:       "tool",
+			ToolCall:   &storage.ToolCall{Name: call.Name, Arguments: call.Arguments},
+			ToolResult: &storage.ToolResult{Content: call.Result},
+		}
+		if call.Error != "" {
+			stored.ToolResult = &storage.ToolResult{Content: call.Error, IsError: true}
+		}
+		messages = append(messages, stored)
+	}
+	messages = append(messages, &storage.Message{Role: "assistant", Content: reply.Response})
+
+	for _, msg := range messages {
+		msg.ConversationID = id
+		msg.Timestamp = time.Now()
+		if err := a.store.AddMessage(msg); err != nil {
+			return id, err
+		}
+	}
+	if err := a.store.RecordModelCall(id, reply.Usage.PromptTokens, reply.Usage.CompletionTokens, latency); err != nil {
+		return id, err
+	}
+	if n := len(reply.ToolCalls); n > 0 {
+		if err := a.store.RecordToolCalls(id, n); err != nil {
+			return id, err
+		}
+		tools := make([]string, n)
+		for i, call := range reply.ToolCalls {
+			tools[i] = call.Name
+		}
+		if err := a.store.RecordToolUsage(id, tools, reply.Usage.PromptTokens, reply.Usage.CompletionTokens); err != nil {
+			return id, err
+		}
+	}
+	return id, nil
+}
+
+// apiConversationTitle takes a conversation's title from its first message
+func apiConversationTitle(text string) string {
+	title := []rune(strings.Join(strings.Fields(text), " "))
+	if len(title) > apiTitleLength {
+		return string(title[:apiTitleLength-3]) + "..."
+	}
+	return string(title)
+}
+
+// listConversations handles GET /conversations?limit=&offset=, most recently
+// updated first
+func (s *apiServer) listConversations(w http.ResponseWriter, r *http.Request) {
+	store := s.agent.store
+	if store == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, errStorageUnavailable)
+		return
+	}
+	limit, err := queryInt(r, "limit", apiDefaultLimit)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	conversations, err := store.ListConversations(limit, offset)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if conversations == nil {
+		conversations = []*storage.Conversation{}
+	}
+	writeAPIResponse(w, http.StatusOK, map[string]interface{}{"conversations": conversations})
+}
+
+// getConversation handles GET /conversations/{id}, returning the
+// conversation with its messages, including those of the conversations it
+// branched from
+func (s *apiServer) getConversation(w http.ResponseWriter, r *http.Request) {
+	store := s.agent.store
+	if store == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, errStorageUnavailable)
+		return
+	}
+	id := r.PathValue("id")
+	conv, err := store.GetConversation(id)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if conv == nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("conversation %s not found", id))
+		return
+	}
+	messages, err := store.GetThread(id)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, map[string]interface{}{"conversation": conv, "messages": messages})
+}
+
+// listTools handles GET /tools
+func (s *apiServer) listTools(w http.ResponseWriter, r *http.Request) {
+	tools := []apiTool{}
+	for _, tool := range s.agent.mcpRegistry.ListTools() {
+		tools = append(tools, apiTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Server:      tool.ServerName,
+			InputSchema: tool.InputSchema,
+		})
+	}
+	writeAPIResponse(w, http.StatusOK, map[string]interface{}{"tools": tools})
+}
+
+// executeTool handles POST /tools/execute. A tool that runs and fails is
+// reported with success false rather than an error status.
+func (s *apiServer) executeTool(w http.ResponseWriter, r *http.Request) {
+	var req toolExecuteRequest
+	if err := decodeAPIRequest(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Name == "" {
+		writeAPIError(w, http.StatusBadRequest, errors.New("name is required"))
+		return
+	}
+	if _, ok := s.agent.mcpRegistry.GetTool(req.Name); !ok {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("%w: %s", mcp.ErrToolNotFound, req.Name))
+		return
+	}
+	if req.Arguments == nil {
+		req.Arguments = map[string]interface{}{}
+	}
+
+	result, err := s.agent.ExecuteTool(r.Context(), req.Name, req.Arguments)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, toolExecuteResponse{
+		Tool:     result.ToolName,
+		Success:  result.Success,
+		Result:   result.Result,
+		Error:    result.Error,
+		Duration: result.Duration,
+	})
+}
+
+// decodeAPIRequest reads a JSON request body into v
+func decodeAPIRequest(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// queryInt reads a non-negative integer query parameter, or def when it is absent
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return n, nil
+}
+
+// writeAPIResponse writes v as a JSON response
+func writeAPIResponse(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIError writes err as a JSON error response
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeAPIResponse(w, status, map[string]string{"error": err.Error()})
+}
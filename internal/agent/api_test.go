@@ -0,0 +1,268 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedModel answers ChatWithTools with its responses in turn and keeps
+// the messages it was sent
+type scriptedModel struct {
+	MockModel
+	mu        sync.Mutex
+	responses []*model.Response
+	received  [][]model.Message
+}
+
+func (m *scriptedModel) ChatWithTools(ctx context.Context, messages []model.Message, tools []model.ToolDefinition, options model.GenerateOptions) (*model.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.received = append(m.received, messages)
+	if len(m.responses) == 0 {
+		return &model.Response{Content: "No more responses"}, nil
+	}
+	response := m.responses[0]
+	m.responses = m.responses[1:]
+	return response, nil
+}
+
+func newTestAPIAgent(t *testing.T, responses ...*model.Response) (*Agent, *scriptedModel) {
+	t.Helper()
+	dir := t.TempDir()
+	agent, err := New(&config.Config{
+		Model:   config.ModelConfig{Name: "test", Temperature: 0.7},
+		Storage: config.StorageConfig{DataDir: dir},
+		Memory:  config.MemoryConfig{Enabled: true, AutoRecall: true, RecallLimit: 3},
+		Logging: config.LoggingConfig{File: filepath.Join(dir, "test.log")},
+	})
+	require.NoError(t, err)
+	require.NoError(t, agent.openStore())
+	t.Cleanup(agent.closeStore)
+
+	m := &scriptedModel{responses: responses}
+	agent.SetModel(m)
+	return agent, m
+}
+
+func doAPIRequest(t *testing.T, handler http.Handler, method, path string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	var reader bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reader).Encode(body))
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, &reader))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded), recorder.Body.String())
+	return recorder.Code, decoded
+}
+
+func TestAPI_ChatSavesAndContinuesConversations(t *testing.T) {
+	agent, m := newTestAPIAgent(t,
+		&model.Response{Content: "Hello! How can I help?", Usage: model.Usage{PromptTokens: 12, CompletionTokens: 6}, Duration: time.Second},
+		&model.Response{Content: "You said hello.", Usage: model.Usage{PromptTokens: 30, CompletionTokens: 4}},
+	)
+	handler := agent.APIHandler("")
+
+	status, body := doAPIRequest(t, handler, "POST", "/chat", map[string]interface{}{"message": "Hello there"})
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "Hello! How can I help?", body["response"])
+	id, _ := body["conversation_id"].(string)
+	require.NotEmpty(t, id)
+	assert.Equal(t, float64(12), body["usage"].(map[string]interface{})["prompt_tokens"])
+
+	status, body = doAPIRequest(t, handler, "POST", "/chat", map[string]interface{}{"conversation_id": id, "message": "What did I say?", "temperature": 0.1})
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, id, body["conversation_id"])
+	require.Len(t, m.received, 2)
+	assert.Equal(t, []model.Message{
+		{Role: "user", Content: "Hello there"},
+		{Role: "assistant", Content: "Hello! How can I help?"},
+		{Role: "user", Content: "What did I say?"},
+	}, m.received[1], "Earlier messages are sent with the new one")
+
+	status, body = doAPIRequest(t, handler, "GET", "/conversations", nil)
+	require.Equal(t, http.StatusOK, status)
+	conversations := body["conversations"].([]interface{})
+	require.Len(t, conversations, 1)
+	assert.Equal(t, "Hello there", conversations[0].(map[string]interface{})["title"])
+
+	status, body = doAPIRequest(t, handler, "GET", "/conversations/"+id, nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.Len(t, body["messages"], 4)
+
+	stats, err := agent.store.SearchManager().ConversationStatistics(id)
+	require.NoError(t, err)
+	assert.Equal(t, 42, stats.TokensIn)
+	assert.Equal(t, 2, stats.ModelCalls)
+}
+
+func TestAPI_ChatRunsToolCalls(t *testing.T) {
+	agent, m := newTestAPIAgent(t, &model.Response{ToolCalls: []model.ToolCall{
+		{Name: "remember", Arguments: map[string]interface{}{"content": "Deploys happen on Tuesdays", "topic": "release"}},
+		{Name: "missing_tool", Arguments: map[string]interface{}{}},
+	}})
+	handler := agent.APIHandler("")
+
+	status, body := doAPIRequest(t, handler, "POST", "/chat", map[string]interface{}{"message": "Remember that deploys happen on Tuesdays"})
+	require.Equal(t, http.StatusOK, status, body)
+	calls := body["tool_calls"].([]interface{})
+	require.Len(t, calls, 2)
+	assert.Equal(t, "remember", calls[0].(map[string]interface{})["name"])
+	assert.Empty(t, calls[0].(map[string]interface{})["error"])
+	assert.Contains(t, calls[1].(map[string]interface{})["error"], "not found")
+	assert.Contains(t, body["response"], "I've executed several tools")
+
+	stats, err := agent.store.SearchManager().ConversationStatistics(body["conversation_id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.ToolCalls)
+
+	// The remembered fact is recalled with the next related message
+	doAPIRequest(t, handler, "POST", "/chat", map[string]interface{}{"message": "When do deploys happen?"})
+	require.Len(t, m.received, 2)
+	assert.Equal(t, "system", m.received[1][0].Role)
+	assert.Contains(t, m.received[1][0].Content, "[release] Deploys happen on Tuesdays")
+}
+
+func TestAPI_ExecuteTool(t *testing.T) {
+	agent, _ := newTestAPIAgent(t)
+	handler := agent.APIHandler("")
+
+	status, body := doAPIRequest(t, handler, "GET", "/tools", nil)
+	require.Equal(t, http.StatusOK, status)
+	names := []string{}
+	for _, tool := range body["tools"].([]interface{}) {
+		names = append(names, tool.(map[string]interface{})["name"].(string))
+	}
+	assert.Contains(t, names, "remember")
+
+	status, body = doAPIRequest(t, handler, "POST", "/tools/execute", map[string]interface{}{
+		"name": "remember", "arguments": map[string]interface{}{"content": "The office is in Lisbon"},
+	})
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, true, body["success"])
+	assert.Equal(t, "remember", body["tool"])
+
+	executions, err := agent.store.ListToolExecutions(storage.ToolExecutionFilter{})
+	require.NoError(t, err)
+	require.Len(t, executions, 1, "Tool executions are audited")
+
+	status, _ = doAPIRequest(t, handler, "POST", "/tools/execute", map[string]interface{}{"name": "nope"})
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestAPI_RejectsBadRequests(t *testing.T) {
+	agent, _ := newTestAPIAgent(t)
+	handler := agent.APIHandler("")
+
+	status, body := doAPIRequest(t, handler, "POST", "/chat", map[string]interface{}{"message": "  "})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "message is required", body["error"])
+
+	status, _ = doAPIRequest(t, handler, "POST", "/chat", map[string]interface{}{"prompt": "hi"})
+	assert.Equal(t, http.StatusBadRequest, status, "Unknown fields are rejected")
+
+	status, _ = doAPIRequest(t, handler, "POST", "/chat", map[string]interface{}{"conversation_id": "missing", "message": "hi"})
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = doAPIRequest(t, handler, "GET", "/conversations?limit=-1", nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	agent.closeStore()
+	status, body = doAPIRequest(t, handler, "GET", "/conversations", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "storage is unavailable", body["error"])
+	require.NoError(t, agent.openStore())
+}
+
+func TestAPI_RequiresToken(t *testing.T) {
+	agent, _ := newTestAPIAgent(t)
+	handler := agent.APIHandler("secret")
+
+	status, _ := doAPIRequest(t, handler, "GET", "/tools", nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/tools", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestAgent_ServeStopsWithContext(t *testing.T) {
+	dir := t.TempDir()
+	agent, err := New(&config.Config{
+		Storage: config.StorageConfig{DataDir: dir},
+		Logging: config.LoggingConfig{File: filepath.Join(dir, "test.log")},
+	})
+	require.NoError(t, err)
+	agent.SetModel(&scriptedModel{responses: []*model.Response{{Content: "pong"}}})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- agent.Serve(ctx, listener, "") }()
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = http.Post("http://"+listener.Addr().String()+"/chat", "application/json", bytes.NewBufferString(`{"message":"ping"}`))
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond)
+	var body ChatResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	resp.Body.Close()
+	assert.Equal(t, "pong", body.Response)
+	assert.NotEmpty(t, body.ConversationID)
+
+	cancel()
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after the context was cancelled")
+	}
+	assert.Nil(t, agent.store, "Storage is closed when the server stops")
+}
+
+func TestAgent_WarnsWhenContextFillsUp(t *testing.T) {
+	agent, m := newTestAPIAgent(t)
+	agent.SetModel(agent.WrapModel(m))
+	agent.config.Model.ContextLength = 1000
+	agent.config.Agent.Compaction.Threshold = 0.8
+	handler := agent.APIHandler("")
+	warnings := func() []string {
+		var texts []string
+		for _, event := range agent.bus.History() {
+			if e, ok := event.(events.NotificationEvent); ok {
+				texts = append(texts, e.Text)
+			}
+		}
+		return texts
+	}
+
+	for _, promptTokens := range []int{500, 900, 950, 100, 820} {
+		m.responses = append(m.responses, &model.Response{Content: "OK", Usage: model.Usage{PromptTokens: promptTokens}})
+		status, body := doAPIRequest(t, handler, "POST", "/chat", map[string]interface{}{"message": "Hello"})
+		require.Equal(t, http.StatusOK, status, body)
+	}
+	assert.Equal(t, []string{
+		"Context 90% full; older messages will be compacted",
+		"Context 82% full; older messages will be compacted",
+	}, warnings(), "The user is told once each time the context fills up")
+}
@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Ask answers question once without the TUI. Input, such as text piped to
+// othello ask, is sent after the question for the model to work with; either
+// may be empty, but not both. The model can call tools, whose results make up
+// the answer as they do in chat, and the exchange is saved as a new
+// conversation when storage is available.
+func (a *Agent) Ask(ctx context.Context, question, input string) (*ChatResult, error) {
+	text := askText(question, input)
+	if text == "" {
+		return nil, errors.New("question is required")
+	}
+	defer a.startHeadless()()
+	return a.respond(ctx, nil, nil, text, a.generateOptions())
+}
+
+// askText combines a question with the input it is about
+func askText(question, input string) string {
+	question = strings.TrimSpace(question)
+	input = strings.TrimRight(input, "\n")
+	if strings.TrimSpace(input) == "" {
+		return question
+	}
+	if question == "" {
+		return input
+	}
+	return question + "\n\nInput:\n```\n" + input + "\n```"
+}
@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingModel fails every chat request
+type failingModel struct {
+	MockModel
+}
+
+func (m *failingModel) ChatWithTools(ctx context.Context, messages []model.Message, tools []model.ToolDefinition, options model.GenerateOptions) (*model.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+// stalledModel never answers, returning only once the request is cancelled
+type stalledModel struct {
+	MockModel
+}
+
+func (m *stalledModel) ChatWithTools(ctx context.Context, messages []model.Message, tools []model.ToolDefinition, options model.GenerateOptions) (*model.Response, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newTestAskAgent(t *testing.T, m model.Model) (*Agent, string) {
+	t.Helper()
+	dir := t.TempDir()
+	agent, err := New(&config.Config{
+		Model:   config.ModelConfig{Name: "test"},
+		Storage: config.StorageConfig{DataDir: dir},
+		Memory:  config.MemoryConfig{Enabled: true},
+		Logging: config.LoggingConfig{File: filepath.Join(dir, "test.log")},
+	})
+	require.NoError(t, err)
+	agent.SetModel(m)
+	return agent, dir
+}
+
+func TestAgent_AskRunsToolsAndSaves(t *testing.T) {
+	agent, dir := newTestAskAgent(t, &scriptedModel{responses: []*model.Response{{
+		ToolCalls: []model.ToolCall{{Name: "remember", Arguments: map[string]interface{}{"content": "Backups run at 2am"}}},
+		Usage:     model.Usage{PromptTokens: 40, CompletionTokens: 8},
+	}}})
+
+	result, err := agent.Ask(context.Background(), "Remember that backups run at 2am", "")
+	require.NoError(t, err)
+	assert.Contains(t, result.Response, "Remembered")
+	require.Len(t, result.ToolCalls, 1)
+	assert.Empty(t, result.ToolCalls[0].Error)
+	assert.NotEmpty(t, result.ToolCalls[0].Duration)
+	require.NotEmpty(t, result.ConversationID)
+	assert.Nil(t, agent.store, "Storage is closed after answering")
+
+	path, err := storage.DatabasePath(dir)
+	require.NoError(t, err)
+	store, err := storage.NewConversationStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	stats, err := store.SearchManager().ConversationStatistics(result.ConversationID)
+	require.NoError(t, err)
+	require.NotNil(t, stats)
+	assert.Equal(t, "Remember that backups run at 2am", stats.Title)
+	assert.Equal(t, 40, stats.TokensIn)
+	assert.Equal(t, 1, stats.ToolCalls)
+}
+
+func TestAgent_AskReportsFailures(t *testing.T) {
+	agent, _ := newTestAskAgent(t, &failingModel{})
+
+	_, err := agent.Ask(context.Background(), " ", "\n")
+	assert.EqualError(t, err, "question is required")
+
+	_, err = agent.Ask(context.Background(), "Hello", "")
+	assert.ErrorContains(t, err, "model request failed: connection refused")
+}
+
+func TestAgent_AskSendsInputWithQuestion(t *testing.T) {
+	m := &scriptedModel{responses: []*model.Response{{Content: "The disk is full."}}}
+	agent, _ := newTestAskAgent(t, m)
+
+	result, err := agent.Ask(context.Background(), "explain this", "ERROR: no space left on device\n")
+	require.NoError(t, err)
+	assert.Equal(t, "The disk is full.", result.Response)
+	require.Len(t, m.received, 1)
+	last := m.received[0][len(m.received[0])-1]
+	assert.Equal(t, "user", last.Role)
+	assert.Equal(t, "explain this\n\nInput:\n```\nERROR: no space left on device\n```", last.Content)
+}
+
+func TestAskText(t *testing.T) {
+	assert.Equal(t, "What is Go?", askText(" What is Go? ", ""))
+	assert.Equal(t, "panic: nil map", askText("", "panic: nil map\n"))
+	assert.Equal(t, "", askText("", " \n"))
+}
+
+func TestAgent_AskFeedsToolResultsBack(t *testing.T) {
+	m := &scriptedModel{responses: []*model.Response{
+		{ToolCalls: []model.ToolCall{{Name: "lookup_fact", Arguments: map[string]interface{}{"query": "backups"}}}, Usage: model.Usage{PromptTokens: 40}},
+		{ToolCalls: []model.ToolCall{{Name: "remember", Arguments: map[string]interface{}{"content": "Backups run at 2am"}}}, Usage: model.Usage{PromptTokens: 60}},
+		{Content: "Saved: backups run at 2am.", Usage: model.Usage{PromptTokens: 80, CompletionTokens: 7}},
+	}}
+	agent, _ := newTestAskAgent(t, m)
+	agent.config.Agent.MaxIterations = 3
+
+	result, err := agent.Ask(context.Background(), "Remember that backups run at 2am", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Saved: backups run at 2am.", result.Response)
+	assert.Equal(t, 180, result.Usage.PromptTokens)
+	require.Len(t, result.ToolCalls, 2)
+	assert.NotEmpty(t, result.ToolCalls[0].Error, "The failed call is reported")
+	assert.Empty(t, result.ToolCalls[1].Error)
+
+	require.Len(t, m.received, 3)
+	retry := m.received[1]
+	assert.Contains(t, retry[len(retry)-2].Content, "Result of lookup_fact:\n❌ Tool lookup_fact failed")
+	assert.Equal(t, model.ToolReflectionPrompt, retry[len(retry)-1].Content)
+	assert.Contains(t, m.received[2][len(m.received[2])-2].Content, "Result of remember:")
+}
+
+func TestAgent_AskStopsAtMaxIterations(t *testing.T) {
+	call := &model.Response{ToolCalls: []model.ToolCall{{Name: "recall", Arguments: map[string]interface{}{"query": "deploys"}}}}
+	m := &scriptedModel{responses: []*model.Response{call, call, call}}
+	agent, _ := newTestAskAgent(t, m)
+	agent.config.Agent.MaxIterations = 1
+
+	result, err := agent.Ask(context.Background(), "What do you know about deploys?", "")
+	require.NoError(t, err)
+	assert.Len(t, m.received, 2)
+	assert.Len(t, result.ToolCalls, 2)
+	assert.Equal(t, result.ToolCalls[1].Result, result.Response, "The last results allowed are the answer")
+}
+
+func TestAgent_AskStopsWhenTurnRunsOutOfTime(t *testing.T) {
+	agent, dir := newTestAskAgent(t, nil)
+	agent.SetModel(agent.WrapModel(&stalledModel{}))
+	agent.config.Agent.TurnTimeout = 20 * time.Millisecond
+
+	_, err := agent.Ask(context.Background(), "What do you know about deploys?", "")
+	require.ErrorIs(t, err, model.ErrTurnTimeout)
+	assert.Contains(t, err.Error(), "agent.turn_timeout of 20ms")
+
+	log, err := os.ReadFile(filepath.Join(dir, "test.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(log), "Turn budget: ", "The budget left is logged before each model request")
+}
+
+func TestAgent_AskCompactsLongRequests(t *testing.T) {
+	m := &scriptedModel{responses: []*model.Response{
+		{ToolCalls: []model.ToolCall{{Name: "recall", Arguments: map[string]interface{}{"query": "deploys"}}}},
+		{Content: "Deploys happen on Tuesdays."},
+	}}
+	agent, _ := newTestAskAgent(t, m)
+	agent.config.Agent.MaxIterations = 1
+	agent.config.Model.ContextLength = 60
+	agent.config.Agent.Compaction = config.CompactionConfig{Threshold: 0.5, KeepRecent: 2}
+
+	result, err := agent.Ask(context.Background(), "What do you know about deploys?", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Deploys happen on Tuesdays.", result.Response)
+
+	require.Len(t, m.received, 2)
+	assert.Equal(t, "What do you know about deploys?", m.received[0][len(m.received[0])-1].Content, "Short requests are sent as they are")
+	review := m.received[1]
+	require.Len(t, review, 4)
+	assert.Regexp(t, `^Summary of earlier messages:\n`, review[0].Content)
+	assert.Contains(t, review[0].Content, "user: What do you know about deploys?")
+	assert.Equal(t, model.ToolReflectionPrompt, review[3].Content)
+}
@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"log"
+	"sync/atomic"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// auditor writes every tool execution to the audit log while a database is
+// open. Executions happen on background goroutines, so the store is swapped
+// atomically as it opens and closes.
+type auditor struct {
+	store  atomic.Pointer[storage.ConversationStore]
+	logger *log.Logger
+}
+
+// record saves one execution, or does nothing while no database is open
+func (au *auditor) record(record mcp.ExecutionRecord) {
+	store := au.store.Load()
+	if store == nil {
+		return
+	}
+	err := store.RecordToolExecution(&storage.ToolExecution{
+		Server:        record.Server,
+		Tool:          record.Tool,
+		ArgumentsHash: storage.HashArguments(record.Arguments),
+		Duration:      record.Duration,
+		Success:       record.Success,
+		ResultSize:    record.ResultSize,
+		Error:         record.Error,
+	})
+	if err != nil {
+		au.logger.Printf("Warning: Failed to record execution of %s in the audit log: %v", record.Tool, err)
+	}
+}
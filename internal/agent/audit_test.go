@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditor_RecordsWhileStoreIsOpen(t *testing.T) {
+	store, err := storage.NewConversationStore(filepath.Join(t.TempDir(), storage.DatabaseFile))
+	require.NoError(t, err)
+	defer store.Close()
+
+	au := &auditor{logger: log.New(io.Discard, "", 0)}
+	record := mcp.ExecutionRecord{
+		Server:     "memory",
+		Tool:       "search",
+		Arguments:  map[string]interface{}{"query": "go"},
+		Duration:   250 * time.Millisecond,
+		Success:    true,
+		ResultSize: 12,
+	}
+	au.record(record) // No store yet
+
+	au.store.Store(store)
+	au.record(record)
+
+	executions, err := store.ListToolExecutions(storage.ToolExecutionFilter{})
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, "memory", executions[0].Server)
+	assert.Equal(t, "search", executions[0].Tool)
+	assert.Equal(t, storage.HashArguments(record.Arguments), executions[0].ArgumentsHash)
+	assert.Equal(t, 250*time.Millisecond, executions[0].Duration)
+	assert.Equal(t, 12, executions[0].ResultSize)
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+const (
+	// bridgePostTimeout bounds one request posting a reply to a chat
+	// platform
+	bridgePostTimeout = 15 * time.Second
+
+	// bridgeSeenLimit is how many delivered messages a bridge remembers to
+	// answer each once
+	bridgeSeenLimit = 1000
+)
+
+// turnProgressKey is the context key of the function told what a turn is
+// doing
+type turnProgressKey struct{}
+
+// withTurnProgress returns ctx with report, which respond calls with a short
+// line on what the turn is doing each time it starts running tools
+func withTurnProgress(ctx context.Context, report func(status string)) context.Context {
+	return context.WithValue(ctx, turnProgressKey{}, report)
+}
+
+// reportTurnProgress tells the turn's progress function, if any, that it is
+// running calls
+func reportTurnProgress(ctx context.Context, calls []model.ToolCall) {
+	report, ok := ctx.Value(turnProgressKey{}).(func(string))
+	if !ok || len(calls) == 0 {
+		return
+	}
+	names := make([]string, len(calls))
+	for i, call := range calls {
+		names[i] = call.Name
+	}
+	report("Running " + strings.Join(names, ", ") + "…")
+}
+
+// chatBridge is what the Slack and Discord bridges share: a conversation per
+// channel whose messages are answered one at a time, in the background after
+// the platform's request has been acknowledged
+type chatBridge struct {
+	agent *Agent
+	ctx   context.Context // Cancelled when the bridge stops
+
+	mu       sync.Mutex
+	channels map[string]*sync.Mutex // Held while a channel's message is answered
+	seen     map[string]bool        // Messages already answered, as platforms may deliver one twice
+
+	turns sync.WaitGroup
+}
+
+func newChatBridge(ctx context.Context, a *Agent) *chatBridge {
+	return &chatBridge{
+		agent:    a,
+		ctx:      ctx,
+		channels: make(map[string]*sync.Mutex),
+		seen:     make(map[string]bool),
+	}
+}
+
+// firstDelivery reports whether the message key is delivered for the first
+// time
+func (b *chatBridge) firstDelivery(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.seen[key] {
+		return false
+	}
+	if len(b.seen) >= bridgeSeenLimit {
+		clear(b.seen)
+	}
+	b.seen[key] = true
+	return true
+}
+
+// answer runs fn in the background with the channel's conversation locked,
+// so that each message sees the answers to those before it
+func (b *chatBridge) answer(conversationID string, fn func(ctx context.Context)) {
+	b.mu.Lock()
+	lock, ok := b.channels[conversationID]
+	if !ok {
+		lock = &sync.Mutex{}
+		b.channels[conversationID] = lock
+	}
+	b.mu.Unlock()
+
+	b.turns.Add(1)
+	go func() {
+		defer b.turns.Done()
+		defer b.agent.crashes.recover("answering a chat message")
+		lock.Lock()
+		defer lock.Unlock()
+		fn(b.ctx)
+	}()
+}
+
+// wait waits for the messages being answered
+func (b *chatBridge) wait() {
+	b.turns.Wait()
+}
+
+// respond answers text sent to a channel, continuing the conversation saved
+// as conversationID and starting it when there is none. report is told what
+// the turn is doing as it runs tools.
+func (b *chatBridge) respond(ctx context.Context, conversationID, text string, report func(status string)) (*ChatResult, error) {
+	a := b.agent
+	var conv *storage.Conversation
+	var history []*storage.Message
+	if a.store != nil {
+		var err error
+		if conv, err = a.store.GetConversation(conversationID); err != nil {
+			return nil, err
+		}
+		if conv == nil {
+			if conv, err = a.store.CreateConversation(conversationID, apiConversationTitle(text)); err != nil {
+				return nil, err
+			}
+		} else if history, err = a.store.GetThread(conv.ID); err != nil {
+			return nil, err
+		}
+	}
+	return a.respond(withTurnProgress(ctx, report), conv, history, text, a.generateOptions())
+}
+
+// postContext returns a context for posting a reply, which outlives the
+// bridge's so that a message answered while stopping is still posted
+func (b *chatBridge) postContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(b.ctx), bridgePostTimeout)
+}
+
+// bridgeReply is the text posted for the answer to a message
+func bridgeReply(result *ChatResult, err error) string {
+	if err != nil {
+		return fmt.Sprintf("Sorry, I couldn't answer that: %v", err)
+	}
+	if strings.TrimSpace(result.Response) == "" {
+		return "(no response)"
+	}
+	return result.Response
+}
+
+// splitMessage splits text into pieces of at most limit runes, breaking at
+// the last newline of each piece when there is one
+func splitMessage(text string, limit int) []string {
+	var pieces []string
+	runes := []rune(text)
+	for len(runes) > limit {
+		cut := limit
+		if i := lastRune(runes[:limit], '\n'); i > limit/2 {
+			cut = i + 1
+		}
+		pieces = append(pieces, string(runes[:cut]))
+		runes = runes[cut:]
+	}
+	return append(pieces, string(runes))
+}
+
+// lastRune returns the index of the last r in runes, or -1
+func lastRune(runes []rune, r rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
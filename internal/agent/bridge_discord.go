@@ -0,0 +1,248 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+)
+
+const (
+	// discordAPI is the base URL of Discord's HTTP API
+	discordAPI = "https://discord.com/api/v10"
+
+	// discordMessageLimit is the most runes Discord takes in one message
+	discordMessageLimit = 2000
+)
+
+// Discord interaction and response types the bridge uses
+const (
+	discordPing               = 1
+	discordApplicationCommand = 2
+
+	discordPong            = 1
+	discordChannelMessage  = 4
+	discordDeferredMessage = 5
+
+	discordEphemeral = 1 << 6 // Message flag showing a reply only to who sent the command
+)
+
+// discordBridge answers the slash command serve.discord.command, which
+// Discord sends to the application's interactions endpoint, replying in the
+// channel it was used in
+type discordBridge struct {
+	*chatBridge
+	cfg config.DiscordBridgeConfig
+	key ed25519.PublicKey
+	api string // Base URL of the HTTP API
+}
+
+// discordInteraction is the part of an interaction the bridge reads
+type discordInteraction struct {
+	ID        string `json:"id"`
+	Type      int    `json:"type"`
+	Token     string `json:"token"`
+	ChannelID string `json:"channel_id"`
+	Data      struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Name  string      `json:"name"`
+			Value interface{} `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+}
+
+// ServeDiscord answers the Discord slash command serve.discord.command,
+// posted to listener as interactions, until ctx is done. Each channel has its
+// own saved conversation.
+func (a *Agent) ServeDiscord(ctx context.Context, listener net.Listener) error {
+	if err := CheckDiscordBridge(a.config); err != nil {
+		return err
+	}
+	bridge, err := newDiscordBridge(ctx, a, discordAPI)
+	if err != nil {
+		return err
+	}
+	return a.serveHTTP(ctx, listener, "Discord bridge", bridge, bridge.wait)
+}
+
+// CheckDiscordBridge returns why cfg can't run the Discord bridge, if it
+// can't
+func CheckDiscordBridge(cfg *config.Config) error {
+	if cfg.Serve.Discord.ApplicationID == "" || cfg.Serve.Discord.PublicKey == "" {
+		return errors.New("serve.discord.application_id and serve.discord.public_key are required")
+	}
+	if cfg.Agent.Offline {
+		return fmt.Errorf("%w: the Discord bridge posts replies to discord.com", ErrOffline)
+	}
+	return nil
+}
+
+func newDiscordBridge(ctx context.Context, a *Agent, api string) (*discordBridge, error) {
+	cfg := a.config.Serve.Discord
+	key, err := hex.DecodeString(cfg.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("serve.discord.public_key is not a hex Ed25519 public key")
+	}
+	return &discordBridge{chatBridge: newChatBridge(ctx, a), cfg: cfg, key: key, api: api}, nil
+}
+
+// ServeHTTP implements http.Handler. Discord expects an answer within three
+// seconds, so commands are acknowledged at once and answered in the
+// background.
+func (b *discordBridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBridgeRequest))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+	if !verifyDiscordRequest(b.key, r.Header, body) {
+		// Discord checks that endpoints refuse bad signatures before using them
+		http.Error(w, "invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	var interaction discordInteraction
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case interaction.Type == discordPing:
+		writeDiscordResponse(w, map[string]interface{}{"type": discordPong})
+	case interaction.Type != discordApplicationCommand || interaction.Data.Name != b.cfg.Command:
+		writeDiscordResponse(w, discordNotice("Othello doesn't know this command."))
+	case len(b.cfg.Channels) > 0 && !slices.Contains(b.cfg.Channels, interaction.ChannelID):
+		writeDiscordResponse(w, discordNotice("Othello doesn't answer in this channel."))
+	default:
+		text := strings.TrimSpace(commandText(interaction))
+		if text == "" {
+			writeDiscordResponse(w, discordNotice("Add a message to the command."))
+			return
+		}
+		writeDiscordResponse(w, map[string]interface{}{"type": discordDeferredMessage})
+		if !b.firstDelivery(interaction.ID) {
+			return
+		}
+		conversationID := "discord_" + interaction.ChannelID
+		b.answer(conversationID, func(ctx context.Context) {
+			b.reply(ctx, conversationID, interaction.Token, text)
+		})
+	}
+}
+
+// commandText returns the text of a command's first string option
+func commandText(interaction discordInteraction) string {
+	for _, option := range interaction.Data.Options {
+		if text, ok := option.Value.(string); ok {
+			return text
+		}
+	}
+	return ""
+}
+
+// reply answers text in the response to the interaction whose token is
+// given: Discord shows that Othello is thinking until the response is first
+// edited, with progress and then the answer, which continues in follow-up
+// messages when it is long. The question is quoted first, since Discord
+// doesn't show the command's options.
+func (b *discordBridge) reply(ctx context.Context, conversationID, token, text string) {
+	quoted := "> " + strings.ReplaceAll(text, "\n", "\n> ")
+	if runes := []rune(quoted); len(runes) > discordMessageLimit/4 {
+		quoted = string(runes[:discordMessageLimit/4]) + "…"
+	}
+	report := func(status string) {
+		b.send(http.MethodPatch, token, quoted+"\n\n*"+status+"*")
+	}
+
+	pieces := splitMessage(quoted+"\n\n"+bridgeReply(b.respond(ctx, conversationID, text, report)), discordMessageLimit)
+	err := b.send(http.MethodPatch, token, pieces[0])
+	for _, piece := range pieces[1:] {
+		if err != nil {
+			break
+		}
+		err = b.send(http.MethodPost, token, piece)
+	}
+	if err != nil {
+		b.agent.logger.Printf("Failed to post the answer to Discord: %v", err)
+	}
+}
+
+// send edits the response to an interaction with PATCH, or adds a follow-up
+// message to it with POST. Mentions in content don't notify anyone.
+func (b *discordBridge) send(method, token, content string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"content":          content,
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := b.api + "/webhooks/" + b.cfg.ApplicationID + "/" + token
+	if method == http.MethodPatch {
+		endpoint += "/messages/@original"
+	}
+	ctx, cancel := b.postContext()
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The error's URL holds the interaction's token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Discord answered %s: %s", resp.Status, firstLine(detail))
+	}
+	return nil
+}
+
+// discordNotice is a response only the user who sent the command sees
+func discordNotice(content string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": discordChannelMessage,
+		"data": map[string]interface{}{"content": content, "flags": discordEphemeral},
+	}
+}
+
+// writeDiscordResponse answers an interaction with response
+func writeDiscordResponse(w http.ResponseWriter, response map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// verifyDiscordRequest reports whether body was sent by Discord:
+// X-Signature-Ed25519 must be the signature of the timestamp and body by the
+// application's key
+func verifyDiscordRequest(key ed25519.PublicKey, header http.Header, body []byte) bool {
+	signature, err := hex.DecodeString(header.Get("X-Signature-Ed25519"))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return false
+	}
+	message := append([]byte(header.Get("X-Signature-Timestamp")), body...)
+	return ed25519.Verify(key, message, signature)
+}
@@ -0,0 +1,266 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+)
+
+const (
+	// slackAPI is the base URL of Slack's Web API
+	slackAPI = "https://slack.com/api"
+
+	// slackMaxAge is how old a request from Slack may be, going by its
+	// timestamp, before it is refused as a replay
+	slackMaxAge = 5 * time.Minute
+
+	// slackMessageLimit is the most runes posted in one Slack message
+	slackMessageLimit = 3900
+
+	// maxBridgeRequest bounds the body of a request from a chat platform
+	maxBridgeRequest = 1 << 20
+)
+
+// slackMention matches mentions of users, such as the app's, in a message
+var slackMention = regexp.MustCompile(`<@[A-Z0-9]+>`)
+
+// slackBridge answers messages Slack sends through the Events API, replying
+// in the channel they were sent to
+type slackBridge struct {
+	*chatBridge
+	cfg config.SlackBridgeConfig
+	api string // Base URL of the Web API
+}
+
+// slackRequest is the part of an Events API request the bridge reads
+type slackRequest struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	TeamID    string `json:"team_id"`
+	Event     struct {
+		Type     string `json:"type"`
+		Subtype  string `json:"subtype"`
+		BotID    string `json:"bot_id"`
+		Text     string `json:"text"`
+		Channel  string `json:"channel"`
+		TS       string `json:"ts"`
+		ThreadTS string `json:"thread_ts"`
+	} `json:"event"`
+}
+
+// ServeSlack answers the messages of Slack channels the app is in, posted
+// to listener by the Events API, until ctx is done. Each channel has its own
+// saved conversation.
+func (a *Agent) ServeSlack(ctx context.Context, listener net.Listener) error {
+	if err := CheckSlackBridge(a.config); err != nil {
+		return err
+	}
+	bridge := newSlackBridge(ctx, a, slackAPI)
+	return a.serveHTTP(ctx, listener, "Slack bridge", bridge, bridge.wait)
+}
+
+// CheckSlackBridge returns why cfg can't run the Slack bridge, if it can't
+func CheckSlackBridge(cfg *config.Config) error {
+	if cfg.Serve.Slack.BotToken == "" || cfg.Serve.Slack.SigningSecret == "" {
+		return errors.New("serve.slack.bot_token and serve.slack.signing_secret are required")
+	}
+	if cfg.Agent.Offline {
+		return fmt.Errorf("%w: the Slack bridge posts replies to slack.com", ErrOffline)
+	}
+	return nil
+}
+
+func newSlackBridge(ctx context.Context, a *Agent, api string) *slackBridge {
+	return &slackBridge{chatBridge: newChatBridge(ctx, a), cfg: a.config.Serve.Slack, api: api}
+}
+
+// ServeHTTP implements http.Handler. Slack expects an answer within three
+// seconds, so messages are answered in the background.
+func (b *slackBridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBridgeRequest))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+	if err := verifySlackRequest(b.cfg.SigningSecret, r.Header, body, time.Now()); err != nil {
+		b.agent.logger.Printf("Refused a Slack request: %v", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var req slackRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	switch req.Type {
+	case "url_verification":
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, req.Challenge)
+		return
+	case "event_callback":
+		b.handleEvent(req)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleEvent starts answering the message of an event, unless it is one the
+// bridge ignores: edits, messages from bots, and channels not in
+// serve.slack.channels. A message that mentions the app arrives as both a
+// message and an app_mention event, and is answered once.
+func (b *slackBridge) handleEvent(req slackRequest) {
+	event := req.Event
+	if event.Type != "message" && event.Type != "app_mention" {
+		return
+	}
+	if event.Subtype != "" || event.BotID != "" {
+		return
+	}
+	if len(b.cfg.Channels) > 0 && !slices.Contains(b.cfg.Channels, event.Channel) {
+		return
+	}
+	text := strings.TrimSpace(slackMention.ReplaceAllString(event.Text, ""))
+	if text == "" || !b.firstDelivery(event.Channel+"/"+event.TS) {
+		return
+	}
+
+	conversationID := "slack_" + req.TeamID + "_" + event.Channel
+	b.answer(conversationID, func(ctx context.Context) {
+		b.reply(ctx, conversationID, event.Channel, event.ThreadTS, text)
+	})
+}
+
+// reply answers text in channel, in the thread it was sent in if any. A
+// placeholder is posted first and edited as the answer progresses.
+func (b *slackBridge) reply(ctx context.Context, conversationID, channel, thread, text string) {
+	logger := b.agent.logger
+	ts, err := b.post(channel, thread, "_Thinking…_")
+	if err != nil {
+		logger.Printf("Failed to post to Slack channel %s: %v", channel, err)
+	}
+	report := func(status string) {
+		if ts != "" {
+			b.update(channel, ts, "_"+status+"_")
+		}
+	}
+
+	pieces := splitMessage(bridgeReply(b.respond(ctx, conversationID, text, report)), slackMessageLimit)
+	if ts == "" {
+		ts, err = b.post(channel, thread, pieces[0])
+	} else {
+		err = b.update(channel, ts, pieces[0])
+	}
+	for _, piece := range pieces[1:] {
+		if err != nil {
+			break
+		}
+		_, err = b.post(channel, thread, piece)
+	}
+	if err != nil {
+		logger.Printf("Failed to post the answer to Slack channel %s: %v", channel, err)
+	}
+}
+
+// post posts text to channel and returns the timestamp Slack identifies the
+// message with
+func (b *slackBridge) post(channel, thread, text string) (string, error) {
+	var resp struct {
+		TS string `json:"ts"`
+	}
+	args := map[string]string{"channel": channel, "text": text}
+	if thread != "" {
+		args["thread_ts"] = thread
+	}
+	err := b.call("chat.postMessage", args, &resp)
+	return resp.TS, err
+}
+
+// update replaces the text of the message ts in channel
+func (b *slackBridge) update(channel, ts, text string) error {
+	return b.call("chat.update", map[string]string{"channel": channel, "ts": ts, "text": text}, nil)
+}
+
+// call calls the Web API method with args and decodes its answer into result
+func (b *slackBridge) call(method string, args map[string]string, result interface{}) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := b.postContext()
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.api+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+b.cfg.BotToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxBridgeRequest))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %s", method, resp.Status)
+	}
+	// Slack reports failures as ok: false with a 200
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &status); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", method, err)
+	}
+	if !status.OK {
+		return fmt.Errorf("%s: %s", method, status.Error)
+	}
+	if result != nil {
+		return json.Unmarshal(respBody, result)
+	}
+	return nil
+}
+
+// verifySlackRequest checks that body was sent by Slack: X-Slack-Signature
+// must be the HMAC-SHA256 of the version, timestamp, and body keyed with the
+// signing secret, and the timestamp recent
+func verifySlackRequest(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("missing timestamp")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > slackMaxAge || age < -slackMaxAge {
+		return errors.New("stale timestamp")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// platformCall is a request a bridge made to a chat platform's API
+type platformCall struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// fakePlatform records the requests made to it and answers them with reply
+type fakePlatform struct {
+	mu    sync.Mutex
+	calls []platformCall
+	reply string
+}
+
+func (p *fakePlatform) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	p.mu.Lock()
+	p.calls = append(p.calls, platformCall{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: body})
+	p.mu.Unlock()
+	io.WriteString(w, p.reply)
+}
+
+func (p *fakePlatform) recorded() []platformCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]platformCall(nil), p.calls...)
+}
+
+// signedSlackRequest is a request to the Slack bridge signed with secret
+func signedSlackRequest(t *testing.T, secret string, payload interface{}) *http.Request {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func slackMessage(eventType, channel, ts, text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "event_callback",
+		"team_id": "T1",
+		"event":   map[string]interface{}{"type": eventType, "channel": channel, "ts": ts, "text": text},
+	}
+}
+
+func TestVerifySlackRequest(t *testing.T) {
+	req := signedSlackRequest(t, "s3cr3t", map[string]string{"type": "event_callback"})
+	body, _ := io.ReadAll(req.Body)
+	now := time.Now()
+
+	assert.NoError(t, verifySlackRequest("s3cr3t", req.Header, body, now))
+	assert.Error(t, verifySlackRequest("other", req.Header, body, now))
+	assert.Error(t, verifySlackRequest("s3cr3t", req.Header, append(body, ' '), now))
+	assert.ErrorContains(t, verifySlackRequest("s3cr3t", req.Header, body, now.Add(10*time.Minute)), "stale")
+	assert.ErrorContains(t, verifySlackRequest("s3cr3t", http.Header{}, body, now), "missing timestamp")
+}
+
+func TestSlackBridge_AnswersEachChannelInItsConversation(t *testing.T) {
+	agent, m := newTestAPIAgent(t,
+		&model.Response{ToolCalls: []model.ToolCall{{Name: "remember", Arguments: map[string]interface{}{"content": "Deploys happen on Fridays"}}}},
+		&model.Response{Content: "On Fridays."},
+	)
+	agent.config.Serve.Slack = config.SlackBridgeConfig{BotToken: "xoxb-test", SigningSecret: "s3cr3t"}
+	slack := &fakePlatform{reply: `{"ok": true, "ts": "1700000000.000100"}`}
+	api := httptest.NewServer(slack)
+	defer api.Close()
+	bridge := newSlackBridge(context.Background(), agent, api.URL)
+
+	send := func(payload interface{}) int {
+		recorder := httptest.NewRecorder()
+		bridge.ServeHTTP(recorder, signedSlackRequest(t, "s3cr3t", payload))
+		return recorder.Code
+	}
+	assert.Equal(t, http.StatusOK, send(slackMessage("message", "C1", "1.1", "Remember that deploys happen on Fridays")))
+	// The same message as a mention, and messages from bots, aren't answered
+	assert.Equal(t, http.StatusOK, send(slackMessage("app_mention", "C1", "1.1", "<@U0BOT> Remember that deploys happen on Fridays")))
+	bot := slackMessage("message", "C1", "1.2", "Beep")
+	bot["event"].(map[string]interface{})["bot_id"] = "B1"
+	assert.Equal(t, http.StatusOK, send(bot))
+	bridge.wait()
+	assert.Equal(t, http.StatusOK, send(slackMessage("app_mention", "C1", "1.3", "<@U0BOT> When do deploys happen?")))
+	bridge.wait()
+
+	var updates []string
+	for _, call := range slack.recorded() {
+		assert.Equal(t, "Bearer xoxb-test", call.Header.Get("Authorization"))
+		assert.Equal(t, "C1", call.Body["channel"])
+		updates = append(updates, call.Path+" "+call.Body["text"].(string))
+	}
+	assert.Equal(t, []string{
+		"/chat.postMessage _Thinking…_",
+		"/chat.update _Running remember…_",
+		"/chat.update Remembered #1 under general (importance 5): Deploys happen on Fridays",
+		"/chat.postMessage _Thinking…_",
+		"/chat.update On Fridays.",
+	}, updates)
+
+	thread, err := agent.store.GetThread("slack_T1_C1")
+	require.NoError(t, err)
+	require.Len(t, thread, 5)
+	assert.Equal(t, "When do deploys happen?", thread[3].Content, "The mention is left out")
+
+	// The second message was sent with the first exchange
+	require.Len(t, m.received, 2)
+	var history []string
+	for _, msg := range m.received[1] {
+		history = append(history, msg.Content)
+	}
+	assert.Contains(t, history, "Remember that deploys happen on Fridays")
+}
+
+func TestSlackBridge_RefusesUnsignedRequests(t *testing.T) {
+	agent, _ := newTestAPIAgent(t)
+	agent.config.Serve.Slack = config.SlackBridgeConfig{BotToken: "xoxb-test", SigningSecret: "s3cr3t"}
+	bridge := newSlackBridge(context.Background(), agent, "http://127.0.0.1:0")
+
+	recorder := httptest.NewRecorder()
+	bridge.ServeHTTP(recorder, signedSlackRequest(t, "guess", slackMessage("message", "C1", "1.1", "Hi")))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	bridge.ServeHTTP(recorder, signedSlackRequest(t, "s3cr3t", map[string]string{"type": "url_verification", "challenge": "abc123"}))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "abc123", recorder.Body.String())
+}
+
+func TestDiscordBridge_AnswersTheCommand(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	agent, _ := newTestAPIAgent(t, &model.Response{Content: "Hello from Othello"})
+	agent.config.Serve.Discord = config.DiscordBridgeConfig{ApplicationID: "app1", PublicKey: hex.EncodeToString(public), Command: "ask", Channels: []string{"C1"}}
+	discord := &fakePlatform{reply: `{}`}
+	api := httptest.NewServer(discord)
+	defer api.Close()
+	bridge, err := newDiscordBridge(context.Background(), agent, api.URL)
+	require.NoError(t, err)
+
+	send := func(key ed25519.PrivateKey, interaction map[string]interface{}) map[string]interface{} {
+		body, err := json.Marshal(interaction)
+		require.NoError(t, err)
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("X-Signature-Timestamp", timestamp)
+		req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(key, append([]byte(timestamp), body...))))
+		recorder := httptest.NewRecorder()
+		bridge.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusOK {
+			return map[string]interface{}{"status": float64(recorder.Code)}
+		}
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		return response
+	}
+	command := func(name, channel string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": "i-" + channel, "type": 2, "token": "tok", "channel_id": channel,
+			"data": map[string]interface{}{"name": name, "options": []map[string]interface{}{{"name": "message", "type": 3, "value": "Say hello"}}},
+		}
+	}
+
+	_, other, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	assert.Equal(t, float64(http.StatusUnauthorized), send(other, map[string]interface{}{"type": 1})["status"])
+	assert.Equal(t, float64(1), send(private, map[string]interface{}{"type": 1})["type"], "PING is answered with PONG")
+	assert.Equal(t, float64(4), send(private, command("weather", "C1"))["type"], "Other commands get a notice")
+	assert.Equal(t, float64(4), send(private, command("ask", "C2"))["type"], "Other channels get a notice")
+	assert.Equal(t, float64(5), send(private, command("ask", "C1"))["type"], "The answer is deferred")
+	bridge.wait()
+
+	calls := discord.recorded()
+	require.Len(t, calls, 1)
+	assert.Equal(t, http.MethodPatch, calls[0].Method)
+	assert.Equal(t, "/webhooks/app1/tok/messages/@original", calls[0].Path)
+	assert.Equal(t, "> Say hello\n\nHello from Othello", calls[0].Body["content"])
+	assert.Equal(t, map[string]interface{}{"parse": []interface{}{}}, calls[0].Body["allowed_mentions"])
+
+	conv, err := agent.store.GetConversation("discord_C1")
+	require.NoError(t, err)
+	require.NotNil(t, conv)
+	assert.Equal(t, "Say hello", conv.Title)
+}
+
+func TestCheckBridges(t *testing.T) {
+	cfg := &config.Config{}
+	assert.ErrorContains(t, CheckSlackBridge(cfg), "serve.slack.bot_token")
+	assert.ErrorContains(t, CheckDiscordBridge(cfg), "serve.discord.application_id")
+
+	cfg.Serve.Slack = config.SlackBridgeConfig{BotToken: "xoxb-test", SigningSecret: "s3cr3t"}
+	assert.NoError(t, CheckSlackBridge(cfg))
+	cfg.Agent.Offline = true
+	assert.True(t, errors.Is(CheckSlackBridge(cfg), ErrOffline))
+}
+
+func TestSplitMessage(t *testing.T) {
+	assert.Equal(t, []string{"short"}, splitMessage("short", 10))
+	assert.Equal(t, []string{"line one\n", "line two"}, splitMessage("line one\nline two", 12))
+	pieces := splitMessage(strings.Repeat("é", 25), 10)
+	assert.Equal(t, []string{strings.Repeat("é", 10), strings.Repeat("é", 10), strings.Repeat("é", 5)}, pieces)
+}
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// chatPromptHistory is how many earlier prompts a chat session recalls
+const chatPromptHistory = 500
+
+// ChatSession is a conversation held without the TUI, as `othello chat
+// --plain` holds one. Each message is answered like a message in the TUI, and
+// the exchanges are saved to one conversation when storage is available.
+type ChatSession struct {
+	agent   *Agent
+	conv    *storage.Conversation
+	history []*storage.Message
+	stop    func()
+}
+
+// NewChatSession starts a conversation without the TUI, or continues the
+// saved conversation conversationID when it isn't empty. Close the session
+// when done.
+func (a *Agent) NewChatSession(conversationID string) (*ChatSession, error) {
+	s := &ChatSession{agent: a, stop: a.startHeadless()}
+	if conversationID == "" {
+		return s, nil
+	}
+	if err := s.load(conversationID); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load continues the saved conversation id
+func (s *ChatSession) load(id string) error {
+	store := s.agent.store
+	if store == nil {
+		return errStorageUnavailable
+	}
+	conv, err := store.GetConversation(id)
+	if err != nil {
+		return err
+	}
+	if conv == nil {
+		return fmt.Errorf("conversation %s not found", id)
+	}
+	history, err := store.GetThread(conv.ID)
+	if err != nil {
+		return err
+	}
+	s.conv, s.history = conv, history
+	return nil
+}
+
+// ConversationID returns the ID of the saved conversation, or "" before the
+// first exchange is saved
+func (s *ChatSession) ConversationID() string {
+	if s.conv == nil {
+		return ""
+	}
+	return s.conv.ID
+}
+
+// Send answers text, running any tools the model calls, and adds the
+// exchange to the conversation
+func (s *ChatSession) Send(ctx context.Context, text string) (*ChatResult, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("message is required")
+	}
+	a := s.agent
+	if a.store != nil {
+		if err := a.store.AddPrompt(text); err != nil {
+			a.logger.Printf("Warning: Failed to save prompt: %v", err)
+		}
+	}
+
+	reply, err := a.respond(ctx, s.conv, s.history, text, a.generateOptions())
+	if err != nil {
+		return nil, err
+	}
+	s.history = append(s.history,
+		&storage.Message{Role: "user", Content: text},
+		&storage.Message{Role: "assistant", Content: reply.Response})
+	if s.conv == nil && reply.ConversationID != "" {
+		conv, err := a.store.GetConversation(reply.ConversationID)
+		if err != nil || conv == nil {
+			conv = &storage.Conversation{ID: reply.ConversationID}
+		}
+		s.conv = conv
+	}
+	return reply, nil
+}
+
+// RecentPrompts returns the prompts typed earlier, in the TUI or a chat
+// session, oldest first
+func (s *ChatSession) RecentPrompts() []string {
+	if s.agent.store == nil {
+		return nil
+	}
+	prompts, err := s.agent.store.RecentPrompts(chatPromptHistory)
+	if err != nil {
+		s.agent.logger.Printf("Warning: Failed to load prompt history: %v", err)
+		return nil
+	}
+	return prompts
+}
+
+// Close ends the session and closes storage
+func (s *ChatSession) Close() {
+	if s.stop != nil {
+		s.stop()
+		s.stop = nil
+	}
+}
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatSession_KeepsOneConversation(t *testing.T) {
+	m := &scriptedModel{responses: []*model.Response{{Content: "Hi, Ada."}, {Content: "Your name is Ada."}}}
+	agent, _ := newTestAskAgent(t, m)
+
+	session, err := agent.NewChatSession("")
+	require.NoError(t, err)
+	first, err := session.Send(context.Background(), "My name is Ada")
+	require.NoError(t, err)
+	assert.Equal(t, "Hi, Ada.", first.Response)
+	require.NotEmpty(t, session.ConversationID())
+
+	second, err := session.Send(context.Background(), "What is my name?")
+	require.NoError(t, err)
+	assert.Equal(t, first.ConversationID, second.ConversationID, "Exchanges are saved to one conversation")
+	sent := m.received[len(m.received)-1]
+	var contents []string
+	for _, msg := range sent {
+		contents = append(contents, msg.Content)
+	}
+	assert.Subset(t, contents, []string{"My name is Ada", "Hi, Ada.", "What is my name?"}, "Earlier messages are sent")
+	assert.Equal(t, []string{"My name is Ada", "What is my name?"}, session.RecentPrompts())
+
+	_, err = session.Send(context.Background(), "  ")
+	assert.EqualError(t, err, "message is required")
+	session.Close()
+	assert.Nil(t, agent.store, "Closing the session closes storage")
+
+	resumed, err := agent.NewChatSession(first.ConversationID)
+	require.NoError(t, err)
+	assert.Len(t, resumed.history, 4, "A saved conversation is continued")
+	resumed.Close()
+
+	_, err = agent.NewChatSession("conv_missing")
+	assert.EqualError(t, err, "conversation conv_missing not found")
+}
@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/tui"
+)
+
+// MissingParameters lists the required parameters of call that are absent,
+// null, or blank, in the order the tool's schema requires them, so the chat
+// can ask the user for them rather than fail. It implements
+// tui.ParameterChecker.
+func (a *Agent) MissingParameters(call model.ToolCall) []tui.MissingParameter {
+	tool, ok := a.mcpRegistry.GetTool(call.Name)
+	if !ok || tool.InputSchema == nil {
+		return nil
+	}
+	schema := tool.Schema()
+	var missing []tui.MissingParameter
+	for _, name := range schema.Required {
+		if !isBlank(call.Arguments[name]) {
+			continue
+		}
+		parameter := tui.MissingParameter{Name: name}
+		if property := schema.Properties[name]; property != nil {
+			parameter.Type, _ = property["type"].(string)
+			parameter.Description, _ = property["description"].(string)
+			parameter.Choices = schemaEnum(property)
+		}
+		missing = append(missing, parameter)
+	}
+	return missing
+}
+
+// schemaEnum returns the allowed values of a parameter that are strings
+func schemaEnum(schema map[string]interface{}) []string {
+	values, _ := schema["enum"].([]interface{})
+	var choices []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			choices = append(choices, s)
+		}
+	}
+	return choices
+}
+
+// isBlank reports whether an argument's value is missing: absent, null, or
+// a string of only spaces, which small models send when they don't know it
+func isBlank(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	}
+	return false
+}
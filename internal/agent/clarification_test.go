@@ -0,0 +1,19 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/tui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgent_MissingParameters(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+
+	missing := agent.MissingParameters(model.ToolCall{Name: "remember", Arguments: map[string]interface{}{"content": "  ", "importance": 3}})
+	assert.Equal(t, []tui.MissingParameter{{Name: "content", Type: "string", Description: "The fact to remember, as a complete sentence"}}, missing)
+
+	assert.Empty(t, agent.MissingParameters(model.ToolCall{Name: "remember", Arguments: map[string]interface{}{"content": "Deploys are on Tuesdays"}}))
+	assert.Empty(t, agent.MissingParameters(model.ToolCall{Name: "no_such_tool"}))
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// clipboardServerName is the name the clipboard tools are registered under
+const clipboardServerName = "othello-clipboard"
+
+// clipboardTools describes the tools clipboardClient offers
+var clipboardTools = []mcp.Tool{
+	{
+		Name:        "read_clipboard",
+		Description: "Return the text on the user's clipboard, such as something they copied and want summarized, explained, or rewritten.",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		},
+	},
+	{
+		Name:        "write_clipboard",
+		Description: "Put text on the user's clipboard, replacing what is there, so they can paste it elsewhere.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"text": map[string]interface{}{"type": "string", "description": "Text to copy to the clipboard"},
+			},
+			"required": []interface{}{"text"},
+		},
+	},
+}
+
+// isClipboardTool reports whether name is one of the clipboard tools
+func isClipboardTool(name string) bool {
+	for _, tool := range clipboardTools {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// clipboardClient reads and sets the system clipboard for the model. It is
+// an in-process mcp.Client, so its calls are validated, checked against tool
+// policies, and audited like those of any MCP server. Unless configured
+// otherwise, the clipboard is only used once the user has approved the call.
+type clipboardClient struct {
+	config config.ClipboardToolConfig
+	read   func() (string, error)
+	write  func(text string) error
+}
+
+func (c *clipboardClient) Connect(ctx context.Context) error    { return nil }
+func (c *clipboardClient) Disconnect(ctx context.Context) error { return nil }
+func (c *clipboardClient) IsConnected() bool                    { return true }
+func (c *clipboardClient) GetTransport() string                 { return "builtin" }
+
+// ListTools implements mcp.Client
+func (c *clipboardClient) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	return clipboardTools, nil
+}
+
+// GetInfo implements mcp.Client
+func (c *clipboardClient) GetInfo(ctx context.Context) (*mcp.ServerInfo, error) {
+	return &mcp.ServerInfo{Name: clipboardServerName, Version: "1.0.0"}, nil
+}
+
+// CallTool implements mcp.Client. Refused and failed calls are reported as
+// error results so the model can see why.
+func (c *clipboardClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (*mcp.ToolResult, error) {
+	text, err := c.run(ctx, name, params)
+	if err != nil {
+		return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: text}}}, nil
+}
+
+// run checks a clipboard tool call and makes it
+func (c *clipboardClient) run(ctx context.Context, name string, params map[string]interface{}) (string, error) {
+	if !isClipboardTool(name) {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	if c.config.RequireApproval && !model.CallApproved(ctx, name, params) {
+		return "", fmt.Errorf("%s needs the user's approval, which can only be given in the chat; set tools.clipboard.require_approval to false to use the clipboard without it", name)
+	}
+
+	if name == "write_clipboard" {
+		text, _ := params["text"].(string)
+		if err := c.write(text); err != nil {
+			return "", fmt.Errorf("set the clipboard: %w", err)
+		}
+		return fmt.Sprintf("Copied %d characters to the clipboard.", len([]rune(text))), nil
+	}
+
+	text, err := c.read()
+	if err != nil {
+		return "", fmt.Errorf("read the clipboard: %w", err)
+	}
+	if strings.TrimSpace(text) == "" {
+		return "The clipboard is empty.", nil
+	}
+	if runes := []rune(text); len(runes) > c.config.MaxRead {
+		text = fmt.Sprintf("%s\n\n[%d more characters were cut]", string(runes[:c.config.MaxRead]), len(runes)-c.config.MaxRead)
+	}
+	return text, nil
+}
+
+// registerClipboard offers the clipboard tools to the model when they are
+// enabled and the system has a clipboard they can use
+func (a *Agent) registerClipboard() {
+	if !a.config.Tools.Clipboard.Enabled {
+		return
+	}
+	if clipboard.Unsupported {
+		a.logger.Printf("Clipboard tools unavailable: no clipboard program found (install xclip, xsel, or wl-clipboard)")
+		return
+	}
+	client := &clipboardClient{config: a.config.Tools.Clipboard, read: clipboard.ReadAll, write: clipboard.WriteAll}
+	if err := a.mcpRegistry.RegisterServer(clipboardServerName, client); err != nil {
+		a.logger.Printf("Warning: Failed to register the clipboard tools: %v", err)
+	}
+}
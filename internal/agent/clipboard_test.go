@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClipboardClient returns a clipboard client using the string
+// clipboard points to instead of the system's
+func newTestClipboardClient(clipboard *string) *clipboardClient {
+	return &clipboardClient{
+		config: config.ClipboardToolConfig{Enabled: true, RequireApproval: true, MaxRead: 100},
+		read:   func() (string, error) { return *clipboard, nil },
+		write:  func(text string) error { *clipboard = text; return nil },
+	}
+}
+
+// useClipboard calls the clipboard tool name with params, approved by the user
+func useClipboard(t *testing.T, client *clipboardClient, name string, params map[string]interface{}) (string, bool) {
+	t.Helper()
+	ctx := model.WithApprovedCalls(context.Background(), []model.ToolCall{{Name: name, Arguments: params}})
+	result, err := client.CallTool(ctx, name, params)
+	require.NoError(t, err)
+	return resultText(t, result), result.IsError
+}
+
+func TestClipboardClient_ReadsAndWrites(t *testing.T) {
+	clipboard := "Meeting moved to 3pm"
+	client := newTestClipboardClient(&clipboard)
+
+	text, isError := useClipboard(t, client, "read_clipboard", map[string]interface{}{})
+	assert.False(t, isError)
+	assert.Equal(t, "Meeting moved to 3pm", text)
+
+	text, isError = useClipboard(t, client, "write_clipboard", map[string]interface{}{"text": "Résumé"})
+	assert.False(t, isError)
+	assert.Equal(t, "Copied 6 characters to the clipboard.", text)
+	assert.Equal(t, "Résumé", clipboard)
+
+	clipboard = strings.Repeat("a", 150)
+	text, _ = useClipboard(t, client, "read_clipboard", map[string]interface{}{})
+	assert.Equal(t, strings.Repeat("a", 100)+"\n\n[50 more characters were cut]", text)
+
+	clipboard = " \n"
+	text, _ = useClipboard(t, client, "read_clipboard", map[string]interface{}{})
+	assert.Equal(t, "The clipboard is empty.", text)
+}
+
+func TestClipboardClient_NeedsApproval(t *testing.T) {
+	clipboard := "secret notes"
+	client := newTestClipboardClient(&clipboard)
+
+	result, err := client.CallTool(context.Background(), "write_clipboard", map[string]interface{}{"text": "new"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "needs the user's approval")
+	assert.Equal(t, "secret notes", clipboard, "The clipboard wasn't changed")
+
+	client.config.RequireApproval = false
+	result, err = client.CallTool(context.Background(), "read_clipboard", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "secret notes", resultText(t, result))
+
+	client.read = func() (string, error) { return "", errors.New("exit status 1") }
+	result, err = client.CallTool(context.Background(), "read_clipboard", nil)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "read the clipboard: exit status 1", resultText(t, result))
+}
@@ -0,0 +1,207 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/fsnotify/fsnotify"
+)
+
+// configReloadDelay lets an editor finish saving a config file before it is
+// read; saving often takes several writes and renames
+const configReloadDelay = 300 * time.Millisecond
+
+// reloadableSettings are the settings a config reload applies while the
+// agent runs. Changes to any other setting take effect on restart.
+var reloadableSettings = map[string]bool{
+	"model.temperature": true,
+	"logging.level":     true,
+	"mcp.servers":       true,
+}
+
+// watchConfig reloads the configuration whenever one of its files changes,
+// until the returned function is called
+func (a *Agent) watchConfig() func() {
+	files := a.config.WatchedFiles()
+	if len(files) == 0 {
+		return func() {}
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		a.logger.Printf("Warning: Config changes won't be applied until restart: %v", err)
+		return func() {}
+	}
+
+	watched := make(map[string]bool, len(files))
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+		watched[file] = true
+		// Editors often save by replacing the file, so its directory is watched
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			a.logger.Printf("Warning: Changes to %s won't be applied until restart: %v", file, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer a.crashes.recover("the config watcher")
+		var reloading sync.Mutex
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				name, _ := filepath.Abs(event.Name)
+				if !watched[name] || event.Op == fsnotify.Chmod {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(configReloadDelay, func() {
+					defer a.crashes.recover("a config reload")
+					reloading.Lock()
+					defer reloading.Unlock()
+					a.reloadConfig(ctx)
+				})
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				a.logger.Printf("Config watcher error: %v", err)
+			case <-ctx.Done():
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			}
+		}
+	}()
+
+	a.logger.Printf("Watching for config changes: %s", strings.Join(files, ", "))
+	return func() {
+		cancel()
+		watcher.Close()
+		<-done
+	}
+}
+
+// reloadConfig loads the changed configuration, applies what can be applied
+// while the agent runs, and tells subscribers what changed
+func (a *Agent) reloadConfig(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	cfg, err := a.config.Reload()
+	if err != nil {
+		a.logger.Printf("Config reload failed, keeping the current settings: %v", err)
+		a.bus.Publish(events.ConfigReloadedEvent{Error: err.Error()})
+		return
+	}
+
+	changes := a.applyConfig(ctx, cfg)
+	restart := a.restartSettings(cfg)
+	if len(changes) == 0 && len(restart) == 0 {
+		return
+	}
+	a.logger.Printf("Config reloaded: applied %v; on restart: %v", changes, restart)
+	a.bus.Publish(events.ConfigReloadedEvent{Changes: changes, Restart: restart, Temperature: cfg.Model.Temperature})
+}
+
+// applyConfig applies the reloadable settings of cfg and describes what
+// changed: the model temperature, the logging level, and the MCP servers in
+// the config file, which are connected, disconnected, or restarted to match
+func (a *Agent) applyConfig(ctx context.Context, cfg *config.Config) []string {
+	var changes []string
+
+	a.configMu.Lock()
+	if old := a.config.Model.Temperature; old != cfg.Model.Temperature {
+		a.config.Model.Temperature = cfg.Model.Temperature
+		changes = append(changes, fmt.Sprintf("temperature %g → %g", old, cfg.Model.Temperature))
+	}
+	if old := a.config.Logging.Level; old != cfg.Logging.Level {
+		a.config.Logging.Level = cfg.Logging.Level
+		a.mcpLogger.setLevel(cfg.Logging.Level)
+		changes = append(changes, fmt.Sprintf("logging level %s → %s", old, cfg.Logging.Level))
+	}
+	oldServers := a.config.MCP.Servers
+	a.config.MCP.Servers = cfg.MCP.Servers
+	a.configMu.Unlock()
+
+	current := make(map[string]config.ServerConfig, len(oldServers))
+	disabled := make(map[string]bool)
+	for _, server := range oldServers {
+		if server.IsEnabled() {
+			current[server.Name] = server
+		} else {
+			disabled[server.Name] = true
+		}
+	}
+	for _, server := range cfg.MCP.Servers {
+		old, existed := current[server.Name]
+		delete(current, server.Name)
+		switch {
+		case !server.IsEnabled():
+			if existed {
+				if err := a.mcpManager.RemoveServer(ctx, server.Name); err != nil {
+					a.logger.Printf("Failed to stop MCP server %s: %v", server.Name, err)
+				}
+				changes = append(changes, "disabled server "+server.Name)
+			}
+			continue
+		case disabled[server.Name]:
+			changes = append(changes, "enabled server "+server.Name)
+		case !existed:
+			changes = append(changes, "added server "+server.Name)
+		case !reflect.DeepEqual(old, server):
+			if err := a.mcpManager.RemoveServer(ctx, server.Name); err != nil {
+				a.logger.Printf("Failed to stop MCP server %s: %v", server.Name, err)
+			}
+			changes = append(changes, "restarted server "+server.Name)
+		default:
+			continue
+		}
+		if err := a.mcpManager.AddServer(ctx, server); err != nil {
+			a.logger.Printf("Failed to connect to MCP server %s: %v", server.Name, err)
+		}
+	}
+	for name := range current {
+		if err := a.mcpManager.RemoveServer(ctx, name); err != nil {
+			a.logger.Printf("Failed to stop MCP server %s: %v", name, err)
+		}
+		changes = append(changes, "removed server "+name)
+	}
+	return changes
+}
+
+// restartSettings returns the settings cfg changes that only take effect
+// when the agent restarts
+func (a *Agent) restartSettings(cfg *config.Config) []string {
+	running := make(map[string]string)
+	for _, setting := range a.config.EffectiveSettings() {
+		running[setting.Key] = fmt.Sprint(setting.Value)
+	}
+	var restart []string
+	for _, setting := range cfg.EffectiveSettings() {
+		if reloadableSettings[setting.Key] {
+			continue
+		}
+		if value, ok := running[setting.Key]; !ok || value != fmt.Sprint(setting.Value) {
+			restart = append(restart, setting.Key)
+		}
+	}
+	return restart
+}
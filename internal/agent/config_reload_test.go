@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_WatchConfigAppliesChanges(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(config.ProfileEnvVar, "")
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	configFile := filepath.Join(homeDir, ".othello", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configFile), 0755))
+	writeConfig := func(content string) {
+		require.NoError(t, os.WriteFile(configFile, []byte(content+"storage:\n  data_dir: "+homeDir+"\nlogging:\n  file: "+filepath.Join(homeDir, "test.log")+"\n"), 0644))
+	}
+	writeConfig("model:\n  name: test\n  temperature: 0.7\n")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	agent, err := New(cfg)
+	require.NoError(t, err)
+
+	sub := agent.bus.Subscribe()
+	defer sub.Close()
+	stop := agent.watchConfig()
+	defer stop()
+
+	nextReload := func() events.ConfigReloadedEvent {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case event := <-sub.Events():
+				if reload, ok := event.(events.ConfigReloadedEvent); ok {
+					return reload
+				}
+			case <-timeout:
+				t.Fatal("config was not reloaded")
+			}
+		}
+	}
+
+	writeConfig("model:\n  name: other\n  temperature: 0.3\n")
+	reload := nextReload()
+	assert.Empty(t, reload.Error)
+	assert.Equal(t, []string{"temperature 0.7 → 0.3"}, reload.Changes)
+	assert.Equal(t, []string{"model.name"}, reload.Restart, "The model name takes effect on restart")
+	assert.Equal(t, 0.3, reload.Temperature)
+	assert.Equal(t, 0.3, agent.generateOptions().Temperature)
+
+	writeConfig("model:\n  name: test\n  temperature: 9\n")
+	reload = nextReload()
+	assert.NotEmpty(t, reload.Error, "Invalid settings are reported and not applied")
+	assert.Equal(t, 0.3, agent.generateOptions().Temperature)
+}
+
+func TestAgent_ApplyConfigSkipsDisabledServers(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(config.ProfileEnvVar, "")
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.Storage.DataDir = homeDir
+	cfg.Logging.File = filepath.Join(homeDir, "test.log")
+	agent, err := New(cfg)
+	require.NoError(t, err)
+
+	disabled := false
+	updated := *cfg
+	updated.MCP.Servers = []config.ServerConfig{{Name: "notes", Command: "notes-server", Transport: "stdio", Enabled: &disabled}}
+	assert.Empty(t, agent.applyConfig(context.Background(), &updated), "A disabled server isn't started")
+
+	enabled := updated
+	enabled.MCP.Servers = []config.ServerConfig{{Name: "notes", Command: "notes-server", Transport: "stdio"}}
+	assert.Equal(t, []string{"enabled server notes"}, agent.applyConfig(context.Background(), &enabled))
+	assert.Equal(t, []string{"disabled server notes"}, agent.applyConfig(context.Background(), &updated))
+}
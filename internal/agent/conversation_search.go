@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/danieleugenewilliams/othello-agent/internal/tui"
+)
+
+// conversationSearch backs the TUI's /search command. It embeds new messages
+// before each search and falls back to text search when the embedding model
+// can't be reached.
+type conversationSearch struct {
+	mu       sync.Mutex // SearchManager is not safe for concurrent use
+	manager  *storage.SearchManager
+	semantic bool
+	logger   *log.Logger
+}
+
+// Search implements tui.ConversationSearcher
+func (s *conversationSearch) Search(ctx context.Context, query string, limit int) ([]*storage.SearchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filter := storage.ParseSearchQuery(query)
+	filter.Limit = limit
+	if s.semantic {
+		results, err := s.searchHybrid(ctx, filter)
+		if err == nil {
+			return results, nil
+		}
+		s.logger.Printf("Semantic search unavailable, using text search: %v", err)
+	}
+	return s.manager.SearchRanked(filter)
+}
+
+// searchHybrid embeds the messages saved since the last search, then searches
+// by both words and meaning
+func (s *conversationSearch) searchHybrid(ctx context.Context, filter storage.SearchFilter) ([]*storage.SearchResult, error) {
+	indexed, err := s.manager.IndexEmbeddings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if indexed > 0 {
+		s.logger.Printf("Embedded %d messages for semantic search", indexed)
+	}
+	return s.manager.SearchHybrid(ctx, filter)
+}
+
+// ConversationSearcher returns the backend for /search, or nil when storage is
+// unavailable. Semantic search uses the configured embedding model through
+// Ollama and is skipped when none is set.
+func (a *Agent) ConversationSearcher() tui.ConversationSearcher {
+	if a.store == nil {
+		return nil
+	}
+
+	search := &conversationSearch{manager: a.store.SearchManager(), logger: a.logger}
+	if name := a.config.Model.EmbeddingModel; name != "" {
+		embedder := model.NewOllamaModel(a.config.Ollama.Host, name)
+		embedder.SetTransport(a.modelTransport())
+		search.manager.SetEmbedder(embedder, name)
+		search.semantic = true
+	}
+	return search
+}
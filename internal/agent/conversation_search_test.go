@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationSearch_FallsBackToTextSearch(t *testing.T) {
+	// Ollama without the embedding model pulled
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"nomic-embed-text\" not found"}`))
+	}))
+	defer server.Close()
+
+	store, err := storage.NewConversationStore(filepath.Join(t.TempDir(), storage.DatabaseFile))
+	require.NoError(t, err)
+	defer store.Close()
+	_, err = store.CreateConversation("conv", "Logs")
+	require.NoError(t, err)
+	require.NoError(t, store.AddMessage(&storage.Message{
+		ConversationID: "conv", Role: "user", Content: "rotate postgres logs", Timestamp: time.Now(),
+	}))
+
+	search := &conversationSearch{
+		manager:  store.SearchManager(),
+		semantic: true,
+		logger:   log.New(io.Discard, "", 0),
+	}
+	search.manager.SetEmbedder(model.NewOllamaModel(server.URL, "nomic-embed-text"), "nomic-embed-text")
+
+	results, err := search.Search(context.Background(), "postgres", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "rotate postgres logs", results[0].Message.Content)
+}
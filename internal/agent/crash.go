@@ -0,0 +1,219 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/danieleugenewilliams/othello-agent/internal/secrets"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// crashDir is the directory in the data directory crash reports are saved to
+const crashDir = "crashes"
+
+// crashReporter saves a report when the TUI or a background task panics, so
+// the crash can be looked into after the terminal is restored
+type crashReporter struct {
+	config *config.Config
+	logger *log.Logger
+	bus    *events.Bus
+	last   atomic.Value // Path of the latest report, as a string
+}
+
+func newCrashReporter(cfg *config.Config, logger *log.Logger, bus *events.Bus) *crashReporter {
+	return &crashReporter{config: cfg, logger: logger, bus: bus}
+}
+
+// recover stops a panic in a background task, saves a crash report, and
+// tells the user where it is. It must be deferred directly at the start of
+// the task's goroutine.
+func (c *crashReporter) recover(where string) {
+	if c == nil {
+		return
+	}
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	path := c.save(where, recovered, debug.Stack())
+	c.logger.Printf("Error: Recovered from panic in %s: %v", where, recovered)
+	text := fmt.Sprintf("Internal error in %s", where)
+	if path != "" {
+		text += "; a crash report was saved to " + path
+	}
+	c.bus.Publish(events.NotificationEvent{Text: text, Level: events.LevelError})
+}
+
+// lastReport returns the path of the latest crash report, or "" when none
+// was saved
+func (c *crashReporter) lastReport() string {
+	path, _ := c.last.Load().(string)
+	return path
+}
+
+// crashError explains that the TUI crashed, pointing to the crash report
+func (c *crashReporter) crashError(err error) error {
+	if errors.Is(err, tea.ErrProgramPanic) {
+		if path := c.lastReport(); path != "" {
+			return fmt.Errorf("the TUI crashed; a crash report was saved to %s", path)
+		}
+	}
+	return err
+}
+
+// save writes a crash report for the panic recovered in where and returns
+// its path, or "" when it couldn't be written
+func (c *crashReporter) save(where string, recovered interface{}, stack []byte) string {
+	dir, err := crashReportDir(c.config)
+	if err != nil {
+		c.logger.Printf("Warning: Failed to save crash report: %v", err)
+		return ""
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		c.logger.Printf("Warning: Failed to save crash report: %v", err)
+		return ""
+	}
+
+	now := time.Now()
+	var b strings.Builder
+	fmt.Fprintf(&b, "Othello crash report\n\n")
+	fmt.Fprintf(&b, "Time:  %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Where: %s\n", where)
+	fmt.Fprintf(&b, "Panic: %v\n", recovered)
+	fmt.Fprintf(&b, "Go:    %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	fmt.Fprintf(&b, "\nStack:\n%s\n", stack)
+
+	fmt.Fprintf(&b, "\nRecent events:\n")
+	history := c.bus.History()
+	if len(history) == 0 {
+		fmt.Fprintf(&b, "  (none)\n")
+	}
+	for _, event := range history {
+		fmt.Fprintf(&b, "  %s %+v\n", event.Kind(), event)
+	}
+
+	fmt.Fprintf(&b, "\nConfiguration:\n")
+	for _, line := range crashConfigSummary(c.config) {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+
+	path := filepath.Join(dir, "crash-"+now.Format("20060102-150405.000")+".txt")
+	if err := os.WriteFile(path, []byte(secrets.Redact(b.String())), 0600); err != nil {
+		c.logger.Printf("Warning: Failed to save crash report: %v", err)
+		return ""
+	}
+	c.last.Store(path)
+	return path
+}
+
+// crashReportDir returns the directory crash reports are saved to
+func crashReportDir(cfg *config.Config) (string, error) {
+	path, err := storage.DatabasePath(cfg.Storage.DataDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), crashDir), nil
+}
+
+// crashConfigSummary describes the settings that help explain a crash. Only
+// settings known to be safe to share are listed: server environments and
+// headers, API keys, and the credentials and queries of URLs are left out.
+// The config isn't locked, as the panic may have left its lock held.
+func crashConfigSummary(cfg *config.Config) []string {
+	lines := []string{
+		fmt.Sprintf("config file: %s", cfg.ConfigFile()),
+		fmt.Sprintf("profile: %s", cfg.Profile()),
+		fmt.Sprintf("model: %s (%s), temperature %g, context length %d", cfg.Model.Name, cfg.Model.Type, cfg.Model.Temperature, cfg.Model.ContextLength),
+		fmt.Sprintf("ollama host: %s", redactedURL(cfg.Ollama.Host)),
+		fmt.Sprintf("tui: render mode %q, accessible %t", cfg.TUI.RenderMode, cfg.TUI.Accessible),
+		fmt.Sprintf("logging level: %s", cfg.Logging.Level),
+	}
+	for _, server := range cfg.MCP.Servers {
+		line := fmt.Sprintf("mcp server %s: transport %s", server.Name, server.Transport)
+		if server.Command != "" {
+			line += ", command " + server.Command
+		}
+		if server.URL != "" {
+			line += ", url " + redactedURL(server.URL)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// redactedURL returns raw without its password and query, which may hold
+// credentials
+func redactedURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.Redacted()
+}
+
+// crashGuard wraps the TUI's model so that a panic in it, or in a command it
+// returns, saves a crash report. The panic is passed on to bubbletea, which
+// restores the terminal and returns tea.ErrProgramPanic from Run.
+type crashGuard struct {
+	model   tea.Model
+	crashes *crashReporter
+}
+
+func (g crashGuard) Init() tea.Cmd {
+	defer g.capture("the TUI")
+	return g.wrap(g.model.Init())
+}
+
+func (g crashGuard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer g.capture("the TUI")
+	model, cmd := g.model.Update(msg)
+	return crashGuard{model: model, crashes: g.crashes}, g.wrap(cmd)
+}
+
+func (g crashGuard) View() string {
+	defer g.capture("the TUI")
+	return g.model.View()
+}
+
+// capture saves a crash report for a panic and panics again. It must be
+// deferred directly.
+func (g crashGuard) capture(where string) {
+	if recovered := recover(); recovered != nil {
+		g.crashes.save(where, recovered, debug.Stack())
+		panic(recovered)
+	}
+}
+
+// wrap guards cmd, and the commands of a batch, the same way
+func (g crashGuard) wrap(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		defer g.capture("a TUI command")
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			wrapped := make(tea.BatchMsg, len(batch))
+			for i, c := range batch {
+				wrapped[i] = g.wrap(c)
+			}
+			return wrapped
+		}
+		return msg
+	}
+}
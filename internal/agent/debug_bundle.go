@@ -0,0 +1,248 @@
+package agent
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/secrets"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// bundleCrashReports is how many of the latest crash reports a debug bundle
+// includes
+const bundleCrashReports = 3
+
+// DebugBundleOptions selects what a debug bundle includes
+type DebugBundleOptions struct {
+	Version  string // Othello's version
+	LogLines int    // How many of the latest log entries are included; 0 includes none
+	Turns    int    // How many of the latest conversation turns are included; 0 includes none
+}
+
+// WriteDebugBundle writes a zip archive to attach to a bug report to w. It
+// holds the doctor's report, which has the versions and whether each MCP
+// server starts, the config with secrets redacted, the latest log entries
+// and crash reports, and, only when opts.Turns is set, the latest
+// conversation turns. The home directory is shown as "~" and known secrets
+// are masked throughout. It returns the names of the files in the archive.
+func WriteDebugBundle(ctx context.Context, w io.Writer, cfg *config.Config, opts DebugBundleOptions) ([]string, error) {
+	hide := hideHomeDir()
+	archive := zip.NewWriter(w)
+	var names []string
+	add := func(name, content string) error {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, hide(secrets.Redact(content))); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	}
+
+	report, err := json.MarshalIndent(Doctor(ctx, opts.Version, cfg, nil), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := add("doctor.json", string(report)+"\n"); err != nil {
+		return nil, err
+	}
+
+	sanitized, err := yaml.Marshal(sanitizedConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := add("config.yaml", string(sanitized)); err != nil {
+		return nil, err
+	}
+
+	if opts.LogLines > 0 {
+		logs, err := recentLogEntries(ctx, cfg, opts.LogLines)
+		if err != nil {
+			return nil, err
+		}
+		if logs != "" {
+			if err := add("othello.log", logs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	reports, err := recentCrashReports(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range reports {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read crash report: %w", err)
+		}
+		if err := add(crashDir+"/"+filepath.Base(path), string(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Turns > 0 {
+		conversations, err := recentTurns(cfg, opts.Turns)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range sortedKeys(conversations) {
+			if err := add("conversations/"+name+".md", conversations[name]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// sanitizedConfig returns a copy of cfg without secrets: settings that hold
+// credentials and the values of server environments and headers are
+// redacted, URLs lose their passwords and query strings, and webhook URLs,
+// which often carry a token in their path, are cut to their host
+func sanitizedConfig(cfg *config.Config) config.Config {
+	sanitized := cfg.Masked()
+	sanitized.Ollama.Host = redactedURL(cfg.Ollama.Host)
+	if sanitized.Share.Endpoint != "" {
+		sanitized.Share.Endpoint = redactedURL(sanitized.Share.Endpoint)
+	}
+	for i, webhook := range sanitized.Serve.Webhooks {
+		sanitized.Serve.Webhooks[i].URL = webhookHost(webhook.URL)
+	}
+	sanitized.MCP.Servers = make([]config.ServerConfig, len(cfg.MCP.Servers))
+	for i, server := range cfg.MCP.Servers {
+		server.Env = redactedValues(server.Env)
+		server.Headers = redactedValues(server.Headers)
+		if server.URL != "" {
+			server.URL = redactedURL(server.URL)
+		}
+		sanitized.MCP.Servers[i] = server
+	}
+	return sanitized
+}
+
+// redactedValues returns values with each value redacted and the keys kept
+func redactedValues(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(values))
+	for key := range values {
+		redacted[key] = secrets.Mask
+	}
+	return redacted
+}
+
+// recentLogEntries returns the last n entries of the log file, or "" when
+// there is no log file yet
+func recentLogEntries(ctx context.Context, cfg *config.Config, n int) (string, error) {
+	path, err := LogFilePath(cfg.Logging.File)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	var b strings.Builder
+	err = TailLog(ctx, path, n, false, LogFilter{}, func(entry LogEntry) {
+		b.WriteString(entry.Text)
+		b.WriteString("\n")
+	})
+	return b.String(), err
+}
+
+// recentCrashReports returns the paths of the latest crash reports, newest
+// first
+func recentCrashReports(cfg *config.Config) ([]string, error) {
+	dir, err := crashReportDir(cfg)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	// Report names start with the time, so they sort oldest first
+	sort.Sort(sort.Reverse(sort.StringSlice(reports)))
+	if len(reports) > bundleCrashReports {
+		reports = reports[:bundleCrashReports]
+	}
+	return reports, nil
+}
+
+// recentTurns returns the last n turns of the latest conversations in
+// Markdown, keyed by conversation ID. A turn is a prompt and what answered
+// it. It returns none when there is no history yet.
+func recentTurns(cfg *config.Config, n int) (map[string]string, error) {
+	path, err := storage.DatabasePath(cfg.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	store, err := storage.NewConversationStore(path)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	// Every conversation holds at least one turn
+	conversations, err := store.ListConversations(n, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	exporter, err := storage.NewExporter(storage.ExportMarkdown)
+	if err != nil {
+		return nil, err
+	}
+	turns := make(map[string]string)
+	for _, conv := range conversations {
+		if n <= 0 {
+			break
+		}
+		thread, err := store.GetThread(conv.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read conversation: %w", err)
+		}
+		start := len(thread)
+		for start > 0 && n > 0 {
+			start--
+			if thread[start].Role == "user" {
+				n--
+			}
+		}
+		var b strings.Builder
+		if err := exporter.Export(&b, conv, thread[start:]); err != nil {
+			return nil, err
+		}
+		turns[conv.ID] = b.String()
+	}
+	return turns, nil
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
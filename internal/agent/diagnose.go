@@ -0,0 +1,262 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// CheckStatus is the outcome of a configuration check
+type CheckStatus string
+
+const (
+	CheckPassed  CheckStatus = "ok"
+	CheckWarning CheckStatus = "warning"
+	CheckFailed  CheckStatus = "error"
+)
+
+// ConfigCheck is the result of checking one part of the configuration
+type ConfigCheck struct {
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+	Detail string      `json:"detail"`
+	Fix    string      `json:"fix,omitempty"` // What to do when the check didn't pass
+}
+
+// ollamaCheckTimeout bounds how long DiagnoseConfig waits for Ollama
+const ollamaCheckTimeout = 5 * time.Second
+
+// minOllamaTimeout is the shortest ollama.timeout that leaves a model time
+// to load before its first answer
+const minOllamaTimeout = 10 * time.Second
+
+// serverDialTimeout bounds how long the doctor waits to connect to an http
+// server
+const serverDialTimeout = 5 * time.Second
+
+// ValidateConfig checks cfg and the MCP servers of the active profile without
+// looking outside the config files, so it is quick and safe to run in CI
+func ValidateConfig(cfg *config.Config) []ConfigCheck {
+	var checks []ConfigCheck
+	if cfg.HasConfigFile() {
+		checks = append(checks, ConfigCheck{Name: "config", Status: CheckPassed, Detail: "Loaded " + cfg.ConfigFile()})
+	} else {
+		checks = append(checks, ConfigCheck{
+			Name:   "config",
+			Status: CheckWarning,
+			Detail: "No config file found, using the defaults",
+			Fix:    "Run `othello config init` to create one",
+		})
+	}
+
+	if cfg.Ollama.Timeout < minOllamaTimeout {
+		checks = append(checks, ConfigCheck{
+			Name:   "ollama.timeout",
+			Status: CheckWarning,
+			Detail: fmt.Sprintf("%s may not leave time for a model to load", cfg.Ollama.Timeout),
+			Fix:    fmt.Sprintf("Set ollama.timeout to at least %s", minOllamaTimeout),
+		})
+	} else {
+		checks = append(checks, ConfigCheck{Name: "ollama.timeout", Status: CheckPassed, Detail: cfg.Ollama.Timeout.String()})
+	}
+
+	servers, check := configuredServers(cfg)
+	if check != nil {
+		checks = append(checks, *check)
+	}
+	seen := make(map[string]bool, len(servers))
+	for _, server := range servers {
+		if !server.IsEnabled() {
+			checks = append(checks, ConfigCheck{Name: "server " + server.Name, Status: CheckPassed, Detail: "disabled"})
+			seen[server.Name] = true
+			continue
+		}
+		checks = append(checks, validateServer(server, seen[server.Name]))
+		seen[server.Name] = true
+	}
+	return checks
+}
+
+// DiagnoseConfig runs the checks of ValidateConfig, then checks that Ollama
+// is reachable and has the configured model, and that each MCP server can be
+// started. In offline mode http servers aren't contacted.
+func DiagnoseConfig(ctx context.Context, cfg *config.Config) []ConfigCheck {
+	checks := ValidateConfig(cfg)
+	checks = append(checks, checkOllama(ctx, cfg))
+
+	servers, _ := configuredServers(cfg)
+	for _, server := range servers {
+		if !server.IsEnabled() {
+			continue
+		}
+		if cfg.Agent.Offline && server.Transport == "http" {
+			checks = append(checks, ConfigCheck{
+				Name:   "url " + server.Name,
+				Status: CheckWarning,
+				Detail: "Not connected in offline mode, which runs only stdio servers",
+				Fix:    "Disable the server, or set agent.offline to false",
+			})
+			continue
+		}
+		if check, ok := checkServerReachable(ctx, server); ok {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// ChecksFailed reports whether any of checks failed
+func ChecksFailed(checks []ConfigCheck) bool {
+	for _, check := range checks {
+		if check.Status == CheckFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// configuredServers returns the MCP servers the agent knows of on start:
+// those in the config files followed by those in mcp.json. The check is
+// non-nil when mcp.json couldn't be read.
+func configuredServers(cfg *config.Config) ([]config.ServerConfig, *ConfigCheck) {
+	servers := append([]config.ServerConfig(nil), cfg.MCP.Servers...)
+	mcpConfig, err := config.LoadMCPConfig()
+	var mcpServers []config.ServerConfig
+	if err == nil {
+		mcpServers, err = config.ConvertMCPToServerConfigs(mcpConfig)
+	}
+	if err != nil {
+		return servers, &ConfigCheck{
+			Name:   "mcp.json",
+			Status: CheckFailed,
+			Detail: err.Error(),
+			Fix:    "Fix the file, or remove it and add the servers again with `othello mcp add`",
+		}
+	}
+	return append(servers, mcpServers...), nil
+}
+
+// validateServer checks that server is complete enough to start
+func validateServer(server config.ServerConfig, duplicate bool) ConfigCheck {
+	check := ConfigCheck{Name: "server " + server.Name, Status: CheckFailed}
+	switch {
+	case server.Name == "":
+		check.Name = "server"
+		check.Detail = "A server has no name"
+		check.Fix = "Give every entry in mcp.servers a name"
+	case duplicate:
+		check.Detail = "More than one server has this name; only the first connects"
+		check.Fix = "Rename or remove one of them in config.yaml or mcp.json"
+	case server.Transport != "stdio" && server.Transport != "http":
+		check.Detail = fmt.Sprintf("Unknown transport %q", server.Transport)
+		check.Fix = "Set transport to stdio or http"
+	case server.Transport == "stdio" && server.Command == "":
+		check.Detail = "No command to start the server"
+		check.Fix = "Set the server's command"
+	case server.Transport == "http" && config.ValidateServerURL(server.URL) != nil:
+		check.Detail = config.ValidateServerURL(server.URL).Error()
+		check.Fix = "Set the server's url to its endpoint, such as https://example.com/mcp"
+	case server.Timeout < 0:
+		check.Detail = fmt.Sprintf("Timeout %s is negative", server.Timeout)
+		check.Fix = "Set a positive timeout, or leave it out for the 30s default"
+	case server.Timeout > 0 && server.Timeout < time.Second:
+		check.Status = CheckWarning
+		check.Detail = fmt.Sprintf("Timeout %s is too short for most tools", server.Timeout)
+		check.Fix = "Set a timeout of a few seconds or more"
+	case server.Transport == "http":
+		check.Status = CheckPassed
+		check.Detail = server.URL
+	default:
+		check.Status = CheckPassed
+		check.Detail = strings.TrimSpace(server.Command + " " + strings.Join(server.Args, " "))
+	}
+	return check
+}
+
+// checkOllama checks that Ollama answers at the configured host and has the
+// configured model
+func checkOllama(ctx context.Context, cfg *config.Config) ConfigCheck {
+	ctx, cancel := context.WithTimeout(ctx, ollamaCheckTimeout)
+	defer cancel()
+
+	check := ConfigCheck{Name: "ollama", Status: CheckFailed}
+	models, err := model.NewOllamaModel(cfg.Ollama.Host, cfg.Model.Name).ListModels(ctx)
+	if err != nil {
+		check.Detail = err.Error()
+		check.Fix = "Start Ollama with `ollama serve`, or set ollama.host to where it runs"
+		return check
+	}
+	for _, name := range models {
+		if name == cfg.Model.Name || name == cfg.Model.Name+":latest" {
+			check.Status = CheckPassed
+			check.Detail = fmt.Sprintf("%s has %s", cfg.Ollama.Host, cfg.Model.Name)
+			return check
+		}
+	}
+	check.Detail = fmt.Sprintf("%s doesn't have the model %s", cfg.Ollama.Host, cfg.Model.Name)
+	check.Fix = fmt.Sprintf("Run `ollama pull %s`, or set model.name to one of `ollama list`", cfg.Model.Name)
+	return check
+}
+
+// checkServerReachable checks that the command starting server is installed,
+// or that the host of an http server accepts connections. It reports false
+// for servers ValidateConfig already found unusable.
+func checkServerReachable(ctx context.Context, server config.ServerConfig) (ConfigCheck, bool) {
+	if server.Name == "" {
+		return ConfigCheck{}, false
+	}
+	if server.Transport == "http" {
+		return checkServerURL(ctx, server)
+	}
+	if server.Transport != "stdio" || server.Command == "" {
+		return ConfigCheck{}, false
+	}
+	check := ConfigCheck{Name: "command " + server.Name}
+	path, err := exec.LookPath(server.Command)
+	if err != nil {
+		check.Status = CheckFailed
+		check.Detail = fmt.Sprintf("%s not found on PATH", server.Command)
+		check.Fix = "Install it, or use the full path to the command"
+		return check, true
+	}
+	check.Status = CheckPassed
+	check.Detail = path
+	return check, true
+}
+
+// checkServerURL checks that the host of the http server accepts connections
+func checkServerURL(ctx context.Context, server config.ServerConfig) (ConfigCheck, bool) {
+	u, err := url.Parse(server.URL)
+	if err != nil || config.ValidateServerURL(server.URL) != nil {
+		return ConfigCheck{}, false
+	}
+	address := u.Host
+	if u.Port() == "" {
+		port := "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	check := ConfigCheck{Name: "url " + server.Name}
+	dialer := net.Dialer{Timeout: serverDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		check.Status = CheckFailed
+		check.Detail = fmt.Sprintf("Can't connect to %s: %v", address, err)
+		check.Fix = "Check that the server is running and the url is right"
+		return check, true
+	}
+	conn.Close()
+	check.Status = CheckPassed
+	check.Detail = server.URL
+	return check, true
+}
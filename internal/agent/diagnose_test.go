@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkNamed returns the check called name, failing the test without one
+func checkNamed(t *testing.T, checks []ConfigCheck, name string) ConfigCheck {
+	t.Helper()
+	for _, check := range checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	require.Failf(t, "check not found", "no check named %q in %v", name, checks)
+	return ConfigCheck{}
+}
+
+func TestValidateConfig_ChecksServers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.ProfileEnvVar, "")
+
+	cfg := &config.Config{
+		Ollama: config.OllamaConfig{Timeout: 2 * time.Second},
+		MCP: config.MCPConfig{Servers: []config.ServerConfig{
+			{Name: "files", Command: "npx", Transport: "stdio"},
+			{Name: "files", Command: "npx", Transport: "stdio"},
+			{Name: "notes", Transport: "stdio"},
+			{Name: "slow", Command: "notes-server", Transport: "stdio", Timeout: 100 * time.Millisecond},
+			{Name: "remote", Transport: "sse"},
+			{Name: "nourl", Transport: "http"},
+			{Name: "paused", Enabled: new(bool)},
+		}},
+	}
+	checks := ValidateConfig(cfg)
+
+	assert.Equal(t, CheckWarning, checkNamed(t, checks, "config").Status, "A missing config file only warns")
+	assert.Equal(t, CheckWarning, checkNamed(t, checks, "ollama.timeout").Status)
+	assert.Equal(t, CheckPassed, checks[2].Status)
+	assert.Contains(t, checks[3].Detail, "More than one server")
+	assert.Equal(t, CheckFailed, checkNamed(t, checks, "server notes").Status)
+	assert.Equal(t, CheckWarning, checkNamed(t, checks, "server slow").Status)
+	assert.Contains(t, checkNamed(t, checks, "server remote").Detail, `Unknown transport "sse"`)
+	assert.Contains(t, checkNamed(t, checks, "server nourl").Detail, "url is required")
+	assert.Equal(t, ConfigCheck{Name: "server paused", Status: CheckPassed, Detail: "disabled"}, checkNamed(t, checks, "server paused"), "Disabled servers aren't checked")
+	assert.True(t, ChecksFailed(checks))
+}
+
+func TestDiagnoseConfig_ChecksOllamaAndCommands(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.ProfileEnvVar, "")
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models": [{"name": "qwen2.5:3b"}, {"name": "llama3:latest"}]}`)
+	}))
+	defer ollama.Close()
+
+	cfg := &config.Config{
+		Model:  config.ModelConfig{Name: "llama3"},
+		Ollama: config.OllamaConfig{Host: ollama.URL, Timeout: time.Minute},
+		MCP: config.MCPConfig{Servers: []config.ServerConfig{
+			{Name: "shell", Command: "sh", Transport: "stdio"},
+			{Name: "missing", Command: "othello-no-such-server", Transport: "stdio"},
+			{Name: "remote", Transport: "http", URL: ollama.URL + "/mcp"},
+			{Name: "offline", Transport: "http", URL: "http://127.0.0.1:1/mcp"},
+		}},
+	}
+	checks := DiagnoseConfig(context.Background(), cfg)
+	assert.Equal(t, CheckPassed, checkNamed(t, checks, "ollama").Status, "llama3 is installed as llama3:latest")
+	assert.Equal(t, CheckPassed, checkNamed(t, checks, "command shell").Status)
+	missing := checkNamed(t, checks, "command missing")
+	assert.Equal(t, CheckFailed, missing.Status)
+	assert.Contains(t, missing.Detail, "not found on PATH")
+	assert.Equal(t, CheckPassed, checkNamed(t, checks, "server remote").Status)
+	assert.Equal(t, CheckPassed, checkNamed(t, checks, "url remote").Status)
+	assert.Equal(t, CheckFailed, checkNamed(t, checks, "url offline").Status)
+
+	cfg.Agent.Offline = true
+	remote := checkNamed(t, DiagnoseConfig(context.Background(), cfg), "url remote")
+	assert.Equal(t, CheckWarning, remote.Status, "offline mode doesn't contact http servers")
+	assert.Contains(t, remote.Detail, "offline mode")
+	cfg.Agent.Offline = false
+
+	cfg.Model.Name = "mistral"
+	check := checkNamed(t, DiagnoseConfig(context.Background(), cfg), "ollama")
+	assert.Equal(t, CheckFailed, check.Status)
+	assert.Contains(t, check.Fix, "ollama pull mistral")
+}
@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package agent
+
+import "errors"
+
+// diskFree isn't available on this platform
+func diskFree(path string) (uint64, error) {
+	return 0, errors.New("free disk space can't be measured on this platform")
+}
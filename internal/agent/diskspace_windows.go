@@ -0,0 +1,18 @@
+//go:build windows
+
+package agent
+
+import "golang.org/x/sys/windows"
+
+// diskFree returns the bytes available to the user on the volume holding path
+func diskFree(path string) (uint64, error) {
+	dir, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var free, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(dir, &free, &total, &totalFree); err != nil {
+		return 0, err
+	}
+	return free, nil
+}
@@ -0,0 +1,230 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// serverConnectTimeout bounds how long the doctor waits for an MCP server to
+// start and list its tools
+const serverConnectTimeout = 15 * time.Second
+
+// Free space in the data directory below which the doctor warns, and below
+// which it fails
+const (
+	lowDiskSpace      = 1 << 30
+	criticalDiskSpace = 100 << 20
+)
+
+// SystemInfo describes the environment Othello runs in
+type SystemInfo struct {
+	Version    string `json:"version"`
+	GoVersion  string `json:"go_version"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	CPUs       int    `json:"cpus"`
+	ConfigFile string `json:"config_file,omitempty"`
+	Profile    string `json:"profile,omitempty"`
+	DataDir    string `json:"data_dir,omitempty"`
+}
+
+// DoctorReport is what `othello doctor` found. Paths in it show the home
+// directory as "~", so it can be shared in a bug report.
+type DoctorReport struct {
+	System SystemInfo    `json:"system"`
+	Checks []ConfigCheck `json:"checks"`
+}
+
+// Doctor checks everything Othello depends on: the checks of DiagnoseConfig,
+// the models Ollama has, that each MCP server starts and lists its tools, the
+// integrity of the history database, and the free space in the data
+// directory. version is Othello's version. When the configuration couldn't be
+// loaded, cfg is nil and loadErr says why; only the system is described then.
+func Doctor(ctx context.Context, version string, cfg *config.Config, loadErr error) DoctorReport {
+	report := DoctorReport{System: SystemInfo{
+		Version:   version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+	}}
+	if loadErr != nil {
+		report.Checks = []ConfigCheck{{
+			Name:   "config",
+			Status: CheckFailed,
+			Detail: loadErr.Error(),
+			Fix:    "Fix the configuration, then run `othello doctor` again",
+		}}
+		return report.shareable()
+	}
+
+	if cfg.HasConfigFile() {
+		report.System.ConfigFile = cfg.ConfigFile()
+	}
+	report.System.Profile = cfg.Profile()
+	report.System.DataDir = cfg.Storage.DataDir
+
+	report.Checks = DiagnoseConfig(ctx, cfg)
+	if check, ok := checkOllamaModels(ctx, cfg); ok {
+		report.Checks = append(report.Checks, check)
+	}
+	servers, _ := configuredServers(cfg)
+	for _, server := range servers {
+		if server.IsEnabled() && validateServer(server, false).Status != CheckFailed {
+			report.Checks = append(report.Checks, checkServerConnects(ctx, server))
+		}
+	}
+	report.Checks = append(report.Checks, checkDatabase(cfg), checkDiskSpace(cfg))
+	return report.shareable()
+}
+
+// shareable returns the report with the home directory shown as "~"
+func (r DoctorReport) shareable() DoctorReport {
+	hide := hideHomeDir()
+	r.System.ConfigFile = hide(r.System.ConfigFile)
+	r.System.DataDir = hide(r.System.DataDir)
+	checks := make([]ConfigCheck, len(r.Checks))
+	for i, check := range r.Checks {
+		check.Detail, check.Fix = hide(check.Detail), hide(check.Fix)
+		checks[i] = check
+	}
+	r.Checks = checks
+	return r
+}
+
+// hideHomeDir returns a function that shows the home directory in text as
+// "~", or leaves text as it is when the home directory is unknown
+func hideHomeDir() func(string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil || homeDir == "" || homeDir == "/" {
+		return func(s string) string { return s }
+	}
+	return func(s string) string { return strings.ReplaceAll(s, homeDir, "~") }
+}
+
+// checkOllamaModels lists the models Ollama has. It reports false when
+// Ollama doesn't answer, which checkOllama already reports.
+func checkOllamaModels(ctx context.Context, cfg *config.Config) (ConfigCheck, bool) {
+	ctx, cancel := context.WithTimeout(ctx, ollamaCheckTimeout)
+	defer cancel()
+
+	models, err := model.NewOllamaModel(cfg.Ollama.Host, cfg.Model.Name).ListModels(ctx)
+	if err != nil {
+		return ConfigCheck{}, false
+	}
+	check := ConfigCheck{Name: "ollama models", Status: CheckPassed}
+	if len(models) == 0 {
+		check.Status = CheckWarning
+		check.Detail = "No models installed"
+		check.Fix = fmt.Sprintf("Run `ollama pull %s`", cfg.Model.Name)
+		return check, true
+	}
+	check.Detail = fmt.Sprintf("%d installed: %s", len(models), strings.Join(models, ", "))
+	return check, true
+}
+
+// checkServerConnects starts server, or connects to it, and lists its tools
+func checkServerConnects(ctx context.Context, server config.ServerConfig) ConfigCheck {
+	ctx, cancel := context.WithTimeout(ctx, serverConnectTimeout)
+	defer cancel()
+
+	check := ConfigCheck{Name: "connect " + server.Name, Status: CheckFailed}
+	client, err := mcp.NewClientFromConfig(server, &agentLogger{logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	if err := client.Connect(ctx); err != nil {
+		check.Detail = fmt.Sprintf("Failed to connect: %v", err)
+		check.Fix = "Run the server's command yourself to see its errors, or check its url"
+		return check
+	}
+	defer client.Disconnect(context.Background())
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		check.Detail = fmt.Sprintf("Connected, but listing tools failed: %v", err)
+		return check
+	}
+	check.Status = CheckPassed
+	check.Detail = fmt.Sprintf("%d tools", len(tools))
+	return check
+}
+
+// checkDatabase runs SQLite's integrity check on the history database
+func checkDatabase(cfg *config.Config) ConfigCheck {
+	check := ConfigCheck{Name: "database", Status: CheckFailed}
+	path, err := storage.DatabasePath(cfg.Storage.DataDir)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		check.Status = CheckPassed
+		check.Detail = "No history yet"
+		return check
+	}
+
+	store, err := storage.NewConversationStore(path)
+	if err != nil {
+		check.Detail = err.Error()
+		check.Fix = "Close other Othello sessions and try again"
+		return check
+	}
+	defer store.Close()
+	problems, err := store.IntegrityCheck()
+	switch {
+	case err != nil:
+		check.Detail = err.Error()
+	case len(problems) > 0:
+		check.Detail = fmt.Sprintf("%s is damaged: %s", path, strings.Join(problems, "; "))
+		check.Fix = "Restore it from a backup, or export what can be read with `othello history export` and start a new one"
+	default:
+		check.Status = CheckPassed
+		check.Detail = path + " is intact"
+	}
+	return check
+}
+
+// checkDiskSpace checks the free space on the disk holding the data directory
+func checkDiskSpace(cfg *config.Config) ConfigCheck {
+	check := ConfigCheck{Name: "disk space", Status: CheckWarning}
+	// The data directory may not exist yet; measure the disk it will be on
+	dir := cfg.Storage.DataDir
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	free, err := diskFree(dir)
+	if err != nil {
+		check.Detail = fmt.Sprintf("Couldn't measure the free space in %s: %v", dir, err)
+		return check
+	}
+	check.Detail = fmt.Sprintf("%.1f GB free in %s", float64(free)/(1<<30), dir)
+	switch {
+	case free < criticalDiskSpace:
+		check.Status = CheckFailed
+		check.Fix = "Free some space; history and the index can't be saved"
+	case free < lowDiskSpace:
+		check.Fix = "Free some space, or prune history with `othello history prune`"
+	default:
+		check.Status = CheckPassed
+	}
+	return check
+}
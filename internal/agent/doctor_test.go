@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctor_ChecksTheEnvironment(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(config.ProfileEnvVar, "")
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models": [{"name": "qwen2.5:3b"}, {"name": "llama3:latest"}]}`)
+	}))
+	defer ollama.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	dataDir := filepath.Join(homeDir, ".othello")
+	path, err := storage.DatabasePath(dataDir)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	store, err := storage.NewConversationStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	cfg := &config.Config{
+		Model:   config.ModelConfig{Name: "llama3"},
+		Ollama:  config.OllamaConfig{Host: ollama.URL, Timeout: time.Minute},
+		Storage: config.StorageConfig{DataDir: dataDir},
+		MCP: config.MCPConfig{Servers: []config.ServerConfig{
+			{Name: "broken", Transport: "http", URL: broken.URL + "/mcp"},
+		}},
+	}
+	report := Doctor(context.Background(), "1.2.3", cfg, nil)
+
+	assert.Equal(t, "1.2.3", report.System.Version)
+	assert.Equal(t, runtime.GOOS, report.System.OS)
+	assert.Equal(t, "~/.othello", report.System.DataDir, "The home directory is hidden")
+	assert.Equal(t, "2 installed: qwen2.5:3b, llama3:latest", checkNamed(t, report.Checks, "ollama models").Detail)
+	assert.Equal(t, CheckFailed, checkNamed(t, report.Checks, "connect broken").Status)
+	database := checkNamed(t, report.Checks, "database")
+	assert.Equal(t, CheckPassed, database.Status)
+	assert.Equal(t, "~/.othello/"+storage.DatabaseFile+" is intact", database.Detail)
+	assert.Contains(t, checkNamed(t, report.Checks, "disk space").Detail, "GB free in ~/.othello")
+}
+
+func TestDoctor_ReportsLoadErrors(t *testing.T) {
+	report := Doctor(context.Background(), "dev", nil, errors.New("model.temperature must be between 0 and 2"))
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, CheckFailed, report.Checks[0].Status)
+	assert.Equal(t, runtime.GOARCH, report.System.Arch, "The system is described even so")
+}
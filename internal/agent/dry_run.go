@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SetDryRun turns dry-run mode on or off. In dry-run mode tool calls are
+// validated against the tool's schema and checked against tool policies, but
+// never run. It implements tui.DryRunner.
+func (a *Agent) SetDryRun(on bool) {
+	a.dryRun.Store(on)
+}
+
+// DryRun reports whether tool calls are only validated, not run
+func (a *Agent) DryRun() bool {
+	return a.dryRun.Load()
+}
+
+// dryRunResult describes the call to toolName that dry-run mode skipped, in
+// place of the tool's result
+func dryRunResult(toolName string, params map[string]interface{}) string {
+	arguments := "{}"
+	if len(params) > 0 {
+		if data, err := json.MarshalIndent(params, "", "  "); err == nil {
+			arguments = string(data)
+		}
+	}
+	return fmt.Sprintf("🧪 Dry run: %s was not called. Its arguments are valid:\n%s", toolName, arguments)
+}
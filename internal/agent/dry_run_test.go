@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_DryRunValidatesWithoutRunning(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	agent.config.Agent.PlanApproval = "always"
+	agent.SetDryRun(true)
+	require.True(t, agent.DryRun())
+	ctx := context.Background()
+
+	output, err := agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "Deploys are on Tuesdays"}, &model.ConversationContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "🧪 Dry run: remember was not called. Its arguments are valid:\n{\n  \"content\": \"Deploys are on Tuesdays\"\n}", output)
+	memories, err := agent.memory.List("", 0)
+	require.NoError(t, err)
+	assert.Empty(t, memories, "The tool didn't run")
+
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"text": "Deploys are on Tuesdays"}, &model.ConversationContext{})
+	assert.EqualError(t, err, "invalid parameters: missing required parameter: content", "Invalid calls are still reported")
+
+	forget := []model.ToolCall{{Name: "forget", Arguments: map[string]interface{}{"id": "mem_1"}}}
+	assert.Nil(t, agent.ToolPlan(forget), "Nothing runs, so nothing needs approval")
+
+	agent.SetDryRun(false)
+	output, err = agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "Deploys are on Tuesdays"}, &model.ConversationContext{})
+	require.NoError(t, err)
+	assert.Contains(t, output, "Remembered")
+	assert.NotNil(t, agent.ToolPlan(forget))
+}
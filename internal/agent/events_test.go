@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receivedEvents returns the events sub has received so far, leaving out
+// log lines
+func receivedEvents(sub *events.Subscription) []events.Event {
+	var received []events.Event
+	for {
+		select {
+		case event := <-sub.Events():
+			if _, ok := event.(events.LogEvent); !ok {
+				received = append(received, event)
+			}
+		default:
+			return received
+		}
+	}
+}
+
+func TestAgent_PublishesEvents(t *testing.T) {
+	agent, _ := newTestAskAgent(t, &scriptedModel{responses: []*model.Response{{
+		ToolCalls: []model.ToolCall{{Name: "remember", Arguments: map[string]interface{}{"content": "Backups run at 2am"}}},
+		Usage:     model.Usage{PromptTokens: 40, CompletionTokens: 8, TotalTokens: 48},
+	}}})
+	first, second := agent.Subscribe(), agent.Subscribe()
+
+	result, err := agent.Ask(context.Background(), "Remember that backups run at 2am", "")
+	require.NoError(t, err)
+
+	received := receivedEvents(first)
+	require.Len(t, received, 3)
+	usage, ok := received[0].(events.TokenUsageEvent)
+	require.True(t, ok, "got %#v", received[0])
+	assert.Equal(t, "request", usage.Source)
+	assert.Equal(t, 48, usage.TotalTokens)
+	executed, ok := received[1].(events.ToolExecutedEvent)
+	require.True(t, ok, "got %#v", received[1])
+	assert.Equal(t, "remember", executed.Tool)
+	assert.True(t, executed.Success)
+	completed, ok := received[2].(events.ConversationCompletedEvent)
+	require.True(t, ok, "got %#v", received[2])
+	assert.Equal(t, result.ConversationID, completed.ConversationID)
+	assert.Equal(t, 1, completed.ToolCalls)
+	assert.Equal(t, 48, completed.TotalTokens)
+	assert.Equal(t, received, receivedEvents(second), "Every subscriber gets every event")
+}
+
+func TestAgent_ReplaysEventsToLateSubscribers(t *testing.T) {
+	agent, _ := newTestAskAgent(t, &MockModel{})
+	agent.handleMCPUpdate(ServerStatusUpdate{ServerName: "memory", Connected: true, ToolCount: 3})
+	agent.handleMCPUpdate(ToolUpdate{ServerName: "memory", Added: []string{"search"}})
+	agent.Notify(events.LevelWarning, "Storage unavailable")
+
+	assert.Equal(t, []events.Event{
+		events.ServerStatusEvent{Server: "memory", Connected: true, ToolCount: 3},
+		events.ToolsChangedEvent{Server: "memory", Added: []string{"search"}},
+		events.NotificationEvent{Text: "Storage unavailable", Level: events.LevelWarning},
+	}, receivedEvents(agent.Subscribe()))
+}
@@ -0,0 +1,219 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+)
+
+// fetchServerName is the name the fetch_url tool is registered under
+const fetchServerName = "othello-fetch"
+
+// maxFetchRedirects is the most redirects fetch_url follows
+const maxFetchRedirects = 5
+
+// fetchTool describes the tool fetchClient offers
+var fetchTool = mcp.Tool{
+	Name:        "fetch_url",
+	Description: "Download a web page, such as documentation, and return its text as markdown. Only some domains may be fetched.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"url": map[string]interface{}{"type": "string", "description": "The http or https address of the page"},
+		},
+		"required": []interface{}{"url"},
+	},
+}
+
+// errPrivateAddress refuses connections to this computer and the local
+// network, so pages can't be used to reach services that aren't public
+var errPrivateAddress = errors.New("address is on this computer or the local network")
+
+// fetchClient downloads web pages for the model. It is an in-process
+// mcp.Client, so its calls are validated, checked against tool policies, and
+// audited like those of any MCP server. Every host, including those
+// redirected to, must be allowed.
+type fetchClient struct {
+	config config.FetchToolConfig
+	client *http.Client
+}
+
+// newFetchClient returns the fetch tool for cfg
+func newFetchClient(cfg config.FetchToolConfig) *fetchClient {
+	c := &fetchClient{config: cfg}
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivate {
+		// Checked on the address actually dialed, after DNS resolution
+		dialer.Control = func(network, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+	c.client = &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+			}
+			return c.checkURL(req.URL)
+		},
+	}
+	return c
+}
+
+func (c *fetchClient) Connect(ctx context.Context) error    { return nil }
+func (c *fetchClient) Disconnect(ctx context.Context) error { return nil }
+func (c *fetchClient) IsConnected() bool                    { return true }
+func (c *fetchClient) GetTransport() string                 { return "builtin" }
+
+// ListTools implements mcp.Client
+func (c *fetchClient) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	return []mcp.Tool{fetchTool}, nil
+}
+
+// GetInfo implements mcp.Client
+func (c *fetchClient) GetInfo(ctx context.Context) (*mcp.ServerInfo, error) {
+	return &mcp.ServerInfo{Name: fetchServerName, Version: "1.0.0"}, nil
+}
+
+// CallTool implements mcp.Client. Failures are reported as error results so
+// the model can see what went wrong.
+func (c *fetchClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (*mcp.ToolResult, error) {
+	text, err := c.fetch(ctx, name, params)
+	if err != nil {
+		return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: text}}}, nil
+}
+
+// checkURL refuses addresses that aren't http or https or whose host isn't
+// allowed
+func (c *fetchClient) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("only http and https addresses can be fetched")
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("address has no host")
+	}
+	for _, pattern := range c.config.AllowedDomains {
+		if matched, _ := path.Match(strings.ToLower(pattern), host); matched {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not an allowed domain; add it to tools.fetch.allowed_domains", host)
+}
+
+// fetch downloads a page and converts it to text for the model
+func (c *fetchClient) fetch(ctx context.Context, name string, params map[string]interface{}) (string, error) {
+	if name != fetchTool.Name {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	raw, _ := params["url"].(string)
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if err := c.checkURL(u); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "othello-agent")
+	req.Header.Set("Accept", "text/html, text/markdown, text/plain, application/json;q=0.9, */*;q=0.5")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("fetch %s: %s", u, resp.Status)
+	}
+	if resp.ContentLength > int64(c.config.MaxSize) {
+		return "", fmt.Errorf("%s is %d bytes, more than the %d bytes allowed", u, resp.ContentLength, c.config.MaxSize)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(c.config.MaxSize)+1))
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", u, err)
+	}
+	if len(body) > c.config.MaxSize {
+		return "", fmt.Errorf("%s is more than the %d bytes allowed", u, c.config.MaxSize)
+	}
+
+	text, title, err := pageText(body, resp.Header.Get("Content-Type"), resp.Request.URL)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", u, err)
+	}
+	if utf8.RuneCountInString(text) > c.config.MaxOutput {
+		runes := []rune(text)
+		text = fmt.Sprintf("%s\n\n[%d more characters were cut]", string(runes[:c.config.MaxOutput]), len(runes)-c.config.MaxOutput)
+	}
+
+	header := "Source: " + resp.Request.URL.String()
+	if title != "" {
+		header = "# " + title + "\n" + header
+	}
+	return header + "\n\n" + text, nil
+}
+
+// pageText returns the readable text of a response body by its content type:
+// HTML is converted to markdown, and other text is returned as it is
+func pageText(body []byte, contentType string, base *url.URL) (text, title string, err error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" {
+		mediaType = http.DetectContentType(body)
+		mediaType, _, _ = mime.ParseMediaType(mediaType)
+	}
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		text, title = htmlToMarkdown(string(body), base)
+		return text, title, nil
+	case strings.HasPrefix(mediaType, "text/"), mediaType == "application/json",
+		mediaType == "application/xml", strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		if !utf8.Valid(body) {
+			return "", "", fmt.Errorf("the page isn't UTF-8 text")
+		}
+		return strings.TrimSpace(string(body)), "", nil
+	}
+	return "", "", fmt.Errorf("%s content can't be read as text", mediaType)
+}
+
+// isPrivateIP reports whether ip belongs to this computer or a private,
+// link-local, or unspecified network
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+// registerFetch offers the fetch_url tool to the model when it is enabled
+// and the agent isn't offline
+func (a *Agent) registerFetch() {
+	if !a.config.Tools.Fetch.Enabled || a.config.Agent.Offline {
+		return
+	}
+	if err := a.mcpRegistry.RegisterServer(fetchServerName, newFetchClient(a.config.Tools.Fetch)); err != nil {
+		a.logger.Printf("Warning: Failed to register the fetch_url tool: %v", err)
+	}
+}
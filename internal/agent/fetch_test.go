@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFetchClient(allowPrivate bool) *fetchClient {
+	return newFetchClient(config.FetchToolConfig{
+		Enabled:        true,
+		AllowedDomains: []string{"127.0.0.1", "*.example.com"},
+		AllowPrivate:   allowPrivate,
+		Timeout:        5 * time.Second,
+		MaxSize:        4096,
+		MaxOutput:      200,
+	})
+}
+
+func fetchURL(t *testing.T, client *fetchClient, address string) (string, bool) {
+	t.Helper()
+	result, err := client.CallTool(context.Background(), "fetch_url", map[string]interface{}{"url": address})
+	require.NoError(t, err)
+	return resultText(t, result), result.IsError
+}
+
+func TestFetchClient_ConvertsPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/docs":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><head><title>Guide</title><script>track()</script></head>
+<body><nav>Home | About</nav><h1>Install</h1><p>Run <code>go install</code> then see <a href="/next">the next step</a>.</p></body></html>`))
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok": true}`))
+		case "/big":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("a", 300)))
+		case "/huge":
+			w.Write([]byte(strings.Repeat("a", 5000)))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG"))
+		case "/away":
+			http.Redirect(w, r, "https://evil.test/", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := newTestFetchClient(true)
+
+	text, isError := fetchURL(t, client, server.URL+"/docs")
+	require.False(t, isError, text)
+	assert.Equal(t, "# Guide\nSource: "+server.URL+"/docs\n\n# Install\n\nRun `go install` then see [the next step]("+server.URL+"/next).", text)
+
+	text, _ = fetchURL(t, client, server.URL+"/data.json")
+	assert.True(t, strings.HasSuffix(text, `{"ok": true}`))
+
+	text, _ = fetchURL(t, client, server.URL+"/big")
+	assert.True(t, strings.HasSuffix(text, strings.Repeat("a", 200)+"\n\n[100 more characters were cut]"))
+
+	tests := map[string]string{
+		"/huge":    "more than the 4096 bytes allowed",
+		"/image":   "image/png content can't be read as text",
+		"/missing": "404 Not Found",
+		"/away":    "evil.test is not an allowed domain",
+	}
+	for path, want := range tests {
+		text, isError := fetchURL(t, client, server.URL+path)
+		assert.True(t, isError, path)
+		assert.Contains(t, text, want, path)
+	}
+}
+
+func TestFetchClient_RefusesAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+	client := newTestFetchClient(false)
+
+	text, isError := fetchURL(t, client, server.URL)
+	assert.True(t, isError)
+	assert.Contains(t, text, "address is on this computer or the local network")
+
+	text, _ = fetchURL(t, client, "https://other.test/page")
+	assert.Contains(t, text, "other.test is not an allowed domain")
+	text, _ = fetchURL(t, client, "file:///etc/passwd")
+	assert.Contains(t, text, "only http and https")
+	assert.NoError(t, client.checkURL(&url.URL{Scheme: "https", Host: "docs.example.com"}))
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	base, _ := url.Parse("https://docs.example.com/guide/")
+	page := `<!DOCTYPE html>
+<html><head><title>API &amp; more</title><style>p { color: red }</style></head>
+<body>
+<header>Site header</header>
+<main>
+<h2>Lists</h2>
+<ul><li>One<li>Two <em>items</em></ul>
+<ol><li>First</li><li><strong>Second</strong></li></ol>
+<pre><code>func main() {
+    fmt.Println("hi")
+}</code></pre>
+<p>See <a href="../ref">the reference</a>, <a href="#top">top</a><br>and more.</p>
+<table><tr><th>Name</th><th>Type</th></tr><tr><td>id</td><td>int</td></tr></table>
+</main>
+<footer>Copyright</footer>
+</body></html>`
+
+	markdown, title := htmlToMarkdown(page, base)
+	assert.Equal(t, "API & more", title)
+	assert.Equal(t, "## Lists\n\n- One\n- Two *items*\n\n1. First\n2. **Second**\n\n```\nfunc main() {\n    fmt.Println(\"hi\")\n}\n```\n\n"+
+		"See [the reference](https://docs.example.com/ref), [top]\nand more.\n\n| Name | Type |\n| id | int |", markdown)
+}
@@ -0,0 +1,398 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/indexer"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+)
+
+// filesServerName is the name the built-in file tools are registered under
+const filesServerName = "othello-files"
+
+const (
+	// maxSearchMatches is the most lines search_files returns
+	maxSearchMatches = 100
+
+	// maxSearchFileSize is the largest file, in bytes, search_files reads
+	maxSearchFileSize = 1 << 20
+
+	// maxSearchLine is the most characters of a matching line returned
+	maxSearchLine = 200
+
+	// maxListEntries is the most entries list_directory returns
+	maxListEntries = 500
+)
+
+// fileTools describes the tools filesClient offers; write_file is left out
+// when writing is not allowed
+var fileTools = []mcp.Tool{
+	{
+		Name:        "read_file",
+		Description: "Read a text file from the user's project",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{"type": "string", "description": "File path, relative to the project root or absolute"},
+			},
+			"required": []interface{}{"path"},
+		},
+	},
+	{
+		Name:        "write_file",
+		Description: "Create or replace a text file in the user's project, creating missing directories",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path":    map[string]interface{}{"type": "string", "description": "File path, relative to the project root or absolute"},
+				"content": map[string]interface{}{"type": "string", "description": "The whole new content of the file"},
+				"append":  map[string]interface{}{"type": "boolean", "description": "Add content to the end of the file instead of replacing it"},
+			},
+			"required": []interface{}{"path", "content"},
+		},
+	},
+	{
+		Name:        "list_directory",
+		Description: "List the files and directories in a directory of the user's project",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{"type": "string", "description": "Directory path, relative to the project root or absolute; defaults to the project root"},
+			},
+		},
+	},
+	{
+		Name:        "search_files",
+		Description: "Find lines matching a regular expression in the files of the user's project, skipping hidden and .gitignore'd files",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"pattern": map[string]interface{}{"type": "string", "description": "Regular expression to look for, such as func \\w+Handler"},
+				"path":    map[string]interface{}{"type": "string", "description": "Directory to search, relative to the project root or absolute; defaults to the project root"},
+				"include": map[string]interface{}{"type": "string", "description": "Only search files whose name matches this glob, such as *.go"},
+			},
+			"required": []interface{}{"pattern"},
+		},
+	},
+}
+
+// filesClient reads and writes files for the model inside the configured
+// roots. It is an in-process mcp.Client, so its calls are validated, checked
+// against tool policies, approved when they change data, and audited like
+// those of any MCP server. An MCP server offering tools of the same names
+// replaces them.
+type filesClient struct {
+	config config.FilesToolConfig
+	roots  []string // Absolute roots with symlinks resolved; the first is where relative paths start
+}
+
+// newFilesClient returns the file tools for cfg. Roots that don't exist are
+// left out; with none configured, the current directory is the only root.
+func newFilesClient(cfg config.FilesToolConfig) (*filesClient, error) {
+	dirs := cfg.Roots
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	client := &filesClient{config: cfg}
+	for _, dir := range dirs {
+		root, err := filepath.EvalSymlinks(absolutePath(dir))
+		if err != nil {
+			continue
+		}
+		client.roots = append(client.roots, root)
+	}
+	if len(client.roots) == 0 {
+		return nil, fmt.Errorf("none of the roots %s exist", strings.Join(dirs, ", "))
+	}
+	return client, nil
+}
+
+func (c *filesClient) Connect(ctx context.Context) error    { return nil }
+func (c *filesClient) Disconnect(ctx context.Context) error { return nil }
+func (c *filesClient) IsConnected() bool                    { return true }
+func (c *filesClient) GetTransport() string                 { return "builtin" }
+
+// ListTools implements mcp.Client
+func (c *filesClient) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	var tools []mcp.Tool
+	for _, tool := range fileTools {
+		if tool.Name == "write_file" && !c.config.AllowWrite {
+			continue
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// GetInfo implements mcp.Client
+func (c *filesClient) GetInfo(ctx context.Context) (*mcp.ServerInfo, error) {
+	return &mcp.ServerInfo{Name: filesServerName, Version: "1.0.0"}, nil
+}
+
+// CallTool implements mcp.Client. Failures are reported as error results so
+// the model can see what went wrong.
+func (c *filesClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (*mcp.ToolResult, error) {
+	text, err := c.call(ctx, name, params)
+	if err != nil {
+		return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: text}}}, nil
+}
+
+// call runs a file tool and describes the outcome
+func (c *filesClient) call(ctx context.Context, name string, params map[string]interface{}) (string, error) {
+	file, _ := params["path"].(string)
+	switch name {
+	case "read_file":
+		return c.read(file)
+	case "write_file":
+		if !c.config.AllowWrite {
+			return "", fmt.Errorf("writing files is turned off by tools.files.allow_write")
+		}
+		content, _ := params["content"].(string)
+		appendTo, _ := params["append"].(bool)
+		return c.write(file, content, appendTo)
+	case "list_directory":
+		return c.list(file)
+	case "search_files":
+		pattern, _ := params["pattern"].(string)
+		include, _ := params["include"].(string)
+		return c.search(ctx, pattern, file, include)
+	}
+	return "", fmt.Errorf("unknown file tool %q", name)
+}
+
+// resolve returns the absolute form of file, which must be inside one of the
+// roots once symlinks are followed. A file that doesn't exist yet is checked
+// by its nearest existing parent directory.
+func (c *filesClient) resolve(file string) (string, error) {
+	if strings.TrimSpace(file) == "" {
+		return c.roots[0], nil
+	}
+	target := file
+	if strings.HasPrefix(target, "~") {
+		target = absolutePath(target)
+	} else if !filepath.IsAbs(target) {
+		target = filepath.Join(c.roots[0], target)
+	}
+	target = filepath.Clean(target)
+
+	// Follow symlinks in the part of the path that exists
+	existing, missing := target, ""
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			target = filepath.Join(resolved, missing)
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing || !os.IsNotExist(err) {
+			return "", fmt.Errorf("resolve %s: %w", file, err)
+		}
+		missing = filepath.Join(filepath.Base(existing), missing)
+		existing = parent
+	}
+
+	if !insideAny(target, c.roots) {
+		return "", fmt.Errorf("%s is outside the allowed directories: %s", file, strings.Join(c.roots, ", "))
+	}
+	return target, nil
+}
+
+// display returns file as shown in results: relative to the root when
+// there is only one, and absolute otherwise
+func (c *filesClient) display(file string) string {
+	if len(c.roots) > 1 || !insideAny(file, c.roots[:1]) {
+		return file
+	}
+	rel, err := filepath.Rel(c.roots[0], file)
+	if err != nil {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+// read returns the text of a file, cut at the configured size. Only regular
+// files are read, since devices and pipes may never end, and no more than
+// the configured size is read into memory.
+func (c *filesClient) read(file string) (string, error) {
+	target, err := c.resolve(file)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", file, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", file)
+	}
+	f, err := os.Open(target)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", file, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, int64(c.config.MaxRead)+1))
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", file, err)
+	}
+	if !indexer.IsText(data) {
+		return "", fmt.Errorf("%s is a binary file", file)
+	}
+	if len(data) > c.config.MaxRead {
+		// Cut before the character the limit falls in, not through it
+		cut := c.config.MaxRead
+		for cut > 0 && !utf8.RuneStart(data[cut]) {
+			cut--
+		}
+		return fmt.Sprintf("%s\n[%d more bytes were cut]", data[:cut], max(info.Size(), int64(len(data)))-int64(cut)), nil
+	}
+	return string(data), nil
+}
+
+// write replaces or appends to a file, creating its directory if needed
+func (c *filesClient) write(file, content string, appendTo bool) (string, error) {
+	if strings.TrimSpace(file) == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	target, err := c.resolve(file)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", fmt.Errorf("create directory for %s: %w", file, err)
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	verb := "Wrote"
+	if appendTo {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		verb = "Appended"
+	}
+	f, err := os.OpenFile(target, flags, 0644)
+	if err != nil {
+		return "", fmt.Errorf("open %s: %w", file, err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write %s: %w", file, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write %s: %w", file, err)
+	}
+	return fmt.Sprintf("%s %d bytes to %s", verb, len(content), c.display(target)), nil
+}
+
+// list describes the entries of a directory, directories first
+func (c *filesClient) list(dir string) (string, error) {
+	target, err := c.resolve(dir)
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(target)
+	if err != nil {
+		return "", fmt.Errorf("list %s: %w", dir, err)
+	}
+	if len(entries) == 0 {
+		return fmt.Sprintf("%s is empty", c.display(target)), nil
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].IsDir() && !entries[j].IsDir()
+	})
+
+	lines := []string{c.display(target) + ":"}
+	for i, entry := range entries {
+		if i == maxListEntries {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(entries)-i))
+			break
+		}
+		if entry.IsDir() {
+			lines = append(lines, entry.Name()+"/")
+			continue
+		}
+		size := ""
+		if info, err := entry.Info(); err == nil {
+			size = fmt.Sprintf(" (%d bytes)", info.Size())
+		}
+		lines = append(lines, entry.Name()+size)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// search finds the lines matching pattern in the files under dir whose names
+// match include
+func (c *filesClient) search(ctx context.Context, pattern, dir, include string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+	if _, err := path.Match(include, ""); err != nil {
+		return "", fmt.Errorf("invalid include pattern %q", include)
+	}
+	target, err := c.resolve(dir)
+	if err != nil {
+		return "", err
+	}
+	files, err := indexer.ListFiles(target, maxSearchFileSize)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	more := 0
+	for _, rel := range files {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if include != "" {
+			if ok, _ := path.Match(include, path.Base(rel)); !ok {
+				continue
+			}
+		}
+		file := filepath.Join(target, filepath.FromSlash(rel))
+		data, err := os.ReadFile(file)
+		if err != nil || !indexer.IsText(data) {
+			continue
+		}
+		for i, line := range strings.Split(string(data), "\n") {
+			if !re.MatchString(line) {
+				continue
+			}
+			if len(matches) == maxSearchMatches {
+				more++
+				continue
+			}
+			matches = append(matches, fmt.Sprintf("%s:%d: %s", c.display(file), i+1, truncateString(strings.TrimSpace(line), maxSearchLine)))
+		}
+	}
+	if len(matches) == 0 {
+		return "No matches.", nil
+	}
+	if more > 0 {
+		matches = append(matches, fmt.Sprintf("... and %d more matches", more))
+	}
+	return strings.Join(matches, "\n"), nil
+}
+
+// registerFiles offers the file tools to the model when they are enabled
+func (a *Agent) registerFiles() {
+	if !a.config.Tools.Files.Enabled {
+		return
+	}
+	client, err := newFilesClient(a.config.Tools.Files)
+	if err == nil {
+		err = a.mcpRegistry.RegisterServer(filesServerName, client)
+	}
+	if err != nil {
+		a.logger.Printf("Warning: Failed to register the file tools: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFilesClient(t *testing.T) (*filesClient, string) {
+	t.Helper()
+	root := t.TempDir()
+	client, err := newFilesClient(config.FilesToolConfig{Enabled: true, Roots: []string{root}, AllowWrite: true, MaxRead: 64})
+	require.NoError(t, err)
+	return client, client.roots[0]
+}
+
+func callFileTool(t *testing.T, client *filesClient, name string, params map[string]interface{}) (string, bool) {
+	t.Helper()
+	result, err := client.CallTool(context.Background(), name, params)
+	require.NoError(t, err)
+	return resultText(t, result), result.IsError
+}
+
+func TestFilesClient_WriteReadList(t *testing.T) {
+	client, root := newTestFilesClient(t)
+
+	text, isError := callFileTool(t, client, "write_file", map[string]interface{}{"path": "notes/todo.md", "content": "- ship it\n"})
+	require.False(t, isError, text)
+	assert.Equal(t, "Wrote 10 bytes to notes/todo.md", text)
+	text, _ = callFileTool(t, client, "write_file", map[string]interface{}{"path": "notes/todo.md", "content": "- test it\n", "append": true})
+	assert.Equal(t, "Appended 10 bytes to notes/todo.md", text)
+
+	text, isError = callFileTool(t, client, "read_file", map[string]interface{}{"path": filepath.Join(root, "notes", "todo.md")})
+	assert.False(t, isError)
+	assert.Equal(t, "- ship it\n- test it\n", text)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "big.txt"), []byte(strings.Repeat("a", 100)), 0644))
+	text, _ = callFileTool(t, client, "read_file", map[string]interface{}{"path": "big.txt"})
+	assert.Equal(t, strings.Repeat("a", 64)+"\n[36 more bytes were cut]", text)
+
+	text, _ = callFileTool(t, client, "list_directory", map[string]interface{}{})
+	assert.Equal(t, ".:\nnotes/\nbig.txt (100 bytes)", text)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "accents.txt"), []byte(strings.Repeat("a", 63)+"éé"), 0644))
+	text, _ = callFileTool(t, client, "read_file", map[string]interface{}{"path": "accents.txt"})
+	assert.Equal(t, strings.Repeat("a", 63)+"\n[4 more bytes were cut]", text, "Characters aren't cut in half")
+	text, isError = callFileTool(t, client, "read_file", map[string]interface{}{"path": "notes"})
+	assert.True(t, isError)
+	assert.Contains(t, text, "notes is not a regular file")
+}
+
+func TestFilesClient_StaysInsideRoots(t *testing.T) {
+	client, root := newTestFilesClient(t)
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "link")))
+
+	for _, path := range []string{"../secret.txt", filepath.Join(outside, "secret.txt"), "link/secret.txt", "link/new.txt"} {
+		text, isError := callFileTool(t, client, "read_file", map[string]interface{}{"path": path})
+		assert.True(t, isError, path)
+		assert.Contains(t, text, "outside the allowed directories", path)
+	}
+	_, isError := callFileTool(t, client, "write_file", map[string]interface{}{"path": "link/new.txt", "content": "x"})
+	assert.True(t, isError)
+	_, err := os.Stat(filepath.Join(outside, "new.txt"))
+	assert.True(t, os.IsNotExist(err), "Nothing is written through the symlink")
+
+	client.config.AllowWrite = false
+	text, isError := callFileTool(t, client, "write_file", map[string]interface{}{"path": "a.txt", "content": "x"})
+	assert.True(t, isError)
+	assert.Contains(t, text, "tools.files.allow_write")
+	tools, err := client.ListTools(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, tools, 3, "write_file isn't offered")
+}
+
+func TestFilesClient_Search(t *testing.T) {
+	client, root := newTestFilesClient(t)
+	files := map[string]string{
+		".gitignore":      "build/\n",
+		"main.go":         "package main\n\nfunc handleLogin() {}\n",
+		"api/routes.go":   "package api\n\nfunc handleLogout() {}\n",
+		"api/README.md":   "handleLogin signs a user in\n",
+		"build/out.go":    "func handleLogin() {}\n",
+		".cache/index.go": "func handleLogin() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	text, isError := callFileTool(t, client, "search_files", map[string]interface{}{"pattern": `func handle\w+`, "include": "*.go"})
+	assert.False(t, isError)
+	assert.Equal(t, "api/routes.go:3: func handleLogout() {}\nmain.go:3: func handleLogin() {}", text)
+
+	text, _ = callFileTool(t, client, "search_files", map[string]interface{}{"pattern": "handleLogin", "path": "api"})
+	assert.Equal(t, "api/README.md:1: handleLogin signs a user in", text)
+
+	text, _ = callFileTool(t, client, "search_files", map[string]interface{}{"pattern": "nothing here"})
+	assert.Equal(t, "No matches.", text)
+
+	_, isError = callFileTool(t, client, "search_files", map[string]interface{}{"pattern": "("})
+	assert.True(t, isError)
+}
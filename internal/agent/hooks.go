@@ -0,0 +1,296 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// Hook events
+const (
+	HookPreToolCall  = "pre_tool_call"  // Before a tool runs; hooks may change its arguments or refuse it
+	HookPostToolCall = "post_tool_call" // After a tool ran; hooks may change its result
+	HookPrePrompt    = "pre_prompt"     // Before messages are sent to the model; hooks may change them
+	HookOnResponse   = "on_response"    // After the model answers; hooks may change the answer
+)
+
+// defaultHookTimeout is how long a hook program may run when its timeout isn't set
+const defaultHookTimeout = 10 * time.Second
+
+// maxHookOutput is the most a hook program may write to stdout
+const maxHookOutput = 1 << 20
+
+// HookEvent is what a hook is given. Hooks change the fields that apply to
+// their event; the others are empty.
+type HookEvent struct {
+	Event     string                 `json:"event"`
+	Tool      string                 `json:"tool,omitempty"`      // Tool events: the tool called
+	Arguments map[string]interface{} `json:"arguments,omitempty"` // Tool events: the call's arguments
+	Result    string                 `json:"result,omitempty"`    // post_tool_call: the result as the chat shows it
+	Messages  []model.Message        `json:"messages,omitempty"`  // pre_prompt: the messages for the model
+	Response  string                 `json:"response,omitempty"`  // on_response: the model's answer
+	Deny      string                 `json:"deny,omitempty"`      // pre_tool_call: set to refuse the call, saying why
+}
+
+// Hook runs at points in the agent's work. Hooks from the configuration run
+// first, in order, then those registered with RegisterHook.
+type Hook interface {
+	Name() string
+	Run(ctx context.Context, event *HookEvent) error
+}
+
+// hookFunc is a Hook made from a function
+type hookFunc struct {
+	name string
+	run  func(ctx context.Context, event *HookEvent) error
+}
+
+func (h *hookFunc) Name() string { return h.name }
+
+func (h *hookFunc) Run(ctx context.Context, event *HookEvent) error { return h.run(ctx, event) }
+
+// NewHook returns a Hook named name that runs run
+func NewHook(name string, run func(ctx context.Context, event *HookEvent) error) Hook {
+	return &hookFunc{name: name, run: run}
+}
+
+// registeredHook is a hook and where it runs
+type registeredHook struct {
+	event string
+	tools []string // Glob patterns of the tools it runs for; empty runs for all
+	hook  Hook
+}
+
+// RegisterHook runs h at event, one of the Hook* constants. For tool events,
+// tools limits it to tools matching these glob patterns.
+func (a *Agent) RegisterHook(event string, h Hook, tools ...string) {
+	a.hooks = append(a.hooks, registeredHook{event: event, tools: tools, hook: h})
+}
+
+// commandHook runs a program with the event as JSON on stdin. If it writes
+// anything to stdout, that is read as the changed event.
+type commandHook struct {
+	config config.HookConfig
+}
+
+func (h *commandHook) Name() string { return h.config.Command[0] }
+
+func (h *commandHook) Run(ctx context.Context, event *HookEvent) error {
+	input, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	timeout := h.config.Timeout
+	if timeout == 0 {
+		timeout = defaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	program := h.config.Command[0]
+	if strings.HasPrefix(program, "~/") {
+		program = absolutePath(program)
+	}
+	cmd := exec.CommandContext(ctx, program, h.config.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.WaitDelay = time.Second
+	stdout := &cappedBuffer{limit: maxHookOutput}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, truncateString(message, 200))
+		}
+		return err
+	}
+	if stdout.dropped > 0 {
+		return fmt.Errorf("wrote more than %d bytes", maxHookOutput)
+	}
+	output := bytes.TrimSpace(stdout.data)
+	if len(output) == 0 {
+		return nil
+	}
+	// Fields the program wrote replace those of the event; a map or list
+	// it wrote is taken as it is rather than merged into the old one
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(output, &fields); err != nil {
+		return fmt.Errorf("invalid output: %w", err)
+	}
+	changed := *event
+	if _, ok := fields["arguments"]; ok {
+		changed.Arguments = nil
+	}
+	if _, ok := fields["messages"]; ok {
+		changed.Messages = nil
+	}
+	if err := json.Unmarshal(output, &changed); err != nil {
+		return fmt.Errorf("invalid output: %w", err)
+	}
+	*event = changed
+	return nil
+}
+
+// hooksFor returns the hooks that run at event for tool, which is empty for
+// events that aren't about a tool
+func (a *Agent) hooksFor(event, tool string) []Hook {
+	var hooks []Hook
+	applies := func(hookEvent string, patterns []string) bool {
+		if hookEvent != event {
+			return false
+		}
+		if tool == "" || len(patterns) == 0 {
+			return true
+		}
+		for _, pattern := range patterns {
+			if toolMatches(pattern, tool) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, cfg := range a.config.Agent.Hooks {
+		if applies(cfg.Event, cfg.Tools) {
+			hooks = append(hooks, &commandHook{config: cfg})
+		}
+	}
+	for _, registered := range a.hooks {
+		if applies(registered.event, registered.tools) {
+			hooks = append(hooks, registered.hook)
+		}
+	}
+	return hooks
+}
+
+// runHooks runs the hooks for event in turn, each seeing the changes of
+// those before it. Hooks that fail before something happens stop it, so a
+// broken guard or redaction hook can't be bypassed; hooks that fail after it
+// are logged and skipped.
+func (a *Agent) runHooks(ctx context.Context, event *HookEvent) error {
+	name, tool := event.Event, event.Tool
+	blocking := name == HookPreToolCall || name == HookPrePrompt
+	for _, hook := range a.hooksFor(name, tool) {
+		err := hook.Run(ctx, event)
+		event.Event, event.Tool = name, tool
+		if err != nil {
+			a.logger.Printf("Hook %s failed at %s: %v", hook.Name(), name, err)
+			if blocking {
+				return fmt.Errorf("hook %s failed: %w", hook.Name(), err)
+			}
+			continue
+		}
+		if name == HookPreToolCall && event.Deny != "" {
+			a.logger.Printf("Hook %s refused %s: %s", hook.Name(), tool, event.Deny)
+			return fmt.Errorf("hook %s refused %s: %s", hook.Name(), tool, event.Deny)
+		}
+	}
+	return nil
+}
+
+// beforeToolCall runs the pre_tool_call hooks and returns the arguments to
+// call the tool with. The hooks change a copy of params.
+func (a *Agent) beforeToolCall(ctx context.Context, toolName string, params map[string]interface{}) (map[string]interface{}, error) {
+	if len(a.hooksFor(HookPreToolCall, toolName)) == 0 {
+		return params, nil
+	}
+	event := &HookEvent{Event: HookPreToolCall, Tool: toolName, Arguments: maps.Clone(params)}
+	if err := a.runHooks(ctx, event); err != nil {
+		return nil, err
+	}
+	return event.Arguments, nil
+}
+
+// afterToolCall runs the post_tool_call hooks and returns the result to show
+func (a *Agent) afterToolCall(ctx context.Context, toolName string, params map[string]interface{}, result string) string {
+	if len(a.hooksFor(HookPostToolCall, toolName)) == 0 {
+		return result
+	}
+	event := &HookEvent{Event: HookPostToolCall, Tool: toolName, Arguments: params, Result: result}
+	a.runHooks(ctx, event)
+	return event.Result
+}
+
+// WrapModel returns m with the pre_prompt and on_response hooks run around
+// each request
+func (a *Agent) WrapModel(m model.Model) model.Model {
+	if m == nil {
+		return nil
+	}
+	return &hookedModel{Model: m, agent: a}
+}
+
+// hookedModel runs the agent's prompt and response hooks around requests to
+// the model it wraps. Hooks are looked up per request, so hooks registered
+// later still run.
+type hookedModel struct {
+	model.Model
+	agent *Agent
+}
+
+func (m *hookedModel) Generate(ctx context.Context, prompt string, options model.GenerateOptions) (*model.Response, error) {
+	messages, err := m.beforePrompt(ctx, []model.Message{{Role: "user", Content: prompt}})
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) > 0 {
+		prompt = messages[len(messages)-1].Content
+	}
+	return m.afterResponse(ctx)(m.Model.Generate(ctx, prompt, options))
+}
+
+func (m *hookedModel) Chat(ctx context.Context, messages []model.Message, options model.GenerateOptions) (*model.Response, error) {
+	messages, err := m.beforePrompt(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	return m.afterResponse(ctx)(m.Model.Chat(ctx, messages, options))
+}
+
+func (m *hookedModel) ChatWithTools(ctx context.Context, messages []model.Message, tools []model.ToolDefinition, options model.GenerateOptions) (*model.Response, error) {
+	messages, err := m.beforePrompt(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	return m.afterResponse(ctx)(m.Model.ChatWithTools(ctx, messages, tools, options))
+}
+
+// beforePrompt runs the pre_prompt hooks on messages
+func (m *hookedModel) beforePrompt(ctx context.Context, messages []model.Message) ([]model.Message, error) {
+	m.agent.logBudget(ctx, "a model request")
+	if len(m.agent.hooksFor(HookPrePrompt, "")) == 0 {
+		return messages, nil
+	}
+	event := &HookEvent{Event: HookPrePrompt, Messages: append([]model.Message{}, messages...)}
+	if err := m.agent.runHooks(ctx, event); err != nil {
+		return nil, err
+	}
+	return event.Messages, nil
+}
+
+// afterResponse returns a function that runs the on_response hooks on a
+// successful response
+func (m *hookedModel) afterResponse(ctx context.Context) func(*model.Response, error) (*model.Response, error) {
+	return func(response *model.Response, err error) (*model.Response, error) {
+		if err == nil && response != nil {
+			m.agent.noteContextUsage(response.Usage.PromptTokens)
+		}
+		if err != nil || response == nil || len(m.agent.hooksFor(HookOnResponse, "")) == 0 {
+			return response, err
+		}
+		event := &HookEvent{Event: HookOnResponse, Response: response.Content}
+		m.agent.runHooks(ctx, event)
+		response.Content = event.Response
+		return response, nil
+	}
+}
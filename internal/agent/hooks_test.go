@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_ToolHooks(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	var seen []string
+	agent.RegisterHook(HookPreToolCall, NewHook("tag", func(ctx context.Context, event *HookEvent) error {
+		seen = append(seen, event.Tool)
+		event.Arguments["content"] = event.Arguments["content"].(string) + " (noted by a hook)"
+		return nil
+	}), "rem*")
+	agent.RegisterHook(HookPostToolCall, NewHook("stamp", func(ctx context.Context, event *HookEvent) error {
+		event.Result = "[checked] " + event.Result
+		return nil
+	}))
+	ctx := context.Background()
+
+	output, err := agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "Deploys are on Tuesdays"}, &model.ConversationContext{})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(output, "[checked] "), output)
+	output, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "deploys"}, &model.ConversationContext{})
+	require.NoError(t, err)
+	assert.Contains(t, output, "Deploys are on Tuesdays (noted by a hook)", "The pre_tool_call hook changed what was stored")
+	assert.Equal(t, []string{"remember"}, seen, "Hooks only run for the tools they match")
+
+	agent.RegisterHook(HookPreToolCall, NewHook("guard", func(ctx context.Context, event *HookEvent) error {
+		event.Deny = "facts need review"
+		return nil
+	}))
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "Backups run at 2am"}, &model.ConversationContext{})
+	assert.EqualError(t, err, "hook guard refused remember: facts need review")
+}
+
+func TestAgent_ToolHookArgumentsAreChecked(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	agent.config.Agent.ToolPolicies = []config.ToolPolicyConfig{{
+		Tool:      "recall",
+		Arguments: map[string]config.ArgumentPolicyConfig{"query": {Banned: []string{"passwords"}}},
+	}}
+	query := interface{}("passwords")
+	agent.RegisterHook(HookPreToolCall, NewHook("rewrite", func(ctx context.Context, event *HookEvent) error {
+		event.Arguments["query"] = query
+		return nil
+	}), "recall")
+	ctx := context.Background()
+	params := map[string]interface{}{"query": "deploys"}
+
+	_, err := agent.ExecuteToolUnifiedWithContext(ctx, "recall", params, &model.ConversationContext{})
+	var denial *PolicyDenial
+	require.ErrorAs(t, err, &denial, "The policies check the hook's arguments")
+	assert.Equal(t, "deploys", params["query"], "Hooks change a copy of the model's arguments")
+
+	query = 42
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", params, &model.ConversationContext{})
+	assert.ErrorIs(t, err, mcp.ErrInvalidArguments, "The hook's arguments are validated")
+}
+
+func TestAgent_CommandHooks(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	agent.config.Agent.Hooks = []config.HookConfig{
+		{Event: HookPreToolCall, Tools: []string{"recall"}, Command: []string{"sh", "-c", `grep -q '"tool":"recall"' && echo '{"arguments": {"query": "lunch"}}'`}},
+		{Event: HookPostToolCall, Command: []string{"sh", "-c", `cat >/dev/null; echo '{"result": "redacted"}'`}},
+	}
+	ctx := context.Background()
+
+	output, err := agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "deploys", "limit": 3}, &model.ConversationContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "redacted", output)
+
+	event := &HookEvent{Event: HookPreToolCall, Tool: "recall", Arguments: map[string]interface{}{"query": "deploys", "limit": 3}}
+	require.NoError(t, agent.runHooks(ctx, event))
+	assert.Equal(t, map[string]interface{}{"query": "lunch"}, event.Arguments, "Arguments written by the program replace the old ones")
+
+	agent.config.Agent.Hooks = []config.HookConfig{
+		{Event: HookPreToolCall, Command: []string{"sh", "-c", "echo 'policy server down' >&2; exit 1"}},
+		{Event: HookPostToolCall, Command: []string{"sh", "-c", "echo not json"}},
+	}
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "deploys"}, &model.ConversationContext{})
+	assert.ErrorContains(t, err, "hook sh failed: exit status 1: policy server down", "A failing pre_tool_call hook stops the call")
+
+	agent.config.Agent.Hooks = agent.config.Agent.Hooks[1:]
+	output, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "deploys"}, &model.ConversationContext{})
+	require.NoError(t, err, "A failing post_tool_call hook is skipped")
+	assert.NotEqual(t, "not json", output)
+}
+
+func TestAgent_ModelHooks(t *testing.T) {
+	m := &scriptedModel{responses: []*model.Response{{Content: "Your key is sk-123"}}}
+	agent := newTestRetryAgent(t, m, 0)
+	agent.RegisterHook(HookPrePrompt, NewHook("redact", func(ctx context.Context, event *HookEvent) error {
+		for i := range event.Messages {
+			event.Messages[i].Content = strings.ReplaceAll(event.Messages[i].Content, "hunter2", "[redacted]")
+		}
+		return nil
+	}))
+	agent.RegisterHook(HookOnResponse, NewHook("mask", func(ctx context.Context, event *HookEvent) error {
+		event.Response = strings.ReplaceAll(event.Response, "sk-123", "sk-***")
+		return nil
+	}))
+	wrapped := agent.WrapModel(m)
+
+	original := []model.Message{{Role: "user", Content: "My password is hunter2"}}
+	response, err := wrapped.ChatWithTools(context.Background(), original, nil, model.GenerateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Your key is sk-***", response.Content)
+	require.Len(t, m.received, 1)
+	assert.Equal(t, "My password is [redacted]", m.received[0][0].Content)
+	assert.Equal(t, "My password is hunter2", original[0].Content, "The caller's messages aren't changed")
+
+	agent.RegisterHook(HookPrePrompt, NewHook("broken", func(ctx context.Context, event *HookEvent) error {
+		return errors.New("vault unreachable")
+	}))
+	_, err = wrapped.ChatWithTools(context.Background(), original, nil, model.GenerateOptions{})
+	assert.EqualError(t, err, "hook broken failed: vault unreachable")
+	assert.Len(t, m.received, 1, "Nothing is sent when a pre_prompt hook fails")
+}
@@ -0,0 +1,271 @@
+package agent
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// skippedElements hold no content worth reading: scripts, styles, and page
+// furniture such as navigation
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true,
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true,
+	"button": true, "iframe": true, "head": true,
+}
+
+// blockElements start on a new paragraph
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true,
+	"table": true, "dl": true, "figure": true, "hr": true, "details": true,
+}
+
+var (
+	htmlTag        = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines     = regexp.MustCompile(`\n{3,}`)
+	trailingSpaces = regexp.MustCompile(`[ \t]+\n`)
+)
+
+// htmlConverter turns HTML into markdown. It reads the page with the
+// encoding/xml decoder in its lenient HTML mode, which copes with most real
+// pages; what it can't parse is kept as text with the tags removed.
+type htmlConverter struct {
+	base  *url.URL
+	out   strings.Builder
+	title string
+
+	skip    int      // Depth inside skipped elements
+	pre     int      // Depth inside <pre>
+	inTitle bool     // Inside <title>, which is read even though <head> is skipped
+	links   []string // Targets of the open <a> elements
+	lists   []int    // Items so far in each open list; -1 for unordered lists
+}
+
+// htmlToMarkdown converts an HTML page to markdown, resolving links against
+// base, and returns it with the page's title
+func htmlToMarkdown(page string, base *url.URL) (markdown, title string) {
+	c := &htmlConverter{base: base}
+	decoder := xml.NewDecoder(strings.NewReader(page))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Keep what was converted and the rest of the page as plain text
+			rest := page[min(int(decoder.InputOffset()), len(page)):]
+			c.text(htmlTag.ReplaceAllString(rest, " "))
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			c.start(strings.ToLower(t.Name.Local), t.Attr)
+		case xml.EndElement:
+			c.end(strings.ToLower(t.Name.Local))
+		case xml.CharData:
+			c.text(string(t))
+		}
+	}
+
+	markdown = trailingSpaces.ReplaceAllString(c.out.String(), "\n")
+	markdown = blankLines.ReplaceAllString(markdown, "\n\n")
+	return strings.TrimSpace(markdown), strings.TrimSpace(c.title)
+}
+
+// start handles an opening tag
+func (c *htmlConverter) start(name string, attrs []xml.Attr) {
+	if name == "title" {
+		c.inTitle = true
+	}
+	if c.skip > 0 || skippedElements[name] {
+		c.skip++
+		return
+	}
+	switch {
+	case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+		c.block()
+		c.out.WriteString(strings.Repeat("#", int(name[1]-'0')) + " ")
+	case blockElements[name]:
+		c.block()
+	case name == "br":
+		c.out.WriteString("\n")
+	case name == "pre":
+		c.block()
+		c.out.WriteString("```\n")
+		c.pre++
+	case name == "code" && c.pre == 0:
+		c.out.WriteString("`")
+	case name == "strong" || name == "b":
+		c.out.WriteString("**")
+	case name == "em" || name == "i":
+		c.out.WriteString("*")
+	case name == "blockquote":
+		c.block()
+		c.out.WriteString("> ")
+	case name == "ul" || name == "ol":
+		if len(c.lists) == 0 {
+			c.block()
+		} else {
+			c.line()
+		}
+		if name == "ol" {
+			c.lists = append(c.lists, 0)
+		} else {
+			c.lists = append(c.lists, -1)
+		}
+	case name == "li":
+		c.line()
+		indent := strings.Repeat("  ", max(len(c.lists)-1, 0))
+		if n := len(c.lists); n > 0 && c.lists[n-1] >= 0 {
+			c.lists[n-1]++
+			c.out.WriteString(fmt.Sprintf("%s%d. ", indent, c.lists[n-1]))
+		} else {
+			c.out.WriteString(indent + "- ")
+		}
+	case name == "tr" || name == "dt":
+		c.line()
+	case name == "td" || name == "th":
+		if out := c.out.String(); strings.HasSuffix(out, "\n") || out == "" {
+			c.out.WriteString("| ")
+		} else {
+			c.out.WriteString(" | ")
+		}
+	case name == "a":
+		c.links = append(c.links, c.resolve(attr(attrs, "href")))
+		c.out.WriteString("[")
+	}
+}
+
+// end handles a closing tag
+func (c *htmlConverter) end(name string) {
+	if name == "title" {
+		c.inTitle = false
+	}
+	if c.skip > 0 {
+		c.skip--
+		return
+	}
+	switch {
+	case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6', blockElements[name], name == "blockquote":
+		c.block()
+	case name == "pre":
+		if c.pre > 0 {
+			c.pre--
+		}
+		c.line()
+		c.out.WriteString("```")
+		c.block()
+	case name == "code" && c.pre == 0:
+		c.out.WriteString("`")
+	case name == "strong" || name == "b":
+		c.out.WriteString("**")
+	case name == "em" || name == "i":
+		c.out.WriteString("*")
+	case name == "ul" || name == "ol":
+		if len(c.lists) > 0 {
+			c.lists = c.lists[:len(c.lists)-1]
+		}
+		if len(c.lists) == 0 {
+			c.block()
+		} else {
+			c.line()
+		}
+	case name == "tr":
+		c.out.WriteString(" |")
+		c.line()
+	case name == "a":
+		if n := len(c.links); n > 0 {
+			if href := c.links[n-1]; href != "" {
+				c.out.WriteString("](" + href + ")")
+			} else {
+				c.out.WriteString("]")
+			}
+			c.links = c.links[:n-1]
+		}
+	}
+}
+
+// text writes text content, collapsing whitespace outside <pre>
+func (c *htmlConverter) text(text string) {
+	if c.inTitle {
+		c.title += text
+	}
+	if c.skip > 0 {
+		return
+	}
+	if c.pre > 0 {
+		c.out.WriteString(text)
+		return
+	}
+	collapsed := strings.Join(strings.Fields(text), " ")
+	if collapsed == "" {
+		if text != "" && !c.spaced() {
+			c.out.WriteString(" ")
+		}
+		return
+	}
+	if text[0] == ' ' || text[0] == '\n' || text[0] == '\t' {
+		if !c.spaced() {
+			collapsed = " " + collapsed
+		}
+	}
+	if last := text[len(text)-1]; last == ' ' || last == '\n' || last == '\t' {
+		collapsed += " "
+	}
+	c.out.WriteString(collapsed)
+}
+
+// spaced reports whether the output ends with a space or a line break, so
+// text written next needs no space before it
+func (c *htmlConverter) spaced() bool {
+	out := c.out.String()
+	line := out[strings.LastIndexByte(out, '\n')+1:]
+	return strings.TrimSpace(line) == "" || strings.HasSuffix(line, " ")
+}
+
+// line starts a new line unless one was just started
+func (c *htmlConverter) line() {
+	if out := c.out.String(); out != "" && !strings.HasSuffix(out, "\n") {
+		c.out.WriteString("\n")
+	}
+}
+
+// block starts a new paragraph
+func (c *htmlConverter) block() {
+	c.line()
+	c.out.WriteString("\n")
+}
+
+// resolve makes a link absolute. Links to places in the same page and
+// javascript: links are dropped.
+func (c *htmlConverter) resolve(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return ""
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if c.base != nil {
+		ref = c.base.ResolveReference(ref)
+	}
+	return ref.String()
+}
+
+// attr returns the value of the attribute called name
+func attr(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// intentPrompt asks the model to classify a request. The examples show the
+// output expected, which small models follow more reliably than a description.
+const intentPrompt = `Classify what the user wants into exactly one intent:
+- search: find, look up, list, or show information
+- create: add, save, or remember something new
+- update: change or fix something that exists
+- delete: remove or clear something
+- analyze: summarize, report on, or find patterns in information
+- transform: convert, format, import, or export information
+- connect: relate or link things together
+- help: ask how to do something or how something works
+- conversation: chat that needs no tools
+
+Reply with only a JSON object with the intent and your confidence from 0 to 1.
+
+Request: find my notes about the deploy process
+{"intent": "search", "confidence": 0.95}
+
+Request: remember that the staging database moved to db2
+{"intent": "create", "confidence": 0.9}
+
+Request: what can you tell me about how trends in my notes changed this month?
+{"intent": "analyze", "confidence": 0.8}
+
+Request: the meeting is on Thursday now, not Tuesday
+{"intent": "update", "confidence": 0.7}
+
+Request: thanks, that's all for today
+{"intent": "conversation", "confidence": 0.9}
+
+Request: %s
+`
+
+// knownIntents are the intents the model may answer with
+var knownIntents = map[Intent]bool{
+	IntentSearch: true, IntentCreate: true, IntentUpdate: true, IntentDelete: true,
+	IntentAnalyze: true, IntentTransform: true, IntentConnect: true, IntentHelp: true,
+	IntentConversation: true,
+}
+
+// classifyWithModel asks the classifier's model for the intent of userInput
+func (ic *IntentClassifier) classifyWithModel(ctx context.Context, userInput string) (Intent, float64, error) {
+	if ic.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ic.timeout)
+		defer cancel()
+	}
+	prompt := fmt.Sprintf(intentPrompt, strings.Join(strings.Fields(userInput), " "))
+	response, err := ic.model.Generate(ctx, prompt, model.GenerateOptions{Temperature: 0, MaxTokens: 50})
+	if err != nil {
+		return "", 0, fmt.Errorf("model request failed: %w", err)
+	}
+	return parseIntent(response.Content)
+}
+
+// parseIntent reads the model's classification, ignoring any text around
+// the JSON object
+func parseIntent(text string) (Intent, float64, error) {
+	start, end := strings.Index(text, "{"), strings.Index(text, "}")
+	if start == -1 || end < start {
+		return "", 0, fmt.Errorf("no JSON object in %q", truncateString(text, 100))
+	}
+	var answer struct {
+		Intent     string  `json:"intent"`
+		Confidence float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &answer); err != nil {
+		return "", 0, fmt.Errorf("invalid classification: %w", err)
+	}
+	intent := Intent(strings.ToLower(strings.TrimSpace(answer.Intent)))
+	if !knownIntents[intent] {
+		return "", 0, fmt.Errorf("unknown intent %q", answer.Intent)
+	}
+	return intent, min(max(answer.Confidence, 0), 1), nil
+}
+
+// intentModel returns the model that classifies requests: agent.intent.model,
+// or the chat model when none is set
+func (a *Agent) intentModel() model.Model {
+	name := a.config.Agent.Intent.Model
+	if name == "" {
+		name = a.config.Model.Name
+	}
+	m := model.NewOllamaModel(a.config.Ollama.Host, name)
+	m.SetTransport(a.modelTransport())
+	return a.WrapModel(m)
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// classifyingModel answers Generate with a fixed reply and keeps the prompt
+type classifyingModel struct {
+	MockModel
+	reply  string
+	err    error
+	prompt string
+}
+
+func (m *classifyingModel) Generate(ctx context.Context, prompt string, options model.GenerateOptions) (*model.Response, error) {
+	m.prompt = prompt
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &model.Response{Content: m.reply}, nil
+}
+
+func newTestClassifier(m model.Model) *IntentClassifier {
+	logger := &MockLogger{}
+	classifier := NewIntentClassifier(NewToolDiscovery(mcp.NewToolRegistry(logger), logger), logger)
+	classifier.SetModel(m, time.Second)
+	return classifier
+}
+
+func TestIntentClassifier_UsesModel(t *testing.T) {
+	m := &classifyingModel{reply: `Sure! {"intent": "delete", "confidence": 0.85}`}
+	classifier := newTestClassifier(m)
+
+	intent, confidence, err := classifier.ClassifyIntent(context.Background(), "get rid of\nthe old staging notes")
+	require.NoError(t, err)
+	assert.Equal(t, IntentDelete, intent, "Keywords alone would read this as a search")
+	assert.Equal(t, 0.85, confidence)
+	assert.Contains(t, m.prompt, "Request: get rid of the old staging notes\n")
+}
+
+func TestIntentClassifier_FallsBackToKeywords(t *testing.T) {
+	for name, m := range map[string]*classifyingModel{
+		"model fails":      {err: errors.New("connection refused")},
+		"not JSON":         {reply: "This is a search request."},
+		"unknown intent":   {reply: `{"intent": "purchase", "confidence": 0.9}`},
+		"malformed object": {reply: `{"intent": search}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			intent, _, err := newTestClassifier(m).ClassifyIntent(context.Background(), "search for deploy notes")
+			require.NoError(t, err)
+			assert.Equal(t, IntentSearch, intent)
+		})
+	}
+}
+
+func TestParseIntent(t *testing.T) {
+	intent, confidence, err := parseIntent("```json\n{\"intent\": \" Help \", \"confidence\": 1.7}\n```")
+	require.NoError(t, err)
+	assert.Equal(t, IntentHelp, intent)
+	assert.Equal(t, 1.0, confidence, "Confidence is kept between 0 and 1")
+
+	_, _, err = parseIntent("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+)
+
+// logStreamer publishes agent log lines for the TUI's activity pane. It is
+// only enabled while the TUI runs so startup logging can't crowd status
+// events out of the events replayed to it.
+type logStreamer struct {
+	enabled atomic.Bool
+	bus     *events.Bus
+}
+
+// Write implements io.Writer, publishing each complete log line as an
+// events.LogEvent
+func (s *logStreamer) Write(p []byte) (int, error) {
+	if !s.enabled.Load() {
+		return len(p), nil
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if line = stripLogHeader(line); line == "" {
+			continue
+		}
+		// Publishing never blocks or logs, which would recurse
+		s.bus.Publish(events.LogEvent{Text: line})
+	}
+	return len(p), nil
+}
+
+// stripLogHeader removes the "[AGENT] 2006/01/02 15:04:05 " prefix written by
+// the agent logger; the activity pane shows its own timestamps
+func stripLogHeader(line string) string {
+	line = strings.TrimPrefix(line, "[AGENT] ")
+	const stamp = "2006/01/02 15:04:05 "
+	if len(line) >= len(stamp) && line[4] == '/' && line[7] == '/' && line[13] == ':' {
+		line = line[len(stamp):]
+	}
+	return line
+}
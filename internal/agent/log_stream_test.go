@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"log"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/danieleugenewilliams/othello-agent/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogStreamer_OnlyStreamsWhenEnabled(t *testing.T) {
+	bus := events.NewBus(0)
+	sub := bus.Subscribe()
+	streamer := &logStreamer{bus: bus}
+	logger := log.New(streamer, "[AGENT] ", log.LstdFlags)
+
+	logger.Printf("before the TUI starts")
+	assert.Len(t, sub.Events(), 0)
+
+	streamer.enabled.Store(true)
+	logger.Printf("Connected to %s", "local-memory")
+
+	require.Len(t, sub.Events(), 1)
+	assert.Equal(t, events.LogEvent{Text: "Connected to local-memory"}, <-sub.Events())
+}
+
+func TestLogStreamer_PublishesEachLine(t *testing.T) {
+	bus := events.NewBus(0)
+	sub := bus.Subscribe()
+	streamer := &logStreamer{bus: bus}
+	streamer.enabled.Store(true)
+
+	n, err := streamer.Write([]byte("first\nsecond\n"))
+	require.NoError(t, err)
+	assert.Equal(t, len("first\nsecond\n"), n)
+	assert.Equal(t, events.LogEvent{Text: "first"}, <-sub.Events())
+	assert.Equal(t, events.LogEvent{Text: "second"}, <-sub.Events())
+}
+
+func TestAgentLogger_MasksSecrets(t *testing.T) {
+	secrets.Register("sk-log-stream-secret")
+	agent, _ := newTestAskAgent(t, &failingModel{})
+	sub := agent.Subscribe()
+	defer sub.Close()
+	agent.logStream.enabled.Store(true)
+
+	agent.logger.Printf("Connecting with API_KEY=%s", "sk-log-stream-secret")
+	agent.Notify(events.LevelError, "Server rejected sk-log-stream-secret")
+
+	var texts []string
+	for len(sub.Events()) > 0 {
+		switch event := (<-sub.Events()).(type) {
+		case events.LogEvent:
+			texts = append(texts, event.Text)
+		case events.NotificationEvent:
+			texts = append(texts, event.Text)
+		}
+	}
+	assert.Contains(t, texts, "Connecting with API_KEY=********")
+	assert.Contains(t, texts, "Server rejected ********")
+	for _, text := range texts {
+		assert.NotContains(t, text, "sk-log-stream-secret")
+	}
+}
@@ -0,0 +1,230 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// logPollInterval is how often a followed log file is checked for new lines
+const logPollInterval = 250 * time.Millisecond
+
+// logTimeLayout is the timestamp log.LstdFlags writes
+const logTimeLayout = "2006/01/02 15:04:05"
+
+// logHeader matches the start of a log entry: the logger's prefix, its
+// timestamp, and any tags, such as "[AGENT] 2006/01/02 15:04:05 [INFO] [MCP] "
+var logHeader = regexp.MustCompile(`^(?:\[AGENT\] )?(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}) ((?:\[[A-Za-z_-]+\] )*)`)
+
+// LogEntry is one entry of the log file. An entry spans several lines when
+// its message does.
+type LogEntry struct {
+	Time      time.Time
+	Level     string // debug, info, warn, or error
+	Component string // What wrote it, such as agent or mcp
+	Message   string
+	Text      string // The entry as it is in the file
+}
+
+// LogFilter selects log entries
+type LogFilter struct {
+	Level     string // The least severe level shown; empty shows all
+	Component string // Only entries of this component; empty shows all
+}
+
+// Validate checks that the filter's level is a logging.level
+func (f LogFilter) Validate() error {
+	if _, ok := logLevels[f.Level]; f.Level != "" && !ok {
+		return fmt.Errorf("unknown log level %q; use debug, info, warn, or error", f.Level)
+	}
+	return nil
+}
+
+// Match reports whether entry passes the filter
+func (f LogFilter) Match(entry LogEntry) bool {
+	if f.Level != "" && logLevels[entry.Level] < logLevels[f.Level] {
+		return false
+	}
+	return f.Component == "" || strings.EqualFold(entry.Component, f.Component)
+}
+
+// ParseLogLine reads the first line of a log entry. It reports false for a
+// line that continues the previous entry's message.
+func ParseLogLine(line string) (LogEntry, bool) {
+	match := logHeader.FindStringSubmatch(line)
+	if match == nil {
+		return LogEntry{}, false
+	}
+	entry := LogEntry{Component: "agent", Message: line[len(match[0]):], Text: line}
+	entry.Time, _ = time.ParseInLocation(logTimeLayout, match[1], time.Local)
+
+	for _, tag := range strings.Fields(match[2]) {
+		tag = strings.ToLower(strings.Trim(tag, "[]"))
+		if _, ok := logLevels[tag]; ok && entry.Level == "" {
+			entry.Level = tag
+		} else if entry.Component == "agent" {
+			entry.Component = tag
+		}
+	}
+	if entry.Level == "" {
+		entry.Level = messageLevel(entry.Message)
+	}
+	return entry, true
+}
+
+// messageLevel guesses the level of a message logged without one
+func messageLevel(message string) string {
+	switch {
+	case strings.HasPrefix(message, "Warning"):
+		return "warn"
+	case strings.HasPrefix(message, "Error"), strings.HasPrefix(message, "Failed"):
+		return "error"
+	}
+	return "info"
+}
+
+// LogFilePath returns the path of the log file logging.file names,
+// expanding a leading ~
+func LogFilePath(file string) (string, error) {
+	if strings.HasPrefix(file, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		file = filepath.Join(homeDir, file[2:])
+	}
+	return file, nil
+}
+
+// TailLog passes the last n entries of the log file at path that match
+// filter to show, oldest first; n of 0 passes them all. When follow is set,
+// it then waits for new entries and passes those that match until ctx is
+// done.
+func TailLog(ctx context.Context, path string, n int, follow bool, filter LogFilter, show func(LogEntry)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no log file at %s yet; it is created when Othello first runs", path)
+		}
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	var recent []LogEntry
+	reader := newLogReader(file, filter)
+	for entry, ok := reader.next(); ok; entry, ok = reader.next() {
+		recent = append(recent, entry)
+		if n > 0 && len(recent) > n {
+			recent = recent[1:]
+		}
+	}
+	if err := reader.err(); err != nil {
+		return fmt.Errorf("failed to read log file: %w", err)
+	}
+	for _, entry := range recent {
+		show(entry)
+	}
+	if !follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(logPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		// Start over when the file was truncated or replaced
+		offset, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return fmt.Errorf("failed to read log file: %w", err)
+		}
+		if info, err := os.Stat(path); err == nil && (info.Size() < offset || !sameFile(file, info)) {
+			if reopened, err := os.Open(path); err == nil {
+				file.Close()
+				file = reopened
+				reader = newLogReader(file, filter)
+			}
+		}
+		for entry, ok := reader.next(); ok; entry, ok = reader.next() {
+			show(entry)
+		}
+		if err := reader.err(); err != nil {
+			return fmt.Errorf("failed to read log file: %w", err)
+		}
+	}
+}
+
+// sameFile reports whether file is the file info describes
+func sameFile(file *os.File, info os.FileInfo) bool {
+	opened, err := file.Stat()
+	return err == nil && os.SameFile(opened, info)
+}
+
+// logReader reads the entries of a log file that match a filter. An entry is
+// only returned once a line after it, or the end of the file, shows it is
+// complete; a partly written last line is kept until the rest arrives.
+type logReader struct {
+	reader  *bufio.Reader
+	filter  LogFilter
+	partial string    // The start of a line that hasn't ended yet
+	pending *LogEntry // The entry being read, which may continue
+	failure error
+}
+
+func newLogReader(r io.Reader, filter LogFilter) *logReader {
+	return &logReader{reader: bufio.NewReader(r), filter: filter}
+}
+
+// next returns the next matching entry that is complete, or false when there
+// are none yet
+func (r *logReader) next() (LogEntry, bool) {
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err != nil {
+			r.partial += line
+			if err != io.EOF {
+				r.failure = err
+			}
+			// The entry read so far is complete unless a line continues it
+			if r.pending != nil && r.partial == "" {
+				entry := *r.pending
+				r.pending = nil
+				if r.filter.Match(entry) {
+					return entry, true
+				}
+			}
+			return LogEntry{}, false
+		}
+		line = strings.TrimRight(r.partial+line, "\r\n")
+		r.partial = ""
+
+		entry, ok := ParseLogLine(line)
+		if !ok {
+			if r.pending != nil {
+				r.pending.Message += "\n" + line
+				r.pending.Text += "\n" + line
+			}
+			continue
+		}
+		previous := r.pending
+		r.pending = &entry
+		if previous != nil && r.filter.Match(*previous) {
+			return *previous, true
+		}
+	}
+}
+
+// err returns the error that stopped reading, if any
+func (r *logReader) err() error {
+	return r.failure
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogLine(t *testing.T) {
+	entry, ok := ParseLogLine("[AGENT] 2026/10/16 14:36:07 [INFO] [MCP] Registered MCP server notes")
+	require.True(t, ok)
+	assert.Equal(t, "info", entry.Level)
+	assert.Equal(t, "mcp", entry.Component)
+	assert.Equal(t, "Registered MCP server notes", entry.Message)
+	assert.Equal(t, time.Date(2026, 10, 16, 14, 36, 7, 0, time.Local), entry.Time)
+
+	entry, ok = ParseLogLine("[AGENT] 2026/10/16 14:36:07 Warning: Failed to open storage: locked")
+	require.True(t, ok)
+	assert.Equal(t, "warn", entry.Level, "Untagged messages are leveled by how they start")
+	assert.Equal(t, "agent", entry.Component)
+
+	entry, ok = ParseLogLine("2026/10/16 14:36:07 [EXTRACTION] Processing text content")
+	require.True(t, ok, "Lines of the standard logger are read too")
+	assert.Equal(t, "extraction", entry.Component)
+
+	_, ok = ParseLogLine("  continued message")
+	assert.False(t, ok)
+}
+
+func TestTailLog_FiltersAndFollows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "othello.log")
+	require.NoError(t, os.WriteFile(path, []byte(`[AGENT] 2026/10/16 14:36:07 Starting Othello AI Agent
+[AGENT] 2026/10/16 14:36:07 [ERROR] [MCP] Failed to connect to notes: exit status 1
+stderr: notes-server: not found
+[AGENT] 2026/10/16 14:36:08 [INFO] [MCP] Registered MCP server files
+[AGENT] 2026/10/16 14:36:09 [ERROR] [MCP] Tool execution failed read_file: denied
+[AGENT] 2026/10/16 14:36:10 Error saving conversation: disk full
+`), 0644))
+
+	var shown []LogEntry
+	show := func(entry LogEntry) { shown = append(shown, entry) }
+	require.NoError(t, TailLog(context.Background(), path, 0, false, LogFilter{Level: "error", Component: "mcp"}, show))
+	require.Len(t, shown, 2)
+	assert.Equal(t, "Failed to connect to notes: exit status 1\nstderr: notes-server: not found", shown[0].Message, "Continuation lines stay with their entry")
+	assert.Equal(t, "Tool execution failed read_file: denied", shown[1].Message)
+
+	shown = nil
+	require.NoError(t, TailLog(context.Background(), path, 2, false, LogFilter{}, show))
+	require.Len(t, shown, 2, "Only the last entries are shown")
+	assert.Equal(t, "Error saving conversation: disk full", shown[1].Message)
+
+	var mu sync.Mutex
+	var followed []string
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- TailLog(ctx, path, 1, true, LogFilter{Level: "warn"}, func(entry LogEntry) {
+			mu.Lock()
+			defer mu.Unlock()
+			followed = append(followed, entry.Message)
+		})
+	}()
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(followed)
+	}
+	require.Eventually(t, func() bool { return count() == 1 }, 5*time.Second, 10*time.Millisecond)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString("[AGENT] 2026/10/16 14:37:00 Agent stopped\n[AGENT] 2026/10/16 14:37:01 Warning: Failed to save prompt: locked\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	assert.Eventually(t, func() bool { return count() == 2 }, 5*time.Second, 50*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"Error saving conversation: disk full", "Warning: Failed to save prompt: locked"}, followed)
+
+	err = TailLog(context.Background(), filepath.Join(t.TempDir(), "missing.log"), 0, false, LogFilter{}, show)
+	assert.ErrorContains(t, err, "no log file at")
+	assert.EqualError(t, LogFilter{Level: "loud"}.Validate(), `unknown log level "loud"; use debug, info, warn, or error`)
+}
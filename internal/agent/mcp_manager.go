@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/danieleugenewilliams/othello-agent/internal/chaos"
 	"github.com/danieleugenewilliams/othello-agent/internal/config"
 	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
 )
@@ -45,10 +46,13 @@ type ServerInfo struct {
 type MCPManager struct {
 	registry     *mcp.ToolRegistry
 	clients      map[string]mcp.Client
+	starting     map[string]bool // Servers AddServer is connecting to
 	factory      *mcp.DefaultClientFactory
 	logger       Logger
 	mutex        sync.RWMutex
 	updateCallback func(interface{}) // Callback for status updates
+	injector       *chaos.Injector   // Fault injection for resilience testing (nil when disabled)
+	offline        bool              // Only stdio servers may be added
 }
 
 // NewMCPManager creates a new MCP manager
@@ -56,6 +60,7 @@ func NewMCPManager(registry *mcp.ToolRegistry, logger Logger) *MCPManager {
 	return &MCPManager{
 		registry: registry,
 		clients:  make(map[string]mcp.Client),
+		starting: make(map[string]bool),
 		factory:  mcp.NewClientFactory(logger),
 		logger:   logger,
 	}
@@ -68,6 +73,21 @@ func (m *MCPManager) SetUpdateCallback(callback func(interface{})) {
 	m.updateCallback = callback
 }
 
+// SetFaultInjector wraps clients for servers added afterwards with fault injection
+func (m *MCPManager) SetFaultInjector(injector *chaos.Injector) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.injector = injector
+}
+
+// SetOffline makes AddServer refuse servers that don't talk over stdio, so
+// that none reach the network
+func (m *MCPManager) SetOffline(offline bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.offline = offline
+}
+
 // notifyUpdate sends an update if callback is set (call with mutex held)
 func (m *MCPManager) notifyUpdate(update interface{}) {
 	if m.updateCallback != nil {
@@ -75,19 +95,31 @@ func (m *MCPManager) notifyUpdate(update interface{}) {
 	}
 }
 
-// AddServer adds and connects to an MCP server
+// AddServer adds and connects to an MCP server. Servers can be added
+// concurrently; each connects without holding up the others.
 func (m *MCPManager) AddServer(ctx context.Context, cfg config.ServerConfig) error {
 	if cfg.Name == "" {
 		return fmt.Errorf("server name cannot be empty")
 	}
 
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
+	if m.offline && !offlineAllows(cfg) {
+		m.mutex.Unlock()
+		return fmt.Errorf("%w: only stdio servers run offline", ErrOffline)
+	}
 	// Check for duplicate
-	if _, exists := m.clients[cfg.Name]; exists {
+	if _, exists := m.clients[cfg.Name]; exists || m.starting[cfg.Name] {
+		m.mutex.Unlock()
 		return fmt.Errorf("server already exists: %s", cfg.Name)
 	}
+	m.starting[cfg.Name] = true
+	injector := m.injector
+	m.mutex.Unlock()
+	defer func() {
+		m.mutex.Lock()
+		delete(m.starting, cfg.Name)
+		m.mutex.Unlock()
+	}()
 
 	// Create client using factory
 	client, err := m.factory.CreateClient(cfg)
@@ -95,6 +127,7 @@ func (m *MCPManager) AddServer(ctx context.Context, cfg config.ServerConfig) err
 		m.logger.Error("Failed to create client", "server", cfg.Name, "error", err)
 		return fmt.Errorf("create client: %w", err)
 	}
+	client = mcp.NewChaosClient(cfg.Name, client, injector, m.logger)
 
 	// Connect to server
 	if err := client.Connect(ctx); err != nil {
@@ -105,10 +138,14 @@ func (m *MCPManager) AddServer(ctx context.Context, cfg config.ServerConfig) err
 	// Register with registry
 	if err := m.registry.RegisterServer(cfg.Name, client); err != nil {
 		client.Disconnect(ctx)
+		m.registry.UnregisterServer(cfg.Name)
 		m.logger.Error("Failed to register server", "server", cfg.Name, "error", err)
 		return fmt.Errorf("register server: %w", err)
 	}
 
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
 	m.clients[cfg.Name] = client
 	m.logger.Info("Added MCP server %s transport %s", cfg.Name, cfg.Transport)
 
@@ -205,13 +242,24 @@ func (m *MCPManager) Close(ctx context.Context) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	// Servers are disconnected at once, so each stdio server's grace period
+	// to exit runs alongside the others
 	var errors []error
+	var errorsMu sync.Mutex
+	var wg sync.WaitGroup
 	for name, client := range m.clients {
-		if err := client.Disconnect(ctx); err != nil {
-			m.logger.Error("Error disconnecting from server", "server", name, "error", err)
-			errors = append(errors, err)
-		}
+		wg.Add(1)
+		go func(name string, client mcp.Client) {
+			defer wg.Done()
+			if err := client.Disconnect(ctx); err != nil {
+				m.logger.Error("Error disconnecting from server", "server", name, "error", err)
+				errorsMu.Lock()
+				errors = append(errors, err)
+				errorsMu.Unlock()
+			}
+		}(name, client)
 	}
+	wg.Wait()
 
 	m.clients = make(map[string]mcp.Client)
 
@@ -0,0 +1,281 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/danieleugenewilliams/othello-agent/internal/tui"
+)
+
+// memoryServerName is the name the built-in memory tools are registered under
+const memoryServerName = "othello-memory"
+
+// localMemory keeps facts in the local database so they can be recalled in
+// later conversations without an MCP memory server. Recall works by meaning
+// with the configured embedding model and falls back to shared words when the
+// model can't be reached.
+type localMemory struct {
+	mu       sync.Mutex // MemoryManager is not safe for concurrent use
+	store    *storage.ConversationStore
+	manager  *storage.MemoryManager
+	semantic bool
+	logger   *log.Logger
+}
+
+// newLocalMemory creates the built-in memory over store, embedding with
+// embedder when it is not nil
+func newLocalMemory(store *storage.ConversationStore, embedder storage.Embedder, embeddingModel string, logger *log.Logger) *localMemory {
+	memory := &localMemory{store: store, manager: store.MemoryManager(), logger: logger}
+	if embedder != nil {
+		memory.manager.SetEmbedder(embedder, embeddingModel)
+		memory.semantic = true
+	}
+	return memory
+}
+
+// Remember implements tui.MemoryStore
+func (m *localMemory) Remember(ctx context.Context, topic, content string, importance int) (*storage.Memory, error) {
+	memory := &storage.Memory{Topic: topic, Content: content, Importance: importance}
+	if err := m.store.SaveMemory(memory); err != nil {
+		return nil, err
+	}
+	return memory, nil
+}
+
+// Recall implements tui.MemoryStore
+func (m *localMemory) Recall(ctx context.Context, query string, limit int) ([]*storage.RecalledMemory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.semantic {
+		recalled, err := m.manager.Recall(ctx, query, limit)
+		if err == nil {
+			return recalled, nil
+		}
+		m.logger.Printf("Semantic memory recall unavailable, matching words instead: %v", err)
+	}
+	return m.manager.RecallText(query, limit)
+}
+
+// List implements tui.MemoryStore
+func (m *localMemory) List(topic string, limit int) ([]*storage.Memory, error) {
+	return m.store.ListMemories(storage.MemoryFilter{Topic: topic, Limit: limit})
+}
+
+// Forget implements tui.MemoryStore
+func (m *localMemory) Forget(id int64) error {
+	return m.store.DeleteMemory(id)
+}
+
+// memoryClient offers the built-in memory to the model as tools. It is an
+// in-process mcp.Client, so its tools are listed, validated, executed, and
+// audited like those of any MCP server.
+type memoryClient struct {
+	memory *localMemory
+}
+
+// memoryTools describes the tools memoryClient offers
+var memoryTools = []mcp.Tool{
+	{
+		Name:        "remember",
+		Description: "Save a fact about the user or their work to recall in later conversations",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"content":    map[string]interface{}{"type": "string", "description": "The fact to remember, as a complete sentence"},
+				"topic":      map[string]interface{}{"type": "string", "description": "A short topic to file the fact under, such as preferences or project name"},
+				"importance": map[string]interface{}{"type": "integer", "description": "1 (trivia) to 10 (essential); defaults to 5", "minimum": 1, "maximum": 10},
+			},
+			"required": []interface{}{"content"},
+		},
+	},
+	{
+		Name:        "recall",
+		Description: "Find remembered facts related to a question or topic",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{"type": "string", "description": "What to look for"},
+				"limit": map[string]interface{}{"type": "integer", "description": "Most facts to return; defaults to 5", "minimum": 1},
+			},
+			"required": []interface{}{"query"},
+		},
+	},
+	{
+		Name:        "forget",
+		Description: "Delete a remembered fact by its ID",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{"type": "integer", "description": "ID of the fact, as shown by recall"},
+			},
+			"required": []interface{}{"id"},
+		},
+	},
+}
+
+func (c *memoryClient) Connect(ctx context.Context) error    { return nil }
+func (c *memoryClient) Disconnect(ctx context.Context) error { return nil }
+func (c *memoryClient) IsConnected() bool                    { return true }
+func (c *memoryClient) GetTransport() string                 { return "builtin" }
+
+// ListTools implements mcp.Client
+func (c *memoryClient) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	tools := make([]mcp.Tool, len(memoryTools))
+	copy(tools, memoryTools)
+	return tools, nil
+}
+
+// GetInfo implements mcp.Client
+func (c *memoryClient) GetInfo(ctx context.Context) (*mcp.ServerInfo, error) {
+	return &mcp.ServerInfo{Name: memoryServerName, Version: "1.0.0"}, nil
+}
+
+// CallTool implements mcp.Client. Failures are reported as error results so
+// the model can see what went wrong.
+func (c *memoryClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (*mcp.ToolResult, error) {
+	text, err := c.call(ctx, name, params)
+	if err != nil {
+		return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: text}}}, nil
+}
+
+// call runs a memory tool and describes the outcome
+func (c *memoryClient) call(ctx context.Context, name string, params map[string]interface{}) (string, error) {
+	switch name {
+	case "remember":
+		content, _ := params["content"].(string)
+		topic, _ := params["topic"].(string)
+		importance, err := intArgument(params, "importance", 0)
+		if err != nil {
+			return "", err
+		}
+		memory, err := c.memory.Remember(ctx, topic, content, importance)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Remembered #%d under %s (importance %d): %s", memory.ID, memory.Topic, memory.Importance, memory.Content), nil
+
+	case "recall":
+		query, _ := params["query"].(string)
+		limit, err := intArgument(params, "limit", 5)
+		if err != nil {
+			return "", err
+		}
+		recalled, err := c.memory.Recall(ctx, query, limit)
+		if err != nil {
+			return "", err
+		}
+		if len(recalled) == 0 {
+			return "Nothing remembered about that.", nil
+		}
+		return describeMemories(recalled), nil
+
+	case "forget":
+		id, err := intArgument(params, "id", 0)
+		if err != nil {
+			return "", err
+		}
+		if err := c.memory.Forget(int64(id)); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Forgot memory #%d", id), nil
+	}
+	return "", fmt.Errorf("unknown memory tool %q", name)
+}
+
+// inverse implements reversible: a fact just remembered is forgotten
+func (c *memoryClient) inverse(name string, params map[string]interface{}, result string) (model.ToolCall, bool) {
+	var id int64
+	if name != "remember" {
+		return model.ToolCall{}, false
+	}
+	if _, err := fmt.Sscanf(result, "Remembered #%d", &id); err != nil {
+		return model.ToolCall{}, false
+	}
+	return model.ToolCall{Name: "forget", Arguments: map[string]interface{}{"id": id}}, true
+}
+
+// intArgument reads an integer argument, which models send as JSON numbers
+// or occasionally as strings
+func intArgument(params map[string]interface{}, name string, fallback int) (int, error) {
+	switch value := params[name].(type) {
+	case nil:
+		return fallback, nil
+	case float64:
+		return int(value), nil
+	case int:
+		return value, nil
+	case int64:
+		return int(value), nil
+	case string:
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a whole number", name)
+		}
+		return number, nil
+	}
+	return 0, fmt.Errorf("%s must be a whole number", name)
+}
+
+// describeMemories lists recalled memories one per line
+func describeMemories(recalled []*storage.RecalledMemory) string {
+	lines := make([]string, len(recalled))
+	for i, r := range recalled {
+		lines[i] = fmt.Sprintf("#%d [%s] %s (importance %d)", r.Memory.ID, r.Memory.Topic, r.Memory.Content, r.Memory.Importance)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// openMemory starts the built-in memory over the open store and offers its
+// tools to the model
+func (a *Agent) openMemory() {
+	if !a.config.Memory.Enabled {
+		return
+	}
+	var embedder storage.Embedder
+	if name := a.config.Model.EmbeddingModel; name != "" {
+		ollama := model.NewOllamaModel(a.config.Ollama.Host, name)
+		ollama.SetTransport(a.modelTransport())
+		embedder = ollama
+	}
+	a.memory = newLocalMemory(a.store, embedder, a.config.Model.EmbeddingModel, a.logger)
+	if err := a.mcpRegistry.RegisterServer(memoryServerName, &memoryClient{memory: a.memory}); err != nil {
+		a.logger.Printf("Warning: Failed to register memory tools: %v", err)
+	}
+}
+
+// closeMemory withdraws the memory tools before the store closes
+func (a *Agent) closeMemory() {
+	if a.memory == nil {
+		return
+	}
+	a.mcpRegistry.UnregisterServer(memoryServerName)
+	a.memory = nil
+}
+
+// MemoryStore returns the built-in memory, or nil when it is disabled or
+// storage is unavailable
+func (a *Agent) MemoryStore() tui.MemoryStore {
+	if a.memory == nil {
+		return nil
+	}
+	return a.memory
+}
+
+// MemoryRecallLimit returns how many related memories are sent with each
+// message, or 0 when automatic recall is off
+func (a *Agent) MemoryRecallLimit() int {
+	if !a.config.Memory.AutoRecall {
+		return 0
+	}
+	return a.config.Memory.RecallLimit
+}
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMemoryClient(t *testing.T) *memoryClient {
+	t.Helper()
+	store, err := storage.NewConversationStore(filepath.Join(t.TempDir(), storage.DatabaseFile))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return &memoryClient{memory: newLocalMemory(store, nil, "", log.New(io.Discard, "", 0))}
+}
+
+func resultText(t *testing.T, result *mcp.ToolResult) string {
+	t.Helper()
+	require.Len(t, result.Content, 1)
+	return result.Content[0].Text
+}
+
+func TestMemoryClient_RememberRecallForget(t *testing.T) {
+	client := newTestMemoryClient(t)
+	ctx := context.Background()
+
+	result, err := client.CallTool(ctx, "remember", map[string]interface{}{
+		"content": "Deploys happen on Tuesdays", "topic": "Release", "importance": float64(8),
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "Remembered #1 under release (importance 8): Deploys happen on Tuesdays", resultText(t, result))
+
+	result, err = client.CallTool(ctx, "recall", map[string]interface{}{"query": "when are deploys"})
+	require.NoError(t, err)
+	assert.Equal(t, "#1 [release] Deploys happen on Tuesdays (importance 8)", resultText(t, result))
+
+	result, err = client.CallTool(ctx, "forget", map[string]interface{}{"id": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Forgot memory #1", resultText(t, result))
+
+	result, err = client.CallTool(ctx, "recall", map[string]interface{}{"query": "deploys"})
+	require.NoError(t, err)
+	assert.Equal(t, "Nothing remembered about that.", resultText(t, result))
+}
+
+func TestMemoryClient_ReportsErrorsAsResults(t *testing.T) {
+	client := newTestMemoryClient(t)
+
+	result, err := client.CallTool(context.Background(), "forget", map[string]interface{}{"id": float64(42)})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "not found")
+
+	result, err = client.CallTool(context.Background(), "remember", map[string]interface{}{"content": "x", "importance": "high"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "importance must be a whole number", resultText(t, result))
+}
+
+func TestMemoryClient_RegistersTools(t *testing.T) {
+	registry := mcp.NewToolRegistry(&agentLogger{logger: log.New(io.Discard, "", 0)})
+	require.NoError(t, registry.RegisterServer(memoryServerName, newTestMemoryClient(t)))
+
+	for _, name := range []string{"remember", "recall", "forget"} {
+		tool, ok := registry.GetTool(name)
+		require.True(t, ok, name)
+		assert.Equal(t, memoryServerName, tool.ServerName)
+	}
+}
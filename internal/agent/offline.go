@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+)
+
+// ErrOffline is the error of network access agent.offline doesn't allow
+var ErrOffline = errors.New("blocked by offline mode")
+
+// offlineTransport is an http.RoundTripper that only sends requests to the
+// Ollama host, refusing the rest before they leave the computer
+type offlineTransport struct {
+	base    http.RoundTripper
+	allowed string // Host and port of the Ollama host
+}
+
+// newOfflineTransport returns a transport sending requests to ollamaHost
+// through base, which defaults to http.DefaultTransport, and refusing others
+func newOfflineTransport(ollamaHost string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	allowed := ""
+	if u, err := url.Parse(ollamaHost); err == nil && u.Host != "" {
+		allowed = hostPort(u)
+	}
+	return &offlineTransport{base: base, allowed: allowed}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.allowed == "" || hostPort(req.URL) != t.allowed {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: %s isn't the Ollama host", ErrOffline, req.URL.Host)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// hostPort returns the host of u with its port, which defaults to the one of
+// its scheme, so that the same host is always written the same way
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(strings.ToLower(u.Hostname()), port)
+}
+
+// offlineAllows reports whether server may run under agent.offline, which
+// only runs servers that talk over stdio
+func offlineAllows(server config.ServerConfig) bool {
+	return server.Transport == "stdio"
+}
+
+// modelTransport returns the transport of requests to Ollama: limited to
+// the Ollama host in offline mode, with faults injected in chaos mode. It is
+// nil when neither is on.
+func (a *Agent) modelTransport() http.RoundTripper {
+	var base http.RoundTripper
+	if a.config.Agent.Offline {
+		base = newOfflineTransport(a.config.Ollama.Host, nil)
+	}
+	return a.chaos.Transport(base)
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineTransport_OnlyReachesOllama(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ollama.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a request reached a host other than Ollama")
+	}))
+	defer other.Close()
+
+	client := &http.Client{Transport: newOfflineTransport(ollama.URL, nil)}
+	resp, err := client.Get(ollama.URL + "/api/tags")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, err = client.Get(other.URL + "/api/tags")
+	assert.ErrorIs(t, err, ErrOffline)
+	_, err = client.Get("https://example.com/")
+	assert.ErrorIs(t, err, ErrOffline)
+}
+
+func TestHostPort(t *testing.T) {
+	for raw, want := range map[string]string{
+		"http://localhost:11434":  "localhost:11434",
+		"http://LocalHost:11434/": "localhost:11434",
+		"http://example.com/api":  "example.com:80",
+		"https://example.com":     "example.com:443",
+		"http://[::1]:11434":      "[::1]:11434",
+	} {
+		u, err := http.NewRequest(http.MethodGet, raw, nil)
+		require.NoError(t, err)
+		assert.Equal(t, want, hostPort(u.URL), raw)
+	}
+}
+
+func TestMCPManager_OfflineRefusesHTTPServers(t *testing.T) {
+	manager := NewMCPManager(mcp.NewToolRegistry(newTestLogger()), newTestLogger())
+	manager.SetOffline(true)
+
+	err := manager.AddServer(context.Background(), config.ServerConfig{Name: "remote", Transport: "http", URL: "https://example.com/mcp"})
+	assert.ErrorIs(t, err, ErrOffline)
+	assert.Empty(t, manager.ListServers())
+}
+
+func TestAgent_OfflineLeavesOutFetch(t *testing.T) {
+	dir := t.TempDir()
+	newAgent := func(offline bool) *Agent {
+		agent, err := New(&config.Config{
+			Model:   config.ModelConfig{Name: "test"},
+			Ollama:  config.OllamaConfig{Host: "http://localhost:11434"},
+			Agent:   config.AgentConfig{Offline: offline},
+			Tools:   config.ToolsConfig{Fetch: config.FetchToolConfig{Enabled: true}},
+			Storage: config.StorageConfig{DataDir: dir},
+			Logging: config.LoggingConfig{File: filepath.Join(dir, "test.log")},
+		})
+		require.NoError(t, err)
+		return agent
+	}
+
+	_, found := newAgent(false).mcpRegistry.GetTool("fetch_url")
+	assert.True(t, found)
+	agent := newAgent(true)
+	_, found = agent.mcpRegistry.GetTool("fetch_url")
+	assert.False(t, found, "offline mode leaves out fetch_url")
+	assert.NotNil(t, agent.modelTransport(), "offline mode limits model requests")
+}
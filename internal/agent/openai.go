@@ -0,0 +1,412 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// completionToolRounds caps how many times a chat completion runs MCP tools
+// and asks the model again before giving up
+const completionToolRounds = 5
+
+// errCompletionToolRounds is returned when the model is still calling MCP
+// tools after completionToolRounds rounds
+var errCompletionToolRounds = fmt.Errorf("the model was still calling tools after %d rounds without answering", completionToolRounds)
+
+// openAIMessage is a chat message in the OpenAI format. Content is a string,
+// or a list of parts for messages with images.
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    json.RawMessage  `json:"content,omitempty"`
+	Name       string           `json:"name,omitempty"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// openAIContentPart is one part of a message's content
+type openAIContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL *struct {
+		URL string `json:"url"`
+	} `json:"image_url,omitempty"`
+}
+
+// openAIToolCall is a call the model made to a function
+type openAIToolCall struct {
+	Index    *int   `json:"index,omitempty"` // set in streamed chunks only
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON-encoded
+	} `json:"function"`
+}
+
+// openAITool is a function the client offers the model
+type openAITool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description,omitempty"`
+		Parameters  map[string]interface{} `json:"parameters,omitempty"`
+	} `json:"function"`
+}
+
+// completionRequest is the body of POST /v1/chat/completions. Fields other
+// clients send, such as n or stop, are accepted and ignored.
+type completionRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Tools       []openAITool    `json:"tools,omitempty"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	TopP        *float64        `json:"top_p,omitempty"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+}
+
+// completionChoice is the single choice of a completion or chunk
+type completionChoice struct {
+	Index        int            `json:"index"`
+	Message      *openAIMessage `json:"message,omitempty"`
+	Delta        *openAIMessage `json:"delta,omitempty"`
+	FinishReason *string        `json:"finish_reason"`
+}
+
+// completionUsage counts the tokens of every model call behind a completion
+type completionUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// completionResponse is a chat completion, or one chunk of a streamed one
+type completionResponse struct {
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []completionChoice `json:"choices"`
+	Usage   *completionUsage   `json:"usage,omitempty"`
+}
+
+// completion is the outcome of running a chat completion
+type completion struct {
+	content   string           // The answer, or what the model said with toolCalls
+	toolCalls []model.ToolCall // calls to the client's tools, for the client to run
+	usage     completionUsage
+}
+
+// chatCompletions handles POST /v1/chat/completions. The model can use the
+// MCP tools, which run here until it answers; calls to tools the request
+// defines are returned to the client as tool_calls. Nothing is saved to the
+// conversation history, since clients send the whole conversation each time.
+func (s *apiServer) chatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req completionRequest
+	body := http.MaxBytesReader(w, r.Body, apiMaxRequestSize)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	messages, err := fromOpenAIMessages(req.Messages)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	a := s.agent
+	options := a.generateOptions()
+	if req.Temperature != nil {
+		options.Temperature = *req.Temperature
+	}
+	if req.TopP != nil {
+		options.TopP = *req.TopP
+	}
+	if req.MaxTokens > 0 {
+		options.MaxTokens = req.MaxTokens
+	}
+
+	result, err := a.complete(r.Context(), messages, req.Tools, options)
+	if errors.Is(err, errCompletionToolRounds) {
+		writeOpenAIError(w, http.StatusBadGateway, err)
+		return
+	}
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadGateway, fmt.Errorf("model request failed: %w", err))
+		return
+	}
+
+	created := time.Now()
+	id := fmt.Sprintf("chatcmpl-%d", created.UnixNano())
+	message := &openAIMessage{Role: "assistant"}
+	finish := "stop"
+	if len(result.toolCalls) > 0 {
+		message.ToolCalls = toOpenAIToolCalls(id, result.toolCalls)
+		finish = "tool_calls"
+	}
+	if len(result.toolCalls) == 0 || result.content != "" {
+		message.Content, _ = json.Marshal(result.content)
+	}
+	response := completionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: created.Unix(),
+		Model:   a.config.Model.Name,
+		Choices: []completionChoice{{Message: message, FinishReason: &finish}},
+		Usage:   &result.usage,
+	}
+	if req.Stream {
+		streamCompletion(w, response)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, response)
+}
+
+// complete asks the model, running the MCP tools it calls and asking again
+// with their results, until it answers or calls only clientTools. MCP tools
+// called alongside the client's run first, and the model is asked again with
+// their results, so it has read them before its calls to the client's tools
+// are returned. The tools run through the agent's tool pipeline, with the
+// request's calls counted against agent.tool_policies.
+func (a *Agent) complete(ctx context.Context, messages []model.Message, clientTools []openAITool, options model.GenerateOptions) (*completion, error) {
+	definitions, err := a.GetMCPToolsAsDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	offered := make(map[string]bool, len(clientTools))
+	for _, tool := range clientTools {
+		offered[tool.Function.Name] = true
+	}
+	// A client tool hides an MCP tool of the same name
+	tools := make([]model.ToolDefinition, 0, len(definitions)+len(clientTools))
+	for _, tool := range definitions {
+		if !offered[tool.Name] {
+			tools = append(tools, tool)
+		}
+	}
+	for _, tool := range clientTools {
+		tools = append(tools, model.ToolDefinition{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+		})
+	}
+
+	convContext := &model.ConversationContext{
+		History:     messages,
+		UserQuery:   lastUserMessage(messages),
+		SessionType: "api",
+		Entities:    model.NewEntityStore(),
+	}
+	result := &completion{}
+	for round := 0; ; round++ {
+		response, err := a.model.ChatWithTools(ctx, messages, tools, options)
+		if err != nil {
+			return nil, err
+		}
+		result.usage.PromptTokens += response.Usage.PromptTokens
+		result.usage.CompletionTokens += response.Usage.CompletionTokens
+		result.usage.TotalTokens += response.Usage.TotalTokens
+
+		var clientCalls, mcpCalls []model.ToolCall
+		for _, call := range response.ToolCalls {
+			if offered[call.Name] {
+				clientCalls = append(clientCalls, call)
+			} else {
+				mcpCalls = append(mcpCalls, call)
+			}
+		}
+		// Out of rounds, the client's calls are still returned, without
+		// the MCP calls made alongside them
+		if len(mcpCalls) == 0 || len(clientCalls) > 0 && round == completionToolRounds {
+			result.content = response.Content
+			result.toolCalls = clientCalls
+			return result, nil
+		}
+		if round == completionToolRounds {
+			return nil, errCompletionToolRounds
+		}
+
+		messages = append(messages, model.Message{Role: "assistant", Content: response.Content})
+		for _, call := range mcpCalls {
+			output := a.runMCPTool(ctx, call, convContext)
+			messages = append(messages, model.Message{Role: "tool", Content: fmt.Sprintf("Result of %s:\n%s", call.Name, output)})
+		}
+	}
+}
+
+// lastUserMessage returns the text of the last message the user sent
+func lastUserMessage(messages []model.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// runMCPTool runs an MCP tool for the model through the agent's tool
+// pipeline and returns what it should read: the tool's output, guarded
+// against injected instructions, or why the call failed. convContext holds
+// the request's earlier calls, which call limits and repeated calls are
+// checked against.
+func (a *Agent) runMCPTool(ctx context.Context, call model.ToolCall, convContext *model.ConversationContext) string {
+	run, err := a.runToolWithRetries(ctx, call.Name, call.Arguments, convContext)
+	if err != nil {
+		return "Error: " + a.guardResult(call.Name, err.Error())
+	}
+	if run.done {
+		return run.output
+	}
+	var output string
+	if run.result != nil {
+		output = a.guardResult(run.name, toolResultText(run.result))
+	}
+	return a.finishToolCall(ctx, run, output, convContext)
+}
+
+// fromOpenAIMessages converts a request's messages for the model. The
+// model reads tool calls as text, so earlier calls are written the way it
+// makes them.
+func fromOpenAIMessages(messages []openAIMessage) ([]model.Message, error) {
+	if len(messages) == 0 {
+		return nil, errors.New("messages are required")
+	}
+	converted := make([]model.Message, 0, len(messages))
+	for i, msg := range messages {
+		text, images, err := openAIContent(msg.Content)
+		if err != nil {
+			return nil, fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		role := msg.Role
+		switch role {
+		case "system", "user", "assistant", "tool":
+		case "developer":
+			role = "system"
+		default:
+			return nil, fmt.Errorf("messages[%d]: unknown role %q", i, msg.Role)
+		}
+		for _, call := range msg.ToolCalls {
+			text = strings.TrimSpace(fmt.Sprintf("%s\nTOOL_CALL: %s\nARGUMENTS: %s", text, call.Function.Name, call.Function.Arguments))
+		}
+		converted = append(converted, model.Message{Role: role, Content: text, Images: images})
+	}
+	return converted, nil
+}
+
+// openAIContent reads message content given as a string or a list of parts,
+// returning its text and the base64 data of any inline images
+func openAIContent(raw json.RawMessage) (string, []string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil, nil
+	}
+	var parts []openAIContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", nil, errors.New("content must be a string or a list of parts")
+	}
+	var texts, images []string
+	for _, part := range parts {
+		switch part.Type {
+		case "text":
+			texts = append(texts, part.Text)
+		case "image_url":
+			if part.ImageURL == nil {
+				continue
+			}
+			_, data, ok := strings.Cut(part.ImageURL.URL, ";base64,")
+			if !ok || !strings.HasPrefix(part.ImageURL.URL, "data:") {
+				return "", nil, errors.New("only inline data: image URLs are supported")
+			}
+			images = append(images, data)
+		}
+	}
+	return strings.Join(texts, "\n"), images, nil
+}
+
+// toOpenAIToolCalls writes the model's tool calls in the OpenAI format
+func toOpenAIToolCalls(completionID string, calls []model.ToolCall) []openAIToolCall {
+	converted := make([]openAIToolCall, len(calls))
+	for i, call := range calls {
+		arguments, _ := json.Marshal(call.Arguments)
+		converted[i].ID = fmt.Sprintf("call_%s_%d", strings.TrimPrefix(completionID, "chatcmpl-"), i)
+		converted[i].Type = "function"
+		converted[i].Function.Name = call.Name
+		converted[i].Function.Arguments = string(arguments)
+	}
+	return converted
+}
+
+// streamCompletion sends a finished completion as server-sent events: one
+// chunk with the whole message, one with the finish reason and usage, and
+// the [DONE] marker
+func streamCompletion(w http.ResponseWriter, response completionResponse) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	message := *response.Choices[0].Message
+	for i := range message.ToolCalls {
+		index := i
+		message.ToolCalls[i].Index = &index
+	}
+	finish := response.Choices[0].FinishReason
+	usage := response.Usage
+
+	response.Object = "chat.completion.chunk"
+	response.Usage = nil
+	response.Choices = []completionChoice{{Delta: &message}}
+	writeEvent(w, response)
+
+	response.Usage = usage
+	response.Choices = []completionChoice{{Delta: &openAIMessage{}, FinishReason: finish}}
+	writeEvent(w, response)
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes v as a server-sent event
+func writeEvent(w http.ResponseWriter, v interface{}) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(w, "data: %s\n\n", data)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// listModels handles GET /v1/models, listing the configured model
+func (s *apiServer) listModels(w http.ResponseWriter, r *http.Request) {
+	writeAPIResponse(w, http.StatusOK, map[string]interface{}{
+		"object": "list",
+		"data": []map[string]interface{}{{
+			"id":       s.agent.config.Model.Name,
+			"object":   "model",
+			"created":  0,
+			"owned_by": "othello",
+		}},
+	})
+}
+
+// writeOpenAIError writes err in the OpenAI error format
+func writeOpenAIError(w http.ResponseWriter, status int, err error) {
+	kind := "invalid_request_error"
+	if status >= http.StatusInternalServerError {
+		kind = "server_error"
+	}
+	writeAPIResponse(w, status, map[string]interface{}{
+		"error": map[string]interface{}{"message": err.Error(), "type": kind},
+	})
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/danieleugenewilliams/othello-agent/internal/tui"
+)
+
+// destructiveWords are words in a tool's name that mark it as deleting or
+// changing data
+var destructiveWords = map[string]bool{
+	"delete": true, "remove": true, "forget": true, "clear": true, "drop": true,
+	"purge": true, "erase": true, "destroy": true, "truncate": true, "reset": true,
+	"kill": true, "write": true, "overwrite": true, "update": true, "edit": true,
+	"modify": true, "move": true, "rename": true, "replace": true,
+}
+
+// isDestructiveTool reports whether a tool's name says it deletes or changes
+// data, as delete_memory, forget, and writeFile do
+func isDestructiveTool(name string) bool {
+	var words []string
+	var word []rune
+	for i, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) || unicode.IsUpper(r) && i > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, r)
+		}
+	}
+	words = append(words, strings.ToLower(string(word)))
+
+	for _, w := range words {
+		if destructiveWords[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// planToolCalls turns the tool calls the model made into an orchestration
+// plan with one step per call
+func (a *Agent) planToolCalls(calls []model.ToolCall) *OrchestrationPlan {
+	plan := &OrchestrationPlan{Description: fmt.Sprintf("Multi-tool operation with %d steps", len(calls))}
+	if len(calls) == 1 {
+		plan.Description = fmt.Sprintf("Single tool operation: %s", calls[0].Name)
+	}
+	for _, call := range calls {
+		step := OrchestrationStep{
+			ToolName:    call.Name,
+			Parameters:  call.Arguments,
+			Destructive: isDestructiveTool(call.Name),
+		}
+		if tool, ok := a.mcpRegistry.GetTool(call.Name); ok {
+			step.Reasoning = tool.Description
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	return plan
+}
+
+// needsApproval reports whether the user must approve plan before it runs:
+// a step runs a command while tools.shell.require_approval is set, uses the
+// clipboard while tools.clipboard.require_approval is set, calls a server
+// trusted only when asked, or agent.plan_approval asks for it. Steps
+// calling a trusted server, or a tool that reads from a server trusted with
+// those, don't need approval.
+func (a *Agent) needsApproval(plan *OrchestrationPlan) bool {
+	for _, step := range plan.Steps {
+		if step.ToolName == shellTool.Name && a.config.Tools.Shell.RequireApproval {
+			return true
+		}
+		if isClipboardTool(step.ToolName) && a.config.Tools.Clipboard.RequireApproval {
+			return true
+		}
+		switch _, level := a.toolTrust(step.ToolName); {
+		case level == storage.TrustAsk:
+			return true
+		case level == storage.TrustFull, level == storage.TrustReadOnly && !step.Destructive:
+			continue
+		}
+		switch a.config.Agent.PlanApproval {
+		case "always":
+			return true
+		case "destructive":
+			if step.Destructive {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ToolPlan returns the plan for calls when agent.plan_approval or a tool's
+// require_approval wants the user to approve it before it runs,
+// or nil when the calls can run straight away. Nothing runs in dry-run mode,
+// so nothing needs approval. It implements tui.ToolPlanner.
+func (a *Agent) ToolPlan(calls []model.ToolCall) *tui.ToolPlan {
+	if a.dryRun.Load() {
+		return nil
+	}
+	plan := a.planToolCalls(calls)
+	if !a.needsApproval(plan) {
+		return nil
+	}
+
+	approval := &tui.ToolPlan{Description: plan.Description}
+	for _, step := range plan.Steps {
+		approval.Steps = append(approval.Steps, tui.ToolPlanStep{
+			Tool:        step.ToolName,
+			Arguments:   step.Parameters,
+			Reasoning:   step.Reasoning,
+			Destructive: step.Destructive,
+		})
+	}
+	return approval
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsDestructiveTool(t *testing.T) {
+	for _, name := range []string{"forget", "delete_memory", "filesystem_write_file", "writeFile", "update-note", "KillProcess"} {
+		assert.True(t, isDestructiveTool(name), name)
+	}
+	for _, name := range []string{"recall", "remember", "search", "read_file", "list_directory", "deleted_items_report", ""} {
+		assert.False(t, isDestructiveTool(name), name)
+	}
+}
+
+func TestAgent_ToolPlanFollowsPlanApproval(t *testing.T) {
+	agent, _ := newTestAskAgent(t, &MockModel{})
+	require.NoError(t, agent.openStore())
+	t.Cleanup(agent.closeStore)
+	recall := []model.ToolCall{{Name: "recall", Arguments: map[string]interface{}{"query": "deploys"}}}
+	cleanup := append(recall, model.ToolCall{Name: "forget", Arguments: map[string]interface{}{"id": "mem_1"}})
+
+	agent.config.Agent.PlanApproval = "never"
+	assert.Nil(t, agent.ToolPlan(cleanup))
+
+	agent.config.Agent.PlanApproval = "destructive"
+	assert.Nil(t, agent.ToolPlan(recall), "Plans that only read run straight away")
+	plan := agent.ToolPlan(cleanup)
+	require.NotNil(t, plan)
+	assert.Equal(t, "Multi-tool operation with 2 steps", plan.Description)
+	require.Len(t, plan.Steps, 2)
+	assert.False(t, plan.Steps[0].Destructive)
+	assert.True(t, plan.Steps[1].Destructive)
+	assert.Equal(t, "mem_1", plan.Steps[1].Arguments["id"])
+	assert.NotEmpty(t, plan.Steps[1].Reasoning, "Steps explain what the tool does")
+
+	agent.config.Agent.PlanApproval = "always"
+	plan = agent.ToolPlan(recall)
+	require.NotNil(t, plan)
+	assert.Equal(t, "Single tool operation: recall", plan.Description)
+}
+
+func TestAgent_ToolPlanAsksBeforeRunningCommands(t *testing.T) {
+	agent, _ := newTestAskAgent(t, &MockModel{})
+	agent.config.Agent.PlanApproval = "never"
+	agent.config.Tools.Shell.RequireApproval = true
+	command := []model.ToolCall{{Name: "run_command", Arguments: map[string]interface{}{"command": "git status"}}}
+
+	plan := agent.ToolPlan(command)
+	require.NotNil(t, plan, "Commands need approval whatever plan_approval says")
+	assert.Equal(t, "run_command", plan.Steps[0].Tool)
+
+	agent.config.Tools.Shell.RequireApproval = false
+	assert.Nil(t, agent.ToolPlan(command))
+}
+
+func TestAgent_ToolPlanAsksBeforeUsingTheClipboard(t *testing.T) {
+	agent, _ := newTestAskAgent(t, &MockModel{})
+	agent.config.Agent.PlanApproval = "never"
+	agent.config.Tools.Clipboard.RequireApproval = true
+	read := []model.ToolCall{{Name: "read_clipboard", Arguments: map[string]interface{}{}}}
+
+	plan := agent.ToolPlan(read)
+	require.NotNil(t, plan, "Reading the clipboard needs approval whatever plan_approval says")
+	assert.Equal(t, "read_clipboard", plan.Steps[0].Tool)
+
+	agent.config.Tools.Clipboard.RequireApproval = false
+	assert.Nil(t, agent.ToolPlan(read))
+}
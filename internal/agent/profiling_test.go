@@ -0,0 +1,28 @@
+package agent
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartProfiling(t *testing.T) {
+	addr, err := StartProfiling(":0")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(addr, "127.0.0.1:") || strings.HasPrefix(addr, "[::1]:"), "Without a host, only localhost can connect: %s", addr)
+
+	resp, err := http.Get("http://" + addr + "/debug/pprof/goroutine?debug=1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), "goroutine profile:")
+
+	_, err = StartProfiling("6060")
+	assert.ErrorContains(t, err, `invalid pprof address "6060"`)
+}
@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/indexer"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/danieleugenewilliams/othello-agent/internal/tui"
+)
+
+// projectIndex finds the parts of indexed project files related to a message.
+// The configured directories are brought up to date in the background when
+// the store opens.
+type projectIndex struct {
+	indexer *indexer.Indexer
+	logger  *log.Logger
+	cancel  context.CancelFunc
+	done    sync.WaitGroup
+}
+
+// Retrieve implements tui.ProjectIndex
+func (p *projectIndex) Retrieve(ctx context.Context, query string, limit int) ([]*storage.ProjectChunk, error) {
+	return p.indexer.Retrieve(ctx, query, limit)
+}
+
+// refresh indexes each of dirs in turn until ctx is cancelled
+func (p *projectIndex) refresh(ctx context.Context, dirs []string) {
+	for _, dir := range dirs {
+		result, err := p.indexer.Index(ctx, dir)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			p.logger.Printf("Warning: Failed to index %s: %v", dir, err)
+			continue
+		}
+		p.logger.Printf("Indexed %s: %d files updated (%d chunks), %d unchanged, %d removed",
+			result.Root, result.Updated, result.Chunks, result.Unchanged, result.Removed)
+	}
+}
+
+// ProjectIndexer returns an indexer over store that embeds with the
+// configured embedding model through Ollama, or nil when none is set
+func ProjectIndexer(store *storage.ConversationStore, cfg *config.Config) *indexer.Indexer {
+	return newProjectIndexer(store, cfg, nil)
+}
+
+// newProjectIndexer is ProjectIndexer with the HTTP transport used to reach
+// Ollama
+func newProjectIndexer(store *storage.ConversationStore, cfg *config.Config, transport http.RoundTripper) *indexer.Indexer {
+	name := cfg.Model.EmbeddingModel
+	if name == "" {
+		return nil
+	}
+	embedder := model.NewOllamaModel(cfg.Ollama.Host, name)
+	embedder.SetTransport(transport)
+	return indexer.New(store, embedder, name, cfg.Index.MaxFileSize)
+}
+
+// openProjectIndex starts indexing the configured directories over the open
+// store. Directories indexed earlier with "othello index" are searched too.
+func (a *Agent) openProjectIndex() {
+	ix := newProjectIndexer(a.store, a.config, a.modelTransport())
+	if ix == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.project = &projectIndex{indexer: ix, logger: a.logger, cancel: cancel}
+	if len(a.config.Index.Dirs) == 0 {
+		return
+	}
+	a.project.done.Add(1)
+	go func(p *projectIndex, dirs []string) {
+		defer p.done.Done()
+		defer a.crashes.recover("project indexing")
+		p.refresh(ctx, dirs)
+	}(a.project, a.config.Index.Dirs)
+}
+
+// closeProjectIndex stops indexing before the store closes
+func (a *Agent) closeProjectIndex() {
+	if a.project == nil {
+		return
+	}
+	a.project.cancel()
+	a.project.done.Wait()
+	a.project = nil
+}
+
+// ProjectIndex returns the project index, or nil when automatic retrieval is
+// off, no embedding model is set, or storage is unavailable
+func (a *Agent) ProjectIndex() tui.ProjectIndex {
+	if a.project == nil || !a.config.Index.AutoRetrieve {
+		return nil
+	}
+	return a.project
+}
+
+// ProjectRetrieveLimit returns how many related parts of project files are
+// sent with each message
+func (a *Agent) ProjectRetrieveLimit() int {
+	return a.config.Index.RetrieveLimit
+}
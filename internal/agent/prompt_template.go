@@ -0,0 +1,250 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template/parse"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/danieleugenewilliams/othello-agent/internal/tui"
+	"gopkg.in/yaml.v3"
+)
+
+// PromptTemplate is a saved prompt read from a Markdown file. Its body
+// refers to variables as {{.name}}, which are filled in before it is sent.
+type PromptTemplate struct {
+	Name        string // File name without .md
+	Description string
+	Variables   []TemplateVariable // In the order the body first uses them
+	Body        string
+}
+
+// TemplateVariable is a value a template asks for
+type TemplateVariable struct {
+	Name        string
+	Description string // What to enter, shown when asking for it
+	Default     string // Used when no value is given; "" makes the variable required
+}
+
+// templateFrontMatter is the optional YAML block between --- lines that
+// starts a template file
+type templateFrontMatter struct {
+	Description string `yaml:"description"`
+	Vars        map[string]struct {
+		Description string `yaml:"description"`
+		Default     string `yaml:"default"`
+	} `yaml:"vars"`
+}
+
+// LoadPromptTemplates reads the templates in dir, sorted by name. A missing
+// directory has none. Files that can't be read are left out and reported
+// together in the error, so the others can still be used.
+func LoadPromptTemplates(dir string) ([]*PromptTemplate, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	var templates []*PromptTemplate
+	var errs []error
+	for _, path := range paths {
+		tmpl, err := loadPromptTemplate(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, errors.Join(errs...)
+}
+
+// LoadPromptTemplate reads the template called name from dir, ignoring case
+func LoadPromptTemplate(dir, name string) (*PromptTemplate, error) {
+	templates, loadErr := LoadPromptTemplates(dir)
+	names := make([]string, 0, len(templates))
+	for _, tmpl := range templates {
+		if strings.EqualFold(tmpl.Name, name) {
+			return tmpl, nil
+		}
+		names = append(names, tmpl.Name)
+	}
+	if loadErr != nil {
+		return nil, fmt.Errorf("template %q not found: %w", name, loadErr)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("template %q not found; add templates to %s", name, dir)
+	}
+	return nil, fmt.Errorf("template %q not found; available: %s", name, strings.Join(names, ", "))
+}
+
+// loadPromptTemplate reads the template file at path
+func loadPromptTemplate(path string) (*PromptTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	tmpl, err := ParsePromptTemplate(strings.TrimSuffix(filepath.Base(path), ".md"), data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// ParsePromptTemplate reads a template called name from Markdown, with the
+// front matter it may start with, and checks its variables
+func ParsePromptTemplate(name string, data []byte) (*PromptTemplate, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	var front templateFrontMatter
+	if rest, ok := strings.CutPrefix(text, "---\n"); ok {
+		header, body, found := strings.Cut(rest, "\n---\n")
+		if !found {
+			return nil, errors.New("front matter has no closing ---")
+		}
+		decoder := yaml.NewDecoder(strings.NewReader(header))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&front); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid front matter: %w", err)
+		}
+		text = body
+	}
+	body := strings.TrimSpace(text)
+	if body == "" {
+		return nil, errors.New("template is empty")
+	}
+
+	parsed, err := parseWorkflowTemplate(body)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &PromptTemplate{Name: name, Description: front.Description, Body: body}
+	var used []string
+	templateFields(parsed.Tree.Root, &used)
+	for _, variable := range used {
+		declared := front.Vars[variable]
+		tmpl.Variables = append(tmpl.Variables, TemplateVariable{Name: variable, Description: declared.Description, Default: declared.Default})
+	}
+	for variable := range front.Vars {
+		if !slices.Contains(used, variable) {
+			return nil, fmt.Errorf("vars.%s isn't used in the template", variable)
+		}
+	}
+	return tmpl, nil
+}
+
+// templateFields adds the variables node refers to as {{.name}} to names,
+// each once, in the order they appear
+func templateFields(node parse.Node, names *[]string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, child := range n.Nodes {
+				templateFields(child, names)
+			}
+		}
+	case *parse.ActionNode:
+		templateFields(n.Pipe, names)
+	case *parse.PipeNode:
+		if n != nil {
+			for _, cmd := range n.Cmds {
+				templateFields(cmd, names)
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			templateFields(arg, names)
+		}
+	case *parse.ChainNode:
+		templateFields(n.Node, names)
+	case *parse.IfNode:
+		templateFields(&n.BranchNode, names)
+	case *parse.RangeNode:
+		templateFields(&n.BranchNode, names)
+	case *parse.WithNode:
+		templateFields(&n.BranchNode, names)
+	case *parse.BranchNode:
+		templateFields(n.Pipe, names)
+		templateFields(n.List, names)
+		templateFields(n.ElseList, names)
+	case *parse.TemplateNode:
+		templateFields(n.Pipe, names)
+	case *parse.FieldNode:
+		if name := n.Ident[0]; !slices.Contains(*names, name) {
+			*names = append(*names, name)
+		}
+	}
+}
+
+// Fill returns the template's body with values filled in. Variables without
+// a value use their default; those without either are reported.
+func (t *PromptTemplate) Fill(values map[string]string) (string, error) {
+	variables := make(map[string]interface{}, len(t.Variables))
+	var missing []string
+	for _, variable := range t.Variables {
+		value, ok := values[variable.Name]
+		if !ok || value == "" {
+			value = variable.Default
+		}
+		if value == "" {
+			missing = append(missing, variable.Name)
+		}
+		variables[variable.Name] = value
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("template %s needs a value for %s", t.Name, strings.Join(missing, ", "))
+	}
+	for name := range values {
+		if _, ok := variables[name]; !ok {
+			return "", fmt.Errorf("template %s has no variable %s", t.Name, name)
+		}
+	}
+	text, err := fillTemplate(t.Body, variables)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(text), nil
+}
+
+// PromptTemplates returns the saved prompt templates for /template. It
+// implements the chat view's template library.
+func (a *Agent) PromptTemplates() ([]tui.PromptTemplate, error) {
+	dir, err := storage.TemplatePath(a.config.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := LoadPromptTemplates(dir)
+	if err != nil {
+		a.logger.Printf("Warning: Failed to read some prompt templates: %v", err)
+	}
+	converted := make([]tui.PromptTemplate, 0, len(templates))
+	for _, tmpl := range templates {
+		item := tui.PromptTemplate{Name: tmpl.Name, Description: tmpl.Description}
+		for _, variable := range tmpl.Variables {
+			item.Variables = append(item.Variables, tui.TemplateVariable{
+				Name:        variable.Name,
+				Description: variable.Description,
+				Default:     variable.Default,
+			})
+		}
+		converted = append(converted, item)
+	}
+	return converted, err
+}
+
+// FillTemplate returns the prompt of the template called name with values
+// filled in
+func (a *Agent) FillTemplate(name string, values map[string]string) (string, error) {
+	dir, err := storage.TemplatePath(a.config.Storage.DataDir)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := LoadPromptTemplate(dir, name)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.Fill(values)
+}
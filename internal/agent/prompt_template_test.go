@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reviewTemplate = `---
+description: Review a change
+vars:
+  focus:
+    description: What to look for
+    default: bugs
+---
+Review {{.repo}} for {{.focus}}.
+{{with .files}}Files: {{.}}{{end}}
+`
+
+func TestParsePromptTemplate_ReadsVariablesInOrder(t *testing.T) {
+	tmpl, err := ParsePromptTemplate("review", []byte(reviewTemplate))
+	require.NoError(t, err)
+	assert.Equal(t, "Review a change", tmpl.Description)
+	assert.Equal(t, []TemplateVariable{
+		{Name: "repo"},
+		{Name: "focus", Description: "What to look for", Default: "bugs"},
+		{Name: "files"},
+	}, tmpl.Variables)
+
+	plain, err := ParsePromptTemplate("plain", []byte("Summarize my day.\n"))
+	require.NoError(t, err)
+	assert.Empty(t, plain.Variables)
+	assert.Equal(t, "Summarize my day.", plain.Body)
+}
+
+func TestParsePromptTemplate_RejectsMalformedTemplates(t *testing.T) {
+	for _, tc := range []struct{ text, message string }{
+		{"", "template is empty"},
+		{"---\ndescription: x\n---\n", "template is empty"},
+		{"---\ndescription: x\nHello", "front matter has no closing ---"},
+		{"---\ntitle: x\n---\nHello", "field title not found"},
+		{"---\nvars:\n  who: {default: you}\n---\nHello", "vars.who isn't used in the template"},
+		{"Hello {{.who", "invalid template"},
+	} {
+		_, err := ParsePromptTemplate("t", []byte(tc.text))
+		assert.ErrorContains(t, err, tc.message, tc.text)
+	}
+}
+
+func TestPromptTemplate_Fill(t *testing.T) {
+	tmpl, err := ParsePromptTemplate("review", []byte(reviewTemplate))
+	require.NoError(t, err)
+
+	text, err := tmpl.Fill(map[string]string{"repo": "othello", "files": "main.go"})
+	require.NoError(t, err)
+	assert.Equal(t, "Review othello for bugs.\nFiles: main.go", text)
+
+	text, err = tmpl.Fill(map[string]string{"repo": "othello", "focus": "style", "files": "a.go"})
+	require.NoError(t, err)
+	assert.Equal(t, "Review othello for style.\nFiles: a.go", text)
+
+	_, err = tmpl.Fill(nil)
+	assert.EqualError(t, err, "template review needs a value for repo, files")
+	_, err = tmpl.Fill(map[string]string{"repo": "othello", "files": "-", "color": "blue"})
+	assert.EqualError(t, err, "template review has no variable color")
+}
+
+func TestLoadPromptTemplates(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "review.md"), []byte(reviewTemplate), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Standup.md"), []byte("What did I do yesterday?"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.md"), []byte("{{end}}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("Not a template"), 0o644))
+
+	templates, err := LoadPromptTemplates(dir)
+	assert.ErrorContains(t, err, "broken.md")
+	require.Len(t, templates, 2)
+	assert.Equal(t, "Standup", templates[0].Name)
+	assert.Equal(t, "review", templates[1].Name)
+
+	tmpl, err := LoadPromptTemplate(dir, "standup")
+	require.NoError(t, err)
+	assert.Equal(t, "What did I do yesterday?", tmpl.Body)
+	_, err = LoadPromptTemplate(dir, "missing")
+	assert.ErrorContains(t, err, `template "missing" not found`)
+
+	templates, err = LoadPromptTemplates(filepath.Join(dir, "none"))
+	assert.NoError(t, err)
+	assert.Empty(t, templates)
+	_, err = LoadPromptTemplate(filepath.Join(dir, "none"), "review")
+	assert.ErrorContains(t, err, "add templates to")
+}
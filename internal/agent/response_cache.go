@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+)
+
+// responseCache keeps the final answers to recent questions, so a question
+// asked again is answered without running the model and its tools. Answers
+// expire after ttl, and all of them are dropped whenever a tool runs, since
+// the tool may have changed what the answer would be.
+type responseCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]cachedResponse
+	now        func() time.Time
+}
+
+// cachedResponse is an answer and when it stops being used
+type cachedResponse struct {
+	answer  string
+	expires time.Time
+}
+
+func newResponseCache(ttl time.Duration, maxEntries int) *responseCache {
+	return &responseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cachedResponse),
+		now:        time.Now,
+	}
+}
+
+// get returns the answer kept for key, unless it has expired
+func (c *responseCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.answer, true
+}
+
+// put keeps answer for key, making room by dropping the answer closest to
+// expiring when the cache is full
+func (c *responseCache) put(key, answer string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		var oldest string
+		for k, entry := range c.entries {
+			if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[key] = cachedResponse{answer: answer, expires: c.now().Add(c.ttl)}
+}
+
+// clear drops every answer
+func (c *responseCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// responseKey identifies a question asked with context: the question with
+// case, spacing, and closing punctuation evened out, and a hash of the
+// context, the model, and the tools it can call
+func (a *Agent) responseKey(question, context string) string {
+	var tools []string
+	for _, tool := range a.mcpRegistry.ListTools() {
+		tools = append(tools, tool.Name)
+	}
+	sort.Strings(tools)
+	hash := sha256.Sum256([]byte(a.config.Model.Name + "\x00" + strings.Join(tools, ",") + "\x00" + context))
+	return normalizeQuestion(question) + "\x00" + hex.EncodeToString(hash[:])
+}
+
+// normalizeQuestion makes questions that differ only in case, spacing, or
+// closing punctuation the same
+func normalizeQuestion(question string) string {
+	question = strings.Join(strings.Fields(strings.ToLower(question)), " ")
+	return strings.TrimRight(question, "?!. ")
+}
+
+// CachedResponse returns the answer given recently to question asked with
+// context, if no tool has run since. It implements tui.ResponseCache.
+func (a *Agent) CachedResponse(question, context string) (string, bool) {
+	if a.responses == nil {
+		return "", false
+	}
+	return a.responses.get(a.responseKey(question, context))
+}
+
+// CacheResponse keeps answer to question asked with context
+func (a *Agent) CacheResponse(question, context, answer string) {
+	if a.responses == nil || strings.TrimSpace(answer) == "" {
+		return
+	}
+	a.responses.put(a.responseKey(question, context), answer)
+}
+
+// observeExecution is told about every tool execution. It records the
+// execution in the audit log and drops the cached answers, which the tool
+// may have made wrong.
+func (a *Agent) observeExecution(record mcp.ExecutionRecord) {
+	a.audit.record(record)
+	if a.responses != nil {
+		a.responses.clear()
+	}
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache_Expires(t *testing.T) {
+	now := time.Now()
+	cache := newResponseCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+
+	cache.put("a", "first")
+	now = now.Add(time.Second)
+	cache.put("b", "second")
+	now = now.Add(29 * time.Second)
+	cache.put("c", "third")
+	_, ok := cache.get("a")
+	assert.False(t, ok, "The answer closest to expiring makes room")
+
+	answer, ok := cache.get("b")
+	require.True(t, ok)
+	assert.Equal(t, "second", answer)
+
+	now = now.Add(45 * time.Second)
+	_, ok = cache.get("b")
+	assert.False(t, ok)
+	_, ok = cache.get("c")
+	assert.True(t, ok)
+}
+
+func TestAgent_CachedResponse(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	agent.responses = newResponseCache(time.Minute, 10)
+
+	agent.CacheResponse("How many memories do I have?", "chat", "You have 3 memories.")
+	answer, ok := agent.CachedResponse("  how many memories do I  have ", "chat")
+	require.True(t, ok)
+	assert.Equal(t, "You have 3 memories.", answer)
+	_, ok = agent.CachedResponse("How many memories do I have?", "analysis")
+	assert.False(t, ok, "Questions asked with other context are answered again")
+
+	_, err := agent.ExecuteToolUnifiedWithContext(context.Background(), "remember", map[string]interface{}{"content": "Deploys are on Tuesdays"}, &model.ConversationContext{})
+	require.NoError(t, err)
+	_, ok = agent.CachedResponse("How many memories do I have?", "chat")
+	assert.False(t, ok, "Running a tool empties the cache")
+}
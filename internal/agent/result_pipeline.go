@@ -0,0 +1,218 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// DefaultResultPipeline names the transformers a tool result goes through
+// unless agent.result_pipelines says otherwise
+var DefaultResultPipeline = []string{"error_check", "metadata", "format", "context", "summarize"}
+
+// defaultPipelineKey is the agent.result_pipelines key that replaces
+// DefaultResultPipeline for every tool without a pipeline of its own
+const defaultPipelineKey = "default"
+
+// maxSummaryInput caps how much of an oversized result the model is asked to summarize
+const maxSummaryInput = 32 << 10
+
+// resultSummaryPrompt asks the model to summarize a tool result. It is
+// filled in with the tool's name, the user's request, and the result.
+const resultSummaryPrompt = `The %s tool returned the output below while working on this request: %q
+
+Summarize the output for answering the request. Keep every identifier, name, number, and error it contains that the request could need, and leave out the rest. Reply with the summary only.
+
+Output:
+%s`
+
+// ResultTransformer is one stage of the pipeline that turns a raw tool result
+// into text for the conversation. Transformers run in order, each seeing what
+// the ones before it left in the ToolOutput.
+type ResultTransformer interface {
+	// Name is how agent.result_pipelines refers to the transformer
+	Name() string
+	Transform(ctx context.Context, output *ToolOutput) error
+}
+
+// ToolOutput is a tool result on its way through the pipeline
+type ToolOutput struct {
+	ToolName string
+	Raw      interface{}                // The result as the tool returned it
+	Text     string                     // The result as text; formatters leave a result that already has text alone
+	Context  *model.ConversationContext // The conversation the tool was called in, if any
+}
+
+// transformerFunc is a ResultTransformer made from a function
+type transformerFunc struct {
+	name      string
+	transform func(ctx context.Context, output *ToolOutput) error
+}
+
+func (t transformerFunc) Name() string { return t.name }
+
+func (t transformerFunc) Transform(ctx context.Context, output *ToolOutput) error {
+	return t.transform(ctx, output)
+}
+
+// NewResultTransformer returns a ResultTransformer named name that runs transform
+func NewResultTransformer(name string, transform func(ctx context.Context, output *ToolOutput) error) ResultTransformer {
+	return transformerFunc{name: name, transform: transform}
+}
+
+// Register adds t to the transformers pipelines can name, replacing any
+// built-in or earlier transformer with the same name
+func (p *ToolResultProcessor) Register(t ResultTransformer) {
+	if p.transformers == nil {
+		p.transformers = make(map[string]ResultTransformer)
+	}
+	p.transformers[t.Name()] = t
+}
+
+// builtinTransformers returns the transformers every processor has
+func (p *ToolResultProcessor) builtinTransformers() map[string]ResultTransformer {
+	builtins := []ResultTransformer{
+		NewResultTransformer("error_check", p.checkResult),
+		NewResultTransformer("metadata", p.extractMetadata),
+		NewResultTransformer("format", p.formatResult),
+		NewResultTransformer("text", p.formatResultText),
+		NewResultTransformer("context", p.contextualize),
+		NewResultTransformer("summarize", p.summarizeResult),
+	}
+	byName := make(map[string]ResultTransformer, len(builtins))
+	for _, t := range builtins {
+		byName[t.Name()] = t
+	}
+	return byName
+}
+
+// pipelineFor returns the transformers to run for toolName, in order
+func (p *ToolResultProcessor) pipelineFor(toolName string) ([]ResultTransformer, error) {
+	names := DefaultResultPipeline
+	candidates := []string{toolName, p.normalizeMCPToolName(toolName), strings.ToLower(toolName), defaultPipelineKey}
+	for _, key := range candidates {
+		if pipeline, ok := p.Pipelines[key]; ok {
+			names = pipeline
+			break
+		}
+	}
+
+	builtins := p.builtinTransformers()
+	pipeline := make([]ResultTransformer, 0, len(names))
+	for _, name := range names {
+		t, ok := p.transformers[name]
+		if !ok {
+			t, ok = builtins[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown result transformer %q in the pipeline for %s", name, toolName)
+		}
+		pipeline = append(pipeline, t)
+	}
+	return pipeline, nil
+}
+
+// checkResult reports results that are missing or failed in place of
+// formatting them
+func (p *ToolResultProcessor) checkResult(ctx context.Context, output *ToolOutput) error {
+	switch result := output.Raw.(type) {
+	case nil:
+		p.logf("[PROCESSOR] Raw result is nil")
+		output.Text = "The tool returned no results."
+	case *mcp.ToolResult:
+		if result.IsError {
+			output.Text = "The tool reported an error: " + toolResultText(result)
+		}
+	case map[string]interface{}:
+		if errMsg, failed := p.checkForError(result); failed {
+			output.Text = errMsg
+		}
+	}
+	return nil
+}
+
+// extractMetadata keeps IDs and other useful values from the result in the
+// conversation context for follow-up requests
+func (p *ToolResultProcessor) extractMetadata(ctx context.Context, output *ToolOutput) error {
+	if output.Raw != nil {
+		p.extractAndStoreMetadata(output.ToolName, output.Raw, output.Context)
+	}
+	return nil
+}
+
+// formatResult formats the result according to its MCP content types and,
+// for JSON, the kind of data it holds
+func (p *ToolResultProcessor) formatResult(ctx context.Context, output *ToolOutput) error {
+	if output.Text != "" {
+		return nil
+	}
+	if toolResult := p.extractMCPToolResult(output.Raw); toolResult != nil {
+		p.logf("[PROCESSOR] Formatting MCP ToolResult")
+		output.Text = p.formatMCPContent(toolResult)
+		return nil
+	}
+	p.logf("[PROCESSOR] Not an MCP ToolResult format, using fallback presentation")
+	output.Text = p.formatFallbackContent(output.Raw)
+	return nil
+}
+
+// formatResultText passes the result's text through as the tool wrote it,
+// for tools whose output shouldn't be interpreted
+func (p *ToolResultProcessor) formatResultText(ctx context.Context, output *ToolOutput) error {
+	if output.Text != "" {
+		return nil
+	}
+	switch result := output.Raw.(type) {
+	case *mcp.ToolResult:
+		output.Text = toolResultText(result)
+	case string:
+		output.Text = result
+	default:
+		output.Text = p.formatFallbackContent(result)
+	}
+	return nil
+}
+
+// contextualize adds follow-up suggestions for the conversation
+func (p *ToolResultProcessor) contextualize(ctx context.Context, output *ToolOutput) error {
+	output.Text = p.generateContextualResponse(output.Text, output.Context)
+	return nil
+}
+
+// summarizeResult has the model summarize results longer than SummarizeOver
+// characters, keeping the full text in the conversation context. Results
+// stay whole when no model is set or the model fails.
+func (p *ToolResultProcessor) summarizeResult(ctx context.Context, output *ToolOutput) error {
+	if p.Model == nil || p.SummarizeOver <= 0 || len(output.Text) <= p.SummarizeOver {
+		return nil
+	}
+	query, text := "", output.Text
+	if output.Context != nil {
+		query = output.Context.UserQuery
+	}
+	if len(text) > maxSummaryInput {
+		text = strings.ToValidUTF8(text[:maxSummaryInput], "") + "\n... (output truncated)"
+	}
+
+	p.logf("[SUMMARIZE] Summarizing %d characters from %s", len(output.Text), output.ToolName)
+	response, err := p.Model.Generate(ctx, fmt.Sprintf(resultSummaryPrompt, output.ToolName, query, text), model.GenerateOptions{
+		Temperature: 0.2,
+		MaxTokens:   1000,
+	})
+	if err != nil {
+		p.logf("[SUMMARIZE] Keeping the full result of %s: %v", output.ToolName, err)
+		return nil
+	}
+	summary := strings.TrimSpace(response.Content)
+	if summary == "" {
+		return nil
+	}
+	if output.Context != nil {
+		output.Context.FullResult = output.Text
+	}
+	output.Text = summary
+	return nil
+}
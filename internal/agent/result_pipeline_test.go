@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func textResult(text string) *mcp.ToolResult {
+	return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: text}}}
+}
+
+func TestResultPipeline_PerToolOverride(t *testing.T) {
+	processor := &ToolResultProcessor{Pipelines: map[string][]string{
+		"read_file": {"error_check", "text", "context"},
+	}}
+	raw := textResult(`{"success": true, "message": "saved"}`)
+
+	formatted, err := processor.ProcessToolResult(context.Background(), "store", raw, "")
+	require.NoError(t, err)
+	assert.Equal(t, "✅ saved", formatted)
+
+	// The override matches MCP-prefixed names too
+	passedThrough, err := processor.ProcessToolResult(context.Background(), "mcp__files__read_file", raw, "")
+	require.NoError(t, err)
+	assert.Equal(t, `{"success": true, "message": "saved"}`, passedThrough)
+}
+
+func TestResultPipeline_RegisteredTransformer(t *testing.T) {
+	processor := &ToolResultProcessor{Pipelines: map[string][]string{
+		"default": {"error_check", "format", "shout"},
+	}}
+	processor.Register(NewResultTransformer("shout", func(ctx context.Context, output *ToolOutput) error {
+		output.Text = strings.ToUpper(output.Text)
+		return nil
+	}))
+
+	processed, err := processor.ProcessToolResult(context.Background(), "echo", textResult("hello"), "")
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", processed)
+
+	// Failed results are reported before any formatting
+	failed := textResult("disk full")
+	failed.IsError = true
+	processed, err = processor.ProcessToolResult(context.Background(), "echo", failed, "")
+	require.NoError(t, err)
+	assert.Equal(t, "THE TOOL REPORTED AN ERROR: DISK FULL", processed)
+}
+
+func TestResultPipeline_UnknownTransformer(t *testing.T) {
+	processor := &ToolResultProcessor{Pipelines: map[string][]string{"echo": {"format", "translate"}}}
+
+	_, err := processor.ProcessToolResult(context.Background(), "echo", textResult("hello"), "")
+	assert.EqualError(t, err, `unknown result transformer "translate" in the pipeline for echo`)
+}
+
+// summaryModel answers requests for summaries with a fixed summary, or fails,
+// recording the prompts
+type summaryModel struct {
+	MockModel
+	fail    bool
+	prompts []string
+}
+
+func (m *summaryModel) Generate(ctx context.Context, prompt string, options model.GenerateOptions) (*model.Response, error) {
+	if !strings.Contains(prompt, "Summarize the output") {
+		return &model.Response{Content: "{}"}, nil
+	}
+	m.prompts = append(m.prompts, prompt)
+	if m.fail {
+		return nil, errors.New("model offline")
+	}
+	return &model.Response{Content: " 3 rows match; the newest is order 1042. "}, nil
+}
+
+func TestResultPipeline_SummarizesOversizedResults(t *testing.T) {
+	m := &summaryModel{}
+	processor := &ToolResultProcessor{Model: m, SummarizeOver: 100}
+	output := strings.Repeat("order 1042 shipped\n", 20)
+	convContext := &model.ConversationContext{UserQuery: "what was my last order?"}
+
+	processed, err := processor.ProcessToolResultWithContext(context.Background(), "query", textResult(output), convContext)
+	require.NoError(t, err)
+	assert.Equal(t, "3 rows match; the newest is order 1042.", processed)
+	assert.Equal(t, strings.TrimSpace(output), strings.TrimSpace(convContext.FullResult), "The full output stays available")
+	require.Len(t, m.prompts, 1)
+	assert.Contains(t, m.prompts[0], `"what was my last order?"`)
+
+	// Short results are left alone, and the last full result is cleared
+	processed, err = processor.ProcessToolResultWithContext(context.Background(), "query", textResult("order 1042"), convContext)
+	require.NoError(t, err)
+	assert.Equal(t, "order 1042", processed)
+	assert.Empty(t, convContext.FullResult)
+	assert.Len(t, m.prompts, 1)
+}
+
+func TestResultPipeline_KeepsFullResultWhenSummaryFails(t *testing.T) {
+	processor := &ToolResultProcessor{Model: &summaryModel{fail: true}, SummarizeOver: 10}
+	convContext := &model.ConversationContext{}
+
+	processed, err := processor.ProcessToolResultWithContext(context.Background(), "query", textResult("a result longer than ten characters"), convContext)
+	require.NoError(t, err)
+	assert.Equal(t, "a result longer than ten characters", processed)
+	assert.Empty(t, convContext.FullResult)
+}
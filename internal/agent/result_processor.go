@@ -13,10 +13,17 @@ import (
 )
 
 // ToolResultProcessor processes raw tool results into user-friendly summaries
+// by running them through a pipeline of ResultTransformers
 type ToolResultProcessor struct {
-	// Can add configuration here later (e.g., verbosity level)
-	Logger *log.Logger
-	Model  model.Model // Optional: for LLM-based metadata extraction
+	Logger    *log.Logger
+	Model     model.Model         // Optional: for LLM-based metadata extraction
+	Pipelines map[string][]string // Transformer names to run per tool instead of DefaultResultPipeline
+
+	// SummarizeOver is the length in characters above which the summarize
+	// transformer has Model shorten a result; 0 never summarizes
+	SummarizeOver int
+
+	transformers map[string]ResultTransformer // Registered in addition to the built-in ones
 }
 
 
@@ -56,27 +63,24 @@ func (p *ToolResultProcessor) ProcessToolResultWithContext(ctx context.Context,
 	p.logf("[PROCESSOR] Raw result type: %T", rawResult)
 	p.logf("[PROCESSOR] Conversation history length: %d", len(convContext.History))
 
-	// Handle nil result
-	if rawResult == nil {
-		p.logf("[PROCESSOR] Raw result is nil")
-		return p.generateContextualResponse("The tool returned no results.", convContext), nil
+	if toolResult, ok := rawResult.(*mcp.ToolResult); ok && toolResult == nil {
+		rawResult = nil
 	}
-
-	// Extract metadata from the tool result before formatting
-	p.extractAndStoreMetadata(rawResult, convContext)
-
-	// The rawResult should be a ToolResult from the MCP server
-	// Try to extract it as a ToolResult struct or map representation
-	if toolResult := p.extractMCPToolResult(rawResult); toolResult != nil {
-		p.logf("[PROCESSOR] Successfully extracted MCP ToolResult with %d content items", 0)
-		baseResult := p.formatMCPContent(toolResult)
-		return p.generateContextualResponse(baseResult, convContext), nil
+	if convContext != nil {
+		convContext.FullResult = ""
 	}
 
-	// Fallback: treat as raw content if not in MCP ToolResult format
-	p.logf("[PROCESSOR] Not an MCP ToolResult format, using fallback presentation")
-	baseResult := p.formatFallbackContent(rawResult)
-	return p.generateContextualResponse(baseResult, convContext), nil
+	pipeline, err := p.pipelineFor(toolName)
+	if err != nil {
+		return "", err
+	}
+	output := &ToolOutput{ToolName: toolName, Raw: rawResult, Context: convContext}
+	for _, transformer := range pipeline {
+		if err := transformer.Transform(ctx, output); err != nil {
+			return "", fmt.Errorf("result transformer %s failed: %w", transformer.Name(), err)
+		}
+	}
+	return output.Text, nil
 }
 
 // checkForError checks if result contains an error
@@ -845,7 +849,9 @@ func (p *ToolResultProcessor) prettyPrintJSON(jsonStr string) string {
 	return ""
 }
 
-// generateContextualResponse enhances the base result with conversation context and follow-up suggestions
+// generateContextualResponse enhances the base result with conversation context.
+// Follow-up suggestions are stored in convContext.FollowUps rather than added
+// to the text, so the interface can offer them as selectable actions.
 func (p *ToolResultProcessor) generateContextualResponse(baseResult string, convContext *model.ConversationContext) string {
 	if convContext == nil {
 		return baseResult
@@ -853,63 +859,77 @@ func (p *ToolResultProcessor) generateContextualResponse(baseResult string, conv
 
 	p.logf("[PROCESSOR] Generating contextual response for user query: %s", convContext.UserQuery)
 
-	var response strings.Builder
-	response.WriteString(baseResult)
-
-	// Note: We extract metadata and store it in convContext.ExtractedMetadata
+	// Note: We extract metadata and store it in convContext.Entities
 	// but we DON'T show it in the user response. The metadata is available
 	// in conversation history for the model to reference when needed.
 	// This keeps responses clean while maintaining context for follow-up queries.
 
-	// Add contextual follow-up based on conversation history and result type
-	followUp := p.generateFollowUpSuggestions(baseResult, convContext)
-	if followUp != "" {
-		response.WriteString("\n\n")
-		response.WriteString(followUp)
-	}
+	// Add contextual follow-ups based on conversation history and result type
+	convContext.FollowUps = p.generateFollowUpSuggestions(baseResult, convContext)
 
-	return response.String()
+	return baseResult
 }
 
 // generateFollowUpSuggestions provides intelligent follow-up suggestions based on context
-func (p *ToolResultProcessor) generateFollowUpSuggestions(result string, convContext *model.ConversationContext) string {
+func (p *ToolResultProcessor) generateFollowUpSuggestions(result string, convContext *model.ConversationContext) []model.FollowUp {
 	// Analyze the result and conversation to suggest relevant follow-ups
 	queryLower := strings.ToLower(convContext.UserQuery)
 
-	var suggestions []string
+	var suggestions []model.FollowUp
 
 	// Search result follow-ups
 	if strings.Contains(result, "I found") && strings.Contains(result, "memor") {
 		// This is a search result
 		if !p.hasRecentToolUsage(convContext.PreviousTools, "store_memory") {
-			suggestions = append(suggestions, "💡 Would you like me to store any new insights from this search?")
+			suggestions = append(suggestions, model.FollowUp{
+				Label:  "Store new insights from this search",
+				Prompt: "Store the key insights from these search results as a new memory",
+			})
 		}
 		if strings.Contains(queryLower, "relate") || strings.Contains(queryLower, "connect") {
-			suggestions = append(suggestions, "🔗 I can also show you relationships between these memories.")
+			suggestions = append(suggestions, model.FollowUp{
+				Label:  "Show relationships between these memories",
+				Prompt: "Show the relationships between these memories",
+			})
 		}
 		if len(convContext.History) > 4 { // Longer conversation
-			suggestions = append(suggestions, "📊 Want me to analyze patterns across your memories?")
+			suggestions = append(suggestions, model.FollowUp{
+				Label:  "Analyze patterns across your memories",
+				Prompt: "Analyze the patterns across my memories",
+			})
 		}
 	}
 
 	// Storage result follow-ups
 	if strings.Contains(result, "stored") && strings.Contains(result, "memory") {
-		suggestions = append(suggestions, "🔍 You can search for this memory later or find related ones.")
+		suggestions = append(suggestions, model.FollowUp{
+			Label:  "Find related memories",
+			Prompt: "Find memories related to the one you just stored",
+		})
 		if p.hasRecentSearches(convContext.History) {
-			suggestions = append(suggestions, "🔗 I can connect this to your recent searches if helpful.")
+			suggestions = append(suggestions, model.FollowUp{
+				Label:  "Connect this to your recent searches",
+				Prompt: "Connect this memory to my recent searches",
+			})
 		}
 	}
 
 	// Analysis result follow-ups
 	if strings.Contains(result, "pattern") || strings.Contains(result, "analys") {
-		suggestions = append(suggestions, "💾 Would you like me to remember these insights for future reference?")
+		suggestions = append(suggestions, model.FollowUp{
+			Label:  "Remember these insights",
+			Prompt: "Store these insights as a memory for future reference",
+		})
 	}
 
 	// Context-aware suggestions based on conversation flow
 	if len(convContext.History) > 0 {
 		lastMessage := convContext.History[len(convContext.History)-1]
 		if lastMessage.Role == "user" && strings.Contains(strings.ToLower(lastMessage.Content), "help") {
-			suggestions = append(suggestions, "ℹ️ Need more specific guidance? Just ask!")
+			suggestions = append(suggestions, model.FollowUp{
+				Label:  "Show what else you can do",
+				Prompt: "What else can you help me with using your tools?",
+			})
 		}
 	}
 
@@ -918,11 +938,7 @@ func (p *ToolResultProcessor) generateFollowUpSuggestions(result string, convCon
 		suggestions = suggestions[:2]
 	}
 
-	if len(suggestions) > 0 {
-		return strings.Join(suggestions, "\n")
-	}
-
-	return ""
+	return suggestions
 }
 
 // hasRecentToolUsage checks if a tool was used recently in the conversation
@@ -938,41 +954,45 @@ func (p *ToolResultProcessor) hasRecentToolUsage(previousTools []string, toolNam
 // generateMetadataContext creates a natural language description of extracted metadata
 // This makes important IDs and values visible to the model for follow-up requests
 func (p *ToolResultProcessor) generateMetadataContext(convContext *model.ConversationContext) string {
-	if convContext == nil || len(convContext.ExtractedMetadata) == 0 {
+	if convContext == nil || convContext.Entities.Len() == 0 {
 		return ""
 	}
 
-	p.logf("[METADATA-CONTEXT] Generating context from %d metadata fields", len(convContext.ExtractedMetadata))
+	p.logf("[METADATA-CONTEXT] Generating context from %d metadata fields", convContext.Entities.Len())
+	latest := func(key string) (interface{}, bool) {
+		entity, ok := convContext.Entities.Latest(key)
+		return entity.Value, ok
+	}
 
 	var contextParts []string
 
 	// Memory ID is the most important for follow-up
-	if memoryID, exists := convContext.ExtractedMetadata["memory_id"]; exists {
+	if memoryID, exists := latest("memory_id"); exists {
 		contextParts = append(contextParts, fmt.Sprintf("(Memory ID: %v)", memoryID))
 		p.logf("[METADATA-CONTEXT] Including memory_id: %v", memoryID)
 	}
 
 	// Also check for generic ID field
-	if id, exists := convContext.ExtractedMetadata["id"]; exists {
-		if _, hasMemoryID := convContext.ExtractedMetadata["memory_id"]; !hasMemoryID {
+	if id, exists := latest("id"); exists {
+		if _, hasMemoryID := latest("memory_id"); !hasMemoryID {
 			contextParts = append(contextParts, fmt.Sprintf("(ID: %v)", id))
 			p.logf("[METADATA-CONTEXT] Including id: %v", id)
 		}
 	}
 
 	// Category and domain for context
-	if categoryID, exists := convContext.ExtractedMetadata["category_id"]; exists {
+	if categoryID, exists := latest("category_id"); exists {
 		contextParts = append(contextParts, fmt.Sprintf("Category: %v", categoryID))
 	}
-	if domain, exists := convContext.ExtractedMetadata["domain"]; exists {
+	if domain, exists := latest("domain"); exists {
 		contextParts = append(contextParts, fmt.Sprintf("Domain: %v", domain))
 	}
 
 	// First result ID from searches
-	if firstMemoryID, exists := convContext.ExtractedMetadata["first_memory_id"]; exists {
+	if firstMemoryID, exists := latest("first_memory_id"); exists {
 		contextParts = append(contextParts, fmt.Sprintf("(First result ID: %v)", firstMemoryID))
 		p.logf("[METADATA-CONTEXT] Including first_memory_id: %v", firstMemoryID)
-	} else if firstID, exists := convContext.ExtractedMetadata["first_id"]; exists {
+	} else if firstID, exists := latest("first_id"); exists {
 		contextParts = append(contextParts, fmt.Sprintf("(First result ID: %v)", firstID))
 		p.logf("[METADATA-CONTEXT] Including first_id: %v", firstID)
 	}
@@ -1004,34 +1024,35 @@ func (p *ToolResultProcessor) hasRecentSearches(history []model.Message) bool {
 }
 
 // extractAndStoreMetadata extracts important metadata from tool results
-// This makes metadata like memory_id, category_id available for follow-up requests
-func (p *ToolResultProcessor) extractAndStoreMetadata(rawResult interface{}, convContext *model.ConversationContext) {
+// This makes metadata like memory_id, category_id available for follow-up requests,
+// recorded with the tool it came from
+func (p *ToolResultProcessor) extractAndStoreMetadata(toolName string, rawResult interface{}, convContext *model.ConversationContext) {
 	if convContext == nil {
 		p.logf("[METADATA-DEBUG] ConvContext is NIL, cannot extract metadata")
 		return
 	}
 
-	p.logf("[METADATA-DEBUG] ConvContext pointer: %p, current metadata fields: %d", convContext, len(convContext.ExtractedMetadata))
+	p.logf("[METADATA-DEBUG] ConvContext pointer: %p, current metadata fields: %d", convContext, convContext.Entities.Len())
 
-	// Initialize metadata map if needed
-	if convContext.ExtractedMetadata == nil {
-		convContext.ExtractedMetadata = make(map[string]interface{})
-		p.logf("[METADATA-DEBUG] Initialized ExtractedMetadata map")
+	// Initialize the entity store if needed
+	if convContext.Entities == nil {
+		convContext.Entities = model.NewEntityStore()
+		p.logf("[METADATA-DEBUG] Initialized entity store")
 	}
 
 	// Try to extract metadata from MCP ToolResult format
 	if toolResult, ok := rawResult.(*mcp.ToolResult); ok {
 		p.logf("[METADATA-DEBUG] Raw result is MCP ToolResult, extracting...")
-		p.extractMetadataFromMCPResult(toolResult, convContext)
-		p.logf("[METADATA-DEBUG] After MCP extraction, metadata fields: %d", len(convContext.ExtractedMetadata))
+		p.extractMetadataFromMCPResult(toolName, toolResult, convContext)
+		p.logf("[METADATA-DEBUG] After MCP extraction, metadata fields: %d", convContext.Entities.Len())
 		return
 	}
 
 	// Try to extract from map format
 	if resultMap, ok := rawResult.(map[string]interface{}); ok {
 		p.logf("[METADATA-DEBUG] Raw result is map[string]interface{}, extracting...")
-		p.extractMetadataFromMap(resultMap, convContext)
-		p.logf("[METADATA-DEBUG] After map extraction, metadata fields: %d", len(convContext.ExtractedMetadata))
+		p.extractMetadataFromMap(toolName, resultMap, convContext)
+		p.logf("[METADATA-DEBUG] After map extraction, metadata fields: %d", convContext.Entities.Len())
 		return
 	}
 
@@ -1039,7 +1060,7 @@ func (p *ToolResultProcessor) extractAndStoreMetadata(rawResult interface{}, con
 }
 
 // extractMetadataFromMCPResult extracts metadata from MCP ToolResult using LLM
-func (p *ToolResultProcessor) extractMetadataFromMCPResult(toolResult *mcp.ToolResult, convContext *model.ConversationContext) {
+func (p *ToolResultProcessor) extractMetadataFromMCPResult(toolName string, toolResult *mcp.ToolResult, convContext *model.ConversationContext) {
 	p.logf("[METADATA-MCP] Extracting from MCP ToolResult with %d content items", len(toolResult.Content))
 	
 	// MCP results have content array - try to parse JSON from text content
@@ -1057,7 +1078,7 @@ func (p *ToolResultProcessor) extractMetadataFromMCPResult(toolResult *mcp.ToolR
 				var parsed map[string]interface{}
 				if err := json.Unmarshal([]byte(trimmed), &parsed); err == nil {
 					p.logf("[METADATA-MCP] Successfully parsed JSON with %d top-level keys", len(parsed))
-					p.extractMetadataFromMap(parsed, convContext)
+					p.extractMetadataFromMap(toolName, parsed, convContext)
 					return
 				} else {
 					p.logf("[METADATA-MCP] Failed to parse JSON: %v", err)
@@ -1066,7 +1087,7 @@ func (p *ToolResultProcessor) extractMetadataFromMCPResult(toolResult *mcp.ToolR
 			
 			// If not JSON, use LLM to extract metadata from natural language
 			p.logf("[METADATA-MCP] Using LLM-based extraction from natural language text...")
-			extracted := p.extractMetadataWithLLM(trimmed, convContext)
+			extracted := p.extractMetadataWithLLM(toolName, trimmed, convContext)
 			if extracted > 0 {
 				p.logf("[METADATA-MCP] Extracted %d metadata fields using LLM", extracted)
 				return
@@ -1076,14 +1097,27 @@ func (p *ToolResultProcessor) extractMetadataFromMCPResult(toolResult *mcp.ToolR
 	p.logf("[METADATA-MCP] No extractable metadata found in MCP ToolResult")
 }
 
+var (
+	// keyValuePattern matches any "key: value" or "key = value" pair, such as
+	// "memory_id: abc123", "ID: xyz", "count: 42", or "status: completed"
+	keyValuePattern = regexp.MustCompile(`(?i)([a-z][a-z0-9_-]*)\s*[:\=]\s*([a-f0-9\-]{8,}|\d+|[a-z][a-z0-9_-]*[a-z0-9])`)
+	// uuidPattern matches a UUID in phrases like "with ID: <uuid>"
+	uuidPattern = regexp.MustCompile(`(?i)(?:with\s+)?ID:\s*([a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12})`)
+
+	// Values isUsefulMetadata keeps for any key: UUIDs, hex hashes,
+	// alphanumeric codes, and numbers
+	uuidValue   = regexp.MustCompile(`^[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}$`)
+	hexValue    = regexp.MustCompile(`^[a-f0-9]{8,}$`)
+	codeValue   = regexp.MustCompile(`^[a-zA-Z0-9_-]{8,}$`)
+	numberValue = regexp.MustCompile(`^\d+$`)
+)
+
 // extractMetadataWithRegex extracts metadata from human-readable text using regex patterns
-func (p *ToolResultProcessor) extractMetadataWithRegex(text string, convContext *model.ConversationContext) int {
+func (p *ToolResultProcessor) extractMetadataWithRegex(toolName, text string, convContext *model.ConversationContext) int {
 	extracted := 0
 	
 	// Universal pattern: Extract any "key: value" or "key = value" pairs
 	// This catches: "memory_id: abc123", "ID: xyz", "count: 42", "status: completed", etc.
-	keyValuePattern := regexp.MustCompile(`(?i)([a-z][a-z0-9_-]*)\s*[:\=]\s*([a-f0-9\-]{8,}|\d+|[a-z][a-z0-9_-]*[a-z0-9])`)
-	
 	matches := keyValuePattern.FindAllStringSubmatch(text, -1)
 	for _, match := range matches {
 		if len(match) > 2 {
@@ -1095,28 +1129,22 @@ func (p *ToolResultProcessor) extractMetadataWithRegex(text string, convContext
 			
 			// Only extract if it looks like useful metadata (identifiers, counts, statuses)
 			if isUsefulMetadata(normalizedKey, value) {
-				// Skip if already exists
-				if _, exists := convContext.ExtractedMetadata[normalizedKey]; !exists {
-					convContext.ExtractedMetadata[normalizedKey] = value
-					extracted++
-					p.logf("[METADATA-REGEX] Extracted %s = %v", normalizedKey, value)
-				}
+				convContext.Entities.Record(normalizedKey, value, toolName)
+				extracted++
+				p.logf("[METADATA-REGEX] Extracted %s = %v", normalizedKey, value)
 			}
 		}
 	}
 	
 	// Special case: Extract UUIDs from common phrases like "with ID: <uuid>" or "successfully with ID: <uuid>"
 	// This handles cases where the key might not be explicitly stated
-	uuidPattern := regexp.MustCompile(`(?i)(?:with\s+)?ID:\s*([a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12})`)
 	if uuidMatches := uuidPattern.FindStringSubmatch(text); len(uuidMatches) > 1 {
 		uuid := uuidMatches[1]
 		// Infer the key from context - if "memory" appears in the text, it's likely a memory_id
 		inferredKey := inferIDKey(text)
-		if _, exists := convContext.ExtractedMetadata[inferredKey]; !exists {
-			convContext.ExtractedMetadata[inferredKey] = uuid
-			extracted++
-			p.logf("[METADATA-REGEX] Extracted (inferred) %s = %v", inferredKey, uuid)
-		}
+		convContext.Entities.Record(inferredKey, uuid, toolName)
+		extracted++
+		p.logf("[METADATA-REGEX] Extracted (inferred) %s = %v", inferredKey, uuid)
 	}
 	
 	return extracted
@@ -1166,21 +1194,21 @@ func isUsefulMetadata(key, value string) bool {
 	// Check if value looks like an identifier (UUID, hash, code)
 	if len(value) >= 8 {
 		// UUID pattern
-		if matched, _ := regexp.MatchString(`^[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}$`, value); matched {
+		if uuidValue.MatchString(value) {
 			return true
 		}
 		// Hex hash pattern (at least 8 chars)
-		if matched, _ := regexp.MatchString(`^[a-f0-9]{8,}$`, value); matched {
+		if hexValue.MatchString(value) {
 			return true
 		}
 		// Alphanumeric code pattern (mixed case, dashes, underscores)
-		if matched, _ := regexp.MatchString(`^[a-zA-Z0-9_-]{8,}$`, value); matched {
+		if codeValue.MatchString(value) {
 			return true
 		}
 	}
 	
 	// Numeric values are useful
-	if matched, _ := regexp.MatchString(`^\d+$`, value); matched {
+	if numberValue.MatchString(value) {
 		return true
 	}
 	
@@ -1221,7 +1249,7 @@ func inferIDKey(text string) string {
 }
 
 // extractMetadataWithLLM uses the LLM to extract relevant metadata from natural language text
-func (p *ToolResultProcessor) extractMetadataWithLLM(text string, convContext *model.ConversationContext) int {
+func (p *ToolResultProcessor) extractMetadataWithLLM(toolName, text string, convContext *model.ConversationContext) int {
 	// If no model available, fall back to regex
 	if p.Model == nil {
 		p.logf("[METADATA-LLM] No model available, skipping LLM extraction")
@@ -1274,14 +1302,9 @@ Respond ONLY with a JSON object, no explanation:`, text)
 	// Add extracted metadata to conversation context
 	count := 0
 	for key, value := range extracted {
-		// Skip if already exists
-		if _, exists := convContext.ExtractedMetadata[key]; exists {
-			continue
-		}
-		
 		// Normalize the key
 		normalizedKey := normalizeMetadataKey(key)
-		convContext.ExtractedMetadata[normalizedKey] = value
+		convContext.Entities.Record(normalizedKey, value, toolName)
 		count++
 		p.logf("[METADATA-LLM] Extracted %s = %v", normalizedKey, value)
 	}
@@ -1298,7 +1321,7 @@ func truncateString(s string, maxLen int) string {
 }
 
 // extractMetadataFromMap extracts metadata from a map result
-func (p *ToolResultProcessor) extractMetadataFromMap(resultMap map[string]interface{}, convContext *model.ConversationContext) {
+func (p *ToolResultProcessor) extractMetadataFromMap(toolName string, resultMap map[string]interface{}, convContext *model.ConversationContext) {
 	// Priority metadata keys to extract (these are most useful for follow-up requests)
 	priorityKeys := []string{
 		"memory_id", "id",
@@ -1307,12 +1330,17 @@ func (p *ToolResultProcessor) extractMetadataFromMap(resultMap map[string]interf
 	}
 
 	extracted := 0
+	recorded := make(map[string]bool)
+	record := func(key string, value interface{}) {
+		convContext.Entities.Record(key, value, toolName)
+		recorded[key] = true
+		extracted++
+	}
 	
 	// Extract priority keys first
 	for _, key := range priorityKeys {
 		if value, exists := resultMap[key]; exists && value != nil {
-			convContext.ExtractedMetadata[key] = value
-			extracted++
+			record(key, value)
 			p.logf("[METADATA] Extracted %s = %v", key, value)
 		}
 	}
@@ -1324,7 +1352,7 @@ func (p *ToolResultProcessor) extractMetadataFromMap(resultMap map[string]interf
 		}
 		
 		// Skip if already extracted
-		if _, exists := convContext.ExtractedMetadata[key]; exists {
+		if recorded[key] {
 			continue
 		}
 		
@@ -1343,8 +1371,7 @@ func (p *ToolResultProcessor) extractMetadataFromMap(resultMap map[string]interf
 			// Only extract simple types (strings, numbers, bools)
 			switch value.(type) {
 			case string, int, int64, float64, bool:
-				convContext.ExtractedMetadata[key] = value
-				extracted++
+				record(key, value)
 				p.logf("[METADATA] Extracted %s = %v (identifier-like field)", key, value)
 			}
 		}
@@ -1358,8 +1385,7 @@ func (p *ToolResultProcessor) extractMetadataFromMap(resultMap map[string]interf
 			for _, key := range priorityKeys {
 				if value, exists := firstResult[key]; exists && value != nil {
 					prefixedKey := "first_" + key
-					convContext.ExtractedMetadata[prefixedKey] = value
-					extracted++
+					record(prefixedKey, value)
 					p.logf("[METADATA] Extracted %s = %v", prefixedKey, value)
 				}
 			}
@@ -1371,7 +1397,7 @@ func (p *ToolResultProcessor) extractMetadataFromMap(resultMap map[string]interf
 				}
 				
 				prefixedKey := "first_" + key
-				if _, exists := convContext.ExtractedMetadata[prefixedKey]; exists {
+				if recorded[prefixedKey] {
 					continue
 				}
 				
@@ -1389,8 +1415,7 @@ func (p *ToolResultProcessor) extractMetadataFromMap(resultMap map[string]interf
 				   keyLower == "status" {
 					switch value.(type) {
 					case string, int, int64, float64, bool:
-						convContext.ExtractedMetadata[prefixedKey] = value
-						extracted++
+						record(prefixedKey, value)
 						p.logf("[METADATA] Extracted %s = %v (from first result)", prefixedKey, value)
 					}
 				}
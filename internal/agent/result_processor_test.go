@@ -2,6 +2,11 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
 	"testing"
 
 	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
@@ -232,15 +237,15 @@ func TestMetadataExtraction_MemoryID(t *testing.T) {
 	convContext := &model.ConversationContext{
 		UserQuery:         "Store this information",
 		SessionType:       "chat",
-		ExtractedMetadata: make(map[string]interface{}),
+		Entities:          model.NewEntityStore(),
 	}
 	
 	_, err := processor.ProcessToolResultWithContext(context.Background(), "store_memory", rawResult, convContext)
 	require.NoError(t, err)
 	
 	// Should extract memory_id into context
-	assert.Contains(t, convContext.ExtractedMetadata, "memory_id", "Should extract memory_id")
-	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440000", convContext.ExtractedMetadata["memory_id"])
+	assert.Contains(t, convContext.Entities.Values(), "memory_id", "Should extract memory_id")
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440000", convContext.Entities.Values()["memory_id"])
 }
 
 // TestMetadataExtraction_SearchResults tests extraction of ID from first search result
@@ -266,17 +271,17 @@ func TestMetadataExtraction_SearchResults(t *testing.T) {
 	convContext := &model.ConversationContext{
 		UserQuery:         "Find related memories",
 		SessionType:       "chat",
-		ExtractedMetadata: make(map[string]interface{}),
+		Entities:          model.NewEntityStore(),
 	}
 	
 	_, err := processor.ProcessToolResultWithContext(context.Background(), "search", rawResult, convContext)
 	require.NoError(t, err)
 	
 	// Should extract first result's IDs
-	assert.Contains(t, convContext.ExtractedMetadata, "first_id", "Should extract first result ID")
-	assert.Equal(t, "first-result-id", convContext.ExtractedMetadata["first_id"])
-	assert.Contains(t, convContext.ExtractedMetadata, "first_memory_id", "Should extract first result memory_id")
-	assert.Equal(t, "mem-123", convContext.ExtractedMetadata["first_memory_id"])
+	assert.Contains(t, convContext.Entities.Values(), "first_id", "Should extract first result ID")
+	assert.Equal(t, "first-result-id", convContext.Entities.Values()["first_id"])
+	assert.Contains(t, convContext.Entities.Values(), "first_memory_id", "Should extract first result memory_id")
+	assert.Equal(t, "mem-123", convContext.Entities.Values()["first_memory_id"])
 }
 
 // TestMetadataExtraction_JSONText tests extraction from JSON embedded in text content
@@ -296,16 +301,16 @@ func TestMetadataExtraction_JSONText(t *testing.T) {
 	convContext := &model.ConversationContext{
 		UserQuery:         "Store memory",
 		SessionType:       "chat",
-		ExtractedMetadata: make(map[string]interface{}),
+		Entities:          model.NewEntityStore(),
 	}
 	
 	_, err := processor.ProcessToolResultWithContext(context.Background(), "store_memory", rawResult, convContext)
 	require.NoError(t, err)
 	
 	// Should parse JSON from text and extract metadata
-	assert.Contains(t, convContext.ExtractedMetadata, "memory_id", "Should extract memory_id from JSON text")
-	assert.Equal(t, "extracted-from-json", convContext.ExtractedMetadata["memory_id"])
-	assert.Contains(t, convContext.ExtractedMetadata, "count", "Should extract count from JSON text")
+	assert.Contains(t, convContext.Entities.Values(), "memory_id", "Should extract memory_id from JSON text")
+	assert.Equal(t, "extracted-from-json", convContext.Entities.Values()["memory_id"])
+	assert.Contains(t, convContext.Entities.Values(), "count", "Should extract count from JSON text")
 }
 
 // TestMetadataContext_Generation tests that metadata is extracted into context
@@ -320,7 +325,7 @@ func TestMetadataContext_Generation(t *testing.T) {
 	convContext := &model.ConversationContext{
 		UserQuery:         "Store this",
 		SessionType:       "chat",
-		ExtractedMetadata: make(map[string]interface{}),
+		Entities:          model.NewEntityStore(),
 	}
 	
 	processed, err := processor.ProcessToolResultWithContext(context.Background(), "store_memory", rawResult, convContext)
@@ -331,7 +336,7 @@ func TestMetadataContext_Generation(t *testing.T) {
 	assert.NotEmpty(t, processed, "Should return a response")
 	
 	// Verify metadata was extracted into context for model to use
-	assert.Equal(t, "uuid-12345", convContext.ExtractedMetadata["memory_id"], "Metadata should be extracted into context")
+	assert.Equal(t, "uuid-12345", convContext.Entities.Values()["memory_id"], "Metadata should be extracted into context")
 }
 
 // TestMetadataContext_Accumulation tests metadata accumulates across multiple tool calls
@@ -341,7 +346,7 @@ func TestMetadataContext_Accumulation(t *testing.T) {
 	convContext := &model.ConversationContext{
 		UserQuery:         "First query",
 		SessionType:       "chat",
-		ExtractedMetadata: make(map[string]interface{}),
+		Entities:          model.NewEntityStore(),
 	}
 	
 	// First tool call - store memory
@@ -351,7 +356,7 @@ func TestMetadataContext_Accumulation(t *testing.T) {
 	}
 	_, err := processor.ProcessToolResultWithContext(context.Background(), "store_memory", result1, convContext)
 	require.NoError(t, err)
-	assert.Equal(t, "mem-001", convContext.ExtractedMetadata["memory_id"])
+	assert.Equal(t, "mem-001", convContext.Entities.Values()["memory_id"])
 	
 	// Second tool call - get stats
 	result2 := map[string]interface{}{
@@ -362,9 +367,28 @@ func TestMetadataContext_Accumulation(t *testing.T) {
 	require.NoError(t, err)
 	
 	// Both metadata should be present
-	assert.Equal(t, "mem-001", convContext.ExtractedMetadata["memory_id"], "Previous metadata should persist")
-	assert.Equal(t, 42, convContext.ExtractedMetadata["memory_count"], "New metadata should be added")
-	assert.Equal(t, "programming", convContext.ExtractedMetadata["domain"], "Domain should be extracted")
+	assert.Equal(t, "mem-001", convContext.Entities.Values()["memory_id"], "Previous metadata should persist")
+	assert.Equal(t, 42, convContext.Entities.Values()["memory_count"], "New metadata should be added")
+	assert.Equal(t, "programming", convContext.Entities.Values()["domain"], "Domain should be extracted")
+}
+
+// TestMetadataContext_KeepsEarlierValues tests a later ID doesn't replace an earlier one
+func TestMetadataContext_KeepsEarlierValues(t *testing.T) {
+	processor := &ToolResultProcessor{}
+
+	convContext := &model.ConversationContext{UserQuery: "Store two things", SessionType: "chat"}
+	for _, id := range []string{"mem-001", "mem-002"} {
+		_, err := processor.ProcessToolResultWithContext(context.Background(), "store_memory", map[string]interface{}{"success": true, "memory_id": id}, convContext)
+		require.NoError(t, err)
+	}
+
+	latest, ok := convContext.Entities.Latest("memory_id")
+	require.True(t, ok)
+	assert.Equal(t, "mem-002", latest.Value)
+	assert.Equal(t, "store_memory", latest.Tool, "Values record the tool they came from")
+	history := convContext.Entities.History("memory_id")
+	require.Len(t, history, 2)
+	assert.Equal(t, "mem-001", history[1].Value, "The first memory_id is kept")
 }
 
 // TestMetadataExtraction_UniversalMCPServer tests metadata extraction works with arbitrary MCP servers
@@ -389,25 +413,25 @@ func TestMetadataExtraction_UniversalMCPServer(t *testing.T) {
 	convContext := &model.ConversationContext{
 		UserQuery:         "Store this document",
 		SessionType:       "chat",
-		ExtractedMetadata: make(map[string]interface{}),
+		Entities:          model.NewEntityStore(),
 	}
 	
 	_, err := processor.ProcessToolResultWithContext(context.Background(), "custom_tool", rawResult, convContext)
 	require.NoError(t, err)
 	
 	// Verify ID-like fields were extracted
-	assert.Equal(t, "doc-12345", convContext.ExtractedMetadata["document_id"], "Should extract custom _id fields")
-	assert.Equal(t, "artifact-xyz", convContext.ExtractedMetadata["artifact_key"], "Should extract _key fields")
-	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440000", convContext.ExtractedMetadata["entity_uuid"], "Should extract _uuid fields")
-	assert.Equal(t, "ref-999", convContext.ExtractedMetadata["record_ref"], "Should extract _ref fields")
-	assert.Equal(t, "completed", convContext.ExtractedMetadata["status"], "Should extract status fields")
-	assert.Equal(t, "important-document", convContext.ExtractedMetadata["name"], "Should extract name fields")
+	assert.Equal(t, "doc-12345", convContext.Entities.Values()["document_id"], "Should extract custom _id fields")
+	assert.Equal(t, "artifact-xyz", convContext.Entities.Values()["artifact_key"], "Should extract _key fields")
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440000", convContext.Entities.Values()["entity_uuid"], "Should extract _uuid fields")
+	assert.Equal(t, "ref-999", convContext.Entities.Values()["record_ref"], "Should extract _ref fields")
+	assert.Equal(t, "completed", convContext.Entities.Values()["status"], "Should extract status fields")
+	assert.Equal(t, "important-document", convContext.Entities.Values()["name"], "Should extract name fields")
 	
 	// Verify complex types were NOT extracted
-	assert.NotContains(t, convContext.ExtractedMetadata, "metadata", "Should not extract nested objects")
-	assert.NotContains(t, convContext.ExtractedMetadata, "created_at", "Should not extract timestamp strings")
+	assert.NotContains(t, convContext.Entities.Values(), "metadata", "Should not extract nested objects")
+	assert.NotContains(t, convContext.Entities.Values(), "created_at", "Should not extract timestamp strings")
 	
-	t.Logf("Extracted %d metadata fields: %+v", len(convContext.ExtractedMetadata), convContext.ExtractedMetadata)
+	t.Logf("Extracted %d metadata fields: %+v", len(convContext.Entities.Values()), convContext.Entities.Values())
 }
 
 // TestMetadataExtraction_CustomResults tests extraction from custom result structures
@@ -434,19 +458,84 @@ func TestMetadataExtraction_CustomResults(t *testing.T) {
 	convContext := &model.ConversationContext{
 		UserQuery:         "Find resources",
 		SessionType:       "chat",
-		ExtractedMetadata: make(map[string]interface{}),
+		Entities:          model.NewEntityStore(),
 	}
 	
 	_, err := processor.ProcessToolResultWithContext(context.Background(), "custom_search", rawResult, convContext)
 	require.NoError(t, err)
 	
 	// Verify top-level metadata
-	assert.Equal(t, 2, convContext.ExtractedMetadata["total"], "Should extract total count")
+	assert.Equal(t, 2, convContext.Entities.Values()["total"], "Should extract total count")
 	
 	// Verify first result metadata with prefix
-	assert.Equal(t, "item-001", convContext.ExtractedMetadata["first_item_id"], "Should extract custom ID from first result")
-	assert.Equal(t, "res-alpha", convContext.ExtractedMetadata["first_resource_key"], "Should extract custom key from first result")
-	assert.Equal(t, "document", convContext.ExtractedMetadata["first_type"], "Should extract type from first result")
+	assert.Equal(t, "item-001", convContext.Entities.Values()["first_item_id"], "Should extract custom ID from first result")
+	assert.Equal(t, "res-alpha", convContext.Entities.Values()["first_resource_key"], "Should extract custom key from first result")
+	assert.Equal(t, "document", convContext.Entities.Values()["first_type"], "Should extract type from first result")
 	
-	t.Logf("Extracted %d metadata fields from custom results: %+v", len(convContext.ExtractedMetadata), convContext.ExtractedMetadata)
+	t.Logf("Extracted %d metadata fields from custom results: %+v", len(convContext.Entities.Values()), convContext.Entities.Values())
+}
+
+// TestFollowUpSuggestions_StoredInContext tests that follow-ups are offered as actions, not appended to the text
+func TestFollowUpSuggestions_StoredInContext(t *testing.T) {
+	processor := &ToolResultProcessor{}
+
+	convContext := &model.ConversationContext{
+		UserQuery:         "Remember that I prefer tabs",
+		SessionType:       "chat",
+		Entities:          model.NewEntityStore(),
+	}
+
+	result := processor.generateContextualResponse("Your memory has been stored", convContext)
+	assert.Equal(t, "Your memory has been stored", result)
+	require.Len(t, convContext.FollowUps, 1)
+	assert.Equal(t, "Find related memories", convContext.FollowUps[0].Label)
+	assert.NotEmpty(t, convContext.FollowUps[0].Prompt)
+
+	// Suggestions from an earlier result are replaced
+	processor.generateContextualResponse("Done", convContext)
+	assert.Empty(t, convContext.FollowUps)
+}
+
+// largeToolResults are payloads of the sizes real tools return: many search
+// results, a long document, and a large JSON array as text
+func largeToolResults() map[string]interface{} {
+	results := make([]interface{}, 1000)
+	for i := range results {
+		results[i] = map[string]interface{}{
+			"id":      fmt.Sprintf("mem%d", i),
+			"content": strings.Repeat("Notes about deploying the service to production. ", 4),
+			"tags":    []interface{}{"deploy", "ops"},
+			"score":   0.9,
+		}
+	}
+	records := make([]map[string]interface{}, 5000)
+	for i := range records {
+		records[i] = map[string]interface{}{"id": i, "name": fmt.Sprintf("record %d", i), "active": i%2 == 0}
+	}
+	array, _ := json.Marshal(records)
+
+	return map[string]interface{}{
+		"search": map[string]interface{}{"results": results, "total_count": len(results)},
+		"read_file": &mcp.ExecuteResult{Result: &mcp.ToolResult{Content: []mcp.Content{
+			{Type: "text", Text: strings.Repeat("A line of a long log file that a tool read back.\n", 20000)},
+		}}},
+		"list_records": &mcp.ExecuteResult{Result: &mcp.ToolResult{Content: []mcp.Content{
+			{Type: "text", Text: string(array)},
+		}}},
+	}
+}
+
+func BenchmarkProcessToolResult_LargePayloads(b *testing.B) {
+	processor := &ToolResultProcessor{Logger: log.New(io.Discard, "", 0)}
+	payloads := largeToolResults()
+	for _, tool := range []string{"search", "read_file", "list_records"} {
+		b.Run(tool, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := processor.ProcessToolResult(context.Background(), tool, payloads[tool], "deploy"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
 }
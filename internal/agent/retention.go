@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// RetentionPolicy converts the configured retention limits for storage
+func RetentionPolicy(cfg config.RetentionConfig) storage.RetentionPolicy {
+	return storage.RetentionPolicy{
+		MaxConversations: cfg.MaxConversations,
+		MaxAge:           cfg.MaxAge,
+		MaxSize:          int64(cfg.MaxSizeMB) << 20,
+	}
+}
+
+// pruner enforces the retention policy on the open database in the
+// background, vacuuming when enough time has passed since the last vacuum
+type pruner struct {
+	store  *storage.ConversationStore
+	config config.RetentionConfig
+	logger *log.Logger
+	bus    *events.Bus // Told of each run, as a scheduled task
+	stop   chan struct{}
+	done   sync.WaitGroup
+}
+
+// startPruning prunes history now and then every configured interval until
+// the returned pruner is stopped. It returns nil when pruning is disabled.
+func startPruning(store *storage.ConversationStore, cfg config.RetentionConfig, logger *log.Logger, bus *events.Bus, crashes *crashReporter) *pruner {
+	if cfg.PruneInterval <= 0 {
+		return nil
+	}
+
+	p := &pruner{store: store, config: cfg, logger: logger, bus: bus, stop: make(chan struct{})}
+	p.done.Add(1)
+	go func() {
+		defer p.done.Done()
+		defer crashes.recover("history pruning")
+		ticker := time.NewTicker(cfg.PruneInterval)
+		defer ticker.Stop()
+		for {
+			p.prune()
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// prune applies the retention policy once and publishes the run
+func (p *pruner) prune() {
+	started := time.Now()
+	run := events.ScheduledTaskEvent{Task: "history_pruning"}
+	defer func() {
+		run.Duration = time.Since(started)
+		if p.bus != nil {
+			p.bus.Publish(run)
+		}
+	}()
+
+	result, err := p.store.Prune(RetentionPolicy(p.config))
+	if err != nil {
+		p.logger.Printf("Warning: Failed to prune conversation history: %v", err)
+		run.Error = err.Error()
+		return
+	}
+	run.Detail = fmt.Sprintf("pruned %d conversations (%d messages)", result.Conversations, result.Messages)
+	if result.Conversations > 0 {
+		p.logger.Printf("Pruned %d conversations (%d messages) from history", result.Conversations, result.Messages)
+	}
+
+	vacuumed, err := p.store.VacuumIfDue(p.config.VacuumInterval)
+	if err != nil {
+		p.logger.Printf("Warning: Failed to vacuum storage: %v", err)
+		run.Error = err.Error()
+	} else if vacuumed {
+		p.logger.Println("Vacuumed storage")
+		run.Detail += " and vacuumed storage"
+	}
+}
+
+// Stop ends background pruning and waits for a run in progress to finish
+func (p *pruner) Stop() {
+	close(p.stop)
+	p.done.Wait()
+}
@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	policy := RetentionPolicy(config.RetentionConfig{MaxConversations: 50, MaxAge: time.Hour, MaxSizeMB: 2})
+	assert.Equal(t, storage.RetentionPolicy{MaxConversations: 50, MaxAge: time.Hour, MaxSize: 2 << 20}, policy)
+}
+
+func TestStartPruning(t *testing.T) {
+	store, err := storage.NewConversationStore(filepath.Join(t.TempDir(), storage.DatabaseFile))
+	require.NoError(t, err)
+	defer store.Close()
+	for _, id := range []string{"old", "new"} {
+		_, err := store.CreateConversation(id, id)
+		require.NoError(t, err)
+	}
+
+	logger := log.New(io.Discard, "", 0)
+	assert.Nil(t, startPruning(store, config.RetentionConfig{MaxConversations: 1}, logger, nil, nil), "A zero interval disables pruning")
+
+	bus := events.NewBus(10)
+	p := startPruning(store, config.RetentionConfig{MaxConversations: 1, PruneInterval: time.Hour}, logger, bus, nil)
+	require.NotNil(t, p)
+	assert.Eventually(t, func() bool {
+		conversations, err := store.ListConversations(10, 0)
+		return err == nil && len(conversations) == 1 && conversations[0].ID == "new"
+	}, time.Second, 10*time.Millisecond, "History is pruned as soon as pruning starts")
+	p.Stop()
+
+	history := bus.History()
+	require.Len(t, history, 1)
+	run := history[0].(events.ScheduledTaskEvent)
+	assert.Equal(t, "history_pruning", run.Task)
+	assert.Equal(t, "pruned 1 conversations (0 messages)", run.Detail)
+	assert.Empty(t, run.Error)
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+)
+
+// ConnectServersInBackground makes Start return without waiting for MCP
+// servers, so the TUI paints at once. The tools a server offered last time
+// are available right away unless its config changed, and calling one waits
+// for the server to finish starting.
+func (a *Agent) ConnectServersInBackground() {
+	a.connectInBackground = true
+}
+
+// connectServer connects to an MCP server and caches the tools it offers. It
+// reports whether the server connected.
+func (a *Agent) connectServer(ctx context.Context, server config.ServerConfig) bool {
+	a.logger.Printf("Connecting to MCP server: %s", server.Name)
+	if err := a.mcpManager.AddServer(ctx, server); err != nil {
+		a.logger.Printf("Failed to connect to MCP server %s: %v", server.Name, err)
+		a.Notify(events.LevelError, "Server %s failed to connect", server.Name)
+		return false
+	}
+	a.logger.Printf("Successfully connected to MCP server: %s", server.Name)
+
+	if err := a.toolSchemas.Save(server, a.mcpRegistry.ListToolsForServer(server.Name)); err != nil {
+		a.logger.Printf("Warning: %v", err)
+	}
+	return true
+}
+
+// connectServersInBackground offers the cached tools of servers and then
+// connects to them all at once, until Stop is called. When a server's tools
+// differ from the cached ones, the change is published.
+func (a *Agent) connectServersInBackground(servers []config.ServerConfig) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopConnecting = cancel
+
+	for _, server := range servers {
+		cached := a.toolSchemas.Tools(server)
+		if cached != nil {
+			a.mcpRegistry.RegisterCachedTools(server.Name, cached)
+			a.logger.Printf("Loaded %d cached tools of MCP server %s", len(cached), server.Name)
+		}
+
+		a.connecting.Add(1)
+		go func(server config.ServerConfig) {
+			defer a.connecting.Done()
+			defer a.crashes.recover("connecting to MCP server " + server.Name)
+
+			var tools []mcp.Tool
+			if a.connectServer(ctx, server) {
+				tools = a.mcpRegistry.ListToolsForServer(server.Name)
+			} else {
+				// Drop the cached tools, which can't be called
+				a.mcpRegistry.UnregisterServer(server.Name)
+			}
+			if added, removed := toolChanges(cached, tools); len(added) > 0 || len(removed) > 0 {
+				a.handleMCPUpdate(ToolUpdate{ServerName: server.Name, ToolCount: len(tools), Added: added, Removed: removed})
+			}
+		}(server)
+	}
+}
+
+// toolChanges returns the names of the tools in after that aren't in
+// before, and of those in before that aren't in after
+func toolChanges(before, after []mcp.Tool) (added, removed []string) {
+	names := make(map[string]bool, len(before))
+	for _, tool := range before {
+		names[tool.Name] = true
+	}
+	for _, tool := range after {
+		if !names[tool.Name] {
+			added = append(added, tool.Name)
+		}
+		delete(names, tool.Name)
+	}
+	for _, tool := range before {
+		if names[tool.Name] {
+			removed = append(removed, tool.Name)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"slices"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// sessionCapabilities are the kinds of tools each session type works with;
+// chat works with all of them
+var sessionCapabilities = map[string][]ToolCapability{
+	"analysis":   {CapabilityAnalyze, CapabilitySearch},
+	"automation": {CapabilityCreate, CapabilityUpdate, CapabilityTransform},
+}
+
+// sessionReminders are the closing instructions for each session type
+var sessionReminders = map[string]string{
+	"analysis":   "**Focus on data-driven insights** and use analysis tools when appropriate",
+	"automation": "**Emphasize efficiency** and suggest automation opportunities",
+}
+
+// sessionFocus describes what the assistant concentrates on in a session type
+func sessionFocus(sessionType string) string {
+	switch sessionType {
+	case "analysis":
+		return "You excel at analyzing data and providing insights."
+	case "automation":
+		return "You focus on automating tasks and managing data efficiently."
+	default:
+		return "You help users accomplish their goals efficiently and accurately."
+	}
+}
+
+// ModePrompt returns the instructions sent with every request made in a
+// session mode, or "" for chat, which needs none. It implements
+// tui.SessionModer.
+func (a *Agent) ModePrompt(mode string) string {
+	reminder, ok := sessionReminders[mode]
+	if !ok {
+		return ""
+	}
+	return sessionFocus(mode) + " " + reminder + "."
+}
+
+// ModeTools narrows tools to the kinds a session mode works with: analysis
+// keeps tools that analyze or search, automation those that create, update,
+// or transform. Chat, and a mode none of the tools suit, keeps them all.
+func (a *Agent) ModeTools(mode string, tools []model.ToolDefinition) []model.ToolDefinition {
+	capabilities, ok := sessionCapabilities[mode]
+	if !ok {
+		return tools
+	}
+	var suited []model.ToolDefinition
+	for _, tool := range tools {
+		capability := toolCapability(mcp.Tool{Name: tool.Name, Description: tool.Description})
+		if slices.Contains(capabilities, capability) {
+			suited = append(suited, tool)
+		}
+	}
+	if len(suited) == 0 {
+		return tools
+	}
+	return suited
+}
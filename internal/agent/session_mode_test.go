@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgent_ModeTools(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	tools := []model.ToolDefinition{
+		{Name: "remember", Description: "Save a fact about the user or their work"},
+		{Name: "recall", Description: "Find remembered facts related to a question"},
+		{Name: "delete_fact", Description: "Delete a remembered fact by its ID"},
+	}
+	names := func(tools []model.ToolDefinition) []string {
+		var names []string
+		for _, tool := range tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"recall"}, names(agent.ModeTools("analysis", tools)))
+	assert.Equal(t, []string{"remember"}, names(agent.ModeTools("automation", tools)))
+	assert.Equal(t, tools, agent.ModeTools("chat", tools))
+	assert.Equal(t, tools[2:], agent.ModeTools("analysis", tools[2:]), "Tools are kept when none suits the mode")
+}
+
+func TestAgent_ModePrompt(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	assert.Empty(t, agent.ModePrompt("chat"))
+	assert.Equal(t, "You excel at analyzing data and providing insights. **Focus on data-driven insights** and use analysis tools when appropriate.", agent.ModePrompt("analysis"))
+}
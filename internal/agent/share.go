@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// gistEndpoint is where gists are uploaded when share.endpoint is empty
+const gistEndpoint = "https://api.github.com/gists"
+
+// maxShareResponse bounds how much of an upload's response is read for the
+// transcript's URL
+const maxShareResponse = 1 << 20
+
+// Share writes messages as a sanitized Markdown transcript in the data
+// directory and, when share.upload is set, uploads it. It returns the path of
+// the file and the URL it was uploaded to. When only the upload fails, the
+// path is returned with the error.
+func (a *Agent) Share(ctx context.Context, messages []*storage.Message) (path, link string, err error) {
+	homeDir, _ := os.UserHomeDir()
+	transcript, err := storage.ShareTranscript(nil, messages, homeDir)
+	if err != nil {
+		return "", "", err
+	}
+
+	path = storage.SharePath(a.config.Storage.DataDir, time.Now())
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", "", fmt.Errorf("create share directory: %w", err)
+	}
+	if err := os.WriteFile(path, transcript, 0600); err != nil {
+		return "", "", fmt.Errorf("write transcript: %w", err)
+	}
+	a.logger.Printf("Shared %d messages to %s", len(messages), path)
+
+	cfg := a.config.Share
+	if cfg.Upload == "" || cfg.Upload == "none" {
+		return path, "", nil
+	}
+	if a.config.Agent.Offline {
+		return path, "", fmt.Errorf("%w: the transcript wasn't uploaded", ErrOffline)
+	}
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	if cfg.Upload == "gist" {
+		link, err = uploadGist(ctx, cfg, filepath.Base(path), transcript)
+	} else {
+		link, err = uploadPaste(ctx, cfg, transcript)
+	}
+	if err != nil {
+		a.logger.Printf("Failed to upload %s: %v", path, err)
+		return path, "", fmt.Errorf("upload transcript: %w", err)
+	}
+	a.logger.Printf("Uploaded %s to %s", path, link)
+	return path, link, nil
+}
+
+// uploadPaste posts transcript to the paste service at share.endpoint and
+// returns the URL it answers with
+func uploadPaste(ctx context.Context, cfg config.ShareConfig, transcript []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Endpoint, bytes.NewReader(transcript))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/markdown; charset=utf-8")
+	return postShare(req, cfg.Token)
+}
+
+// uploadGist creates a secret gist holding transcript as the file name and
+// returns its URL
+func uploadGist(ctx context.Context, cfg config.ShareConfig, name string, transcript []byte) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"description": "Othello conversation",
+		"public":      false,
+		"files":       map[string]interface{}{name: map[string]string{"content": string(transcript)}},
+	})
+	if err != nil {
+		return "", err
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = gistEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return postShare(req, cfg.Token)
+}
+
+// postShare sends the upload req with token, if any, and returns the URL of
+// the uploaded transcript from the response
+func postShare(req *http.Request, token string) (string, error) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxShareResponse))
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("%s: %s", resp.Status, firstLine(body))
+	}
+	return shareURL(body)
+}
+
+// shareURL returns the URL of an uploaded transcript from the response of
+// the service: the html_url or url of a JSON object, or the first line of
+// plain text
+func shareURL(body []byte) (string, error) {
+	var fields struct {
+		HTMLURL string `json:"html_url"`
+		URL     string `json:"url"`
+	}
+	link := firstLine(body)
+	if json.Unmarshal(body, &fields) == nil {
+		link = fields.HTMLURL
+		if link == "" {
+			link = fields.URL
+		}
+	}
+	if u, err := url.Parse(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("the response has no URL of the transcript")
+	}
+	return link, nil
+}
+
+// firstLine returns the first line of body without surrounding space
+func firstLine(body []byte) string {
+	line, _, _ := strings.Cut(string(body), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shareMessages is a conversation holding a key that must not be shared
+var shareMessages = []*storage.Message{
+	{Role: "user", Content: "Use the key sk-abcdefghijklmnopqrstuvwxyz123"},
+	{Role: "assistant", Content: "Done."},
+}
+
+func newTestShareAgent(t *testing.T, share config.ShareConfig) (*Agent, string) {
+	t.Helper()
+	agent, dir := newTestAskAgent(t, &MockModel{})
+	share.Timeout = 5 * time.Second
+	agent.config.Share = share
+	return agent, dir
+}
+
+func TestAgent_ShareWritesSanitizedTranscript(t *testing.T) {
+	agent, dir := newTestShareAgent(t, config.ShareConfig{Upload: "none"})
+
+	path, link, err := agent.Share(context.Background(), shareMessages)
+	require.NoError(t, err)
+	assert.Empty(t, link)
+	assert.Equal(t, filepath.Join(dir, storage.ShareDir), filepath.Dir(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Use the key ********")
+	assert.NotContains(t, string(data), "sk-abcdefghijklmnopqrstuvwxyz123")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestAgent_ShareUploadsToPaste(t *testing.T) {
+	var received string
+	paste := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer paste-token", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		fmt.Fprintln(w, "https://paste.example.com/abc")
+	}))
+	defer paste.Close()
+	agent, _ := newTestShareAgent(t, config.ShareConfig{Upload: "paste", Endpoint: paste.URL, Token: "paste-token"})
+
+	_, link, err := agent.Share(context.Background(), shareMessages)
+	require.NoError(t, err)
+	assert.Equal(t, "https://paste.example.com/abc", link)
+	assert.Contains(t, received, "Use the key ********")
+}
+
+func TestAgent_ShareUploadsGist(t *testing.T) {
+	gists := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var gist struct {
+			Public bool                         `json:"public"`
+			Files  map[string]map[string]string `json:"files"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gist))
+		assert.False(t, gist.Public)
+		require.Len(t, gist.Files, 1)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"url": "https://api.github.com/gists/1", "html_url": "https://gist.github.com/1"}`)
+	}))
+	defer gists.Close()
+	agent, _ := newTestShareAgent(t, config.ShareConfig{Upload: "gist", Endpoint: gists.URL, Token: "gh-token"})
+
+	_, link, err := agent.Share(context.Background(), shareMessages)
+	require.NoError(t, err)
+	assert.Equal(t, "https://gist.github.com/1", link)
+}
+
+func TestAgent_ShareUploadFailureKeepsFile(t *testing.T) {
+	paste := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad token", http.StatusUnauthorized)
+	}))
+	defer paste.Close()
+	agent, _ := newTestShareAgent(t, config.ShareConfig{Upload: "paste", Endpoint: paste.URL})
+
+	path, link, err := agent.Share(context.Background(), shareMessages)
+	assert.ErrorContains(t, err, "401 Unauthorized: bad token")
+	assert.Empty(t, link)
+	assert.FileExists(t, path)
+}
+
+func TestAgent_ShareOfflineDoesNotUpload(t *testing.T) {
+	paste := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the transcript was uploaded in offline mode")
+	}))
+	defer paste.Close()
+	agent, _ := newTestShareAgent(t, config.ShareConfig{Upload: "paste", Endpoint: paste.URL})
+	agent.config.Agent.Offline = true
+
+	path, _, err := agent.Share(context.Background(), shareMessages)
+	assert.ErrorIs(t, err, ErrOffline)
+	assert.FileExists(t, path)
+}
+
+func TestShareURL(t *testing.T) {
+	link, err := shareURL([]byte("https://paste.example.com/abc\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://paste.example.com/abc", link)
+
+	link, err = shareURL([]byte(`{"url": "https://paste.example.com/xyz"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "https://paste.example.com/xyz", link)
+
+	_, err = shareURL([]byte("created"))
+	assert.Error(t, err)
+}
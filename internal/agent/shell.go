@@ -0,0 +1,248 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// shellServerName is the name the run_command tool is registered under
+const shellServerName = "othello-shell"
+
+// shellTool describes the tool shellClient offers
+var shellTool = mcp.Tool{
+	Name:        "run_command",
+	Description: "Run a program on the user's computer and return its output. The command is split into a program and arguments like a shell would, but pipes, redirects, globs, and variables are not expanded.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"command": map[string]interface{}{"type": "string", "description": "Program and arguments, such as: git log --oneline -5"},
+			"dir":     map[string]interface{}{"type": "string", "description": "Directory to run in, relative to the working directory; defaults to the working directory"},
+		},
+		"required": []interface{}{"command"},
+	},
+}
+
+// shellClient runs commands for the model. It is an in-process mcp.Client,
+// so its calls are validated, checked against tool policies, and audited like
+// those of any MCP server. Only allowlisted programs run, with no shell, for
+// a limited time, and, unless configured otherwise, only once the user has
+// approved the exact call.
+type shellClient struct {
+	config config.ShellToolConfig
+}
+
+func (c *shellClient) Connect(ctx context.Context) error    { return nil }
+func (c *shellClient) Disconnect(ctx context.Context) error { return nil }
+func (c *shellClient) IsConnected() bool                    { return true }
+func (c *shellClient) GetTransport() string                 { return "builtin" }
+
+// ListTools implements mcp.Client
+func (c *shellClient) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	return []mcp.Tool{shellTool}, nil
+}
+
+// GetInfo implements mcp.Client
+func (c *shellClient) GetInfo(ctx context.Context) (*mcp.ServerInfo, error) {
+	return &mcp.ServerInfo{Name: shellServerName, Version: "1.0.0"}, nil
+}
+
+// CallTool implements mcp.Client. Refused and failed commands are reported
+// as error results so the model can see why; a command that ran and exited
+// with an error is not a failed call, and its exit status is in the result.
+func (c *shellClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (*mcp.ToolResult, error) {
+	text, err := c.run(ctx, name, params)
+	if err != nil {
+		return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: text}}}, nil
+}
+
+// run checks a run_command call and runs its command
+func (c *shellClient) run(ctx context.Context, name string, params map[string]interface{}) (string, error) {
+	if name != shellTool.Name {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	if c.config.RequireApproval && !model.CallApproved(ctx, name, params) {
+		return "", fmt.Errorf("%s needs the user's approval, which can only be given in the chat; set tools.shell.require_approval to false to run commands without it", name)
+	}
+
+	command, _ := params["command"].(string)
+	args, err := splitCommand(command)
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 0 {
+		return "", fmt.Errorf("command is empty")
+	}
+	if !c.allowed(args[0]) {
+		return "", fmt.Errorf("%s is not an allowed command; allowed: %s", args[0], strings.Join(c.config.AllowedCommands, ", "))
+	}
+	subdir, _ := params["dir"].(string)
+	dir, err := c.dir(subdir)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.WaitDelay = time.Second
+	output := &cappedBuffer{limit: c.config.MaxOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return "", fmt.Errorf("%s timed out after %s\n%s", args[0], c.config.Timeout, output)
+	case errors.As(err, &exitErr):
+		return fmt.Sprintf("%s\nExit status: %d", output, exitErr.ExitCode()), nil
+	case err != nil:
+		return "", fmt.Errorf("run %s: %w", args[0], err)
+	}
+	if output.Len() == 0 {
+		return "The command finished with no output.", nil
+	}
+	return output.String(), nil
+}
+
+// allowed reports whether program may run. Programs are found on PATH by
+// name, so names with a directory are refused.
+func (c *shellClient) allowed(program string) bool {
+	if strings.ContainsAny(program, `/\`) {
+		return false
+	}
+	for _, pattern := range c.config.AllowedCommands {
+		if matched, _ := path.Match(pattern, program); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// dir returns the directory to run a command in: subdir of the working
+// directory, which it may not leave, even through a symlink
+func (c *shellClient) dir(subdir string) (string, error) {
+	workingDir := c.config.WorkingDir
+	if workingDir == "" {
+		workingDir = "."
+	}
+	workingDir = absolutePath(workingDir)
+	if subdir == "" {
+		return workingDir, nil
+	}
+	if resolved, err := filepath.EvalSymlinks(workingDir); err == nil {
+		workingDir = resolved
+	}
+	dir := subdir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(workingDir, dir)
+	}
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("dir %q: %w", subdir, err)
+	}
+	if !insideAny(dir, []string{workingDir}) {
+		return "", fmt.Errorf("dir %q is outside the working directory %s", subdir, workingDir)
+	}
+	return dir, nil
+}
+
+// splitCommand splits a command into words the way a shell would, honoring
+// single quotes, double quotes, and backslash escapes, without expanding
+// anything
+func splitCommand(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("command has an unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("command ends with a backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// cappedBuffer keeps the first limit bytes written to it and counts the rest
+type cappedBuffer struct {
+	limit   int
+	data    []byte
+	dropped int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	keep := min(len(p), b.limit-len(b.data))
+	b.data = append(b.data, p[:keep]...)
+	b.dropped += len(p) - keep
+	return len(p), nil
+}
+
+// Len returns how many bytes were written, including those dropped
+func (b *cappedBuffer) Len() int {
+	return len(b.data) + b.dropped
+}
+
+// String returns the bytes kept, noting how many were dropped
+func (b *cappedBuffer) String() string {
+	text := strings.TrimRight(string(b.data), "\n")
+	if b.dropped > 0 {
+		text += fmt.Sprintf("\n[%d more bytes of output were cut]", b.dropped)
+	}
+	return text
+}
+
+// registerShell offers the run_command tool to the model when it is enabled
+func (a *Agent) registerShell() {
+	if !a.config.Tools.Shell.Enabled {
+		return
+	}
+	if err := a.mcpRegistry.RegisterServer(shellServerName, &shellClient{config: a.config.Tools.Shell}); err != nil {
+		a.logger.Printf("Warning: Failed to register the run_command tool: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestShellClient(t *testing.T) *shellClient {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("run_command tests use Unix programs")
+	}
+	return &shellClient{config: config.ShellToolConfig{
+		Enabled:         true,
+		RequireApproval: true,
+		AllowedCommands: []string{"echo", "ls", "sh", "sleep", "false"},
+		Timeout:         5 * time.Second,
+		MaxOutput:       1024,
+		WorkingDir:      t.TempDir(),
+	}}
+}
+
+// runCommand calls run_command with command, approved by the user
+func runCommand(t *testing.T, client *shellClient, params map[string]interface{}) (string, bool) {
+	t.Helper()
+	ctx := model.WithApprovedCalls(context.Background(), []model.ToolCall{{Name: "run_command", Arguments: params}})
+	result, err := client.CallTool(ctx, "run_command", params)
+	require.NoError(t, err)
+	return resultText(t, result), result.IsError
+}
+
+func TestShellClient_RunsApprovedCommands(t *testing.T) {
+	client := newTestShellClient(t)
+	require.NoError(t, os.Mkdir(filepath.Join(client.config.WorkingDir, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(client.config.WorkingDir, "src", "main.go"), []byte("package main"), 0644))
+
+	text, isError := runCommand(t, client, map[string]interface{}{"command": `echo "hello  world" it\'s`})
+	assert.False(t, isError)
+	assert.Equal(t, "hello  world it's", text)
+
+	text, isError = runCommand(t, client, map[string]interface{}{"command": "ls", "dir": "src"})
+	assert.False(t, isError)
+	assert.Equal(t, "main.go", text)
+
+	text, isError = runCommand(t, client, map[string]interface{}{"command": "false"})
+	assert.False(t, isError, "A command that fails still ran")
+	assert.Contains(t, text, "Exit status: 1")
+
+	text, _ = runCommand(t, client, map[string]interface{}{"command": "sh -c true"})
+	assert.Equal(t, "The command finished with no output.", text)
+}
+
+func TestShellClient_RefusesCommands(t *testing.T) {
+	client := newTestShellClient(t)
+	params := map[string]interface{}{"command": "echo hi"}
+
+	result, err := client.CallTool(context.Background(), "run_command", params)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "needs the user's approval")
+
+	require.NoError(t, os.Symlink(t.TempDir(), filepath.Join(client.config.WorkingDir, "out")))
+	tests := []struct {
+		params map[string]interface{}
+		want   string
+	}{
+		{map[string]interface{}{"command": "rm -rf /"}, "rm is not an allowed command"},
+		{map[string]interface{}{"command": "/bin/echo hi"}, "/bin/echo is not an allowed command"},
+		{map[string]interface{}{"command": "echo 'hi"}, "unterminated ' quote"},
+		{map[string]interface{}{"command": "  "}, "command is empty"},
+		{map[string]interface{}{"command": "ls", "dir": "../.."}, "outside the working directory"},
+		{map[string]interface{}{"command": "ls", "dir": "out"}, "outside the working directory"},
+	}
+	for _, tt := range tests {
+		text, isError := runCommand(t, client, tt.params)
+		assert.True(t, isError, tt.params["command"])
+		assert.Contains(t, text, tt.want)
+	}
+
+	client.config.RequireApproval = false
+	result, err = client.CallTool(context.Background(), "run_command", params)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", resultText(t, result))
+}
+
+func TestShellClient_LimitsTimeAndOutput(t *testing.T) {
+	client := newTestShellClient(t)
+	client.config.Timeout = 100 * time.Millisecond
+	text, isError := runCommand(t, client, map[string]interface{}{"command": "sleep 5"})
+	assert.True(t, isError)
+	assert.Contains(t, text, "sleep timed out after 100ms")
+
+	client.config.Timeout = 5 * time.Second
+	client.config.MaxOutput = 10
+	text, isError = runCommand(t, client, map[string]interface{}{"command": "echo " + strings.Repeat("x", 30)})
+	assert.False(t, isError)
+	assert.Equal(t, strings.Repeat("x", 10)+"\n[21 more bytes of output were cut]", text)
+}
+
+func TestSplitCommand(t *testing.T) {
+	words, err := splitCommand(`git commit -m "fix the \"parser\"" --author='A B' a\ b`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"git", "commit", "-m", `fix the "parser"`, "--author=A B", "a b"}, words)
+
+	words, err = splitCommand(`echo ''`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"echo", ""}, words)
+
+	_, err = splitCommand(`echo \`)
+	assert.Error(t, err)
+}
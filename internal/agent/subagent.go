@@ -0,0 +1,251 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// subAgentServerName is the name the delegate tool is registered under
+const subAgentServerName = "othello-agents"
+
+// subAgentPrompt is the system message each sub-agent starts with
+const subAgentPrompt = `You are a sub-agent working on one task for another assistant, which will only see your final answer. Use the available tools as needed, then reply with your findings: concise, factual, and self-contained. If you could not finish the task, say what you found and what is missing.`
+
+// subAgentResult is what one sub-agent found
+type subAgentResult struct {
+	Task      string
+	Answer    string
+	Rounds    int // model calls made
+	ToolCalls int
+	Tokens    int    // tokens sent and generated
+	Stopped   string // why the sub-agent stopped before answering, if it did
+	Err       error
+}
+
+// runSubAgents runs a sub-agent for each task, at most the configured number
+// at a time, and returns their results in the order of tasks
+func (a *Agent) runSubAgents(ctx context.Context, tasks []string, background string, budget int) []subAgentResult {
+	limit := a.config.Agent.SubAgents.MaxParallel
+	if limit < 1 {
+		limit = 1
+	}
+	slots := make(chan struct{}, limit)
+	results := make([]subAgentResult, len(tasks))
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(i int, task string) {
+			defer wg.Done()
+			defer a.crashes.recover("a sub-agent")
+			// Replaced by the result unless the sub-agent panics
+			results[i] = subAgentResult{Task: task, Err: errors.New("the sub-agent crashed")}
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[i] = subAgentResult{Task: task, Err: ctx.Err()}
+				return
+			}
+			results[i] = a.runSubAgent(ctx, task, background, budget)
+		}(i, task)
+	}
+	wg.Wait()
+	return results
+}
+
+// runSubAgent works on task in a context of its own: it asks the model, runs
+// the tools it calls, and asks again with their results until the model
+// answers, the configured number of rounds is used, or the token budget is
+// spent. Sub-agents can't delegate further.
+func (a *Agent) runSubAgent(ctx context.Context, task, background string, budget int) subAgentResult {
+	result := subAgentResult{Task: task}
+	definitions, err := a.GetMCPToolsAsDefinitions(ctx)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	tools := make([]model.ToolDefinition, 0, len(definitions))
+	for _, tool := range definitions {
+		if tool.Name != "delegate" {
+			tools = append(tools, tool)
+		}
+	}
+
+	prompt := task
+	if background != "" {
+		prompt = fmt.Sprintf("%s\n\nBackground:\n%s", task, background)
+	}
+	messages := []model.Message{
+		{Role: "system", Content: subAgentPrompt},
+		{Role: "user", Content: prompt},
+	}
+
+	rounds := a.config.Agent.SubAgents.MaxRounds
+	convContext := &model.ConversationContext{
+		History:     messages,
+		UserQuery:   prompt,
+		SessionType: "sub-agent",
+		Entities:    model.NewEntityStore(),
+	}
+	for result.Rounds < rounds {
+		options := a.generateOptions()
+		if remaining := budget - result.Tokens; options.MaxTokens == 0 || remaining < options.MaxTokens {
+			options.MaxTokens = remaining
+		}
+		started := time.Now()
+		response, err := a.model.ChatWithTools(ctx, messages, tools, options)
+		result.Rounds++
+		if err != nil {
+			result.Err = fmt.Errorf("model request failed: %w", err)
+			return result
+		}
+		a.publishUsage("sub-agent", response, responseLatency(response, started))
+		used := response.Usage.TotalTokens
+		if used == 0 {
+			used = response.Usage.PromptTokens + response.Usage.CompletionTokens
+		}
+		result.Tokens += used
+		result.Answer = response.Content
+
+		if len(response.ToolCalls) == 0 {
+			return result
+		}
+		if result.Tokens >= budget {
+			result.Stopped = fmt.Sprintf("token budget of %d spent", budget)
+			return result
+		}
+
+		messages = append(messages, model.Message{Role: "assistant", Content: response.Content})
+		for _, call := range response.ToolCalls {
+			result.ToolCalls++
+			messages = append(messages, model.Message{
+				Role:    "tool",
+				Content: fmt.Sprintf("Result of %s:\n%s", call.Name, a.runMCPTool(ctx, call, convContext)),
+			})
+		}
+	}
+	result.Stopped = fmt.Sprintf("limit of %d model calls reached", rounds)
+	return result
+}
+
+// describeSubAgentResults merges what the sub-agents found into one result
+// for the model that delegated to them
+func describeSubAgentResults(results []subAgentResult, budget int) string {
+	sections := make([]string, len(results))
+	for i, r := range results {
+		header := fmt.Sprintf("## Task %d: %s\n(%d model calls, %d tool calls, %d of %d tokens)", i+1, r.Task, r.Rounds, r.ToolCalls, r.Tokens, budget)
+		var body string
+		switch {
+		case r.Err != nil:
+			body = fmt.Sprintf("Failed: %v", r.Err)
+		case r.Stopped != "":
+			body = fmt.Sprintf("Stopped early (%s). Findings so far:\n%s", r.Stopped, strings.TrimSpace(r.Answer))
+		default:
+			body = strings.TrimSpace(r.Answer)
+		}
+		sections[i] = header + "\n" + body
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// subAgentClient offers sub-agents to the model as the delegate tool. Like
+// the built-in memory, it is an in-process mcp.Client.
+type subAgentClient struct {
+	agent *Agent
+}
+
+// delegateTool describes the tool subAgentClient offers
+var delegateTool = mcp.Tool{
+	Name:        "delegate",
+	Description: "Hand independent research tasks to sub-agents that work on them in parallel, each with its own context and the other tools, and return their findings. Use it to split a broad request into focused parts.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"tasks":        map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "One self-contained instruction per sub-agent, such as \"Research X\" or \"Summarize Y\""},
+			"context":      map[string]interface{}{"type": "string", "description": "Background every sub-agent needs, since they can't see this conversation"},
+			"token_budget": map[string]interface{}{"type": "integer", "description": "Most tokens each sub-agent may use; defaults to and cannot exceed the configured budget", "minimum": 1},
+		},
+		"required": []interface{}{"tasks"},
+	},
+}
+
+func (c *subAgentClient) Connect(ctx context.Context) error    { return nil }
+func (c *subAgentClient) Disconnect(ctx context.Context) error { return nil }
+func (c *subAgentClient) IsConnected() bool                    { return true }
+func (c *subAgentClient) GetTransport() string                 { return "builtin" }
+
+// ListTools implements mcp.Client
+func (c *subAgentClient) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	return []mcp.Tool{delegateTool}, nil
+}
+
+// GetInfo implements mcp.Client
+func (c *subAgentClient) GetInfo(ctx context.Context) (*mcp.ServerInfo, error) {
+	return &mcp.ServerInfo{Name: subAgentServerName, Version: "1.0.0"}, nil
+}
+
+// CallTool implements mcp.Client. Invalid requests are reported as error
+// results so the model can correct them.
+func (c *subAgentClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (*mcp.ToolResult, error) {
+	text, err := c.delegate(ctx, name, params)
+	if err != nil {
+		return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return &mcp.ToolResult{Content: []mcp.Content{{Type: "text", Text: text}}}, nil
+}
+
+// delegate checks a delegate call and runs its sub-agents
+func (c *subAgentClient) delegate(ctx context.Context, name string, params map[string]interface{}) (string, error) {
+	if name != delegateTool.Name {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	a := c.agent
+	if a.model == nil {
+		return "", fmt.Errorf("no model is available for sub-agents")
+	}
+	settings := a.config.Agent.SubAgents
+
+	var tasks []string
+	items, _ := params["tasks"].([]interface{})
+	for _, item := range items {
+		if task, ok := item.(string); ok && strings.TrimSpace(task) != "" {
+			tasks = append(tasks, strings.TrimSpace(task))
+		}
+	}
+	if len(tasks) == 0 {
+		return "", fmt.Errorf("tasks must list at least one task")
+	}
+	if len(tasks) > settings.MaxTasks {
+		return "", fmt.Errorf("at most %d tasks can be delegated at once", settings.MaxTasks)
+	}
+	budget, err := intArgument(params, "token_budget", settings.TokenBudget)
+	if err != nil {
+		return "", err
+	}
+	if budget < 1 || budget > settings.TokenBudget {
+		budget = settings.TokenBudget
+	}
+	background, _ := params["context"].(string)
+
+	a.logger.Printf("Delegating %d tasks to sub-agents with a budget of %d tokens each", len(tasks), budget)
+	results := a.runSubAgents(ctx, tasks, strings.TrimSpace(background), budget)
+	return describeSubAgentResults(results, budget), nil
+}
+
+// registerSubAgents offers the delegate tool to the model when sub-agents
+// are enabled
+func (a *Agent) registerSubAgents() {
+	if !a.config.Agent.SubAgents.Enabled {
+		return
+	}
+	if err := a.mcpRegistry.RegisterServer(subAgentServerName, &subAgentClient{agent: a}); err != nil {
+		a.logger.Printf("Warning: Failed to register the delegate tool: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// taskModel answers ChatWithTools with answer, so concurrent sub-agents can
+// be told apart by their messages
+type taskModel struct {
+	MockModel
+	answer   func(messages []model.Message, tools []model.ToolDefinition) *model.Response
+	mu       sync.Mutex
+	received [][]model.Message
+}
+
+func (m *taskModel) ChatWithTools(ctx context.Context, messages []model.Message, tools []model.ToolDefinition, options model.GenerateOptions) (*model.Response, error) {
+	m.mu.Lock()
+	m.received = append(m.received, messages)
+	m.mu.Unlock()
+	return m.answer(messages, tools), nil
+}
+
+func newTestSubAgentAgent(t *testing.T, m model.Model, settings config.SubAgentConfig) *Agent {
+	t.Helper()
+	dir := t.TempDir()
+	settings.Enabled = true
+	agent, err := New(&config.Config{
+		Model:   config.ModelConfig{Name: "test"},
+		Storage: config.StorageConfig{DataDir: dir},
+		Memory:  config.MemoryConfig{Enabled: true},
+		Agent:   config.AgentConfig{SubAgents: settings},
+		Logging: config.LoggingConfig{File: filepath.Join(dir, "test.log")},
+	})
+	require.NoError(t, err)
+	require.NoError(t, agent.openStore())
+	t.Cleanup(agent.closeStore)
+	agent.SetModel(m)
+	return agent
+}
+
+func TestDelegate_MergesSubAgentFindings(t *testing.T) {
+	m := &taskModel{answer: func(messages []model.Message, tools []model.ToolDefinition) *model.Response {
+		for _, tool := range tools {
+			if tool.Name == "delegate" {
+				return &model.Response{Content: "sub-agents must not delegate"}
+			}
+		}
+		task := strings.SplitN(messages[1].Content, "\n", 2)[0]
+		return &model.Response{Content: "Findings for " + task, Usage: model.Usage{TotalTokens: 100}}
+	}}
+	agent := newTestSubAgentAgent(t, m, config.SubAgentConfig{MaxParallel: 2, MaxTasks: 3, MaxRounds: 2, TokenBudget: 1000})
+
+	output, err := agent.ExecuteToolUnified(context.Background(), "delegate", map[string]interface{}{
+		"tasks":   []interface{}{"Research Go generics", "Summarize the release notes"},
+		"context": "The user maintains a Go CLI.",
+	}, "compare things")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Task 1: Research Go generics")
+	assert.Contains(t, output, "Findings for Research Go generics")
+	assert.Contains(t, output, "Findings for Summarize the release notes")
+	assert.Contains(t, output, "100 of 1000 tokens")
+	assert.Less(t, strings.Index(output, "Task 1"), strings.Index(output, "Task 2"), "Results keep the order of the tasks")
+
+	require.Len(t, m.received, 2)
+	for _, messages := range m.received {
+		require.Len(t, messages, 2, "Each sub-agent starts with its own context")
+		assert.Equal(t, subAgentPrompt, messages[0].Content)
+		assert.Contains(t, messages[1].Content, "Background:\nThe user maintains a Go CLI.")
+	}
+}
+
+func TestSubAgent_StopsAtTokenBudget(t *testing.T) {
+	m := &taskModel{answer: func(messages []model.Message, tools []model.ToolDefinition) *model.Response {
+		return &model.Response{
+			Content:   "Still looking",
+			ToolCalls: []model.ToolCall{{Name: "recall", Arguments: map[string]interface{}{"query": "deploys"}}},
+			Usage:     model.Usage{PromptTokens: 500, CompletionTokens: 100},
+		}
+	}}
+	agent := newTestSubAgentAgent(t, m, config.SubAgentConfig{MaxParallel: 1, MaxTasks: 1, MaxRounds: 10, TokenBudget: 1000})
+
+	result := agent.runSubAgent(context.Background(), "Find deploy facts", "", 1000)
+	assert.Equal(t, 2, result.Rounds)
+	assert.Equal(t, 1200, result.Tokens)
+	assert.Equal(t, 1, result.ToolCalls)
+	assert.Equal(t, "token budget of 1000 spent", result.Stopped)
+	assert.Contains(t, m.received[1][3].Content, "Result of recall:")
+
+	result = agent.runSubAgent(context.Background(), "Find deploy facts", "", 100000)
+	assert.Equal(t, 10, result.Rounds)
+	assert.Equal(t, "limit of 10 model calls reached", result.Stopped)
+	assert.Contains(t, describeSubAgentResults([]subAgentResult{result}, 100000), "Stopped early (limit of 10 model calls reached). Findings so far:\nStill looking")
+}
+
+func TestSubAgents_RunAtMostMaxParallel(t *testing.T) {
+	var running, peak int32
+	m := &taskModel{answer: func(messages []model.Message, tools []model.ToolDefinition) *model.Response {
+		now := atomic.AddInt32(&running, 1)
+		for {
+			seen := atomic.LoadInt32(&peak)
+			if now <= seen || atomic.CompareAndSwapInt32(&peak, seen, now) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return &model.Response{Content: "done"}
+	}}
+	agent := newTestSubAgentAgent(t, m, config.SubAgentConfig{MaxParallel: 2, MaxTasks: 5, MaxRounds: 1, TokenBudget: 1000})
+
+	results := agent.runSubAgents(context.Background(), []string{"a", "b", "c", "d", "e"}, "", 1000)
+	require.Len(t, results, 5)
+	assert.Equal(t, "e", results[4].Task)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+}
+
+func TestDelegate_RejectsBadRequests(t *testing.T) {
+	agent := newTestSubAgentAgent(t, &taskModel{}, config.SubAgentConfig{MaxParallel: 1, MaxTasks: 2, MaxRounds: 1, TokenBudget: 1000})
+	client := &subAgentClient{agent: agent}
+
+	for _, tc := range []struct {
+		params  map[string]interface{}
+		message string
+	}{
+		{map[string]interface{}{"tasks": []interface{}{" "}}, "tasks must list at least one task"},
+		{map[string]interface{}{"tasks": []interface{}{"a", "b", "c"}}, "at most 2 tasks can be delegated at once"},
+		{map[string]interface{}{"tasks": []interface{}{"a"}, "token_budget": "lots"}, "token_budget must be a whole number"},
+	} {
+		result, err := client.CallTool(context.Background(), "delegate", tc.params)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Equal(t, tc.message, result.Content[0].Text)
+	}
+}
@@ -74,15 +74,11 @@ func (spg *SystemPromptGenerator) filterRelevantTools(allTools []ToolMetadata, c
 	}
 
 	// Filter by session type
-	switch context.SessionType {
-	case "analysis":
-		return spg.filterByCapabilities(allTools, []ToolCapability{CapabilityAnalyze, CapabilitySearch})
-	case "automation":
-		return spg.filterByCapabilities(allTools, []ToolCapability{CapabilityCreate, CapabilityUpdate, CapabilityTransform})
-	default:
-		// For general chat, include all tools but prioritize simpler ones
-		return spg.prioritizeSimpleTools(allTools)
+	if capabilities, ok := sessionCapabilities[context.SessionType]; ok {
+		return spg.filterByCapabilities(allTools, capabilities)
 	}
+	// For general chat, include all tools but prioritize simpler ones
+	return spg.prioritizeSimpleTools(allTools)
 }
 
 // filterByQueryRelevance filters tools based on query keywords and intent
@@ -206,14 +202,7 @@ func (spg *SystemPromptGenerator) getTopSimpleTools(tools []ToolMetadata, n int)
 func (spg *SystemPromptGenerator) generateHeaderSection(context PromptContext) string {
 	header := `You are an intelligent AI assistant with access to powerful tools that extend your capabilities. `
 
-	switch context.SessionType {
-	case "analysis":
-		header += `You excel at analyzing data and providing insights. `
-	case "automation":
-		header += `You focus on automating tasks and managing data efficiently. `
-	default:
-		header += `You help users accomplish their goals efficiently and accurately. `
-	}
+	header += sessionFocus(context.SessionType) + " "
 
 	header += `
 
@@ -452,10 +441,8 @@ IMPORTANT REMINDERS:
 
 If you don't need a tool for a query, respond normally with helpful information.`
 
-	if context.SessionType == "analysis" {
-		footer += "\n- **Focus on data-driven insights** and use analysis tools when appropriate"
-	} else if context.SessionType == "automation" {
-		footer += "\n- **Emphasize efficiency** and suggest automation opportunities"
+	if reminder := sessionReminders[context.SessionType]; reminder != "" {
+		footer += "\n- " + reminder
 	}
 
 	return footer
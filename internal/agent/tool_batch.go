@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/tui"
+)
+
+// ExecuteToolCallsWithContext runs the tool calls the model made in one turn
+// and returns what each produced, in the order of calls. The tools run one
+// after another, since a call may depend on what an earlier one changed, but
+// each result is processed and summarized as soon as its tool finishes, at
+// most agent.process_parallel at a time, while the next tools run. Once all
+// are processed, convContext is updated in the order of calls, as if they
+// were made one at a time.
+func (a *Agent) ExecuteToolCallsWithContext(ctx context.Context, calls []model.ToolCall, convContext *model.ConversationContext) []tui.ToolCallOutcome {
+	limit := a.config.Agent.ProcessParallel
+	if limit < 1 {
+		limit = 1
+	}
+	slots := make(chan struct{}, limit)
+	outcomes := make([]tui.ToolCallOutcome, len(calls))
+	runs := make([]*toolRun, len(calls))
+	repeated := make([]bool, len(calls))
+	ran := make(map[string]bool) // Calls of this turn that ran, which a repeat waits for
+	var previous []string
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		// A repeat of a call still being processed is answered once it's done
+		if a.config.Agent.DedupeCalls && ran[callKey(call.Name, call.Arguments)] {
+			repeated[i] = true
+			continue
+		}
+		started := time.Now()
+		run, err := a.runToolWithRetries(ctx, call.Name, call.Arguments, convContext)
+		if err != nil {
+			outcomes[i] = tui.ToolCallOutcome{Err: err, Duration: time.Since(started)}
+			continue
+		}
+		runs[i] = run
+		if run.done {
+			outcomes[i] = tui.ToolCallOutcome{Output: run.output, Duration: time.Since(started)}
+			continue
+		}
+		ran[callKey(run.name, run.key)] = true
+
+		// Processing reads a copy of the context, which sees the calls made
+		// before this one and keeps the follow-ups and full result it sets
+		local := *convContext
+		local.PreviousTools = append(slices.Clip(convContext.PreviousTools), previous...)
+		previous = append(previous, run.name)
+		wg.Add(1)
+		go func(i int, run *toolRun) {
+			defer wg.Done()
+			defer a.crashes.recover("processing a tool result")
+			// Replaced by the processed result unless processing panics
+			outcomes[i] = tui.ToolCallOutcome{Output: a.guardResult(run.name, unprocessedResult(run.result))}
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			outcomes[i].Output = a.processToolRun(ctx, run, &local)
+			outcomes[i].FollowUps, outcomes[i].FullResult = local.FollowUps, local.FullResult
+			outcomes[i].Duration = time.Since(started)
+		}(i, run)
+	}
+	wg.Wait()
+
+	for i, call := range calls {
+		switch {
+		case repeated[i]:
+			outcomes[i].Output, _ = a.repeatedCall(turnResults(convContext), call.Name, call.Arguments)
+		case runs[i] != nil && !runs[i].done:
+			outcomes[i].Output = a.finishToolCall(ctx, runs[i], outcomes[i].Output, convContext)
+			convContext.FollowUps, convContext.FullResult = outcomes[i].FollowUps, outcomes[i].FullResult
+		}
+	}
+	return outcomes
+}
@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// ToolCallOutput is what a tool called outside a chat returned
+type ToolCallOutput struct {
+	Tool      string          `json:"tool"`
+	Server    string          `json:"server"`
+	Result    *mcp.ToolResult `json:"result"`
+	Raw       string          `json:"raw"`       // The text of the result as the tool returned it
+	Processed string          `json:"processed"` // The result as it is shown in chat
+	Duration  string          `json:"duration,omitempty"`
+}
+
+// OpenTools makes the built-in tools, such as the memory tools, available
+// outside a chat by opening storage, as `othello tools` needs. The returned
+// function closes it.
+func (a *Agent) OpenTools() func() {
+	return a.startHeadless()
+}
+
+// Tools returns the tools of the connected servers, sorted by server and then
+// by name. The built-in tools are included while OpenTools is in effect.
+func (a *Agent) Tools() []mcp.Tool {
+	tools := a.mcpRegistry.ListTools()
+	sort.Slice(tools, func(i, j int) bool {
+		if tools[i].ServerName != tools[j].ServerName {
+			return tools[i].ServerName < tools[j].ServerName
+		}
+		return tools[i].Name < tools[j].Name
+	})
+	return tools
+}
+
+// Tool returns the tool name, or false when no connected server has it
+func (a *Agent) Tool(name string) (mcp.Tool, bool) {
+	return a.mcpRegistry.GetTool(name)
+}
+
+// CallTool runs the tool name with arguments outside a chat, as `othello
+// tools call` does. The arguments are checked against the tool's schema
+// first, and the call is recorded in the audit log when storage is open. A
+// tool that runs but reports an error is returned as an error.
+func (a *Agent) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*ToolCallOutput, error) {
+	tool, ok := a.mcpRegistry.GetTool(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", mcp.ErrToolNotFound, name)
+	}
+	if err := ValidateToolCall(model.ToolCall{Name: name, Arguments: arguments}, tool); err != nil {
+		return nil, fmt.Errorf("%w: %w", mcp.ErrInvalidArguments, err)
+	}
+	started := time.Now()
+	result, err := a.toolExecutor.Execute(ctx, name, arguments)
+	if err != nil {
+		return nil, err
+	}
+	if result.Result == nil {
+		result.Result = &mcp.ToolResult{}
+	}
+	if result.Result.IsError {
+		return nil, fmt.Errorf("%s failed: %s", name, toolResultText(result.Result))
+	}
+
+	output := &ToolCallOutput{
+		Tool:     name,
+		Server:   tool.ServerName,
+		Result:   result.Result,
+		Raw:      toolResultText(result.Result),
+		Duration: time.Since(started).Round(time.Millisecond).String(),
+	}
+	if output.Processed, err = a.ProcessToolResult(ctx, name, result, ""); err != nil {
+		a.logger.Printf("Warning: Failed to process result for %s: %v", name, err)
+		output.Processed = output.Raw
+	}
+	return output, nil
+}
+
+// ToolArgument converts value, typed on the command line, to the type the
+// tool's schema gives the parameter name. Values of parameters without a
+// type are read as JSON when they can be, and kept as text otherwise.
+func ToolArgument(tool mcp.Tool, name, value string) (interface{}, error) {
+	var paramType string
+	if properties, ok := tool.InputSchema["properties"].(map[string]interface{}); ok {
+		if property, ok := properties[name].(map[string]interface{}); ok {
+			paramType, _ = property["type"].(string)
+		}
+	}
+
+	switch paramType {
+	case "string":
+		return value, nil
+	case "integer":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a whole number, got %q", name, value)
+		}
+		return n, nil
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, got %q", name, value)
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got %q", name, value)
+		}
+		return b, nil
+	case "array", "object":
+		var v interface{}
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("%s must be a JSON %s: %w", name, paramType, err)
+		}
+		return v, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err == nil {
+		return v, nil
+	}
+	return value, nil
+}
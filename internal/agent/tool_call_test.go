@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_CallTool(t *testing.T) {
+	agent, _ := newTestAskAgent(t, &failingModel{})
+	assert.Empty(t, agent.Tools(), "Built-in tools need storage")
+	closeTools := agent.OpenTools()
+
+	var names []string
+	for _, tool := range agent.Tools() {
+		names = append(names, tool.ServerName+"/"+tool.Name)
+	}
+	assert.Subset(t, names, []string{memoryServerName + "/forget", memoryServerName + "/recall", memoryServerName + "/remember"})
+
+	output, err := agent.CallTool(context.Background(), "remember", map[string]interface{}{"content": "Backups run at 2am", "importance": 8})
+	require.NoError(t, err)
+	assert.Equal(t, memoryServerName, output.Server)
+	assert.Contains(t, output.Raw, "Remembered")
+	assert.NotEmpty(t, output.Processed)
+
+	_, err = agent.CallTool(context.Background(), "remember", map[string]interface{}{})
+	assert.ErrorIs(t, err, mcp.ErrInvalidArguments)
+	_, err = agent.CallTool(context.Background(), "launch", nil)
+	assert.ErrorIs(t, err, mcp.ErrToolNotFound)
+
+	closeTools()
+	assert.Nil(t, agent.store)
+	assert.Empty(t, agent.Tools())
+}
+
+func TestToolArgument_FollowsSchema(t *testing.T) {
+	tool := memoryTools[1]
+	require.Equal(t, "recall", tool.Name)
+
+	value, err := ToolArgument(tool, "limit", "3")
+	require.NoError(t, err)
+	assert.Equal(t, 3, value)
+	value, err = ToolArgument(tool, "query", "42")
+	require.NoError(t, err)
+	assert.Equal(t, "42", value, "A string parameter keeps the text")
+	_, err = ToolArgument(tool, "limit", "many")
+	assert.EqualError(t, err, `limit must be a whole number, got "many"`)
+
+	value, err = ToolArgument(tool, "tags", `["a", "b"]`)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, value, "Untyped values are read as JSON")
+	value, err = ToolArgument(tool, "note", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", value)
+}
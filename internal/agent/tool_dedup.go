@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// repeatedCallNote follows the earlier result of a call the model made again.
+// Small models often call the same tool over and over instead of answering;
+// this tells them they already have what they asked for.
+const repeatedCallNote = "(%s was already called with these arguments for this request, so this is the earlier result. Answer with it instead of calling %s again.)"
+
+// callKey identifies a tool call by its tool and arguments. encoding/json
+// writes map keys in order, so equal arguments give the same key.
+func callKey(name string, arguments map[string]interface{}) string {
+	encoded, err := json.Marshal(arguments)
+	if err != nil {
+		encoded = []byte(fmt.Sprintf("%v", arguments))
+	}
+	return name + " " + string(encoded)
+}
+
+// repeatedCall returns the result of an identical call made earlier in the
+// request, with a note for the model, when there was one and repeated calls
+// are deduplicated
+func (a *Agent) repeatedCall(results map[string]string, name string, arguments map[string]interface{}) (string, bool) {
+	if !a.config.Agent.DedupeCalls {
+		return "", false
+	}
+	output, ok := results[callKey(name, arguments)]
+	if !ok {
+		return "", false
+	}
+	a.logger.Printf("Repeated call to %s answered with its earlier result", name)
+	return output + "\n\n" + fmt.Sprintf(repeatedCallNote, name, name), true
+}
+
+// rememberCall records the result of a successful call for repeatedCall
+func rememberCall(results map[string]string, name string, arguments map[string]interface{}, output string) {
+	results[callKey(name, arguments)] = output
+}
+
+// turnResults returns the tool results recorded for convContext's request
+func turnResults(convContext *model.ConversationContext) map[string]string {
+	if convContext.TurnResults == nil {
+		convContext.TurnResults = make(map[string]string)
+	}
+	return convContext.TurnResults
+}
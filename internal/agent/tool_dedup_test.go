@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_RepeatedToolCallsReuseResults(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	agent.config.Agent.DedupeCalls = true
+	// A second run of the same call would be refused, so only a reused
+	// result can succeed
+	agent.config.Agent.ToolPolicies = []config.ToolPolicyConfig{{Tool: "remember", MaxCallsPerTurn: 1}}
+	ctx := context.Background()
+	convContext := &model.ConversationContext{}
+	params := map[string]interface{}{"content": "Deploys are on Tuesdays", "topic": "ops"}
+
+	first, err := agent.ExecuteToolUnifiedWithContext(ctx, "remember", params, convContext)
+	require.NoError(t, err)
+	again, err := agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"topic": "ops", "content": "Deploys are on Tuesdays"}, convContext)
+	require.NoError(t, err)
+	assert.Equal(t, first+"\n\n(remember was already called with these arguments for this request, so this is the earlier result. Answer with it instead of calling remember again.)", again)
+	assert.Equal(t, 1, convContext.TurnCalls["remember"])
+
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "Backups run at 2am"}, convContext)
+	assert.Error(t, err, "Calls with other arguments aren't reused, so the policy refuses them")
+
+	agent.config.Agent.DedupeCalls = false
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "remember", params, convContext)
+	assert.Error(t, err, "Without deduplication the call runs again")
+}
+
+func TestAgent_RunMCPToolReusesResults(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	agent.config.Agent.DedupeCalls = true
+	convContext := &model.ConversationContext{}
+	call := model.ToolCall{Name: "recall", Arguments: map[string]interface{}{"query": "deploys"}}
+
+	first := agent.runMCPTool(context.Background(), call, convContext)
+	assert.NotContains(t, first, "already called")
+	assert.Contains(t, agent.runMCPTool(context.Background(), call, convContext), "recall was already called with these arguments")
+	assert.NotContains(t, agent.runMCPTool(context.Background(), call, &model.ConversationContext{}), "already called", "Each loop has its own results")
+
+	failed := model.ToolCall{Name: "recall", Arguments: map[string]interface{}{"topic": "deploys"}}
+	agent.runMCPTool(context.Background(), failed, convContext)
+	assert.NotContains(t, agent.runMCPTool(context.Background(), failed, convContext), "already called", "Failed calls aren't reused")
+}
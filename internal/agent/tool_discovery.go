@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
 )
@@ -35,7 +36,9 @@ type ToolMetadata struct {
 // ToolDiscovery manages dynamic tool discovery and categorization
 type ToolDiscovery struct {
 	registry *mcp.ToolRegistry
-	cache    map[string][]ToolMetadata
+	mu       sync.Mutex
+	cached   []ToolMetadata // Categorized tools of the registry snapshot at version; nil when invalidated
+	version  uint64
 	logger   mcp.Logger
 }
 
@@ -43,21 +46,24 @@ type ToolDiscovery struct {
 func NewToolDiscovery(registry *mcp.ToolRegistry, logger mcp.Logger) *ToolDiscovery {
 	return &ToolDiscovery{
 		registry: registry,
-		cache:    make(map[string][]ToolMetadata),
 		logger:   logger,
 	}
 }
 
 // DiscoverAllTools discovers and categorizes tools from all registered servers
+// The result is cached until the registry's tools change.
 func (td *ToolDiscovery) DiscoverAllTools(ctx context.Context) ([]ToolMetadata, error) {
+	snapshot := td.registry.Snapshot()
+	td.mu.Lock()
+	defer td.mu.Unlock()
+
 	// Check cache first
-	cacheKey := "all_tools"
-	if cached, exists := td.cache[cacheKey]; exists {
-		return cached, nil
+	if td.cached != nil && td.version == snapshot.Version {
+		return td.cached, nil
 	}
 
 	// Get all tools from registry
-	tools := td.registry.ListTools()
+	tools := snapshot.Tools()
 	metadata := make([]ToolMetadata, len(tools))
 
 	for i, tool := range tools {
@@ -73,9 +79,9 @@ func (td *ToolDiscovery) DiscoverAllTools(ctx context.Context) ([]ToolMetadata,
 	})
 
 	// Cache the results
-	td.cache[cacheKey] = metadata
+	td.cached, td.version = metadata, snapshot.Version
 	td.logger.Info("Discovered and categorized %d tools from %d servers",
-		len(metadata), td.registry.GetServerCount())
+		len(metadata), snapshot.ServerCount())
 
 	return metadata, nil
 }
@@ -124,6 +130,12 @@ func (td *ToolDiscovery) analyzeToolMetadata(tool mcp.Tool) ToolMetadata {
 
 // categorizeToolCapability determines the primary capability of a tool
 func (td *ToolDiscovery) categorizeToolCapability(tool mcp.Tool) ToolCapability {
+	return toolCapability(tool)
+}
+
+// toolCapability determines the primary capability of a tool from the words
+// in its name and description
+func toolCapability(tool mcp.Tool) ToolCapability {
 	name := strings.ToLower(tool.Name)
 	description := strings.ToLower(tool.Description)
 	combined := name + " " + description
@@ -310,6 +322,8 @@ func GetCapabilityName(capability ToolCapability) string {
 
 // InvalidateCache clears the tool discovery cache
 func (td *ToolDiscovery) InvalidateCache() {
-	td.cache = make(map[string][]ToolMetadata)
+	td.mu.Lock()
+	td.cached = nil
+	td.mu.Unlock()
 	td.logger.Info("Tool discovery cache invalidated")
 }
\ No newline at end of file
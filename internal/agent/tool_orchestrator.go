@@ -43,6 +43,7 @@ type OrchestrationStep struct {
 	Dependencies []string // Names of tools that must complete before this step
 	Optional     bool     // Whether this step can be skipped if it fails
 	Reasoning    string   // Why this step is needed
+	Destructive  bool     // Whether the tool deletes or changes data
 }
 
 // ToolOrchestrator manages complex multi-tool operations
@@ -332,7 +333,7 @@ func (to *ToolOrchestrator) executePlan(ctx context.Context, plan *Orchestration
 		}
 
 		// Execute the step
-		stepResult := to.executeStep(ctx, step)
+		stepResult := to.ExecuteStep(ctx, step)
 		result.ToolResults = append(result.ToolResults, stepResult)
 
 		if stepResult.Success {
@@ -381,8 +382,9 @@ func (to *ToolOrchestrator) checkDependencies(dependencies []string, completed m
 	return true
 }
 
-// executeStep executes a single orchestration step
-func (to *ToolOrchestrator) executeStep(ctx context.Context, step OrchestrationStep) ToolExecutionResult {
+// ExecuteStep executes a single orchestration step. A tool that reports an
+// error in its result fails the step.
+func (to *ToolOrchestrator) ExecuteStep(ctx context.Context, step OrchestrationStep) ToolExecutionResult {
 	startTime := time.Now()
 
 	// Execute the tool
@@ -401,6 +403,15 @@ func (to *ToolOrchestrator) executeStep(ctx context.Context, step OrchestrationS
 
 	// Format the result
 	formattedResult := to.executor.FormatResult(executeResult)
+	if executeResult.Result != nil && executeResult.Result.IsError {
+		return ToolExecutionResult{
+			ToolName:   step.ToolName,
+			Success:    false,
+			Error:      formattedResult,
+			Duration:   duration,
+			Parameters: step.Parameters,
+		}
+	}
 
 	return ToolExecutionResult{
 		ToolName:   step.ToolName,
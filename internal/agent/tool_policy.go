@@ -0,0 +1,193 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// PolicyDenial is a tool call refused by one of agent.tool_policies. The
+// call never reaches the tool.
+type PolicyDenial struct {
+	Tool   string
+	Reason string
+}
+
+func (e *PolicyDenial) Error() string {
+	return fmt.Sprintf("tool policy denied %s: %s", e.Tool, e.Reason)
+}
+
+// policyApplies reports whether policy covers toolName
+func policyApplies(policy config.ToolPolicyConfig, toolName string) bool {
+	return toolMatches(policy.Tool, toolName)
+}
+
+// toolMatches reports whether the glob pattern matches toolName: its full
+// name or, for MCP tools, the name without the mcp__server__ prefix
+func toolMatches(pattern, toolName string) bool {
+	for _, name := range []string{toolName, baseToolName(toolName)} {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// baseToolName strips the mcp__server__ prefix from an MCP tool name
+func baseToolName(toolName string) string {
+	if parts := strings.Split(toolName, "__"); len(parts) >= 3 && parts[0] == "mcp" {
+		return strings.Join(parts[2:], "__")
+	}
+	return toolName
+}
+
+// checkToolPolicies refuses a call to toolName that breaks one of
+// agent.tool_policies, or that the trust given to its server doesn't allow:
+// calls to a server trusted only when asked must have been approved in ctx.
+// turnCalls counts the calls to each tool in the current request; an allowed
+// call is added to it. A nil turnCalls leaves call limits unchecked.
+func (a *Agent) checkToolPolicies(ctx context.Context, toolName string, params map[string]interface{}, turnCalls map[string]int) error {
+	switch server, level := a.toolTrust(toolName); {
+	case level == storage.TrustBlocked:
+		return &PolicyDenial{Tool: toolName, Reason: fmt.Sprintf("the %s server is blocked", server)}
+	case level == storage.TrustReadOnly && isDestructiveTool(baseToolName(toolName)):
+		return &PolicyDenial{Tool: toolName, Reason: fmt.Sprintf("the %s server is only trusted with tools that read, and %s deletes or changes data", server, baseToolName(toolName))}
+	case level == storage.TrustAsk && !model.CallApproved(ctx, toolName, params):
+		return &PolicyDenial{Tool: toolName, Reason: fmt.Sprintf("the %s server is only trusted with calls the user approves", server)}
+	}
+	for _, policy := range a.config.Agent.ToolPolicies {
+		if !policyApplies(policy, toolName) {
+			continue
+		}
+		if policy.MaxCallsPerTurn > 0 && turnCalls != nil {
+			calls := 0
+			for tool, n := range turnCalls {
+				if policyApplies(policy, tool) {
+					calls += n
+				}
+			}
+			if calls >= policy.MaxCallsPerTurn {
+				return &PolicyDenial{Tool: toolName, Reason: fmt.Sprintf("%s may be called at most %d times per request", policy.Tool, policy.MaxCallsPerTurn)}
+			}
+		}
+	}
+	if err := a.checkArgumentPolicies(toolName, params); err != nil {
+		return err
+	}
+	if turnCalls != nil {
+		turnCalls[toolName]++
+	}
+	return nil
+}
+
+// checkArgumentPolicies refuses a call to toolName whose params break the
+// argument rules of agent.tool_policies
+func (a *Agent) checkArgumentPolicies(toolName string, params map[string]interface{}) error {
+	for _, policy := range a.config.Agent.ToolPolicies {
+		if !policyApplies(policy, toolName) {
+			continue
+		}
+		for argument, rule := range policy.Arguments {
+			for name, value := range params {
+				if !strings.EqualFold(name, argument) {
+					continue
+				}
+				if reason := checkArgument(name, value, rule); reason != "" {
+					return &PolicyDenial{Tool: toolName, Reason: reason}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkArgument returns why value breaks rule, or "" when it doesn't. Each
+// item of a list is checked on its own.
+func checkArgument(name string, value interface{}, rule config.ArgumentPolicyConfig) string {
+	var values []string
+	if items, ok := value.([]interface{}); ok {
+		for _, item := range items {
+			values = append(values, fmt.Sprint(item))
+		}
+	} else {
+		values = []string{fmt.Sprint(value)}
+	}
+
+	for _, v := range values {
+		for _, banned := range rule.Banned {
+			if strings.EqualFold(v, banned) {
+				return fmt.Sprintf("%s may not be %q", name, v)
+			}
+		}
+		if rule.Pattern != "" {
+			// Patterns are checked when the config is loaded
+			if re, err := policyPattern(rule.Pattern); err == nil && !re.MatchString(v) {
+				return fmt.Sprintf("%s %q doesn't match %s", name, v, rule.Pattern)
+			}
+		}
+		if len(rule.Paths) > 0 && !insideAny(v, rule.Paths) {
+			return fmt.Sprintf("%s %q is outside %s", name, v, strings.Join(rule.Paths, ", "))
+		}
+	}
+	return ""
+}
+
+// policyPatterns keeps the compiled patterns of agent.tool_policies, so
+// they aren't compiled again for every call
+var policyPatterns sync.Map // pattern -> *regexp.Regexp
+
+// policyPattern returns pattern compiled, compiling it on first use
+func policyPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := policyPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	policyPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// insideAny reports whether file is one of dirs or inside one of them, once
+// both are made absolute and "~" is expanded
+func insideAny(file string, dirs []string) bool {
+	file = absolutePath(file)
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(absolutePath(dir), file)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// absolutePath cleans p, expanding a leading "~" to the home directory
+func absolutePath(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[1:])
+		}
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return filepath.Clean(p)
+}
+
+// turnCalls returns the tool calls counted for convContext's request
+func turnCalls(convContext *model.ConversationContext) map[string]int {
+	if convContext.TurnCalls == nil {
+		convContext.TurnCalls = make(map[string]int)
+	}
+	return convContext.TurnCalls
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_ToolPoliciesRefuseCalls(t *testing.T) {
+	m := &scriptedModel{}
+	agent := newTestRetryAgent(t, m, 2)
+	agent.config.Agent.ToolPolicies = []config.ToolPolicyConfig{
+		{Tool: "*", MaxCallsPerTurn: 3},
+		{Tool: "rem*", MaxCallsPerTurn: 1, Arguments: map[string]config.ArgumentPolicyConfig{
+			"Topic": {Pattern: "^[a-z]+$", Banned: []string{"secrets"}},
+		}},
+	}
+	ctx := context.Background()
+	convContext := &model.ConversationContext{}
+
+	_, err := agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "My password is hunter2", "topic": "Secrets"}, convContext)
+	var denial *PolicyDenial
+	require.ErrorAs(t, err, &denial)
+	assert.Equal(t, `tool policy denied remember: topic may not be "Secrets"`, err.Error())
+	assert.Empty(t, m.received, "Refused calls aren't sent back to the model to correct")
+
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "Deploys are on Tuesdays", "topic": "deploy days"}, convContext)
+	assert.EqualError(t, err, `tool policy denied remember: topic "deploy days" doesn't match ^[a-z]+$`)
+
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "Deploys are on Tuesdays", "topic": "deploys"}, convContext)
+	require.NoError(t, err)
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "Backups run at 2am"}, convContext)
+	assert.EqualError(t, err, "tool policy denied remember: rem* may be called at most 1 times per request")
+
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "deploys"}, convContext)
+	require.NoError(t, err)
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "backups"}, convContext)
+	require.NoError(t, err)
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "lunch"}, convContext)
+	assert.EqualError(t, err, "tool policy denied recall: * may be called at most 3 times per request")
+
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "lunch"}, &model.ConversationContext{})
+	assert.NoError(t, err, "A new request starts counting again")
+}
+
+func TestCheckArgument_Paths(t *testing.T) {
+	dir := t.TempDir()
+	rule := config.ArgumentPolicyConfig{Paths: []string{dir}}
+
+	assert.Empty(t, checkArgument("path", filepath.Join(dir, "notes.md"), rule))
+	assert.Empty(t, checkArgument("path", dir, rule))
+	assert.Empty(t, checkArgument("paths", []interface{}{filepath.Join(dir, "a"), filepath.Join(dir, "b")}, rule))
+
+	outside := filepath.Join(dir, "..", "other", "notes.md")
+	assert.Equal(t, `path "`+outside+`" is outside `+dir, checkArgument("path", outside, rule))
+	assert.NotEmpty(t, checkArgument("path", dir+"-sibling", rule), "A directory sharing the prefix is outside")
+	assert.NotEmpty(t, checkArgument("paths", []interface{}{filepath.Join(dir, "a"), "/etc/passwd"}, rule), "Every item of a list is checked")
+}
+
+func TestPolicyApplies(t *testing.T) {
+	policy := config.ToolPolicyConfig{Tool: "write_*"}
+	assert.True(t, policyApplies(policy, "write_file"))
+	assert.True(t, policyApplies(policy, "mcp__filesystem__write_file"), "MCP tools match without their prefix")
+	assert.False(t, policyApplies(policy, "read_file"))
+	assert.True(t, policyApplies(config.ToolPolicyConfig{Tool: "mcp__filesystem__*"}, "mcp__filesystem__read_file"))
+}
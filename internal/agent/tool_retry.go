@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// toolCallError is a failed tool call the model may fix by calling the tool
+// again with other arguments: the arguments didn't match the tool's schema,
+// or the tool reported an error
+type toolCallError struct {
+	err error
+}
+
+func (e *toolCallError) Error() string { return e.err.Error() }
+func (e *toolCallError) Unwrap() error { return e.err }
+
+// toolRetryPrompt asks the model to correct a failed call. It is filled in
+// with the tool's name, the error, the arguments sent, and the tool's schema.
+const toolRetryPrompt = `Your call to the %[1]s tool failed.
+
+Error: %[2]s
+
+Arguments you sent:
+%[3]s
+
+Parameters %[1]s accepts (JSON schema):
+%[4]s
+
+Call %[1]s again with corrected arguments. If it can't do what was asked, reply without calling it.`
+
+// toolResultText returns the text and data content of a tool result
+func toolResultText(result *mcp.ToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if content.Text != "" {
+			parts = append(parts, content.Text)
+		} else if content.Data != "" {
+			parts = append(parts, content.Data)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// correctToolCall shows the model why its call to toolName failed, along
+// with the tool's schema, and returns the arguments of the call it makes
+// instead
+func (a *Agent) correctToolCall(ctx context.Context, toolName string, params map[string]interface{}, failure error, convContext *model.ConversationContext) (map[string]interface{}, error) {
+	tool, ok := a.mcpRegistry.GetTool(toolName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", mcp.ErrToolNotFound, toolName)
+	}
+	sent, _ := json.MarshalIndent(params, "", "  ")
+	schema, _ := json.MarshalIndent(tool.InputSchema, "", "  ")
+
+	messages := append(append([]model.Message{}, convContext.History...), model.Message{
+		Role:    "user",
+		Content: fmt.Sprintf(toolRetryPrompt, toolName, failure, sent, schema),
+	})
+	definition := model.ToolDefinition{Name: tool.Name, Description: tool.Description, Parameters: tool.InputSchema}
+	response, err := a.model.ChatWithTools(ctx, messages, []model.ToolDefinition{definition}, a.generateOptions())
+	if err != nil {
+		return nil, fmt.Errorf("model request failed: %w", err)
+	}
+
+	for _, call := range response.ToolCalls {
+		if call.Name != toolName {
+			continue
+		}
+		if reflect.DeepEqual(call.Arguments, params) {
+			return nil, fmt.Errorf("the model sent the same arguments again")
+		}
+		return call.Arguments, nil
+	}
+	return nil, fmt.Errorf("the model didn't call %s again", toolName)
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRetryAgent(t *testing.T, m model.Model, retries int) *Agent {
+	t.Helper()
+	agent, _ := newTestAskAgent(t, m)
+	require.NoError(t, agent.openStore())
+	t.Cleanup(agent.closeStore)
+	agent.config.Agent.ToolRetries = retries
+	return agent
+}
+
+func TestAgent_ModelCorrectsRejectedToolCall(t *testing.T) {
+	m := &scriptedModel{responses: []*model.Response{{
+		ToolCalls: []model.ToolCall{{Name: "remember", Arguments: map[string]interface{}{"content": "Backups run at 2am"}}},
+	}}}
+	agent := newTestRetryAgent(t, m, 2)
+	history := []model.Message{{Role: "user", Content: "Remember that backups run at 2am"}}
+
+	output, err := agent.ExecuteToolUnifiedWithContext(context.Background(), "remember", map[string]interface{}{"text": "Backups run at 2am"}, &model.ConversationContext{History: history})
+	require.NoError(t, err)
+	assert.Contains(t, output, "Remembered")
+
+	require.Len(t, m.received, 1)
+	retry := m.received[0]
+	require.Len(t, retry, 2, "The retry keeps the conversation")
+	assert.Contains(t, retry[1].Content, "Your call to the remember tool failed.")
+	assert.Contains(t, retry[1].Content, "Error: invalid parameters: missing required parameter: content")
+	assert.Contains(t, retry[1].Content, `"text": "Backups run at 2am"`)
+	assert.Contains(t, retry[1].Content, `"required": [`+"\n"+`    "content"`)
+}
+
+func TestAgent_ToolRetriesAreCapped(t *testing.T) {
+	attempt := 0
+	m := &taskModel{answer: func(messages []model.Message, tools []model.ToolDefinition) *model.Response {
+		attempt++
+		return &model.Response{ToolCalls: []model.ToolCall{{Name: "recall", Arguments: map[string]interface{}{"topic": fmt.Sprint(attempt)}}}}
+	}}
+	agent := newTestRetryAgent(t, m, 2)
+
+	_, err := agent.ExecuteToolUnifiedWithContext(context.Background(), "recall", map[string]interface{}{"topic": "deploys"}, &model.ConversationContext{})
+	var rejected *toolCallError
+	require.ErrorAs(t, err, &rejected)
+	assert.Equal(t, "invalid parameters: missing required parameter: query", err.Error())
+	assert.ErrorIs(t, err, mcp.ErrInvalidArguments)
+	assert.Len(t, m.received, 2)
+	for _, messages := range m.received {
+		assert.Len(t, messages, 1)
+	}
+}
+
+func TestAgent_ToolErrorsAreRetriedOnlyForTheModel(t *testing.T) {
+	m := &scriptedModel{responses: []*model.Response{
+		{ToolCalls: []model.ToolCall{{Name: "remember", Arguments: map[string]interface{}{"content": "   "}}}},
+	}}
+	agent := newTestRetryAgent(t, m, 1)
+
+	_, err := agent.ExecuteToolUnified(context.Background(), "remember", map[string]interface{}{"content": ""}, "Manual tool execution")
+	var rejected *toolCallError
+	require.True(t, errors.As(err, &rejected), "Errors the tool reports fail the call: %v", err)
+	assert.Empty(t, m.received, "Manual calls aren't sent to the model")
+
+	_, err = agent.ExecuteToolUnifiedWithContext(context.Background(), "remember", map[string]interface{}{"content": ""}, &model.ConversationContext{})
+	require.Error(t, err)
+	assert.Len(t, m.received, 1)
+
+	m.responses = []*model.Response{{Content: "I can't remember an empty fact."}}
+	_, err = agent.ExecuteToolUnifiedWithContext(context.Background(), "remember", map[string]interface{}{"content": ""}, &model.ConversationContext{})
+	require.Error(t, err, "A model that stops calling the tool ends the retries")
+	assert.Len(t, m.received, 2)
+}
@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// toolSchemaFile is where the tools each MCP server offered are kept, in the
+// data directory
+const toolSchemaFile = "tool_schemas.json"
+
+// toolSchemaCache keeps the tools each MCP server offered on disk, so the
+// TUI can offer them before the servers finish starting. A server's tools
+// are dropped when its config changes.
+type toolSchemaCache struct {
+	path    string
+	mu      sync.Mutex
+	servers map[string]cachedServerTools
+}
+
+// cachedServerTools are the tools a server offered with the config it had
+type cachedServerTools struct {
+	Fingerprint string     `json:"fingerprint"`
+	Tools       []mcp.Tool `json:"tools"`
+}
+
+// openToolSchemaCache reads the cache in the configured data directory. A
+// cache that can't be read is started over, and the error says why.
+func openToolSchemaCache(cfg *config.Config) (*toolSchemaCache, error) {
+	path, err := storage.DatabasePath(cfg.Storage.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	c := &toolSchemaCache{
+		path:    filepath.Join(filepath.Dir(path), toolSchemaFile),
+		servers: make(map[string]cachedServerTools),
+	}
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &c.servers)
+	}
+	if err != nil {
+		c.servers = make(map[string]cachedServerTools)
+		return c, fmt.Errorf("failed to read tool cache: %w", err)
+	}
+	return c, nil
+}
+
+// Tools returns the tools the server offered last time, or nil when they
+// aren't cached, its config changed since, or the cache is nil
+func (c *toolSchemaCache) Tools(server config.ServerConfig) []mcp.Tool {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.servers[server.Name]
+	if !ok || cached.Fingerprint != serverFingerprint(server) {
+		return nil
+	}
+	return cached.Tools
+}
+
+// Save records the tools the server offers and writes the cache. It does
+// nothing on a nil cache.
+func (c *toolSchemaCache) Save(server config.ServerConfig, tools []mcp.Tool) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.servers[server.Name] = cachedServerTools{Fingerprint: serverFingerprint(server), Tools: tools}
+	data, err := json.Marshal(c.servers)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	// Replace the file whole, so another instance never reads half of it
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), toolSchemaFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write tool cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write tool cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write tool cache: %w", err)
+	}
+	return nil
+}
+
+// serverFingerprint identifies the settings that decide which tools a server
+// offers. It is a hash, so the cache holds no secrets from the config.
+func serverFingerprint(server config.ServerConfig) string {
+	data, _ := json.Marshal(struct {
+		Command   string
+		Args      []string
+		Env       map[string]string
+		Transport string
+		URL       string
+		Headers   map[string]string
+	}{server.Command, server.Args, server.Env, server.Transport, server.URL, server.Headers})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolSchemaCache(t *testing.T) {
+	cfg := &config.Config{Storage: config.StorageConfig{DataDir: t.TempDir()}}
+	server := config.ServerConfig{Name: "notes", Command: "notes-server", Env: map[string]string{"NOTES_TOKEN": "s3cret"}}
+
+	cache, err := openToolSchemaCache(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, cache.Tools(server))
+	require.NoError(t, cache.Save(server, []mcp.Tool{{Name: "search_notes", ServerName: "notes"}}))
+
+	cache, err = openToolSchemaCache(cfg)
+	require.NoError(t, err)
+	tools := cache.Tools(server)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "search_notes", tools[0].Name)
+
+	disabled := server
+	disabled.Enabled = new(bool)
+	assert.Len(t, cache.Tools(disabled), 1, "Settings that don't change the tools keep them cached")
+	changed := server
+	changed.Args = []string{"--read-only"}
+	assert.Nil(t, cache.Tools(changed), "Changing the server's config drops its tools")
+
+	data, err := os.ReadFile(filepath.Join(cfg.Storage.DataDir, toolSchemaFile))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cret")
+
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Storage.DataDir, toolSchemaFile), []byte("{"), 0600))
+	cache, err = openToolSchemaCache(cfg)
+	assert.Error(t, err)
+	assert.Nil(t, cache.Tools(server), "A broken cache is started over")
+}
+
+// newTestMCPServer serves an MCP server over HTTP that offers tools
+func newTestMCPServer(t *testing.T, tools ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req mcp.Message
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		var result interface{} = map[string]interface{}{"protocolVersion": "2024-11-05", "capabilities": map[string]interface{}{}}
+		if req.Method == "tools/list" {
+			var list []map[string]interface{}
+			for _, name := range tools {
+				list = append(list, map[string]interface{}{"name": name, "inputSchema": map[string]interface{}{"type": "object"}})
+			}
+			result = map[string]interface{}{"tools": list}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(mcp.Message{ID: req.ID, Result: result})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAgent_ConnectServersInBackground(t *testing.T) {
+	agent, _ := newTestAskAgent(t, &scriptedModel{})
+	var err error
+	agent.toolSchemas, err = openToolSchemaCache(agent.config)
+	require.NoError(t, err)
+
+	notes := config.ServerConfig{Name: "notes", Transport: "http", URL: newTestMCPServer(t, "search_notes", "add_note").URL}
+	broken := config.ServerConfig{Name: "broken", Command: filepath.Join(t.TempDir(), "missing-server")}
+	require.NoError(t, agent.toolSchemas.Save(notes, []mcp.Tool{{Name: "search_notes"}, {Name: "old_tool"}}))
+	require.NoError(t, agent.toolSchemas.Save(broken, []mcp.Tool{{Name: "fix"}}))
+
+	sub := agent.Subscribe()
+	defer sub.Close()
+	agent.connectServersInBackground([]config.ServerConfig{notes, broken})
+	defer agent.Stop(t.Context())
+
+	changes := map[string]events.ToolsChangedEvent{}
+	timeout := time.After(5 * time.Second)
+	for len(changes) < 2 {
+		select {
+		case event := <-sub.Events():
+			if change, ok := event.(events.ToolsChangedEvent); ok {
+				changes[change.Server] = change
+			}
+		case <-timeout:
+			t.Fatalf("servers did not finish starting: %v", changes)
+		}
+	}
+	assert.Equal(t, []string{"add_note"}, changes["notes"].Added)
+	assert.Equal(t, []string{"old_tool"}, changes["notes"].Removed)
+	assert.Equal(t, []string{"fix"}, changes["broken"].Removed, "The cached tools of a server that fails to start are dropped")
+
+	_, ok := agent.mcpRegistry.GetTool("fix")
+	assert.False(t, ok)
+	_, ok = agent.mcpRegistry.GetTool("add_note")
+	assert.True(t, ok)
+	assert.Len(t, agent.toolSchemas.Tools(notes), 2, "The tools the server offers are cached for next time")
+}
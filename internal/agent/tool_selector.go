@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
 )
 
 // Intent represents user intent classification
@@ -33,10 +35,14 @@ type ToolSuggestion struct {
 	Alternatives []string
 }
 
-// IntentClassifier classifies user intent and suggests appropriate tools
+// IntentClassifier classifies user intent and suggests appropriate tools.
+// Intents are found by keyword unless a model is set with SetModel, in which
+// case keywords are only used when the model can't answer.
 type IntentClassifier struct {
 	discovery *ToolDiscovery
 	logger    mcp.Logger
+	model     model.Model   // Model that classifies requests; nil uses keywords
+	timeout   time.Duration // Longest wait for the model
 }
 
 // NewIntentClassifier creates a new intent classifier
@@ -47,8 +53,29 @@ func NewIntentClassifier(discovery *ToolDiscovery, logger mcp.Logger) *IntentCla
 	}
 }
 
+// SetModel has m classify requests, waiting at most timeout for each answer
+// before falling back to keywords
+func (ic *IntentClassifier) SetModel(m model.Model, timeout time.Duration) {
+	ic.model = m
+	ic.timeout = timeout
+}
+
 // ClassifyIntent analyzes user input to determine intent
 func (ic *IntentClassifier) ClassifyIntent(ctx context.Context, userInput string) (Intent, float64, error) {
+	if ic.model != nil {
+		intent, confidence, err := ic.classifyWithModel(ctx, userInput)
+		if err == nil {
+			ic.logger.Debug("Model classified intent '%s' with confidence %.2f for input: %s",
+				intent, confidence, userInput)
+			return intent, confidence, nil
+		}
+		ic.logger.Error("Model intent classification failed, using keywords: %v", err)
+	}
+	return ic.classifyByKeywords(userInput)
+}
+
+// classifyByKeywords scores each intent by the keywords in userInput
+func (ic *IntentClassifier) classifyByKeywords(userInput string) (Intent, float64, error) {
 	inputLower := strings.ToLower(strings.TrimSpace(userInput))
 	words := strings.Fields(inputLower)
 
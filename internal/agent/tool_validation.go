@@ -11,33 +11,13 @@ import (
 // ValidateToolCall validates a tool call against the tool's JSON schema
 func ValidateToolCall(toolCall model.ToolCall, tool mcp.Tool) error {
 	// If no schema, accept anything
-	if tool.InputSchema == nil {
+	schema := tool.Schema()
+	if schema == nil {
 		return nil
 	}
 	
-	schema := tool.InputSchema
-	
-	// Get properties and required fields from schema
-	properties, _ := schema["properties"].(map[string]interface{})
-	if properties == nil {
-		properties = make(map[string]interface{})
-	}
-	
-	required, _ := schema["required"].([]interface{})
-	requiredMap := make(map[string]bool)
-	for _, req := range required {
-		if reqStr, ok := req.(string); ok {
-			requiredMap[reqStr] = true
-		}
-	}
-	
-	// Check arguments exist
-	if toolCall.Arguments == nil {
-		toolCall.Arguments = make(map[string]interface{})
-	}
-	
 	// Validate required parameters are present
-	for paramName := range requiredMap {
+	for _, paramName := range schema.Required {
 		if _, exists := toolCall.Arguments[paramName]; !exists {
 			return fmt.Errorf("missing required parameter: %s", paramName)
 		}
@@ -45,20 +25,15 @@ func ValidateToolCall(toolCall model.ToolCall, tool mcp.Tool) error {
 	
 	// Validate no unknown parameters
 	for paramName := range toolCall.Arguments {
-		if _, exists := properties[paramName]; !exists {
+		if _, exists := schema.Properties[paramName]; !exists {
 			return fmt.Errorf("unknown parameter: %s (not in tool schema)", paramName)
 		}
 	}
 	
 	// Validate parameter types
 	for paramName, paramValue := range toolCall.Arguments {
-		paramSchema, exists := properties[paramName]
-		if !exists {
-			continue // Already checked above
-		}
-		
-		paramSchemaMap, ok := paramSchema.(map[string]interface{})
-		if !ok {
+		paramSchemaMap := schema.Properties[paramName]
+		if paramSchemaMap == nil {
 			continue
 		}
 		
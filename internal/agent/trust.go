@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// serverTrust keeps the permissions file loaded, reading it again whenever
+// it changes, so trust set with "othello mcp trust" applies to an agent that
+// is already running
+type serverTrust struct {
+	path   string
+	logger *log.Logger
+
+	mu          sync.Mutex
+	modTime     time.Time
+	size        int64
+	permissions *storage.Permissions
+}
+
+// newServerTrust returns the trust kept in the permissions file of dataDir.
+// Without a data directory no server is given any.
+func newServerTrust(dataDir string, logger *log.Logger) *serverTrust {
+	if dataDir == "" {
+		return &serverTrust{logger: logger}
+	}
+	path, err := storage.PermissionsPath(dataDir)
+	if err != nil {
+		logger.Printf("Warning: Server trust won't be applied: %v", err)
+	}
+	return &serverTrust{path: path, logger: logger}
+}
+
+// level returns the trust given to server, or "" when it was given none
+func (t *serverTrust) level(server string) storage.TrustLevel {
+	if t.path == "" {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, err := os.Stat(t.path)
+	switch {
+	case err != nil:
+		t.permissions, t.modTime, t.size = nil, time.Time{}, 0
+	case t.permissions == nil || !info.ModTime().Equal(t.modTime) || info.Size() != t.size:
+		permissions, err := storage.LoadPermissions(t.path)
+		if err != nil {
+			// The last decisions read stay in force until the file is fixed
+			t.logger.Printf("Warning: Keeping the server trust read before: %v", err)
+			break
+		}
+		t.permissions, t.modTime, t.size = permissions, info.ModTime(), info.Size()
+	}
+	if t.permissions == nil {
+		return ""
+	}
+	level, _ := t.permissions.Trust(server)
+	return level
+}
+
+// toolTrust returns the server of toolName and the trust it was given, ""
+// when it was given none
+func (a *Agent) toolTrust(toolName string) (string, storage.TrustLevel) {
+	tool, ok := a.mcpRegistry.GetTool(toolName)
+	if !ok || a.trust == nil {
+		return "", ""
+	}
+	return tool.ServerName, a.trust.level(tool.ServerName)
+}
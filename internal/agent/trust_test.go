@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_ServerTrust(t *testing.T) {
+	agent, dir := newTestAskAgent(t, &MockModel{})
+	require.NoError(t, agent.openStore())
+	t.Cleanup(agent.closeStore)
+	path, err := storage.PermissionsPath(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+	recall := []model.ToolCall{{Name: "recall", Arguments: map[string]interface{}{"query": "deploys"}}}
+	forget := []model.ToolCall{{Name: "forget", Arguments: map[string]interface{}{"id": "mem_1"}}}
+	agent.config.Agent.PlanApproval = "always"
+
+	// Trust set while the agent runs applies to its next call
+	require.NoError(t, storage.SetServerTrust(path, memoryServerName, storage.TrustReadOnly))
+	assert.Nil(t, agent.ToolPlan(recall), "Tools that read from a read-only server don't need approval")
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "forget", map[string]interface{}{"id": 1}, &model.ConversationContext{})
+	var denial *PolicyDenial
+	require.ErrorAs(t, err, &denial)
+	assert.Contains(t, err.Error(), "the othello-memory server is only trusted with tools that read, and forget deletes or changes data")
+
+	require.NoError(t, storage.SetServerTrust(path, memoryServerName, storage.TrustAsk))
+	agent.config.Agent.PlanApproval = "never"
+	assert.NotNil(t, agent.ToolPlan(recall), "Every call to a server trusted when asked waits for approval")
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "deploys"}, &model.ConversationContext{})
+	assert.EqualError(t, err, "tool policy denied recall: the othello-memory server is only trusted with calls the user approves")
+	_, err = agent.ExecuteToolUnifiedWithContext(model.WithApprovedCalls(ctx, recall), "recall", map[string]interface{}{"query": "deploys"}, &model.ConversationContext{})
+	assert.NoError(t, err, "Approved calls run")
+
+	require.NoError(t, storage.SetServerTrust(path, memoryServerName, storage.TrustFull))
+	agent.config.Agent.PlanApproval = "always"
+	assert.Nil(t, agent.ToolPlan(forget), "Trusted servers run without approval")
+
+	require.NoError(t, storage.SetServerTrust(path, memoryServerName, storage.TrustBlocked))
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "deploys"}, &model.ConversationContext{})
+	assert.EqualError(t, err, "tool policy denied recall: the othello-memory server is blocked")
+
+	require.NoError(t, storage.SetServerTrust(path, memoryServerName, ""))
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "deploys"}, &model.ConversationContext{})
+	assert.NoError(t, err, "Without a decision the configuration decides")
+}
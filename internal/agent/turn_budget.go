@@ -0,0 +1,26 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// logBudget notes in the log how much of the turn's time budget is left
+// before what starts
+func (a *Agent) logBudget(ctx context.Context, what string) {
+	if left, ok := model.TurnBudgetLeft(ctx); ok {
+		a.logger.Printf("Turn budget: %s left before %s", left.Round(time.Millisecond), what)
+	}
+}
+
+// turnError returns err, or ErrTurnTimeout with the budget when err ended
+// the turn because it ran out of time
+func (a *Agent) turnError(ctx context.Context, err error) error {
+	if err != nil && model.TurnTimedOut(ctx) {
+		return fmt.Errorf("%w after agent.turn_timeout of %s", model.ErrTurnTimeout, a.config.Agent.TurnTimeout)
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// reversible is implemented by built-in tool clients whose changes can be
+// reversed by another of their tools
+type reversible interface {
+	// inverse returns the call that reverses a successful call to name with
+	// params, which returned result
+	inverse(name string, params map[string]interface{}, result string) (model.ToolCall, bool)
+}
+
+// inverseCall returns the call that reverses a successful call to toolName:
+// the one in agent.undo for it, or else the one its built-in client knows
+func (a *Agent) inverseCall(toolName string, params map[string]interface{}, result string) (model.ToolCall, bool) {
+	for _, rule := range a.config.Agent.Undo {
+		if !toolMatches(rule.Tool, toolName) {
+			continue
+		}
+		arguments, err := fillArguments(rule.Arguments, undoVariables(params, result))
+		if err != nil {
+			a.logger.Printf("Can't undo %s with %s: %v", toolName, rule.Inverse, err)
+			return model.ToolCall{}, false
+		}
+		return model.ToolCall{Name: rule.Inverse, Arguments: arguments}, true
+	}
+
+	tool, ok := a.mcpRegistry.GetTool(toolName)
+	if !ok {
+		return model.ToolCall{}, false
+	}
+	client, ok := a.mcpRegistry.GetServer(tool.ServerName)
+	if !ok {
+		return model.ToolCall{}, false
+	}
+	if r, ok := client.(reversible); ok {
+		return r.inverse(toolName, params, result)
+	}
+	return model.ToolCall{}, false
+}
+
+// undoVariables returns what agent.undo arguments can refer to: the call's
+// arguments, then the fields of a JSON object result, and the whole result
+func undoVariables(params map[string]interface{}, result string) map[string]interface{} {
+	variables := make(map[string]interface{}, len(params)+1)
+	for name, value := range params {
+		variables[name] = value
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(result)), &fields); err == nil {
+		for name, value := range fields {
+			variables[name] = value
+		}
+	}
+	variables["result"] = result
+	return variables
+}
+
+// recordInverse keeps the call that reverses a successful call to toolName
+// for Undo, when there is one
+func (a *Agent) recordInverse(convContext *model.ConversationContext, toolName string, params map[string]interface{}, result *mcp.ToolResult) {
+	call, ok := a.inverseCall(toolName, params, toolResultText(result))
+	if !ok {
+		return
+	}
+	a.logger.Printf("Recorded %s to undo %s", call.Name, toolName)
+	convContext.Undo = append(convContext.Undo, call)
+}
+
+// Undo makes calls, which reverse earlier tool calls, newest first. They are
+// checked against the tool policies and the servers' trust, and run through
+// the hooks, like the calls they reverse. It stops at the first that fails
+// and describes the calls made.
+func (a *Agent) Undo(ctx context.Context, calls []model.ToolCall) (string, error) {
+	var done []string
+	for i := len(calls) - 1; i >= 0; i-- {
+		call := calls[i]
+		a.logger.Printf("Undoing with %s: %+v", call.Name, call.Arguments)
+		run, err := a.runTool(ctx, call.Name, call.Arguments, &model.ConversationContext{SessionType: "undo"})
+		if err != nil {
+			return strings.Join(done, "\n"), fmt.Errorf("undo with %s failed: %w", call.Name, err)
+		}
+		output := run.output
+		if !run.done {
+			if run.result != nil {
+				output = toolResultText(run.result)
+			}
+			output = a.afterToolCall(ctx, run.name, run.params, output)
+		}
+		done = append(done, fmt.Sprintf("%s: %s", call.Name, truncateString(output, 200)))
+	}
+	return strings.Join(done, "\n"), nil
+}
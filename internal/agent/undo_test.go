@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_UndoForgetsRememberedFacts(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	ctx := context.Background()
+	convContext := &model.ConversationContext{}
+
+	_, err := agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "Deploys are on Tuesdays"}, convContext)
+	require.NoError(t, err)
+	_, err = agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "deploys"}, convContext)
+	require.NoError(t, err)
+	require.Len(t, convContext.Undo, 1, "Only the call that changed something is recorded")
+	assert.Equal(t, "forget", convContext.Undo[0].Name)
+
+	done, err := agent.Undo(ctx, convContext.Undo)
+	require.NoError(t, err)
+	assert.Contains(t, done, "forget: Forgot memory #")
+	output, err := agent.ExecuteToolUnifiedWithContext(ctx, "recall", map[string]interface{}{"query": "deploys"}, &model.ConversationContext{})
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Tuesdays")
+
+	_, err = agent.Undo(ctx, convContext.Undo)
+	assert.ErrorContains(t, err, "undo with forget failed", "The fact is already gone")
+}
+
+func TestAgent_UndoChecksPoliciesAndRunsHooks(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	ctx := context.Background()
+	convContext := &model.ConversationContext{}
+	_, err := agent.ExecuteToolUnifiedWithContext(ctx, "remember", map[string]interface{}{"content": "Deploys are on Tuesdays"}, convContext)
+	require.NoError(t, err)
+	require.Len(t, convContext.Undo, 1)
+
+	agent.config.Agent.ToolPolicies = []config.ToolPolicyConfig{{
+		Tool:      "forget",
+		Arguments: map[string]config.ArgumentPolicyConfig{"id": {Pattern: "^none$"}},
+	}}
+	_, err = agent.Undo(ctx, convContext.Undo)
+	var denial *PolicyDenial
+	require.ErrorAs(t, err, &denial, "Calls that reverse others are checked like them")
+
+	agent.config.Agent.ToolPolicies = nil
+	agent.RegisterHook(HookPostToolCall, NewHook("stamp", func(ctx context.Context, event *HookEvent) error {
+		event.Result = "[checked] " + event.Result
+		return nil
+	}), "forget")
+	done, err := agent.Undo(ctx, convContext.Undo)
+	require.NoError(t, err)
+	assert.Contains(t, done, "forget: [checked] Forgot memory #")
+}
+
+func TestAgent_InverseFromConfig(t *testing.T) {
+	agent := newTestRetryAgent(t, &scriptedModel{}, 0)
+	agent.config.Agent.Undo = []config.UndoConfig{{
+		Tool:      "store_*",
+		Inverse:   "delete_memory",
+		Arguments: map[string]interface{}{"memory_id": "{{.id}}", "reason": "undo {{.domain}}"},
+	}}
+
+	call, ok := agent.inverseCall("store_memory", map[string]interface{}{"domain": "work"}, `{"id": 42, "status": "stored"}`)
+	require.True(t, ok)
+	assert.Equal(t, model.ToolCall{Name: "delete_memory", Arguments: map[string]interface{}{"memory_id": float64(42), "reason": "undo work"}}, call)
+
+	_, ok = agent.inverseCall("search", map[string]interface{}{}, "")
+	assert.False(t, ok)
+}
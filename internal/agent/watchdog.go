@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Growth past a baseline that the watchdog reports: the heap or the
+// goroutines at least doubling by a meaningful amount, or notification
+// subscribers piling up, which points to subscriptions that are never closed
+const (
+	watchdogHeapGrowth       = 64 << 20
+	watchdogGoroutineGrowth  = 50
+	watchdogSubscriberGrowth = 10
+)
+
+// resourceUsage is what the watchdog measures
+type resourceUsage struct {
+	Heap        uint64 // Bytes of allocated heap objects
+	Goroutines  int
+	Subscribers int // Open event bus subscriptions
+}
+
+// watchdog measures resource usage every interval and logs a warning when it
+// grows abnormally, to find what bloats long sessions
+type watchdog struct {
+	measure  func() resourceUsage
+	logger   *log.Logger
+	baseline resourceUsage // What growth is measured from; raised after each warning
+	stop     chan struct{}
+	done     sync.WaitGroup
+}
+
+// watchResources starts the watchdog at the configured interval, until the
+// returned function is called
+func (a *Agent) watchResources() func() {
+	w := startWatchdog(a.config.Logging.WatchdogInterval, a.measureResources, a.logger, a.crashes)
+	if w == nil {
+		return func() {}
+	}
+	return w.Stop
+}
+
+// measureResources returns the agent's current resource usage
+func (a *Agent) measureResources() resourceUsage {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return resourceUsage{
+		Heap:        stats.HeapAlloc,
+		Goroutines:  runtime.NumGoroutine(),
+		Subscribers: a.bus.Subscribers(),
+	}
+}
+
+// startWatchdog takes the baseline now and then checks usage every interval
+// until the returned watchdog is stopped. It returns nil when interval is 0.
+func startWatchdog(interval time.Duration, measure func() resourceUsage, logger *log.Logger, crashes *crashReporter) *watchdog {
+	if interval <= 0 {
+		return nil
+	}
+
+	w := &watchdog{measure: measure, logger: logger, baseline: measure(), stop: make(chan struct{})}
+	w.done.Add(1)
+	go func() {
+		defer w.done.Done()
+		defer crashes.recover("the memory watchdog")
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				for _, warning := range w.check(w.measure()) {
+					w.logger.Printf("Warning: %s; run with --pprof to profile it", warning)
+				}
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+	return w
+}
+
+// check compares usage with the baseline and describes abnormal growth. The
+// baseline of what grew is raised to usage, so growth is reported once.
+func (w *watchdog) check(usage resourceUsage) []string {
+	var warnings []string
+	if usage.Heap >= 2*w.baseline.Heap && usage.Heap-w.baseline.Heap >= watchdogHeapGrowth {
+		warnings = append(warnings, fmt.Sprintf("Heap grew from %d MB to %d MB", w.baseline.Heap>>20, usage.Heap>>20))
+		w.baseline.Heap = usage.Heap
+	}
+	if usage.Goroutines >= 2*w.baseline.Goroutines && usage.Goroutines-w.baseline.Goroutines >= watchdogGoroutineGrowth {
+		warnings = append(warnings, fmt.Sprintf("Goroutines grew from %d to %d", w.baseline.Goroutines, usage.Goroutines))
+		w.baseline.Goroutines = usage.Goroutines
+	}
+	if usage.Subscribers-w.baseline.Subscribers >= watchdogSubscriberGrowth {
+		warnings = append(warnings, fmt.Sprintf("Event subscribers grew from %d to %d, so some may never be closed", w.baseline.Subscribers, usage.Subscribers))
+		w.baseline.Subscribers = usage.Subscribers
+	}
+	return warnings
+}
+
+// Stop stops the watchdog and waits for a check in progress to finish
+func (w *watchdog) Stop() {
+	close(w.stop)
+	w.done.Wait()
+}
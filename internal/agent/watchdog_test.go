@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"bytes"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchdog_ReportsAbnormalGrowthOnce(t *testing.T) {
+	w := &watchdog{baseline: resourceUsage{Heap: 40 << 20, Goroutines: 20, Subscribers: 2}}
+
+	assert.Empty(t, w.check(resourceUsage{Heap: 70 << 20, Goroutines: 60, Subscribers: 5}), "Ordinary growth isn't reported")
+	assert.Equal(t, []string{
+		"Heap grew from 40 MB to 130 MB",
+		"Goroutines grew from 20 to 80",
+		"Event subscribers grew from 2 to 12, so some may never be closed",
+	}, w.check(resourceUsage{Heap: 130 << 20, Goroutines: 80, Subscribers: 12}))
+	assert.Empty(t, w.check(resourceUsage{Heap: 140 << 20, Goroutines: 85, Subscribers: 13}), "Growth is measured from the last report")
+}
+
+func TestWatchdog_LogsWhileRunning(t *testing.T) {
+	assert.Nil(t, startWatchdog(0, nil, nil, nil), "A zero interval disables the watchdog")
+
+	var mu sync.Mutex
+	subscribers := 1
+	measure := func() resourceUsage {
+		mu.Lock()
+		defer mu.Unlock()
+		return resourceUsage{Subscribers: subscribers}
+	}
+	var buf syncBuffer
+	w := startWatchdog(10*time.Millisecond, measure, log.New(&buf, "", 0), nil)
+	require.NotNil(t, w)
+	mu.Lock()
+	subscribers = 40
+	mu.Unlock()
+	assert.Eventually(t, func() bool {
+		return bytes.Contains(buf.Bytes(), []byte("Warning: Event subscribers grew from 1 to 40, so some may never be closed; run with --pprof to profile it"))
+	}, time.Second, 10*time.Millisecond)
+	w.Stop()
+}
+
+// syncBuffer is a bytes.Buffer that can be written and read concurrently
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
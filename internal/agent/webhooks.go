@@ -0,0 +1,213 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+)
+
+const (
+	// defaultWebhookRetries is how many times a failed delivery is tried
+	// again when a webhook's retries aren't set; a retries of 0 means none
+	defaultWebhookRetries = 3
+
+	// defaultWebhookTimeout bounds one delivery attempt when a webhook's
+	// timeout isn't set
+	defaultWebhookTimeout = 10 * time.Second
+
+	// webhookDrainTimeout is how long stopping waits for deliveries in
+	// progress before cancelling them
+	webhookDrainTimeout = 10 * time.Second
+)
+
+// webhookBackoff is the wait before a failed delivery is first tried again;
+// each later wait is twice as long
+var webhookBackoff = time.Second
+
+// webhookPayload is the JSON body posted to webhooks
+type webhookPayload struct {
+	Event string      `json:"event"`
+	Time  time.Time   `json:"time"`
+	Data  interface{} `json:"data"`
+}
+
+// webhookEvent returns the name and data webhooks are sent for event, and
+// false for events they aren't sent
+func webhookEvent(event events.Event) (string, interface{}, bool) {
+	switch e := event.(type) {
+	case events.ConversationCompletedEvent:
+		return "conversation_completed", map[string]interface{}{
+			"conversation_id": e.ConversationID,
+			"tool_calls":      e.ToolCalls,
+			"total_tokens":    e.TotalTokens,
+			"duration_ms":     e.Duration.Milliseconds(),
+		}, true
+	case events.ToolExecutedEvent:
+		if e.Success {
+			return "", nil, false
+		}
+		return "tool_failed", map[string]interface{}{
+			"tool":        e.Tool,
+			"error":       e.Error,
+			"duration_ms": e.Duration.Milliseconds(),
+		}, true
+	case events.ScheduledTaskEvent:
+		return "scheduled_task", map[string]interface{}{
+			"task":        e.Task,
+			"detail":      e.Detail,
+			"error":       e.Error,
+			"duration_ms": e.Duration.Milliseconds(),
+		}, true
+	}
+	return "", nil, false
+}
+
+// startWebhooks posts events to serve.webhooks as they happen, until the
+// returned function is called. It then waits a while for deliveries in
+// progress. Nothing is sent in offline mode.
+func (a *Agent) startWebhooks() func() {
+	hooks := a.config.Serve.Webhooks
+	if len(hooks) == 0 {
+		return func() {}
+	}
+	if a.config.Agent.Offline {
+		a.logger.Printf("Offline mode: not sending events to %d webhooks", len(hooks))
+		return func() {}
+	}
+
+	sub := a.bus.SubscribeNew()
+	ctx, cancel := context.WithCancel(context.Background())
+	var sending sync.WaitGroup
+	sending.Add(1)
+	go func() {
+		defer sending.Done()
+		defer a.crashes.recover("sending webhooks")
+		for event := range sub.Events() {
+			name, data, ok := webhookEvent(event)
+			if !ok {
+				continue
+			}
+			body, err := json.Marshal(webhookPayload{Event: name, Time: time.Now().UTC(), Data: data})
+			if err != nil {
+				a.logger.Printf("Warning: Failed to encode %s for webhooks: %v", name, err)
+				continue
+			}
+			for _, hook := range hooks {
+				if len(hook.Events) > 0 && !slices.Contains(hook.Events, name) {
+					continue
+				}
+				sending.Add(1)
+				go func(hook config.WebhookConfig) {
+					defer sending.Done()
+					defer a.crashes.recover("sending a webhook")
+					a.deliverWebhook(ctx, hook, name, body)
+				}(hook)
+			}
+		}
+	}()
+	a.logger.Printf("Sending events to %d webhooks", len(hooks))
+
+	return func() {
+		sub.Close()
+		done := make(chan struct{})
+		go func() {
+			sending.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(webhookDrainTimeout):
+			cancel()
+			<-done
+		}
+		cancel()
+	}
+}
+
+// deliverWebhook posts body, the payload of event, to hook, trying again
+// with growing waits while it fails and can be retried
+func (a *Agent) deliverWebhook(ctx context.Context, hook config.WebhookConfig, event string, body []byte) {
+	retries := defaultWebhookRetries
+	if hook.Retries != nil {
+		retries = *hook.Retries
+	}
+	wait := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := postWebhook(ctx, hook, event, body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt > retries {
+			a.logger.Printf("Failed to send %s to webhook %s after %d attempts: %v", event, webhookHost(hook.URL), attempt, err)
+			return
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			a.logger.Printf("Gave up sending %s to webhook %s: %v", event, webhookHost(hook.URL), err)
+			return
+		}
+		wait *= 2
+	}
+}
+
+// postWebhook makes one attempt to post body to hook, signed when the hook
+// has a secret. It reports whether a failed attempt is worth trying again:
+// requests the webhook refused as invalid aren't.
+func postWebhook(ctx context.Context, hook config.WebhookConfig, event string, body []byte) (bool, error) {
+	timeout := hook.Timeout
+	if timeout == 0 {
+		timeout = defaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Othello-Event", event)
+	if hook.Secret != "" {
+		req.Header.Set("X-Othello-Signature", "sha256="+webhookSignature(hook.Secret, body))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook answered %s", resp.Status)
+}
+
+// webhookSignature returns the hex HMAC-SHA256 of body keyed with secret,
+// which receivers compute the same way to check a payload came from Othello
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookHost returns the host of a webhook's URL to name it in the log,
+// since the rest of the URL often holds a token
+func webhookHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "(invalid URL)"
+}
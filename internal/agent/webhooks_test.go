@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookReceiver records the payloads posted to it
+type webhookReceiver struct {
+	mu       sync.Mutex
+	payloads []webhookPayload
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payloads = append(r.payloads, payload)
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+}
+
+func (r *webhookReceiver) events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var names []string
+	for _, payload := range r.payloads {
+		names = append(names, payload.Event)
+	}
+	return names
+}
+
+func TestWebhookEvent(t *testing.T) {
+	name, data, ok := webhookEvent(events.ToolExecutedEvent{Tool: "search", Error: "timeout", Duration: 1500 * time.Millisecond})
+	require.True(t, ok)
+	assert.Equal(t, "tool_failed", name)
+	assert.Equal(t, map[string]interface{}{"tool": "search", "error": "timeout", "duration_ms": int64(1500)}, data)
+
+	_, _, ok = webhookEvent(events.ToolExecutedEvent{Tool: "search", Success: true})
+	assert.False(t, ok, "Successful tool calls aren't sent")
+	_, _, ok = webhookEvent(events.LogEvent{Text: "ready"})
+	assert.False(t, ok)
+}
+
+func TestAgent_WebhooksReceiveEvents(t *testing.T) {
+	all, failures := &webhookReceiver{}, &webhookReceiver{}
+	allServer, failuresServer := httptest.NewServer(all), httptest.NewServer(failures)
+	defer allServer.Close()
+	defer failuresServer.Close()
+
+	agent, _ := newTestAskAgent(t, &MockModel{})
+	agent.config.Serve.Webhooks = []config.WebhookConfig{
+		{URL: allServer.URL, Secret: "hook-secret"},
+		{URL: failuresServer.URL, Events: []string{"tool_failed"}},
+	}
+	agent.bus.Publish(events.ScheduledTaskEvent{Task: "before webhooks started"})
+	stop := agent.startWebhooks()
+
+	agent.bus.Publish(events.ConversationCompletedEvent{ConversationID: "conv_1", ToolCalls: 2})
+	agent.bus.Publish(events.ToolExecutedEvent{Tool: "search", Error: "timeout"})
+	agent.bus.Publish(events.ScheduledTaskEvent{Task: "history_pruning", Detail: "pruned 0 conversations (0 messages)"})
+	assert.Eventually(t, func() bool { return len(all.events()) == 3 && len(failures.events()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	assert.ElementsMatch(t, []string{"conversation_completed", "tool_failed", "scheduled_task"}, all.events(), "Events from before the webhooks started aren't sent")
+	assert.Equal(t, []string{"tool_failed"}, failures.events())
+	for i, header := range all.headers {
+		assert.Equal(t, "sha256="+webhookSignature("hook-secret", all.bodies[i]), header.Get("X-Othello-Signature"))
+		assert.Equal(t, "application/json", header.Get("Content-Type"))
+	}
+	assert.Empty(t, failures.headers[0].Get("X-Othello-Signature"), "Payloads are only signed with a secret")
+	assert.Equal(t, "tool_failed", failures.headers[0].Get("X-Othello-Event"))
+}
+
+func TestAgent_WebhookRetries(t *testing.T) {
+	webhookBackoff = time.Millisecond
+	defer func() { webhookBackoff = time.Second }()
+
+	var attempts atomic.Int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		}
+	}))
+	defer flaky.Close()
+	var refusals atomic.Int32
+	refusing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refusals.Add(1)
+		http.Error(w, "unknown hook", http.StatusNotFound)
+	}))
+	defer refusing.Close()
+	var unretried atomic.Int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unretried.Add(1)
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	noRetries := 0
+	agent, _ := newTestAskAgent(t, &MockModel{})
+	agent.config.Serve.Webhooks = []config.WebhookConfig{{URL: flaky.URL}, {URL: refusing.URL}, {URL: down.URL, Retries: &noRetries}}
+	stop := agent.startWebhooks()
+	agent.bus.Publish(events.ToolExecutedEvent{Tool: "search", Error: "timeout"})
+	assert.Eventually(t, func() bool { return attempts.Load() == 3 }, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	assert.Equal(t, int32(3), attempts.Load(), "Server errors are retried until delivered")
+	assert.Equal(t, int32(1), refusals.Load(), "Refused payloads aren't retried")
+	assert.Equal(t, int32(1), unretried.Load(), "retries: 0 tries once")
+}
+
+func TestAgent_WebhooksOffline(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	agent, _ := newTestAskAgent(t, &MockModel{})
+	agent.config.Agent.Offline = true
+	agent.config.Serve.Webhooks = []config.WebhookConfig{{URL: server.URL}}
+	stop := agent.startWebhooks()
+	agent.bus.Publish(events.ToolExecutedEvent{Tool: "search", Error: "timeout"})
+	stop()
+
+	assert.Zero(t, agent.bus.Subscribers(), "Offline mode doesn't subscribe to events")
+	assert.Empty(t, receiver.events())
+}
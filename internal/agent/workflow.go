@@ -0,0 +1,354 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowName matches step names and variable names, which templates refer
+// to as {{.name}}
+var workflowName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// wholeReference matches an argument that is nothing but one variable, which
+// is passed with its own type rather than as text
+var wholeReference = regexp.MustCompile(`^\{\{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+
+// Workflow is a sequence of prompts and tool calls read from a YAML file.
+// Each step's output is saved as a variable under the step's name, and
+// prompts and tool arguments refer to variables as {{.name}}.
+type Workflow struct {
+	Name  string                 `yaml:"name" json:"name,omitempty"`
+	Vars  map[string]interface{} `yaml:"vars" json:"vars,omitempty"`
+	Steps []WorkflowStep         `yaml:"steps" json:"steps"`
+}
+
+// WorkflowStep either sends Prompt to the model or calls Tool with Arguments
+type WorkflowStep struct {
+	Name      string                 `yaml:"name" json:"name,omitempty"`
+	Prompt    string                 `yaml:"prompt" json:"prompt,omitempty"`
+	Tool      string                 `yaml:"tool" json:"tool,omitempty"`
+	Arguments map[string]interface{} `yaml:"arguments" json:"arguments,omitempty"`
+	Optional  bool                   `yaml:"optional" json:"optional,omitempty"` // continue the workflow if this step fails
+}
+
+// label names the step in progress and errors
+func (s WorkflowStep) label() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.Tool != "":
+		return s.Tool
+	default:
+		return "prompt"
+	}
+}
+
+// WorkflowStepResult is the outcome of one workflow step
+type WorkflowStepResult struct {
+	Step      int                    `json:"step"` // 1-based position in the workflow
+	Name      string                 `json:"name,omitempty"`
+	Tool      string                 `json:"tool,omitempty"`
+	Prompt    string                 `json:"prompt,omitempty"`    // the prompt sent, with variables filled in
+	Arguments map[string]interface{} `json:"arguments,omitempty"` // the arguments passed, with variables filled in
+	Output    string                 `json:"output,omitempty"`
+	ToolCalls []ToolCallResult       `json:"tool_calls,omitempty"` // tools the model called for a prompt
+	Error     string                 `json:"error,omitempty"`
+	Usage     *model.Usage           `json:"usage,omitempty"`
+	Duration  string                 `json:"duration"`
+}
+
+// WorkflowResult is the outcome of a workflow run
+type WorkflowResult struct {
+	Name           string               `json:"name,omitempty"`
+	ConversationID string               `json:"conversation_id,omitempty"` // where the prompts and answers were saved
+	Steps          []WorkflowStepResult `json:"steps"`
+	Output         string               `json:"output"` // the last step's output
+	Success        bool                 `json:"success"`
+	Error          string               `json:"error,omitempty"`
+	Duration       string               `json:"duration"`
+}
+
+// LoadWorkflow reads and checks the workflow file at path
+func LoadWorkflow(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow: %w", err)
+	}
+	workflow, err := ParseWorkflow(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return workflow, nil
+}
+
+// ParseWorkflow reads a workflow from YAML and checks that its steps and
+// templates are well formed
+func ParseWorkflow(data []byte) (*Workflow, error) {
+	var workflow Workflow
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&workflow); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("workflow has no steps")
+		}
+		return nil, fmt.Errorf("invalid workflow: %w", err)
+	}
+	if len(workflow.Steps) == 0 {
+		return nil, errors.New("workflow has no steps")
+	}
+	for name := range workflow.Vars {
+		if !workflowName.MatchString(name) {
+			return nil, fmt.Errorf("variable %q must be letters, digits, and underscores", name)
+		}
+	}
+
+	named := make(map[string]bool)
+	for i, step := range workflow.Steps {
+		n := i + 1
+		if (step.Prompt == "") == (step.Tool == "") {
+			return nil, fmt.Errorf("step %d: set either prompt or tool", n)
+		}
+		if step.Prompt != "" && len(step.Arguments) > 0 {
+			return nil, fmt.Errorf("step %d: arguments are only for tool steps", n)
+		}
+		if step.Name != "" {
+			if !workflowName.MatchString(step.Name) {
+				return nil, fmt.Errorf("step %d: name %q must be letters, digits, and underscores", n, step.Name)
+			}
+			if named[step.Name] {
+				return nil, fmt.Errorf("step %d: another step is already named %q", n, step.Name)
+			}
+			named[step.Name] = true
+		}
+		if _, err := parseWorkflowTemplate(step.Prompt); err != nil {
+			return nil, fmt.Errorf("step %d: %w", n, err)
+		}
+		if err := checkArgumentTemplates(step.Arguments); err != nil {
+			return nil, fmt.Errorf("step %d: %w", n, err)
+		}
+	}
+	return &workflow, nil
+}
+
+// RunWorkflow runs the workflow's steps in order, with vars overriding the
+// workflow's own variables. Tool steps run through the tool orchestrator;
+// prompt steps go to the model, which can call tools as it does in chat, and
+// are saved as one conversation. A step that fails stops the workflow unless
+// it is optional. progress, if not nil, is called after each step.
+func (a *Agent) RunWorkflow(ctx context.Context, workflow *Workflow, vars map[string]string, progress func(WorkflowStepResult)) (*WorkflowResult, error) {
+	defer a.startHeadless()()
+
+	started := time.Now()
+	variables := make(map[string]interface{}, len(workflow.Vars)+len(vars))
+	for name, value := range workflow.Vars {
+		variables[name] = value
+	}
+	for name, value := range vars {
+		variables[name] = value
+	}
+
+	orchestrator := NewToolOrchestrator(a.toolExecutor, nil, nil, &LoggerAdapter{Logger: a.logger})
+	result := &WorkflowResult{Name: workflow.Name, Steps: make([]WorkflowStepResult, 0, len(workflow.Steps))}
+	var conv *storage.Conversation
+	var failed error
+	for i, step := range workflow.Steps {
+		if err := ctx.Err(); err != nil {
+			failed = err
+			break
+		}
+
+		stepStarted := time.Now()
+		stepResult := WorkflowStepResult{Step: i + 1, Name: step.Name, Tool: step.Tool}
+		var err error
+		if step.Tool != "" {
+			err = a.runWorkflowTool(ctx, orchestrator, step, variables, &stepResult)
+		} else {
+			conv, err = a.runWorkflowPrompt(ctx, conv, step, variables, &stepResult)
+		}
+		stepResult.Duration = time.Since(stepStarted).Round(time.Millisecond).String()
+		if err != nil {
+			stepResult.Error = err.Error()
+		}
+		result.Steps = append(result.Steps, stepResult)
+		if progress != nil {
+			progress(stepResult)
+		}
+
+		if err != nil && !step.Optional {
+			failed = fmt.Errorf("step %d (%s) failed: %w", i+1, step.label(), err)
+			break
+		}
+		if step.Name != "" {
+			variables[step.Name] = stepResult.Output
+		}
+		if err == nil {
+			result.Output = stepResult.Output
+		}
+	}
+
+	if conv != nil {
+		result.ConversationID = conv.ID
+	}
+	result.Duration = time.Since(started).Round(time.Millisecond).String()
+	result.Success = failed == nil
+	if failed != nil {
+		result.Error = failed.Error()
+		return result, failed
+	}
+	return result, nil
+}
+
+// runWorkflowTool fills in a tool step's arguments and runs it
+func (a *Agent) runWorkflowTool(ctx context.Context, orchestrator *ToolOrchestrator, step WorkflowStep, variables map[string]interface{}, result *WorkflowStepResult) error {
+	arguments, err := fillArguments(step.Arguments, variables)
+	if err != nil {
+		return err
+	}
+	result.Arguments = arguments
+
+	if tool, ok := a.mcpRegistry.GetTool(step.Tool); ok {
+		if err := ValidateToolCall(model.ToolCall{Name: step.Tool, Arguments: arguments}, tool); err != nil {
+			return fmt.Errorf("invalid parameters: %w", err)
+		}
+	}
+	executed := orchestrator.ExecuteStep(ctx, OrchestrationStep{
+		ToolName:   step.Tool,
+		Parameters: arguments,
+		Optional:   step.Optional,
+	})
+	if !executed.Success {
+		return errors.New(executed.Error)
+	}
+	result.Output = executed.Result
+	return nil
+}
+
+// runWorkflowPrompt fills in a prompt step and sends it to the model,
+// saving the exchange to conv, or to a new conversation when conv is nil
+func (a *Agent) runWorkflowPrompt(ctx context.Context, conv *storage.Conversation, step WorkflowStep, variables map[string]interface{}, result *WorkflowStepResult) (*storage.Conversation, error) {
+	prompt, err := fillTemplate(step.Prompt, variables)
+	if err != nil {
+		return conv, err
+	}
+	result.Prompt = prompt
+
+	// Each prompt stands alone; earlier answers reach it only through variables
+	reply, err := a.respond(ctx, conv, nil, prompt, a.generateOptions())
+	if err != nil {
+		return conv, err
+	}
+	result.Output = reply.Response
+	result.ToolCalls = reply.ToolCalls
+	result.Usage = &reply.Usage
+	if conv == nil && reply.ConversationID != "" && a.store != nil {
+		if saved, err := a.store.GetConversation(reply.ConversationID); err == nil {
+			conv = saved
+		}
+	}
+	return conv, nil
+}
+
+// parseWorkflowTemplate parses text as a template that fails on variables
+// that aren't set
+func parseWorkflowTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("step").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// fillTemplate fills in the variables text refers to
+func fillTemplate(text string, variables map[string]interface{}) (string, error) {
+	tmpl, err := parseWorkflowTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var filled strings.Builder
+	if err := tmpl.Execute(&filled, variables); err != nil {
+		return "", fmt.Errorf("failed to fill in template: %w", err)
+	}
+	return filled.String(), nil
+}
+
+// fillArguments fills in the variables in every string of arguments. A string
+// that is nothing but {{.name}} is replaced by the variable itself, so numbers,
+// booleans, and lists keep their type.
+func fillArguments(arguments map[string]interface{}, variables map[string]interface{}) (map[string]interface{}, error) {
+	filled := make(map[string]interface{}, len(arguments))
+	for key, value := range arguments {
+		v, err := fillValue(value, variables)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", key, err)
+		}
+		filled[key] = v
+	}
+	return filled, nil
+}
+
+// fillValue fills in the variables in value for fillArguments
+func fillValue(value interface{}, variables map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if match := wholeReference.FindStringSubmatch(v); match != nil {
+			if variable, ok := variables[match[1]]; ok {
+				return variable, nil
+			}
+		}
+		return fillTemplate(v, variables)
+	case map[string]interface{}:
+		return fillArguments(v, variables)
+	case []interface{}:
+		filled := make([]interface{}, len(v))
+		for i, item := range v {
+			f, err := fillValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			filled[i] = f
+		}
+		return filled, nil
+	default:
+		return value, nil
+	}
+}
+
+// checkArgumentTemplates parses every template in arguments
+func checkArgumentTemplates(arguments map[string]interface{}) error {
+	for key, value := range arguments {
+		if err := checkValueTemplates(value); err != nil {
+			return fmt.Errorf("argument %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// checkValueTemplates parses the templates in value for checkArgumentTemplates
+func checkValueTemplates(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		_, err := parseWorkflowTemplate(v)
+		return err
+	case map[string]interface{}:
+		return checkArgumentTemplates(v)
+	case []interface{}:
+		for _, item := range v {
+			if err := checkValueTemplates(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
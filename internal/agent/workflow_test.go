@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkflow_RejectsMalformedWorkflows(t *testing.T) {
+	for _, tc := range []struct{ yaml, message string }{
+		{``, "workflow has no steps"},
+		{`steps: []`, "workflow has no steps"},
+		{"steps:\n  - prompt: hi\n    tool: recall", "step 1: set either prompt or tool"},
+		{"steps:\n  - name: x\n", "step 1: set either prompt or tool"},
+		{"steps:\n  - prompt: hi\n    arguments: {a: 1}", "step 1: arguments are only for tool steps"},
+		{"steps:\n  - name: a\n    prompt: hi\n  - name: a\n    prompt: again", `step 2: another step is already named "a"`},
+		{"steps:\n  - name: my-step\n    prompt: hi", `step 1: name "my-step" must be letters, digits, and underscores`},
+		{"steps:\n  - prompt: \"{{.x\"", "step 1: invalid template"},
+		{"steps:\n  - tool: recall\n    arguments: {query: \"{{end}}\"}", "step 1: argument query: invalid template"},
+		{"steps:\n  - prompt: hi\n    retries: 3", "field retries not found"},
+	} {
+		_, err := ParseWorkflow([]byte(tc.yaml))
+		assert.ErrorContains(t, err, tc.message, tc.yaml)
+	}
+}
+
+func TestFillArguments_KeepsWholeVariableTypes(t *testing.T) {
+	filled, err := fillArguments(map[string]interface{}{
+		"limit":  "{{ .limit }}",
+		"query":  "notes about {{.topic}}",
+		"nested": map[string]interface{}{"tags": []interface{}{"{{.topic}}", 7}},
+	}, map[string]interface{}{"limit": 5, "topic": "release"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"limit":  5,
+		"query":  "notes about release",
+		"nested": map[string]interface{}{"tags": []interface{}{"release", 7}},
+	}, filled)
+
+	_, err = fillArguments(map[string]interface{}{"query": "{{.missing}}"}, map[string]interface{}{})
+	assert.ErrorContains(t, err, `map has no entry for key "missing"`)
+}
+
+func TestAgent_RunWorkflowPassesVariablesBetweenSteps(t *testing.T) {
+	m := &scriptedModel{responses: []*model.Response{
+		{Content: "Deploys happen on Tuesdays.", Usage: model.Usage{PromptTokens: 30, CompletionTokens: 6}},
+		{Content: "Weekly report: deploys are on Tuesdays."},
+	}}
+	agent, _ := newTestAskAgent(t, m)
+
+	path := filepath.Join(t.TempDir(), "report.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+name: Weekly report
+vars:
+  topic: deploys
+steps:
+  - tool: remember
+    arguments:
+      content: "{{.topic}} happen on Tuesdays"
+      topic: "{{.topic}}"
+  - name: facts
+    tool: recall
+    arguments:
+      query: "{{.topic}}"
+  - name: summary
+    prompt: |
+      Summarize for the {{.team}} team:
+      {{.facts}}
+  - prompt: "Write a weekly report from: {{.summary}}"
+`), 0644))
+	workflow, err := LoadWorkflow(path)
+	require.NoError(t, err)
+
+	var progress []string
+	result, err := agent.RunWorkflow(context.Background(), workflow, map[string]string{"team": "platform"}, func(step WorkflowStepResult) {
+		progress = append(progress, step.Name)
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "Weekly report", result.Name)
+	assert.Equal(t, []string{"", "facts", "summary", ""}, progress)
+	assert.Equal(t, "Weekly report: deploys are on Tuesdays.", result.Output)
+	assert.NotEmpty(t, result.ConversationID)
+
+	require.Len(t, result.Steps, 4)
+	assert.Equal(t, "deploys happen on Tuesdays", result.Steps[0].Arguments["content"])
+	assert.Contains(t, result.Steps[1].Output, "deploys happen on Tuesdays")
+	require.Len(t, m.received, 2)
+	first := m.received[0][len(m.received[0])-1].Content
+	assert.Contains(t, first, "Summarize for the platform team:")
+	assert.Contains(t, first, "deploys happen on Tuesdays")
+	assert.Equal(t, "Write a weekly report from: Deploys happen on Tuesdays.", result.Steps[3].Prompt)
+	assert.Equal(t, 30, result.Steps[2].Usage.PromptTokens)
+}
+
+func TestAgent_RunWorkflowStopsAtFailedSteps(t *testing.T) {
+	agent, _ := newTestAskAgent(t, &scriptedModel{responses: []*model.Response{{Content: "Done anyway."}}})
+
+	workflow, err := ParseWorkflow([]byte(`
+steps:
+  - name: lookup
+    tool: missing_tool
+    optional: true
+  - prompt: "Lookup said: [{{.lookup}}]"
+  - tool: recall
+    arguments: {query: "{{.nothing}}"}
+  - prompt: never sent
+`))
+	require.NoError(t, err)
+
+	result, err := agent.RunWorkflow(context.Background(), workflow, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "step 3 (recall) failed")
+	assert.False(t, result.Success)
+	require.Len(t, result.Steps, 3)
+	assert.Contains(t, result.Steps[0].Error, "not found")
+	assert.Equal(t, "Lookup said: []", result.Steps[1].Prompt, "A failed optional step leaves its variable empty")
+	assert.Equal(t, "Done anyway.", result.Output)
+}
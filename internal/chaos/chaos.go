@@ -0,0 +1,173 @@
+// Package chaos injects faults into tool calls and model requests so the
+// agent's timeout and error handling can be exercised without real failures.
+package chaos
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+)
+
+// defaultDropTimeout bounds how long a dropped tool call waits when neither the
+// config nor the caller's context sets a deadline
+const defaultDropTimeout = 10 * time.Second
+
+// Injector decides which faults to inject. A nil *Injector injects nothing,
+// so callers can use it unconditionally.
+type Injector struct {
+	cfg config.ChaosConfig
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// New returns an injector for cfg, or nil when chaos mode is disabled
+func New(cfg config.ChaosConfig) *Injector {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Injector{
+		cfg: cfg,
+		rng: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// roll reports whether an event with the given probability happens
+func (i *Injector) roll(rate float64) bool {
+	if i == nil || rate <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rng.Float64() < rate
+}
+
+// ToolLatency returns a random delay, up to the configured maximum, to add to a tool call
+func (i *Injector) ToolLatency() time.Duration {
+	if i == nil || i.cfg.ToolLatency <= 0 {
+		return 0
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return time.Duration(i.rng.Int63n(int64(i.cfg.ToolLatency)))
+}
+
+// DropResponse reports whether a tool response should never be delivered
+func (i *Injector) DropResponse() bool {
+	return i.roll(i.config().DropRate)
+}
+
+// DropTimeout returns how long a dropped call waits before failing
+func (i *Injector) DropTimeout() time.Duration {
+	if timeout := i.config().DropTimeout; timeout > 0 {
+		return timeout
+	}
+	return defaultDropTimeout
+}
+
+// MalformResponse reports whether a tool result should be corrupted
+func (i *Injector) MalformResponse() bool {
+	return i.roll(i.config().MalformedRate)
+}
+
+// FailModelRequest reports whether a model request should fail with HTTP 500
+func (i *Injector) FailModelRequest() bool {
+	return i.roll(i.config().ModelErrorRate)
+}
+
+// Summary describes the active faults for logs and notifications
+func (i *Injector) Summary() string {
+	if i == nil {
+		return "disabled"
+	}
+	var parts []string
+	if i.cfg.ToolLatency > 0 {
+		parts = append(parts, "tool latency up to "+i.cfg.ToolLatency.String())
+	}
+	if i.cfg.DropRate > 0 {
+		parts = append(parts, percent(i.cfg.DropRate)+" dropped tool responses")
+	}
+	if i.cfg.MalformedRate > 0 {
+		parts = append(parts, percent(i.cfg.MalformedRate)+" malformed tool results")
+	}
+	if i.cfg.ModelErrorRate > 0 {
+		parts = append(parts, percent(i.cfg.ModelErrorRate)+" model HTTP 500s")
+	}
+	if len(parts) == 0 {
+		return "enabled, no faults configured"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// config returns the injector's settings, or the zero value for a nil injector
+func (i *Injector) config() config.ChaosConfig {
+	if i == nil {
+		return config.ChaosConfig{}
+	}
+	return i.cfg
+}
+
+// percent formats a rate such as 0.25 as "25%"
+func percent(rate float64) string {
+	return fmt.Sprintf("%g%%", math.Round(rate*1000)/10)
+}
+
+// CorruptJSON truncates s so that any JSON it contains no longer parses
+func CorruptJSON(s string) string {
+	if len(s) < 2 {
+		return "{"
+	}
+	return s[:len(s)/2]
+}
+
+// Transport wraps base so that model requests fail with HTTP 500 at the
+// configured rate. It returns base unchanged when the injector is nil.
+func (i *Injector) Transport(base http.RoundTripper) http.RoundTripper {
+	if i == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &faultTransport{base: base, injector: i}
+}
+
+// faultTransport is an http.RoundTripper that injects server errors
+type faultTransport struct {
+	base     http.RoundTripper
+	injector *Injector
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.injector.FailModelRequest() {
+		return t.base.RoundTrip(req)
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	body := `{"error":"chaos: injected internal server error"}`
+	return &http.Response{
+		Status:        "500 Internal Server Error",
+		StatusCode:    http.StatusInternalServerError,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
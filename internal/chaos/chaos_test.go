@@ -0,0 +1,93 @@
+package chaos
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_DisabledReturnsNil(t *testing.T) {
+	injector := New(config.ChaosConfig{DropRate: 1})
+	assert.Nil(t, injector)
+
+	// A nil injector never injects anything
+	assert.False(t, injector.DropResponse())
+	assert.False(t, injector.MalformResponse())
+	assert.False(t, injector.FailModelRequest())
+	assert.Zero(t, injector.ToolLatency())
+	assert.Equal(t, "disabled", injector.Summary())
+	assert.Nil(t, injector.Transport(nil))
+}
+
+func TestInjector_Rates(t *testing.T) {
+	always := New(config.ChaosConfig{Enabled: true, Seed: 1, DropRate: 1, MalformedRate: 1, ModelErrorRate: 1})
+	never := New(config.ChaosConfig{Enabled: true, Seed: 1})
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, always.DropResponse())
+		assert.True(t, always.MalformResponse())
+		assert.True(t, always.FailModelRequest())
+
+		assert.False(t, never.DropResponse())
+		assert.False(t, never.MalformResponse())
+		assert.False(t, never.FailModelRequest())
+	}
+}
+
+func TestInjector_ToolLatency(t *testing.T) {
+	injector := New(config.ChaosConfig{Enabled: true, Seed: 1, ToolLatency: 50 * time.Millisecond})
+
+	for i := 0; i < 100; i++ {
+		delay := injector.ToolLatency()
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.Less(t, delay, 50*time.Millisecond)
+	}
+}
+
+func TestInjector_DropTimeout(t *testing.T) {
+	assert.Equal(t, defaultDropTimeout, New(config.ChaosConfig{Enabled: true}).DropTimeout())
+	assert.Equal(t, time.Second, New(config.ChaosConfig{Enabled: true, DropTimeout: time.Second}).DropTimeout())
+}
+
+func TestInjector_Summary(t *testing.T) {
+	injector := New(config.ChaosConfig{Enabled: true, ToolLatency: 2 * time.Second, DropRate: 0.1, ModelErrorRate: 0.25})
+	assert.Equal(t, "tool latency up to 2s, 10% dropped tool responses, 25% model HTTP 500s", injector.Summary())
+
+	assert.Equal(t, "enabled, no faults configured", New(config.ChaosConfig{Enabled: true}).Summary())
+}
+
+func TestTransport_InjectsServerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	failing := &http.Client{Transport: New(config.ChaosConfig{Enabled: true, ModelErrorRate: 1}).Transport(nil)}
+	resp, err := failing.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "chaos")
+
+	passing := &http.Client{Transport: New(config.ChaosConfig{Enabled: true}).Transport(nil)}
+	resp, err = passing.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestCorruptJSON(t *testing.T) {
+	for _, input := range []string{`{"results":[{"id":1},{"id":2}]}`, `[]`, `{}`, ``} {
+		var v interface{}
+		assert.Error(t, json.Unmarshal([]byte(CorruptJSON(input)), &v), "input %q", input)
+	}
+}
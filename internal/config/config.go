@@ -1,11 +1,20 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -16,18 +25,42 @@ type Config struct {
 	TUI     TUIConfig     `mapstructure:"tui" yaml:"tui"`
 	MCP     MCPConfig     `mapstructure:"mcp" yaml:"mcp"`
 	Storage StorageConfig `mapstructure:"storage" yaml:"storage"`
+	Memory  MemoryConfig  `mapstructure:"memory" yaml:"memory"`
+	Index   IndexConfig   `mapstructure:"index" yaml:"index"`
+	Tools   ToolsConfig   `mapstructure:"tools" yaml:"tools"`
+	Agent   AgentConfig   `mapstructure:"agent" yaml:"agent"`
+	Share   ShareConfig   `mapstructure:"share" yaml:"share"`
+	Serve   ServeConfig   `mapstructure:"serve" yaml:"serve"`
 	Logging LoggingConfig `mapstructure:"logging" yaml:"logging"`
+	Chaos   ChaosConfig   `mapstructure:"chaos" yaml:"chaos"`
 
-	configFile string // Track which config file was loaded
+	configFile         string          // Track which config file was loaded
+	profile            string          // Name of the active profile
+	profileFile        string          // Profile file merged over the global config, if any
+	profileKeys        map[string]bool // Keys set by the profile file
+	workspaceFile      string          // Workspace file merged over the global config, if any
+	workspaceOverrides []string        // Keys set by the workspace file
+	settings           []Setting       // Every effective value with its source
+	flags              *pflag.FlagSet  // Command-line flags the configuration was loaded with
 }
 
 // ModelConfig contains model-specific settings
 type ModelConfig struct {
-	Type          string  `mapstructure:"type" yaml:"type"`
-	Name          string  `mapstructure:"name" yaml:"name"`
-	Temperature   float64 `mapstructure:"temperature" yaml:"temperature"`
-	MaxTokens     int     `mapstructure:"max_tokens" yaml:"max_tokens"`
-	ContextLength int     `mapstructure:"context_length" yaml:"context_length"`
+	Type           string        `mapstructure:"type" yaml:"type"`
+	Name           string        `mapstructure:"name" yaml:"name"`
+	Temperature    float64       `mapstructure:"temperature" yaml:"temperature"`
+	MaxTokens      int           `mapstructure:"max_tokens" yaml:"max_tokens"`
+	ContextLength  int           `mapstructure:"context_length" yaml:"context_length"`
+	EmbeddingModel string        `mapstructure:"embedding_model" yaml:"embedding_model"` // Ollama model used for semantic search
+	IncludePinned  bool          `mapstructure:"include_pinned" yaml:"include_pinned"`   // Send pinned messages with every request
+	Pricing        PricingConfig `mapstructure:"pricing" yaml:"pricing"`
+}
+
+// PricingConfig is what a hosted model charges, used to show what requests
+// cost. Both prices are zero for local models.
+type PricingConfig struct {
+	InputPerMillion  float64 `mapstructure:"input_per_million" yaml:"input_per_million"`   // Price of a million prompt tokens
+	OutputPerMillion float64 `mapstructure:"output_per_million" yaml:"output_per_million"` // Price of a million generated tokens
 }
 
 // OllamaConfig contains Ollama-specific settings
@@ -41,6 +74,8 @@ type TUIConfig struct {
 	Theme      string `mapstructure:"theme" yaml:"theme"`
 	ShowHints  bool   `mapstructure:"show_hints" yaml:"show_hints"`
 	AutoScroll bool   `mapstructure:"auto_scroll" yaml:"auto_scroll"`
+	RenderMode string `mapstructure:"render_mode" yaml:"render_mode"`
+	Accessible bool   `mapstructure:"accessible" yaml:"accessible"`
 }
 
 // MCPConfig contains MCP server settings
@@ -56,55 +91,431 @@ type ServerConfig struct {
 	Args      []string          `mapstructure:"args" yaml:"args"`
 	Env       map[string]string `mapstructure:"env" yaml:"env"`
 	Transport string            `mapstructure:"transport" yaml:"transport"`
+	URL       string            `mapstructure:"url" yaml:"url,omitempty"`         // Endpoint of http servers
+	Headers   map[string]string `mapstructure:"headers" yaml:"headers,omitempty"` // Sent with every request to http servers
 	Timeout   time.Duration     `mapstructure:"timeout" yaml:"timeout"`
+	Enabled   *bool             `mapstructure:"enabled" yaml:"enabled,omitempty"` // false keeps the server configured without connecting to it
+	Sandbox   *SandboxConfig    `mapstructure:"sandbox" yaml:"sandbox,omitempty"` // Runs a stdio server with reduced privileges
+}
+
+// SandboxConfig limits what a stdio server can reach, for servers that
+// aren't trusted. A sandboxed server runs in its own process group, which is
+// killed as a whole when the server stops, and sees only the environment
+// variables listed in InheritEnv besides its own env. Limits of 0 aren't
+// applied; limits are only supported on Linux.
+type SandboxConfig struct {
+	InheritEnv []string `mapstructure:"inherit_env" yaml:"inherit_env,omitempty" json:"inherit_env,omitempty"` // Variables passed on from othello's environment; PATH, HOME, USER, LANG, and TMPDIR when empty
+	MemoryMB   int      `mapstructure:"memory_mb" yaml:"memory_mb,omitempty" json:"memory_mb,omitempty"`       // Most memory the server may allocate
+	CPUSeconds int      `mapstructure:"cpu_seconds" yaml:"cpu_seconds,omitempty" json:"cpu_seconds,omitempty"` // Most CPU time the server may use before it's killed
+	MaxFiles   int      `mapstructure:"max_files" yaml:"max_files,omitempty" json:"max_files,omitempty"`       // Most files the server may have open at once
+}
+
+// validate checks the sandbox of a server using transport
+func (s *SandboxConfig) validate(transport string) error {
+	if s == nil {
+		return nil
+	}
+	if transport != "stdio" {
+		return fmt.Errorf("sandbox only applies to stdio servers")
+	}
+	if s.MemoryMB < 0 || s.CPUSeconds < 0 || s.MaxFiles < 0 {
+		return fmt.Errorf("sandbox limits cannot be negative")
+	}
+	return nil
+}
+
+// IsEnabled reports whether the agent connects to the server. Servers are
+// enabled unless set to enabled: false.
+func (s ServerConfig) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
 }
 
 // StorageConfig contains storage settings
 type StorageConfig struct {
-	HistorySize int           `mapstructure:"history_size" yaml:"history_size"`
-	CacheTTL    time.Duration `mapstructure:"cache_ttl" yaml:"cache_ttl"`
-	DataDir     string        `mapstructure:"data_dir" yaml:"data_dir"`
+	HistorySize int             `mapstructure:"history_size" yaml:"history_size"`
+	CacheTTL    time.Duration   `mapstructure:"cache_ttl" yaml:"cache_ttl"`
+	DataDir     string          `mapstructure:"data_dir" yaml:"data_dir"`
+	Retention   RetentionConfig `mapstructure:"retention" yaml:"retention"`
+}
+
+// RetentionConfig limits how much conversation history is kept. The limits
+// default to zero, which keeps everything.
+type RetentionConfig struct {
+	MaxConversations int           `mapstructure:"max_conversations" yaml:"max_conversations"` // Most conversations kept
+	MaxAge           time.Duration `mapstructure:"max_age" yaml:"max_age"`                     // Conversations not updated for longer are deleted
+	MaxSizeMB        int           `mapstructure:"max_size_mb" yaml:"max_size_mb"`             // Database size kept under by deleting the oldest conversations
+	PruneInterval    time.Duration `mapstructure:"prune_interval" yaml:"prune_interval"`       // How often history is pruned while the TUI runs; 0 disables it
+	VacuumInterval   time.Duration `mapstructure:"vacuum_interval" yaml:"vacuum_interval"`     // Least time between vacuums that shrink the database file
+}
+
+// MemoryConfig controls the built-in memory, which keeps facts across
+// conversations in the local database without an MCP memory server
+type MemoryConfig struct {
+	Enabled     bool `mapstructure:"enabled" yaml:"enabled"`           // Offer the memory tools to the model
+	AutoRecall  bool `mapstructure:"auto_recall" yaml:"auto_recall"`   // Send memories related to each message with it
+	RecallLimit int  `mapstructure:"recall_limit" yaml:"recall_limit"` // Most memories sent with a message
+}
+
+// IndexConfig controls the project index, which lets the model see the parts
+// of local project files related to each message
+type IndexConfig struct {
+	Dirs          []string `mapstructure:"dirs" yaml:"dirs"`                     // Directories indexed when the TUI starts; .gitignore files are respected
+	AutoRetrieve  bool     `mapstructure:"auto_retrieve" yaml:"auto_retrieve"`   // Send related parts of indexed files with each message
+	RetrieveLimit int      `mapstructure:"retrieve_limit" yaml:"retrieve_limit"` // Most parts sent with a message
+	MaxFileSize   int      `mapstructure:"max_file_size" yaml:"max_file_size"`   // Larger files, in bytes, are not indexed
+}
+
+// ToolsConfig controls the tools built into the agent, which work without
+// any MCP server
+type ToolsConfig struct {
+	Shell     ShellToolConfig     `mapstructure:"shell" yaml:"shell"`
+	Files     FilesToolConfig     `mapstructure:"files" yaml:"files"`
+	Fetch     FetchToolConfig     `mapstructure:"fetch" yaml:"fetch"`
+	Clipboard ClipboardToolConfig `mapstructure:"clipboard" yaml:"clipboard"`
+}
+
+// ShellToolConfig controls the run_command tool, which runs a program with
+// arguments but no shell, so pipes, redirects, and globs are not expanded
+type ShellToolConfig struct {
+	Enabled         bool          `mapstructure:"enabled" yaml:"enabled"`                   // Offer run_command to the model
+	RequireApproval bool          `mapstructure:"require_approval" yaml:"require_approval"` // Run only commands the user approved in the chat
+	AllowedCommands []string      `mapstructure:"allowed_commands" yaml:"allowed_commands"` // Programs that may be run, by name or glob pattern; "*" allows any
+	Timeout         time.Duration `mapstructure:"timeout" yaml:"timeout"`                   // Commands running longer are killed
+	MaxOutput       int           `mapstructure:"max_output" yaml:"max_output"`             // Most bytes of output returned; the rest is cut
+	WorkingDir      string        `mapstructure:"working_dir" yaml:"working_dir"`           // Directory commands run in; "" uses the current directory
+}
+
+// FilesToolConfig controls the read_file, write_file, list_directory, and
+// search_files tools, which only reach files inside the configured roots
+type FilesToolConfig struct {
+	Enabled    bool     `mapstructure:"enabled" yaml:"enabled"`         // Offer the file tools to the model
+	Roots      []string `mapstructure:"roots" yaml:"roots"`             // Directories the tools may use; none allows only the current directory
+	AllowWrite bool     `mapstructure:"allow_write" yaml:"allow_write"` // Offer write_file
+	MaxRead    int      `mapstructure:"max_read" yaml:"max_read"`       // Most bytes read_file returns; the rest is cut
+}
+
+// FetchToolConfig controls the fetch_url tool, which downloads web pages
+// from allowed domains and converts HTML to markdown for the model
+type FetchToolConfig struct {
+	Enabled        bool          `mapstructure:"enabled" yaml:"enabled"`                 // Offer fetch_url to the model
+	AllowedDomains []string      `mapstructure:"allowed_domains" yaml:"allowed_domains"` // Hosts that may be fetched, by name or glob pattern such as "*.python.org"; "*" allows any
+	AllowPrivate   bool          `mapstructure:"allow_private" yaml:"allow_private"`     // Fetch from this computer and the local network too
+	Timeout        time.Duration `mapstructure:"timeout" yaml:"timeout"`                 // Requests taking longer are cancelled
+	MaxSize        int           `mapstructure:"max_size" yaml:"max_size"`               // Larger responses, in bytes, are refused
+	MaxOutput      int           `mapstructure:"max_output" yaml:"max_output"`           // Most characters of a page returned; the rest is cut
+}
+
+// AgentConfig controls how the agent works through a request
+type AgentConfig struct {
+	MaxIterations   int                 `mapstructure:"max_iterations" yaml:"max_iterations"`     // Most times a message's tool results go back to the model; 0 shows them as the answer
+	PlanApproval    string              `mapstructure:"plan_approval" yaml:"plan_approval"`       // When the chat asks before running tool calls: always, never, or destructive
+	ToolRetries     int                 `mapstructure:"tool_retries" yaml:"tool_retries"`         // Most times the model may correct a tool call's arguments after an error
+	SummarizeOver   int                 `mapstructure:"summarize_over" yaml:"summarize_over"`     // Characters above which a tool result is summarized by the model before it joins the conversation; 0 never summarizes
+	DedupeCalls     bool                `mapstructure:"dedupe_calls" yaml:"dedupe_calls"`         // Answer a tool call repeated within a request with its earlier result instead of running it again
+	ProcessParallel int                 `mapstructure:"process_parallel" yaml:"process_parallel"` // Most results of a turn's tool calls processed and summarized at once
+	TurnTimeout     time.Duration       `mapstructure:"turn_timeout" yaml:"turn_timeout"`         // Time one message's model requests, tool calls, and result processing may take together; 0 allows any
+	InjectionGuard  string              `mapstructure:"injection_guard" yaml:"injection_guard"`   // How tool results are checked for text that reads as instructions to the model: off, standard, or strict
+	Offline         bool                `mapstructure:"offline" yaml:"offline"`                   // Block network access other than to the Ollama host, and run only stdio MCP servers
+	SubAgents       SubAgentConfig      `mapstructure:"subagents" yaml:"subagents"`
+	Compaction      CompactionConfig    `mapstructure:"compaction" yaml:"compaction"`
+	Intent          IntentConfig        `mapstructure:"intent" yaml:"intent"`
+	ResponseCache   ResponseCacheConfig `mapstructure:"response_cache" yaml:"response_cache"`
+	Mode            string              `mapstructure:"mode" yaml:"mode"`       // Session mode new conversations start in: chat, analysis, or automation
+	Persona         string              `mapstructure:"persona" yaml:"persona"` // Persona the chat starts with; "" uses none
+	Personas        []PersonaConfig     `mapstructure:"personas" yaml:"personas,omitempty"`
+
+	// ResultPipelines names the result transformers to run, in order, for
+	// each tool whose results should be processed differently from the
+	// default pipeline. The key "default" replaces the default pipeline.
+	ResultPipelines map[string][]string `mapstructure:"result_pipelines" yaml:"result_pipelines,omitempty"`
+
+	// ToolPolicies are checked before every tool call; a call that breaks
+	// one is refused without reaching the tool
+	ToolPolicies []ToolPolicyConfig `mapstructure:"tool_policies" yaml:"tool_policies,omitempty"`
+
+	// Hooks are programs run at points in the agent's work, which can log,
+	// change, or refuse what happens there
+	Hooks []HookConfig `mapstructure:"hooks" yaml:"hooks,omitempty"`
+
+	// Undo names, for tools whose changes can be reversed, the call that
+	// reverses them, which /undo makes
+	Undo []UndoConfig `mapstructure:"undo" yaml:"undo,omitempty"`
+}
+
+// SubAgentConfig controls sub-agents: short model loops, each with its own
+// context, that the model can start with the delegate tool to research parts
+// of a request in parallel
+type SubAgentConfig struct {
+	Enabled     bool `mapstructure:"enabled" yaml:"enabled"`           // Offer the delegate tool to the model
+	MaxParallel int  `mapstructure:"max_parallel" yaml:"max_parallel"` // Most sub-agents running at once
+	MaxTasks    int  `mapstructure:"max_tasks" yaml:"max_tasks"`       // Most tasks in one delegation
+	MaxRounds   int  `mapstructure:"max_rounds" yaml:"max_rounds"`     // Most model calls each sub-agent makes
+	TokenBudget int  `mapstructure:"token_budget" yaml:"token_budget"` // Most tokens, sent and generated, each sub-agent uses
+}
+
+// IntentConfig controls how requests are classified by intent to suggest
+// tools: by keyword, or by asking a model and using keywords when it fails
+type IntentConfig struct {
+	Classifier string        `mapstructure:"classifier" yaml:"classifier"` // keywords or model
+	Model      string        `mapstructure:"model" yaml:"model"`           // Ollama model that classifies; "" uses model.name
+	Timeout    time.Duration `mapstructure:"timeout" yaml:"timeout"`       // Longest wait for the model before using keywords
+}
+
+// ResponseCacheConfig controls how long the chat keeps its answers, so a
+// question asked again is answered without running the model and tools. Any
+// tool call empties the cache, since it may change the answer.
+type ResponseCacheConfig struct {
+	Enabled    bool          `mapstructure:"enabled" yaml:"enabled"`         // Answer repeated questions from the cache
+	TTL        time.Duration `mapstructure:"ttl" yaml:"ttl"`                 // How long an answer is kept
+	MaxEntries int           `mapstructure:"max_entries" yaml:"max_entries"` // Most answers kept; the oldest go first
+}
+
+// CompactionConfig controls how the messages of a request are compacted when
+// they come close to model.context_length: older messages are replaced with
+// a summary by the model
+type CompactionConfig struct {
+	Threshold  float64 `mapstructure:"threshold" yaml:"threshold"`     // Fraction of the context window at which to compact; 0 never compacts
+	KeepRecent int     `mapstructure:"keep_recent" yaml:"keep_recent"` // Latest messages kept as they are
+}
+
+// SessionModes are the modes a conversation can be in, which shape the
+// prompt, the tools offered, and the temperature
+var SessionModes = []string{"chat", "analysis", "automation"}
+
+// PersonaConfig is a named system prompt profile the chat can switch to
+// with /persona
+type PersonaConfig struct {
+	Name         string   `mapstructure:"name" yaml:"name"`
+	SystemPrompt string   `mapstructure:"system_prompt" yaml:"system_prompt"`
+	Tools        []string `mapstructure:"tools" yaml:"tools,omitempty"`             // Tools offered to the model first and recommended to it
+	Temperature  *float64 `mapstructure:"temperature" yaml:"temperature,omitempty"` // Replaces model.temperature while the persona is active
+}
+
+// ToolPolicyConfig limits calls to the tools matching Tool, a glob pattern
+// such as "write_*" or "*"
+type ToolPolicyConfig struct {
+	Tool            string                          `mapstructure:"tool" yaml:"tool"`
+	MaxCallsPerTurn int                             `mapstructure:"max_calls_per_turn" yaml:"max_calls_per_turn,omitempty"` // Most calls to the matching tools per request; 0 doesn't limit them
+	Arguments       map[string]ArgumentPolicyConfig `mapstructure:"arguments" yaml:"arguments,omitempty"`                   // Rules for the values of arguments, by argument name
+}
+
+// ArgumentPolicyConfig restricts the values an argument may have. Each item
+// of a list argument is checked on its own.
+type ArgumentPolicyConfig struct {
+	Pattern string   `mapstructure:"pattern" yaml:"pattern,omitempty"` // Regular expression values must match
+	Paths   []string `mapstructure:"paths" yaml:"paths,omitempty"`     // Directories path values must be inside
+	Banned  []string `mapstructure:"banned" yaml:"banned,omitempty"`   // Values refused, compared ignoring case
+}
+
+// HookEvents are the points in the agent's work hooks can run at
+var HookEvents = []string{"pre_tool_call", "post_tool_call", "pre_prompt", "on_response"}
+
+// HookConfig runs Command at Event. The program reads the event as JSON on
+// stdin and may write the event back, changed, on stdout.
+type HookConfig struct {
+	Event   string        `mapstructure:"event" yaml:"event"`               // One of HookEvents
+	Command []string      `mapstructure:"command" yaml:"command"`           // Program and arguments
+	Tools   []string      `mapstructure:"tools" yaml:"tools,omitempty"`     // Tool names or glob patterns the tool events run for; empty runs for all
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout,omitempty"` // Longest the program may run; 0 uses 10s
+}
+
+// UndoConfig reverses calls to Tool by calling Inverse with Arguments. The
+// arguments are templates that refer to the call's arguments, the fields of
+// a JSON object result, and the whole result as {{.name}}, {{.id}}, and
+// {{.result}}.
+type UndoConfig struct {
+	Tool      string                 `mapstructure:"tool" yaml:"tool"`
+	Inverse   string                 `mapstructure:"inverse" yaml:"inverse"`
+	Arguments map[string]interface{} `mapstructure:"arguments" yaml:"arguments,omitempty"`
+}
+
+// ShareConfig controls /share, which writes a sanitized transcript of the
+// conversation and can upload it
+type ShareConfig struct {
+	Upload   string        `mapstructure:"upload" yaml:"upload"`             // Where transcripts are uploaded: none, paste, or gist
+	Endpoint string        `mapstructure:"endpoint" yaml:"endpoint"`         // URL transcripts are posted to; "" with gist uses the GitHub API
+	Token    string        `mapstructure:"token" yaml:"token" secret:"true"` // Sent as a bearer token; use ${secret:name} to keep it out of the file
+	Timeout  time.Duration `mapstructure:"timeout" yaml:"timeout"`           // Uploads taking longer are cancelled
+}
+
+// ServeConfig controls `othello serve`
+type ServeConfig struct {
+	// Webhooks are sent a JSON payload when events happen while serving
+	Webhooks []WebhookConfig `mapstructure:"webhooks" yaml:"webhooks,omitempty"`
+
+	// Slack and Discord answer messages sent to `othello serve slack` and
+	// `othello serve discord`
+	Slack   SlackBridgeConfig   `mapstructure:"slack" yaml:"slack"`
+	Discord DiscordBridgeConfig `mapstructure:"discord" yaml:"discord"`
+}
+
+// SlackBridgeConfig connects `othello serve slack` to a Slack app that sends
+// it messages through the Events API
+type SlackBridgeConfig struct {
+	BotToken      string   `mapstructure:"bot_token" yaml:"bot_token" secret:"true"`           // xoxb- token replies are posted with; use ${secret:name} to keep it out of the file
+	SigningSecret string   `mapstructure:"signing_secret" yaml:"signing_secret" secret:"true"` // Checks that requests come from Slack
+	Channels      []string `mapstructure:"channels" yaml:"channels,omitempty"`                 // IDs of the channels answered; empty answers every channel the app is in
+}
+
+// discordCommandPattern matches the names Discord allows for slash commands
+var discordCommandPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// DiscordBridgeConfig connects `othello serve discord` to a Discord
+// application whose slash command is sent to its interactions endpoint
+type DiscordBridgeConfig struct {
+	ApplicationID string   `mapstructure:"application_id" yaml:"application_id"`
+	PublicKey     string   `mapstructure:"public_key" yaml:"public_key"`       // Hex Ed25519 key that checks requests come from Discord
+	Command       string   `mapstructure:"command" yaml:"command"`             // Name of the slash command answered
+	Channels      []string `mapstructure:"channels" yaml:"channels,omitempty"` // IDs of the channels answered; empty answers every channel
+}
+
+// WebhookEvents are the events webhooks can be sent
+var WebhookEvents = []string{"conversation_completed", "tool_failed", "scheduled_task"}
+
+// WebhookConfig posts the events it is sent to URL. A delivery that fails
+// is tried again, waiting longer each time.
+type WebhookConfig struct {
+	URL     string        `mapstructure:"url" yaml:"url"`
+	Events  []string      `mapstructure:"events" yaml:"events,omitempty"`               // Some of WebhookEvents; empty sends all
+	Secret  string        `mapstructure:"secret" yaml:"secret,omitempty" secret:"true"` // Key the payload is signed with as HMAC-SHA256 in X-Othello-Signature
+	Retries *int          `mapstructure:"retries" yaml:"retries,omitempty"`             // Times a failed delivery is tried again; unset uses 3, and 0 never retries
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout,omitempty"`             // Longest one attempt may take; 0 uses 10s
+}
+
+// ClipboardToolConfig controls the read_clipboard and write_clipboard tools,
+// which use the system clipboard
+type ClipboardToolConfig struct {
+	Enabled         bool `mapstructure:"enabled" yaml:"enabled"`                   // Offer the clipboard tools to the model
+	RequireApproval bool `mapstructure:"require_approval" yaml:"require_approval"` // Use the clipboard only when the user approved the call in the chat
+	MaxRead         int  `mapstructure:"max_read" yaml:"max_read"`                 // Most characters read_clipboard returns; the rest is cut
 }
 
 // LoggingConfig contains logging settings
 type LoggingConfig struct {
-	Level  string `mapstructure:"level" yaml:"level"`
-	File   string `mapstructure:"file" yaml:"file"`
-	Format string `mapstructure:"format" yaml:"format"`
+	Level            string        `mapstructure:"level" yaml:"level"`
+	File             string        `mapstructure:"file" yaml:"file"`
+	Format           string        `mapstructure:"format" yaml:"format"`
+	WatchdogInterval time.Duration `mapstructure:"watchdog_interval" yaml:"watchdog_interval"` // How often memory and goroutines are checked for abnormal growth while running; 0 disables it
 }
 
+// ChaosConfig injects faults for resilience testing. It is deliberately absent
+// from the defaults and the generated config file; add a chaos section by hand
+// to enable it.
+type ChaosConfig struct {
+	Enabled        bool          `mapstructure:"enabled" yaml:"enabled"`
+	Seed           int64         `mapstructure:"seed" yaml:"seed"`                         // 0 picks a random seed
+	ToolLatency    time.Duration `mapstructure:"tool_latency" yaml:"tool_latency"`         // Maximum random delay added to each tool call
+	DropRate       float64       `mapstructure:"drop_rate" yaml:"drop_rate"`               // Fraction of MCP tool responses never delivered
+	DropTimeout    time.Duration `mapstructure:"drop_timeout" yaml:"drop_timeout"`         // How long a dropped call waits before failing
+	MalformedRate  float64       `mapstructure:"malformed_rate" yaml:"malformed_rate"`     // Fraction of tool results with truncated JSON
+	ModelErrorRate float64       `mapstructure:"model_error_rate" yaml:"model_error_rate"` // Fraction of Ollama requests answered with HTTP 500
+}
+
+// noConfigFile is reported by ConfigFile when only defaults were loaded
+const noConfigFile = "defaults (no config file found)"
+
 // ConfigFile returns the path to the configuration file that was loaded
 func (c *Config) ConfigFile() string {
 	return c.configFile
 }
 
-// Load loads the configuration from various sources
+// HasConfigFile reports whether a configuration file was found and loaded
+func (c *Config) HasConfigFile() bool {
+	return c.configFile != "" && c.configFile != noConfigFile
+}
+
+// Profile returns the name of the active profile
+func (c *Config) Profile() string {
+	if c.profile == "" {
+		return DefaultProfile
+	}
+	return c.profile
+}
+
+// ProfileFile returns the path to the profile file that was merged, or "" if none
+func (c *Config) ProfileFile() string {
+	return c.profileFile
+}
+
+// WorkspaceFile returns the path to the workspace file that was merged, or "" if none
+func (c *Config) WorkspaceFile() string {
+	return c.workspaceFile
+}
+
+// WorkspaceOverrides returns the keys whose values come from the workspace file
+func (c *Config) WorkspaceOverrides() []string {
+	return c.workspaceOverrides
+}
+
+// IsWorkspaceOverride reports whether key was set by the workspace file
+func (c *Config) IsWorkspaceOverride(key string) bool {
+	for _, overridden := range c.workspaceOverrides {
+		if overridden == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigFileEnvVar names a config file to read instead of searching for
+// config.yaml. The --config flag sets it.
+const ConfigFileEnvVar = "OTHELLO_CONFIG"
+
+// Load loads the configuration from various sources.
+// Precedence, lowest to highest: built-in defaults, config.yaml, the active
+// profile's config.yaml, workspace.yaml, OTHELLO_* environment variables.
+// config.yaml is searched for in ., ~/.othello, and /etc/othello, unless
+// ConfigFileEnvVar names the file to use.
 func Load() (*Config, error) {
+	return LoadWithFlags(nil)
+}
+
+// LoadWithFlags loads the configuration like Load, letting any known
+// command-line flags in flags take precedence over every other source
+func LoadWithFlags(flags *pflag.FlagSet) (*Config, error) {
 	v := viper.New()
 
 	// Set configuration file properties
 	v.SetConfigName("config")
 	v.SetConfigType("yaml")
 
-	// Add search paths for configuration files
-	v.AddConfigPath(".")
-	
-	// Add ~/.othello directory
-	homeDir, err := os.UserHomeDir()
-	if err == nil {
-		v.AddConfigPath(filepath.Join(homeDir, ".othello"))
+	if path := strings.TrimSpace(os.Getenv(ConfigFileEnvVar)); path != "" {
+		// A named config file replaces the search paths, and must exist
+		path, err := expandHome(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("config file %s does not exist", path)
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		v.SetConfigFile(path)
+	} else {
+		// Add search paths for configuration files
+		v.AddConfigPath(".")
+
+		// Add ~/.othello directory
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			v.AddConfigPath(filepath.Join(homeDir, ".othello"))
+		}
+
+		// Add system config directory
+		v.AddConfigPath("/etc/othello")
 	}
-	
-	// Add system config directory
-	v.AddConfigPath("/etc/othello")
 
 	// Set defaults
 	setDefaults(v)
 
-	// Set environment variable support
-	v.SetEnvPrefix("OTHELLO")
+	// Set environment variable support (model.name is read from OTHELLO_MODEL_NAME)
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
+	if err := bindFlags(v, flags); err != nil {
+		return nil, err
+	}
+
 	// Read configuration file
 	var configFile string
 	if err := v.ReadInConfig(); err != nil {
@@ -112,9 +523,41 @@ func Load() (*Config, error) {
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
 		// Config file not found, will use defaults
-		configFile = "defaults (no config file found)"
+		configFile = noConfigFile
 	} else {
 		configFile = v.ConfigFileUsed()
+		if err := validateFile(configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	// Remember which keys the global config file set, before workspace values are merged in
+	fileKeys := make(map[string]bool)
+	for _, key := range v.AllKeys() {
+		if v.InConfig(key) {
+			fileKeys[key] = true
+		}
+	}
+
+	// Merge the settings of the active profile
+	profile := ActiveProfile()
+	profilePath, profileKeys, err := applyProfile(v, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge per-project overrides from the nearest workspace.yaml
+	var workspaceFile string
+	var workspaceOverrides []string
+	if wd, err := os.Getwd(); err == nil {
+		if path, ok := FindWorkspaceFile(wd); ok {
+			overrides, err := applyWorkspace(v, path)
+			if err != nil {
+				return nil, err
+			}
+			workspaceFile = path
+			workspaceOverrides = overrides
+		}
 	}
 
 	// Unmarshal configuration
@@ -123,7 +566,20 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
+	// Resolve ${env:NAME} and ${file:PATH} references and expand "~"
+	if err := interpolateConfig(&config); err != nil {
+		return nil, fmt.Errorf("error resolving config: %w", err)
+	}
+	registerSecretSettings(&config)
+
 	config.configFile = configFile
+	config.flags = flags
+	config.profile = profile
+	config.profileFile = profilePath
+	config.profileKeys = profileKeys
+	config.workspaceFile = workspaceFile
+	config.workspaceOverrides = workspaceOverrides
+	config.resolveSources(v, fileKeys, flags)
 
 	// Validate configuration
 	if err := config.validate(); err != nil {
@@ -133,27 +589,116 @@ func Load() (*Config, error) {
 	return &config, nil
 }
 
+// Reload loads the configuration again from the same sources, so changes
+// made to the files since are picked up
+func (c *Config) Reload() (*Config, error) {
+	return LoadWithFlags(c.flags)
+}
+
+// WatchedFiles returns the files the configuration was read from: the config
+// file, the profile file, and the workspace file, when they exist
+func (c *Config) WatchedFiles() []string {
+	var files []string
+	if c.HasConfigFile() {
+		files = append(files, c.configFile)
+	}
+	for _, file := range []string{c.profileFile, c.workspaceFile} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
 // setDefaults sets default configuration values
 func setDefaults(v *viper.Viper) {
 	// Model defaults
 	v.SetDefault("model.type", "ollama")
-	v.SetDefault("model.name", "qwen2.5:3b")
+	v.SetDefault("model.name", DefaultModelName)
 	v.SetDefault("model.temperature", 0.7)
 	v.SetDefault("model.max_tokens", 2048)
 	v.SetDefault("model.context_length", 8192)
+	v.SetDefault("model.embedding_model", DefaultEmbeddingModel)
+	v.SetDefault("model.include_pinned", false)
+	v.SetDefault("model.pricing.input_per_million", 0.0)
+	v.SetDefault("model.pricing.output_per_million", 0.0)
 
 	// Ollama defaults
-	v.SetDefault("ollama.host", "http://localhost:11434")
+	v.SetDefault("ollama.host", DefaultOllamaHost)
 	v.SetDefault("ollama.timeout", "30s")
 
 	// TUI defaults
 	v.SetDefault("tui.theme", "default")
 	v.SetDefault("tui.show_hints", true)
 	v.SetDefault("tui.auto_scroll", true)
+	v.SetDefault("tui.render_mode", "auto")
+	v.SetDefault("tui.accessible", false)
 
 	// Storage defaults
 	v.SetDefault("storage.history_size", 1000)
 	v.SetDefault("storage.cache_ttl", "1h")
+	v.SetDefault("storage.retention.max_conversations", 0)
+	v.SetDefault("storage.retention.max_age", "0s")
+	v.SetDefault("storage.retention.max_size_mb", 0)
+	v.SetDefault("storage.retention.prune_interval", "1h")
+	v.SetDefault("storage.retention.vacuum_interval", "168h")
+	
+	// Memory defaults
+	v.SetDefault("memory.enabled", true)
+	v.SetDefault("memory.auto_recall", true)
+	v.SetDefault("memory.recall_limit", 5)
+
+	// Project index defaults
+	v.SetDefault("index.auto_retrieve", true)
+	v.SetDefault("index.retrieve_limit", 4)
+	v.SetDefault("index.max_file_size", 262144)
+
+	// Built-in tool defaults
+	v.SetDefault("tools.shell.enabled", true)
+	v.SetDefault("tools.shell.require_approval", true)
+	v.SetDefault("tools.shell.allowed_commands", DefaultShellCommands)
+	v.SetDefault("tools.shell.timeout", "30s")
+	v.SetDefault("tools.shell.max_output", 16384)
+	v.SetDefault("tools.shell.working_dir", "")
+	v.SetDefault("tools.files.enabled", true)
+	v.SetDefault("tools.files.roots", []string{})
+	v.SetDefault("tools.files.allow_write", true)
+	v.SetDefault("tools.files.max_read", 65536)
+	v.SetDefault("tools.fetch.enabled", true)
+	v.SetDefault("tools.fetch.allowed_domains", DefaultFetchDomains)
+	v.SetDefault("tools.fetch.allow_private", false)
+	v.SetDefault("tools.fetch.timeout", "20s")
+	v.SetDefault("tools.fetch.max_size", 2097152)
+	v.SetDefault("tools.fetch.max_output", 20000)
+	v.SetDefault("tools.clipboard.enabled", true)
+	v.SetDefault("tools.clipboard.require_approval", true)
+	v.SetDefault("tools.clipboard.max_read", 20000)
+	
+	// Sub-agent defaults
+	v.SetDefault("agent.max_iterations", 5)
+	v.SetDefault("agent.plan_approval", "destructive")
+	v.SetDefault("agent.tool_retries", 2)
+	v.SetDefault("agent.summarize_over", 6000)
+	v.SetDefault("agent.dedupe_calls", true)
+	v.SetDefault("agent.process_parallel", 4)
+	v.SetDefault("agent.turn_timeout", "5m")
+	v.SetDefault("agent.injection_guard", "standard")
+	v.SetDefault("agent.offline", false)
+	v.SetDefault("agent.subagents.enabled", true)
+	v.SetDefault("agent.subagents.max_parallel", 3)
+	v.SetDefault("agent.subagents.max_tasks", 5)
+	v.SetDefault("agent.subagents.max_rounds", 4)
+	v.SetDefault("agent.subagents.token_budget", 8000)
+	v.SetDefault("agent.compaction.threshold", 0.8)
+	v.SetDefault("agent.compaction.keep_recent", 4)
+	v.SetDefault("agent.intent.classifier", "keywords")
+	v.SetDefault("agent.intent.model", "")
+	v.SetDefault("agent.intent.timeout", "10s")
+	v.SetDefault("agent.response_cache.enabled", true)
+	v.SetDefault("agent.response_cache.ttl", "2m")
+	v.SetDefault("agent.response_cache.max_entries", 100)
+	v.SetDefault("agent.mode", "chat")
+	v.SetDefault("agent.persona", "")
 	
 	// Set default data directory
 	homeDir, err := os.UserHomeDir()
@@ -163,9 +708,23 @@ func setDefaults(v *viper.Viper) {
 		v.SetDefault("storage.data_dir", ".othello")
 	}
 
+	// Share defaults
+	v.SetDefault("share.upload", "none")
+	v.SetDefault("share.endpoint", "")
+	v.SetDefault("share.token", "")
+	v.SetDefault("share.timeout", "30s")
+
+	// Serve defaults
+	v.SetDefault("serve.slack.bot_token", "")
+	v.SetDefault("serve.slack.signing_secret", "")
+	v.SetDefault("serve.discord.application_id", "")
+	v.SetDefault("serve.discord.public_key", "")
+	v.SetDefault("serve.discord.command", "ask")
+
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "text")
+	v.SetDefault("logging.watchdog_interval", "1m")
 	
 	// Set default log file path
 	if homeDir, err := os.UserHomeDir(); err == nil {
@@ -193,6 +752,9 @@ func (c *Config) validate() error {
 	if c.Model.MaxTokens <= 0 {
 		return fmt.Errorf("model.max_tokens must be positive")
 	}
+	if c.Model.Pricing.InputPerMillion < 0 || c.Model.Pricing.OutputPerMillion < 0 {
+		return fmt.Errorf("model.pricing prices cannot be negative")
+	}
 
 	// Validate Ollama configuration
 	if c.Ollama.Host == "" {
@@ -202,6 +764,14 @@ func (c *Config) validate() error {
 		return fmt.Errorf("ollama.timeout must be positive")
 	}
 
+	// Validate TUI configuration
+	validRenderModes := map[string]bool{
+		"": true, "auto": true, "full": true, "plain": true,
+	}
+	if !validRenderModes[c.TUI.RenderMode] {
+		return fmt.Errorf("tui.render_mode must be one of: auto, full, plain")
+	}
+
 	// Validate storage configuration
 	if c.Storage.HistorySize <= 0 {
 		return fmt.Errorf("storage.history_size must be positive")
@@ -209,6 +779,228 @@ func (c *Config) validate() error {
 	if c.Storage.CacheTTL <= 0 {
 		return fmt.Errorf("storage.cache_ttl must be positive")
 	}
+	retention := c.Storage.Retention
+	if retention.MaxConversations < 0 || retention.MaxSizeMB < 0 {
+		return fmt.Errorf("storage.retention limits cannot be negative")
+	}
+	if retention.MaxAge < 0 || retention.PruneInterval < 0 || retention.VacuumInterval < 0 {
+		return fmt.Errorf("storage.retention durations cannot be negative")
+	}
+
+	// Validate MCP server configuration
+	for i, server := range c.MCP.Servers {
+		if err := server.Sandbox.validate(server.Transport); err != nil {
+			return fmt.Errorf("mcp.servers[%d]: %w", i, err)
+		}
+	}
+
+	// Validate memory configuration
+	if c.Memory.RecallLimit < 0 {
+		return fmt.Errorf("memory.recall_limit cannot be negative")
+	}
+
+	// Validate project index configuration
+	if c.Index.RetrieveLimit < 0 {
+		return fmt.Errorf("index.retrieve_limit cannot be negative")
+	}
+	if c.Index.MaxFileSize <= 0 {
+		return fmt.Errorf("index.max_file_size must be positive")
+	}
+
+	// Validate built-in tool configuration
+	if c.Tools.Shell.Timeout <= 0 {
+		return fmt.Errorf("tools.shell.timeout must be positive")
+	}
+	if c.Tools.Shell.MaxOutput <= 0 {
+		return fmt.Errorf("tools.shell.max_output must be positive")
+	}
+	if c.Tools.Files.MaxRead <= 0 {
+		return fmt.Errorf("tools.files.max_read must be positive")
+	}
+	for _, command := range c.Tools.Shell.AllowedCommands {
+		if _, err := path.Match(command, ""); err != nil {
+			return fmt.Errorf("tools.shell.allowed_commands: invalid pattern %q", command)
+		}
+	}
+	if c.Tools.Fetch.Timeout <= 0 {
+		return fmt.Errorf("tools.fetch.timeout must be positive")
+	}
+	if c.Tools.Fetch.MaxSize <= 0 || c.Tools.Fetch.MaxOutput <= 0 {
+		return fmt.Errorf("tools.fetch.max_size and max_output must be positive")
+	}
+	for _, domain := range c.Tools.Fetch.AllowedDomains {
+		if _, err := path.Match(domain, ""); err != nil {
+			return fmt.Errorf("tools.fetch.allowed_domains: invalid pattern %q", domain)
+		}
+	}
+	if c.Tools.Clipboard.MaxRead <= 0 {
+		return fmt.Errorf("tools.clipboard.max_read must be positive")
+	}
+
+	// Validate agent configuration
+	if c.Agent.MaxIterations < 0 {
+		return fmt.Errorf("agent.max_iterations cannot be negative")
+	}
+	if c.Agent.ToolRetries < 0 {
+		return fmt.Errorf("agent.tool_retries cannot be negative")
+	}
+	if c.Agent.SummarizeOver < 0 {
+		return fmt.Errorf("agent.summarize_over cannot be negative")
+	}
+	if c.Agent.ProcessParallel < 1 {
+		return fmt.Errorf("agent.process_parallel must be at least 1")
+	}
+	if c.Agent.TurnTimeout < 0 {
+		return fmt.Errorf("agent.turn_timeout cannot be negative")
+	}
+	validInjectionGuards := map[string]bool{
+		"": true, "off": true, "standard": true, "strict": true,
+	}
+	if !validInjectionGuards[c.Agent.InjectionGuard] {
+		return fmt.Errorf("agent.injection_guard must be one of: off, standard, strict")
+	}
+	validPlanApprovals := map[string]bool{
+		"": true, "always": true, "never": true, "destructive": true,
+	}
+	if !validPlanApprovals[c.Agent.PlanApproval] {
+		return fmt.Errorf("agent.plan_approval must be one of: always, never, destructive")
+	}
+	for tool, pipeline := range c.Agent.ResultPipelines {
+		if len(pipeline) == 0 {
+			return fmt.Errorf("agent.result_pipelines.%s must list at least one transformer", tool)
+		}
+	}
+	subAgents := c.Agent.SubAgents
+	if subAgents.MaxParallel < 1 || subAgents.MaxTasks < 1 || subAgents.MaxRounds < 1 || subAgents.TokenBudget < 1 {
+		return fmt.Errorf("agent.subagents limits must be positive")
+	}
+	if c.Agent.Compaction.Threshold < 0 || c.Agent.Compaction.Threshold > 1 {
+		return fmt.Errorf("agent.compaction.threshold must be between 0 and 1")
+	}
+	if c.Agent.Compaction.KeepRecent < 1 {
+		return fmt.Errorf("agent.compaction.keep_recent must be positive")
+	}
+	if c.Agent.Intent.Classifier != "keywords" && c.Agent.Intent.Classifier != "model" {
+		return fmt.Errorf("agent.intent.classifier must be one of: keywords, model")
+	}
+	if c.Agent.Intent.Timeout <= 0 {
+		return fmt.Errorf("agent.intent.timeout must be positive")
+	}
+	if c.Agent.ResponseCache.Enabled && (c.Agent.ResponseCache.TTL <= 0 || c.Agent.ResponseCache.MaxEntries < 1) {
+		return fmt.Errorf("agent.response_cache ttl and max_entries must be positive")
+	}
+	if !slices.Contains(SessionModes, c.Agent.Mode) {
+		return fmt.Errorf("agent.mode must be one of: %s", strings.Join(SessionModes, ", "))
+	}
+	personas := make(map[string]bool)
+	for _, persona := range c.Agent.Personas {
+		if strings.TrimSpace(persona.Name) == "" || strings.ContainsAny(persona.Name, " \t") {
+			return fmt.Errorf("agent.personas names must be single words")
+		}
+		if personas[persona.Name] {
+			return fmt.Errorf("agent.personas has more than one persona named %s", persona.Name)
+		}
+		personas[persona.Name] = true
+		if persona.Temperature != nil && (*persona.Temperature < 0 || *persona.Temperature > 2) {
+			return fmt.Errorf("agent.personas %s: temperature must be between 0 and 2", persona.Name)
+		}
+	}
+	if c.Agent.Persona != "" && !personas[c.Agent.Persona] {
+		return fmt.Errorf("agent.persona %s is not one of agent.personas", c.Agent.Persona)
+	}
+	for _, policy := range c.Agent.ToolPolicies {
+		if _, err := path.Match(policy.Tool, ""); policy.Tool == "" || err != nil {
+			return fmt.Errorf("agent.tool_policies tool must be a tool name or glob pattern, got %q", policy.Tool)
+		}
+		if policy.MaxCallsPerTurn < 0 {
+			return fmt.Errorf("agent.tool_policies %s: max_calls_per_turn cannot be negative", policy.Tool)
+		}
+		for argument, rule := range policy.Arguments {
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("agent.tool_policies %s: invalid pattern for %s: %w", policy.Tool, argument, err)
+			}
+		}
+	}
+	for i, hook := range c.Agent.Hooks {
+		if !slices.Contains(HookEvents, hook.Event) {
+			return fmt.Errorf("agent.hooks[%d].event must be one of: %s", i, strings.Join(HookEvents, ", "))
+		}
+		if len(hook.Command) == 0 || hook.Command[0] == "" {
+			return fmt.Errorf("agent.hooks[%d].command is required", i)
+		}
+		if hook.Timeout < 0 {
+			return fmt.Errorf("agent.hooks[%d].timeout cannot be negative", i)
+		}
+		for _, tool := range hook.Tools {
+			if _, err := path.Match(tool, ""); tool == "" || err != nil {
+				return fmt.Errorf("agent.hooks[%d].tools must be tool names or glob patterns, got %q", i, tool)
+			}
+		}
+	}
+	for i, undo := range c.Agent.Undo {
+		if undo.Tool == "" || undo.Inverse == "" {
+			return fmt.Errorf("agent.undo[%d] needs both tool and inverse", i)
+		}
+	}
+
+	// Validate share configuration
+	switch c.Share.Upload {
+	case "", "none", "gist":
+	case "paste":
+		if c.Share.Endpoint == "" {
+			return fmt.Errorf("share.endpoint is required to upload to a paste service")
+		}
+	default:
+		return fmt.Errorf("share.upload must be one of: none, paste, gist")
+	}
+	if c.Share.Endpoint != "" {
+		if u, err := url.Parse(c.Share.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("share.endpoint must be an http:// or https:// URL")
+		}
+	}
+	if c.Share.Upload == "gist" && c.Share.Token == "" {
+		return fmt.Errorf("share.token is required to upload gists")
+	}
+	if c.Share.Timeout <= 0 {
+		return fmt.Errorf("share.timeout must be positive")
+	}
+
+	// Validate serve configuration
+	for i, webhook := range c.Serve.Webhooks {
+		if u, err := url.Parse(webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("serve.webhooks[%d].url must be an http:// or https:// URL", i)
+		}
+		for _, event := range webhook.Events {
+			if !slices.Contains(WebhookEvents, event) {
+				return fmt.Errorf("serve.webhooks[%d].events must be some of: %s", i, strings.Join(WebhookEvents, ", "))
+			}
+		}
+		if (webhook.Retries != nil && *webhook.Retries < 0) || webhook.Timeout < 0 {
+			return fmt.Errorf("serve.webhooks[%d].retries and timeout cannot be negative", i)
+		}
+	}
+	if key := c.Serve.Discord.PublicKey; key != "" {
+		if decoded, err := hex.DecodeString(key); err != nil || len(decoded) != ed25519.PublicKeySize {
+			return fmt.Errorf("serve.discord.public_key must be %d hexadecimal characters", 2*ed25519.PublicKeySize)
+		}
+	}
+	if !discordCommandPattern.MatchString(c.Serve.Discord.Command) {
+		return fmt.Errorf("serve.discord.command must be 1-32 lowercase letters, digits, - or _")
+	}
+
+	// Validate chaos configuration
+	for name, rate := range map[string]float64{
+		"chaos.drop_rate":        c.Chaos.DropRate,
+		"chaos.malformed_rate":   c.Chaos.MalformedRate,
+		"chaos.model_error_rate": c.Chaos.ModelErrorRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	if c.Chaos.ToolLatency < 0 || c.Chaos.DropTimeout < 0 {
+		return fmt.Errorf("chaos durations cannot be negative")
+	}
 
 	// Validate logging configuration
 	validLevels := map[string]bool{
@@ -217,13 +1009,16 @@ func (c *Config) validate() error {
 	if !validLevels[c.Logging.Level] {
 		return fmt.Errorf("logging.level must be one of: debug, info, warn, error")
 	}
+	if c.Logging.WatchdogInterval < 0 {
+		return fmt.Errorf("logging.watchdog_interval cannot be negative")
+	}
 
 	return nil
 }
 
 // Save writes the current configuration to the config file
 func (c *Config) Save() error {
-	if c.configFile == "" || c.configFile == "defaults (no config file found)" {
+	if !c.HasConfigFile() {
 		// No config file exists, create one
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
@@ -249,6 +1044,9 @@ func (c *Config) Save() error {
 	v.Set("mcp", c.MCP)
 	v.Set("storage", c.Storage)
 	v.Set("logging", c.Logging)
+	if c.Chaos.Enabled {
+		v.Set("chaos", c.Chaos)
+	}
 	
 	// Write to file
 	if err := v.WriteConfigAs(c.configFile); err != nil {
@@ -314,71 +1112,11 @@ func (c *Config) GetMCPServer(name string) (*ServerConfig, error) {
 
 // CreateDefaultConfig creates a default configuration file in the user's home directory
 func CreateDefaultConfig() error {
-	homeDir, err := os.UserHomeDir()
+	configFile, err := WriteInitialConfig(SetupOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	configDir := filepath.Join(homeDir, ".othello")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	configFile := filepath.Join(configDir, "config.yaml")
-	
-	// Check if config file already exists
-	if _, err := os.Stat(configFile); err == nil {
-		return fmt.Errorf("config file already exists: %s", configFile)
-	}
-
-	defaultConfig := `# Othello AI Agent Configuration
-
-# Model configuration
-model:
-  type: "ollama"           # Model provider (ollama)
-  name: "qwen2.5:3b"       # Model name
-  temperature: 0.7         # Response creativity (0.0-2.0)
-  max_tokens: 2048         # Maximum response length
-  context_length: 8192     # Context window size
-
-# Ollama configuration
-ollama:
-  host: "http://localhost:11434"  # Ollama server URL
-  timeout: "30s"                  # Request timeout
-
-# Terminal UI configuration
-tui:
-  theme: "default"         # UI theme
-  show_hints: true         # Show keyboard hints
-  auto_scroll: true        # Auto-scroll to new messages
-
-# MCP server configuration
-mcp:
-  servers: []              # List of MCP servers (empty by default)
-  # Example server configuration:
-  # - name: "filesystem"
-  #   command: "mcp-filesystem"
-  #   args: ["--root", "/home/user"]
-  #   transport: "stdio"
-  #   timeout: "10s"
-
-# Storage configuration
-storage:
-  history_size: 1000       # Maximum conversation history
-  cache_ttl: "1h"          # Tool cache time-to-live
-  data_dir: "~/.othello"   # Data directory
-
-# Logging configuration
-logging:
-  level: "info"            # Log level (debug, info, warn, error)
-  file: "~/.othello/logs/othello.log"  # Log file path
-  format: "text"           # Log format (text, json)
-`
-
-	if err := os.WriteFile(configFile, []byte(defaultConfig), 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+		return err
 	}
 
 	fmt.Printf("Default configuration created: %s\n", configFile)
 	return nil
-}
\ No newline at end of file
+}
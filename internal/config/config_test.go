@@ -22,6 +22,7 @@ func TestLoadDefaultConfig(t *testing.T) {
 	assert.Equal(t, 0.7, cfg.Model.Temperature)
 	assert.Equal(t, 2048, cfg.Model.MaxTokens)
 	assert.Equal(t, 8192, cfg.Model.ContextLength)
+	assert.Equal(t, PricingConfig{}, cfg.Model.Pricing)
 
 	assert.Equal(t, "http://localhost:11434", cfg.Ollama.Host)
 	assert.Equal(t, 30*time.Second, cfg.Ollama.Timeout)
@@ -32,6 +33,33 @@ func TestLoadDefaultConfig(t *testing.T) {
 
 	assert.Equal(t, 1000, cfg.Storage.HistorySize)
 	assert.Equal(t, time.Hour, cfg.Storage.CacheTTL)
+	assert.Equal(t, RetentionConfig{PruneInterval: time.Hour, VacuumInterval: 7 * 24 * time.Hour}, cfg.Storage.Retention)
+	assert.Equal(t, MemoryConfig{Enabled: true, AutoRecall: true, RecallLimit: 5}, cfg.Memory)
+	assert.Equal(t, IndexConfig{AutoRetrieve: true, RetrieveLimit: 4, MaxFileSize: 262144}, cfg.Index)
+	assert.Equal(t, ShellToolConfig{Enabled: true, RequireApproval: true, AllowedCommands: DefaultShellCommands, Timeout: 30 * time.Second, MaxOutput: 16384}, cfg.Tools.Shell)
+	assert.Equal(t, FilesToolConfig{Enabled: true, Roots: []string{}, AllowWrite: true, MaxRead: 65536}, cfg.Tools.Files)
+	assert.Equal(t, FetchToolConfig{Enabled: true, AllowedDomains: DefaultFetchDomains, Timeout: 20 * time.Second, MaxSize: 2097152, MaxOutput: 20000}, cfg.Tools.Fetch)
+	assert.Equal(t, ClipboardToolConfig{Enabled: true, RequireApproval: true, MaxRead: 20000}, cfg.Tools.Clipboard)
+	assert.Equal(t, 5, cfg.Agent.MaxIterations)
+	assert.Equal(t, "destructive", cfg.Agent.PlanApproval)
+	assert.Equal(t, 2, cfg.Agent.ToolRetries)
+	assert.Equal(t, 6000, cfg.Agent.SummarizeOver)
+	assert.True(t, cfg.Agent.DedupeCalls)
+	assert.Equal(t, 4, cfg.Agent.ProcessParallel)
+	assert.Equal(t, 5*time.Minute, cfg.Agent.TurnTimeout)
+	assert.Equal(t, "standard", cfg.Agent.InjectionGuard)
+	assert.False(t, cfg.Agent.Offline)
+	assert.Equal(t, ShareConfig{Upload: "none", Timeout: 30 * time.Second}, cfg.Share)
+	assert.Empty(t, cfg.Serve.Webhooks)
+	assert.Equal(t, SlackBridgeConfig{}, cfg.Serve.Slack)
+	assert.Equal(t, DiscordBridgeConfig{Command: "ask"}, cfg.Serve.Discord)
+	assert.Empty(t, cfg.Agent.ResultPipelines)
+	assert.Equal(t, SubAgentConfig{Enabled: true, MaxParallel: 3, MaxTasks: 5, MaxRounds: 4, TokenBudget: 8000}, cfg.Agent.SubAgents)
+	assert.Equal(t, CompactionConfig{Threshold: 0.8, KeepRecent: 4}, cfg.Agent.Compaction)
+	assert.Equal(t, IntentConfig{Classifier: "keywords", Timeout: 10 * time.Second}, cfg.Agent.Intent)
+	assert.Empty(t, cfg.Agent.Persona)
+	assert.Empty(t, cfg.Agent.Personas)
+	assert.Empty(t, cfg.Agent.ToolPolicies)
 
 	assert.Equal(t, "info", cfg.Logging.Level)
 	assert.Equal(t, "text", cfg.Logging.Format)
@@ -78,6 +106,13 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: "model.max_tokens must be positive",
 		},
+		{
+			name: "negative price",
+			modify: func(c *Config) {
+				c.Model.Pricing.OutputPerMillion = -1
+			},
+			wantErr: "model.pricing prices cannot be negative",
+		},
 		{
 			name: "empty ollama host",
 			modify: func(c *Config) {
@@ -106,6 +141,287 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: "storage.cache_ttl must be positive",
 		},
+		{
+			name: "negative retention limit",
+			modify: func(c *Config) {
+				c.Storage.Retention.MaxConversations = -1
+			},
+			wantErr: "storage.retention limits cannot be negative",
+		},
+		{
+			name: "negative sandbox limit",
+			modify: func(c *Config) {
+				c.MCP.Servers = []ServerConfig{{Name: "logs", Transport: "stdio", Sandbox: &SandboxConfig{MemoryMB: -1}}}
+			},
+			wantErr: "mcp.servers[0]: sandbox limits cannot be negative",
+		},
+		{
+			name: "sandboxed http server",
+			modify: func(c *Config) {
+				c.MCP.Servers = []ServerConfig{{Name: "search", Transport: "http", Sandbox: &SandboxConfig{}}}
+			},
+			wantErr: "mcp.servers[0]: sandbox only applies to stdio servers",
+		},
+		{
+			name: "negative memory recall limit",
+			modify: func(c *Config) {
+				c.Memory.RecallLimit = -1
+			},
+			wantErr: "memory.recall_limit cannot be negative",
+		},
+		{
+			name: "negative index retrieve limit",
+			modify: func(c *Config) {
+				c.Index.RetrieveLimit = -1
+			},
+			wantErr: "index.retrieve_limit cannot be negative",
+		},
+		{
+			name: "zero index max file size",
+			modify: func(c *Config) {
+				c.Index.MaxFileSize = 0
+			},
+			wantErr: "index.max_file_size must be positive",
+		},
+		{
+			name: "zero shell timeout",
+			modify: func(c *Config) {
+				c.Tools.Shell.Timeout = 0
+			},
+			wantErr: "tools.shell.timeout must be positive",
+		},
+		{
+			name: "zero file read limit",
+			modify: func(c *Config) {
+				c.Tools.Files.MaxRead = 0
+			},
+			wantErr: "tools.files.max_read must be positive",
+		},
+		{
+			name: "zero fetch size limit",
+			modify: func(c *Config) {
+				c.Tools.Fetch.MaxSize = 0
+			},
+			wantErr: "tools.fetch.max_size and max_output must be positive",
+		},
+		{
+			name: "zero clipboard read limit",
+			modify: func(c *Config) {
+				c.Tools.Clipboard.MaxRead = 0
+			},
+			wantErr: "tools.clipboard.max_read must be positive",
+		},
+		{
+			name: "invalid allowed command pattern",
+			modify: func(c *Config) {
+				c.Tools.Shell.AllowedCommands = []string{"git["}
+			},
+			wantErr: `tools.shell.allowed_commands: invalid pattern "git["`,
+		},
+		{
+			name: "negative agent max iterations",
+			modify: func(c *Config) {
+				c.Agent.MaxIterations = -1
+			},
+			wantErr: "agent.max_iterations cannot be negative",
+		},
+		{
+			name: "negative tool retries",
+			modify: func(c *Config) {
+				c.Agent.ToolRetries = -1
+			},
+			wantErr: "agent.tool_retries cannot be negative",
+		},
+		{
+			name: "negative summary threshold",
+			modify: func(c *Config) {
+				c.Agent.SummarizeOver = -1
+			},
+			wantErr: "agent.summarize_over cannot be negative",
+		},
+		{
+			name: "no parallel result processing",
+			modify: func(c *Config) {
+				c.Agent.ProcessParallel = 0
+			},
+			wantErr: "agent.process_parallel must be at least 1",
+		},
+		{
+			name: "negative turn timeout",
+			modify: func(c *Config) {
+				c.Agent.TurnTimeout = -time.Second
+			},
+			wantErr: "agent.turn_timeout cannot be negative",
+		},		{
+			name: "unknown injection guard",
+			modify: func(c *Config) {
+				c.Agent.InjectionGuard = "paranoid"
+			},
+			wantErr: "agent.injection_guard must be one of: off, standard, strict",
+		},
+		{
+			name: "unknown share upload",
+			modify: func(c *Config) {
+				c.Share.Upload = "email"
+			},
+			wantErr: "share.upload must be one of: none, paste, gist",
+		},
+		{
+			name: "paste upload without endpoint",
+			modify: func(c *Config) {
+				c.Share.Upload = "paste"
+			},
+			wantErr: "share.endpoint is required to upload to a paste service",
+		},
+		{
+			name: "gist upload without token",
+			modify: func(c *Config) {
+				c.Share.Upload = "gist"
+			},
+			wantErr: "share.token is required to upload gists",
+		},
+		{
+			name: "invalid share endpoint",
+			modify: func(c *Config) {
+				c.Share.Endpoint = "ftp://paste.example.com"
+			},
+			wantErr: "share.endpoint must be an http:// or https:// URL",
+		},
+		{
+			name: "webhook without url",
+			modify: func(c *Config) {
+				c.Serve.Webhooks = []WebhookConfig{{}}
+			},
+			wantErr: "serve.webhooks[0].url must be an http:// or https:// URL",
+		},
+		{
+			name: "unknown webhook event",
+			modify: func(c *Config) {
+				c.Serve.Webhooks = []WebhookConfig{{URL: "https://hooks.example.com", Events: []string{"tool_started"}}}
+			},
+			wantErr: "serve.webhooks[0].events must be some of: conversation_completed, tool_failed, scheduled_task",
+		},
+		{
+			name: "negative webhook retries",
+			modify: func(c *Config) {
+				retries := -1
+				c.Serve.Webhooks = []WebhookConfig{{URL: "https://hooks.example.com", Retries: &retries}}
+			},
+			wantErr: "serve.webhooks[0].retries and timeout cannot be negative",
+		},
+		{
+			name: "short discord public key",
+			modify: func(c *Config) {
+				c.Serve.Discord.PublicKey = "abcd"
+			},
+			wantErr: "serve.discord.public_key must be 64 hexadecimal characters",
+		},
+		{
+			name: "invalid discord command",
+			modify: func(c *Config) {
+				c.Serve.Discord.Command = "Ask Othello"
+			},
+			wantErr: "serve.discord.command must be 1-32 lowercase letters, digits, - or _",
+		},
+
+		{
+			name: "invalid plan approval",
+			modify: func(c *Config) {
+				c.Agent.PlanApproval = "sometimes"
+			},
+			wantErr: "agent.plan_approval must be one of: always, never, destructive",
+		},
+		{
+			name: "empty result pipeline",
+			modify: func(c *Config) {
+				c.Agent.ResultPipelines = map[string][]string{"read_file": {}}
+			},
+			wantErr: "agent.result_pipelines.read_file must list at least one transformer",
+		},
+		{
+			name: "zero sub-agent token budget",
+			modify: func(c *Config) {
+				c.Agent.SubAgents.TokenBudget = 0
+			},
+			wantErr: "agent.subagents limits must be positive",
+		},
+		{
+			name: "compaction threshold above one",
+			modify: func(c *Config) {
+				c.Agent.Compaction.Threshold = 1.5
+			},
+			wantErr: "agent.compaction.threshold must be between 0 and 1",
+		},
+		{
+			name: "unknown hook event",
+			modify: func(c *Config) {
+				c.Agent.Hooks = []HookConfig{{Event: "on_start", Command: []string{"logger"}}}
+			},
+			wantErr: "agent.hooks[0].event must be one of: pre_tool_call, post_tool_call, pre_prompt, on_response",
+		},
+		{
+			name: "hook without a command",
+			modify: func(c *Config) {
+				c.Agent.Hooks = []HookConfig{{Event: "pre_prompt"}}
+			},
+			wantErr: "agent.hooks[0].command is required",
+		},
+		{
+			name: "undo without an inverse",
+			modify: func(c *Config) {
+				c.Agent.Undo = []UndoConfig{{Tool: "store_memory"}}
+			},
+			wantErr: "agent.undo[0] needs both tool and inverse",
+		},
+		{
+			name: "unknown intent classifier",
+			modify: func(c *Config) {
+				c.Agent.Intent.Classifier = "llm"
+			},
+			wantErr: "agent.intent.classifier must be one of: keywords, model",
+		},
+		{
+			name: "response cache without a ttl",
+			modify: func(c *Config) {
+				c.Agent.ResponseCache.TTL = 0
+			},
+			wantErr: "agent.response_cache ttl and max_entries must be positive",
+		},
+		{
+			name: "unknown session mode",
+			modify: func(c *Config) {
+				c.Agent.Mode = "research"
+			},
+			wantErr: "agent.mode must be one of: chat, analysis, automation",
+		},
+		{
+			name: "duplicate persona",
+			modify: func(c *Config) {
+				c.Agent.Personas = []PersonaConfig{{Name: "writer"}, {Name: "writer"}}
+			},
+			wantErr: "agent.personas has more than one persona named writer",
+		},
+		{
+			name: "unknown starting persona",
+			modify: func(c *Config) {
+				c.Agent.Persona = "pirate"
+			},
+			wantErr: "agent.persona pirate is not one of agent.personas",
+		},
+		{
+			name: "tool policy without a tool",
+			modify: func(c *Config) {
+				c.Agent.ToolPolicies = []ToolPolicyConfig{{MaxCallsPerTurn: 3}}
+			},
+			wantErr: `agent.tool_policies tool must be a tool name or glob pattern, got ""`,
+		},
+		{
+			name: "invalid tool policy pattern",
+			modify: func(c *Config) {
+				c.Agent.ToolPolicies = []ToolPolicyConfig{{Tool: "write_file", Arguments: map[string]ArgumentPolicyConfig{"path": {Pattern: "("}}}}
+			},
+			wantErr: "agent.tool_policies write_file: invalid pattern for path: error parsing regexp: missing closing ): `(`",
+		},
 		{
 			name: "invalid log level",
 			modify: func(c *Config) {
@@ -113,6 +429,20 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: "logging.level must be one of: debug, info, warn, error",
 		},
+		{
+			name: "invalid chaos drop rate",
+			modify: func(c *Config) {
+				c.Chaos.DropRate = 1.5
+			},
+			wantErr: "chaos.drop_rate must be between 0 and 1",
+		},
+		{
+			name: "negative chaos latency",
+			modify: func(c *Config) {
+				c.Chaos.ToolLatency = -time.Second
+			},
+			wantErr: "chaos durations cannot be negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -166,6 +496,9 @@ model:
   temperature: 0.5
   max_tokens: 1000
   context_length: 4000
+  pricing:
+    input_per_million: 0.15
+    output_per_million: 0.6
 
 ollama:
   host: "http://test:8080"
@@ -181,6 +514,23 @@ storage:
   cache_ttl: "30m"
   data_dir: "/tmp/test"
 
+agent:
+  persona: "reviewer"
+  personas:
+    - name: "reviewer"
+      system_prompt: "You review code changes."
+      tools: ["read_file", "search"]
+      temperature: 0
+    - name: "writer"
+      system_prompt: "You write documentation."
+  tool_policies:
+    - tool: "write_*"
+      max_calls_per_turn: 2
+      arguments:
+        filePath:
+          paths: ["/tmp/test"]
+          banned: [".env"]
+
 logging:
   level: "debug"
   file: "/tmp/test.log"
@@ -209,6 +559,7 @@ logging:
 	assert.Equal(t, 0.5, cfg.Model.Temperature)
 	assert.Equal(t, 1000, cfg.Model.MaxTokens)
 	assert.Equal(t, 4000, cfg.Model.ContextLength)
+	assert.Equal(t, PricingConfig{InputPerMillion: 0.15, OutputPerMillion: 0.6}, cfg.Model.Pricing)
 
 	assert.Equal(t, "http://test:8080", cfg.Ollama.Host)
 	assert.Equal(t, 10*time.Second, cfg.Ollama.Timeout)
@@ -221,6 +572,20 @@ logging:
 	assert.Equal(t, 30*time.Minute, cfg.Storage.CacheTTL)
 	assert.Equal(t, "/tmp/test", cfg.Storage.DataDir)
 
+	assert.Equal(t, "reviewer", cfg.Agent.Persona)
+	require.Len(t, cfg.Agent.Personas, 2)
+	assert.Equal(t, []string{"read_file", "search"}, cfg.Agent.Personas[0].Tools)
+	require.NotNil(t, cfg.Agent.Personas[0].Temperature, "A temperature of 0 is kept")
+	assert.Equal(t, 0.0, *cfg.Agent.Personas[0].Temperature)
+	assert.Nil(t, cfg.Agent.Personas[1].Temperature)
+	require.Len(t, cfg.Agent.ToolPolicies, 1)
+	assert.Equal(t, "write_*", cfg.Agent.ToolPolicies[0].Tool)
+	assert.Equal(t, 2, cfg.Agent.ToolPolicies[0].MaxCallsPerTurn)
+	require.Len(t, cfg.Agent.ToolPolicies[0].Arguments, 1)
+	for _, rule := range cfg.Agent.ToolPolicies[0].Arguments {
+		assert.Equal(t, ArgumentPolicyConfig{Paths: []string{"/tmp/test"}, Banned: []string{".env"}}, rule)
+	}
+
 	assert.Equal(t, "debug", cfg.Logging.Level)
 	assert.Equal(t, "/tmp/test.log", cfg.Logging.File)
 	assert.Equal(t, "json", cfg.Logging.Format)
@@ -228,6 +593,48 @@ logging:
 	assert.Contains(t, cfg.ConfigFile(), "config.yaml")
 }
 
+func TestLoad_ConfigFileEnvVar(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+	workDir := t.TempDir()
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(workDir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "config.yaml"), []byte("model:\n  name: searched\n"), 0644))
+	instanceFile := filepath.Join(homeDir, "instances", "second.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(instanceFile), 0755))
+	require.NoError(t, os.WriteFile(instanceFile, []byte("model:\n  name: second\n"), 0644))
+
+	t.Setenv(ConfigFileEnvVar, "~/instances/second.yaml")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "second", cfg.Model.Name, "The named file is used instead of the search paths")
+	assert.Equal(t, instanceFile, cfg.ConfigFile())
+
+	t.Setenv(ConfigFileEnvVar, filepath.Join(homeDir, "missing.yaml"))
+	_, err = Load()
+	assert.ErrorContains(t, err, "missing.yaml", "A named file that doesn't exist is an error")
+}
+
+func TestLoad_WebhookRetries(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+	path := filepath.Join(homeDir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("serve:\n  webhooks:\n    - url: https://a.example.com\n      retries: 0\n    - url: https://b.example.com\n"), 0644))
+	t.Setenv(ConfigFileEnvVar, path)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Len(t, cfg.Serve.Webhooks, 2)
+	require.NotNil(t, cfg.Serve.Webhooks[0].Retries, "retries: 0 is kept apart from unset")
+	assert.Equal(t, 0, *cfg.Serve.Webhooks[0].Retries)
+	assert.Nil(t, cfg.Serve.Webhooks[1].Retries)
+}
+
 func TestConfig_AddMCPServer(t *testing.T) {
 	// Create a temporary config for testing
 	tempDir := t.TempDir()
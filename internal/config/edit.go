@@ -0,0 +1,311 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EditableFile returns the file `othello config set` and `othello config
+// edit` change: the active profile's config file, or else the config file
+// that was loaded. When there is neither, ~/.othello/config.yaml is created
+// with the defaults.
+func (c *Config) EditableFile() (string, error) {
+	if c.profileFile != "" {
+		return c.profileFile, nil
+	}
+	if c.HasConfigFile() {
+		return c.configFile, nil
+	}
+	path, err := WriteInitialConfig(SetupOptions{})
+	if err != nil {
+		return "", err
+	}
+	c.configFile = path
+	return path, nil
+}
+
+// Lookup returns the effective settings at key: the setting itself, or every
+// setting in the section key names
+func (c *Config) Lookup(key string) ([]Setting, error) {
+	key = strings.ToLower(key)
+	var found []Setting
+	for _, setting := range c.settings {
+		if setting.Key == key || strings.HasPrefix(setting.Key, key+".") {
+			found = append(found, setting)
+		}
+	}
+	if len(found) == 0 {
+		if _, err := settingType(key); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s is not set", key)
+	}
+	return found, nil
+}
+
+// SetValue sets key to value in the file EditableFile returns, and returns
+// the file. value is read as YAML, so numbers, booleans, durations, and lists
+// such as [a, b] keep their types. Comments and the other settings in the file
+// are kept. The file is left unchanged when the configuration wouldn't load
+// with the new value.
+func (c *Config) SetValue(key, value string) (string, error) {
+	key = strings.ToLower(key)
+	fieldType, err := settingType(key)
+	if err != nil {
+		return "", err
+	}
+	if fieldType.Kind() == reflect.Struct {
+		return "", fmt.Errorf("%s is a section; set one of its settings, such as %s.%s", key, key, firstSetting(fieldType))
+	}
+
+	node, err := valueNode(value, fieldType)
+	if err != nil {
+		return "", err
+	}
+	path, err := c.EditableFile()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	data, err = setYAMLValue(data, strings.Split(key, "."), node)
+	if err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	return path, c.ReplaceFile(path, data)
+}
+
+// ReplaceFile writes data to the config file at path when the configuration
+// loads with it. Otherwise the file is left as it was and the reason is
+// returned.
+func (c *Config) ReplaceFile(path string, data []byte) error {
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, loadErr := c.Reload(); loadErr != nil {
+		if original == nil {
+			err = os.Remove(path)
+		} else {
+			err = os.WriteFile(path, original, 0644)
+		}
+		if err != nil {
+			return fmt.Errorf("%w (and restoring %s failed: %v)", loadErr, path, err)
+		}
+		return loadErr
+	}
+	return nil
+}
+
+// settingType returns the type of the setting key, following the
+// mapstructure names of Config's fields. Keys inside maps, such as an
+// environment variable of a server, are settings of the map's value type.
+func settingType(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(Config{})
+	for i, part := range strings.Split(key, ".") {
+		switch t.Kind() {
+		case reflect.Map:
+			t = t.Elem()
+			continue
+		case reflect.Struct:
+			field, ok := fieldNamed(t, part)
+			if !ok {
+				return nil, fmt.Errorf("unknown setting %s", key)
+			}
+			t = field.Type
+		default:
+			parent := strings.Join(strings.Split(key, ".")[:i], ".")
+			return nil, fmt.Errorf("unknown setting %s: %s has no settings inside it", key, parent)
+		}
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+	}
+	return t, nil
+}
+
+// fieldNamed returns the field of the struct type t whose mapstructure name is name
+func fieldNamed(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if field.IsExported() && tag == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// firstSetting returns the name of the first setting in the section type t
+func firstSetting(t reflect.Type) string {
+	for i := 0; i < t.NumField(); i++ {
+		if tag, _, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ","); tag != "" {
+			return tag
+		}
+	}
+	return ""
+}
+
+// valueNode reads value as YAML for a setting of type t. Text settings keep
+// value as written, even when it looks like a number or boolean.
+func valueNode(value string, t reflect.Type) (*yaml.Node, error) {
+	if t.Kind() == reflect.String {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: yaml.DoubleQuotedStyle}, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("invalid value %q", value)
+	}
+	return doc.Content[0], nil
+}
+
+// setYAMLValue returns the YAML document data with the value at path
+// replaced by value, adding the mappings along path that are missing
+func setYAMLValue(data []byte, path []string, value *yaml.Node) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	mapping := doc.Content[0]
+	for i, part := range path {
+		if mapping.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not a section", strings.Join(path[:i], "."))
+		}
+		var existing *yaml.Node
+		for j := 0; j+1 < len(mapping.Content); j += 2 {
+			if strings.EqualFold(mapping.Content[j].Value, part) {
+				existing = mapping.Content[j+1]
+				switch {
+				case i == len(path)-1:
+					value.LineComment = existing.LineComment
+					mapping.Content[j+1] = value
+				case existing.Tag == "!!null":
+					// An empty section, such as "mcp:" with nothing under it
+					existing = &yaml.Node{Kind: yaml.MappingNode}
+					mapping.Content[j+1] = existing
+				}
+				break
+			}
+		}
+		if existing == nil {
+			existing = &yaml.Node{Kind: yaml.MappingNode}
+			if i == len(path)-1 {
+				existing = value
+			}
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, existing)
+		}
+		mapping = existing
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// SetServerEnabled switches the server name in the mcp.servers list of a
+// config file on or off, keeping its configuration, and returns the file. The
+// workspace, profile, and global config files are searched in that order.
+func (c *Config) SetServerEnabled(name string, enabled bool) (string, error) {
+	files := []string{c.workspaceFile, c.profileFile}
+	if c.HasConfigFile() {
+		files = append(files, c.configFile)
+	}
+	for _, path := range files {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		data, found, err := setServerEnabled(data, name, enabled)
+		if err != nil {
+			return "", fmt.Errorf("failed to update %s: %w", path, err)
+		}
+		if found {
+			return path, c.ReplaceFile(path, data)
+		}
+	}
+	return "", fmt.Errorf("server with name '%s' not found", name)
+}
+
+// setServerEnabled returns the YAML document data with the enabled setting of
+// the server name in mcp.servers changed, and whether the server was found.
+// Enabled is the default, so enabling a server removes the setting.
+func setServerEnabled(data []byte, name string, enabled bool) ([]byte, bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, false, nil
+	}
+	servers := mappingValue(mappingValue(doc.Content[0], "mcp"), "servers")
+	if servers == nil || servers.Kind != yaml.SequenceNode {
+		return nil, false, nil
+	}
+
+	for _, server := range servers.Content {
+		if nameNode := mappingValue(server, "name"); nameNode == nil || nameNode.Value != name {
+			continue
+		}
+		for i := 0; i+1 < len(server.Content); i += 2 {
+			if strings.EqualFold(server.Content[i].Value, "enabled") {
+				server.Content = append(server.Content[:i], server.Content[i+2:]...)
+				break
+			}
+		}
+		if !enabled {
+			server.Content = append(server.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: "enabled"},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"})
+		}
+
+		var out bytes.Buffer
+		encoder := yaml.NewEncoder(&out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&doc); err != nil {
+			return nil, false, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, false, err
+		}
+		return out.Bytes(), true, nil
+	}
+	return nil, false, nil
+}
+
+// mappingValue returns the value of key in the YAML mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
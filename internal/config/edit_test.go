@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_SetValue(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	path, err := cfg.SetValue("model.name", "llama3.1:8b")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(homeDir, ".othello", "config.yaml"), path, "A config file is created with the defaults")
+
+	_, err = cfg.SetValue("model.temperature", "0.3")
+	require.NoError(t, err)
+	_, err = cfg.SetValue("tools.fetch.allowed_domains", "[docs.python.org, go.dev]")
+	require.NoError(t, err)
+	_, err = cfg.SetValue("mcp.servers", "[{name: notes, command: notes-server, transport: stdio}]")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `name: "llama3.1:8b" # Model name`, "Comments are kept")
+
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "llama3.1:8b", cfg.Model.Name)
+	assert.Equal(t, 0.3, cfg.Model.Temperature)
+	assert.Equal(t, []string{"docs.python.org", "go.dev"}, cfg.Tools.Fetch.AllowedDomains)
+	require.Len(t, cfg.MCP.Servers, 1)
+	assert.Equal(t, "notes-server", cfg.MCP.Servers[0].Command)
+
+	settings, err := cfg.Lookup("model.temperature")
+	require.NoError(t, err)
+	require.Len(t, settings, 1)
+	assert.Equal(t, 0.3, settings[0].Value)
+	settings, err = cfg.Lookup("ollama")
+	require.NoError(t, err)
+	assert.Len(t, settings, 2, "A section returns each of its settings")
+	_, err = cfg.Lookup("ollama.port")
+	assert.ErrorContains(t, err, "unknown setting ollama.port")
+
+	_, err = cfg.SetValue("model.temperature", "5")
+	assert.ErrorContains(t, err, "model.temperature must be between 0 and 2")
+	unchanged, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data, unchanged, "An invalid value isn't written")
+
+	_, err = cfg.SetValue("model.nmae", "x")
+	assert.ErrorContains(t, err, "unknown setting model.nmae")
+	_, err = cfg.SetValue("model", "x")
+	assert.ErrorContains(t, err, "model is a section")
+	_, err = cfg.SetValue("model.name.first", "x")
+	assert.ErrorContains(t, err, "model.name has no settings inside it")
+}
+
+func TestConfig_SetValueWritesActiveProfile(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	profilePath, err := CreateProfile("work")
+	require.NoError(t, err)
+	t.Setenv(ProfileEnvVar, "work")
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	path, err := cfg.SetValue("model.name", "work-model")
+	require.NoError(t, err)
+	assert.Equal(t, profilePath, path)
+	_, err = os.Stat(filepath.Join(homeDir, ".othello", "config.yaml"))
+	assert.True(t, os.IsNotExist(err), "The global config file is left alone")
+}
+
+func TestConfig_SetServerEnabled(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	configFile := filepath.Join(homeDir, ".othello", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configFile), 0755))
+	require.NoError(t, os.WriteFile(configFile, []byte(`mcp:
+  servers:
+    - name: notes
+      command: notes-server # Started from PATH
+      transport: stdio
+`), 0644))
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.MCP.Servers[0].IsEnabled(), "Servers are enabled by default")
+
+	path, err := cfg.SetServerEnabled("notes", false)
+	require.NoError(t, err)
+	assert.Equal(t, configFile, path)
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.MCP.Servers[0].IsEnabled())
+	assert.Equal(t, "notes-server", cfg.MCP.Servers[0].Command, "The configuration is kept")
+
+	_, err = cfg.SetServerEnabled("notes", true)
+	require.NoError(t, err)
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "enabled", "Enabling removes the setting")
+	assert.Contains(t, string(data), "# Started from PATH")
+
+	_, err = cfg.SetServerEnabled("github", false)
+	assert.ErrorContains(t, err, "server with name 'github' not found")
+
+	require.NoError(t, AddMCPServer("github", MCPServerConfig{Command: "github-server"}))
+	require.NoError(t, SetMCPServerEnabled("github", false))
+	servers, err := ListMCPServers()
+	require.NoError(t, err)
+	assert.False(t, *servers["github"].Enabled)
+	mcpConfig, err := LoadMCPConfig()
+	require.NoError(t, err)
+	converted, err := ConvertMCPToServerConfigs(mcpConfig)
+	require.NoError(t, err)
+	assert.False(t, converted[0].IsEnabled())
+	require.NoError(t, SetMCPServerEnabled("github", true))
+	servers, err = ListMCPServers()
+	require.NoError(t, err)
+	assert.Nil(t, servers["github"].Enabled)
+}
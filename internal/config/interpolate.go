@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/secrets"
+)
+
+// referencePattern matches the ${env:NAME}, ${file:PATH}, and ${secret:NAME}
+// references that config values may contain
+var referencePattern = regexp.MustCompile(`\$\{(env|file|secret):([^}]*)\}`)
+
+// secretProvider resolves ${secret:NAME} references
+var secretProvider secrets.Provider = secrets.NewKeychain()
+
+// interpolate resolves the references in value and expands a leading "~" to
+// the home directory. ${env:NAME} is replaced with the environment variable
+// NAME, which must be set, ${file:PATH} with the contents of the file at PATH
+// without its trailing newline, and ${secret:NAME} with the secret NAME from
+// the OS keychain, so secrets can be kept out of config files. Resolved
+// values often hold tokens, so they are registered to be masked.
+func interpolate(value string) (string, error) {
+	var resolveErr error
+	value = referencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		match := referencePattern.FindStringSubmatch(reference)
+		kind, name := match[1], strings.TrimSpace(match[2])
+		if resolveErr != nil {
+			return reference
+		}
+		switch kind {
+		case "env":
+			resolved, ok := os.LookupEnv(name)
+			if !ok {
+				resolveErr = fmt.Errorf("environment variable %s is not set", name)
+			}
+			secrets.Register(resolved)
+			return resolved
+		case "secret":
+			resolved, err := secretProvider.Get(name)
+			if errors.Is(err, secrets.ErrNotFound) {
+				err = fmt.Errorf("secret %s is not stored; add it with `othello secret set %s`", name, name)
+			}
+			if err != nil {
+				resolveErr = err
+				return reference
+			}
+			secrets.Register(resolved)
+			return resolved
+		default:
+			path, err := expandHome(name)
+			if err != nil {
+				resolveErr = err
+				return reference
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				resolveErr = fmt.Errorf("failed to read %s: %w", name, err)
+				return reference
+			}
+			resolved := strings.TrimRight(string(data), "\r\n")
+			secrets.Register(resolved)
+			return resolved
+		}
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return expandHome(value)
+}
+
+// registerSecretSettings registers the settings of c that hold credentials,
+// those tagged secret:"true", the MCP servers' environments and headers, and
+// webhook URLs, which often carry a token, so they are masked where Othello
+// prints text
+func registerSecretSettings(c *Config) {
+	eachSecretSetting(reflect.ValueOf(c).Elem(), func(setting reflect.Value) {
+		secrets.Register(setting.String())
+	})
+	for _, server := range c.MCP.Servers {
+		registerServerSecrets(server)
+	}
+	for _, webhook := range c.Serve.Webhooks {
+		secrets.Register(webhook.URL)
+	}
+}
+
+// eachSecretSetting calls fn with each string setting held by v that is
+// tagged secret:"true"
+func eachSecretSetting(v reflect.Value, fn func(setting reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			switch {
+			case !field.IsExported():
+			case field.Tag.Get("secret") == "true":
+				fn(v.Field(i))
+			default:
+				eachSecretSetting(v.Field(i), fn)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			eachSecretSetting(v.Index(i), fn)
+		}
+	}
+}
+
+// Masked returns a copy of c with each setting tagged secret:"true" that is
+// set replaced by secrets.Mask. Lists are copied, so c is left as it is.
+func (c *Config) Masked() Config {
+	masked := *c
+	v := reflect.ValueOf(&masked).Elem()
+	unshareLists(v)
+	eachSecretSetting(v, func(setting reflect.Value) {
+		if setting.String() != "" {
+			setting.SetString(secrets.Mask)
+		}
+	})
+	return masked
+}
+
+// unshareLists replaces the lists held by v with copies, so that changing
+// their items leaves the original's alone
+func unshareLists(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				unshareLists(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		v.Set(copied)
+		for i := 0; i < copied.Len(); i++ {
+			unshareLists(copied.Index(i))
+		}
+	}
+}
+
+// registerServerSecrets registers the environment and headers of server,
+// which often hold API keys, so they are masked where Othello prints text
+func registerServerSecrets(server ServerConfig) {
+	for _, value := range server.Env {
+		secrets.Register(value)
+	}
+	for _, value := range server.Headers {
+		secrets.Register(value)
+	}
+}
+
+// MaskedValue returns value, an environment variable or header of an MCP
+// server as the config file has it, to show the user. A value that refers to
+// its secret, such as "Bearer ${secret:github-token}", is shown as it is, and
+// any other value is masked.
+func MaskedValue(value string) string {
+	if referencePattern.MatchString(value) {
+		return value
+	}
+	return secrets.Mask
+}
+
+// expandHome expands a leading "~" in path to the home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, path[1:]), nil
+}
+
+// interpolateConfig resolves the references in every string setting of c.
+// Errors name the setting whose reference couldn't be resolved.
+func interpolateConfig(c *Config) error {
+	return interpolateValue(reflect.ValueOf(c).Elem(), "")
+}
+
+// interpolateValue resolves the references in the strings held by v, the
+// setting key
+func interpolateValue(v reflect.Value, key string) error {
+	switch v.Kind() {
+	case reflect.String:
+		resolved, err := interpolate(v.String())
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		v.SetString(resolved)
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return interpolateValue(v.Elem(), key)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if key != "" {
+				name = key + "." + name
+			}
+			if err := interpolateValue(v.Field(i), name); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := interpolateValue(v.Index(i), fmt.Sprintf("%s[%d]", key, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Map values can't be set in place, so each is resolved in a copy
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(iter.Value().Type()).Elem()
+			value.Set(iter.Value())
+			if err := interpolateValue(value, fmt.Sprintf("%s.%v", key, iter.Key())); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), value)
+		}
+	}
+	return nil
+}
@@ -1,18 +1,57 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"time"
 )
 
 // MCPServerConfig represents the standard MCP server configuration
 type MCPServerConfig struct {
-	Command string            `json:"command"`
-	Args    []string          `json:"args,omitempty"`
-	Env     map[string]string `json:"env,omitempty"`
+	Command   string            `json:"command,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	Transport string            `json:"transport,omitempty"` // stdio when empty, or http when URL is set
+	URL       string            `json:"url,omitempty"`       // Endpoint of http servers
+	Headers   map[string]string `json:"headers,omitempty"`   // Sent with every request to http servers
+	Timeout   string            `json:"timeout,omitempty"`   // A duration such as "45s"; 30s when empty
+	Enabled   *bool             `json:"enabled,omitempty"`   // false keeps the server configured without connecting to it
+	Sandbox   *SandboxConfig    `json:"sandbox,omitempty"`   // Runs a stdio server with reduced privileges
+}
+
+// defaultMCPServerTimeout is the timeout of mcp.json servers that don't set one
+const defaultMCPServerTimeout = 30 * time.Second
+
+// validate checks that server, called name in mcp.json, can be started
+func (s MCPServerConfig) validate(name string) error {
+	switch s.transport() {
+	case "stdio":
+		if s.Command == "" {
+			return fmt.Errorf("server %s: command is required", name)
+		}
+	case "http":
+		if err := ValidateServerURL(s.URL); err != nil {
+			return fmt.Errorf("server %s: %w", name, err)
+		}
+	default:
+		return fmt.Errorf("server %s: transport must be stdio or http, got %q", name, s.Transport)
+	}
+	if s.Timeout != "" {
+		if timeout, err := time.ParseDuration(s.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("server %s: timeout must be a duration such as 30s, got %q", name, s.Timeout)
+		}
+	}
+	if err := s.Sandbox.validate(s.transport()); err != nil {
+		return fmt.Errorf("server %s: %w", name, err)
+	}
+	return nil
 }
 
 // MCPStandardConfig represents the standard MCP configuration format
@@ -20,14 +59,26 @@ type MCPStandardConfig struct {
 	MCPServers map[string]MCPServerConfig `json:"mcpServers"`
 }
 
-// LoadMCPConfig loads MCP configuration from ~/.othello/mcp.json
-func LoadMCPConfig() (*MCPStandardConfig, error) {
-	homeDir, err := os.UserHomeDir()
+// MCPConfigPath returns the mcp.json of the active profile:
+// ~/.othello/mcp.json, or ~/.othello/profiles/<name>/mcp.json
+func MCPConfigPath() (string, error) {
+	profile := ActiveProfile()
+	if !ProfileExists(profile) {
+		return "", unknownProfileError(profile)
+	}
+	dir, err := ProfileDir(profile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return "", err
 	}
+	return filepath.Join(dir, "mcp.json"), nil
+}
 
-	mcpConfigPath := filepath.Join(homeDir, ".othello", "mcp.json")
+// LoadMCPConfig loads MCP configuration from the active profile's mcp.json
+func LoadMCPConfig() (*MCPStandardConfig, error) {
+	mcpConfigPath, err := MCPConfigPath()
+	if err != nil {
+		return nil, err
+	}
 	
 	// If mcp.json doesn't exist, return empty config
 	if _, err := os.Stat(mcpConfigPath); os.IsNotExist(err) {
@@ -53,20 +104,17 @@ func loadMCPJSON(path string) (*MCPStandardConfig, error) {
 	return &mcpConfig, nil
 }
 
-// SaveMCPConfig saves the MCP configuration to ~/.othello/mcp.json
+// SaveMCPConfig saves the MCP configuration to the active profile's mcp.json
 func SaveMCPConfig(mcpConfig *MCPStandardConfig) error {
-	homeDir, err := os.UserHomeDir()
+	mcpConfigPath, err := MCPConfigPath()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return err
 	}
 
-	configDir := filepath.Join(homeDir, ".othello")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(mcpConfigPath), 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	mcpConfigPath := filepath.Join(configDir, "mcp.json")
-
 	data, err := json.MarshalIndent(mcpConfig, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal mcp config: %w", err)
@@ -90,6 +138,9 @@ func AddMCPServer(name string, server MCPServerConfig) error {
 	if _, exists := mcpConfig.MCPServers[name]; exists {
 		return fmt.Errorf("server with name '%s' already exists", name)
 	}
+	if err := server.validate(name); err != nil {
+		return err
+	}
 
 	mcpConfig.MCPServers[name] = server
 	return SaveMCPConfig(mcpConfig)
@@ -110,6 +161,119 @@ func RemoveMCPServer(name string) error {
 	return SaveMCPConfig(mcpConfig)
 }
 
+// transport returns the transport of the server: the one set, or else http
+// for servers with a URL and stdio for the others
+func (s MCPServerConfig) transport() string {
+	switch {
+	case s.Transport != "":
+		return s.Transport
+	case s.URL != "":
+		return "http"
+	}
+	return "stdio"
+}
+
+// ValidateServerURL checks that rawURL can be the endpoint of an http server
+func ValidateServerURL(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("url is required for http servers")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http:// or https:// URL, got %q", rawURL)
+	}
+	return nil
+}
+
+// UpdateMCPServer replaces the configuration of the server name in mcp.json
+func UpdateMCPServer(name string, server MCPServerConfig) error {
+	mcpConfig, err := LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load mcp config: %w", err)
+	}
+
+	if _, exists := mcpConfig.MCPServers[name]; !exists {
+		return fmt.Errorf("server with name '%s' not found", name)
+	}
+	if err := server.validate(name); err != nil {
+		return err
+	}
+
+	mcpConfig.MCPServers[name] = server
+	return SaveMCPConfig(mcpConfig)
+}
+
+// PatchMCPServer returns server with the JSON merge patch (RFC 7386) patch
+// applied: fields in patch replace those of server, and null fields are
+// removed. For example {"timeout": "1m", "env": {"DEBUG": null}}.
+func PatchMCPServer(server MCPServerConfig, patch []byte) (MCPServerConfig, error) {
+	var changes map[string]any
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return server, fmt.Errorf("invalid JSON patch: %w", err)
+	}
+	data, err := json.Marshal(server)
+	if err != nil {
+		return server, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return server, err
+	}
+
+	data, err = json.Marshal(mergePatch(fields, changes))
+	if err != nil {
+		return server, err
+	}
+	var patched MCPServerConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		return server, fmt.Errorf("invalid JSON patch: %w", err)
+	}
+	return patched, nil
+}
+
+// mergePatch applies the JSON merge patch changes to target
+func mergePatch(target, changes map[string]any) map[string]any {
+	if target == nil {
+		target = make(map[string]any)
+	}
+	for key, change := range changes {
+		switch change := change.(type) {
+		case nil:
+			delete(target, key)
+		case map[string]any:
+			existing, _ := target[key].(map[string]any)
+			target[key] = mergePatch(existing, change)
+		default:
+			target[key] = change
+		}
+	}
+	return target
+}
+
+// SetMCPServerEnabled switches a server in mcp.json on or off, keeping its
+// configuration
+func SetMCPServerEnabled(name string, enabled bool) error {
+	mcpConfig, err := LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load mcp config: %w", err)
+	}
+
+	server, exists := mcpConfig.MCPServers[name]
+	if !exists {
+		return fmt.Errorf("server with name '%s' not found", name)
+	}
+
+	// Enabled is the default, so it is left out of the file
+	server.Enabled = nil
+	if !enabled {
+		server.Enabled = &enabled
+	}
+	mcpConfig.MCPServers[name] = server
+	return SaveMCPConfig(mcpConfig)
+}
+
 // ListMCPServers returns all servers from mcp.json
 func ListMCPServers() (map[string]MCPServerConfig, error) {
 	mcpConfig, err := LoadMCPConfig()
@@ -120,21 +284,36 @@ func ListMCPServers() (map[string]MCPServerConfig, error) {
 	return mcpConfig.MCPServers, nil
 }
 
-// ConvertMCPToServerConfigs converts MCP standard format to internal ServerConfig format
-func ConvertMCPToServerConfigs(mcpConfig *MCPStandardConfig) []ServerConfig {
+// ConvertMCPToServerConfigs converts MCP standard format to internal ServerConfig
+// format, resolving ${env:NAME} and ${file:PATH} references as Load does
+func ConvertMCPToServerConfigs(mcpConfig *MCPStandardConfig) ([]ServerConfig, error) {
 	servers := make([]ServerConfig, 0, len(mcpConfig.MCPServers))
 
 	for name, mcpServer := range mcpConfig.MCPServers {
+		if err := mcpServer.validate(name); err != nil {
+			return nil, fmt.Errorf("error in mcp.json: %w", err)
+		}
 		server := ServerConfig{
 			Name:      name,
 			Command:   mcpServer.Command,
-			Args:      mcpServer.Args,
-			Env:       mcpServer.Env,
-			Transport: "stdio", // Default transport for MCP
-			Timeout:   30 * time.Second, // Default timeout
+			Args:      slices.Clone(mcpServer.Args),
+			Env:       maps.Clone(mcpServer.Env),
+			Transport: mcpServer.transport(),
+			URL:       mcpServer.URL,
+			Headers:   maps.Clone(mcpServer.Headers),
+			Enabled:   mcpServer.Enabled,
+			Sandbox:   mcpServer.Sandbox,
+			Timeout:   defaultMCPServerTimeout,
+		}
+		if mcpServer.Timeout != "" {
+			server.Timeout, _ = time.ParseDuration(mcpServer.Timeout)
+		}
+		if err := interpolateValue(reflect.ValueOf(&server).Elem(), "mcpServers."+name); err != nil {
+			return nil, fmt.Errorf("error resolving mcp.json: %w", err)
 		}
+		registerServerSecrets(server)
 		servers = append(servers, server)
 	}
 
-	return servers
+	return servers, nil
 }
\ No newline at end of file
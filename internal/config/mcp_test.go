@@ -0,0 +1,99 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateMCPServer(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+
+	require.NoError(t, AddMCPServer("memory", MCPServerConfig{Command: "npx", Args: []string{"memory-server"}}))
+	require.NoError(t, UpdateMCPServer("memory", MCPServerConfig{Command: "npx", Args: []string{"memory-server", "--db", "notes.db"}, Timeout: "2m"}))
+
+	mcpConfig, err := LoadMCPConfig()
+	require.NoError(t, err)
+	servers, err := ConvertMCPToServerConfigs(mcpConfig)
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, []string{"memory-server", "--db", "notes.db"}, servers[0].Args)
+	assert.Equal(t, 2*time.Minute, servers[0].Timeout)
+	assert.Equal(t, "stdio", servers[0].Transport, "The transport defaults to stdio")
+
+	assert.ErrorContains(t, UpdateMCPServer("memory", MCPServerConfig{Command: "npx", Timeout: "soon"}), "timeout must be a duration")
+	assert.ErrorContains(t, UpdateMCPServer("memory", MCPServerConfig{Command: "npx", Transport: "sse"}), "transport must be stdio or http")
+	assert.ErrorContains(t, UpdateMCPServer("memory", MCPServerConfig{}), "command is required")
+	assert.ErrorContains(t, UpdateMCPServer("github", MCPServerConfig{Command: "gh"}), "server with name 'github' not found")
+}
+
+func TestPatchMCPServer(t *testing.T) {
+	server := MCPServerConfig{
+		Command: "npx",
+		Args:    []string{"memory-server"},
+		Env:     map[string]string{"DEBUG": "1", "DB": "notes.db"},
+	}
+
+	patched, err := PatchMCPServer(server, []byte(`{"timeout": "1m", "args": ["memory-server", "--verbose"], "env": {"DEBUG": null, "LOG": "info"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, MCPServerConfig{
+		Command: "npx",
+		Args:    []string{"memory-server", "--verbose"},
+		Env:     map[string]string{"DB": "notes.db", "LOG": "info"},
+		Timeout: "1m",
+	}, patched)
+	assert.Equal(t, "1", server.Env["DEBUG"], "The original is left alone")
+
+	patched, err = PatchMCPServer(server, []byte(`{"env": null}`))
+	require.NoError(t, err)
+	assert.Nil(t, patched.Env)
+
+	_, err = PatchMCPServer(server, []byte(`{"tiemout": "1m"}`))
+	assert.ErrorContains(t, err, `unknown field "tiemout"`)
+	_, err = PatchMCPServer(server, []byte(`["not", "an", "object"]`))
+	assert.ErrorContains(t, err, "invalid JSON patch")
+}
+
+func TestAddMCPServer_HTTP(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+	t.Setenv("SEARCH_KEY", "k-123")
+
+	require.NoError(t, AddMCPServer("search", MCPServerConfig{
+		URL:     "https://mcp.example.com/mcp",
+		Headers: map[string]string{"Authorization": "Bearer ${env:SEARCH_KEY}"},
+	}))
+	mcpConfig, err := LoadMCPConfig()
+	require.NoError(t, err)
+	servers, err := ConvertMCPToServerConfigs(mcpConfig)
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, "http", servers[0].Transport, "Servers with a URL use http")
+	assert.Equal(t, "https://mcp.example.com/mcp", servers[0].URL)
+	assert.Equal(t, "Bearer k-123", servers[0].Headers["Authorization"], "Headers may hold references")
+
+	assert.ErrorContains(t, AddMCPServer("ftp", MCPServerConfig{URL: "ftp://example.com"}), "url must be an http:// or https:// URL")
+	assert.ErrorContains(t, AddMCPServer("empty", MCPServerConfig{Transport: "http"}), "url is required")
+}
+
+func TestAddMCPServer_Sandbox(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+
+	sandbox := &SandboxConfig{InheritEnv: []string{"PATH"}, MemoryMB: 256, MaxFiles: 64}
+	require.NoError(t, AddMCPServer("logs", MCPServerConfig{Command: "log-server", Sandbox: sandbox}))
+	mcpConfig, err := LoadMCPConfig()
+	require.NoError(t, err)
+	servers, err := ConvertMCPToServerConfigs(mcpConfig)
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, sandbox, servers[0].Sandbox)
+
+	assert.ErrorContains(t, AddMCPServer("search", MCPServerConfig{URL: "https://mcp.example.com/mcp", Sandbox: sandbox}),
+		"sandbox only applies to stdio servers")
+	assert.ErrorContains(t, AddMCPServer("greedy", MCPServerConfig{Command: "x", Sandbox: &SandboxConfig{CPUSeconds: -1}}),
+		"sandbox limits cannot be negative")
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultProfile is the profile used when none is selected. Its settings are
+// ~/.othello/config.yaml and ~/.othello/mcp.json alone.
+const DefaultProfile = "default"
+
+// ProfileEnvVar selects the profile when --profile isn't given
+const ProfileEnvVar = "OTHELLO_PROFILE"
+
+// currentProfileFile records the profile chosen with `othello config profiles
+// switch`, used when neither --profile nor OTHELLO_PROFILE selects one
+const currentProfileFile = "current_profile"
+
+// profileNamePattern is what profile names may look like, so they are safe
+// as directory names
+var profileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// profileTemplate is written by CreateProfile. It is filled in with the
+// profile's name.
+const profileTemplate = `# Othello profile %[1]q
+#
+# Settings here override ~/.othello/config.yaml while the profile is active.
+# MCP servers added with ` + "`othello mcp add`" + ` while it is active are kept in
+# mcp.json next to this file, replacing ~/.othello/mcp.json.
+
+# model:
+#   name: "qwen2.5:3b"
+
+storage:
+  data_dir: "~/.othello/profiles/%[1]s"   # Conversations, memories, and the index
+`
+
+// configDir returns ~/.othello
+func configDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".othello"), nil
+}
+
+// ProfileDir returns the directory holding the settings of the profile
+// name: ~/.othello for the default profile, ~/.othello/profiles/<name> for
+// the others
+func ProfileDir(name string) (string, error) {
+	dir, err := configDir()
+	if err != nil || name == DefaultProfile {
+		return dir, err
+	}
+	return filepath.Join(dir, "profiles", name), nil
+}
+
+// profileFile returns the config file of the profile name, which is layered
+// over the global config file
+func profileFile(name string) (string, error) {
+	dir, err := ProfileDir(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// ActiveProfile returns the selected profile: the one named by
+// OTHELLO_PROFILE, or else the one switched to, or else the default
+func ActiveProfile() string {
+	if name := strings.TrimSpace(os.Getenv(ProfileEnvVar)); name != "" {
+		return name
+	}
+	dir, err := configDir()
+	if err != nil {
+		return DefaultProfile
+	}
+	data, err := os.ReadFile(filepath.Join(dir, currentProfileFile))
+	if name := strings.TrimSpace(string(data)); err == nil && name != "" {
+		return name
+	}
+	return DefaultProfile
+}
+
+// ListProfiles returns the default profile followed by the others, sorted
+func ListProfiles() ([]string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "profiles"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && ProfileExists(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// ProfileExists reports whether the profile name has been created
+func ProfileExists(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	if !profileNamePattern.MatchString(name) {
+		return false
+	}
+	path, err := profileFile(name)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// CreateProfile creates the profile name with a commented config file, and
+// returns the file's path
+func CreateProfile(name string) (string, error) {
+	if !profileNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+	if ProfileExists(name) {
+		return "", fmt.Errorf("profile %q already exists", name)
+	}
+	path, err := profileFile(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(profileTemplate, name)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write profile: %w", err)
+	}
+	return path, nil
+}
+
+// SwitchProfile makes name the profile used when neither --profile nor
+// OTHELLO_PROFILE selects one
+func SwitchProfile(name string) error {
+	if !ProfileExists(name) {
+		return unknownProfileError(name)
+	}
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, currentProfileFile)
+	if name == DefaultProfile {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to switch profile: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to switch profile: %w", err)
+	}
+	return nil
+}
+
+// unknownProfileError is returned for a profile that hasn't been created
+func unknownProfileError(name string) error {
+	return fmt.Errorf("profile %q not found; create it with `othello config profiles create %s`", name, name)
+}
+
+// applyProfile merges the config file of the profile name over v and returns
+// the file and the keys it set. The default profile has no file to merge.
+func applyProfile(v *viper.Viper, name string) (string, map[string]bool, error) {
+	if name == DefaultProfile {
+		return "", nil, nil
+	}
+	if !ProfileExists(name) {
+		return "", nil, unknownProfileError(name)
+	}
+	path, err := profileFile(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := validateFile(path); err != nil {
+		return "", nil, err
+	}
+	pv := viper.New()
+	pv.SetConfigFile(path)
+	pv.SetConfigType("yaml")
+	if err := pv.ReadInConfig(); err != nil {
+		return "", nil, fmt.Errorf("error reading profile %s: %w", path, err)
+	}
+	keys := make(map[string]bool)
+	for _, key := range pv.AllKeys() {
+		keys[key] = true
+	}
+
+	// Merge into the config file layer so workspaces, environment variables,
+	// and flags still win
+	if err := v.MergeConfigMap(pv.AllSettings()); err != nil {
+		return "", nil, fmt.Errorf("error merging profile %s: %w", path, err)
+	}
+	return path, keys, nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiles_CreateListSwitch(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+
+	profiles, err := ListProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultProfile}, profiles)
+	assert.Equal(t, DefaultProfile, ActiveProfile())
+
+	_, err = CreateProfile("work")
+	require.NoError(t, err)
+	_, err = CreateProfile("experiments")
+	require.NoError(t, err)
+	_, err = CreateProfile("work")
+	assert.ErrorContains(t, err, "already exists")
+	_, err = CreateProfile("../escape")
+	assert.ErrorContains(t, err, "invalid profile name")
+
+	profiles, err = ListProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultProfile, "experiments", "work"}, profiles)
+
+	require.NoError(t, SwitchProfile("work"))
+	assert.Equal(t, "work", ActiveProfile())
+	t.Setenv(ProfileEnvVar, "experiments")
+	assert.Equal(t, "experiments", ActiveProfile(), "OTHELLO_PROFILE wins over the profile switched to")
+	t.Setenv(ProfileEnvVar, "")
+
+	assert.ErrorContains(t, SwitchProfile("personal"), "not found")
+	require.NoError(t, SwitchProfile(DefaultProfile))
+	assert.Equal(t, DefaultProfile, ActiveProfile())
+}
+
+func TestLoad_MergesActiveProfile(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	configDir := filepath.Join(homeDir, ".othello")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("model:\n  name: global-model\n  max_tokens: 1000\n"), 0644))
+	path, err := CreateProfile("work")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("model:\n  name: work-model\nstorage:\n  data_dir: ~/.othello/profiles/work\n"), 0644))
+
+	t.Setenv(ProfileEnvVar, "work")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "work", cfg.Profile())
+	assert.Equal(t, path, cfg.ProfileFile())
+	assert.Equal(t, "work-model", cfg.Model.Name)
+	assert.Equal(t, 1000, cfg.Model.MaxTokens, "Settings the profile leaves out come from config.yaml")
+	assert.Equal(t, filepath.Join(configDir, "profiles", "work"), cfg.Storage.DataDir, "~ is expanded")
+	assert.Equal(t, SourceProfile, cfg.SourceOf("model.name"))
+	assert.Equal(t, SourceFile, cfg.SourceOf("model.max_tokens"))
+
+	mcpPath, err := MCPConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(configDir, "profiles", "work", "mcp.json"), mcpPath)
+
+	t.Setenv(ProfileEnvVar, "personal")
+	_, err = Load()
+	assert.ErrorContains(t, err, `profile "personal" not found`)
+
+	t.Setenv(ProfileEnvVar, "")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultProfile, cfg.Profile())
+	assert.Equal(t, "global-model", cfg.Model.Name)
+}
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaID identifies the JSON Schema of config files. Editors that support
+// the yaml-language-server modeline use it for completion and validation.
+const SchemaID = "https://raw.githubusercontent.com/danieleugenewilliams/othello-agent/main/docs/config.schema.json"
+
+// Schema is the subset of JSON Schema that describes config files
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 any                `json:"type,omitempty"` // A type name, or a list of them
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // false, or the schema of every value
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// durationPattern matches durations such as "30s" and "1h30m"
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// schemaEnums are the values settings of a fixed set accept, by key. "[]"
+// stands for every item of a list.
+var schemaEnums = map[string][]string{
+	"tui.render_mode":           {"auto", "full", "plain"},
+	"mcp.servers[].transport":   {"stdio", "http"},
+	"agent.plan_approval":       {"always", "never", "destructive"},
+	"agent.injection_guard":     {"off", "standard", "strict"},
+	"agent.intent.classifier":   {"keywords", "model"},
+	"agent.mode":                SessionModes,
+	"agent.hooks[].event":       HookEvents,
+	"share.upload":              {"none", "paste", "gist"},
+	"serve.webhooks[].events[]": WebhookEvents,
+	"logging.level":             {"debug", "info", "warn", "error"},
+}
+
+// schemaRanges are the least and greatest values of numeric settings, by key
+var schemaRanges = map[string][2]float64{
+	"model.temperature":            {0, 2},
+	"agent.personas[].temperature": {0, 2},
+	"agent.compaction.threshold":   {0, 1},
+	"chaos.drop_rate":              {0, 1},
+	"chaos.malformed_rate":         {0, 1},
+	"chaos.model_error_rate":       {0, 1},
+}
+
+// ConfigSchema returns the JSON Schema of config files, generated from Config
+func ConfigSchema() *Schema {
+	schema := schemaFor(reflect.TypeOf(Config{}), "")
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	schema.ID = SchemaID
+	schema.Title = "Othello configuration"
+	return schema
+}
+
+// ConfigSchemaJSON returns ConfigSchema as indented JSON
+func ConfigSchemaJSON() ([]byte, error) {
+	data, err := json.MarshalIndent(ConfigSchema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaFor returns the schema of settings of type t, the setting key
+func schemaFor(t reflect.Type, key string) *Schema {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return &Schema{Type: []string{"string", "integer"}, Pattern: durationPattern}
+	}
+	schema := &Schema{}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), key)
+	case reflect.Struct:
+		schema.Type = "object"
+		schema.Properties = make(map[string]*Schema)
+		schema.AdditionalProperties = false
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if !field.IsExported() || name == "" {
+				continue
+			}
+			schema.Properties[name] = schemaFor(field.Type, strings.TrimPrefix(key+"."+name, "."))
+		}
+	case reflect.Map:
+		schema.Type = "object"
+		schema.AdditionalProperties = schemaFor(t.Elem(), key+".*")
+	case reflect.Slice:
+		schema.Type = "array"
+		schema.Items = schemaFor(t.Elem(), key+"[]")
+	case reflect.String:
+		schema.Type = "string"
+		schema.Enum = schemaEnums[key]
+	case reflect.Bool:
+		schema.Type = "boolean"
+	case reflect.Int, reflect.Int64:
+		schema.Type = "integer"
+	case reflect.Float64:
+		schema.Type = "number"
+	}
+	if limits, ok := schemaRanges[key]; ok {
+		schema.Minimum, schema.Maximum = &limits[0], &limits[1]
+	}
+	return schema
+}
+
+// SchemaError is a value in a config file that doesn't match ConfigSchema
+type SchemaError struct {
+	File    string
+	Line    int
+	Key     string
+	Message string // What is wrong, phrased to follow the key
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s:%d: %s %s", e.File, e.Line, e.Key, e.Message)
+}
+
+// validateFile checks the YAML config file at path against ConfigSchema and
+// returns a SchemaError for each value that doesn't match
+func validateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	var errs []error
+	validateNode(doc.Content[0], ConfigSchema(), "", func(node *yaml.Node, key, message string) {
+		errs = append(errs, &SchemaError{File: path, Line: node.Line, Key: key, Message: message})
+	})
+	return errors.Join(errs...)
+}
+
+// validateNode checks node, the setting key, against schema, reporting each
+// mismatch. Values are checked as leniently as they are decoded, so a number
+// written as a quoted string is accepted.
+func validateNode(node *yaml.Node, schema *Schema, key string, report func(node *yaml.Node, key, message string)) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Tag == "!!null" {
+		return
+	}
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			report(node, key, "must be a section of settings")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name, value := node.Content[i], node.Content[i+1]
+			child := strings.TrimPrefix(key+"."+name.Value, ".")
+			if valueSchema, ok := schema.AdditionalProperties.(*Schema); ok {
+				validateNode(value, valueSchema, child, report)
+			} else if property, ok := schema.Properties[strings.ToLower(name.Value)]; ok {
+				validateNode(value, property, child, report)
+			} else {
+				report(name, child, "is not a setting"+suggestSetting(name.Value, schema))
+			}
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			report(node, key, "must be a list")
+			return
+		}
+		for i, item := range node.Content {
+			validateNode(item, schema.Items, fmt.Sprintf("%s[%d]", key, i), report)
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			report(node, key, "must be "+scalarName(schema))
+			return
+		}
+		validateScalar(node, schema, key, report)
+	}
+}
+
+// validateScalar checks the single value node against schema
+func validateScalar(node *yaml.Node, schema *Schema, key string, report func(node *yaml.Node, key, message string)) {
+	value := node.Value
+	switch {
+	case schema.Pattern == durationPattern:
+		if _, err := time.ParseDuration(value); err != nil && node.Tag != "!!int" {
+			report(node, key, fmt.Sprintf("must be a duration such as 30s or 5m, got %q", value))
+		}
+	case schema.Type == "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			report(node, key, fmt.Sprintf("must be true or false, got %q", value))
+		}
+	case schema.Type == "integer":
+		if _, err := strconv.Atoi(value); err != nil {
+			report(node, key, fmt.Sprintf("must be a whole number, got %q", value))
+		}
+	case schema.Type == "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			report(node, key, fmt.Sprintf("must be a number, got %q", value))
+		} else if schema.Minimum != nil && (number < *schema.Minimum || number > *schema.Maximum) {
+			report(node, key, fmt.Sprintf("must be between %g and %g", *schema.Minimum, *schema.Maximum))
+		}
+	case len(schema.Enum) > 0:
+		if !slices.Contains(schema.Enum, value) {
+			report(node, key, fmt.Sprintf("must be one of: %s", strings.Join(schema.Enum, ", ")))
+		}
+	}
+}
+
+// scalarName describes the single value schema describes
+func scalarName(schema *Schema) string {
+	switch {
+	case schema.Pattern == durationPattern:
+		return "a duration"
+	case schema.Type == "boolean":
+		return "true or false"
+	case schema.Type == "integer":
+		return "a whole number"
+	case schema.Type == "number":
+		return "a number"
+	}
+	return "a single value"
+}
+
+// suggestSetting returns a hint naming the setting of the section schema
+// that name is probably a misspelling of, or "" when none is close
+func suggestSetting(name string, schema *Schema) string {
+	best, bestDistance := "", 3
+	for property := range schema.Properties {
+		if distance := editDistance(strings.ToLower(name), property); distance < bestDistance || distance == bestDistance && property < best {
+			best, bestDistance = property, distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf("; did you mean %s?", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigSchema_MatchesShippedFile(t *testing.T) {
+	schema, err := ConfigSchemaJSON()
+	require.NoError(t, err)
+	shipped, err := os.ReadFile(filepath.Join("..", "..", "docs", "config.schema.json"))
+	require.NoError(t, err)
+	assert.Equal(t, string(shipped), string(schema), "Regenerate it with: go run ./cmd/othello config schema > docs/config.schema.json")
+}
+
+func TestConfigSchema_DescribesSettings(t *testing.T) {
+	schema := ConfigSchema()
+	model := schema.Properties["model"]
+	require.NotNil(t, model)
+	assert.Equal(t, "string", model.Properties["name"].Type)
+	assert.Equal(t, 2.0, *model.Properties["temperature"].Maximum)
+	assert.Equal(t, false, model.AdditionalProperties)
+
+	server := schema.Properties["mcp"].Properties["servers"].Items
+	assert.Equal(t, []string{"stdio", "http"}, server.Properties["transport"].Enum)
+	assert.Equal(t, "string", server.Properties["env"].AdditionalProperties.(*Schema).Type)
+	assert.Equal(t, durationPattern, server.Properties["timeout"].Pattern)
+}
+
+func TestLoad_ReportsSchemaErrorsWithLines(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv(ProfileEnvVar, "")
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	configFile := filepath.Join(homeDir, ".othello", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configFile), 0755))
+	require.NoError(t, os.WriteFile(configFile, []byte(`model:
+  name: "qwen2.5:3b"
+  temprature: 0.5
+  max_tokens: lots
+ollama:
+  timeout: "soon"
+mcp:
+  servers:
+    - name: notes
+      transport: pipe
+logging: debug
+`), 0644))
+
+	_, err = Load()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, configFile+":3: model.temprature is not a setting; did you mean temperature?")
+	assert.ErrorContains(t, err, configFile+":4: model.max_tokens must be a whole number, got \"lots\"")
+	assert.ErrorContains(t, err, configFile+":6: ollama.timeout must be a duration such as 30s or 5m")
+	assert.ErrorContains(t, err, configFile+":10: mcp.servers[0].transport must be one of: stdio, http")
+	assert.ErrorContains(t, err, configFile+":11: logging must be a section of settings")
+
+	require.NoError(t, os.WriteFile(configFile, []byte("model:\n  max_tokens: \"4096\"\n  include_pinned: \"true\"\n"), 0644))
+	cfg, err := Load()
+	require.NoError(t, err, "Values are checked as leniently as they are decoded")
+	assert.Equal(t, 4096, cfg.Model.MaxTokens)
+}
@@ -0,0 +1,354 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Defaults written to a new configuration file
+const (
+	DefaultModelName      = "qwen2.5:3b"
+	DefaultOllamaHost     = "http://localhost:11434"
+	DefaultEmbeddingModel = "nomic-embed-text"
+)
+
+// DefaultShellCommands are the programs run_command may run unless
+// tools.shell.allowed_commands says otherwise
+var DefaultShellCommands = []string{"ls", "pwd", "cat", "head", "tail", "wc", "grep", "find", "echo", "date", "which", "git", "go"}
+
+// DefaultFetchDomains are the documentation sites fetch_url may read unless
+// tools.fetch.allowed_domains says otherwise
+var DefaultFetchDomains = []string{"go.dev", "pkg.go.dev", "docs.python.org", "developer.mozilla.org", "*.readthedocs.io", "github.com", "raw.githubusercontent.com", "en.wikipedia.org"}
+
+// SetupOptions holds the choices made while creating the first configuration file.
+// Empty fields fall back to the defaults.
+type SetupOptions struct {
+	OllamaHost string
+	ModelName  string
+	Servers    []ServerConfig
+}
+
+// RecommendedServer is an MCP server offered during first-run setup
+type RecommendedServer struct {
+	Server      ServerConfig
+	Description string
+}
+
+// RecommendedServers returns the MCP servers suggested to new users
+func RecommendedServers() []RecommendedServer {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+
+	return []RecommendedServer{
+		{
+			Server: ServerConfig{
+				Name:      "filesystem",
+				Command:   "npx",
+				Args:      []string{"-y", "@modelcontextprotocol/server-filesystem", homeDir},
+				Transport: "stdio",
+				Timeout:   30 * time.Second,
+			},
+			Description: "Read and search files in your home directory (requires Node.js)",
+		},
+		{
+			Server: ServerConfig{
+				Name:      "local-memory",
+				Command:   "npx",
+				Args:      []string{"-y", "@danieleugenewilliams/local-memory-server"},
+				Transport: "stdio",
+				Timeout:   30 * time.Second,
+			},
+			Description: "Store and recall memories across conversations (requires Node.js)",
+		},
+	}
+}
+
+// DefaultConfigPath returns ~/.othello/config.yaml
+func DefaultConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".othello", "config.yaml"), nil
+}
+
+// WriteInitialConfig writes a commented configuration file to DefaultConfigPath
+// using opts and returns its path. It refuses to overwrite an existing file.
+func WriteInitialConfig(opts SetupOptions) (string, error) {
+	configFile, err := DefaultConfigPath()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// Check if config file already exists
+	if _, err := os.Stat(configFile); err == nil {
+		return "", fmt.Errorf("config file already exists: %s", configFile)
+	}
+
+	if opts.OllamaHost == "" {
+		opts.OllamaHost = DefaultOllamaHost
+	}
+	if opts.ModelName == "" {
+		opts.ModelName = DefaultModelName
+	}
+
+	var content strings.Builder
+	if err := configTemplate.Execute(&content, opts); err != nil {
+		return "", fmt.Errorf("failed to render config file: %w", err)
+	}
+
+	if err := os.WriteFile(configFile, []byte(content.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return configFile, nil
+}
+
+// configTemplate renders a new configuration file from SetupOptions
+var configTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+	"quoteList": func(values []string) string {
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = strconv.Quote(value)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	},
+}).Parse(`# Othello AI Agent Configuration
+# yaml-language-server: $schema=` + SchemaID + `
+
+# Model configuration
+model:
+  type: "ollama"           # Model provider (ollama)
+  name: {{quote .ModelName}}       # Model name
+  temperature: 0.7         # Response creativity (0.0-2.0)
+  max_tokens: 2048         # Maximum response length
+  context_length: 8192     # Context window size
+  embedding_model: "nomic-embed-text"  # Local embedding model for /search
+  include_pinned: false    # Send messages pinned with /pin with every request
+  pricing:                 # Price per million tokens, shown by /usage for hosted models
+    input_per_million: 0
+    output_per_million: 0
+
+# Ollama configuration
+ollama:
+  host: {{quote .OllamaHost}}  # Ollama server URL
+  timeout: "30s"                  # Request timeout
+
+# Terminal UI configuration
+tui:
+  theme: "default"         # UI theme
+  show_hints: true         # Show keyboard hints
+  auto_scroll: true        # Auto-scroll to new messages
+  render_mode: "auto"      # auto (detect terminal), full, or plain (no color/unicode)
+  accessible: false        # Screen-reader friendly: plain text, high contrast, announces changes
+
+# MCP server configuration
+mcp:
+{{- if .Servers}}
+  servers:
+{{- range .Servers}}
+    - name: {{quote .Name}}
+      command: {{quote .Command}}
+      args: {{quoteList .Args}}
+      transport: {{quote .Transport}}
+      timeout: "{{.Timeout}}"
+{{- end}}
+{{- else}}
+  servers: []              # List of MCP servers (empty by default)
+  # Example server configuration:
+  # - name: "filesystem"
+  #   command: "mcp-filesystem"
+  #   args: ["--root", "~/notes"]
+  #   env:
+  #     TOKEN: "${env:FS_TOKEN}"  # Or "${file:~/.secrets/fs-token}"
+  #   transport: "stdio"
+  #   timeout: "10s"
+  #   sandbox:                # Reduced privileges for servers you don't trust
+  #     inherit_env: ["PATH", "HOME"]  # Only these of your variables are passed on
+  #     memory_mb: 512        # Limits apply on Linux; 0 or unset applies none
+  #     cpu_seconds: 300
+  #     max_files: 256
+{{- end}}
+
+# Storage configuration
+storage:
+  history_size: 1000       # Maximum conversation history
+  cache_ttl: "1h"          # Tool cache time-to-live
+  data_dir: "~/.othello"   # Data directory
+  retention:               # Limits on saved conversations; 0 keeps everything
+    max_conversations: 0   # Most conversations kept
+    max_age: "0s"          # Delete conversations idle longer, e.g. "720h"
+    max_size_mb: 0         # Delete the oldest conversations to stay under this size
+    prune_interval: "1h"   # How often to prune while running; "0s" disables
+    vacuum_interval: "168h"  # Least time between database compactions
+
+# Built-in memory: facts kept across conversations without an MCP memory server
+memory:
+  enabled: true            # Let the model remember and recall facts
+  auto_recall: true        # Send related memories with each message
+  recall_limit: 5          # Most memories sent with a message
+
+# Project index: related parts of local files are sent with each message
+index:
+  dirs: []                 # Directories to index when the TUI starts, e.g. ["~/code/myproject"]
+  auto_retrieve: true      # Send related parts of indexed files with each message
+  retrieve_limit: 4        # Most parts sent with a message
+  max_file_size: 262144    # Larger files, in bytes, are not indexed
+
+# Tools built into the agent, available without any MCP server
+tools:
+  # run_command runs a program with arguments; there is no shell, so pipes,
+  # redirects, and globs don't work
+  shell:
+    enabled: true          # Offer run_command to the model
+    require_approval: true # Ask before each command; without the chat to ask in, commands are refused
+    allowed_commands: ["ls", "pwd", "cat", "head", "tail", "wc", "grep", "find", "echo", "date", "which", "git", "go"]
+    timeout: "30s"         # Commands running longer are killed
+    max_output: 16384      # Most bytes of output returned to the model
+    working_dir: ""        # Directory commands run in ("" is the current directory)
+  # read_file, write_file, list_directory, and search_files
+  files:
+    enabled: true          # Offer the file tools to the model
+    roots: []              # Directories they may use, e.g. ["~/code"] (none is the current directory)
+    allow_write: true      # Offer write_file; writes are approved like other changes
+    max_read: 65536        # Most bytes read_file returns
+  # fetch_url downloads documentation pages, converting HTML to markdown
+  fetch:
+    enabled: true          # Offer fetch_url to the model
+    # Hosts that may be fetched; patterns like "*.example.com" work, "*" allows any
+    allowed_domains: ["go.dev", "pkg.go.dev", "docs.python.org", "developer.mozilla.org", "*.readthedocs.io", "github.com", "raw.githubusercontent.com", "en.wikipedia.org"]
+    allow_private: false   # Fetch from this computer and the local network too
+    timeout: "20s"         # Requests taking longer are cancelled
+    max_size: 2097152      # Larger responses, in bytes, are refused
+    max_output: 20000      # Most characters of a page returned to the model
+  # read_clipboard and write_clipboard use the system clipboard
+  clipboard:
+    enabled: true          # Offer the clipboard tools to the model
+    require_approval: true # Ask before each use; without the chat to ask in, calls are refused
+    max_read: 20000        # Most characters read_clipboard returns
+
+# How the agent works through a request
+agent:
+  max_iterations: 5        # Most times tool results go back to the model per message (0 shows them as the answer)
+  # Ask before running tool calls: always, never, or destructive (only when
+  # a tool deletes or changes data)
+  plan_approval: "destructive"
+  tool_retries: 2          # Most times the model may correct a tool call after an error
+  summarize_over: 6000     # Characters above which the model summarizes a tool result (0 never)
+  dedupe_calls: true       # Reuse the result when the model repeats a tool call in the same request
+  process_parallel: 4      # Most results of a turn's tool calls processed and summarized at once
+  turn_timeout: "5m"       # Time one message's model requests and tool calls may take together (0 allows any)
+  # Neutralize text in tool results that reads as instructions to the model:
+  # off, standard, or strict (also catches phrasing ordinary text may use)
+  injection_guard: "standard"
+  # Block network access other than to ollama.host, and run only stdio MCP
+  # servers, so nothing leaves this computer
+  offline: false
+  # Result transformers to run for particular tools, in order, instead of
+  # error_check, metadata, format, context, summarize
+  # result_pipelines:
+  #   read_file: [error_check, text, context]
+  # Sub-agents: the model can delegate research tasks to run in parallel
+  subagents:
+    enabled: true          # Offer the delegate tool to the model
+    max_parallel: 3        # Most sub-agents running at once
+    max_tasks: 5           # Most tasks in one delegation
+    max_rounds: 4          # Most model calls per sub-agent
+    token_budget: 8000     # Most tokens each sub-agent uses
+  # Older messages are summarized when a request nears model.context_length
+  compaction:
+    threshold: 0.8         # Fraction of the context window that triggers it (0 never)
+    keep_recent: 4         # Latest messages kept word for word
+  # How requests are classified to suggest tools: by keywords, or by a model
+  # with keywords as the fallback
+  intent:
+    classifier: "keywords"  # keywords or model
+    model: ""              # Small, fast model to classify with ("" uses model.name)
+    timeout: "10s"         # Longest wait for the model
+  # Answers to repeated questions, kept until the TTL passes or a tool runs
+  response_cache:
+    enabled: true
+    ttl: "2m"              # How long an answer is kept
+    max_entries: 100       # Most answers kept
+  # Session mode new conversations start in; switch with /mode <name>
+  mode: "chat"             # chat, analysis, or automation
+  # Personas: system prompts to switch between with /persona <name>
+  persona: ""              # Persona to start with
+  # personas:
+  #   - name: "reviewer"
+  #     system_prompt: "You review code changes for bugs and unclear names."
+  #     tools: ["read_file", "search"]   # Offered first and recommended
+  #     temperature: 0.2                 # Replaces model.temperature
+  # Tool policies: calls that break one are refused before they run
+  # tool_policies:
+  #   - tool: "write_*"                 # Tool name or glob pattern
+  #     max_calls_per_turn: 3           # Most calls per request (0 no limit)
+  #     arguments:
+  #       path:
+  #         paths: ["~/projects"]       # Directories paths must be inside
+  #         pattern: "\\.(go|md)$"      # Regular expression values must match
+  #       mode:
+  #         banned: ["overwrite"]       # Values refused
+  # Hooks: programs run with the event as JSON on stdin, which may write it
+  # back changed on stdout (pre_tool_call, post_tool_call, pre_prompt,
+  # on_response)
+  # hooks:
+  #   - event: "pre_tool_call"
+  #     command: ["~/.othello/hooks/guard.sh"]
+  #     tools: ["run_command"]          # Tool events only; empty runs for all
+  #     timeout: "10s"
+  # Undo: the call /undo makes to reverse an MCP tool's changes, with
+  # arguments filled from the call's arguments and its JSON result
+  # undo:
+  #   - tool: "store_memory"
+  #     inverse: "delete_memory"
+  #     arguments:
+  #       memory_id: "{{"{{.memory_id}}"}}"
+
+# othello serve: post events (conversation_completed, tool_failed,
+# scheduled_task) as JSON to webhooks, signed with HMAC-SHA256 when a secret
+# is set. othello serve slack and othello serve discord answer messages from
+# a Slack app or a Discord slash command, one conversation per channel.
+# serve:
+#   webhooks:
+#     - url: "https://hooks.example.com/othello"
+#       events: [tool_failed]   # Leave out to send every event
+#       secret: "${secret:webhook-key}"
+#       retries: 3
+#       timeout: "10s"
+#   slack:
+#     bot_token: "${secret:slack-bot-token}"
+#     signing_secret: "${secret:slack-signing-secret}"
+#     channels: ["C0123456789"]  # Leave out to answer every channel the app is in
+#   discord:
+#     application_id: "123456789012345678"
+#     public_key: "<hex public key from the developer portal>"
+#     command: "ask"
+
+# /share: sanitized transcripts of a conversation, written to the data
+# directory and optionally uploaded
+share:
+  upload: "none"           # Where to upload: none, paste, or gist
+  endpoint: ""             # URL a paste service takes posts at; "" with gist uses the GitHub API
+  token: ""                # Bearer token for the upload, such as "${secret:github-token}"
+  timeout: "30s"           # Uploads taking longer are cancelled
+
+# Logging configuration
+logging:
+  level: "info"            # Log level (debug, info, warn, error)
+  file: "~/.othello/logs/othello.log"  # Log file path
+  format: "text"           # Log format (text, json)
+  watchdog_interval: "1m"  # How often to check for memory and goroutine growth; "0s" disables
+`))
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteInitialConfig(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+
+	servers := []ServerConfig{RecommendedServers()[0].Server}
+	path, err := WriteInitialConfig(SetupOptions{
+		OllamaHost: "http://gpu-box:11434",
+		ModelName:  "llama3.1:8b",
+		Servers:    servers,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(homeDir, ".othello", "config.yaml"), path)
+
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.HasConfigFile())
+	assert.Equal(t, path, cfg.ConfigFile())
+	assert.Equal(t, "llama3.1:8b", cfg.Model.Name)
+	assert.Equal(t, "http://gpu-box:11434", cfg.Ollama.Host)
+	require.Len(t, cfg.MCP.Servers, 1)
+	assert.Equal(t, "filesystem", cfg.MCP.Servers[0].Name)
+	assert.Equal(t, servers[0].Args, cfg.MCP.Servers[0].Args)
+	assert.Equal(t, 30*time.Second, cfg.MCP.Servers[0].Timeout)
+
+	// An existing config file is never overwritten
+	_, err = WriteInitialConfig(SetupOptions{})
+	assert.ErrorContains(t, err, "config file already exists")
+}
+
+func TestWriteInitialConfig_Defaults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	path, err := WriteInitialConfig(SetupOptions{})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `name: "qwen2.5:3b"`)
+	assert.Contains(t, string(data), `host: "http://localhost:11434"`)
+	assert.Contains(t, string(data), "servers: []")
+}
+
+func TestLoad_WithoutConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.HasConfigFile())
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Source identifies where an effective configuration value came from
+type Source string
+
+// Configuration sources, lowest to highest precedence
+const (
+	SourceDefault   Source = "default"
+	SourceFile      Source = "file"
+	SourceProfile   Source = "profile"
+	SourceWorkspace Source = "workspace"
+	SourceEnv       Source = "env"
+	SourceFlag      Source = "flag"
+)
+
+// Precedence describes the order in which sources override each other
+const Precedence = "default < file < profile < workspace < env < flag"
+
+// envPrefix is prepended to environment variable names, e.g. OTHELLO_MODEL_NAME
+const envPrefix = "OTHELLO"
+
+// flagBindings maps command-line flag names to the settings they override
+var flagBindings = map[string]string{
+	"model": "model.name",
+}
+
+// Setting is a single effective configuration value and where it came from
+type Setting struct {
+	Key    string
+	Value  interface{}
+	Source Source
+	Origin string // file path, environment variable, or flag that supplied the value
+}
+
+// EnvVarName returns the environment variable that overrides key
+func EnvVarName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// EffectiveSettings returns every effective setting, sorted by key
+func (c *Config) EffectiveSettings() []Setting {
+	settings := make([]Setting, len(c.settings))
+	copy(settings, c.settings)
+	return settings
+}
+
+// SourceOf returns where the effective value of key came from
+func (c *Config) SourceOf(key string) Source {
+	for _, setting := range c.settings {
+		if setting.Key == key {
+			return setting.Source
+		}
+	}
+	return SourceDefault
+}
+
+// bindFlags lets the known flags present in flags override their settings
+func bindFlags(v *viper.Viper, flags *pflag.FlagSet) error {
+	if flags == nil {
+		return nil
+	}
+	for name, key := range flagBindings {
+		if flag := flags.Lookup(name); flag != nil {
+			if err := v.BindPFlag(key, flag); err != nil {
+				return fmt.Errorf("error binding --%s flag: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveSources attributes every key in v to the highest-precedence source that set it.
+// fileKeys holds the keys present in the global config file.
+func (c *Config) resolveSources(v *viper.Viper, fileKeys map[string]bool, flags *pflag.FlagSet) {
+	flagForKey := make(map[string]string, len(flagBindings))
+	for name, key := range flagBindings {
+		flagForKey[key] = name
+	}
+
+	keys := v.AllKeys()
+	sort.Strings(keys)
+
+	c.settings = make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		setting := Setting{Key: key, Value: v.Get(key), Source: SourceDefault}
+
+		name, bound := flagForKey[key]
+		switch {
+		case bound && flags != nil && flags.Changed(name):
+			setting.Source = SourceFlag
+			setting.Origin = "--" + name
+		case envIsSet(key):
+			setting.Source = SourceEnv
+			setting.Origin = EnvVarName(key)
+		case c.IsWorkspaceOverride(key):
+			setting.Source = SourceWorkspace
+			setting.Origin = c.workspaceFile
+		case c.profileKeys[key]:
+			setting.Source = SourceProfile
+			setting.Origin = c.profileFile
+		case fileKeys[key]:
+			setting.Source = SourceFile
+			setting.Origin = c.configFile
+		}
+
+		c.settings = append(c.settings, setting)
+	}
+}
+
+// envIsSet reports whether the environment variable for key is set; like viper,
+// an empty value counts as unset
+func envIsSet(key string) bool {
+	return os.Getenv(EnvVarName(key)) != ""
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvVarName(t *testing.T) {
+	assert.Equal(t, "OTHELLO_MODEL_NAME", EnvVarName("model.name"))
+	assert.Equal(t, "OTHELLO_STORAGE_CACHE_TTL", EnvVarName("storage.cache_ttl"))
+}
+
+func TestLoadWithFlags_SourceAttribution(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configContent := `
+model:
+  temperature: 0.5
+  max_tokens: 1000
+logging:
+  level: "debug"
+`
+	workspaceContent := `
+model:
+  name: "qwen2.5-coder:7b"
+  temperature: 0.2
+  context_length: 32768
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte(configContent), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, WorkspaceFileName), []byte(workspaceContent), 0644))
+
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(tempDir))
+
+	t.Setenv("OTHELLO_MODEL_CONTEXT_LENGTH", "4096")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("model", "", "")
+	require.NoError(t, flags.Parse([]string{"--model", "llama3"}))
+
+	cfg, err := LoadWithFlags(flags)
+	require.NoError(t, err)
+
+	// Each layer overrides the ones below it
+	assert.Equal(t, "llama3", cfg.Model.Name)
+	assert.Equal(t, 4096, cfg.Model.ContextLength)
+	assert.Equal(t, 0.2, cfg.Model.Temperature)
+	assert.Equal(t, 1000, cfg.Model.MaxTokens)
+
+	assert.Equal(t, SourceFlag, cfg.SourceOf("model.name"))
+	assert.Equal(t, SourceEnv, cfg.SourceOf("model.context_length"))
+	assert.Equal(t, SourceWorkspace, cfg.SourceOf("model.temperature"))
+	assert.Equal(t, SourceFile, cfg.SourceOf("model.max_tokens"))
+	assert.Equal(t, SourceFile, cfg.SourceOf("logging.level"))
+	assert.Equal(t, SourceDefault, cfg.SourceOf("ollama.host"))
+
+	origins := make(map[string]string)
+	for _, setting := range cfg.EffectiveSettings() {
+		origins[setting.Key] = setting.Origin
+	}
+	assert.Equal(t, "--model", origins["model.name"])
+	assert.Equal(t, "OTHELLO_MODEL_CONTEXT_LENGTH", origins["model.context_length"])
+	assert.Contains(t, origins["model.temperature"], WorkspaceFileName)
+	assert.Contains(t, origins["model.max_tokens"], "config.yaml")
+	assert.Empty(t, origins["ollama.host"])
+}
+
+func TestLoadWithFlags_UnchangedFlagDoesNotOverride(t *testing.T) {
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("model", "", "")
+	require.NoError(t, flags.Parse(nil))
+
+	cfg, err := LoadWithFlags(flags)
+	require.NoError(t, err)
+
+	assert.Equal(t, "qwen2.5:3b", cfg.Model.Name)
+	assert.Equal(t, SourceDefault, cfg.SourceOf("model.name"))
+}
+
+func TestEffectiveSettingsAreSorted(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	settings := cfg.EffectiveSettings()
+	require.NotEmpty(t, settings)
+	for i := 1; i < len(settings); i++ {
+		assert.Less(t, settings[i-1].Key, settings[i].Key)
+	}
+}
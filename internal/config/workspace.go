@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// WorkspaceFileName is the per-project settings file, looked up from the
+// working directory towards the filesystem root
+const WorkspaceFileName = "workspace.yaml"
+
+// workspaceKeys are the settings a workspace file may override
+var workspaceKeys = []string{
+	"model.name",
+	"model.temperature",
+	"model.context_length",
+}
+
+// FindWorkspaceFile returns the nearest workspace.yaml in dir or one of its parents,
+// checking both <dir>/workspace.yaml and <dir>/.othello/workspace.yaml
+func FindWorkspaceFile(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	// The global ~/.othello directory is not a workspace
+	homeDir, _ := os.UserHomeDir()
+
+	for {
+		candidates := []string{
+			filepath.Join(dir, WorkspaceFileName),
+			filepath.Join(dir, ".othello", WorkspaceFileName),
+		}
+		for _, candidate := range candidates {
+			if filepath.Dir(candidate) == filepath.Join(homeDir, ".othello") {
+				continue
+			}
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate, true
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// applyWorkspace merges the workspace file at path over v and returns the keys it overrode
+func applyWorkspace(v *viper.Viper, path string) ([]string, error) {
+	if err := validateFile(path); err != nil {
+		return nil, err
+	}
+	ws := viper.New()
+	ws.SetConfigFile(path)
+	ws.SetConfigType("yaml")
+	if err := ws.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading workspace file %s: %w", path, err)
+	}
+
+	allowed := make(map[string]bool, len(workspaceKeys))
+	for _, key := range workspaceKeys {
+		allowed[key] = true
+	}
+
+	var overridden []string
+	for _, key := range ws.AllKeys() {
+		if !allowed[key] {
+			return nil, fmt.Errorf("workspace file %s: %s cannot be overridden per workspace (allowed: model.name, model.temperature, model.context_length)", path, key)
+		}
+		overridden = append(overridden, key)
+	}
+	sort.Strings(overridden)
+
+	// Merge into the config file layer so environment variables and flags still win
+	if err := v.MergeConfigMap(ws.AllSettings()); err != nil {
+		return nil, fmt.Errorf("error merging workspace file %s: %w", path, err)
+	}
+
+	return overridden, nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindWorkspaceFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "src", "pkg")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	_, ok := FindWorkspaceFile(nested)
+	assert.False(t, ok)
+
+	// A workspace file in a parent directory applies to nested directories
+	workspaceFile := filepath.Join(root, ".othello", WorkspaceFileName)
+	require.NoError(t, os.MkdirAll(filepath.Dir(workspaceFile), 0755))
+	require.NoError(t, os.WriteFile(workspaceFile, []byte("model:\n  name: coder\n"), 0644))
+
+	found, ok := FindWorkspaceFile(nested)
+	require.True(t, ok)
+	assert.Equal(t, workspaceFile, found)
+
+	// The nearest file wins
+	closer := filepath.Join(root, "src", WorkspaceFileName)
+	require.NoError(t, os.WriteFile(closer, []byte("model:\n  name: other\n"), 0644))
+
+	found, ok = FindWorkspaceFile(nested)
+	require.True(t, ok)
+	assert.Equal(t, closer, found)
+}
+
+func TestLoadWithWorkspaceOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configContent := `
+model:
+  name: "global-model"
+  temperature: 0.5
+  max_tokens: 1000
+`
+	workspaceContent := `
+model:
+  name: "qwen2.5-coder:7b"
+  context_length: 32768
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte(configContent), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, WorkspaceFileName), []byte(workspaceContent), 0644))
+
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(tempDir))
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	// Workspace values win over the global config
+	assert.Equal(t, "qwen2.5-coder:7b", cfg.Model.Name)
+	assert.Equal(t, 32768, cfg.Model.ContextLength)
+
+	// Settings the workspace doesn't mention keep their global values
+	assert.Equal(t, 0.5, cfg.Model.Temperature)
+	assert.Equal(t, 1000, cfg.Model.MaxTokens)
+
+	assert.Contains(t, cfg.WorkspaceFile(), WorkspaceFileName)
+	assert.Equal(t, []string{"model.context_length", "model.name"}, cfg.WorkspaceOverrides())
+	assert.True(t, cfg.IsWorkspaceOverride("model.name"))
+	assert.False(t, cfg.IsWorkspaceOverride("model.temperature"))
+}
+
+func TestLoadRejectsUnsupportedWorkspaceKeys(t *testing.T) {
+	tempDir := t.TempDir()
+	workspaceContent := `
+ollama:
+  host: "http://elsewhere:11434"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, WorkspaceFileName), []byte(workspaceContent), 0644))
+
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(tempDir))
+
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ollama.host cannot be overridden per workspace")
+}
+
+func TestLoadValidatesWorkspaceValues(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, WorkspaceFileName), []byte("model:\n  temperature: 3\n"), 0644))
+
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalWd)
+	require.NoError(t, os.Chdir(tempDir))
+
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model.temperature must be between 0 and 2")
+}
@@ -0,0 +1,120 @@
+package events
+
+import "sync"
+
+// subscriberBuffer is how many events a subscriber can fall behind by before
+// it misses new ones
+const subscriberBuffer = 100
+
+// Bus delivers each published event to every subscriber. It keeps the latest
+// events so that subscribers that join late, such as a TUI started after the
+// servers connected, see them too.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	history     []Event
+	historySize int
+}
+
+// Subscription receives the events published on a Bus after it was made,
+// following the ones the bus replays
+type Subscription struct {
+	bus    *Bus
+	events chan Event
+}
+
+// NewBus returns a bus that replays up to historySize of the latest events
+// to new subscribers
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		subscribers: make(map[*Subscription]struct{}),
+		historySize: historySize,
+	}
+}
+
+// Publish sends event to every subscriber. It never blocks: a subscriber
+// that has fallen too far behind misses the event.
+func (b *Bus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.historySize > 0 {
+		b.history = append(b.history, event)
+		if len(b.history) > b.historySize {
+			b.history = b.history[len(b.history)-b.historySize:]
+		}
+	}
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+// History returns a copy of the events the bus keeps, oldest first
+func (b *Bus) History() []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Event(nil), b.history...)
+}
+
+// Subscribers returns how many subscriptions are open
+func (b *Bus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers)
+}
+
+// Subscribe returns a subscription that receives the events the bus keeps,
+// oldest first, and then every event published until it is closed
+func (b *Bus) Subscribe() *Subscription {
+	return b.subscribe(true)
+}
+
+// SubscribeNew returns a subscription that receives only the events
+// published after it was made, for subscribers that act on events rather
+// than show them
+func (b *Bus) SubscribeNew() *Subscription {
+	return b.subscribe(false)
+}
+
+// subscribe returns a new subscription, which first receives the events the
+// bus keeps when replay is set
+func (b *Bus) subscribe(replay bool) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replayed []Event
+	if replay {
+		replayed = b.history
+	}
+	sub := &Subscription{
+		bus:    b,
+		events: make(chan Event, subscriberBuffer+len(replayed)),
+	}
+	for _, event := range replayed {
+		sub.events <- event
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Events returns the channel the subscription's events arrive on. It is
+// closed when the subscription is.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close stops the subscription's events. Closing it again does nothing.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if _, ok := s.bus.subscribers[s]; ok {
+		delete(s.bus.subscribers, s)
+		close(s.events)
+	}
+}
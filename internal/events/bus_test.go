@@ -0,0 +1,93 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drain(sub *Subscription) []Event {
+	var received []Event
+	for {
+		select {
+		case event := <-sub.Events():
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
+
+func TestBus_DeliversToEverySubscriber(t *testing.T) {
+	bus := NewBus(0)
+	first, second := bus.Subscribe(), bus.Subscribe()
+
+	bus.Publish(ServerStatusEvent{Server: "memory", Connected: true, ToolCount: 3})
+	bus.Publish(LogEvent{Text: "ready"})
+
+	want := []Event{ServerStatusEvent{Server: "memory", Connected: true, ToolCount: 3}, LogEvent{Text: "ready"}}
+	assert.Equal(t, want, drain(first))
+	assert.Equal(t, want, drain(second))
+}
+
+func TestBus_ReplaysRecentEventsToLateSubscribers(t *testing.T) {
+	bus := NewBus(2)
+	bus.Publish(LogEvent{Text: "starting"})
+	bus.Publish(ServerStatusEvent{Server: "memory", Connected: true})
+	bus.Publish(ToolsChangedEvent{Server: "memory", Added: []string{"search"}})
+
+	late := bus.Subscribe()
+	bus.Publish(NotificationEvent{Text: "Chaos mode enabled", Level: LevelWarning})
+
+	assert.Equal(t, []Event{
+		ServerStatusEvent{Server: "memory", Connected: true},
+		ToolsChangedEvent{Server: "memory", Added: []string{"search"}},
+		NotificationEvent{Text: "Chaos mode enabled", Level: LevelWarning},
+	}, drain(late), "Only the latest two events are kept")
+
+	history := bus.History()
+	assert.Equal(t, []Event{
+		ToolsChangedEvent{Server: "memory", Added: []string{"search"}},
+		NotificationEvent{Text: "Chaos mode enabled", Level: LevelWarning},
+	}, history)
+	history[0] = LogEvent{Text: "changed"}
+	assert.Equal(t, ToolsChangedEvent{Server: "memory", Added: []string{"search"}}, bus.History()[0], "History is a copy")
+}
+
+func TestBus_SlowSubscribersMissEventsWithoutBlocking(t *testing.T) {
+	bus := NewBus(0)
+	slow := bus.Subscribe()
+	for i := 0; i < subscriberBuffer+10; i++ {
+		bus.Publish(TokenUsageEvent{TotalTokens: i})
+	}
+
+	received := drain(slow)
+	require.Len(t, received, subscriberBuffer)
+	assert.Equal(t, TokenUsageEvent{TotalTokens: 0}, received[0])
+}
+
+func TestSubscription_Close(t *testing.T) {
+	bus := NewBus(10)
+	sub := bus.Subscribe()
+	assert.Equal(t, 1, bus.Subscribers())
+	sub.Close()
+	sub.Close()
+	assert.Equal(t, 0, bus.Subscribers())
+
+	bus.Publish(LogEvent{Text: "after close"})
+	_, open := <-sub.Events()
+	assert.False(t, open)
+}
+
+func TestBus_SubscribeNewSkipsHistory(t *testing.T) {
+	bus := NewBus(5)
+	bus.Publish(LogEvent{Text: "starting"})
+
+	sub := bus.SubscribeNew()
+	defer sub.Close()
+	bus.Publish(ScheduledTaskEvent{Task: "history_pruning"})
+
+	assert.Equal(t, []Event{ScheduledTaskEvent{Task: "history_pruning"}}, drain(sub))
+	assert.Len(t, bus.History(), 2, "The bus still keeps every event")
+}
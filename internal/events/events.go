@@ -0,0 +1,111 @@
+// Package events carries what happens in the agent, such as servers
+// connecting, tools running, and tokens spent, to the TUI and any other
+// subscriber.
+package events
+
+import "time"
+
+// Event is something that happened in the agent. Kind names the event's
+// type, such as "server_status".
+type Event interface {
+	Kind() string
+}
+
+// Level is how much a notification matters to the user
+type Level int
+
+const (
+	LevelInfo Level = iota
+	LevelSuccess
+	LevelWarning
+	LevelError
+)
+
+// ServerStatusEvent is an MCP server connecting or disconnecting
+type ServerStatusEvent struct {
+	Server    string
+	Connected bool
+	ToolCount int
+	Error     string // Why the server disconnected or failed to connect
+}
+
+// ToolsChangedEvent is a server's tools changing while it is connected
+type ToolsChangedEvent struct {
+	Server  string
+	Added   []string
+	Removed []string
+}
+
+// ToolExecutedEvent is a tool call finishing
+type ToolExecutedEvent struct {
+	Tool     string
+	Success  bool
+	Duration time.Duration
+	Error    string // Why the call failed
+}
+
+// ToolOutputEvent is output a tool streamed while it runs, before its result
+type ToolOutputEvent struct {
+	Tool string
+	Text string
+}
+
+// TokenUsageEvent is the tokens one model request used
+type TokenUsageEvent struct {
+	Source           string // What made the request, such as "request" or "sub-agent"
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Duration         time.Duration
+}
+
+// NotificationEvent is a short message for the user, shown by the TUI as a
+// toast
+type NotificationEvent struct {
+	Text  string
+	Level Level
+}
+
+// LogEvent is a line written to the agent's log
+type LogEvent struct {
+	Text string
+}
+
+// ConfigReloadedEvent is the configuration files changing while the agent
+// runs. Changes lists what was applied; settings that only take effect on
+// restart are listed in Restart.
+type ConfigReloadedEvent struct {
+	Changes     []string
+	Restart     []string
+	Temperature float64 // The model temperature now in effect
+	Error       string  // Why the changed files couldn't be loaded
+}
+
+// ConversationCompletedEvent is a request to the agent, such as one from
+// the HTTP API, being answered
+type ConversationCompletedEvent struct {
+	ConversationID string // "" when the exchange couldn't be saved
+	ToolCalls      int
+	TotalTokens    int
+	Duration       time.Duration
+}
+
+// ScheduledTaskEvent is a task the agent runs on a schedule, such as
+// pruning history, finishing a run
+type ScheduledTaskEvent struct {
+	Task     string
+	Detail   string // What the run did
+	Duration time.Duration
+	Error    string // Why the run failed
+}
+
+func (ServerStatusEvent) Kind() string          { return "server_status" }
+func (ToolsChangedEvent) Kind() string          { return "tools_changed" }
+func (ToolExecutedEvent) Kind() string          { return "tool_executed" }
+func (ToolOutputEvent) Kind() string            { return "tool_output" }
+func (TokenUsageEvent) Kind() string            { return "token_usage" }
+func (NotificationEvent) Kind() string          { return "notification" }
+func (LogEvent) Kind() string                   { return "log" }
+func (ConfigReloadedEvent) Kind() string        { return "config_reloaded" }
+func (ConversationCompletedEvent) Kind() string { return "conversation_completed" }
+func (ScheduledTaskEvent) Kind() string         { return "scheduled_task" }
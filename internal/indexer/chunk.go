@@ -0,0 +1,47 @@
+package indexer
+
+import "strings"
+
+const (
+	// chunkLines is the most lines in a chunk
+	chunkLines = 40
+
+	// chunkChars is the most characters in a chunk, for files with long lines
+	chunkChars = 2000
+
+	// chunkOverlap is how many lines each chunk repeats from the end of the
+	// one before, so code near a boundary is seen with its context. Short
+	// chunks repeat at most half their lines.
+	chunkOverlap = 8
+)
+
+// chunk is a run of lines from a file
+type chunk struct {
+	startLine int // Counting from 1
+	endLine   int // Inclusive
+	content   string
+}
+
+// chunkText splits text into overlapping runs of lines. Runs with nothing but
+// whitespace are dropped.
+func chunkText(text string) []chunk {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	var chunks []chunk
+	for start := 0; start < len(lines); {
+		end, size := start, 0
+		for end < len(lines) && end-start < chunkLines && (end == start || size+len(lines[end]) <= chunkChars) {
+			size += len(lines[end]) + 1
+			end++
+		}
+
+		content := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(content) != "" {
+			chunks = append(chunks, chunk{startLine: start + 1, endLine: end, content: content})
+		}
+		if end == len(lines) {
+			break
+		}
+		start = end - min(chunkOverlap, (end-start)/2)
+	}
+	return chunks
+}
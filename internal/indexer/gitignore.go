@@ -0,0 +1,142 @@
+package indexer
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is one pattern from a .gitignore file
+type ignoreRule struct {
+	base    string // Directory of the .gitignore file, relative to the root; "" for the root
+	pattern *regexp.Regexp
+	negate  bool // The pattern starts with "!", so matching paths are kept
+	dirOnly bool // The pattern ends with "/", so it only matches directories
+	rooted  bool // The pattern contains a "/", so it matches paths from base rather than names
+}
+
+// ignoreRules holds the rules of every .gitignore file read so far. As in
+// git, a later rule wins over an earlier one, and rules from a deeper
+// directory come after those of its parents.
+type ignoreRules struct {
+	rules []ignoreRule
+}
+
+// load reads the .gitignore file in dir, which is base relative to the root,
+// if there is one
+func (r *ignoreRules) load(dir, base string) error {
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text(), base); ok {
+			r.rules = append(r.rules, rule)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseIgnoreRule parses one line of a .gitignore file. Blank lines and
+// comments are not rules.
+func parseIgnoreRule(line, base string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.rooted = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	pattern, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.pattern = pattern
+	return rule, true
+}
+
+// globToRegexp translates a gitignore glob: "*" and "?" stay within a path
+// segment, and "**" crosses segments
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**/") {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(glob[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			if end := strings.IndexByte(glob[i:], ']'); end > 0 {
+				class := glob[i+1 : i+end]
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				b.WriteString("[" + class + "]")
+				i += end
+			} else {
+				b.WriteString(`\[`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// ignored reports whether rel, a path relative to the root with forward
+// slashes, is ignored
+func (r *ignoreRules) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range r.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		target := rel
+		if rule.base != "" {
+			if !strings.HasPrefix(rel, rule.base+"/") {
+				continue
+			}
+			target = strings.TrimPrefix(rel, rule.base+"/")
+		}
+		if !rule.rooted {
+			target = path.Base(target)
+		}
+		if rule.pattern.MatchString(target) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,57 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rulesFrom(base string, lines ...string) *ignoreRules {
+	rules := &ignoreRules{}
+	for _, line := range lines {
+		if rule, ok := parseIgnoreRule(line, base); ok {
+			rules.rules = append(rules.rules, rule)
+		}
+	}
+	return rules
+}
+
+func TestIgnoreRules(t *testing.T) {
+	rules := rulesFrom("", "# build output", "", "*.log", "!keep.log", "/bin", "build/", "docs/**/draft.md", "tmp?")
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"logs/server.log", false, true},
+		{"logs/keep.log", false, false},
+		{"bin", true, true},
+		{"cmd/bin", true, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"src/build", true, true},
+		{"docs/draft.md", false, true},
+		{"docs/a/b/draft.md", false, true},
+		{"draft.md", false, false},
+		{"tmp1", false, true},
+		{"tmp", false, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.ignored, rules.ignored(tt.path, tt.isDir), tt.path)
+	}
+}
+
+func TestIgnoreRules_NestedFilesApplyBelowTheirDirectory(t *testing.T) {
+	rules := rulesFrom("", "*.gen.go")
+	rules.rules = append(rules.rules, rulesFrom("web", "node_modules/", "/dist", "!api.gen.go").rules...)
+
+	assert.True(t, rules.ignored("web/node_modules", true))
+	assert.True(t, rules.ignored("web/dist", true))
+	assert.False(t, rules.ignored("web/src/dist", true), "A rooted pattern is relative to its .gitignore")
+	assert.False(t, rules.ignored("dist", true), "Rules don't apply outside their directory")
+	assert.True(t, rules.ignored("types.gen.go", false))
+	assert.False(t, rules.ignored("web/api.gen.go", false), "Deeper rules win")
+}
@@ -0,0 +1,231 @@
+// Package indexer keeps an index of local project files so the parts related
+// to a message can be sent to the model with it. Files are split into
+// overlapping chunks of lines, embedded, and stored in the conversation
+// database; files listed in .gitignore are skipped.
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// embedBatchSize is how many chunks are embedded per request
+const embedBatchSize = 32
+
+// Indexer chunks and embeds the files of project directories and finds the
+// chunks related to a query
+type Indexer struct {
+	store       *storage.ConversationStore
+	embedder    storage.Embedder
+	model       string
+	maxFileSize int64
+}
+
+// Result counts what indexing a directory did
+type Result struct {
+	Root      string // Absolute path of the directory
+	Updated   int    // Files embedded because they were new or changed
+	Unchanged int    // Files already indexed as they are
+	Removed   int    // Files indexed before that are gone or now ignored
+	Chunks    int    // Chunks embedded for the updated files
+}
+
+// New returns an indexer that embeds with embedder, whose model is named
+// model, and skips files larger than maxFileSize bytes
+func New(store *storage.ConversationStore, embedder storage.Embedder, model string, maxFileSize int) *Indexer {
+	return &Indexer{store: store, embedder: embedder, model: model, maxFileSize: int64(maxFileSize)}
+}
+
+// Root returns the absolute, cleaned form of dir under which its files are
+// indexed, expanding a leading "~" to the home directory
+func Root(dir string) (string, error) {
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("get home directory: %w", err)
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	return filepath.Abs(dir)
+}
+
+// Index brings the index of dir up to date: new and changed files are
+// embedded, and files that were removed or are now ignored are dropped.
+// Hidden files and directories, such as .env and .git, binary files, and
+// files over the size limit are skipped along with those .gitignore lists.
+func (ix *Indexer) Index(ctx context.Context, dir string) (Result, error) {
+	root, err := Root(dir)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Root: root}
+	if info, err := os.Stat(root); err != nil {
+		return result, fmt.Errorf("index %s: %w", dir, err)
+	} else if !info.IsDir() {
+		return result, fmt.Errorf("index %s: not a directory", dir)
+	}
+
+	indexed, err := ix.store.ProjectFileHashes(root, ix.model)
+	if err != nil {
+		return result, err
+	}
+	files, err := ListFiles(root, ix.maxFileSize)
+	if err != nil {
+		return result, err
+	}
+
+	for _, rel := range files {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return result, fmt.Errorf("read %s: %w", rel, err)
+		}
+		if !IsText(data) {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+		if previous, ok := indexed[rel]; ok {
+			delete(indexed, rel)
+			if previous == hash {
+				result.Unchanged++
+				continue
+			}
+		}
+
+		chunks, err := ix.indexFile(ctx, root, rel, hash, string(data))
+		if err != nil {
+			return result, err
+		}
+		result.Updated++
+		result.Chunks += chunks
+	}
+
+	// What is left was indexed before but no longer belongs in the index
+	for rel := range indexed {
+		if err := ix.store.DeleteProjectFile(root, rel); err != nil {
+			return result, err
+		}
+		result.Removed++
+	}
+	return result, nil
+}
+
+// ListFiles lists the files under root worth reading, as slash-separated
+// paths relative to root. Hidden files and directories, files listed in
+// .gitignore, empty files, and files over maxFileSize bytes are left out.
+func ListFiles(root string, maxFileSize int64) ([]string, error) {
+	rules := &ignoreRules{}
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if entry.IsDir() {
+			if rel == "." {
+				return rules.load(path, "")
+			}
+			if strings.HasPrefix(entry.Name(), ".") || rules.ignored(rel, true) {
+				return filepath.SkipDir
+			}
+			return rules.load(path, rel)
+		}
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") || rules.ignored(rel, false) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() == 0 || info.Size() > maxFileSize {
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list files in %s: %w", root, err)
+	}
+	return files, nil
+}
+
+// indexFile embeds the chunks of a file and saves them in place of those
+// indexed before, returning how many there were
+func (ix *Indexer) indexFile(ctx context.Context, root, rel, hash, text string) (int, error) {
+	var chunks []*storage.ProjectChunk
+	for _, c := range chunkText(text) {
+		chunks = append(chunks, &storage.ProjectChunk{StartLine: c.startLine, EndLine: c.endLine, Content: c.content})
+	}
+
+	var vectors [][]float32
+	for start := 0; start < len(chunks); start += embedBatchSize {
+		batch := chunks[start:min(start+embedBatchSize, len(chunks))]
+		texts := make([]string, len(batch))
+		for i, chunk := range batch {
+			// The path helps match questions that name the file or package
+			texts[i] = rel + "\n" + chunk.Content
+		}
+		embedded, err := ix.embedder.Embed(ctx, texts)
+		if err != nil {
+			return 0, fmt.Errorf("embed %s: %w", rel, err)
+		}
+		if len(embedded) != len(batch) {
+			return 0, fmt.Errorf("embed %s: got %d vectors for %d chunks", rel, len(embedded), len(batch))
+		}
+		vectors = append(vectors, embedded...)
+	}
+
+	if err := ix.store.SaveProjectFile(root, rel, hash, ix.model, chunks, vectors); err != nil {
+		return 0, err
+	}
+	return len(chunks), nil
+}
+
+// Retrieve returns up to limit chunks from any indexed directory related to
+// query, best first
+func (ix *Indexer) Retrieve(ctx context.Context, query string, limit int) ([]*storage.ProjectChunk, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	vectors, err := ix.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embed query: got %d vectors", len(vectors))
+	}
+	return ix.store.SearchProjectChunks(ctx, ix.model, vectors[0], limit)
+}
+
+// Clear drops the index of dir and returns how many files it held
+func (ix *Indexer) Clear(dir string) (int, error) {
+	root, err := Root(dir)
+	if err != nil {
+		return 0, err
+	}
+	return ix.store.ClearProjectIndex(root)
+}
+
+// IsText reports whether data looks like text rather than a binary file,
+// which, like git, it takes to be one with a NUL byte near the start
+func IsText(data []byte) bool {
+	head := data[:min(len(data), 8000)]
+	return bytes.IndexByte(head, 0) < 0
+}
@@ -0,0 +1,153 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wordEmbedder embeds text by counting a few words, so chunks about the same
+// thing are similar
+type wordEmbedder struct {
+	texts []string
+	err   error
+}
+
+var embedWords = []string{"database", "router", "parser"}
+
+func (e *wordEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.texts = append(e.texts, texts...)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, len(embedWords)+1)
+		vector[len(embedWords)] = 0.1
+		for dim, word := range embedWords {
+			vector[dim] = float32(strings.Count(strings.ToLower(text), word))
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+func newTestStore(t *testing.T) *storage.ConversationStore {
+	store, err := storage.NewConversationStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestIndexer_IndexAndRetrieve(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":        "*.log\nvendor/\n",
+		"db/store.go":       "package db\n\n// Open connects to the database\nfunc Open() {}\n",
+		"http/router.go":    "package http\n\n// The router dispatches requests\n",
+		"server.log":        "database router parser",
+		"vendor/lib.go":     "package lib // database",
+		".git/config":       "[core] database",
+		".env":              "DATABASE_PASSWORD=secret",
+		"assets/logo.png":   "\x89PNG\x00\x00database",
+		"web/.gitignore":    "dist/\n",
+		"web/dist/app.js":   "// router",
+		"web/src/parser.js": "// parser for the router",
+	})
+
+	embedder := &wordEmbedder{}
+	ix := New(newTestStore(t), embedder, "test-embed", 1<<20)
+	ctx := context.Background()
+
+	result, err := ix.Index(ctx, root)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Root: root, Updated: 3, Chunks: 3}, result, "Only db, http, and web/src are indexed")
+	assert.Contains(t, embedder.texts, "db/store.go\npackage db\n\n// Open connects to the database\nfunc Open() {}")
+
+	chunks, err := ix.Retrieve(ctx, "How do I open the database?", 3)
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "db/store.go", chunks[0].Path)
+	assert.Equal(t, root, chunks[0].Root)
+	assert.Equal(t, 1, chunks[0].StartLine)
+	assert.Equal(t, 4, chunks[0].EndLine)
+
+	// Only changed files are embedded again, and removed ones are dropped
+	embedder.texts = nil
+	writeFiles(t, root, map[string]string{"http/router.go": "package http\n\n// The router and its parser\n"})
+	require.NoError(t, os.Remove(filepath.Join(root, "db/store.go")))
+	result, err = ix.Index(ctx, root)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Root: root, Updated: 1, Unchanged: 1, Removed: 1, Chunks: 1}, result)
+	assert.Len(t, embedder.texts, 1)
+
+	chunks, err = ix.Retrieve(ctx, "database", 3)
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	files, err := ix.Clear(root)
+	require.NoError(t, err)
+	assert.Equal(t, 2, files)
+}
+
+func TestIndexer_Errors(t *testing.T) {
+	ix := New(newTestStore(t), &wordEmbedder{err: errors.New("connection refused")}, "test-embed", 1<<20)
+	ctx := context.Background()
+
+	_, err := ix.Index(ctx, filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"main.go": "package main"})
+	_, err = ix.Index(ctx, root)
+	assert.ErrorContains(t, err, "embed main.go: connection refused")
+
+	_, err = ix.Retrieve(ctx, "main", 3)
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestIndexer_SkipsLargeFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"small.go": "package small", "big.go": strings.Repeat("x", 100)})
+	ix := New(newTestStore(t), &wordEmbedder{}, "test-embed", 50)
+
+	result, err := ix.Index(context.Background(), root)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Updated)
+}
+
+func TestChunkText(t *testing.T) {
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = "line"
+	}
+	chunks := chunkText(strings.Join(lines, "\n") + "\n")
+	require.Len(t, chunks, 3)
+	assert.Equal(t, chunk{startLine: 1, endLine: 40, content: strings.Join(lines[:40], "\n")}, chunks[0])
+	assert.Equal(t, 33, chunks[1].startLine, "Chunks overlap")
+	assert.Equal(t, 72, chunks[1].endLine)
+	assert.Equal(t, 65, chunks[2].startLine)
+	assert.Equal(t, 100, chunks[2].endLine)
+
+	long := strings.Repeat("y", 1500)
+	chunks = chunkText(long + "\n" + long + "\n" + long)
+	require.Len(t, chunks, 3, "Long lines end chunks early")
+	assert.Equal(t, 1, chunks[0].endLine)
+
+	assert.Empty(t, chunkText("\n\n   \n"))
+}
@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/chaos"
+)
+
+// ChaosClient wraps a Client and injects tool call latency, dropped responses,
+// and malformed results for resilience testing
+type ChaosClient struct {
+	Client
+	server   string
+	injector *chaos.Injector
+	logger   Logger
+}
+
+// NewChaosClient wraps client with fault injection. It returns client unchanged
+// when injector is nil.
+func NewChaosClient(server string, client Client, injector *chaos.Injector, logger Logger) Client {
+	if injector == nil {
+		return client
+	}
+	return &ChaosClient{
+		Client:   client,
+		server:   server,
+		injector: injector,
+		logger:   logger,
+	}
+}
+
+// CallTool calls the wrapped client, then delays, drops, or corrupts the response
+func (c *ChaosClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (*ToolResult, error) {
+	if delay := c.injector.ToolLatency(); delay > 0 {
+		c.logger.Debug("chaos: delaying %s/%s by %s", c.server, name, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if c.injector.DropResponse() {
+		// The request is never sent; the caller sees a call that never returns
+		timeout := c.injector.DropTimeout()
+		c.logger.Debug("chaos: dropping response for %s/%s", c.server, name)
+		select {
+		case <-time.After(timeout):
+			return nil, fmt.Errorf("%w: chaos: no response from %s for %s after %s: %w", ErrServerUnavailable, c.server, name, timeout, context.DeadlineExceeded)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	result, err := c.Client.CallTool(ctx, name, params)
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	if c.injector.MalformResponse() {
+		c.logger.Debug("chaos: corrupting result of %s/%s", c.server, name)
+		corrupted := &ToolResult{IsError: result.IsError, Content: make([]Content, len(result.Content))}
+		for i, content := range result.Content {
+			content.Text = chaos.CorruptJSON(content.Text)
+			corrupted.Content[i] = content
+		}
+		return corrupted, nil
+	}
+
+	return result, nil
+}
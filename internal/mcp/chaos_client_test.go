@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/chaos"
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is a connected Client that returns a fixed tool result
+type fakeClient struct {
+	calls  int
+	result *ToolResult
+}
+
+func (f *fakeClient) Connect(ctx context.Context) error    { return nil }
+func (f *fakeClient) Disconnect(ctx context.Context) error { return nil }
+func (f *fakeClient) IsConnected() bool                    { return true }
+func (f *fakeClient) GetTransport() string                 { return "fake" }
+func (f *fakeClient) ListTools(ctx context.Context) ([]Tool, error) {
+	return nil, nil
+}
+func (f *fakeClient) GetInfo(ctx context.Context) (*ServerInfo, error) {
+	return &ServerInfo{Name: "fake"}, nil
+}
+func (f *fakeClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (*ToolResult, error) {
+	f.calls++
+	return f.result, nil
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{result: &ToolResult{Content: []Content{{Type: "text", Text: `{"results":[{"id":1}]}`}}}}
+}
+
+func TestNewChaosClient_DisabledReturnsClient(t *testing.T) {
+	client := newFakeClient()
+	assert.Same(t, client, NewChaosClient("fake", client, nil, NewSimpleLogger()))
+}
+
+func TestChaosClient_DropsResponse(t *testing.T) {
+	client := newFakeClient()
+	injector := chaos.New(config.ChaosConfig{Enabled: true, DropRate: 1, DropTimeout: 20 * time.Millisecond})
+	wrapped := NewChaosClient("fake", client, injector, NewSimpleLogger())
+
+	start := time.Now()
+	result, err := wrapped.CallTool(context.Background(), "search", nil)
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Zero(t, client.calls)
+}
+
+func TestChaosClient_DropHonoursContext(t *testing.T) {
+	injector := chaos.New(config.ChaosConfig{Enabled: true, DropRate: 1, DropTimeout: time.Minute})
+	wrapped := NewChaosClient("fake", newFakeClient(), injector, NewSimpleLogger())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := wrapped.CallTool(ctx, "search", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestChaosClient_MalformsResult(t *testing.T) {
+	client := newFakeClient()
+	injector := chaos.New(config.ChaosConfig{Enabled: true, MalformedRate: 1})
+	wrapped := NewChaosClient("fake", client, injector, NewSimpleLogger())
+
+	result, err := wrapped.CallTool(context.Background(), "search", nil)
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+
+	var v interface{}
+	assert.Error(t, json.Unmarshal([]byte(result.Content[0].Text), &v))
+	// The wrapped client's result is left intact
+	assert.NoError(t, json.Unmarshal([]byte(client.result.Content[0].Text), &v))
+}
+
+func TestChaosClient_PassesThrough(t *testing.T) {
+	client := newFakeClient()
+	injector := chaos.New(config.ChaosConfig{Enabled: true})
+	wrapped := NewChaosClient("fake", client, injector, NewSimpleLogger())
+
+	result, err := wrapped.CallTool(context.Background(), "search", nil)
+	require.NoError(t, err)
+	assert.Same(t, client.result, result)
+	assert.Equal(t, 1, client.calls)
+}
@@ -43,8 +43,11 @@ func ServerFromConfig(cfg config.ServerConfig) Server {
 		Transport: cfg.Transport,
 		Command:   command,
 		Args:      cfg.Args,
+		URL:       cfg.URL,
+		Headers:   cfg.Headers,
 		Env:       cfg.Env,
 		Timeout:   timeout,
+		Sandbox:   cfg.Sandbox,
 	}
 }
 
@@ -105,14 +105,17 @@ func TestClientFactoryWithHTTP(t *testing.T) {
 	serverCfg := config.ServerConfig{
 		Name:      "test-http-factory",
 		Transport: "http",
-		Command:   "http://localhost:8080/mcp", // For HTTP, the command might be the URL
+		URL:       "http://localhost:8080/mcp",
+		Headers:   map[string]string{"Authorization": "Bearer token"},
 	}
 
 	client, err := factory.CreateClient(serverCfg)
 	require.NoError(t, err)
 	require.NotNil(t, client)
 
-	// Verify it creates an HTTP client
-	_, ok := client.(*HTTPClient)
-	assert.True(t, ok)
+	// Verify it creates an HTTP client for the URL
+	httpClient, ok := client.(*HTTPClient)
+	require.True(t, ok)
+	assert.Equal(t, "http://localhost:8080/mcp", httpClient.server.URL)
+	assert.Equal(t, "Bearer token", httpClient.server.Headers["Authorization"])
 }
\ No newline at end of file
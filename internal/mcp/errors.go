@@ -0,0 +1,16 @@
+package mcp
+
+import "errors"
+
+// Errors tool calls fail with. They are wrapped with the details, so callers
+// can tell what went wrong with errors.Is.
+var (
+	// ErrToolNotFound is returned for a tool no server provides
+	ErrToolNotFound = errors.New("tool not found")
+	// ErrServerUnavailable is returned when the server providing a tool
+	// isn't registered, can't be reached, or stops answering
+	ErrServerUnavailable = errors.New("server unavailable")
+	// ErrInvalidArguments is returned for arguments that don't match the
+	// tool's input schema
+	ErrInvalidArguments = errors.New("invalid parameters")
+)
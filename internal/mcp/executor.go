@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"time"
 )
 
 // ToolExecutor handles tool execution with parameter validation and result processing
 type ToolExecutor struct {
 	registry *ToolRegistry
 	logger   Logger
+	observer func(ExecutionRecord) // Optional; told about every execution
 }
 
 // NewToolExecutor creates a new tool executor
@@ -29,49 +31,117 @@ type ExecuteResult struct {
 	Duration string      `json:"duration"`
 }
 
+// ExecutionRecord describes one finished tool execution for auditing
+type ExecutionRecord struct {
+	Server     string // Empty when the tool was not found
+	Tool       string
+	Arguments  map[string]interface{}
+	Duration   time.Duration
+	Success    bool
+	ResultSize int    // Bytes of content returned
+	Error      string // Why the execution failed, if it did
+}
+
+// SetObserver registers a function called after every execution, successful
+// or not. It runs on the executing goroutine, so it should return quickly.
+func (e *ToolExecutor) SetObserver(observer func(ExecutionRecord)) {
+	e.observer = observer
+}
+
 // Execute executes a tool with the given parameters
 func (e *ToolExecutor) Execute(ctx context.Context, toolName string, params map[string]interface{}) (*ExecuteResult, error) {
+	started := time.Now()
+	result, err := e.execute(ctx, toolName, params)
+	if e.observer != nil {
+		e.observer(newExecutionRecord(toolName, params, result, err, time.Since(started)))
+	}
+	return result, err
+}
+
+// newExecutionRecord describes the outcome of Execute
+func newExecutionRecord(toolName string, params map[string]interface{}, result *ExecuteResult, err error, duration time.Duration) ExecutionRecord {
+	record := ExecutionRecord{
+		Tool:      toolName,
+		Arguments: params,
+		Duration:  duration,
+		Success:   err == nil,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if result == nil {
+		return record
+	}
+	record.Server = result.Tool.ServerName
+	if result.Result != nil {
+		for _, content := range result.Result.Content {
+			record.ResultSize += len(content.Text) + len(content.Data)
+		}
+		if result.Result.IsError {
+			record.Success = false
+			if record.Error == "" && len(result.Result.Content) > 0 {
+				record.Error = result.Result.Content[0].Text
+			}
+		}
+	}
+	return record
+}
+
+// execute runs the tool for Execute
+func (e *ToolExecutor) execute(ctx context.Context, toolName string, params map[string]interface{}) (*ExecuteResult, error) {
 	start := ctx.Value("start_time")
 	if start == nil {
 		start = "unknown"
 	}
 	
-	// Get the tool from registry
-	tool, exists := e.registry.GetTool(toolName)
+	// Get the tool and its server from one snapshot, so a refresh in
+	// between can't pair the tool with a server that no longer offers it
+	snapshot := e.registry.Snapshot()
+	tool, exists := snapshot.Tool(toolName)
 	if !exists {
+		err := fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
 		return &ExecuteResult{
-			Error:    fmt.Errorf("tool '%s' not found", toolName),
+			Error:    err,
 			Duration: "0ms",
-		}, fmt.Errorf("tool '%s' not found", toolName)
+		}, err
 	}
 	
 	e.logger.Info("Executing tool %s from server %s", toolName, tool.ServerName)
 	
 	// Validate parameters against schema
 	if err := e.validateParameters(tool, params); err != nil {
+		err = fmt.Errorf("%w: %w", ErrInvalidArguments, err)
 		return &ExecuteResult{
 			Tool:     tool,
-			Error:    fmt.Errorf("parameter validation failed: %w", err),
+			Error:    err,
 			Duration: "0ms",
 		}, err
 	}
 	
-	// Get the server client
-	client, exists := e.registry.GetServer(tool.ServerName)
+	// Get the server client, waiting for it if it is still starting
+	client, exists := snapshot.Server(tool.ServerName)
+	if !exists {
+		client, exists = e.registry.WaitForServer(ctx, tool.ServerName)
+	}
 	if !exists {
+		err := fmt.Errorf("%w: server '%s' not found", ErrServerUnavailable, tool.ServerName)
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%w: server '%s' is still starting: %w", ErrServerUnavailable, tool.ServerName, ctx.Err())
+		}
 		return &ExecuteResult{
 			Tool:     tool,
-			Error:    fmt.Errorf("server '%s' not found", tool.ServerName),
+			Error:    err,
 			Duration: "0ms",
-		}, fmt.Errorf("server '%s' not found", tool.ServerName)
+		}, err
 	}
 	
 	// Ensure server is connected
 	if !client.IsConnected() {
 		if err := client.Connect(ctx); err != nil {
+			err = fmt.Errorf("%w: failed to connect to server: %w", ErrServerUnavailable, err)
 			return &ExecuteResult{
 				Tool:     tool,
-				Error:    fmt.Errorf("failed to connect to server: %w", err),
+				Error:    err,
 				Duration: "0ms",
 			}, err
 		}
@@ -99,30 +169,14 @@ func (e *ToolExecutor) Execute(ctx context.Context, toolName string, params map[
 
 // validateParameters validates tool parameters against the JSON schema
 func (e *ToolExecutor) validateParameters(tool Tool, params map[string]interface{}) error {
-	schema := tool.InputSchema
-	if schema == nil {
-		// No schema means no validation required
+	schema := tool.Schema()
+	if schema == nil || schema.Properties == nil {
+		// No schema or properties means no validation required
 		return nil
 	}
 	
-	// Get the properties from the schema
-	properties, ok := schema["properties"].(map[string]interface{})
-	if !ok {
-		return nil // No properties to validate
-	}
-	
-	// Get required fields
-	required := make(map[string]bool)
-	if reqFields, ok := schema["required"].([]interface{}); ok {
-		for _, field := range reqFields {
-			if fieldName, ok := field.(string); ok {
-				required[fieldName] = true
-			}
-		}
-	}
-	
 	// Validate required fields are present
-	for fieldName := range required {
+	for _, fieldName := range schema.Required {
 		if _, exists := params[fieldName]; !exists {
 			return fmt.Errorf("required parameter '%s' is missing", fieldName)
 		}
@@ -130,7 +184,7 @@ func (e *ToolExecutor) validateParameters(tool Tool, params map[string]interface
 	
 	// Validate each parameter
 	for paramName, paramValue := range params {
-		propSchema, exists := properties[paramName]
+		propSchema, exists := schema.Properties[paramName]
 		if !exists {
 			return fmt.Errorf("unknown parameter '%s'", paramName)
 		}
@@ -144,9 +198,8 @@ func (e *ToolExecutor) validateParameters(tool Tool, params map[string]interface
 }
 
 // validateParameter validates a single parameter against its schema
-func (e *ToolExecutor) validateParameter(name string, value interface{}, schema interface{}) error {
-	schemaMap, ok := schema.(map[string]interface{})
-	if !ok {
+func (e *ToolExecutor) validateParameter(name string, value interface{}, schemaMap map[string]interface{}) error {
+	if schemaMap == nil {
 		return nil // Can't validate without proper schema
 	}
 	
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolClient is a fakeClient that offers one tool
+type toolClient struct {
+	*fakeClient
+	tool Tool
+}
+
+func (c *toolClient) ListTools(ctx context.Context) ([]Tool, error) {
+	return []Tool{c.tool}, nil
+}
+
+func newObservedExecutor(t *testing.T, result *ToolResult) (*ToolExecutor, *[]ExecutionRecord) {
+	t.Helper()
+	registry := NewToolRegistry(NewSimpleLogger())
+	client := &toolClient{fakeClient: &fakeClient{result: result}, tool: Tool{Name: "search"}}
+	require.NoError(t, registry.RegisterServer("memory", client))
+
+	var records []ExecutionRecord
+	executor := NewToolExecutor(registry, NewSimpleLogger())
+	executor.SetObserver(func(record ExecutionRecord) {
+		records = append(records, record)
+	})
+	return executor, &records
+}
+
+func TestToolExecutor_ObserverRecordsSuccess(t *testing.T) {
+	executor, records := newObservedExecutor(t, &ToolResult{Content: []Content{{Type: "text", Text: "found 3"}}})
+
+	_, err := executor.Execute(context.Background(), "search", map[string]interface{}{"query": "go"})
+	require.NoError(t, err)
+
+	require.Len(t, *records, 1)
+	record := (*records)[0]
+	assert.Equal(t, "memory", record.Server)
+	assert.Equal(t, "search", record.Tool)
+	assert.Equal(t, map[string]interface{}{"query": "go"}, record.Arguments)
+	assert.True(t, record.Success)
+	assert.Equal(t, len("found 3"), record.ResultSize)
+	assert.Empty(t, record.Error)
+}
+
+func TestToolExecutor_ObserverRecordsFailures(t *testing.T) {
+	executor, records := newObservedExecutor(t, &ToolResult{Content: []Content{{Type: "text", Text: "bad query"}}, IsError: true})
+
+	_, err := executor.Execute(context.Background(), "search", nil)
+	require.NoError(t, err)
+	_, err = executor.Execute(context.Background(), "missing", nil)
+	require.Error(t, err)
+
+	require.Len(t, *records, 2)
+	assert.False(t, (*records)[0].Success)
+	assert.Equal(t, "bad query", (*records)[0].Error)
+
+	assert.False(t, (*records)[1].Success)
+	assert.Empty(t, (*records)[1].Server)
+	assert.Contains(t, (*records)[1].Error, "not found")
+}
+
+func TestToolExecutor_ErrorsAreTyped(t *testing.T) {
+	registry := NewToolRegistry(NewSimpleLogger())
+	tool := Tool{Name: "store", InputSchema: map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"content": map[string]interface{}{"type": "string"}},
+		"required":   []interface{}{"content"},
+	}}
+	require.NoError(t, registry.RegisterServer("memory", &toolClient{fakeClient: newFakeClient(), tool: tool}))
+	executor := NewToolExecutor(registry, NewSimpleLogger())
+
+	_, err := executor.Execute(context.Background(), "missing", nil)
+	assert.ErrorIs(t, err, ErrToolNotFound)
+
+	_, err = executor.Execute(context.Background(), "store", map[string]interface{}{})
+	assert.ErrorIs(t, err, ErrInvalidArguments)
+	assert.ErrorContains(t, err, "content")
+
+	_, err = NewSTDIOClient(Server{Name: "memory"}, NewSimpleLogger()).CallTool(context.Background(), "store", nil)
+	assert.ErrorIs(t, err, ErrServerUnavailable)
+}
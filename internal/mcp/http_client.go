@@ -26,11 +26,9 @@ type HTTPClient struct {
 // NewHTTPClient creates a new HTTP client for an MCP server
 func NewHTTPClient(server Server, logger Logger) *HTTPClient {
 	return &HTTPClient{
-		server: server,
-		httpClient: &http.Client{
-			Timeout: server.Timeout,
-		},
-		logger: logger,
+		server:     server,
+		httpClient: &http.Client{}, // server.Timeout limits the wait between messages, so streamed results can run longer
+		logger:     logger,
 	}
 }
 
@@ -100,7 +98,7 @@ func (c *HTTPClient) GetTransport() string {
 // ListTools lists all available tools from the server
 func (c *HTTPClient) ListTools(ctx context.Context) ([]Tool, error) {
 	if !c.IsConnected() {
-		return nil, fmt.Errorf("not connected to server")
+		return nil, fmt.Errorf("%w: not connected to server", ErrServerUnavailable)
 	}
 
 	msg := Message{
@@ -139,15 +137,20 @@ func (c *HTTPClient) ListTools(ctx context.Context) ([]Tool, error) {
 // CallTool executes a tool with the given parameters
 func (c *HTTPClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (*ToolResult, error) {
 	if !c.IsConnected() {
-		return nil, fmt.Errorf("not connected to server")
+		return nil, fmt.Errorf("%w: not connected to server", ErrServerUnavailable)
 	}
 
+	callParams := ToolCallParams{
+		Name:      name,
+		Arguments: params,
+	}
+	if toolOutput(ctx) != nil {
+		// Servers only report progress on calls that ask for it
+		callParams.Meta = map[string]interface{}{"progressToken": c.nextRequestID()}
+	}
 	msg := Message{
 		Method: "tools/call",
-		Params: ToolCallParams{
-			Name:      name,
-			Arguments: params,
-		},
+		Params: callParams,
 	}
 
 	response, err := c.sendRequest(ctx, msg)
@@ -179,7 +182,7 @@ func (c *HTTPClient) CallTool(ctx context.Context, name string, params map[strin
 // GetInfo retrieves server information
 func (c *HTTPClient) GetInfo(ctx context.Context) (*ServerInfo, error) {
 	if !c.IsConnected() {
-		return nil, fmt.Errorf("not connected to server")
+		return nil, fmt.Errorf("%w: not connected to server", ErrServerUnavailable)
 	}
 
 	msg := Message{
@@ -250,6 +253,19 @@ func (c *HTTPClient) sendRequest(ctx context.Context, msg Message) (Message, err
 		return Message{}, fmt.Errorf("marshal message: %w", err)
 	}
 
+	// The wait for each message is limited rather than the whole exchange,
+	// so a tool streaming its output can run as long as it keeps writing
+	var idle *time.Timer
+	if c.server.Timeout > 0 {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+		idle = time.AfterFunc(c.server.Timeout, func() {
+			cancel(fmt.Errorf("%w: no response in %s: %w", ErrServerUnavailable, c.server.Timeout, context.DeadlineExceeded))
+		})
+		defer idle.Stop()
+	}
+
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.server.URL, bytes.NewReader(data))
 	if err != nil {
@@ -262,7 +278,10 @@ func (c *HTTPClient) sendRequest(ctx context.Context, msg Message) (Message, err
 	// Send request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return Message{}, fmt.Errorf("send request: %w", err)
+		if cause := context.Cause(ctx); cause != nil && ctx.Err() != nil {
+			err = cause
+		}
+		return Message{}, fmt.Errorf("%w: send request: %w", ErrServerUnavailable, err)
 	}
 	defer resp.Body.Close()
 
@@ -279,19 +298,27 @@ func (c *HTTPClient) sendRequest(ctx context.Context, msg Message) (Message, err
 		return Message{}, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(body))
 	}
 
-	// Parse response
-	var response Message
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return Message{}, fmt.Errorf("decode response: %w", err)
+	// Parse response, which may follow notifications streamed while the
+	// request runs
+	output := toolOutput(ctx)
+	response, err := readResponse(resp, requestID, func(notification Message) {
+		if idle != nil {
+			idle.Reset(c.server.Timeout)
+		}
+		if text, ok := notificationText(notification); ok && output != nil {
+			output(text)
+		}
+	})
+	if err != nil && ctx.Err() != nil {
+		return Message{}, context.Cause(ctx)
 	}
-
-	return response, nil
+	return response, err
 }
 
 // setHeaders sets the required HTTP headers for MCP
 func (c *HTTPClient) setHeaders(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
 	req.Header.Set("Mcp-Protocol-Version", "2024-11-05")
 
 	// Set session ID if we have one
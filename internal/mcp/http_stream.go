@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxEventSize bounds one server-sent event, which may carry a whole tool
+// result
+const maxEventSize = 16 * 1024 * 1024
+
+// toolOutputKey is the context key of the function given streamed output
+type toolOutputKey struct{}
+
+// WithToolOutput returns a context under which tool calls pass the output
+// their server streams while the tool runs, such as the lines a log tailer
+// reads, to output as it arrives. Output is called from the goroutine making
+// the call.
+func WithToolOutput(ctx context.Context, output func(text string)) context.Context {
+	return context.WithValue(ctx, toolOutputKey{}, output)
+}
+
+// toolOutput returns the function given streamed output under ctx, nil when
+// there is none
+func toolOutput(ctx context.Context) func(text string) {
+	output, _ := ctx.Value(toolOutputKey{}).(func(text string))
+	return output
+}
+
+// readResponse reads the response to the request with id from resp. The
+// body is either JSON-RPC messages, one after another as the server writes
+// them, or server-sent events carrying them. Messages read before the
+// response, such as progress notifications, are passed to notify.
+func readResponse(resp *http.Response, id int64, notify func(Message)) (Message, error) {
+	read := readJSONMessages
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "text/event-stream" {
+		read = readEventMessages
+	}
+
+	want := strconv.FormatInt(id, 10)
+	var response Message
+	found := false
+	err := read(resp.Body, func(msg Message) bool {
+		if msg.Method == "" && msg.ID != nil && fmt.Sprint(msg.ID) == want {
+			response, found = msg, true
+			return false
+		}
+		notify(msg)
+		return true
+	})
+	if err != nil {
+		return Message{}, fmt.Errorf("decode response: %w", err)
+	}
+	if !found {
+		return Message{}, errors.New("decode response: the server stopped before sending the result")
+	}
+	return response, nil
+}
+
+// readJSONMessages passes each JSON-RPC message in body to handle until
+// handle returns false or the body ends
+func readJSONMessages(body io.Reader, handle func(Message) bool) error {
+	decoder := json.NewDecoder(body)
+	for {
+		var msg Message
+		if err := decoder.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !handle(msg) {
+			return nil
+		}
+	}
+}
+
+// readEventMessages passes the JSON-RPC message of each server-sent event in
+// body to handle until handle returns false or the body ends. Events of types
+// other than "message" are skipped.
+func readEventMessages(body io.Reader, handle func(Message) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	var data []string
+	eventType := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "data":
+				data = append(data, value)
+			case "event":
+				eventType = value
+			}
+			continue
+		}
+
+		// A blank line ends the event
+		if len(data) > 0 && (eventType == "" || eventType == "message") {
+			var msg Message
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &msg); err != nil {
+				return fmt.Errorf("event data: %w", err)
+			}
+			if !handle(msg) {
+				return nil
+			}
+		}
+		data, eventType = nil, ""
+	}
+	return scanner.Err()
+}
+
+// notificationText returns the text a progress or log notification carries
+// for the user
+func notificationText(msg Message) (string, bool) {
+	params, _ := msg.Params.(map[string]interface{})
+	switch msg.Method {
+	case "notifications/progress":
+		text, ok := params["message"].(string)
+		return text, ok && text != ""
+	case "notifications/message":
+		switch data := params["data"].(type) {
+		case nil:
+			return "", false
+		case string:
+			return data, data != ""
+		default:
+			encoded, err := json.Marshal(data)
+			return string(encoded), err == nil
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStreamingHTTPServer starts a server whose tools/call streams two log
+// lines before its result, as server-sent events when sse is set and as
+// JSON messages otherwise, waiting pause between messages
+func newStreamingHTTPServer(t *testing.T, sse bool, pause time.Duration) (*httptest.Server, *[]Message) {
+	t.Helper()
+	var calls []Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Message
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var messages []Message
+		switch req.Method {
+		case "initialize":
+			messages = []Message{{ID: req.ID, Result: map[string]interface{}{}}}
+		case "tools/call":
+			calls = append(calls, req)
+			messages = []Message{
+				{Method: "notifications/message", Params: map[string]interface{}{"level": "info", "data": "tailing app.log"}},
+				{Method: "notifications/progress", Params: map[string]interface{}{"progressToken": 1, "progress": 1, "message": "ERROR disk full"}},
+				{Method: "notifications/message", Params: map[string]interface{}{"level": "debug", "data": map[string]interface{}{"lines": 2}}},
+				{ID: req.ID, Result: map[string]interface{}{"content": []map[string]interface{}{{"type": "text", "text": "2 lines"}}}},
+			}
+		}
+
+		if sse {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, ": keep-alive\n\nevent: ping\ndata: {}\n\n")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		for _, msg := range messages {
+			data, err := json.Marshal(msg)
+			require.NoError(t, err)
+			if sse {
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			} else {
+				fmt.Fprintf(w, "%s\n", data)
+			}
+			w.(http.Flusher).Flush()
+			time.Sleep(pause)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestHTTPClient_CallToolStreamsOutput(t *testing.T) {
+	for _, sse := range []bool{true, false} {
+		t.Run(fmt.Sprintf("sse=%v", sse), func(t *testing.T) {
+			// Each message comes well within the timeout, though the call
+			// as a whole takes longer
+			server, calls := newStreamingHTTPServer(t, sse, 100*time.Millisecond)
+			client := NewHTTPClient(Server{Name: "logs", URL: server.URL, Timeout: 250 * time.Millisecond}, NewSimpleLogger())
+			require.NoError(t, client.Connect(context.Background()))
+
+			var output []string
+			ctx := WithToolOutput(context.Background(), func(text string) {
+				output = append(output, text)
+			})
+			result, err := client.CallTool(ctx, "tail", map[string]interface{}{"file": "app.log"})
+			require.NoError(t, err)
+			require.Len(t, result.Content, 1)
+			assert.Equal(t, "2 lines", result.Content[0].Text)
+			assert.Equal(t, []string{"tailing app.log", "ERROR disk full", `{"lines":2}`}, output)
+
+			require.Len(t, *calls, 1)
+			params := (*calls)[0].Params.(map[string]interface{})
+			assert.Contains(t, params["_meta"], "progressToken", "Progress is asked for when output is shown")
+
+			// Without anywhere to show output, progress isn't asked for
+			_, err = client.CallTool(context.Background(), "tail", nil)
+			require.NoError(t, err)
+			assert.NotContains(t, (*calls)[1].Params, "_meta")
+		})
+	}
+}
+
+func TestHTTPClient_CallToolTimesOutBetweenMessages(t *testing.T) {
+	server, _ := newStreamingHTTPServer(t, true, 300*time.Millisecond)
+	client := NewHTTPClient(Server{Name: "logs", URL: server.URL, Timeout: 100 * time.Millisecond}, NewSimpleLogger())
+	require.NoError(t, client.Connect(context.Background()))
+
+	_, err := client.CallTool(context.Background(), "tail", nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrServerUnavailable)
+	assert.Contains(t, err.Error(), "no response in 100ms")
+}
+
+func TestReadResponse_StopsBeforeResult(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{"Content-Type": {"text/event-stream; charset=utf-8"}},
+		Body:   io.NopCloser(strings.NewReader(`data: {"method":"notifications/progress","params":{"message":"starting"}}` + "\n\n")),
+	}
+
+	var notified []Message
+	_, err := readResponse(resp, 1, func(msg Message) { notified = append(notified, msg) })
+	assert.ErrorContains(t, err, "stopped before sending the result")
+	assert.Len(t, notified, 1)
+}
@@ -4,66 +4,18 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"time"
+	"sync/atomic"
 )
 
-// ToolCache manages cached tool information with TTL
-type ToolCache struct {
-	tools map[string]Tool
-	ttl   time.Duration
-	mutex sync.RWMutex
-}
-
-// NewToolCache creates a new tool cache with the specified TTL
-func NewToolCache(ttl time.Duration) *ToolCache {
-	return &ToolCache{
-		tools: make(map[string]Tool),
-		ttl:   ttl,
-	}
-}
-
-// Get retrieves a tool from the cache if it's still valid
-func (c *ToolCache) Get(name string) (Tool, bool) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	
-	tool, exists := c.tools[name]
-	if !exists {
-		return Tool{}, false
-	}
-	
-	// Check if cache entry is still valid
-	if time.Since(tool.LastUpdated) > c.ttl {
-		return Tool{}, false
-	}
-	
-	return tool, true
-}
-
-// Set stores a tool in the cache
-func (c *ToolCache) Set(tool Tool) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	
-	tool.LastUpdated = time.Now()
-	c.tools[tool.Name] = tool
-}
-
-// Clear removes all tools from the cache
-func (c *ToolCache) Clear() {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	
-	c.tools = make(map[string]Tool)
-}
-
-// ToolRegistry manages tool discovery and caching across multiple MCP servers
+// ToolRegistry manages tool discovery and caching across multiple MCP servers.
+// Its tools and servers are published as immutable snapshots: changes are
+// made to a copy under the mutex and then swapped in, so lookups never wait
+// for a refresh and never see one half done.
 type ToolRegistry struct {
-	tools   map[string]Tool
-	servers map[string]Client
-	cache   *ToolCache
-	mutex   sync.RWMutex
-	logger  Logger
+	snapshot atomic.Pointer[ToolSnapshot]
+	pending  map[string]chan struct{} // Closed once a server with cached tools registers or is removed
+	mutex    sync.Mutex               // Held to change the snapshot or pending
+	logger   Logger
 }
 
 // Logger interface for registry logging
@@ -75,24 +27,63 @@ type Logger interface {
 
 // NewToolRegistry creates a new tool registry
 func NewToolRegistry(logger Logger) *ToolRegistry {
-	return &ToolRegistry{
-		tools:   make(map[string]Tool),
-		servers: make(map[string]Client),
-		cache:   NewToolCache(time.Hour), // 1 hour cache TTL
+	r := &ToolRegistry{
+		pending: make(map[string]chan struct{}),
 		logger:  logger,
 	}
+	r.snapshot.Store(newToolSnapshot(0))
+	return r
 }
 
-// RegisterServer registers an MCP server with the registry
+// Snapshot returns the current tools and servers. Look up a tool and its
+// server in the same snapshot to see them as they were at one moment.
+func (r *ToolRegistry) Snapshot() *ToolSnapshot {
+	return r.snapshot.Load()
+}
+
+// changeLocked publishes a copy of the snapshot changed by change (must be
+// called with lock held)
+func (r *ToolRegistry) changeLocked(change func(next *ToolSnapshot)) {
+	next := r.snapshot.Load().next()
+	change(next)
+	r.snapshot.Store(next)
+}
+
+// RegisterServer registers an MCP server with the registry and discovers its
+// tools, which replace any it offered before. The server stays registered
+// when discovery fails.
 func (r *ToolRegistry) RegisterServer(name string, client Client) error {
+	// Discover tools without the lock, so a slow server doesn't hold up changes
+	tools, err := r.discoverTools(context.Background(), name, client)
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
-	r.servers[name] = client
+	r.changeLocked(func(next *ToolSnapshot) {
+		next.servers[name] = client
+		if err == nil {
+			next.replaceTools(name, tools)
+		}
+	})
+	r.resolvePendingLocked(name)
 	r.logger.Info("Registered MCP server %s", name)
+	return err
+}
+
+// RegisterCachedTools makes tools a server offered before, such as those
+// cached on disk, available while the server is still starting. Until
+// RegisterServer or UnregisterServer is called for it, WaitForServer waits
+// for the server.
+func (r *ToolRegistry) RegisterCachedTools(serverName string, tools []Tool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	
-	// Discover tools from the server
-	return r.discoverToolsLocked(context.Background(), name, client)
+	r.changeLocked(func(next *ToolSnapshot) {
+		next.replaceTools(serverName, tools)
+	})
+	if _, registered := r.Snapshot().Server(serverName); !registered && r.pending[serverName] == nil {
+		r.pending[serverName] = make(chan struct{})
+	}
 }
 
 // UnregisterServer removes an MCP server from the registry
@@ -100,60 +91,68 @@ func (r *ToolRegistry) UnregisterServer(name string) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
-	delete(r.servers, name)
-	
-	// Remove tools from this server
-	for toolName, tool := range r.tools {
-		if tool.ServerName == name {
-			delete(r.tools, toolName)
-		}
-	}
+	r.changeLocked(func(next *ToolSnapshot) {
+		delete(next.servers, name)
+		next.replaceTools(name, nil)
+	})
+	r.resolvePendingLocked(name)
 	
 	r.logger.Info("Unregistered MCP server", "name", name)
 }
 
-// discoverToolsLocked discovers tools from a server (must be called with lock held)
-func (r *ToolRegistry) discoverToolsLocked(ctx context.Context, serverName string, client Client) error {
+// resolvePendingLocked stops waiting for a server (must be called with lock held)
+func (r *ToolRegistry) resolvePendingLocked(name string) {
+	if pending, ok := r.pending[name]; ok {
+		close(pending)
+		delete(r.pending, name)
+	}
+}
+
+// discoverTools lists the tools of a server, connecting to it first if needed
+func (r *ToolRegistry) discoverTools(ctx context.Context, serverName string, client Client) ([]Tool, error) {
 	if !client.IsConnected() {
 		if err := client.Connect(ctx); err != nil {
-			return fmt.Errorf("connect to server %s: %w", serverName, err)
+			return nil, fmt.Errorf("connect to server %s: %w", serverName, err)
 		}
 	}
 	
 	tools, err := client.ListTools(ctx)
 	if err != nil {
 		r.logger.Error("Failed to list tools from server %s: %v", serverName, err)
-		return fmt.Errorf("list tools from %s: %w", serverName, err)
+		return nil, fmt.Errorf("list tools from %s: %w", serverName, err)
 	}
 	
 	r.logger.Info("Discovered tools from server %s count %d", serverName, len(tools))
-	
-	// Register tools in the registry
-	for _, tool := range tools {
-		tool.ServerName = serverName
-		tool.LastUpdated = time.Now()
-		r.tools[tool.Name] = tool
-		r.cache.Set(tool)
-		
-		r.logger.Debug("Registered tool %s from server %s", tool.Name, serverName)
-	}
-	
-	return nil
+	return tools, nil
 }
 
-// RefreshTools refreshes tools from all registered servers
+// RefreshTools refreshes tools from all registered servers. The tools
+// discovered are published together, in one new snapshot.
 func (r *ToolRegistry) RefreshTools(ctx context.Context) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+	servers := r.Snapshot().servers
 	
 	var errors []error
-	
-	for serverName, client := range r.servers {
-		if err := r.discoverToolsLocked(ctx, serverName, client); err != nil {
+	discovered := make(map[string][]Tool, len(servers))
+	for serverName, client := range servers {
+		tools, err := r.discoverTools(ctx, serverName, client)
+		if err != nil {
 			errors = append(errors, err)
+			continue
 		}
+		discovered[serverName] = tools
 	}
 	
+	r.mutex.Lock()
+	r.changeLocked(func(next *ToolSnapshot) {
+		for serverName, tools := range discovered {
+			// Skip servers removed or replaced during discovery
+			if next.servers[serverName] == servers[serverName] {
+				next.replaceTools(serverName, tools)
+			}
+		}
+	})
+	r.mutex.Unlock()
+	
 	if len(errors) > 0 {
 		return fmt.Errorf("failed to refresh tools from %d servers: %v", len(errors), errors)
 	}
@@ -163,80 +162,52 @@ func (r *ToolRegistry) RefreshTools(ctx context.Context) error {
 
 // GetTool retrieves a tool by name
 func (r *ToolRegistry) GetTool(name string) (Tool, bool) {
-	// First try cache
-	if tool, found := r.cache.Get(name); found {
-		return tool, true
-	}
-	
-	// Then try registry
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-	
-	tool, exists := r.tools[name]
-	if exists {
-		// Update cache
-		r.cache.Set(tool)
-	}
-	
-	return tool, exists
+	return r.Snapshot().Tool(name)
 }
 
 // ListTools returns all available tools
 func (r *ToolRegistry) ListTools() []Tool {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-	
-	tools := make([]Tool, 0, len(r.tools))
-	for _, tool := range r.tools {
-		tools = append(tools, tool)
-	}
-	
-	return tools
+	return r.Snapshot().Tools()
 }
 
 // ListToolsForServer returns tools available for a specific server
 func (r *ToolRegistry) ListToolsForServer(serverName string) []Tool {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-	
-	var tools []Tool
-	for _, tool := range r.tools {
-		if tool.ServerName == serverName {
-			tools = append(tools, tool)
-		}
-	}
-	
-	return tools
+	return r.Snapshot().ToolsForServer(serverName)
 }
 
 // GetServer returns the client for a specific server
 func (r *ToolRegistry) GetServer(name string) (Client, bool) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+	return r.Snapshot().Server(name)
+}
+
+// WaitForServer returns the client for a specific server like GetServer,
+// first waiting for a server whose cached tools were registered to finish
+// starting, or for ctx to be done
+func (r *ToolRegistry) WaitForServer(ctx context.Context, name string) (Client, bool) {
+	r.mutex.Lock()
+	client, exists := r.Snapshot().Server(name)
+	pending := r.pending[name]
+	r.mutex.Unlock()
+	if exists || pending == nil {
+		return client, exists
+	}
 	
-	client, exists := r.servers[name]
-	return client, exists
+	select {
+	case <-pending:
+		return r.GetServer(name)
+	case <-ctx.Done():
+		return nil, false
+	}
 }
 
 // ListServers returns all registered server names
 func (r *ToolRegistry) ListServers() []string {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-	
-	servers := make([]string, 0, len(r.servers))
-	for name := range r.servers {
-		servers = append(servers, name)
-	}
-	
-	return servers
+	return r.Snapshot().Servers()
 }
 
 // IsServerConnected checks if a server is connected
 func (r *ToolRegistry) IsServerConnected(name string) bool {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-	
-	client, exists := r.servers[name]
+	client, exists := r.Snapshot().Server(name)
 	if !exists {
 		return false
 	}
@@ -246,46 +217,22 @@ func (r *ToolRegistry) IsServerConnected(name string) bool {
 
 // GetToolCount returns the total number of registered tools
 func (r *ToolRegistry) GetToolCount() int {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-	
-	return len(r.tools)
+	return r.Snapshot().ToolCount()
 }
 
 // GetServerCount returns the total number of registered servers
 func (r *ToolRegistry) GetServerCount() int {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-	
-	return len(r.servers)
+	return r.Snapshot().ServerCount()
 }
 
 // GetAllTools returns all tools from all servers
 func (r *ToolRegistry) GetAllTools() []Tool {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-
-	tools := make([]Tool, 0, len(r.tools))
-	for _, tool := range r.tools {
-		tools = append(tools, tool)
-	}
-
-	return tools
+	return r.Snapshot().Tools()
 }
 
 // GetToolsByServer returns all tools from a specific server
 func (r *ToolRegistry) GetToolsByServer(serverName string) []Tool {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-
-	var tools []Tool
-	for _, tool := range r.tools {
-		if tool.ServerName == serverName {
-			tools = append(tools, tool)
-		}
-	}
-
-	return tools
+	return r.Snapshot().ToolsForServer(serverName)
 }
 
 // Clear removes all tools and servers from the registry
@@ -293,9 +240,10 @@ func (r *ToolRegistry) Clear() {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
-	r.tools = make(map[string]Tool)
-	r.servers = make(map[string]Client)
-	r.cache.Clear()
+	r.snapshot.Store(newToolSnapshot(r.Snapshot().Version + 1))
+	for name := range r.pending {
+		r.resolvePendingLocked(name)
+	}
 	
 	r.logger.Info("Cleared tool registry")
-}
\ No newline at end of file
+}
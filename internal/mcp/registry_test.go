@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// manyToolsClient is a fakeClient that offers many tools
+type manyToolsClient struct {
+	*fakeClient
+	tools []Tool
+}
+
+func (c *manyToolsClient) ListTools(ctx context.Context) ([]Tool, error) {
+	return c.tools, nil
+}
+
+// newLargeRegistry returns a registry of servers servers with perServer
+// tools each, named tool-<server>-<n>
+func newLargeRegistry(tb testing.TB, servers, perServer int) *ToolRegistry {
+	tb.Helper()
+	registry := NewToolRegistry(&SimpleLogger{Logger: log.New(io.Discard, "", 0)})
+	for s := 0; s < servers; s++ {
+		tools := make([]Tool, perServer)
+		for n := range tools {
+			tools[n] = Tool{
+				Name:        fmt.Sprintf("tool-%d-%d", s, n),
+				Description: "Reads and writes records of the example service",
+				InputSchema: map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"query": map[string]interface{}{"type": "string"}},
+				},
+			}
+		}
+		require.NoError(tb, registry.RegisterServer(fmt.Sprintf("server-%d", s), &manyToolsClient{fakeClient: &fakeClient{}, tools: tools}))
+	}
+	return registry
+}
+
+func TestToolRegistry_LookupDoesNotAllocate(t *testing.T) {
+	registry := newLargeRegistry(t, 5, 120)
+	require.Equal(t, 600, registry.GetToolCount())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, ok := registry.GetTool("tool-4-119"); !ok {
+			t.Fatal("tool not found")
+		}
+	})
+	assert.Zero(t, allocs, "Looking up a tool is on the path of every tool call")
+}
+
+func TestToolRegistry_CachedToolsWaitForServer(t *testing.T) {
+	registry := NewToolRegistry(&SimpleLogger{Logger: log.New(io.Discard, "", 0)})
+	registry.RegisterCachedTools("notes", []Tool{{Name: "search_notes"}, {Name: "old_tool"}})
+
+	tool, ok := registry.GetTool("search_notes")
+	require.True(t, ok, "Cached tools are offered before the server starts")
+	assert.Equal(t, "notes", tool.ServerName)
+	_, ok = registry.GetServer("notes")
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, ok = registry.WaitForServer(ctx, "notes")
+	assert.False(t, ok, "Waiting ends with the context")
+
+	client := &manyToolsClient{fakeClient: newFakeClient(), tools: []Tool{{Name: "search_notes"}, {Name: "add_note"}}}
+	waited := make(chan Client)
+	go func() {
+		client, _ := registry.WaitForServer(context.Background(), "notes")
+		waited <- client
+	}()
+	require.NoError(t, registry.RegisterServer("notes", client))
+	assert.Same(t, client, <-waited)
+
+	_, ok = registry.GetTool("old_tool")
+	assert.False(t, ok, "The tools the server offers replace the cached ones")
+	_, ok = registry.GetTool("add_note")
+	assert.True(t, ok)
+	assert.Equal(t, 2, registry.GetToolCount())
+}
+
+func TestToolRegistry_UnregisterDropsCachedTools(t *testing.T) {
+	registry := NewToolRegistry(&SimpleLogger{Logger: log.New(io.Discard, "", 0)})
+	registry.RegisterCachedTools("notes", []Tool{{Name: "search_notes"}})
+	registry.UnregisterServer("notes")
+
+	_, ok := registry.GetTool("search_notes")
+	assert.False(t, ok)
+	_, ok = registry.WaitForServer(context.Background(), "notes")
+	assert.False(t, ok, "A server that failed to start isn't waited for")
+}
+
+func TestToolRegistry_SnapshotsDontChange(t *testing.T) {
+	registry := newLargeRegistry(t, 1, 2)
+	before := registry.Snapshot()
+
+	client := &manyToolsClient{fakeClient: newFakeClient(), tools: []Tool{{Name: "tool-0-0"}, {Name: "tool-0-9"}}}
+	require.NoError(t, registry.RegisterServer("server-0", client))
+	after := registry.Snapshot()
+	assert.Greater(t, after.Version, before.Version)
+
+	_, ok := before.Tool("tool-0-1")
+	assert.True(t, ok, "An earlier snapshot keeps the tools it had")
+	_, ok = before.Tool("tool-0-9")
+	assert.False(t, ok)
+	_, ok = after.Tool("tool-0-1")
+	assert.False(t, ok)
+	server, _ := after.Server("server-0")
+	assert.Same(t, client, server)
+
+	registry.Clear()
+	assert.Zero(t, registry.Snapshot().ToolCount())
+	assert.Equal(t, 2, after.ToolCount())
+	assert.Greater(t, registry.Snapshot().Version, after.Version)
+}
+
+func TestToolRegistry_LookupsDuringRefresh(t *testing.T) {
+	registry := newLargeRegistry(t, 3, 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				snapshot := registry.Snapshot()
+				tool, ok := snapshot.Tool("tool-2-7")
+				if !assert.True(t, ok, "A tool never goes missing while its server is refreshed") {
+					return
+				}
+				_, ok = snapshot.Server(tool.ServerName)
+				assert.True(t, ok)
+				assert.Len(t, snapshot.Tools(), 60)
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		require.NoError(t, registry.RefreshTools(context.Background()))
+	}
+	cancel()
+	wg.Wait()
+}
+
+func BenchmarkToolRegistry(b *testing.B) {
+	for _, size := range []struct{ servers, perServer int }{{5, 100}, {10, 200}} {
+		registry := newLargeRegistry(b, size.servers, size.perServer)
+		total := size.servers * size.perServer
+
+		b.Run(fmt.Sprintf("GetTool/%d", total), func(b *testing.B) {
+			names := make([]string, 64)
+			for i := range names {
+				names[i] = fmt.Sprintf("tool-%d-%d", i%size.servers, i*7%size.perServer)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, ok := registry.GetTool(names[i%len(names)]); !ok {
+					b.Fatal("tool not found")
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("GetToolMissing/%d", total), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				registry.GetTool("missing")
+			}
+		})
+		b.Run(fmt.Sprintf("ListTools/%d", total), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				registry.ListTools()
+			}
+		})
+		b.Run(fmt.Sprintf("GetToolsByServer/%d", total), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				registry.GetToolsByServer("server-1")
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+)
+
+// sandboxInheritEnv is the environment a sandboxed server inherits when its
+// sandbox doesn't list any: enough to find programs and a home directory
+var sandboxInheritEnv = []string{"PATH", "HOME", "USER", "LANG", "TMPDIR"}
+
+// serverEnv returns the environment of a stdio server: othello's, or only
+// the variables its sandbox inherits, with the server's own env added
+func serverEnv(server Server) []string {
+	var env []string
+	if server.Sandbox == nil {
+		env = os.Environ()
+	} else {
+		inherit := server.Sandbox.InheritEnv
+		if len(inherit) == 0 {
+			inherit = sandboxInheritEnv
+		}
+		for _, variable := range os.Environ() {
+			name, _, _ := strings.Cut(variable, "=")
+			if slices.Contains(inherit, name) {
+				env = append(env, variable)
+			}
+		}
+	}
+	for key, value := range server.Env {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env
+}
+
+// startSandboxed starts cmd in its own process group with the limits of
+// sandbox. The limits are set before the server's program runs, so none of
+// its work happens without them.
+func startSandboxed(cmd *exec.Cmd, sandbox *config.SandboxConfig) error {
+	if err := limitCommand(cmd, sandbox); err != nil {
+		return fmt.Errorf("sandbox: %w", err)
+	}
+	isolateProcess(cmd)
+	return cmd.Start()
+}
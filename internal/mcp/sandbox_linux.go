@@ -0,0 +1,49 @@
+//go:build linux
+
+package mcp
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+)
+
+// sandboxShell sets the limits of a sandboxed server before running it. It
+// is given the server's command and arguments, and replaces itself with them
+// once the limits are set.
+const sandboxShell = "/bin/sh"
+
+// limitCommand makes cmd set the resource limits of sandbox before it runs
+// the server's program, by running it through sandboxShell. The limits are
+// set both soft and hard, so the server can't raise them, and the processes
+// it starts inherit them. When a limit can't be set the shell exits without
+// running the server.
+func limitCommand(cmd *exec.Cmd, sandbox *config.SandboxConfig) error {
+	limits := []struct {
+		flag  string
+		value int
+		scale int
+	}{
+		{"-d", sandbox.MemoryMB, 1024}, // In kilobytes
+		{"-t", sandbox.CPUSeconds, 1},
+		{"-n", sandbox.MaxFiles, 1},
+	}
+	var script []string
+	for _, limit := range limits {
+		if limit.value != 0 {
+			script = append(script, fmt.Sprintf("ulimit %s %d", limit.flag, limit.value*limit.scale))
+		}
+	}
+	if len(script) == 0 {
+		return nil
+	}
+	if cmd.Err != nil {
+		return cmd.Err
+	}
+	script = append(script, `exec "$@"`)
+	cmd.Args = append([]string{"sh", "-c", strings.Join(script, " && "), "sh", cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = sandboxShell
+	return nil
+}
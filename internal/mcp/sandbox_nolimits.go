@@ -0,0 +1,19 @@
+//go:build !linux
+
+package mcp
+
+import (
+	"errors"
+	"os/exec"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+)
+
+// limitCommand fails when sandbox sets limits, which are only supported on
+// Linux, rather than run the server without them
+func limitCommand(cmd *exec.Cmd, sandbox *config.SandboxConfig) error {
+	if sandbox.MemoryMB != 0 || sandbox.CPUSeconds != 0 || sandbox.MaxFiles != 0 {
+		return errors.New("resource limits are only supported on Linux")
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package mcp
+
+import "os/exec"
+
+// isolateProcess does nothing: process groups are only supported on Unix
+func isolateProcess(cmd *exec.Cmd) {}
+
+// killProcess kills the process of cmd. Only Unix has process groups, so
+// the processes it started are left running.
+func killProcess(cmd *exec.Cmd, group bool) error {
+	return cmd.Process.Kill()
+}
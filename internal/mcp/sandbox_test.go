@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSandboxedClient returns a client for a sandboxed shell script that
+// answers the initialize request and then runs rest
+func newSandboxedClient(t *testing.T, rest string, sandbox *config.SandboxConfig) *STDIOClient {
+	t.Helper()
+	client := newScriptedSTDIOClient(t, rest)
+	client.server.Sandbox = sandbox
+	client.server.Env = map[string]string{"SERVER_TOKEN": "abc"}
+	return client
+}
+
+func TestServerEnv(t *testing.T) {
+	t.Setenv("OTHELLO_TEST_SECRET", "s3cr3t")
+	server := Server{Env: map[string]string{"SERVER_TOKEN": "abc"}}
+	assert.Contains(t, serverEnv(server), "OTHELLO_TEST_SECRET=s3cr3t", "Servers inherit othello's environment")
+	assert.Contains(t, serverEnv(server), "SERVER_TOKEN=abc")
+
+	server.Sandbox = &config.SandboxConfig{}
+	env := serverEnv(server)
+	assert.NotContains(t, env, "OTHELLO_TEST_SECRET=s3cr3t", "Sandboxed servers only inherit what they need")
+	assert.Contains(t, env, "PATH="+os.Getenv("PATH"))
+	assert.Contains(t, env, "SERVER_TOKEN=abc")
+
+	server.Sandbox.InheritEnv = []string{"OTHELLO_TEST_SECRET"}
+	assert.ElementsMatch(t, []string{"OTHELLO_TEST_SECRET=s3cr3t", "SERVER_TOKEN=abc"}, serverEnv(server))
+}
+
+func TestSTDIOClient_SandboxLimitsServer(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resource limits are only supported on Linux")
+	}
+	limits := filepath.Join(t.TempDir(), "limits")
+	client := newSandboxedClient(t, "ulimit -n > "+limits+"; ulimit -d >> "+limits+"; cat >/dev/null",
+		&config.SandboxConfig{MaxFiles: 64, MemoryMB: 512})
+	require.NoError(t, client.Connect(context.Background()))
+	require.NoError(t, client.Disconnect(context.Background()))
+
+	data, err := os.ReadFile(limits)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"64", "524288"}, strings.Fields(string(data)))
+}
+
+func TestSTDIOClient_SandboxedServerNeedsItsLimits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resource limits are only supported on Linux")
+	}
+	ran := filepath.Join(t.TempDir(), "ran")
+	client := newSandboxedClient(t, "touch "+ran+"; cat >/dev/null", &config.SandboxConfig{MaxFiles: 1 << 30})
+	assert.Error(t, client.Connect(context.Background()))
+	client.Disconnect(context.Background())
+	assert.NoFileExists(t, ran, "A server whose limits can't be set never runs")
+}
+
+func TestSTDIOClient_SandboxedServerStopsWithItsProcesses(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the test reads processes from /proc")
+	}
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	client := newSandboxedClient(t, "sleep 60 & echo $! > "+pidFile+"; cat >/dev/null", &config.SandboxConfig{})
+	require.NoError(t, client.Connect(context.Background()))
+	assert.NotEqual(t, os.Getpid(), client.cmd.Process.Pid)
+	require.NoError(t, client.Disconnect(context.Background()))
+
+	data, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		// The process is gone, or dead and waiting to be reaped
+		stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+		return err != nil || strings.Contains(string(stat), ") Z ")
+	}, 2*time.Second, 20*time.Millisecond, "The server's background process is killed with it")
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package mcp
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolateProcess makes cmd start a process group of its own, so the
+// processes it starts can be stopped with it and signals sent to othello's
+// group don't reach it
+func isolateProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcess kills the process of cmd, along with its process group when
+// group is set
+func killProcess(cmd *exec.Cmd, group bool) error {
+	if group {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,42 @@
+package mcp
+
+// ToolSchema is a tool's input schema parsed for validating calls, so the
+// schema isn't walked again for each call
+type ToolSchema struct {
+	Properties map[string]map[string]interface{} // Schema of each parameter, nil when it isn't an object; nil when the schema lists none
+	Required   []string                          // Parameters every call must have, in the schema's order
+}
+
+// ParseToolSchema parses an input schema. A nil schema parses to nil.
+func ParseToolSchema(schema map[string]interface{}) *ToolSchema {
+	if schema == nil {
+		return nil
+	}
+	parsed := &ToolSchema{}
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		parsed.Properties = make(map[string]map[string]interface{}, len(properties))
+		for name, property := range properties {
+			parsed.Properties[name], _ = property.(map[string]interface{})
+		}
+	}
+	switch required := schema["required"].(type) {
+	case []interface{}:
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				parsed.Required = append(parsed.Required, name)
+			}
+		}
+	case []string:
+		parsed.Required = append(parsed.Required, required...)
+	}
+	return parsed
+}
+
+// Schema returns the parsed input schema of the tool, nil when it has none.
+// Tools from a ToolRegistry are parsed once, when they are registered.
+func (t Tool) Schema() *ToolSchema {
+	if t.schema != nil {
+		return t.schema
+	}
+	return ParseToolSchema(t.InputSchema)
+}
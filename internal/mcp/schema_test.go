@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToolSchema(t *testing.T) {
+	assert.Nil(t, ParseToolSchema(nil))
+
+	schema := ParseToolSchema(map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string"},
+			"raw":   true,
+		},
+		"required": []interface{}{"query", 3},
+	})
+	assert.Equal(t, &ToolSchema{
+		Properties: map[string]map[string]interface{}{"query": {"type": "string"}, "raw": nil},
+		Required:   []string{"query"},
+	}, schema)
+
+	schema = ParseToolSchema(map[string]interface{}{"required": []string{"path"}})
+	assert.Nil(t, schema.Properties, "A schema without properties lists none")
+	assert.Equal(t, []string{"path"}, schema.Required, "Schemas written in Go list required parameters as strings")
+}
+
+func TestToolRegistry_ParsesSchemasOnRegistration(t *testing.T) {
+	registry := newLargeRegistry(t, 1, 3)
+	tool, ok := registry.GetTool("tool-0-1")
+	require.True(t, ok)
+	schema := tool.Schema()
+	require.NotNil(t, schema)
+	assert.Contains(t, schema.Properties, "query")
+
+	again, _ := registry.GetTool("tool-0-1")
+	assert.Same(t, schema, again.Schema(), "The schema is parsed once, not per lookup")
+	allocs := testing.AllocsPerRun(100, func() {
+		again.Schema()
+	})
+	assert.Zero(t, allocs)
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"maps"
+	"time"
+)
+
+// ToolSnapshot is the tools and servers of a ToolRegistry at one version. A
+// snapshot never changes: the registry makes a new one for each change, so
+// readers hold a consistent view without locks while tools are refreshed.
+type ToolSnapshot struct {
+	Version uint64 // Increases with each change to the registry
+	tools   map[string]Tool
+	servers map[string]Client
+}
+
+// newToolSnapshot returns an empty snapshot at version
+func newToolSnapshot(version uint64) *ToolSnapshot {
+	return &ToolSnapshot{
+		Version: version,
+		tools:   make(map[string]Tool),
+		servers: make(map[string]Client),
+	}
+}
+
+// next returns a copy of the snapshot at the next version, to be changed
+// before it is published
+func (s *ToolSnapshot) next() *ToolSnapshot {
+	return &ToolSnapshot{
+		Version: s.Version + 1,
+		tools:   maps.Clone(s.tools),
+		servers: maps.Clone(s.servers),
+	}
+}
+
+// replaceTools replaces the tools of a server with tools, in a snapshot not
+// yet published
+func (s *ToolSnapshot) replaceTools(serverName string, tools []Tool) {
+	for name, tool := range s.tools {
+		if tool.ServerName == serverName {
+			delete(s.tools, name)
+		}
+	}
+	for _, tool := range tools {
+		tool.ServerName = serverName
+		tool.LastUpdated = time.Now()
+		tool.schema = ParseToolSchema(tool.InputSchema)
+		s.tools[tool.Name] = tool
+	}
+}
+
+// Tool returns the tool called name
+func (s *ToolSnapshot) Tool(name string) (Tool, bool) {
+	tool, exists := s.tools[name]
+	return tool, exists
+}
+
+// Tools returns all the tools
+func (s *ToolSnapshot) Tools() []Tool {
+	tools := make([]Tool, 0, len(s.tools))
+	for _, tool := range s.tools {
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+// ToolsForServer returns the tools of a server
+func (s *ToolSnapshot) ToolsForServer(serverName string) []Tool {
+	var tools []Tool
+	for _, tool := range s.tools {
+		if tool.ServerName == serverName {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// Server returns the client of a registered server
+func (s *ToolSnapshot) Server(name string) (Client, bool) {
+	client, exists := s.servers[name]
+	return client, exists
+}
+
+// Servers returns the names of the registered servers
+func (s *ToolSnapshot) Servers() []string {
+	servers := make([]string, 0, len(s.servers))
+	for name := range s.servers {
+		servers = append(servers, name)
+	}
+	return servers
+}
+
+// ToolCount returns the number of tools
+func (s *ToolSnapshot) ToolCount() int {
+	return len(s.tools)
+}
+
+// ServerCount returns the number of registered servers
+func (s *ToolSnapshot) ServerCount() int {
+	return len(s.servers)
+}
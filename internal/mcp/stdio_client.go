@@ -6,13 +6,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// stdioExitGrace is how long Disconnect waits for a server to exit after its
+// stdin is closed before killing it
+const stdioExitGrace = 2 * time.Second
+
 // STDIOClient implements the Client interface for STDIO-based MCP servers
 type STDIOClient struct {
 	server     Server
@@ -47,14 +50,13 @@ func (c *STDIOClient) Connect(ctx context.Context) error {
 		return fmt.Errorf("no command specified for server %s", c.server.Name)
 	}
 	
+	// The process outlives ctx, which only bounds the handshake, and is
+	// ended by Disconnect
 	args := append(c.server.Command[1:], c.server.Args...)
-	c.cmd = exec.CommandContext(ctx, c.server.Command[0], args...)
+	c.cmd = exec.Command(c.server.Command[0], args...)
 	
 	// Set environment variables
-	c.cmd.Env = os.Environ()
-	for key, value := range c.server.Env {
-		c.cmd.Env = append(c.cmd.Env, fmt.Sprintf("%s=%s", key, value))
-	}
+	c.cmd.Env = serverEnv(c.server)
 	
 	// Set up pipes
 	var err error
@@ -74,7 +76,11 @@ func (c *STDIOClient) Connect(ctx context.Context) error {
 	}
 	
 	// Start the process
-	if err := c.cmd.Start(); err != nil {
+	if c.server.Sandbox != nil {
+		if err := startSandboxed(c.cmd, c.server.Sandbox); err != nil {
+			return fmt.Errorf("start MCP server process: %w", err)
+		}
+	} else if err := c.cmd.Start(); err != nil {
 		return fmt.Errorf("start MCP server process: %w", err)
 	}
 	
@@ -86,7 +92,11 @@ func (c *STDIOClient) Connect(ctx context.Context) error {
 	c.logger.Info("Connected to MCP server name=%s pid=%d", c.server.Name, c.cmd.Process.Pid)
 	
 	// Send initialize request
-	return c.initialize(ctx)
+	if err := c.initialize(ctx); err != nil {
+		c.Disconnect(context.Background())
+		return err
+	}
+	return nil
 }
 
 // Disconnect closes the connection to the MCP server
@@ -108,12 +118,33 @@ func (c *STDIOClient) Disconnect(ctx context.Context) error {
 		c.stderr.Close()
 	}
 	
-	// Terminate process
+	// Closing stdin asks the server to exit; it's killed if it doesn't
+	// within the grace period or before ctx is done
 	if c.cmd != nil && c.cmd.Process != nil {
-		if err := c.cmd.Process.Kill(); err != nil {
-			c.logger.Error("Failed to kill MCP server process: %v", err)
+		exited := make(chan struct{})
+		go func() {
+			c.cmd.Wait()
+			close(exited)
+		}()
+		grace := time.NewTimer(stdioExitGrace)
+		defer grace.Stop()
+		select {
+		case <-exited:
+		case <-grace.C:
+		case <-ctx.Done():
+		}
+		select {
+		case <-exited:
+		default:
+			if err := killProcess(c.cmd, c.server.Sandbox != nil); err != nil {
+				c.logger.Error("Failed to kill MCP server process: %v", err)
+			}
+			<-exited
+		}
+		if c.server.Sandbox != nil {
+			// Processes the server started and left behind go with it
+			killProcess(c.cmd, true)
 		}
-		c.cmd.Wait() // Wait for process to exit
 	}
 	
 	c.logger.Info("Disconnected from MCP server", "name", c.server.Name)
@@ -133,7 +164,7 @@ func (c *STDIOClient) GetTransport() string {
 // ListTools lists all available tools from the server
 func (c *STDIOClient) ListTools(ctx context.Context) ([]Tool, error) {
 	if !c.IsConnected() {
-		return nil, fmt.Errorf("not connected to server")
+		return nil, fmt.Errorf("%w: not connected to server", ErrServerUnavailable)
 	}
 	
 	msg := Message{
@@ -164,7 +195,7 @@ func (c *STDIOClient) ListTools(ctx context.Context) ([]Tool, error) {
 // CallTool executes a tool with the given parameters
 func (c *STDIOClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (*ToolResult, error) {
 	if !c.IsConnected() {
-		return nil, fmt.Errorf("not connected to server")
+		return nil, fmt.Errorf("%w: not connected to server", ErrServerUnavailable)
 	}
 	
 	msg := Message{
@@ -204,7 +235,7 @@ func (c *STDIOClient) CallTool(ctx context.Context, name string, params map[stri
 // GetInfo retrieves server information
 func (c *STDIOClient) GetInfo(ctx context.Context) (*ServerInfo, error) {
 	if !c.IsConnected() {
-		return nil, fmt.Errorf("not connected to server")
+		return nil, fmt.Errorf("%w: not connected to server", ErrServerUnavailable)
 	}
 	
 	msg := Message{
@@ -292,7 +323,7 @@ func (c *STDIOClient) sendRequest(ctx context.Context, msg Message) (Message, er
 	
 	data = append(data, '\n')
 	if _, err := c.stdin.Write(data); err != nil {
-		return Message{}, fmt.Errorf("write message: %w", err)
+		return Message{}, fmt.Errorf("%w: write message: %w", ErrServerUnavailable, err)
 	}
 	
 	// Wait for response
@@ -307,7 +338,7 @@ func (c *STDIOClient) sendRequest(ctx context.Context, msg Message) (Message, er
 	case <-ctx.Done():
 		return Message{}, ctx.Err()
 	case <-time.After(timeout):
-		return Message{}, fmt.Errorf("request timeout after %v", timeout)
+		return Message{}, fmt.Errorf("%w: request timeout after %v", ErrServerUnavailable, timeout)
 	}
 }
 
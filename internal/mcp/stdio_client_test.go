@@ -4,10 +4,13 @@ import (
 	"context"
 	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // SimpleLogger implements the Logger interface for testing
@@ -146,4 +149,44 @@ func TestSTDIOClient_ConnectWithoutCommand(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no command specified")
 	assert.False(t, client.IsConnected())
-}
\ No newline at end of file
+}
+// newScriptedSTDIOClient returns a client for a shell script that answers the
+// initialize request and then runs rest
+func newScriptedSTDIOClient(t *testing.T, rest string) *STDIOClient {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	script := `read line; echo '{"jsonrpc":"2.0","id":1,"result":{}}'; ` + rest
+	return NewSTDIOClient(Server{
+		Name:      "scripted",
+		Transport: "stdio",
+		Command:   []string{"sh", "-c", script},
+		Timeout:   5 * time.Second,
+	}, NewSimpleLogger())
+}
+
+func TestSTDIOClient_DisconnectLetsServerExit(t *testing.T) {
+	exited := filepath.Join(t.TempDir(), "exited")
+	client := newScriptedSTDIOClient(t, "cat >/dev/null; touch "+exited)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, client.Connect(ctx))
+	cancel()
+	assert.True(t, client.IsConnected(), "The server outlives the context it was started with")
+
+	require.NoError(t, client.Disconnect(context.Background()))
+	assert.FileExists(t, exited, "The server exits on its own once its input is closed")
+}
+
+func TestSTDIOClient_DisconnectKillsServerWhenContextDone(t *testing.T) {
+	client := newScriptedSTDIOClient(t, "exec sleep 60")
+	require.NoError(t, client.Connect(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	started := time.Now()
+	require.NoError(t, client.Disconnect(ctx))
+	assert.Less(t, time.Since(started), stdioExitGrace, "The server isn't given its grace period")
+	assert.False(t, client.cmd.ProcessState.Exited(), "The server was killed")
+}
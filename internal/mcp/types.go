@@ -3,6 +3,8 @@ package mcp
 import (
 	"context"
 	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/config"
 )
 
 // Tool represents an MCP tool with its metadata and schema
@@ -12,6 +14,8 @@ type Tool struct {
 	InputSchema map[string]interface{} `json:"inputSchema"`
 	ServerName  string                 `json:"serverName"`
 	LastUpdated time.Time              `json:"lastUpdated"`
+
+	schema *ToolSchema // InputSchema parsed when the tool was registered
 }
 
 // ToolResult represents the result of a tool execution
@@ -29,15 +33,16 @@ type Content struct {
 
 // Server represents an MCP server configuration
 type Server struct {
-	Name      string            `json:"name"`
-	Transport string            `json:"transport"` // "stdio" or "http"
-	Command   []string          `json:"command,omitempty"`
-	Args      []string          `json:"args,omitempty"`
-	URL       string            `json:"url,omitempty"`
-	Headers   map[string]string `json:"headers,omitempty"`
-	Env       map[string]string `json:"env,omitempty"`
-	Timeout   time.Duration     `json:"timeout"`
-	Connected bool              `json:"connected"`
+	Name      string                `json:"name"`
+	Transport string                `json:"transport"` // "stdio" or "http"
+	Command   []string              `json:"command,omitempty"`
+	Args      []string              `json:"args,omitempty"`
+	URL       string                `json:"url,omitempty"`
+	Headers   map[string]string     `json:"headers,omitempty"`
+	Env       map[string]string     `json:"env,omitempty"`
+	Timeout   time.Duration         `json:"timeout"`
+	Connected bool                  `json:"connected"`
+	Sandbox   *config.SandboxConfig `json:"sandbox,omitempty"` // Reduced privileges of a stdio server, nil to run it as othello does
 }
 
 // Client interface for MCP server communication
@@ -102,6 +107,7 @@ const (
 type ToolCallParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      map[string]interface{} `json:"_meta,omitempty"` // Such as the token progress notifications are sent for
 }
 
 // Tool list response
@@ -7,61 +7,6 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestToolCache(t *testing.T) {
-	cache := NewToolCache(time.Hour)
-	
-	// Test empty cache
-	_, exists := cache.Get("nonexistent")
-	assert.False(t, exists)
-	
-	// Test setting and getting a tool
-	tool := Tool{
-		Name:        "test-tool",
-		Description: "A test tool",
-		InputSchema: map[string]interface{}{
-			"type": "object",
-			"properties": map[string]interface{}{
-				"input": map[string]interface{}{
-					"type": "string",
-				},
-			},
-		},
-		ServerName: "test-server",
-	}
-	
-	cache.Set(tool)
-	
-	retrieved, exists := cache.Get("test-tool")
-	assert.True(t, exists)
-	assert.Equal(t, tool.Name, retrieved.Name)
-	assert.Equal(t, tool.Description, retrieved.Description)
-	assert.Equal(t, tool.ServerName, retrieved.ServerName)
-	assert.NotZero(t, retrieved.LastUpdated)
-}
-
-func TestToolCacheTTL(t *testing.T) {
-	cache := NewToolCache(time.Millisecond * 100) // Very short TTL for testing
-	
-	tool := Tool{
-		Name:        "ttl-test",
-		Description: "TTL test tool",
-		ServerName:  "test-server",
-	}
-	
-	cache.Set(tool)
-	
-	// Should exist immediately
-	_, exists := cache.Get("ttl-test")
-	assert.True(t, exists)
-	
-	// Wait for TTL to expire
-	time.Sleep(time.Millisecond * 150)
-	
-	// Should not exist after TTL
-	_, exists = cache.Get("ttl-test")
-	assert.False(t, exists)
-}
-
 func TestServer(t *testing.T) {
 	server := Server{
 		Name:      "test-server",
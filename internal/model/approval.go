@@ -0,0 +1,37 @@
+package model
+
+import (
+	"context"
+	"reflect"
+)
+
+// approvedCallsKey is the context key for the tool calls the user approved
+type approvedCallsKey struct{}
+
+// WithApprovedCalls returns a context that records the user's approval of
+// calls, so tools that must not run unattended can tell they were approved
+func WithApprovedCalls(ctx context.Context, calls []ToolCall) context.Context {
+	approved := append(ApprovedCalls(ctx), calls...)
+	return context.WithValue(ctx, approvedCallsKey{}, approved)
+}
+
+// ApprovedCalls returns the tool calls approved in ctx
+func ApprovedCalls(ctx context.Context) []ToolCall {
+	calls, _ := ctx.Value(approvedCallsKey{}).([]ToolCall)
+	return calls[:len(calls):len(calls)]
+}
+
+// CallApproved reports whether the user approved a call to name with
+// exactly these arguments. A call the model changed after approval, such as
+// one corrected after an error, is not approved.
+func CallApproved(ctx context.Context, name string, arguments map[string]interface{}) bool {
+	for _, call := range ApprovedCalls(ctx) {
+		if call.Name != name {
+			continue
+		}
+		if len(call.Arguments) == 0 && len(arguments) == 0 || reflect.DeepEqual(call.Arguments, arguments) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallApproved(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, CallApproved(ctx, "run_command", map[string]interface{}{"command": "ls"}))
+
+	ctx = WithApprovedCalls(ctx, []ToolCall{
+		{Name: "run_command", Arguments: map[string]interface{}{"command": "ls"}},
+		{Name: "list_servers"},
+	})
+	assert.True(t, CallApproved(ctx, "run_command", map[string]interface{}{"command": "ls"}))
+	assert.False(t, CallApproved(ctx, "run_command", map[string]interface{}{"command": "ls -a"}), "Changed arguments aren't approved")
+	assert.False(t, CallApproved(ctx, "delete_file", map[string]interface{}{"command": "ls"}))
+	assert.True(t, CallApproved(ctx, "list_servers", map[string]interface{}{}), "No arguments matches empty arguments")
+
+	ctx = WithApprovedCalls(ctx, []ToolCall{{Name: "search", Arguments: map[string]interface{}{"query": "x"}}})
+	assert.Len(t, ApprovedCalls(ctx), 3, "Approvals accumulate")
+}
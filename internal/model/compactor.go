@@ -0,0 +1,116 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// compactedMessageLength truncates each message quoted in a compaction request
+const compactedMessageLength = 2000
+
+// ContextCompactor keeps requests within the model's context window by
+// replacing older messages with a summary once the messages fill most of it
+type ContextCompactor struct {
+	Model      Model
+	Limit      int     // Tokens the context window holds
+	Threshold  float64 // Fraction of Limit at which to compact; 0 never compacts
+	KeepRecent int     // Latest messages kept as they are
+}
+
+// EstimateTokens roughly counts the tokens messages take up, at four
+// characters a token
+func EstimateTokens(messages []Message) int {
+	tokens := 0
+	for _, msg := range messages {
+		tokens += len(msg.Content)/4 + 4
+	}
+	return tokens
+}
+
+// NeedsCompaction reports whether messages come close enough to the limit to
+// be compacted
+func (c *ContextCompactor) NeedsCompaction(messages []Message) bool {
+	if c == nil || c.Threshold <= 0 || c.Limit <= 0 {
+		return false
+	}
+	return float64(EstimateTokens(messages)) >= c.Threshold*float64(c.Limit)
+}
+
+// CompactIfNeeded compacts messages when they come close to the limit and
+// returns them unchanged otherwise
+func (c *ContextCompactor) CompactIfNeeded(ctx context.Context, messages []Message, metadata map[string]interface{}) ([]Message, error) {
+	if !c.NeedsCompaction(messages) {
+		return messages, nil
+	}
+	return c.Compact(ctx, messages, metadata)
+}
+
+// Compact replaces the messages between the leading system messages and the
+// latest KeepRecent with a system message holding the model's summary of them
+// and the metadata worth keeping, such as IDs from tool results
+func (c *ContextCompactor) Compact(ctx context.Context, messages []Message, metadata map[string]interface{}) ([]Message, error) {
+	start := 0
+	for start < len(messages) && messages[start].Role == "system" {
+		start++
+	}
+	end := len(messages) - c.KeepRecent
+	// Tool results stay with the call that asked for them
+	for end > start && end < len(messages) && messages[end].Role == "tool" {
+		end--
+	}
+	if end <= start {
+		return messages, nil
+	}
+
+	response, err := c.Model.Generate(ctx, compactionPrompt(messages[start:end]), GenerateOptions{
+		Temperature: 0.2,
+		MaxTokens:   500,
+	})
+	if err != nil {
+		return messages, fmt.Errorf("failed to summarize %d messages: %w", end-start, err)
+	}
+	summary := strings.TrimSpace(response.Content)
+	if summary == "" {
+		return messages, fmt.Errorf("the model returned an empty summary")
+	}
+
+	content := "Summary of earlier messages:\n" + summary
+	if details := describeMetadata(metadata); details != "" {
+		content += "\n\nDetails from earlier tool results:\n" + details
+	}
+	compacted := append([]Message{}, messages[:start]...)
+	compacted = append(compacted, Message{Role: "system", Content: content})
+	return append(compacted, messages[end:]...), nil
+}
+
+// compactionPrompt asks for a summary of messages
+func compactionPrompt(messages []Message) string {
+	var b strings.Builder
+	b.WriteString("Summarize these messages from a conversation between a user and an AI assistant that uses tools. ")
+	b.WriteString("Keep the user's goals, decisions made, facts learned, and any identifiers, names, and numbers a later step could need. ")
+	b.WriteString("Reply with the summary only.\n\n")
+	for _, msg := range messages {
+		content := msg.Content
+		if runes := []rune(content); len(runes) > compactedMessageLength {
+			content = string(runes[:compactedMessageLength]) + "..."
+		}
+		fmt.Fprintf(&b, "%s: %s\n", msg.Role, content)
+	}
+	return b.String()
+}
+
+// describeMetadata lists metadata as "key: value" lines in key order
+func describeMetadata(metadata map[string]interface{}) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = fmt.Sprintf("- %s: %v", key, metadata[key])
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func compactionTestMessages() []Message {
+	return []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Content: "Find my notes on the Q3 launch"},
+		{Role: "assistant", Content: "TOOL_CALL: search"},
+		{Role: "tool", Content: "Result of search:\n" + strings.Repeat("launch notes ", 100)},
+		{Role: "assistant", Content: "The launch is on 3 October."},
+		{Role: "user", Content: "Who owns it?"},
+	}
+}
+
+func TestContextCompactor_CompactsOlderMessages(t *testing.T) {
+	m := &MockModel{}
+	m.On("Generate", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "user: Find my notes on the Q3 launch") && !strings.Contains(prompt, "Who owns it?")
+	}), mock.Anything).Return(&Response{Content: "The user asked about the Q3 launch, which is on 3 October."}, nil)
+	compactor := &ContextCompactor{Model: m, Limit: 400, Threshold: 0.5, KeepRecent: 2}
+	messages := compactionTestMessages()
+
+	require.True(t, compactor.NeedsCompaction(messages))
+	compacted, err := compactor.CompactIfNeeded(context.Background(), messages, map[string]interface{}{"memory_id": "mem_7"})
+	require.NoError(t, err)
+	require.Len(t, compacted, 4)
+	assert.Equal(t, messages[0], compacted[0])
+	assert.Equal(t, "Summary of earlier messages:\nThe user asked about the Q3 launch, which is on 3 October.\n\nDetails from earlier tool results:\n- memory_id: mem_7", compacted[1].Content)
+	assert.Equal(t, messages[4:], compacted[2:])
+	assert.False(t, compactor.NeedsCompaction(compacted))
+	m.AssertExpectations(t)
+}
+
+func TestContextCompactor_KeepsToolResultsWithTheirCall(t *testing.T) {
+	m := &MockModel{}
+	m.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&Response{Content: "The user wants the Q3 launch notes."}, nil)
+	compactor := &ContextCompactor{Model: m, KeepRecent: 3}
+
+	compacted, err := compactor.Compact(context.Background(), compactionTestMessages(), nil)
+	require.NoError(t, err)
+	require.Len(t, compacted, 6, "The kept messages start at the tool call, not its result")
+	assert.Equal(t, "Summary of earlier messages:\nThe user wants the Q3 launch notes.", compacted[1].Content)
+	assert.Equal(t, "TOOL_CALL: search", compacted[2].Content)
+}
+
+func TestContextCompactor_LeavesMessagesAlone(t *testing.T) {
+	messages := compactionTestMessages()
+
+	// Below the threshold, or with compaction off, the model isn't asked
+	for _, compactor := range []*ContextCompactor{
+		{Model: &MockModel{}, Limit: 100000, Threshold: 0.8, KeepRecent: 2},
+		{Model: &MockModel{}, Limit: 400, KeepRecent: 2},
+		nil,
+	} {
+		compacted, err := compactor.CompactIfNeeded(context.Background(), messages, nil)
+		require.NoError(t, err)
+		assert.Equal(t, messages, compacted)
+	}
+
+	failing := &MockModel{}
+	failing.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("model offline"))
+	compacted, err := (&ContextCompactor{Model: failing, KeepRecent: 2}).Compact(context.Background(), messages, nil)
+	assert.EqualError(t, err, "failed to summarize 3 messages: model offline")
+	assert.Equal(t, messages, compacted)
+}
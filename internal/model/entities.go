@@ -0,0 +1,182 @@
+package model
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxEntityHistory is the most values kept for one key
+const maxEntityHistory = 10
+
+// Entity is a value extracted from a tool result, such as a memory ID, kept
+// so follow-up requests can refer to it
+type Entity struct {
+	Key   string      // What the value is, such as memory_id
+	Value interface{} // The value, a string, number, or bool
+	Tool  string      // Tool whose result held it; "" when unknown
+	Seen  time.Time   // When it was last extracted
+
+	order int // Position among all values recorded, for ordering by recency
+}
+
+// EntityStore keeps the values extracted from a conversation's tool results.
+// Each key keeps a history, so a second memory_id doesn't lose the first:
+// the newest value is used unless the model asks about an earlier one.
+// The zero value is empty and ready to use; a nil store is empty.
+type EntityStore struct {
+	mu       sync.Mutex
+	entities map[string][]Entity // Values of each key, newest last
+	recorded int
+}
+
+// NewEntityStore returns an empty entity store
+func NewEntityStore() *EntityStore {
+	return &EntityStore{}
+}
+
+// Record keeps value as the newest for key, noting the tool it came from.
+// A value already kept for key becomes the newest rather than a duplicate.
+func (s *EntityStore) Record(key string, value interface{}, tool string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entities == nil {
+		s.entities = make(map[string][]Entity)
+	}
+	history := s.entities[key]
+	for i, entity := range history {
+		if reflect.DeepEqual(entity.Value, value) {
+			history = append(history[:i:i], history[i+1:]...)
+			break
+		}
+	}
+	s.recorded++
+	history = append(history, Entity{Key: key, Value: value, Tool: tool, Seen: time.Now(), order: s.recorded})
+	if len(history) > maxEntityHistory {
+		history = history[len(history)-maxEntityHistory:]
+	}
+	s.entities[key] = history
+}
+
+// Clone returns a copy of the store that changes independently of it
+func (s *EntityStore) Clone() *EntityStore {
+	clone := NewEntityStore()
+	if s == nil {
+		return clone
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clone.recorded = s.recorded
+	if s.entities != nil {
+		clone.entities = make(map[string][]Entity, len(s.entities))
+		for key, history := range s.entities {
+			clone.entities[key] = append([]Entity(nil), history...)
+		}
+	}
+	return clone
+}
+
+// Latest returns the newest value kept for key
+func (s *EntityStore) Latest(key string) (Entity, bool) {
+	if s == nil {
+		return Entity{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history := s.entities[key]
+	if len(history) == 0 {
+		return Entity{}, false
+	}
+	return history[len(history)-1], true
+}
+
+// History returns every value kept for key, newest first
+func (s *EntityStore) History(key string) []Entity {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history := s.entities[key]
+	entities := make([]Entity, len(history))
+	for i, entity := range history {
+		entities[len(history)-1-i] = entity
+	}
+	return entities
+}
+
+// Len returns how many keys have values
+func (s *EntityStore) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entities)
+}
+
+// Values returns the newest value of every key
+func (s *EntityStore) Values() map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, entity := range s.Recent(0) {
+		values[entity.Key] = entity.Value
+	}
+	return values
+}
+
+// Recent returns the newest value of each key, most recently extracted
+// first, up to limit of them; 0 returns them all
+func (s *EntityStore) Recent(limit int) []Entity {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	var entities []Entity
+	for _, history := range s.entities {
+		if len(history) > 0 {
+			entities = append(entities, history[len(history)-1])
+		}
+	}
+	s.mu.Unlock()
+	sortByRecency(entities)
+	if limit > 0 && len(entities) > limit {
+		entities = entities[:limit]
+	}
+	return entities
+}
+
+// Resolve returns the values that could fill a tool parameter called name,
+// most recent first: those kept under name itself, then those from the first
+// of a list of results (first_name). A parameter called id, or ending in _id,
+// is also resolved from generic id values.
+func (s *EntityStore) Resolve(name string) []Entity {
+	if s == nil {
+		return nil
+	}
+	name = strings.ToLower(name)
+	keys := []string{name, "first_" + name}
+	if name != "id" && strings.HasSuffix(name, "_id") {
+		keys = append(keys, "id", "first_id")
+	}
+
+	var exact, others []Entity
+	for i, key := range keys {
+		history := s.History(key)
+		if i == 0 {
+			exact = history
+		} else {
+			others = append(others, history...)
+		}
+	}
+	sortByRecency(others)
+	return append(exact, others...)
+}
+
+// sortByRecency orders entities from the most recently extracted
+func sortByRecency(entities []Entity) {
+	sort.SliceStable(entities, func(i, j int) bool {
+		return entities[i].order > entities[j].order
+	})
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// entityValues returns the values of entities in order
+func entityValues(entities []Entity) []interface{} {
+	values := make([]interface{}, 0, len(entities))
+	for _, entity := range entities {
+		values = append(values, entity.Value)
+	}
+	return values
+}
+
+func TestEntityStore_KeepsHistory(t *testing.T) {
+	store := NewEntityStore()
+	store.Record("memory_id", "mem-1", "store_memory")
+	store.Record("domain", "work", "store_memory")
+	store.Record("memory_id", "mem-2", "store_memory")
+
+	latest, ok := store.Latest("memory_id")
+	require.True(t, ok)
+	assert.Equal(t, "mem-2", latest.Value)
+	assert.Equal(t, "store_memory", latest.Tool)
+	assert.False(t, latest.Seen.IsZero())
+	assert.Equal(t, []interface{}{"mem-2", "mem-1"}, entityValues(store.History("memory_id")), "The first memory_id isn't lost")
+
+	store.Record("memory_id", "mem-1", "search")
+	assert.Equal(t, []interface{}{"mem-1", "mem-2"}, entityValues(store.History("memory_id")), "A value seen again becomes the newest")
+	latest, _ = store.Latest("memory_id")
+	assert.Equal(t, "search", latest.Tool)
+
+	assert.Equal(t, 2, store.Len())
+	assert.Equal(t, map[string]interface{}{"memory_id": "mem-1", "domain": "work"}, store.Values())
+	assert.Equal(t, []interface{}{"mem-1", "work"}, entityValues(store.Recent(0)))
+	assert.Equal(t, []interface{}{"mem-1"}, entityValues(store.Recent(1)))
+}
+
+func TestEntityStore_LimitsHistory(t *testing.T) {
+	store := NewEntityStore()
+	for i := 0; i < maxEntityHistory+5; i++ {
+		store.Record("id", fmt.Sprint(i), "")
+	}
+	history := store.History("id")
+	require.Len(t, history, maxEntityHistory)
+	assert.Equal(t, fmt.Sprint(maxEntityHistory+4), history[0].Value)
+}
+
+func TestEntityStore_Resolve(t *testing.T) {
+	store := NewEntityStore()
+	store.Record("first_memory_id", "mem-found", "search")
+	store.Record("id", "generic", "stats")
+	store.Record("memory_id", "mem-stored", "store_memory")
+	store.Record("category_id", "cat-1", "categorize")
+
+	assert.Equal(t, []interface{}{"mem-stored", "generic", "mem-found"}, entityValues(store.Resolve("Memory_ID")),
+		"Values kept under the name come first, then related ones by recency")
+	assert.Equal(t, []interface{}{"generic"}, entityValues(store.Resolve("id")))
+	assert.Empty(t, store.Resolve("domain"))
+}
+
+func TestEntityStore_NilIsEmpty(t *testing.T) {
+	var store *EntityStore
+	assert.Zero(t, store.Len())
+	assert.Empty(t, store.Values())
+	assert.Empty(t, store.History("id"))
+	assert.Empty(t, store.Resolve("id"))
+	_, ok := store.Latest("id")
+	assert.False(t, ok)
+}
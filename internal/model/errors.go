@@ -0,0 +1,22 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ErrModelTimeout is returned, wrapped with the details, when the model
+// doesn't answer in time
+var ErrModelTimeout = errors.New("model timed out")
+
+// requestError wraps err, a failure of step in a request to the model, with
+// ErrModelTimeout when the request ran out of time
+func requestError(step string, err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %s: %w", ErrModelTimeout, step, err)
+	}
+	return fmt.Errorf("%s: %w", step, err)
+}
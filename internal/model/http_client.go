@@ -115,14 +115,14 @@ func (c *HTTPClient) chatOpenAICompatible(ctx context.Context, messages []Messag
 	// Send request
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return nil, requestError("send request", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		return nil, requestError("read response", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -212,13 +212,13 @@ func (c *HTTPClient) chatLlamaCpp(ctx context.Context, messages []Message, optio
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return nil, requestError("send request", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		return nil, requestError("read response", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -291,13 +291,13 @@ func (c *HTTPClient) chatTextGenWebUI(ctx context.Context, messages []Message, o
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return nil, requestError("send request", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		return nil, requestError("read response", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -19,10 +19,16 @@ type Model interface {
 	IsAvailable(ctx context.Context) bool
 }
 
+// Embedder is implemented by models that can turn text into embedding vectors
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
 // Message represents a chat message
 type Message struct {
-	Role    string `json:"role"`    // "user", "assistant", "system"
-	Content string `json:"content"`
+	Role    string   `json:"role"`    // "user", "assistant", "system"
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"` // base64-encoded images for vision models
 }
 
 // ToolDefinition represents a tool that can be called by the model
@@ -38,21 +44,66 @@ type ToolCall struct {
 	Arguments map[string]interface{} `json:"arguments"`
 }
 
+// ToolReflectionPrompt follows tool results sent back to the model, asking it
+// to check them before deciding what to do next
+const ToolReflectionPrompt = "Review the tool results above. If a tool failed or returned something unexpected, work out why, then call it again with corrected arguments or try another tool. If the request needs further steps, call the tools for them. Otherwise answer the user's request from the results without calling any tools."
+
+// ToolResultMessages returns the messages that hand a round of tool calls
+// back to the model: its reply that made the calls, the result of each call,
+// and ToolReflectionPrompt. results holds one entry per call.
+func ToolResultMessages(reply string, calls []ToolCall, results []string) []Message {
+	if strings.TrimSpace(reply) == "" {
+		// Native tool calls come without text, so spell them out
+		reply = FormatToolCalls(calls)
+	}
+
+	messages := []Message{{Role: "assistant", Content: reply}}
+	for i, call := range calls {
+		messages = append(messages, Message{Role: "tool", Content: fmt.Sprintf("Result of %s:\n%s", call.Name, results[i])})
+	}
+	return append(messages, Message{Role: "system", Content: ToolReflectionPrompt})
+}
+
+// FormatToolCalls writes calls the way models are asked to make them
+func FormatToolCalls(calls []ToolCall) string {
+	lines := make([]string, 0, len(calls))
+	for _, call := range calls {
+		arguments := []byte("{}")
+		if len(call.Arguments) > 0 {
+			arguments, _ = json.Marshal(call.Arguments)
+		}
+		lines = append(lines, fmt.Sprintf("TOOL_CALL: %s\nARGUMENTS: %s", call.Name, arguments))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // ConversationContext provides context for intelligent response generation
 type ConversationContext struct {
-	History          []Message              // Recent conversation history
-	UserQuery        string                 // Current user query that triggered the tool
-	SessionType      string                 // Type of session (chat, analysis, etc.)
-	PreviousTools    []string               // Tools used recently in conversation
-	ExtractedMetadata map[string]interface{} // Key metadata extracted from tool results (e.g., memory_id, category_id)
+	History       []Message         // Recent conversation history
+	UserQuery     string            // Current user query that triggered the tool
+	SessionType   string            // Type of session (chat, analysis, etc.)
+	PreviousTools []string          // Tools used recently in conversation
+	Entities      *EntityStore      // Values such as memory_id and category_id extracted from tool results, with their history
+	FollowUps     []FollowUp        // Suggested next prompts for the latest tool result
+	FullResult    string            // The latest tool result before it was summarized; empty when it wasn't
+	TurnCalls     map[string]int    // Calls to each tool in the current request, limited by tool policies
+	TurnResults   map[string]string // Results of the current request's tool calls, so repeated calls aren't run again
+	Undo          []ToolCall        // Calls that reverse the reversible tool calls made so far, oldest first
+}
+
+// FollowUp is a suggested next step offered after a tool result
+type FollowUp struct {
+	Label  string // Short description shown to the user
+	Prompt string // Message sent when the suggestion is chosen
 }
 
 // GenerateOptions contains options for generation
 type GenerateOptions struct {
-	Temperature float64 `json:"temperature,omitempty"`
-	MaxTokens   int     `json:"max_tokens,omitempty"`
-	TopP        float64 `json:"top_p,omitempty"`
-	Stream      bool    `json:"stream,omitempty"`
+	Temperature   float64 `json:"temperature,omitempty"`
+	MaxTokens     int     `json:"max_tokens,omitempty"`
+	TopP          float64 `json:"top_p,omitempty"`
+	ContextLength int     `json:"context_length,omitempty"`
+	Stream        bool    `json:"stream,omitempty"`
 }
 
 // Response represents a model response
@@ -89,6 +140,16 @@ func NewOllamaModel(host, modelName string) *OllamaModel {
 	}
 }
 
+// Name returns the Ollama model name used for requests
+func (m *OllamaModel) Name() string {
+	return m.modelName
+}
+
+// SetTransport replaces the HTTP transport used for requests to Ollama
+func (m *OllamaModel) SetTransport(rt http.RoundTripper) {
+	m.client.Transport = rt
+}
+
 // Generate generates text from a prompt
 func (m *OllamaModel) Generate(ctx context.Context, prompt string, options GenerateOptions) (*Response, error) {
 	// Convert to chat format for consistency
@@ -119,6 +180,9 @@ func (m *OllamaModel) Chat(ctx context.Context, messages []Message, options Gene
 	if options.TopP > 0 {
 		payload["top_p"] = options.TopP
 	}
+	if options.ContextLength > 0 {
+		payload["num_ctx"] = options.ContextLength
+	}
 	
 	// Marshal request
 	requestBody, err := json.Marshal(payload)
@@ -138,14 +202,14 @@ func (m *OllamaModel) Chat(ctx context.Context, messages []Message, options Gene
 	// Send request
 	resp, err := m.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return nil, requestError("send request", err)
 	}
 	defer resp.Body.Close()
 	
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		return nil, requestError("read response", err)
 	}
 	
 	if resp.StatusCode != http.StatusOK {
@@ -159,6 +223,8 @@ func (m *OllamaModel) Chat(ctx context.Context, messages []Message, options Gene
 		} `json:"message"`
 		Done   bool `json:"done"`
 		Error  string `json:"error,omitempty"`
+		PromptEvalCount int `json:"prompt_eval_count"` // tokens in the prompt
+		EvalCount       int `json:"eval_count"`        // tokens generated
 	}
 	
 	if err := json.Unmarshal(body, &ollamaResponse); err != nil {
@@ -171,13 +237,20 @@ func (m *OllamaModel) Chat(ctx context.Context, messages []Message, options Gene
 	
 	duration := time.Since(start)
 	
+	usage := Usage{
+		PromptTokens:     ollamaResponse.PromptEvalCount,
+		CompletionTokens: ollamaResponse.EvalCount,
+		TotalTokens:      ollamaResponse.PromptEvalCount + ollamaResponse.EvalCount,
+	}
+	if usage.TotalTokens == 0 {
+		// Older Ollama versions don't report token counts
+		usage.TotalTokens = len(ollamaResponse.Message.Content) / 4 // Rough estimate
+	}
+	
 	return &Response{
 		Content:  ollamaResponse.Message.Content,
 		Duration: duration,
-		Usage: Usage{
-			// Ollama doesn't provide token counts by default
-			TotalTokens: len(ollamaResponse.Message.Content) / 4, // Rough estimate
-		},
+		Usage:    usage,
 	}, nil
 }
 
@@ -418,4 +491,86 @@ func (m *OllamaModel) IsAvailable(ctx context.Context) bool {
 	}
 	
 	return false
-}
\ No newline at end of file
+}
+// ListModels returns the names of the models installed in Ollama, as shown by `ollama list`
+func (m *OllamaModel) ListModels(ctx context.Context) ([]string, error) {
+	url := fmt.Sprintf("%s/api/tags", m.host)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connect to Ollama at %s: %w", m.host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	var tagsResponse struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tagsResponse); err != nil {
+		return nil, fmt.Errorf("decode model list: %w", err)
+	}
+
+	names := make([]string, 0, len(tagsResponse.Models))
+	for _, model := range tagsResponse.Models {
+		names = append(names, model.Name)
+	}
+	return names, nil
+}
+
+// Embed returns an embedding vector for each of texts, computed locally by
+// Ollama with this model
+func (m *OllamaModel) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"model": m.modelName,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/embed", m.host)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, requestError("send request", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, requestError("read response", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var embedResponse struct {
+		Embeddings [][]float32 `json:"embeddings"`
+		Error      string      `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(body, &embedResponse); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	if embedResponse.Error != "" {
+		return nil, fmt.Errorf("ollama error: %s", embedResponse.Error)
+	}
+	if len(embedResponse.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d texts", len(embedResponse.Embeddings), len(texts))
+	}
+
+	return embedResponse.Embeddings, nil
+}
@@ -2,10 +2,14 @@ package model
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOllamaModel_IsAvailable(t *testing.T) {
@@ -37,4 +41,88 @@ func TestNewOllamaModel(t *testing.T) {
 	assert.Equal(t, host, model.host)
 	assert.Equal(t, modelName, model.modelName)
 	assert.NotNil(t, model.client)
-}
\ No newline at end of file
+}
+func TestOllamaModel_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/tags", r.URL.Path)
+		w.Write([]byte(`{"models":[{"name":"qwen2.5:3b"},{"name":"llama3.1:8b"}]}`))
+	}))
+	defer server.Close()
+
+	models, err := NewOllamaModel(server.URL, "").ListModels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"qwen2.5:3b", "llama3.1:8b"}, models)
+}
+
+func TestOllamaModel_ListModelsUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewOllamaModel(server.URL, "").ListModels(context.Background())
+	assert.ErrorContains(t, err, "status 503")
+}
+
+func TestOllamaModel_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/embed", r.URL.Path)
+		var request struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "nomic-embed-text", request.Model)
+		assert.Equal(t, []string{"hello", "world"}, request.Input)
+		w.Write([]byte(`{"embeddings":[[0.1,0.2],[0.3,0.4]]}`))
+	}))
+	defer server.Close()
+
+	vectors, err := NewOllamaModel(server.URL, "nomic-embed-text").Embed(context.Background(), []string{"hello", "world"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{0.1, 0.2}, {0.3, 0.4}}, vectors)
+}
+
+func TestOllamaModel_EmbedModelMissing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"nomic-embed-text\" not found"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewOllamaModel(server.URL, "nomic-embed-text").Embed(context.Background(), []string{"hello"})
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestOllamaModel_ChatReportsTokenCounts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		w.Write([]byte(`{"message":{"content":"hi there"},"done":true,"prompt_eval_count":12,"eval_count":3}`))
+	}))
+	defer server.Close()
+
+	resp, err := NewOllamaModel(server.URL, "qwen2.5:3b").Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, GenerateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15}, resp.Usage)
+	assert.Positive(t, resp.Duration)
+}
+
+func TestOllamaModel_ChatTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := NewOllamaModel(server.URL, "qwen2.5:3b").Chat(ctx, []Message{{Role: "user", Content: "hi"}}, GenerateOptions{})
+	assert.ErrorIs(t, err, ErrModelTimeout)
+}
+
+func TestOllamaModel_ChatFailureIsNotTimeout(t *testing.T) {
+	_, err := NewOllamaModel("http://127.0.0.1:0", "qwen2.5:3b").Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, GenerateOptions{})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrModelTimeout)
+}
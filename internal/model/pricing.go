@@ -0,0 +1,19 @@
+package model
+
+// Pricing is what a hosted model charges per million tokens. The zero value
+// is a free, local model.
+type Pricing struct {
+	InputPerMillion  float64 // Price of a million prompt tokens
+	OutputPerMillion float64 // Price of a million generated tokens
+}
+
+// Free reports whether requests cost nothing
+func (p Pricing) Free() bool {
+	return p.InputPerMillion == 0 && p.OutputPerMillion == 0
+}
+
+// Cost returns the price of sending promptTokens and generating
+// completionTokens
+func (p Pricing) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.InputPerMillion + float64(completionTokens)*p.OutputPerMillion) / 1e6
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricing_Cost(t *testing.T) {
+	assert.True(t, Pricing{}.Free())
+	assert.Zero(t, Pricing{}.Cost(1000, 1000))
+
+	pricing := Pricing{InputPerMillion: 3, OutputPerMillion: 15}
+	assert.False(t, pricing.Free())
+	assert.InDelta(t, 0.0045, pricing.Cost(1000, 100), 1e-9)
+}
@@ -306,3 +306,17 @@ func TestCreateToolPrompt_SearchExample(t *testing.T) {
 	// The prompt should make the connection clear
 	require.NotEmpty(t, prompt, "Prompt should not be empty")
 }
+
+func TestToolResultMessages(t *testing.T) {
+	calls := []ToolCall{{Name: "search", Arguments: map[string]interface{}{"query": "RDS"}}, {Name: "stats"}}
+	messages := ToolResultMessages("", calls, []string{"2 memories found", "Error: tool stats not found"})
+
+	require.Len(t, messages, 4)
+	assert.Equal(t, Message{Role: "assistant", Content: "TOOL_CALL: search\nARGUMENTS: {\"query\":\"RDS\"}\nTOOL_CALL: stats\nARGUMENTS: {}"}, messages[0])
+	assert.Equal(t, Message{Role: "tool", Content: "Result of search:\n2 memories found"}, messages[1])
+	assert.Equal(t, Message{Role: "tool", Content: "Result of stats:\nError: tool stats not found"}, messages[2])
+	assert.Equal(t, Message{Role: "system", Content: ToolReflectionPrompt}, messages[3])
+
+	messages = ToolResultMessages("TOOL_CALL: stats", calls[1:], []string{"3 sessions"})
+	assert.Equal(t, "TOOL_CALL: stats", messages[0].Content, "A reply with text is kept as it was")
+}
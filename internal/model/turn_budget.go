@@ -0,0 +1,36 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTurnTimeout is why a turn's context ends when the turn used up its time
+// budget, agent.turn_timeout
+var ErrTurnTimeout = errors.New("the request ran out of time")
+
+// WithTurnBudget returns the context for one turn, which its model requests,
+// tool calls, and result processing run under, ending it after budget with
+// ErrTurnTimeout as the cause. A budget of zero leaves the turn unbounded.
+func WithTurnBudget(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	if budget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, budget, ErrTurnTimeout)
+}
+
+// TurnBudgetLeft returns how long is left before ctx's deadline, and false
+// when it has none
+func TurnBudgetLeft(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return max(time.Until(deadline), 0), true
+}
+
+// TurnTimedOut reports whether ctx ended because its turn ran out of time
+func TurnTimedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrTurnTimeout)
+}
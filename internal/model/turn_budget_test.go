@@ -0,0 +1,30 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTurnBudget(t *testing.T) {
+	ctx, cancel := WithTurnBudget(context.Background(), 0)
+	_, bounded := TurnBudgetLeft(ctx)
+	assert.False(t, bounded, "A zero budget leaves the turn unbounded")
+	cancel()
+	assert.False(t, TurnTimedOut(ctx), "A cancelled turn didn't run out of time")
+
+	ctx, cancel = WithTurnBudget(context.Background(), time.Minute)
+	defer cancel()
+	left, bounded := TurnBudgetLeft(ctx)
+	assert.True(t, bounded)
+	assert.InDelta(t, time.Minute, left, float64(time.Second))
+
+	ctx, cancel = WithTurnBudget(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.True(t, TurnTimedOut(ctx))
+	left, _ = TurnBudgetLeft(ctx)
+	assert.Zero(t, left)
+}
@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask is shown in place of a secret value
+const Mask = "********"
+
+// minRedactedLength is the shortest value Redact masks: shorter values, such
+// as "1" or "true" in a server's environment, are too likely to appear in
+// ordinary text to be masked in it
+const minRedactedLength = 6
+
+// known holds the values Redact masks
+var known struct {
+	sync.RWMutex
+	values   map[string]bool
+	replacer *strings.Replacer // Built from values when first needed
+}
+
+// Register adds values, such as resolved ${secret:name} references and the
+// environments and headers of MCP servers, to those Redact masks. For a value
+// such as "Bearer <token>", the token is masked on its own too.
+func Register(values ...string) {
+	known.Lock()
+	defer known.Unlock()
+
+	for _, value := range values {
+		candidates := []string{value}
+		if scheme, token, ok := strings.Cut(value, " "); ok && scheme != "" {
+			candidates = append(candidates, strings.TrimSpace(token))
+		}
+		for _, candidate := range candidates {
+			if len(candidate) < minRedactedLength || known.values[candidate] {
+				continue
+			}
+			if known.values == nil {
+				known.values = make(map[string]bool)
+			}
+			known.values[candidate] = true
+			known.replacer = nil
+		}
+	}
+}
+
+// Redact returns text with every registered secret replaced by Mask
+func Redact(text string) string {
+	known.RLock()
+	replacer := known.replacer
+	empty := len(known.values) == 0
+	known.RUnlock()
+	if empty {
+		return text
+	}
+	if replacer == nil {
+		replacer = buildReplacer()
+	}
+	return replacer.Replace(text)
+}
+
+// buildReplacer makes the replacer of the registered secrets, longest first
+// so a secret that contains another is masked whole
+func buildReplacer() *strings.Replacer {
+	known.Lock()
+	defer known.Unlock()
+
+	if known.replacer != nil {
+		return known.replacer
+	}
+	values := make([]string, 0, len(known.values))
+	for value := range known.values {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	pairs := make([]string, 0, 2*len(values))
+	for _, value := range values {
+		pairs = append(pairs, value, Mask)
+	}
+	known.replacer = strings.NewReplacer(pairs...)
+	return known.replacer
+}
+
+// RedactingWriter returns a writer that writes to w with registered secrets
+// masked. Each write is redacted on its own, so it suits writers that are
+// written whole lines, such as loggers.
+func RedactingWriter(w io.Writer) io.Writer {
+	return redactingWriter{w}
+}
+
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,27 @@
+package secrets
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact_MasksRegisteredSecrets(t *testing.T) {
+	assert.Equal(t, "token sk-live-123456 in text", Redact("token sk-live-123456 in text"), "Unregistered values are shown")
+
+	Register("sk-live-123456", "Bearer ghp_redact_test", "true", "")
+	assert.Equal(t, "token ******** in text", Redact("token sk-live-123456 in text"))
+	assert.Equal(t, "Authorization: ********", Redact("Authorization: Bearer ghp_redact_test"))
+	assert.Equal(t, "sent ******** again", Redact("sent ghp_redact_test again"), "The token of a header is masked on its own")
+	assert.Equal(t, "enabled: true", Redact("enabled: true"), "Short values aren't masked in text")
+
+	Register("sk-live-123456-extended")
+	assert.Equal(t, "key ********", Redact("key sk-live-123456-extended"), "The longest secret is masked whole")
+
+	var buf bytes.Buffer
+	logger := log.New(RedactingWriter(&buf), "", 0)
+	logger.Printf("Starting server with API_KEY=%s", "sk-live-123456")
+	assert.Equal(t, "Starting server with API_KEY=********\n", buf.String())
+}
@@ -0,0 +1,131 @@
+// Package secrets stores secrets such as API keys and tokens outside config
+// files, so config values can refer to them as ${secret:name}.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Service is the name secrets are stored under in the OS keychain
+const Service = "othello"
+
+// ErrNotFound is returned for a secret that hasn't been stored
+var ErrNotFound = errors.New("secret not found")
+
+// namePattern is what secret names may look like, so they fit in a
+// ${secret:name} reference
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Provider stores secrets by name
+type Provider interface {
+	// Get returns the secret name, or ErrNotFound
+	Get(name string) (string, error)
+	// Set stores value as the secret name, replacing any earlier value
+	Set(name, value string) error
+	// Delete removes the secret name, or returns ErrNotFound
+	Delete(name string) error
+}
+
+// ValidateName checks that name can be used as a secret name
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits, '.', '_', and '-'", name)
+	}
+	return nil
+}
+
+// Keychain is a Provider backed by the OS keychain: the macOS Keychain, the
+// Windows Credential Manager, or the Secret Service (libsecret) on Linux
+type Keychain struct {
+	service string
+}
+
+// NewKeychain returns a Provider that keeps secrets in the OS keychain under
+// Service
+func NewKeychain() *Keychain {
+	return &Keychain{service: Service}
+}
+
+// Get returns the secret name from the keychain
+func (k *Keychain) Get(name string) (string, error) {
+	value, err := keyring.Get(k.service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s from the keychain: %w", name, err)
+	}
+	return value, nil
+}
+
+// Set stores the secret name in the keychain
+func (k *Keychain) Set(name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if err := keyring.Set(k.service, name, value); err != nil {
+		return fmt.Errorf("failed to store secret %s in the keychain: %w", name, err)
+	}
+	return nil
+}
+
+// Delete removes the secret name from the keychain
+func (k *Keychain) Delete(name string) error {
+	err := keyring.Delete(k.service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete secret %s from the keychain: %w", name, err)
+	}
+	return nil
+}
+
+// Memory is a Provider that keeps secrets in memory, for tests
+type Memory struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// NewMemory returns an empty in-memory Provider
+func NewMemory() *Memory {
+	return &Memory{secrets: make(map[string]string)}
+}
+
+// Get returns the secret name
+func (m *Memory) Get(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// Set stores value as the secret name
+func (m *Memory) Set(name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.secrets[name] = value
+	return nil
+}
+
+// Delete removes the secret name
+func (m *Memory) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.secrets[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(m.secrets, name)
+	return nil
+}
@@ -0,0 +1,33 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeychain_StoresSecrets(t *testing.T) {
+	keyring.MockInit()
+	keychain := NewKeychain()
+
+	_, err := keychain.Get("github-token")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, keychain.Set("github-token", "ghp_example"))
+	value, err := keychain.Get("github-token")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_example", value)
+
+	require.NoError(t, keychain.Delete("github-token"))
+	assert.ErrorIs(t, keychain.Delete("github-token"), ErrNotFound)
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, ValidateName("openai.api_key-2"))
+	assert.Error(t, ValidateName(""))
+	assert.Error(t, ValidateName("has space"))
+	assert.Error(t, ValidateName("close}brace"), "Names must fit in a ${secret:name} reference")
+	assert.Error(t, NewMemory().Set("-flag", "x"))
+}
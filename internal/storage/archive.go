@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxArchiveLine bounds one conversation in a JSONL archive
+const maxArchiveLine = 64 << 20
+
+// ArchiveFormat identifies the file format of a history archive
+type ArchiveFormat string
+
+// Supported archive formats. Only JSONL archives can be imported again.
+const (
+	ArchiveJSONL    ArchiveFormat = "jsonl"
+	ArchiveMarkdown ArchiveFormat = "markdown"
+)
+
+// ParseArchiveFormat converts a user-supplied format name, such as "md", into an ArchiveFormat
+func ParseArchiveFormat(name string) (ArchiveFormat, error) {
+	switch strings.ToLower(name) {
+	case "jsonl", "ndjson":
+		return ArchiveJSONL, nil
+	case "markdown", "md":
+		return ArchiveMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown archive format %q (supported: jsonl, markdown)", name)
+}
+
+// ArchivedConversation is one line of a JSONL archive: a conversation and its
+// own messages, including tool calls and results. A branch refers to the
+// message it continues from by the ID that message has in the archive.
+type ArchivedConversation struct {
+	Conversation *Conversation `json:"conversation"`
+	Messages     []*Message    `json:"messages"`
+}
+
+// ImportResult reports what ImportArchive added
+type ImportResult struct {
+	Conversations int // Conversations added
+	Messages      int // Messages added with them
+	Skipped       int // Conversations already in the database
+}
+
+// ExportArchive writes every saved conversation to w, oldest first, and
+// returns how many were written. JSONL keeps each branch's own messages so
+// the archive can be imported again; Markdown writes each conversation as a
+// self-contained transcript, including the messages a branch inherits.
+func (s *ConversationStore) ExportArchive(w io.Writer, format ArchiveFormat) (int, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + conversationColumns + `
+		FROM conversations c
+		LEFT JOIN messages pm ON pm.id = c.parent_message_id
+		ORDER BY c.created_at ASC, c.id
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("query conversations: %w", err)
+	}
+	conversations, err := scanConversations(rows)
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	encoder := json.NewEncoder(w)
+	for i, conv := range conversations {
+		switch format {
+		case ArchiveJSONL:
+			messages, err := s.GetMessages(conv.ID, -1, 0)
+			if err != nil {
+				return i, err
+			}
+			if messages == nil {
+				messages = []*Message{}
+			}
+			if err := encoder.Encode(ArchivedConversation{Conversation: conv, Messages: messages}); err != nil {
+				return i, fmt.Errorf("write conversation: %w", err)
+			}
+		case ArchiveMarkdown:
+			thread, err := s.GetThread(conv.ID)
+			if err != nil {
+				return i, err
+			}
+			if i > 0 {
+				if _, err := io.WriteString(w, "---\n\n"); err != nil {
+					return i, err
+				}
+			}
+			if err := (markdownExporter{}).Export(w, conv, thread); err != nil {
+				return i, fmt.Errorf("write conversation: %w", err)
+			}
+		default:
+			return 0, fmt.Errorf("unsupported archive format: %s", format)
+		}
+	}
+	return len(conversations), nil
+}
+
+// ImportArchive adds the conversations in a JSONL archive written by
+// ExportArchive. Conversations already in the database are skipped, so an
+// archive can be imported more than once. Titles, summaries, and timestamps
+// are kept, and branches are relinked to the messages they continue from.
+func (s *ConversationStore) ImportArchive(r io.Reader) (*ImportResult, error) {
+	result := &ImportResult{}
+	// Archived message IDs are replaced on import; branches need the new ones
+	messageIDs := make(map[int64]int64)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxArchiveLine)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var archived ArchivedConversation
+		if err := json.Unmarshal(scanner.Bytes(), &archived); err != nil {
+			return result, fmt.Errorf("line %d: %w", line, err)
+		}
+		if archived.Conversation == nil || archived.Conversation.ID == "" {
+			return result, fmt.Errorf("line %d: missing conversation", line)
+		}
+
+		conv, err := s.GetConversation(archived.Conversation.ID)
+		if err != nil {
+			return result, err
+		}
+		if conv != nil {
+			// Branches in the archive may continue from these messages
+			existing, err := s.GetMessages(conv.ID, -1, 0)
+			if err != nil {
+				return result, err
+			}
+			if len(existing) == len(archived.Messages) {
+				for i, msg := range archived.Messages {
+					messageIDs[msg.ID] = existing[i].ID
+				}
+			}
+			result.Skipped++
+			continue
+		}
+
+		if err := s.importConversation(archived, messageIDs); err != nil {
+			return result, fmt.Errorf("line %d: %w", line, err)
+		}
+		result.Conversations++
+		result.Messages += len(archived.Messages)
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("read archive: %w", err)
+	}
+	return result, nil
+}
+
+// importConversation inserts an archived conversation with its messages,
+// recording their new IDs in messageIDs. A branch whose parent message was
+// not imported becomes a conversation of its own.
+func (s *ConversationStore) importConversation(archived ArchivedConversation, messageIDs map[int64]int64) error {
+	conv := archived.Conversation
+	var parent sql.NullInt64
+	if id, ok := messageIDs[conv.ParentMessageID]; ok && conv.ParentMessageID != 0 {
+		parent = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO conversations (id, title, created_at, updated_at, summary, mode, parent_message_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, conv.ID, conv.Title, conv.CreatedAt, conv.UpdatedAt, conv.Summary, conv.Mode, parent); err != nil {
+		return fmt.Errorf("insert conversation: %w", err)
+	}
+
+	imported := make(map[int64]int64, len(archived.Messages))
+	for _, archivedMsg := range archived.Messages {
+		msg := *archivedMsg
+		msg.ConversationID = conv.ID
+		if err := insertMessage(tx, &msg); err != nil {
+			return err
+		}
+		imported[archivedMsg.ID] = msg.ID
+	}
+
+	// Stats are counted here so the archived updated_at is kept
+	if _, err := tx.Exec(`
+		UPDATE conversations
+		SET message_count = (SELECT COUNT(*) FROM messages WHERE conversation_id = ?),
+			total_tokens = (SELECT COALESCE(SUM(token_count), 0) FROM messages WHERE conversation_id = ?)
+		WHERE id = ?
+	`, conv.ID, conv.ID, conv.ID); err != nil {
+		return fmt.Errorf("update conversation stats: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	for archivedID, id := range imported {
+		messageIDs[archivedID] = id
+	}
+	return nil
+}
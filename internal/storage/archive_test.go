@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArchiveFormat(t *testing.T) {
+	format, err := ParseArchiveFormat("MD")
+	require.NoError(t, err)
+	assert.Equal(t, ArchiveMarkdown, format)
+
+	format, err = ParseArchiveFormat("jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, ArchiveJSONL, format)
+
+	_, err = ParseArchiveFormat("html")
+	assert.Error(t, err)
+}
+
+func TestArchive_RoundTrip(t *testing.T) {
+	source := setupTestDB(t)
+	defer source.Close()
+
+	_, err := source.CreateConversation("root", "Deploying")
+	require.NoError(t, err)
+	root := addMessages(t, source, "root", "how do I deploy?")
+	require.NoError(t, source.AddMessage(&Message{
+		ConversationID: "root",
+		Role:           "tool",
+		ToolCall:       &ToolCall{ID: "call_1", Name: "run", Arguments: map[string]interface{}{"cmd": "make deploy"}},
+		ToolResult:     &ToolResult{ID: "call_1", Content: "deployed", IsError: false},
+		Timestamp:      time.Now(),
+		TokenCount:     12,
+	}))
+	require.NoError(t, source.UpdateConversationSummary("root", "Deploying with make", "The user deployed with make."))
+	_, err = source.CreateBranch("branch", "Deploy by hand", root[0].ID)
+	require.NoError(t, err)
+	addMessages(t, source, "branch", "copy the binary instead")
+
+	var archive bytes.Buffer
+	count, err := source.ExportArchive(&archive, ArchiveJSONL)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Len(t, strings.Split(strings.TrimSpace(archive.String()), "\n"), 2, "One conversation per line")
+
+	target := setupTestDB(t)
+	defer target.Close()
+	// Take the first message IDs so the archived ones don't line up by chance
+	_, err = target.CreateConversation("other", "Other")
+	require.NoError(t, err)
+	addMessages(t, target, "other", "unrelated", "messages")
+
+	result, err := target.ImportArchive(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, &ImportResult{Conversations: 2, Messages: 3}, result)
+
+	conv, err := target.GetConversation("root")
+	require.NoError(t, err)
+	original, err := source.GetConversation("root")
+	require.NoError(t, err)
+	assert.Equal(t, "Deploying with make", conv.Title)
+	assert.Equal(t, "The user deployed with make.", conv.Summary)
+	assert.Equal(t, 2, conv.MessageCount)
+	assert.Equal(t, 12, conv.TotalTokens)
+	assert.True(t, original.UpdatedAt.Equal(conv.UpdatedAt), "Timestamps are kept")
+
+	messages, err := target.GetMessages("root", -1, 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "make deploy", messages[1].ToolCall.Arguments["cmd"])
+	assert.Equal(t, "deployed", messages[1].ToolResult.Content)
+
+	branch, err := target.GetConversation("branch")
+	require.NoError(t, err)
+	assert.Equal(t, "root", branch.ParentConversationID)
+	assert.Equal(t, []string{"how do I deploy?", "copy the binary instead"}, threadContents(t, target, "branch"))
+
+	// Importing again adds nothing
+	result, err = target.ImportArchive(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, &ImportResult{Skipped: 2}, result)
+}
+
+func TestArchive_Markdown(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	_, err := store.CreateConversation("root", "Deploying")
+	require.NoError(t, err)
+	root := addMessages(t, store, "root", "how do I deploy?")
+	_, err = store.CreateBranch("branch", "Deploy by hand", root[0].ID)
+	require.NoError(t, err)
+	addMessages(t, store, "branch", "copy the binary instead")
+
+	var archive bytes.Buffer
+	_, err = store.ExportArchive(&archive, ArchiveMarkdown)
+	require.NoError(t, err)
+
+	transcripts := strings.Split(archive.String(), "---\n\n")
+	require.Len(t, transcripts, 2)
+	assert.Contains(t, transcripts[0], "# Deploying")
+	assert.Contains(t, transcripts[1], "# Deploy by hand")
+	assert.Contains(t, transcripts[1], "how do I deploy?", "A branch's transcript includes what it inherits")
+}
+
+func TestImportArchive_InvalidLine(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	_, err := store.ImportArchive(strings.NewReader("\n{\"conversation\": {\"id\": \"a\", \"title\": \"A\"}, \"messages\": []}\nnot json\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3")
+
+	_, err = store.ImportArchive(strings.NewReader(`{"messages": []}`))
+	assert.ErrorContains(t, err, "missing conversation")
+}
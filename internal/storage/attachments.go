@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Attachment is a file, image, or tool output kept with a message. Its data is
+// stored once per distinct content and read with ReadAttachment.
+type Attachment struct {
+	ID             int64     `json:"id"`
+	MessageID      int64     `json:"message_id"`
+	ConversationID string    `json:"conversation_id"`
+	Name           string    `json:"name"`
+	MediaType      string    `json:"media_type"`
+	Hash           string    `json:"hash"` // SHA-256 of the data
+	Size           int64     `json:"size"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// IsImage reports whether the attachment holds an image
+func (a *Attachment) IsImage() bool {
+	return strings.HasPrefix(a.MediaType, "image/")
+}
+
+// AttachmentFilter narrows ListAttachments; zero values match everything
+type AttachmentFilter struct {
+	ConversationIDs []string
+	MessageIDs      []int64
+}
+
+// DetectMediaType guesses the media type of data named name, preferring the
+// file extension and falling back to sniffing the content
+func DetectMediaType(name string, data []byte) string {
+	if mediaType := mime.TypeByExtension(filepath.Ext(name)); mediaType != "" {
+		return mediaType
+	}
+	return http.DetectContentType(data)
+}
+
+// AddAttachment stores data as an attachment of the message with messageID.
+// An empty mediaType is detected from name and data.
+func (s *ConversationStore) AddAttachment(messageID int64, name, mediaType string, data []byte) (*Attachment, error) {
+	if mediaType == "" {
+		mediaType = DetectMediaType(name, data)
+	}
+	sum := sha256.Sum256(data)
+	attachment := &Attachment{
+		MessageID: messageID,
+		Name:      filepath.Base(name),
+		MediaType: mediaType,
+		Hash:      hex.EncodeToString(sum[:]),
+		Size:      int64(len(data)),
+		CreatedAt: time.Now(),
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRow("SELECT conversation_id FROM messages WHERE id = ?", messageID).Scan(&attachment.ConversationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message %d not found", messageID)
+		}
+		return nil, fmt.Errorf("query message: %w", err)
+	}
+	if _, err := tx.Exec(
+		"INSERT OR IGNORE INTO blobs (hash, data, size, created_at) VALUES (?, ?, ?, ?)",
+		attachment.Hash, data, attachment.Size, attachment.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("insert blob: %w", err)
+	}
+	result, err := tx.Exec(`
+		INSERT INTO attachments (message_id, name, media_type, blob_hash, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, messageID, attachment.Name, attachment.MediaType, attachment.Hash, attachment.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert attachment: %w", err)
+	}
+	if attachment.ID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("get attachment ID: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	return attachment, nil
+}
+
+// ListAttachments returns the attachments matching filter in the order they
+// were added
+func (s *ConversationStore) ListAttachments(filter AttachmentFilter) ([]*Attachment, error) {
+	var conditions []string
+	var args []interface{}
+	if len(filter.ConversationIDs) > 0 {
+		conditions = append(conditions, "m.conversation_id IN ("+placeholders(len(filter.ConversationIDs))+")")
+		for _, id := range filter.ConversationIDs {
+			args = append(args, id)
+		}
+	}
+	if len(filter.MessageIDs) > 0 {
+		conditions = append(conditions, "a.message_id IN ("+placeholders(len(filter.MessageIDs))+")")
+		for _, id := range filter.MessageIDs {
+			args = append(args, id)
+		}
+	}
+
+	query := `
+		SELECT a.id, a.message_id, m.conversation_id, a.name, a.media_type, a.blob_hash, b.size, a.created_at
+		FROM attachments a
+		JOIN messages m ON m.id = a.message_id
+		JOIN blobs b ON b.hash = a.blob_hash
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY a.id"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*Attachment
+	for rows.Next() {
+		var a Attachment
+		if err := rows.Scan(&a.ID, &a.MessageID, &a.ConversationID, &a.Name, &a.MediaType, &a.Hash, &a.Size, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan attachment: %w", err)
+		}
+		attachments = append(attachments, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// ReadAttachment returns the data of the attachment with id
+func (s *ConversationStore) ReadAttachment(id int64) ([]byte, error) {
+	var data []byte
+	err := s.stmts.QueryRow(`
+		SELECT b.data FROM attachments a JOIN blobs b ON b.hash = a.blob_hash WHERE a.id = ?
+	`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read attachment: %w", err)
+	}
+	return data, nil
+}
+
+// deleteUnreferencedBlobs removes the stored data no attachment refers to
+// any more
+func deleteUnreferencedBlobs(db execer) error {
+	if _, err := db.Exec("DELETE FROM blobs WHERE hash NOT IN (SELECT blob_hash FROM attachments)"); err != nil {
+		return fmt.Errorf("delete unreferenced blobs: %w", err)
+	}
+	return nil
+}
+
+// placeholders returns n comma-separated query parameters
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
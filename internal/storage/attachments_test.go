@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addTestMessage(t *testing.T, store *ConversationStore, conversationID string) *Message {
+	t.Helper()
+	msg := &Message{ConversationID: conversationID, Role: "user", Content: "see attached", Timestamp: time.Now()}
+	require.NoError(t, store.AddMessage(msg))
+	return msg
+}
+
+func TestAddAttachment(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+	_, err := store.CreateConversation("conv", "Files")
+	require.NoError(t, err)
+	msg := addTestMessage(t, store, "conv")
+
+	notes, err := store.AddAttachment(msg.ID, "/home/me/notes.md", "", []byte("# Notes"))
+	require.NoError(t, err)
+	assert.Equal(t, "notes.md", notes.Name)
+	assert.Equal(t, "conv", notes.ConversationID)
+	assert.Contains(t, notes.MediaType, "text/markdown")
+	assert.Equal(t, int64(7), notes.Size)
+
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	image, err := store.AddAttachment(msg.ID, "screenshot", "", png)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", image.MediaType, "Detected from content without an extension")
+	assert.True(t, image.IsImage())
+
+	data, err := store.ReadAttachment(notes.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes", string(data))
+
+	_, err = store.AddAttachment(999, "missing.txt", "", []byte("x"))
+	assert.Error(t, err)
+	_, err = store.ReadAttachment(999)
+	assert.Error(t, err)
+}
+
+func TestListAttachments(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+	for _, id := range []string{"a", "b"} {
+		_, err := store.CreateConversation(id, id)
+		require.NoError(t, err)
+	}
+	first := addTestMessage(t, store, "a")
+	second := addTestMessage(t, store, "b")
+	_, err := store.AddAttachment(first.ID, "one.txt", "", []byte("one"))
+	require.NoError(t, err)
+	_, err = store.AddAttachment(second.ID, "two.txt", "", []byte("two"))
+	require.NoError(t, err)
+
+	all, err := store.ListAttachments(AttachmentFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "one.txt", all[0].Name)
+
+	byConversation, err := store.ListAttachments(AttachmentFilter{ConversationIDs: []string{"b"}})
+	require.NoError(t, err)
+	require.Len(t, byConversation, 1)
+	assert.Equal(t, "two.txt", byConversation[0].Name)
+
+	byMessage, err := store.ListAttachments(AttachmentFilter{MessageIDs: []int64{first.ID}})
+	require.NoError(t, err)
+	require.Len(t, byMessage, 1)
+	assert.Equal(t, "one.txt", byMessage[0].Name)
+}
+
+func TestAttachments_SharedDataIsStoredOnce(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+	for _, id := range []string{"a", "b"} {
+		_, err := store.CreateConversation(id, id)
+		require.NoError(t, err)
+	}
+	first := addTestMessage(t, store, "a")
+	second := addTestMessage(t, store, "b")
+	_, err := store.AddAttachment(first.ID, "report.txt", "", []byte("quarterly numbers"))
+	require.NoError(t, err)
+	shared, err := store.AddAttachment(second.ID, "copy.txt", "", []byte("quarterly numbers"))
+	require.NoError(t, err)
+
+	countBlobs := func() int {
+		var n int
+		require.NoError(t, store.db.QueryRow("SELECT COUNT(*) FROM blobs").Scan(&n))
+		return n
+	}
+	assert.Equal(t, 1, countBlobs())
+
+	// The data stays while another conversation still refers to it
+	require.NoError(t, store.DeleteConversation("a"))
+	assert.Equal(t, 1, countBlobs())
+	data, err := store.ReadAttachment(shared.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "quarterly numbers", string(data))
+
+	result := &PruneResult{}
+	require.NoError(t, store.deleteForRetention("b", result))
+	assert.Equal(t, 0, countBlobs())
+	remaining, err := store.ListAttachments(AttachmentFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ToolExecution is one entry in the tool audit log. Only a hash of the
+// arguments is kept, so the log can show repeated calls without storing what
+// was sent.
+type ToolExecution struct {
+	ID            int64         `json:"id"`
+	Server        string        `json:"server"`
+	Tool          string        `json:"tool"`
+	ArgumentsHash string        `json:"arguments_hash"`
+	Duration      time.Duration `json:"duration"`
+	Success       bool          `json:"success"`
+	ResultSize    int           `json:"result_size"` // bytes of content returned
+	Error         string        `json:"error,omitempty"`
+	ExecutedAt    time.Time     `json:"executed_at"`
+}
+
+// ToolExecutionFilter narrows ListToolExecutions; zero values match everything
+type ToolExecutionFilter struct {
+	Server     string
+	Tool       string
+	FailedOnly bool
+	Since      time.Time
+	Limit      int // most recent executions returned; 0 returns all
+}
+
+// HashArguments returns the SHA-256 of args encoded as JSON. Object keys are
+// sorted when encoding, so equal arguments always hash the same.
+func HashArguments(args map[string]interface{}) string {
+	if len(args) == 0 {
+		args = map[string]interface{}{}
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		// Arguments came from decoded JSON, so this only happens for values
+		// built in code; hash their printed form instead
+		data = []byte(fmt.Sprintf("%v", args))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// RecordToolExecution adds exec to the audit log, setting its ID and, when
+// unset, its execution time
+func (s *ConversationStore) RecordToolExecution(exec *ToolExecution) error {
+	if exec.ExecutedAt.IsZero() {
+		exec.ExecutedAt = time.Now()
+	}
+	result, err := s.stmts.Exec(`
+		INSERT INTO tool_executions (server, tool, arguments_hash, duration_ms, success, result_size, error, executed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, exec.Server, exec.Tool, exec.ArgumentsHash, exec.Duration.Milliseconds(), exec.Success, exec.ResultSize, exec.Error, exec.ExecutedAt)
+	if err != nil {
+		return fmt.Errorf("insert tool execution: %w", err)
+	}
+	exec.ID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get tool execution ID: %w", err)
+	}
+	return nil
+}
+
+// ListToolExecutions returns the audit log entries matching filter, most recent first
+func (s *ConversationStore) ListToolExecutions(filter ToolExecutionFilter) ([]*ToolExecution, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Server != "" {
+		conditions = append(conditions, "server = ?")
+		args = append(args, filter.Server)
+	}
+	if filter.Tool != "" {
+		conditions = append(conditions, "tool = ?")
+		args = append(args, filter.Tool)
+	}
+	if filter.FailedOnly {
+		conditions = append(conditions, "success = 0")
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "executed_at >= ?")
+		args = append(args, filter.Since)
+	}
+
+	query := `
+		SELECT id, server, tool, arguments_hash, duration_ms, success, result_size, error, executed_at
+		FROM tool_executions`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	query += "\n\t\tORDER BY executed_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query tool executions: %w", err)
+	}
+	defer rows.Close()
+
+	var executions []*ToolExecution
+	for rows.Next() {
+		exec := &ToolExecution{}
+		var durationMS int64
+		if err := rows.Scan(&exec.ID, &exec.Server, &exec.Tool, &exec.ArgumentsHash, &durationMS,
+			&exec.Success, &exec.ResultSize, &exec.Error, &exec.ExecutedAt); err != nil {
+			return nil, fmt.Errorf("scan tool execution: %w", err)
+		}
+		exec.Duration = time.Duration(durationMS) * time.Millisecond
+		executions = append(executions, exec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tool executions: %w", err)
+	}
+	return executions, nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashArguments(t *testing.T) {
+	a := HashArguments(map[string]interface{}{"query": "go", "limit": 5})
+	b := HashArguments(map[string]interface{}{"limit": 5, "query": "go"})
+	assert.Equal(t, a, b, "Key order does not change the hash")
+	assert.Len(t, a, 64)
+	assert.NotEqual(t, a, HashArguments(map[string]interface{}{"query": "rust", "limit": 5}))
+	assert.Equal(t, HashArguments(nil), HashArguments(map[string]interface{}{}))
+}
+
+func TestRecordAndListToolExecutions(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	start := time.Now().Add(-time.Hour)
+	executions := []*ToolExecution{
+		{Server: "memory", Tool: "search", ArgumentsHash: "a", Duration: 120 * time.Millisecond, Success: true, ResultSize: 42, ExecutedAt: start},
+		{Server: "files", Tool: "read_file", ArgumentsHash: "b", Duration: 2 * time.Second, Error: "permission denied", ExecutedAt: start.Add(time.Minute)},
+		{Server: "memory", Tool: "store_memory", ArgumentsHash: "c", Duration: 80 * time.Millisecond, Success: true, ExecutedAt: start.Add(2 * time.Minute)},
+	}
+	for _, exec := range executions {
+		require.NoError(t, store.RecordToolExecution(exec))
+		assert.NotZero(t, exec.ID)
+	}
+
+	all, err := store.ListToolExecutions(ToolExecutionFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "store_memory", all[0].Tool, "Most recent first")
+	assert.Equal(t, "search", all[2].Tool)
+	assert.Equal(t, 120*time.Millisecond, all[2].Duration)
+	assert.Equal(t, 42, all[2].ResultSize)
+	assert.True(t, all[2].Success)
+
+	failed, err := store.ListToolExecutions(ToolExecutionFilter{FailedOnly: true})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "permission denied", failed[0].Error)
+
+	memory, err := store.ListToolExecutions(ToolExecutionFilter{Server: "memory", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, memory, 1)
+	assert.Equal(t, "store_memory", memory[0].Tool)
+
+	search, err := store.ListToolExecutions(ToolExecutionFilter{Tool: "search"})
+	require.NoError(t, err)
+	assert.Len(t, search, 1)
+
+	recent, err := store.ListToolExecutions(ToolExecutionFilter{Since: start.Add(30 * time.Second)})
+	require.NoError(t, err)
+	assert.Len(t, recent, 2)
+}
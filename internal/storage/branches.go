@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// conversationColumns selects a conversation row aliased c, joined to its
+// parent message as pm
+const conversationColumns = `c.id, c.title, c.created_at, c.updated_at, c.message_count, c.total_tokens,
+		c.summary, c.mode, c.parent_message_id, pm.conversation_id`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanConversation reads a row selected with conversationColumns
+func scanConversation(row rowScanner) (*Conversation, error) {
+	var conv Conversation
+	var parentMessageID sql.NullInt64
+	var parentConversationID sql.NullString
+	if err := row.Scan(
+		&conv.ID, &conv.Title, &conv.CreatedAt, &conv.UpdatedAt,
+		&conv.MessageCount, &conv.TotalTokens,
+		&conv.Summary, &conv.Mode, &parentMessageID, &parentConversationID,
+	); err != nil {
+		return nil, err
+	}
+	conv.ParentMessageID = parentMessageID.Int64
+	conv.ParentConversationID = parentConversationID.String
+	return &conv, nil
+}
+
+// scanConversations reads every row selected with conversationColumns
+func scanConversations(rows *sql.Rows) ([]*Conversation, error) {
+	var conversations []*Conversation
+	for rows.Next() {
+		conv, err := scanConversation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan conversation: %w", err)
+		}
+		conversations = append(conversations, conv)
+	}
+	return conversations, rows.Err()
+}
+
+// CreateBranch creates a conversation that continues from parentMessageID,
+// which belongs to another conversation. The branch holds only its own
+// messages; GetThread includes those it inherits.
+func (s *ConversationStore) CreateBranch(id, title string, parentMessageID int64) (*Conversation, error) {
+	var parentConversationID string
+	if err := s.db.QueryRow(
+		"SELECT conversation_id FROM messages WHERE id = ?", parentMessageID,
+	).Scan(&parentConversationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("branch from message %d: no such message", parentMessageID)
+		}
+		return nil, fmt.Errorf("query parent message: %w", err)
+	}
+
+	now := time.Now()
+	conv := &Conversation{
+		ID:                   id,
+		Title:                title,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+		ParentMessageID:      parentMessageID,
+		ParentConversationID: parentConversationID,
+	}
+
+	query := `
+		INSERT INTO conversations (id, title, created_at, updated_at, parent_message_id)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, conv.ID, conv.Title, conv.CreatedAt, conv.UpdatedAt, conv.ParentMessageID); err != nil {
+		return nil, fmt.Errorf("insert conversation: %w", err)
+	}
+
+	return conv, nil
+}
+
+// ListBranches returns the conversations that branch from a message in
+// conversationID, oldest first
+func (s *ConversationStore) ListBranches(conversationID string) ([]*Conversation, error) {
+	query := `
+		SELECT ` + conversationColumns + `
+		FROM conversations c
+		JOIN messages pm ON pm.id = c.parent_message_id
+		WHERE pm.conversation_id = ?
+		ORDER BY c.created_at ASC
+	`
+
+	rows, err := s.db.Query(query, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("query branches: %w", err)
+	}
+	defer rows.Close()
+
+	return scanConversations(rows)
+}
+
+// GetThread returns every message leading up to and including the messages
+// of conversationID: those inherited from the conversations it branched from,
+// up to each branch point, followed by its own.
+func (s *ConversationStore) GetThread(conversationID string) ([]*Message, error) {
+	thread, err := s.GetMessages(conversationID, -1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	conv, err := s.GetConversation(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := map[string]bool{conversationID: true}
+	for conv != nil && conv.ParentConversationID != "" && !visited[conv.ParentConversationID] {
+		visited[conv.ParentConversationID] = true
+
+		inherited, err := s.GetMessages(conv.ParentConversationID, -1, 0)
+		if err != nil {
+			return nil, err
+		}
+		var prefix []*Message
+		for _, msg := range inherited {
+			// IDs increase as messages are added, so they order a conversation
+			if msg.ID <= conv.ParentMessageID {
+				prefix = append(prefix, msg)
+			}
+		}
+		thread = append(prefix, thread...)
+
+		if conv, err = s.GetConversation(conv.ParentConversationID); err != nil {
+			return nil, err
+		}
+	}
+
+	return thread, nil
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addMessages(t *testing.T, store *ConversationStore, conversationID string, contents ...string) []*Message {
+	t.Helper()
+	var messages []*Message
+	for _, content := range contents {
+		msg := &Message{ConversationID: conversationID, Role: "user", Content: content, Timestamp: time.Now()}
+		require.NoError(t, store.AddMessage(msg))
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func threadContents(t *testing.T, store *ConversationStore, conversationID string) []string {
+	t.Helper()
+	thread, err := store.GetThread(conversationID)
+	require.NoError(t, err)
+	var contents []string
+	for _, msg := range thread {
+		contents = append(contents, msg.Content)
+	}
+	return contents
+}
+
+func TestConversationBranches(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	_, err := store.CreateConversation("root", "Root")
+	require.NoError(t, err)
+	root := addMessages(t, store, "root", "a", "b", "c")
+
+	branch, err := store.CreateBranch("branch", "Branch", root[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "root", branch.ParentConversationID)
+	addMessages(t, store, "branch", "c2")
+
+	_, err = store.CreateBranch("nested", "Nested", root[0].ID)
+	require.NoError(t, err)
+	addMessages(t, store, "nested", "b2", "c3")
+
+	_, err = store.CreateBranch("twice", "Twice", mustLastMessage(t, store, "branch").ID)
+	require.NoError(t, err)
+	addMessages(t, store, "twice", "d")
+
+	t.Run("thread includes inherited messages", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b", "c"}, threadContents(t, store, "root"))
+		assert.Equal(t, []string{"a", "b", "c2"}, threadContents(t, store, "branch"))
+		assert.Equal(t, []string{"a", "b2", "c3"}, threadContents(t, store, "nested"))
+		assert.Equal(t, []string{"a", "b", "c2", "d"}, threadContents(t, store, "twice"))
+	})
+
+	t.Run("branches are listed under their parent", func(t *testing.T) {
+		branches, err := store.ListBranches("root")
+		require.NoError(t, err)
+		require.Len(t, branches, 2)
+		assert.Equal(t, "branch", branches[0].ID)
+		assert.Equal(t, "nested", branches[1].ID)
+
+		branches, err = store.ListBranches("branch")
+		require.NoError(t, err)
+		require.Len(t, branches, 1)
+		assert.Equal(t, "twice", branches[0].ID)
+	})
+
+	t.Run("conversations report their parent", func(t *testing.T) {
+		conv, err := store.GetConversation("twice")
+		require.NoError(t, err)
+		assert.Equal(t, "branch", conv.ParentConversationID)
+
+		conversations, err := store.ListConversations(10, 0)
+		require.NoError(t, err)
+		parents := make(map[string]string)
+		for _, conv := range conversations {
+			parents[conv.ID] = conv.ParentConversationID
+		}
+		assert.Equal(t, map[string]string{"root": "", "branch": "root", "nested": "root", "twice": "branch"}, parents)
+	})
+
+	t.Run("deleting the parent detaches its branches", func(t *testing.T) {
+		require.NoError(t, store.DeleteConversation("root"))
+		conv, err := store.GetConversation("branch")
+		require.NoError(t, err)
+		assert.Zero(t, conv.ParentMessageID)
+		assert.Equal(t, []string{"c2"}, threadContents(t, store, "branch"))
+	})
+}
+
+func mustLastMessage(t *testing.T, store *ConversationStore, conversationID string) *Message {
+	t.Helper()
+	messages, err := store.GetMessages(conversationID, -1, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, messages)
+	return messages[len(messages)-1]
+}
+
+func TestCreateBranch_UnknownMessage(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	_, err := store.CreateBranch("branch", "Branch", 42)
+	assert.ErrorContains(t, err, "no such message")
+}
+
+func TestMigrateSchema_ExistingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+
+	// The tables as they were before branching, summaries, tags, and pins
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE conversations (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		message_count INTEGER NOT NULL DEFAULT 0,
+		total_tokens INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		conversation_id TEXT NOT NULL,
+		role TEXT NOT NULL CHECK (role IN ('user', 'assistant', 'tool')),
+		content TEXT NOT NULL,
+		tool_call TEXT,
+		tool_result TEXT,
+		timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		token_count INTEGER NOT NULL DEFAULT 0,
+		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE
+	)`)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO conversations (id, title) VALUES ('old', 'Old')")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err := NewConversationStore(dbPath)
+	require.NoError(t, err)
+	defer store.Close()
+
+	conv, err := store.GetConversation("old")
+	require.NoError(t, err)
+	require.NotNil(t, conv)
+	assert.Zero(t, conv.ParentMessageID)
+	assert.Empty(t, conv.Summary)
+
+	message := addMessages(t, store, "old", "hello")[0]
+	_, err = store.CreateBranch("new", "New", message.ID)
+	require.NoError(t, err)
+	require.NoError(t, store.UpdateConversationSummary("old", "Greeting", "The user said hello."))
+	_, err = store.TagMessage(message.ID, "greeting")
+	require.NoError(t, err)
+	require.NoError(t, store.SetMessagePinned(message.ID, true))
+}
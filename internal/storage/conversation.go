@@ -19,6 +19,8 @@ type Message struct {
 	ToolResult    *ToolResult `json:"tool_result,omitempty" db:"tool_result"`
 	Timestamp     time.Time `json:"timestamp" db:"timestamp"`
 	TokenCount    int       `json:"token_count" db:"token_count"`
+	Tags          []string  `json:"tags,omitempty" db:"tags"`
+	Pinned        bool      `json:"pinned,omitempty" db:"pinned"`
 }
 
 // ToolCall represents a tool call request
@@ -43,29 +45,45 @@ type Conversation struct {
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 	MessageCount int      `json:"message_count" db:"message_count"`
 	TotalTokens  int      `json:"total_tokens" db:"total_tokens"`
+	Summary      string   `json:"summary,omitempty" db:"summary"` // rolling summary used to compact context
+	Mode         string   `json:"mode,omitempty" db:"mode"`       // session mode the conversation was left in; "" for the default
+	// ParentMessageID is the message a branch continues from; zero for a
+	// conversation that starts from scratch
+	ParentMessageID      int64  `json:"parent_message_id,omitempty" db:"parent_message_id"`
+	ParentConversationID string `json:"parent_conversation_id,omitempty"` // conversation holding ParentMessageID
 }
 
 // ConversationStore manages conversation storage
 type ConversationStore struct {
-	db *sql.DB
+	db    *sql.DB
+	stmts *stmtCache // prepared statements for frequent queries
+	fts   bool       // messages_fts is available for full-text search
 }
 
-// NewConversationStore creates a new conversation store
+// NewConversationStore creates a new conversation store. File databases use
+// WAL mode so the TUI and background jobs can read and write at the same time.
 func NewConversationStore(dbPath string) (*ConversationStore, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open("sqlite3", dataSourceName(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
+	configurePool(db, dbPath)
 	
-	// Enable foreign key constraints
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		return nil, fmt.Errorf("enable foreign keys: %w", err)
+	// Connect now so a bad path or locked database fails here
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to database: %w", err)
 	}
 	
-	store := &ConversationStore{db: db}
+	store := &ConversationStore{db: db, stmts: newStmtCache(db)}
 	if err := store.initSchema(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("initialize schema: %w", err)
 	}
+	if err := store.initSearchIndex(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initialize search index: %w", err)
+	}
 	
 	return store, nil
 }
@@ -79,7 +97,15 @@ func (s *ConversationStore) initSchema() error {
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		message_count INTEGER NOT NULL DEFAULT 0,
-		total_tokens INTEGER NOT NULL DEFAULT 0
+		total_tokens INTEGER NOT NULL DEFAULT 0,
+		parent_message_id INTEGER REFERENCES messages(id) ON DELETE SET NULL,
+		summary TEXT NOT NULL DEFAULT '', -- rolling summary written by the model
+		prompt_tokens INTEGER NOT NULL DEFAULT 0, -- tokens sent to the model
+		completion_tokens INTEGER NOT NULL DEFAULT 0, -- tokens the model generated
+		model_calls INTEGER NOT NULL DEFAULT 0,
+		model_latency_ms INTEGER NOT NULL DEFAULT 0, -- total time waiting for the model
+		tool_calls INTEGER NOT NULL DEFAULT 0,
+		mode TEXT NOT NULL DEFAULT '' -- session mode, such as analysis
 	);
 	
 	CREATE TABLE IF NOT EXISTS messages (
@@ -91,18 +117,158 @@ func (s *ConversationStore) initSchema() error {
 		tool_result TEXT, -- JSON blob for tool results
 		timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		token_count INTEGER NOT NULL DEFAULT 0,
+		tags TEXT NOT NULL DEFAULT '', -- comma-separated, with a comma at each end
+		pinned INTEGER NOT NULL DEFAULT 0,
 		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE
 	);
 	
 	CREATE INDEX IF NOT EXISTS idx_messages_conversation_id ON messages(conversation_id);
 	CREATE INDEX IF NOT EXISTS idx_messages_timestamp ON messages(timestamp);
 	CREATE INDEX IF NOT EXISTS idx_conversations_updated_at ON conversations(updated_at);
+	
+	CREATE TABLE IF NOT EXISTS prompt_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		prompt TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	
+	CREATE TABLE IF NOT EXISTS message_embeddings (
+		message_id INTEGER PRIMARY KEY,
+		model TEXT NOT NULL, -- embedding model that produced the vector
+		vector BLOB NOT NULL, -- little-endian float32 values
+		FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+	);
+	
+	CREATE TABLE IF NOT EXISTS store_metadata (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	
+	CREATE TABLE IF NOT EXISTS tool_executions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		server TEXT NOT NULL,
+		tool TEXT NOT NULL,
+		arguments_hash TEXT NOT NULL, -- SHA-256 of the JSON arguments, not the arguments themselves
+		duration_ms INTEGER NOT NULL,
+		success INTEGER NOT NULL,
+		result_size INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		executed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	
+	CREATE INDEX IF NOT EXISTS idx_tool_executions_executed_at ON tool_executions(executed_at);
+	
+	CREATE TABLE IF NOT EXISTS memories (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		topic TEXT NOT NULL,
+		content TEXT NOT NULL,
+		importance INTEGER NOT NULL DEFAULT 5, -- 1 (trivia) to 10 (essential)
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		recalled_at DATETIME, -- last time the memory was sent to the model
+		recall_count INTEGER NOT NULL DEFAULT 0
+	);
+	
+	CREATE INDEX IF NOT EXISTS idx_memories_topic ON memories(topic);
+	
+	CREATE TABLE IF NOT EXISTS memory_embeddings (
+		memory_id INTEGER PRIMARY KEY,
+		model TEXT NOT NULL, -- embedding model that produced the vector
+		vector BLOB NOT NULL, -- little-endian float32 values
+		FOREIGN KEY (memory_id) REFERENCES memories(id) ON DELETE CASCADE
+	);
+	
+	CREATE TABLE IF NOT EXISTS blobs (
+		hash TEXT PRIMARY KEY, -- SHA-256 of data, so identical files are stored once
+		data BLOB NOT NULL,
+		size INTEGER NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	
+	CREATE TABLE IF NOT EXISTS attachments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		message_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		media_type TEXT NOT NULL,
+		blob_hash TEXT NOT NULL REFERENCES blobs(hash),
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+	);
+	
+	CREATE INDEX IF NOT EXISTS idx_attachments_message_id ON attachments(message_id);
+	
+	CREATE TABLE IF NOT EXISTS tool_usage (
+		conversation_id TEXT NOT NULL,
+		tool TEXT NOT NULL,
+		calls INTEGER NOT NULL DEFAULT 0,
+		prompt_tokens INTEGER NOT NULL DEFAULT 0, -- share of the tokens of the model calls that called the tool
+		completion_tokens INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (conversation_id, tool),
+		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE
+	);
+	
+	CREATE TABLE IF NOT EXISTS project_chunks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		root TEXT NOT NULL, -- absolute path of the indexed directory
+		path TEXT NOT NULL, -- file path relative to root, with forward slashes
+		file_hash TEXT NOT NULL, -- SHA-256 of the whole file, so unchanged files are skipped
+		start_line INTEGER NOT NULL,
+		end_line INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		model TEXT NOT NULL, -- embedding model that produced the vector
+		vector BLOB NOT NULL -- little-endian float32 values
+	);
+	
+	CREATE INDEX IF NOT EXISTS idx_project_chunks_root_path ON project_chunks(root, path);
 	`
 	
 	if _, err := s.db.Exec(schema); err != nil {
 		return fmt.Errorf("create schema: %w", err)
 	}
 	
+	return s.migrateSchema()
+}
+
+// migrateSchema adds the columns introduced since a database was created
+func (s *ConversationStore) migrateSchema() error {
+	columns := []struct{ table, name, definition string }{
+		{"conversations", "parent_message_id", "INTEGER REFERENCES messages(id) ON DELETE SET NULL"},
+		{"conversations", "summary", "TEXT NOT NULL DEFAULT ''"},
+		{"messages", "tags", "TEXT NOT NULL DEFAULT ''"},
+		{"messages", "pinned", "INTEGER NOT NULL DEFAULT 0"},
+		{"conversations", "prompt_tokens", "INTEGER NOT NULL DEFAULT 0"},
+		{"conversations", "completion_tokens", "INTEGER NOT NULL DEFAULT 0"},
+		{"conversations", "model_calls", "INTEGER NOT NULL DEFAULT 0"},
+		{"conversations", "model_latency_ms", "INTEGER NOT NULL DEFAULT 0"},
+		{"conversations", "tool_calls", "INTEGER NOT NULL DEFAULT 0"},
+		{"conversations", "mode", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, column := range columns {
+		var exists int
+		if err := s.db.QueryRow(
+			"SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", column.table, column.name,
+		).Scan(&exists); err != nil {
+			return fmt.Errorf("inspect %s table: %w", column.table, err)
+		}
+		if exists > 0 {
+			continue
+		}
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", column.table, column.name, column.definition)
+		if _, err := s.db.Exec(query); err != nil {
+			return fmt.Errorf("add %s.%s column: %w", column.table, column.name, err)
+		}
+	}
+
+	if _, err := s.db.Exec(
+		"CREATE INDEX IF NOT EXISTS idx_conversations_parent_message_id ON conversations(parent_message_id)",
+	); err != nil {
+		return fmt.Errorf("create parent_message_id index: %w", err)
+	}
+	if _, err := s.db.Exec(
+		"CREATE INDEX IF NOT EXISTS idx_messages_pinned ON messages(pinned) WHERE pinned = 1",
+	); err != nil {
+		return fmt.Errorf("create pinned index: %w", err)
+	}
 	return nil
 }
 
@@ -131,31 +297,30 @@ func (s *ConversationStore) CreateConversation(id, title string) (*Conversation,
 // GetConversation retrieves a conversation by ID
 func (s *ConversationStore) GetConversation(id string) (*Conversation, error) {
 	query := `
-		SELECT id, title, created_at, updated_at, message_count, total_tokens
-		FROM conversations
-		WHERE id = ?
+		SELECT ` + conversationColumns + `
+		FROM conversations c
+		LEFT JOIN messages pm ON pm.id = c.parent_message_id
+		WHERE c.id = ?
 	`
 	
-	var conv Conversation
-	if err := s.db.QueryRow(query, id).Scan(
-		&conv.ID, &conv.Title, &conv.CreatedAt, &conv.UpdatedAt,
-		&conv.MessageCount, &conv.TotalTokens,
-	); err != nil {
+	conv, err := scanConversation(s.stmts.QueryRow(query, id))
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("query conversation: %w", err)
 	}
 	
-	return &conv, nil
+	return conv, nil
 }
 
 // ListConversations returns all conversations ordered by updated time
 func (s *ConversationStore) ListConversations(limit, offset int) ([]*Conversation, error) {
 	query := `
-		SELECT id, title, created_at, updated_at, message_count, total_tokens
-		FROM conversations
-		ORDER BY updated_at DESC
+		SELECT ` + conversationColumns + `
+		FROM conversations c
+		LEFT JOIN messages pm ON pm.id = c.parent_message_id
+		ORDER BY c.updated_at DESC
 		LIMIT ? OFFSET ?
 	`
 	
@@ -165,23 +330,30 @@ func (s *ConversationStore) ListConversations(limit, offset int) ([]*Conversatio
 	}
 	defer rows.Close()
 	
-	var conversations []*Conversation
-	for rows.Next() {
-		var conv Conversation
-		if err := rows.Scan(
-			&conv.ID, &conv.Title, &conv.CreatedAt, &conv.UpdatedAt,
-			&conv.MessageCount, &conv.TotalTokens,
-		); err != nil {
-			return nil, fmt.Errorf("scan conversation: %w", err)
-		}
-		conversations = append(conversations, &conv)
-	}
-	
-	return conversations, nil
+	return scanConversations(rows)
 }
 
 // AddMessage adds a message to a conversation
 func (s *ConversationStore) AddMessage(msg *Message) error {
+	if err := insertMessage(s.stmts, msg); err != nil {
+		return err
+	}
+	
+	// Update conversation stats
+	if err := s.updateConversationStats(msg.ConversationID); err != nil {
+		return fmt.Errorf("update conversation stats: %w", err)
+	}
+	
+	return nil
+}
+
+// execer is implemented by *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertMessage inserts msg and sets its ID, leaving the conversation stats alone
+func insertMessage(db execer, msg *Message) error {
 	// Serialize tool call and result to JSON
 	var toolCallJSON, toolResultJSON sql.NullString
 	
@@ -203,13 +375,14 @@ func (s *ConversationStore) AddMessage(msg *Message) error {
 	
 	// Insert message
 	query := `
-		INSERT INTO messages (conversation_id, role, content, tool_call, tool_result, timestamp, token_count)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO messages (conversation_id, role, content, tool_call, tool_result, timestamp, token_count, tags, pinned)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	
-	result, err := s.db.Exec(query,
+	result, err := db.Exec(query,
 		msg.ConversationID, msg.Role, msg.Content,
 		toolCallJSON, toolResultJSON, msg.Timestamp, msg.TokenCount,
+		encodeTags(msg.Tags), msg.Pinned,
 	)
 	if err != nil {
 		return fmt.Errorf("insert message: %w", err)
@@ -222,25 +395,20 @@ func (s *ConversationStore) AddMessage(msg *Message) error {
 	}
 	msg.ID = id
 	
-	// Update conversation stats
-	if err := s.updateConversationStats(msg.ConversationID); err != nil {
-		return fmt.Errorf("update conversation stats: %w", err)
-	}
-	
 	return nil
 }
 
 // GetMessages retrieves messages for a conversation
 func (s *ConversationStore) GetMessages(conversationID string, limit, offset int) ([]*Message, error) {
 	query := `
-		SELECT id, conversation_id, role, content, tool_call, tool_result, timestamp, token_count
+		SELECT id, conversation_id, role, content, tool_call, tool_result, timestamp, token_count, tags, pinned
 		FROM messages
 		WHERE conversation_id = ?
 		ORDER BY timestamp ASC
 		LIMIT ? OFFSET ?
 	`
 	
-	rows, err := s.db.Query(query, conversationID, limit, offset)
+	rows, err := s.stmts.Query(query, conversationID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("query messages: %w", err)
 	}
@@ -250,13 +418,16 @@ func (s *ConversationStore) GetMessages(conversationID string, limit, offset int
 	for rows.Next() {
 		var msg Message
 		var toolCallJSON, toolResultJSON sql.NullString
+		var tags string
 		
 		if err := rows.Scan(
 			&msg.ID, &msg.ConversationID, &msg.Role, &msg.Content,
 			&toolCallJSON, &toolResultJSON, &msg.Timestamp, &msg.TokenCount,
+			&tags, &msg.Pinned,
 		); err != nil {
 			return nil, fmt.Errorf("scan message: %w", err)
 		}
+		msg.Tags = decodeTags(tags)
 		
 		// Deserialize tool call and result
 		if toolCallJSON.Valid {
@@ -281,13 +452,14 @@ func (s *ConversationStore) GetMessages(conversationID string, limit, offset int
 	return messages, nil
 }
 
-// DeleteConversation deletes a conversation and all its messages
+// DeleteConversation deletes a conversation and all its messages, along with
+// attachment data no other message shares
 func (s *ConversationStore) DeleteConversation(id string) error {
 	query := "DELETE FROM conversations WHERE id = ?"
 	if _, err := s.db.Exec(query, id); err != nil {
 		return fmt.Errorf("delete conversation: %w", err)
 	}
-	return nil
+	return deleteUnreferencedBlobs(s.db)
 }
 
 // UpdateConversationTitle updates the title of a conversation
@@ -299,17 +471,41 @@ func (s *ConversationStore) UpdateConversationTitle(id, title string) error {
 	return nil
 }
 
-// SearchMessages searches for messages containing the given text
+// UpdateConversationSummary replaces the title and rolling summary of a conversation
+func (s *ConversationStore) UpdateConversationSummary(id, title, summary string) error {
+	query := "UPDATE conversations SET title = ?, summary = ?, updated_at = ? WHERE id = ?"
+	if _, err := s.db.Exec(query, title, summary, time.Now(), id); err != nil {
+		return fmt.Errorf("update conversation summary: %w", err)
+	}
+	return nil
+}
+
+// SetConversationMode records the session mode a conversation is in
+func (s *ConversationStore) SetConversationMode(id, mode string) error {
+	query := "UPDATE conversations SET mode = ? WHERE id = ?"
+	if _, err := s.db.Exec(query, mode, id); err != nil {
+		return fmt.Errorf("update conversation mode: %w", err)
+	}
+	return nil
+}
+
+// SearchMessages searches for messages containing the given text. With the
+// full-text index each word matches as a prefix; otherwise the text must
+// appear as written.
 func (s *ConversationStore) SearchMessages(query string, limit int) ([]*Message, error) {
+	match, arg := "content LIKE ?", "%"+query+"%"
+	if terms := ftsQuery(query); s.fts && terms != "" {
+		match, arg = "id IN (SELECT rowid FROM messages_fts WHERE messages_fts MATCH ?)", terms
+	}
 	sqlQuery := `
 		SELECT id, conversation_id, role, content, tool_call, tool_result, timestamp, token_count
 		FROM messages
-		WHERE content LIKE ?
+		WHERE ` + match + `
 		ORDER BY timestamp DESC
 		LIMIT ?
 	`
 	
-	rows, err := s.db.Query(sqlQuery, "%"+query+"%", limit)
+	rows, err := s.db.Query(sqlQuery, arg, limit)
 	if err != nil {
 		return nil, fmt.Errorf("search messages: %w", err)
 	}
@@ -364,7 +560,7 @@ func (s *ConversationStore) updateConversationStats(conversationID string) error
 		WHERE id = ?
 	`
 	
-	_, err := s.db.Exec(query, conversationID, conversationID, time.Now(), conversationID)
+	_, err := s.stmts.Exec(query, conversationID, conversationID, time.Now(), conversationID)
 	if err != nil {
 		return fmt.Errorf("update conversation stats: %w", err)
 	}
@@ -445,5 +641,9 @@ func (s *ConversationStore) GetRecentConversationContext(conversationID string,
 
 // Close closes the database connection
 func (s *ConversationStore) Close() error {
+	if err := s.stmts.Close(); err != nil {
+		s.db.Close()
+		return fmt.Errorf("close statements: %w", err)
+	}
 	return s.db.Close()
 }
\ No newline at end of file
@@ -525,4 +525,33 @@ func TestConcurrentAccess(t *testing.T) {
 	messages, err := store.GetMessages(conv.ID, 20, 0)
 	assert.NoError(t, err)
 	assert.Len(t, messages, 10)
-}
\ No newline at end of file
+}
+func TestUpdateConversationSummary(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	_, err := store.CreateConversation("conv", "how do I rotate postgres logs")
+	require.NoError(t, err)
+	require.NoError(t, store.UpdateConversationSummary("conv", "Postgres log rotation", "The user set up logrotate for Postgres."))
+
+	conv, err := store.GetConversation("conv")
+	require.NoError(t, err)
+	assert.Equal(t, "Postgres log rotation", conv.Title)
+	assert.Equal(t, "The user set up logrotate for Postgres.", conv.Summary)
+}
+
+func TestSetConversationMode(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	_, err := store.CreateConversation("conv", "why did signups drop")
+	require.NoError(t, err)
+	conv, err := store.GetConversation("conv")
+	require.NoError(t, err)
+	assert.Empty(t, conv.Mode)
+
+	require.NoError(t, store.SetConversationMode("conv", "analysis"))
+	conv, err = store.GetConversation("conv")
+	require.NoError(t, err)
+	assert.Equal(t, "analysis", conv.Mode)
+}
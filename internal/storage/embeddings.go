@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// embeddingBatchSize is how many messages are embedded per request
+	embeddingBatchSize = 32
+
+	// hybridCandidates is how many results each of text and semantic search
+	// contribute before they are merged
+	hybridCandidates = 50
+
+	// minSimilarity is the cosine similarity below which a message is not
+	// considered related to the query
+	minSimilarity = 0.5
+
+	// rrfK dampens the weight of top ranks in reciprocal rank fusion
+	rrfK = 60
+)
+
+// Embedder turns text into embedding vectors, one per input
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// SetEmbedder enables semantic search with vectors from embedder. model names
+// the embedding model so vectors from different models are never compared.
+func (sm *SearchManager) SetEmbedder(embedder Embedder, model string) {
+	sm.embedder = embedder
+	sm.embeddingModel = model
+}
+
+// IndexEmbeddings embeds the messages that have no vector from the current
+// embedding model yet and returns how many were added
+func (sm *SearchManager) IndexEmbeddings(ctx context.Context) (int, error) {
+	if sm.embedder == nil {
+		return 0, nil
+	}
+
+	indexed := 0
+	for {
+		pending, err := sm.store.messagesWithoutEmbedding(sm.embeddingModel, embeddingBatchSize)
+		if err != nil {
+			return indexed, err
+		}
+		if len(pending) == 0 {
+			return indexed, nil
+		}
+
+		texts := make([]string, len(pending))
+		for i, msg := range pending {
+			texts[i] = msg.Content
+		}
+		vectors, err := sm.embedder.Embed(ctx, texts)
+		if err != nil {
+			return indexed, fmt.Errorf("embed messages: %w", err)
+		}
+		if len(vectors) != len(pending) {
+			return indexed, fmt.Errorf("embed messages: got %d vectors for %d messages", len(vectors), len(pending))
+		}
+
+		for i, msg := range pending {
+			if err := sm.store.SaveEmbedding(msg.ID, sm.embeddingModel, vectors[i]); err != nil {
+				return indexed, err
+			}
+			indexed++
+		}
+	}
+}
+
+// SearchHybrid combines full-text and semantic search, so messages that use
+// different words for the same idea are found too. Results from both are
+// merged by reciprocal rank fusion and Rank holds the negated fused score.
+// Without an embedder it is the same as SearchRanked.
+func (sm *SearchManager) SearchHybrid(ctx context.Context, filter SearchFilter) ([]*SearchResult, error) {
+	if sm.embedder == nil || strings.TrimSpace(filter.Query) == "" {
+		return sm.SearchRanked(filter)
+	}
+
+	candidates := filter
+	candidates.Limit = max(hybridCandidates, filter.Limit+filter.Offset)
+	candidates.Offset = 0
+
+	text, err := sm.SearchRanked(candidates)
+	if err != nil {
+		return nil, err
+	}
+	semantic, err := sm.semanticSearch(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	results := fuseResults(text, semantic)
+	if filter.Offset >= len(results) {
+		return nil, nil
+	}
+	results = results[filter.Offset:]
+	if filter.Limit > 0 && len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+	return results, nil
+}
+
+// semanticSearch returns the messages whose embeddings are most similar to
+// the query, best first
+func (sm *SearchManager) semanticSearch(ctx context.Context, filter SearchFilter) ([]*SearchResult, error) {
+	vectors, err := sm.embedder.Embed(ctx, []string{filter.Query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embed query: got %d vectors", len(vectors))
+	}
+	target := vectors[0]
+
+	query := `
+		SELECT m.id, m.conversation_id, m.role, m.content, m.timestamp, e.vector
+		FROM message_embeddings e
+		JOIN messages m ON m.id = e.message_id
+		JOIN conversations c ON m.conversation_id = c.id
+		WHERE e.model = $1
+	`
+	conditions, args, _ := filterConditions(filter, []interface{}{sm.embeddingModel}, 2)
+	query += conditions
+
+	rows, err := sm.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute semantic search query: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		result := &SearchResult{Message: &Message{}}
+		var blob []byte
+		err := rows.Scan(
+			&result.Message.ID,
+			&result.Message.ConversationID,
+			&result.Message.Role,
+			&result.Message.Content,
+			&result.Message.Timestamp,
+			&blob,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan embedding: %w", err)
+		}
+		result.Similarity = cosineSimilarity(target, decodeVector(blob))
+		if result.Similarity < minSimilarity {
+			continue
+		}
+		result.Snippet = likeSnippet(result.Message.Content, "")
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over embeddings: %w", err)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Similarity > results[j].Similarity
+	})
+	if filter.Limit > 0 && len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+	return results, nil
+}
+
+// fuseResults merges ranked result lists with reciprocal rank fusion. A
+// message found by both keeps the text snippet, which marks the matched terms.
+func fuseResults(lists ...[]*SearchResult) []*SearchResult {
+	scores := make(map[int64]float64)
+	merged := make(map[int64]*SearchResult)
+	var order []*SearchResult
+
+	for _, list := range lists {
+		for rank, result := range list {
+			id := result.Message.ID
+			scores[id] += 1 / float64(rrfK+rank+1)
+			existing, ok := merged[id]
+			if !ok {
+				copied := *result
+				merged[id] = &copied
+				order = append(order, &copied)
+				continue
+			}
+			existing.Similarity = max(existing.Similarity, result.Similarity)
+		}
+	}
+
+	for _, result := range order {
+		result.Rank = -scores[result.Message.ID]
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].Rank < order[j].Rank
+	})
+	return order
+}
+
+// SaveEmbedding stores the embedding vector of a message, replacing any
+// earlier one
+func (s *ConversationStore) SaveEmbedding(messageID int64, model string, vector []float32) error {
+	query := "INSERT OR REPLACE INTO message_embeddings (message_id, model, vector) VALUES (?, ?, ?)"
+	if _, err := s.db.Exec(query, messageID, model, encodeVector(vector)); err != nil {
+		return fmt.Errorf("save embedding: %w", err)
+	}
+	return nil
+}
+
+// messagesWithoutEmbedding returns up to limit messages with content but no
+// vector from model, oldest first
+func (s *ConversationStore) messagesWithoutEmbedding(model string, limit int) ([]*Message, error) {
+	query := `
+		SELECT m.id, m.content
+		FROM messages m
+		LEFT JOIN message_embeddings e ON e.message_id = m.id AND e.model = ?
+		WHERE e.message_id IS NULL AND m.content != ''
+		ORDER BY m.id
+		LIMIT ?
+	`
+	rows, err := s.db.Query(query, model, limit)
+	if err != nil {
+		return nil, fmt.Errorf("find messages without embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		msg := &Message{}
+		if err := rows.Scan(&msg.ID, &msg.Content); err != nil {
+			return nil, fmt.Errorf("scan message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// encodeVector packs a vector as little-endian float32 values
+func encodeVector(vector []float32) []byte {
+	blob := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(blob[4*i:], math.Float32bits(value))
+	}
+	return blob
+}
+
+// decodeVector unpacks a vector stored by encodeVector
+func decodeVector(blob []byte) []float32 {
+	vector := make([]float32, len(blob)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(blob[4*i:]))
+	}
+	return vector
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// they differ in length or either is zero
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conceptEmbedder embeds text by counting words from a few concepts, treating
+// synonyms as the same dimension
+type conceptEmbedder struct {
+	calls int
+}
+
+var concepts = [][]string{
+	{"car", "automobile", "vehicle"},
+	{"weather", "rain", "sunny"},
+	{"cooking", "recipe", "bake"},
+}
+
+func (e *conceptEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, len(concepts)+1)
+		vector[len(concepts)] = 0.1 // keeps unrelated text from being a zero vector
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			for dim, synonyms := range concepts {
+				for _, synonym := range synonyms {
+					if strings.Trim(word, ".,!?") == synonym {
+						vector[dim]++
+					}
+				}
+			}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+func TestVectorEncoding(t *testing.T) {
+	vector := []float32{0.25, -1.5, 3}
+	assert.Equal(t, vector, decodeVector(encodeVector(vector)))
+
+	assert.InDelta(t, 1.0, cosineSimilarity([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0.0, cosineSimilarity([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.Zero(t, cosineSimilarity([]float32{1}, []float32{1, 2}))
+	assert.Zero(t, cosineSimilarity([]float32{0, 0}, []float32{1, 2}))
+}
+
+func TestSearchManager_IndexEmbeddings(t *testing.T) {
+	store, searchManager := setupSearchTestDB(t)
+	defer store.Close()
+
+	addSearchMessages(t, store, "My car broke down", "It looks like rain today")
+
+	count, err := searchManager.IndexEmbeddings(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, count, "nothing is indexed without an embedder")
+
+	embedder := &conceptEmbedder{}
+	searchManager.SetEmbedder(embedder, "concepts")
+	count, err = searchManager.IndexEmbeddings(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = searchManager.IndexEmbeddings(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, count, "already indexed messages are skipped")
+
+	// A different model needs its own vectors
+	searchManager.SetEmbedder(embedder, "other")
+	count, err = searchManager.IndexEmbeddings(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestSearchManager_SearchHybrid(t *testing.T) {
+	store, searchManager := setupSearchTestDB(t)
+	defer store.Close()
+
+	addSearchMessages(t, store,
+		"My car broke down on the highway",
+		"It looks like rain today",
+		"Which automobile should I buy?",
+		"Here is a recipe to bake bread",
+	)
+	searchManager.SetEmbedder(&conceptEmbedder{}, "concepts")
+	_, err := searchManager.IndexEmbeddings(context.Background())
+	require.NoError(t, err)
+
+	results, err := searchManager.SearchHybrid(context.Background(), SearchFilter{Query: "automobile"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	// Found by both text and meaning, so it ranks first and keeps the marked snippet
+	assert.Equal(t, "Which automobile should I buy?", results[0].Message.Content)
+	assert.Contains(t, results[0].Snippet, SnippetStart+"automobile"+SnippetEnd)
+	assert.Greater(t, results[0].Similarity, 0.9)
+
+	// Found only by meaning
+	assert.Equal(t, "My car broke down on the highway", results[1].Message.Content)
+	assert.Less(t, results[0].Rank, results[1].Rank)
+
+	limited, err := searchManager.SearchHybrid(context.Background(), SearchFilter{Query: "automobile", Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, limited, 1)
+	assert.Equal(t, results[1].Message.ID, limited[0].Message.ID)
+
+	filtered, err := searchManager.SearchHybrid(context.Background(), SearchFilter{Query: "automobile", ConversationID: "missing"})
+	require.NoError(t, err)
+	assert.Empty(t, filtered)
+}
+
+func TestSearchManager_SearchHybridWithoutEmbedder(t *testing.T) {
+	store, searchManager := setupSearchTestDB(t)
+	defer store.Close()
+
+	addSearchMessages(t, store, "My car broke down", "Which automobile should I buy?")
+
+	results, err := searchManager.SearchHybrid(context.Background(), SearchFilter{Query: "automobile"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Which automobile should I buy?", results[0].Message.Content)
+	assert.Zero(t, results[0].Similarity)
+}
@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExportFormat identifies the file format of a conversation export
+type ExportFormat string
+
+// Supported export formats
+const (
+	ExportMarkdown ExportFormat = "markdown"
+	ExportJSON     ExportFormat = "json"
+	ExportHTML     ExportFormat = "html"
+)
+
+// ExportFormats lists the supported export formats
+var ExportFormats = []ExportFormat{ExportMarkdown, ExportJSON, ExportHTML}
+
+// ParseExportFormat converts a user-supplied format name, such as "md", into an ExportFormat
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch strings.ToLower(name) {
+	case "markdown", "md":
+		return ExportMarkdown, nil
+	case "json":
+		return ExportJSON, nil
+	case "html", "htm":
+		return ExportHTML, nil
+	}
+	return "", fmt.Errorf("unknown export format %q (supported: markdown, json, html)", name)
+}
+
+// Extension returns the file extension for the format, including the dot
+func (f ExportFormat) Extension() string {
+	switch f {
+	case ExportMarkdown:
+		return ".md"
+	case ExportHTML:
+		return ".html"
+	}
+	return "." + string(f)
+}
+
+// Exporter writes a conversation and its messages in a particular format
+type Exporter interface {
+	Export(w io.Writer, conv *Conversation, messages []*Message) error
+}
+
+// NewExporter returns the exporter for format
+func NewExporter(format ExportFormat) (Exporter, error) {
+	switch format {
+	case ExportMarkdown:
+		return markdownExporter{}, nil
+	case ExportJSON:
+		return jsonExporter{}, nil
+	case ExportHTML:
+		return htmlExporter{}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+// DefaultExportPath returns a timestamped file name for an export in dir
+func DefaultExportPath(dir string, format ExportFormat, now time.Time) string {
+	return filepath.Join(dir, "othello-conversation-"+now.Format("20060102-150405")+format.Extension())
+}
+
+// ExportToFile writes the conversation to path in format, creating parent directories as needed
+func ExportToFile(path string, format ExportFormat, conv *Conversation, messages []*Message) error {
+	exporter, err := NewExporter(format)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create export directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create export file: %w", err)
+	}
+
+	if err := exporter.Export(file, conv, messages); err != nil {
+		file.Close()
+		return fmt.Errorf("export conversation: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close export file: %w", err)
+	}
+	return nil
+}
+
+// markdownExporter renders a conversation as Markdown
+type markdownExporter struct{}
+
+func (markdownExporter) Export(w io.Writer, conv *Conversation, messages []*Message) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", exportTitle(conv))
+	if conv != nil && !conv.CreatedAt.IsZero() {
+		fmt.Fprintf(&b, "_Started %s_\n\n", conv.CreatedAt.Format(time.RFC1123))
+	}
+
+	for _, msg := range messages {
+		fmt.Fprintf(&b, "## %s", roleTitle(msg.Role))
+		if !msg.Timestamp.IsZero() {
+			fmt.Fprintf(&b, " (%s)", msg.Timestamp.Format("15:04:05"))
+		}
+		b.WriteString("\n\n")
+
+		if msg.Content != "" {
+			b.WriteString(msg.Content)
+			b.WriteString("\n\n")
+		}
+		if msg.ToolCall != nil {
+			fmt.Fprintf(&b, "**Tool call:** `%s`\n\n", msg.ToolCall.Name)
+			if len(msg.ToolCall.Arguments) > 0 {
+				args, err := json.MarshalIndent(msg.ToolCall.Arguments, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshal tool arguments: %w", err)
+				}
+				fmt.Fprintf(&b, "```json\n%s\n```\n\n", args)
+			}
+		}
+		if msg.ToolResult != nil {
+			label := "Result"
+			if msg.ToolResult.IsError {
+				label = "Error"
+			}
+			fmt.Fprintf(&b, "**%s:**\n\n```\n%s\n```\n\n", label, msg.ToolResult.Content)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// jsonExporter writes the conversation and messages as indented JSON
+type jsonExporter struct{}
+
+func (jsonExporter) Export(w io.Writer, conv *Conversation, messages []*Message) error {
+	if messages == nil {
+		messages = []*Message{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Conversation *Conversation `json:"conversation,omitempty"`
+		Messages     []*Message    `json:"messages"`
+	}{conv, messages})
+}
+
+// htmlExporter renders a standalone HTML page
+type htmlExporter struct{}
+
+// htmlMessage is a message prepared for the HTML template
+type htmlMessage struct {
+	Role       string
+	Title      string
+	Time       string
+	Content    string
+	ToolName   string
+	ToolArgs   []string
+	ToolResult string
+	IsError    bool
+}
+
+var htmlTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 50rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+.message { border-left: 4px solid #ccc; margin: 1rem 0; padding: 0.5rem 1rem; }
+.user { border-color: #3b82f6; }
+.assistant { border-color: #10b981; }
+.tool { border-color: #f59e0b; }
+.meta { color: #666; font-size: 0.85rem; }
+.content { white-space: pre-wrap; }
+pre { background: #f5f5f5; padding: 0.5rem; overflow-x: auto; }
+.error { color: #b91c1c; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Messages}}<div class="message {{.Role}}">
+<div class="meta"><strong>{{.Title}}</strong>{{if .Time}} · {{.Time}}{{end}}</div>
+{{if .Content}}<div class="content">{{.Content}}</div>
+{{end}}{{if .ToolName}}<p>Tool call: <code>{{.ToolName}}</code></p>
+{{if .ToolArgs}}<pre>{{range .ToolArgs}}{{.}}
+{{end}}</pre>
+{{end}}{{end}}{{if .ToolResult}}<pre{{if .IsError}} class="error"{{end}}>{{.ToolResult}}</pre>
+{{end}}</div>
+{{end}}</body>
+</html>
+`))
+
+func (htmlExporter) Export(w io.Writer, conv *Conversation, messages []*Message) error {
+	data := struct {
+		Title    string
+		Messages []htmlMessage
+	}{Title: exportTitle(conv)}
+
+	for _, msg := range messages {
+		hm := htmlMessage{
+			Role:    msg.Role,
+			Title:   roleTitle(msg.Role),
+			Content: msg.Content,
+		}
+		if !msg.Timestamp.IsZero() {
+			hm.Time = msg.Timestamp.Format("15:04:05")
+		}
+		if msg.ToolCall != nil {
+			hm.ToolName = msg.ToolCall.Name
+			keys := make([]string, 0, len(msg.ToolCall.Arguments))
+			for key := range msg.ToolCall.Arguments {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				hm.ToolArgs = append(hm.ToolArgs, fmt.Sprintf("%s: %v", key, msg.ToolCall.Arguments[key]))
+			}
+		}
+		if msg.ToolResult != nil {
+			hm.ToolResult = msg.ToolResult.Content
+			hm.IsError = msg.ToolResult.IsError
+		}
+		data.Messages = append(data.Messages, hm)
+	}
+
+	return htmlTemplate.Execute(w, data)
+}
+
+// exportTitle returns the heading used for an exported conversation
+func exportTitle(conv *Conversation) string {
+	if conv != nil && conv.Title != "" {
+		return conv.Title
+	}
+	return "Othello Conversation"
+}
+
+// roleTitle capitalizes a message role for display
+func roleTitle(role string) string {
+	switch role {
+	case "user":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	case "tool":
+		return "Tool"
+	case "system":
+		return "System"
+	}
+	return role
+}
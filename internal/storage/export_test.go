@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportFixture() (*Conversation, []*Message) {
+	ts := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	conv := &Conversation{ID: "conv-1", Title: "Database setup", CreatedAt: ts}
+	messages := []*Message{
+		{Role: "user", Content: "Find my notes on <postgres>", Timestamp: ts},
+		{
+			Role:       "tool",
+			Content:    "Executing tool: search...",
+			ToolCall:   &ToolCall{Name: "search", Arguments: map[string]interface{}{"query": "postgres", "limit": 5}},
+			ToolResult: &ToolResult{Content: `{"results":[]}`},
+			Timestamp:  ts.Add(time.Second),
+		},
+		{Role: "assistant", Content: "I couldn't find any notes.", ToolResult: &ToolResult{Content: "timeout", IsError: true}},
+	}
+	return conv, messages
+}
+
+func TestParseExportFormat(t *testing.T) {
+	for input, want := range map[string]ExportFormat{"markdown": ExportMarkdown, "MD": ExportMarkdown, "json": ExportJSON, "html": ExportHTML} {
+		got, err := ParseExportFormat(input)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := ParseExportFormat("pdf")
+	assert.ErrorContains(t, err, "unknown export format")
+}
+
+func TestMarkdownExporter(t *testing.T) {
+	conv, messages := exportFixture()
+	exporter, err := NewExporter(ExportMarkdown)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, exporter.Export(&buf, conv, messages))
+	out := buf.String()
+
+	assert.Contains(t, out, "# Database setup")
+	assert.Contains(t, out, "## User (09:30:00)")
+	assert.Contains(t, out, "Find my notes on <postgres>")
+	assert.Contains(t, out, "**Tool call:** `search`")
+	assert.Contains(t, out, `"query": "postgres"`)
+	assert.Contains(t, out, "**Result:**\n\n```\n{\"results\":[]}\n```")
+	assert.Contains(t, out, "**Error:**")
+}
+
+func TestJSONExporter(t *testing.T) {
+	conv, messages := exportFixture()
+	exporter, err := NewExporter(ExportJSON)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, exporter.Export(&buf, conv, messages))
+
+	var decoded struct {
+		Conversation Conversation `json:"conversation"`
+		Messages     []Message    `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "Database setup", decoded.Conversation.Title)
+	require.Len(t, decoded.Messages, 3)
+	assert.Equal(t, "search", decoded.Messages[1].ToolCall.Name)
+	assert.Equal(t, `{"results":[]}`, decoded.Messages[1].ToolResult.Content)
+	assert.True(t, decoded.Messages[2].ToolResult.IsError)
+}
+
+func TestHTMLExporter_EscapesContent(t *testing.T) {
+	conv, messages := exportFixture()
+	exporter, err := NewExporter(ExportHTML)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, exporter.Export(&buf, conv, messages))
+	out := buf.String()
+
+	assert.Contains(t, out, "<title>Database setup</title>")
+	assert.Contains(t, out, "Find my notes on &lt;postgres&gt;")
+	assert.Contains(t, out, "<code>search</code>")
+	assert.Contains(t, out, "limit: 5\nquery: postgres")
+	assert.Contains(t, out, `<pre class="error">timeout</pre>`)
+}
+
+func TestExportToFile(t *testing.T) {
+	conv, messages := exportFixture()
+	path := filepath.Join(t.TempDir(), "exports", "conversation.md")
+
+	require.NoError(t, ExportToFile(path, ExportMarkdown, conv, messages))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Database setup")
+}
+
+func TestDefaultExportPath(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 30, 5, 0, time.UTC)
+	assert.Equal(t, filepath.Join("out", "othello-conversation-20250314-093005.html"), DefaultExportPath("out", ExportHTML, now))
+}
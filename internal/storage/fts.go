@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// snippetTokens is roughly how many words a search snippet spans
+const snippetTokens = 12
+
+// Markers placed around matched terms in search snippets
+const (
+	SnippetStart = "["
+	SnippetEnd   = "]"
+)
+
+// ftsSchema indexes message content in an FTS5 table kept in sync by triggers.
+// Only content is indexed; the rows themselves stay in messages.
+const ftsSchema = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
+		content,
+		content='messages',
+		content_rowid='id'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS messages_fts_insert AFTER INSERT ON messages BEGIN
+		INSERT INTO messages_fts(rowid, content) VALUES (new.id, new.content);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS messages_fts_delete AFTER DELETE ON messages BEGIN
+		INSERT INTO messages_fts(messages_fts, rowid, content) VALUES ('delete', old.id, old.content);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS messages_fts_update AFTER UPDATE OF content ON messages BEGIN
+		INSERT INTO messages_fts(messages_fts, rowid, content) VALUES ('delete', old.id, old.content);
+		INSERT INTO messages_fts(rowid, content) VALUES (new.id, new.content);
+	END;
+`
+
+// SearchResult is a message matched by a ranked search
+type SearchResult struct {
+	Message    *Message `json:"message"`
+	Snippet    string   `json:"snippet"`    // matched terms wrapped in SnippetStart and SnippetEnd
+	Rank       float64  `json:"rank"`       // lower is better; 0 without full-text search
+	Similarity float64  `json:"similarity"` // cosine similarity to the query; 0 unless found semantically
+}
+
+// initSearchIndex creates the full-text index when SQLite was built with FTS5
+// and indexes any messages saved before it existed. Without FTS5 the store
+// falls back to LIKE queries.
+func (s *ConversationStore) initSearchIndex() error {
+	var existing int
+	if err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'messages_fts'",
+	).Scan(&existing); err != nil {
+		return fmt.Errorf("check search index: %w", err)
+	}
+
+	if _, err := s.db.Exec(ftsSchema); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return nil
+		}
+		return fmt.Errorf("create search index: %w", err)
+	}
+
+	if existing == 0 {
+		if _, err := s.db.Exec("INSERT INTO messages_fts(messages_fts) VALUES ('rebuild')"); err != nil {
+			return fmt.Errorf("build search index: %w", err)
+		}
+	}
+
+	s.fts = true
+	return nil
+}
+
+// FullTextSearch reports whether searches use the FTS5 index rather than LIKE
+func (s *ConversationStore) FullTextSearch() bool {
+	return s.fts
+}
+
+// ftsQuery turns free text into an FTS5 query matching every word as a
+// prefix. Words are quoted so punctuation and FTS5 operators are taken
+// literally.
+func ftsQuery(text string) string {
+	words := strings.Fields(text)
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"*`
+	}
+	return strings.Join(terms, " ")
+}
+
+// likeSnippet cuts a snippet around the first match of query in content,
+// marking the match the way FTS5 snippets do
+func likeSnippet(content, query string) string {
+	i := findIgnoreCase(content, query)
+	if query == "" || i < 0 || i+len(query) > len(content) {
+		words := strings.Fields(content)
+		if len(words) > snippetTokens {
+			return strings.Join(words[:snippetTokens], " ") + "..."
+		}
+		return strings.Join(words, " ")
+	}
+
+	// at is the word the match starts in
+	at := len(strings.Fields(content[:i]))
+	if at > 0 && !unicode.IsSpace(rune(content[i-1])) {
+		at--
+	}
+	end := i + len(query)
+	words := strings.Fields(content[:i] + SnippetStart + content[i:end] + SnippetEnd + content[end:])
+
+	from := max(at-snippetTokens/2, 0)
+	to := min(from+snippetTokens, len(words))
+	from = max(to-snippetTokens, 0)
+
+	snippet := strings.Join(words[from:to], " ")
+	if from > 0 {
+		snippet = "..." + snippet
+	}
+	if to < len(words) {
+		snippet += "..."
+	}
+	return snippet
+}
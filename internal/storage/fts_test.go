@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addSearchMessages(t *testing.T, store *ConversationStore, contents ...string) {
+	t.Helper()
+	_, err := store.CreateConversation("conv-search", "Search")
+	require.NoError(t, err)
+	for i, content := range contents {
+		require.NoError(t, store.AddMessage(&Message{
+			ConversationID: "conv-search",
+			Role:           "assistant",
+			Content:        content,
+			Timestamp:      time.Now().Add(time.Duration(i) * time.Minute),
+		}))
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	assert.Equal(t, `"machine"* "learn"*`, ftsQuery("machine  learn"))
+	assert.Equal(t, `"say"* """hi"""*`, ftsQuery(`say "hi"`))
+	assert.Equal(t, `"NOT"* "a*"*`, ftsQuery("NOT a*"))
+	assert.Empty(t, ftsQuery("   "))
+}
+
+func TestLikeSnippet(t *testing.T) {
+	assert.Equal(t, "Machine [learn]ing is fun", likeSnippet("Machine learning is fun", "LEARN"))
+	assert.Equal(t, "I like [machine learn]ing", likeSnippet("I like machine learning", "machine learn"))
+	assert.Equal(t, "no match here", likeSnippet("no  match here", "absent"))
+
+	long := "one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen target eighteen"
+	snippet := likeSnippet(long, "target")
+	assert.Contains(t, snippet, "[target]")
+	assert.True(t, len(snippet) < len(long))
+	assert.Equal(t, "...", snippet[:3])
+}
+
+func TestSearchManager_SearchRanked(t *testing.T) {
+	store, searchManager := setupSearchTestDB(t)
+	defer store.Close()
+
+	addSearchMessages(t, store,
+		"Machine learning is a subset of AI",
+		"Unrelated message about the weather",
+		"I enjoy machine learning and more machine learning",
+	)
+
+	results, err := searchManager.SearchRanked(SearchFilter{Query: "machine learn"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.Contains(t, result.Message.Content, "achine learning")
+		assert.Contains(t, result.Snippet, SnippetStart)
+		assert.Contains(t, result.Snippet, SnippetEnd)
+	}
+}
+
+func TestSearchIndex_FTS(t *testing.T) {
+	store, searchManager := setupSearchTestDB(t)
+	defer store.Close()
+	if !store.FullTextSearch() {
+		t.Skip("SQLite built without FTS5; build with -tags sqlite_fts5")
+	}
+
+	addSearchMessages(t, store,
+		"Databases store rows; a database index speeds up database queries",
+		"The cat sat on the mat",
+		"Backups of a database",
+	)
+
+	t.Run("ranks the best match first", func(t *testing.T) {
+		results, err := searchManager.SearchRanked(SearchFilter{Query: "datab"})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Contains(t, results[0].Message.Content, "index")
+		assert.LessOrEqual(t, results[0].Rank, results[1].Rank)
+		assert.Contains(t, results[0].Snippet, "[database]")
+	})
+
+	t.Run("triggers follow updates and deletes", func(t *testing.T) {
+		_, err := store.db.Exec("UPDATE messages SET content = 'The dog sat on the log' WHERE content LIKE 'The cat%'")
+		require.NoError(t, err)
+		messages, err := store.SearchMessages("cat", 10)
+		require.NoError(t, err)
+		assert.Empty(t, messages)
+		messages, err = store.SearchMessages("dog", 10)
+		require.NoError(t, err)
+		assert.Len(t, messages, 1)
+
+		_, err = store.db.Exec("DELETE FROM messages WHERE content LIKE 'Backups%'")
+		require.NoError(t, err)
+		messages, err = store.SearchMessages("backups", 10)
+		require.NoError(t, err)
+		assert.Empty(t, messages)
+	})
+}
+
+func TestSearchIndex_BackfillsExistingMessages(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "backfill.db")
+	store, err := NewConversationStore(dbPath)
+	require.NoError(t, err)
+	if !store.FullTextSearch() {
+		store.Close()
+		t.Skip("SQLite built without FTS5; build with -tags sqlite_fts5")
+	}
+	addSearchMessages(t, store, "Saved before the index existed")
+	require.NoError(t, store.Close())
+
+	// Simulate a database from before full-text search
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	_, err = db.Exec("DROP TABLE messages_fts")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err = NewConversationStore(dbPath)
+	require.NoError(t, err)
+	defer store.Close()
+
+	messages, err := store.SearchMessages("existed", 10)
+	require.NoError(t, err)
+	assert.Len(t, messages, 1)
+}
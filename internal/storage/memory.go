@@ -0,0 +1,389 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultImportance is given to memories saved without an importance
+	DefaultImportance = 5
+
+	// MaxImportance marks a memory as essential
+	MaxImportance = 10
+
+	// DefaultTopic files memories saved without a topic
+	DefaultTopic = "general"
+
+	// minMemorySimilarity is the cosine similarity below which a memory is
+	// not considered related to the query
+	minMemorySimilarity = 0.5
+
+	// minQueryWordLength skips short words such as "a" and "is" when
+	// matching memories by text
+	minQueryWordLength = 3
+)
+
+// Memory is a fact kept across conversations, filed under a topic
+type Memory struct {
+	ID          int64     `json:"id"`
+	Topic       string    `json:"topic"`
+	Content     string    `json:"content"`
+	Importance  int       `json:"importance"` // 1 (trivia) to 10 (essential)
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	RecalledAt  time.Time `json:"recalled_at,omitempty"` // zero until first recalled
+	RecallCount int       `json:"recall_count"`
+}
+
+// MemoryFilter narrows ListMemories; zero values match everything
+type MemoryFilter struct {
+	Topic         string
+	MinImportance int
+	Limit         int
+}
+
+// RecalledMemory is a memory related to a recall query. Score combines how
+// closely it matches with its importance; higher is better.
+type RecalledMemory struct {
+	Memory *Memory `json:"memory"`
+	Score  float64 `json:"score"`
+}
+
+// NormalizeTopic returns topic as it is stored: lower case with single
+// spaces, or DefaultTopic when empty
+func NormalizeTopic(topic string) string {
+	topic = strings.Join(strings.Fields(strings.ToLower(topic)), " ")
+	if topic == "" {
+		return DefaultTopic
+	}
+	return topic
+}
+
+// clampImportance keeps importance between 1 and MaxImportance, treating 0
+// as unset
+func clampImportance(importance int) int {
+	switch {
+	case importance == 0:
+		return DefaultImportance
+	case importance < 1:
+		return 1
+	case importance > MaxImportance:
+		return MaxImportance
+	}
+	return importance
+}
+
+// memoryColumns lists the columns read by scanMemory
+const memoryColumns = "id, topic, content, importance, created_at, updated_at, recalled_at, recall_count"
+
+// scanMemory reads a row selected with memoryColumns
+func scanMemory(row interface{ Scan(...interface{}) error }) (*Memory, error) {
+	memory := &Memory{}
+	var recalledAt sql.NullTime
+	if err := row.Scan(&memory.ID, &memory.Topic, &memory.Content, &memory.Importance,
+		&memory.CreatedAt, &memory.UpdatedAt, &recalledAt, &memory.RecallCount); err != nil {
+		return nil, err
+	}
+	memory.RecalledAt = recalledAt.Time
+	return memory, nil
+}
+
+// SaveMemory stores memory, filling in its ID and timestamps. Saving a fact
+// already stored under the same topic updates it instead, keeping the higher
+// importance.
+func (s *ConversationStore) SaveMemory(memory *Memory) error {
+	memory.Topic = NormalizeTopic(memory.Topic)
+	memory.Content = strings.TrimSpace(memory.Content)
+	memory.Importance = clampImportance(memory.Importance)
+	if memory.Content == "" {
+		return fmt.Errorf("memory content cannot be empty")
+	}
+
+	now := time.Now()
+	existing, err := scanMemory(s.db.QueryRow(
+		"SELECT "+memoryColumns+" FROM memories WHERE topic = ? AND content = ?", memory.Topic, memory.Content,
+	))
+	switch {
+	case err == sql.ErrNoRows:
+		result, err := s.db.Exec(`
+			INSERT INTO memories (topic, content, importance, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, memory.Topic, memory.Content, memory.Importance, now, now)
+		if err != nil {
+			return fmt.Errorf("insert memory: %w", err)
+		}
+		if memory.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("get memory ID: %w", err)
+		}
+		memory.CreatedAt, memory.UpdatedAt = now, now
+		return nil
+	case err != nil:
+		return fmt.Errorf("query memory: %w", err)
+	}
+
+	importance := max(existing.Importance, memory.Importance)
+	if _, err := s.db.Exec(
+		"UPDATE memories SET importance = ?, updated_at = ? WHERE id = ?", importance, now, existing.ID,
+	); err != nil {
+		return fmt.Errorf("update memory: %w", err)
+	}
+	*memory = *existing
+	memory.Importance = importance
+	memory.UpdatedAt = now
+	return nil
+}
+
+// GetMemory returns the memory with id, or nil if there is none
+func (s *ConversationStore) GetMemory(id int64) (*Memory, error) {
+	memory, err := scanMemory(s.db.QueryRow("SELECT "+memoryColumns+" FROM memories WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query memory: %w", err)
+	}
+	return memory, nil
+}
+
+// ListMemories returns the memories matching filter, most important first and
+// then most recently updated
+func (s *ConversationStore) ListMemories(filter MemoryFilter) ([]*Memory, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Topic != "" {
+		conditions = append(conditions, "topic = ?")
+		args = append(args, NormalizeTopic(filter.Topic))
+	}
+	if filter.MinImportance > 0 {
+		conditions = append(conditions, "importance >= ?")
+		args = append(args, filter.MinImportance)
+	}
+
+	query := "SELECT " + memoryColumns + " FROM memories"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY importance DESC, updated_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query memories: %w", err)
+	}
+	defer rows.Close()
+
+	var memories []*Memory
+	for rows.Next() {
+		memory, err := scanMemory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan memory: %w", err)
+		}
+		memories = append(memories, memory)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate memories: %w", err)
+	}
+	return memories, nil
+}
+
+// DeleteMemory removes a memory; its embeddings go with it
+func (s *ConversationStore) DeleteMemory(id int64) error {
+	result, err := s.db.Exec("DELETE FROM memories WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete memory: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("delete memory: %w", err)
+	} else if deleted == 0 {
+		return fmt.Errorf("memory %d not found", id)
+	}
+	return nil
+}
+
+// markRecalled records that memories were just sent to the model
+func (s *ConversationStore) markRecalled(memories []*RecalledMemory) error {
+	now := time.Now()
+	for _, recalled := range memories {
+		if _, err := s.db.Exec(
+			"UPDATE memories SET recalled_at = ?, recall_count = recall_count + 1 WHERE id = ?", now, recalled.Memory.ID,
+		); err != nil {
+			return fmt.Errorf("update memory: %w", err)
+		}
+		recalled.Memory.RecalledAt = now
+		recalled.Memory.RecallCount++
+	}
+	return nil
+}
+
+// MemoryManager recalls the memories related to a query, by meaning when an
+// embedder is set and by shared words otherwise
+type MemoryManager struct {
+	store          *ConversationStore
+	embedder       Embedder
+	embeddingModel string
+}
+
+// MemoryManager returns a memory manager over the store's memories
+func (s *ConversationStore) MemoryManager() *MemoryManager {
+	return &MemoryManager{store: s}
+}
+
+// SetEmbedder enables recall by meaning with vectors from embedder. model
+// names the embedding model so vectors from different models are never compared.
+func (mm *MemoryManager) SetEmbedder(embedder Embedder, model string) {
+	mm.embedder = embedder
+	mm.embeddingModel = model
+}
+
+// Recall returns up to limit memories related to query, best first. Without
+// an embedder it is the same as RecallText.
+func (mm *MemoryManager) Recall(ctx context.Context, query string, limit int) ([]*RecalledMemory, error) {
+	if mm.embedder == nil || strings.TrimSpace(query) == "" {
+		return mm.RecallText(query, limit)
+	}
+
+	memories, err := mm.store.ListMemories(MemoryFilter{})
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := mm.embeddings(ctx, memories)
+	if err != nil {
+		return nil, err
+	}
+	queryVectors, err := mm.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(queryVectors) != 1 {
+		return nil, fmt.Errorf("embed query: got %d vectors", len(queryVectors))
+	}
+
+	var recalled []*RecalledMemory
+	for _, memory := range memories {
+		similarity := cosineSimilarity(queryVectors[0], vectors[memory.ID])
+		if similarity < minMemorySimilarity {
+			continue
+		}
+		recalled = append(recalled, &RecalledMemory{Memory: memory, Score: similarity * importanceWeight(memory.Importance)})
+	}
+	return mm.best(recalled, limit)
+}
+
+// RecallText returns up to limit memories sharing words with query, best first
+func (mm *MemoryManager) RecallText(query string, limit int) ([]*RecalledMemory, error) {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		word = strings.Trim(word, ".,;:!?\"'()[]{}")
+		if len([]rune(word)) >= minQueryWordLength {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	memories, err := mm.store.ListMemories(MemoryFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var recalled []*RecalledMemory
+	for _, memory := range memories {
+		text := strings.ToLower(memory.Topic + " " + memory.Content)
+		matched := 0
+		for _, word := range words {
+			if strings.Contains(text, word) {
+				matched++
+			}
+		}
+		if matched == 0 {
+			continue
+		}
+		relevance := float64(matched) / float64(len(words))
+		recalled = append(recalled, &RecalledMemory{Memory: memory, Score: relevance * importanceWeight(memory.Importance)})
+	}
+	return mm.best(recalled, limit)
+}
+
+// best sorts recalled memories by score, keeps up to limit, and records that
+// they were recalled
+func (mm *MemoryManager) best(recalled []*RecalledMemory, limit int) ([]*RecalledMemory, error) {
+	sort.SliceStable(recalled, func(i, j int) bool {
+		return recalled[i].Score > recalled[j].Score
+	})
+	if limit > 0 && len(recalled) > limit {
+		recalled = recalled[:limit]
+	}
+	if err := mm.store.markRecalled(recalled); err != nil {
+		return nil, err
+	}
+	return recalled, nil
+}
+
+// embeddings returns the vector of each memory by ID, embedding the memories
+// that have no vector from the current model yet
+func (mm *MemoryManager) embeddings(ctx context.Context, memories []*Memory) (map[int64][]float32, error) {
+	rows, err := mm.store.db.QueryContext(ctx,
+		"SELECT memory_id, vector FROM memory_embeddings WHERE model = ?", mm.embeddingModel)
+	if err != nil {
+		return nil, fmt.Errorf("query memory embeddings: %w", err)
+	}
+	vectors := make(map[int64][]float32)
+	for rows.Next() {
+		var id int64
+		var blob []byte
+		if err := rows.Scan(&id, &blob); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan memory embedding: %w", err)
+		}
+		vectors[id] = decodeVector(blob)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate memory embeddings: %w", err)
+	}
+
+	var pending []*Memory
+	for _, memory := range memories {
+		if _, ok := vectors[memory.ID]; !ok {
+			pending = append(pending, memory)
+		}
+	}
+	for start := 0; start < len(pending); start += embeddingBatchSize {
+		batch := pending[start:min(start+embeddingBatchSize, len(pending))]
+		texts := make([]string, len(batch))
+		for i, memory := range batch {
+			texts[i] = memory.Topic + ": " + memory.Content
+		}
+		embedded, err := mm.embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("embed memories: %w", err)
+		}
+		if len(embedded) != len(batch) {
+			return nil, fmt.Errorf("embed memories: got %d vectors for %d memories", len(embedded), len(batch))
+		}
+		for i, memory := range batch {
+			if _, err := mm.store.db.Exec(
+				"INSERT OR REPLACE INTO memory_embeddings (memory_id, model, vector) VALUES (?, ?, ?)",
+				memory.ID, mm.embeddingModel, encodeVector(embedded[i]),
+			); err != nil {
+				return nil, fmt.Errorf("save memory embedding: %w", err)
+			}
+			vectors[memory.ID] = embedded[i]
+		}
+	}
+	return vectors, nil
+}
+
+// importanceWeight scales a match by importance, from 0.55 for trivia to 1
+// for essential memories, so importance breaks ties without burying a close match
+func importanceWeight(importance int) float64 {
+	return 0.5 + float64(importance)/(2*MaxImportance)
+}
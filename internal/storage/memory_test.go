@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTopic(t *testing.T) {
+	assert.Equal(t, "home office", NormalizeTopic("  Home   Office "))
+	assert.Equal(t, DefaultTopic, NormalizeTopic(" "))
+}
+
+func TestSaveMemory(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	memory := &Memory{Topic: "Preferences", Content: " Prefers dark mode ", Importance: 12}
+	require.NoError(t, store.SaveMemory(memory))
+	assert.NotZero(t, memory.ID)
+	assert.Equal(t, "preferences", memory.Topic)
+	assert.Equal(t, "Prefers dark mode", memory.Content)
+	assert.Equal(t, MaxImportance, memory.Importance)
+
+	// Saving the same fact again updates it, keeping the higher importance
+	again := &Memory{Topic: "preferences", Content: "Prefers dark mode", Importance: 3}
+	require.NoError(t, store.SaveMemory(again))
+	assert.Equal(t, memory.ID, again.ID)
+	assert.Equal(t, MaxImportance, again.Importance)
+
+	unset := &Memory{Content: "Lives in Lisbon"}
+	require.NoError(t, store.SaveMemory(unset))
+	assert.Equal(t, DefaultTopic, unset.Topic)
+	assert.Equal(t, DefaultImportance, unset.Importance)
+
+	assert.Error(t, store.SaveMemory(&Memory{Topic: "empty"}))
+
+	memories, err := store.ListMemories(MemoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, memories, 2)
+	assert.Equal(t, "Prefers dark mode", memories[0].Content, "Most important first")
+
+	general, err := store.ListMemories(MemoryFilter{Topic: "General"})
+	require.NoError(t, err)
+	require.Len(t, general, 1)
+	important, err := store.ListMemories(MemoryFilter{MinImportance: 8})
+	require.NoError(t, err)
+	assert.Len(t, important, 1)
+
+	require.NoError(t, store.DeleteMemory(memory.ID))
+	deleted, err := store.GetMemory(memory.ID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+	assert.Error(t, store.DeleteMemory(memory.ID))
+}
+
+func TestMemoryManager_RecallText(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+	for _, memory := range []*Memory{
+		{Topic: "car", Content: "The car is a blue hatchback", Importance: 2},
+		{Topic: "car", Content: "The car needs new tires before winter", Importance: 9},
+		{Topic: "cooking", Content: "Allergic to peanuts", Importance: 10},
+	} {
+		require.NoError(t, store.SaveMemory(memory))
+	}
+
+	recalled, err := store.MemoryManager().RecallText("What does my car need?", 5)
+	require.NoError(t, err)
+	require.Len(t, recalled, 2)
+	assert.Equal(t, "The car needs new tires before winter", recalled[0].Memory.Content)
+	assert.Equal(t, 1, recalled[0].Memory.RecallCount)
+	assert.False(t, recalled[0].Memory.RecalledAt.IsZero())
+
+	none, err := store.MemoryManager().RecallText("is it ok", 5)
+	require.NoError(t, err)
+	assert.Empty(t, none, "Short words are ignored")
+}
+
+func TestMemoryManager_RecallByMeaning(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+	for _, memory := range []*Memory{
+		{Topic: "transport", Content: "Drives an automobile to work"},
+		{Topic: "food", Content: "Likes to bake bread"},
+	} {
+		require.NoError(t, store.SaveMemory(memory))
+	}
+
+	embedder := &conceptEmbedder{}
+	manager := store.MemoryManager()
+	manager.SetEmbedder(embedder, "concepts")
+
+	recalled, err := manager.Recall(context.Background(), "my vehicle", 5)
+	require.NoError(t, err)
+	require.Len(t, recalled, 1)
+	assert.Equal(t, "Drives an automobile to work", recalled[0].Memory.Content)
+
+	// Memory vectors are embedded once and reused
+	calls := embedder.calls
+	_, err = manager.Recall(context.Background(), "a recipe", 5)
+	require.NoError(t, err)
+	assert.Equal(t, calls+1, embedder.calls, "Only the query is embedded again")
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PermissionsFile is the name of the file inside the data directory that
+// keeps the trust given to each server
+const PermissionsFile = "permissions.json"
+
+// TrustLevel is how far the agent trusts the tools of a server
+type TrustLevel string
+
+const (
+	TrustBlocked  TrustLevel = "blocked"   // No tool of the server may be called
+	TrustReadOnly TrustLevel = "read-only" // Tools that only read run without approval; tools that delete or change data are refused
+	TrustAsk      TrustLevel = "ask"       // Every call waits for the user's approval
+	TrustFull     TrustLevel = "trusted"   // Every call runs without approval
+)
+
+// TrustLevels are the levels a server can be given, from least trusted
+var TrustLevels = []TrustLevel{TrustBlocked, TrustReadOnly, TrustAsk, TrustFull}
+
+// ParseTrustLevel returns the trust level called name
+func ParseTrustLevel(name string) (TrustLevel, error) {
+	level := TrustLevel(strings.ToLower(name))
+	if !slices.Contains(TrustLevels, level) {
+		names := make([]string, len(TrustLevels))
+		for i, l := range TrustLevels {
+			names[i] = string(l)
+		}
+		return "", fmt.Errorf("unknown trust level %q; use one of: %s", name, strings.Join(names, ", "))
+	}
+	return level, nil
+}
+
+// ServerTrust is the trust given to one server
+type ServerTrust struct {
+	Level   TrustLevel `json:"level"`
+	Updated time.Time  `json:"updated"`
+}
+
+// Permissions are the trust decisions kept in the permissions file, by
+// server name
+type Permissions struct {
+	Servers map[string]ServerTrust `json:"servers"`
+}
+
+// Trust returns the level given to server, and false when it was given none
+func (p *Permissions) Trust(server string) (TrustLevel, bool) {
+	trust, ok := p.Servers[server]
+	return trust.Level, ok
+}
+
+// PermissionsPath returns the path of the permissions file in dataDir,
+// expanding a leading ~
+func PermissionsPath(dataDir string) (string, error) {
+	return dataFilePath(dataDir, PermissionsFile)
+}
+
+// LoadPermissions reads the permissions file at path. A missing file holds
+// no decisions.
+func LoadPermissions(path string) (*Permissions, error) {
+	permissions := &Permissions{Servers: make(map[string]ServerTrust)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return permissions, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read permissions: %w", err)
+	}
+	if err := json.Unmarshal(data, permissions); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if permissions.Servers == nil {
+		permissions.Servers = make(map[string]ServerTrust)
+	}
+	return permissions, nil
+}
+
+// SetServerTrust gives server the trust level in the permissions file at
+// path, creating the file if needed. An empty level removes the server's
+// decision, so the agent's configuration decides again.
+func SetServerTrust(path, server string, level TrustLevel) error {
+	permissions, err := LoadPermissions(path)
+	if err != nil {
+		return err
+	}
+	if level == "" {
+		delete(permissions.Servers, server)
+	} else {
+		permissions.Servers[server] = ServerTrust{Level: level, Updated: time.Now().UTC()}
+	}
+
+	data, err := json.MarshalIndent(permissions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode permissions: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	// Written to a temporary file first, so a running agent never reads half a file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("write permissions: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write permissions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", PermissionsFile)
+
+	permissions, err := LoadPermissions(path)
+	require.NoError(t, err, "A missing file holds no decisions")
+	_, ok := permissions.Trust("filesystem")
+	assert.False(t, ok)
+
+	require.NoError(t, SetServerTrust(path, "filesystem", TrustReadOnly))
+	require.NoError(t, SetServerTrust(path, "community", TrustBlocked))
+	permissions, err = LoadPermissions(path)
+	require.NoError(t, err)
+	level, ok := permissions.Trust("filesystem")
+	assert.True(t, ok)
+	assert.Equal(t, TrustReadOnly, level)
+	assert.False(t, permissions.Servers["filesystem"].Updated.IsZero())
+
+	require.NoError(t, SetServerTrust(path, "filesystem", ""))
+	permissions, err = LoadPermissions(path)
+	require.NoError(t, err)
+	_, ok = permissions.Trust("filesystem")
+	assert.False(t, ok, "Clearing a decision removes it")
+	assert.Contains(t, permissions.Servers, "community")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+	_, err = LoadPermissions(path)
+	assert.Error(t, err)
+}
+
+func TestParseTrustLevel(t *testing.T) {
+	level, err := ParseTrustLevel("Read-Only")
+	require.NoError(t, err)
+	assert.Equal(t, TrustReadOnly, level)
+
+	_, err = ParseTrustLevel("sometimes")
+	assert.ErrorContains(t, err, "use one of: blocked, read-only, ask, trusted")
+}
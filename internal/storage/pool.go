@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"database/sql"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// busyTimeout is how long a connection waits for another to finish
+	// writing before failing with "database is locked"
+	busyTimeout = 5 * time.Second
+
+	// maxOpenConns bounds the connection pool. In WAL mode readers don't block
+	// the writer, so a few connections let the TUI read while background jobs write.
+	maxOpenConns = 4
+
+	// connMaxIdleTime closes pooled connections that sit unused
+	connMaxIdleTime = 5 * time.Minute
+)
+
+// memoryDatabase is the path SQLite opens as a private in-memory database
+const memoryDatabase = ":memory:"
+
+// dataSourceName adds the connection settings to dbPath. They are applied to
+// every connection the pool opens, unlike a PRAGMA run once after opening.
+// Transactions take the write lock when they begin, so two writers queue on
+// the busy timeout instead of one failing when it tries to upgrade its lock.
+func dataSourceName(dbPath string) string {
+	params := url.Values{}
+	params.Set("_busy_timeout", strconv.FormatInt(busyTimeout.Milliseconds(), 10))
+	params.Set("_foreign_keys", "on")
+	params.Set("_txlock", "immediate")
+	if dbPath != memoryDatabase {
+		params.Set("_journal_mode", "WAL")
+		params.Set("_synchronous", "NORMAL")
+	}
+	return dbPath + "?" + params.Encode()
+}
+
+// configurePool sizes the connection pool for dbPath. Each connection to
+// ":memory:" opens a separate empty database, so it is limited to one.
+func configurePool(db *sql.DB, dbPath string) {
+	if dbPath == memoryDatabase {
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		return
+	}
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxOpenConns)
+	db.SetConnMaxIdleTime(connMaxIdleTime)
+}
+
+// stmtCache prepares each query once and reuses the statement on later calls.
+// It is only for queries with fixed text; queries built from filters would
+// fill it with statements that are rarely reused.
+type stmtCache struct {
+	db    *sql.DB
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// newStmtCache creates an empty statement cache over db
+func newStmtCache(db *sql.DB) *stmtCache {
+	return &stmtCache{db: db, stmts: make(map[string]*sql.Stmt)}
+}
+
+// prepare returns the cached statement for query, preparing it if needed
+func (c *stmtCache) prepare(query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := c.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+// Exec runs query with a cached statement
+func (c *stmtCache) Exec(query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := c.prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(args...)
+}
+
+// Query runs query with a cached statement
+func (c *stmtCache) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := c.prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Query(args...)
+}
+
+// QueryRow runs query with a cached statement. A query that can't be prepared
+// runs directly so the error surfaces from Scan.
+func (c *stmtCache) QueryRow(query string, args ...interface{}) *sql.Row {
+	stmt, err := c.prepare(query)
+	if err != nil {
+		return c.db.QueryRow(query, args...)
+	}
+	return stmt.QueryRow(args...)
+}
+
+// Close closes every cached statement
+func (c *stmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var firstErr error
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.stmts, query)
+	}
+	return firstErr
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConversationStore_WALMode(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	var mode string
+	require.NoError(t, store.db.QueryRow("PRAGMA journal_mode").Scan(&mode))
+	assert.Equal(t, "wal", mode)
+
+	// Settings apply to every pooled connection, not just the first
+	conns := make([]interface{ Close() error }, 0, maxOpenConns)
+	for i := 0; i < maxOpenConns; i++ {
+		conn, err := store.db.Conn(t.Context())
+		require.NoError(t, err)
+		conns = append(conns, conn)
+
+		var foreignKeys, timeout int
+		require.NoError(t, conn.QueryRowContext(t.Context(), "PRAGMA foreign_keys").Scan(&foreignKeys))
+		require.NoError(t, conn.QueryRowContext(t.Context(), "PRAGMA busy_timeout").Scan(&timeout))
+		assert.Equal(t, 1, foreignKeys)
+		assert.Equal(t, int(busyTimeout.Milliseconds()), timeout)
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+func TestNewConversationStore_MemorySharesOneDatabase(t *testing.T) {
+	store, err := NewConversationStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.CreateConversation("conv", "In memory")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conv, err := store.GetConversation("conv")
+			assert.NoError(t, err)
+			assert.NotNil(t, conv, "Every query sees the same in-memory database")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConversationStore_ConcurrentWrites(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	store, err := NewConversationStore(dbPath)
+	require.NoError(t, err)
+	defer store.Close()
+
+	// A second store stands in for a background job with its own pool
+	background, err := NewConversationStore(dbPath)
+	require.NoError(t, err)
+	defer background.Close()
+
+	_, err = store.CreateConversation("conv", "Busy")
+	require.NoError(t, err)
+
+	const writers, writes = 4, 25
+	errs := make(chan error, 2*writers*writes)
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		for _, s := range []*ConversationStore{store, background} {
+			wg.Add(1)
+			go func(s *ConversationStore, w int) {
+				defer wg.Done()
+				for i := 0; i < writes; i++ {
+					if err := s.AddMessage(&Message{
+						ConversationID: "conv",
+						Role:           "user",
+						Content:        fmt.Sprintf("writer %d message %d", w, i),
+						Timestamp:      time.Now(),
+					}); err != nil {
+						errs <- err
+					}
+					if err := s.RecordToolExecution(&ToolExecution{Server: "test", Tool: "echo", Success: true}); err != nil {
+						errs <- err
+					}
+				}
+			}(s, w)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	conv, err := store.GetConversation("conv")
+	require.NoError(t, err)
+	assert.Equal(t, 2*writers*writes, conv.MessageCount)
+}
+
+func TestStmtCache(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	require.NoError(t, store.AddPrompt("first"))
+	require.NoError(t, store.AddPrompt("second"))
+	count := len(store.stmts.stmts)
+	assert.NotZero(t, count)
+
+	require.NoError(t, store.AddPrompt("third"))
+	assert.Equal(t, count, len(store.stmts.stmts), "Statements are prepared once")
+
+	var missing string
+	err := store.stmts.QueryRow("SELECT missing FROM nowhere").Scan(&missing)
+	assert.Error(t, err)
+
+	require.NoError(t, store.stmts.Close())
+	assert.Empty(t, store.stmts.stmts)
+}
+
+func TestDeleteMemory_RemovesEmbeddings(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	memory := &Memory{Content: "Drives an automobile to work"}
+	require.NoError(t, store.SaveMemory(memory))
+	_, err := store.db.Exec("INSERT INTO memory_embeddings (memory_id, model, vector) VALUES (?, ?, ?)",
+		memory.ID, "concepts", encodeVector([]float32{1, 0}))
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteMemory(memory.ID))
+	var remaining int
+	require.NoError(t, store.db.QueryRow("SELECT COUNT(*) FROM memory_embeddings").Scan(&remaining))
+	assert.Zero(t, remaining)
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// minChunkSimilarity is the cosine similarity below which a project chunk is
+// not considered related to the query. It is higher than for messages since
+// unrelated code still shares much of its vocabulary.
+const minChunkSimilarity = 0.55
+
+// ProjectChunk is a run of lines from a file in an indexed project directory
+type ProjectChunk struct {
+	ID         int64
+	Root       string // Absolute path of the indexed directory
+	Path       string // File path relative to Root, with forward slashes
+	StartLine  int    // First line, counting from 1
+	EndLine    int    // Last line, inclusive
+	Content    string
+	Similarity float64 // Cosine similarity to the query, set by SearchProjectChunks
+}
+
+// ProjectFileHashes returns the hash of each file indexed under root with
+// vectors from model, by path
+func (s *ConversationStore) ProjectFileHashes(root, model string) (map[string]string, error) {
+	rows, err := s.db.Query(
+		"SELECT DISTINCT path, file_hash FROM project_chunks WHERE root = ? AND model = ?", root, model,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query project files: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var path, hash string
+		if err := rows.Scan(&path, &hash); err != nil {
+			return nil, fmt.Errorf("scan project file: %w", err)
+		}
+		hashes[path] = hash
+	}
+	return hashes, rows.Err()
+}
+
+// SaveProjectFile replaces the chunks of a file with chunks, whose vectors
+// from model are given in the same order
+func (s *ConversationStore) SaveProjectFile(root, path, fileHash, model string, chunks []*ProjectChunk, vectors [][]float32) error {
+	if len(vectors) != len(chunks) {
+		return fmt.Errorf("save project file: got %d vectors for %d chunks", len(vectors), len(chunks))
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM project_chunks WHERE root = ? AND path = ?", root, path); err != nil {
+		return fmt.Errorf("delete project chunks: %w", err)
+	}
+	for i, chunk := range chunks {
+		result, err := tx.Exec(`
+			INSERT INTO project_chunks (root, path, file_hash, start_line, end_line, content, model, vector)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, root, path, fileHash, chunk.StartLine, chunk.EndLine, chunk.Content, model, encodeVector(vectors[i]))
+		if err != nil {
+			return fmt.Errorf("insert project chunk: %w", err)
+		}
+		chunk.ID, _ = result.LastInsertId()
+		chunk.Root, chunk.Path = root, path
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteProjectFile removes the chunks of a file that no longer exists
+func (s *ConversationStore) DeleteProjectFile(root, path string) error {
+	if _, err := s.db.Exec("DELETE FROM project_chunks WHERE root = ? AND path = ?", root, path); err != nil {
+		return fmt.Errorf("delete project file: %w", err)
+	}
+	return nil
+}
+
+// ClearProjectIndex removes every chunk indexed under root and returns how
+// many files they came from
+func (s *ConversationStore) ClearProjectIndex(root string) (int, error) {
+	var files int
+	if err := s.db.QueryRow(
+		"SELECT COUNT(DISTINCT path) FROM project_chunks WHERE root = ?", root,
+	).Scan(&files); err != nil {
+		return 0, fmt.Errorf("count project files: %w", err)
+	}
+	if _, err := s.db.Exec("DELETE FROM project_chunks WHERE root = ?", root); err != nil {
+		return 0, fmt.Errorf("clear project index: %w", err)
+	}
+	return files, nil
+}
+
+// SearchProjectChunks returns up to limit chunks with vectors from model that
+// are most similar to query, best first, from every indexed directory
+func (s *ConversationStore) SearchProjectChunks(ctx context.Context, model string, query []float32, limit int) ([]*ProjectChunk, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, root, path, start_line, end_line, content, vector
+		FROM project_chunks
+		WHERE model = ?
+	`, model)
+	if err != nil {
+		return nil, fmt.Errorf("query project chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []*ProjectChunk
+	for rows.Next() {
+		chunk := &ProjectChunk{}
+		var blob []byte
+		if err := rows.Scan(&chunk.ID, &chunk.Root, &chunk.Path, &chunk.StartLine, &chunk.EndLine, &chunk.Content, &blob); err != nil {
+			return nil, fmt.Errorf("scan project chunk: %w", err)
+		}
+		chunk.Similarity = cosineSimilarity(query, decodeVector(blob))
+		if chunk.Similarity < minChunkSimilarity {
+			continue
+		}
+		chunks = append(chunks, chunk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate project chunks: %w", err)
+	}
+
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return chunks[i].Similarity > chunks[j].Similarity
+	})
+	if limit > 0 && len(chunks) > limit {
+		chunks = chunks[:limit]
+	}
+	return chunks, nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectChunks(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	chunks := []*ProjectChunk{
+		{StartLine: 1, EndLine: 20, Content: "func startEngine(car *Car)"},
+		{StartLine: 15, EndLine: 40, Content: "func forecast() Weather"},
+	}
+	vectors := [][]float32{{1, 0, 0.1}, {0, 1, 0.1}}
+	require.NoError(t, store.SaveProjectFile("/src/app", "main.go", "hash1", "embed", chunks, vectors))
+	old := []*ProjectChunk{{StartLine: 1, EndLine: 5, Content: "func driveCar()"}}
+	require.NoError(t, store.SaveProjectFile("/src/app", "old.go", "hash2", "embed", old, vectors[:1]))
+	assert.Equal(t, "main.go", chunks[0].Path)
+	assert.NotZero(t, chunks[0].ID)
+
+	hashes, err := store.ProjectFileHashes("/src/app", "embed")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"main.go": "hash1", "old.go": "hash2"}, hashes)
+	hashes, err = store.ProjectFileHashes("/src/app", "other-model")
+	require.NoError(t, err)
+	assert.Empty(t, hashes, "Vectors from another model don't count")
+
+	found, err := store.SearchProjectChunks(ctx, "embed", []float32{1, 0, 0}, 5)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.ElementsMatch(t, []string{"main.go", "old.go"}, []string{found[0].Path, found[1].Path})
+	assert.Equal(t, "func startEngine(car *Car)", found[0].Content)
+	assert.Equal(t, "/src/app", found[0].Root)
+	assert.Equal(t, 1, found[0].StartLine)
+	assert.Greater(t, found[0].Similarity, 0.99)
+
+	require.NoError(t, store.DeleteProjectFile("/src/app", "old.go"))
+	require.NoError(t, store.SaveProjectFile("/src/app", "main.go", "hash3", "embed", chunks[1:], vectors[1:]))
+	found, err = store.SearchProjectChunks(ctx, "embed", []float32{1, 0, 0}, 5)
+	require.NoError(t, err)
+	assert.Empty(t, found, "Replaced and deleted chunks are gone, and unrelated ones aren't returned")
+
+	files, err := store.ClearProjectIndex("/src/app")
+	require.NoError(t, err)
+	assert.Equal(t, 1, files)
+	hashes, err = store.ProjectFileHashes("/src/app", "embed")
+	require.NoError(t, err)
+	assert.Empty(t, hashes)
+
+	assert.Error(t, store.SaveProjectFile("/src/app", "main.go", "hash", "embed", chunks, vectors[:1]))
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DatabaseFile is the name of the SQLite database inside the data directory
+const DatabaseFile = "othello.db"
+
+// DatabasePath returns the path of the database in dataDir, expanding a leading ~
+func DatabasePath(dataDir string) (string, error) {
+	return dataFilePath(dataDir, DatabaseFile)
+}
+
+// TemplateDir is the name of the directory of prompt templates inside the
+// data directory
+const TemplateDir = "templates"
+
+// TemplatePath returns the path of the template directory in dataDir,
+// expanding a leading ~
+func TemplatePath(dataDir string) (string, error) {
+	return dataFilePath(dataDir, TemplateDir)
+}
+
+// dataFilePath returns the path of file in dataDir, expanding a leading ~
+func dataFilePath(dataDir, file string) (string, error) {
+	if strings.HasPrefix(dataDir, "~/") || dataDir == "~" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("get home directory: %w", err)
+		}
+		dataDir = filepath.Join(homeDir, strings.TrimPrefix(dataDir, "~"))
+	}
+	return filepath.Join(dataDir, file), nil
+}
+
+// AddPrompt records a prompt typed into the chat input. A prompt identical to
+// the most recent one is not stored again.
+func (s *ConversationStore) AddPrompt(prompt string) error {
+	var last string
+	err := s.stmts.QueryRow("SELECT prompt FROM prompt_history ORDER BY id DESC LIMIT 1").Scan(&last)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("query last prompt: %w", err)
+	}
+	if err == nil && last == prompt {
+		return nil
+	}
+
+	if _, err := s.stmts.Exec("INSERT INTO prompt_history (prompt, created_at) VALUES (?, ?)", prompt, time.Now()); err != nil {
+		return fmt.Errorf("insert prompt: %w", err)
+	}
+	return nil
+}
+
+// RecentPrompts returns up to limit of the most recent prompts, oldest first
+func (s *ConversationStore) RecentPrompts(limit int) ([]string, error) {
+	rows, err := s.db.Query("SELECT prompt FROM prompt_history ORDER BY id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, fmt.Errorf("query prompts: %w", err)
+	}
+	defer rows.Close()
+
+	var prompts []string
+	for rows.Next() {
+		var prompt string
+		if err := rows.Scan(&prompt); err != nil {
+			return nil, fmt.Errorf("scan prompt: %w", err)
+		}
+		prompts = append(prompts, prompt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate prompts: %w", err)
+	}
+
+	// Reverse to chronological order (oldest first)
+	for i, j := 0, len(prompts)-1; i < j; i, j = i+1, j-1 {
+		prompts[i], prompts[j] = prompts[j], prompts[i]
+	}
+	return prompts, nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptHistory(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	prompts, err := store.RecentPrompts(10)
+	require.NoError(t, err)
+	assert.Empty(t, prompts)
+
+	for _, prompt := range []string{"first", "second", "second", "/tools", "third"} {
+		require.NoError(t, store.AddPrompt(prompt))
+	}
+
+	prompts, err = store.RecentPrompts(10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "/tools", "third"}, prompts, "Consecutive duplicates are stored once")
+
+	prompts, err = store.RecentPrompts(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/tools", "third"}, prompts)
+}
+
+func TestPromptHistory_PersistsAcrossStores(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), DatabaseFile)
+
+	store, err := NewConversationStore(dbPath)
+	require.NoError(t, err)
+	require.NoError(t, store.AddPrompt("remember me"))
+	require.NoError(t, store.Close())
+
+	store, err = NewConversationStore(dbPath)
+	require.NoError(t, err)
+	defer store.Close()
+
+	prompts, err := store.RecentPrompts(10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"remember me"}, prompts)
+}
+
+func TestDatabasePath(t *testing.T) {
+	path, err := DatabasePath("/var/lib/othello")
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/othello/othello.db", path)
+
+	homeDir, err := os.UserHomeDir()
+	require.NoError(t, err)
+	path, err = DatabasePath("~/.othello")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(homeDir, ".othello", DatabaseFile), path)
+}
@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// lastVacuumKey records in store_metadata when the database was last vacuumed
+const lastVacuumKey = "last_vacuum"
+
+// RetentionPolicy limits how much conversation history is kept. A zero limit
+// is not enforced.
+type RetentionPolicy struct {
+	MaxConversations int           // Most conversations kept, newest first
+	MaxAge           time.Duration // Conversations not updated for longer are deleted
+	MaxSize          int64         // Bytes of data the oldest conversations are deleted to stay under
+}
+
+// PruneResult reports what Prune deleted
+type PruneResult struct {
+	Conversations int   // Conversations deleted
+	Messages      int   // Messages deleted with them
+	Size          int64 // Bytes of data left in the database
+}
+
+// conversationAge is the part of a conversation that decides whether it is kept
+type conversationAge struct {
+	id        string
+	parentID  string // conversation this one branches from, if any
+	updatedAt time.Time
+}
+
+// Prune deletes the conversations that fall outside policy, oldest first. A
+// conversation that a kept branch continues from is kept too, so no branch
+// loses the messages it inherits, and the newest conversation is never
+// deleted to save space. The space freed is reused by new messages; Vacuum
+// returns it to the file system.
+func (s *ConversationStore) Prune(policy RetentionPolicy) (*PruneResult, error) {
+	result := &PruneResult{}
+
+	conversations, err := s.conversationAges()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range expiredConversations(conversations, policy, time.Now()) {
+		if err := s.deleteForRetention(id, result); err != nil {
+			return nil, err
+		}
+	}
+
+	for policy.MaxSize > 0 {
+		size, err := s.UsedSize()
+		if err != nil {
+			return nil, err
+		}
+		if size <= policy.MaxSize {
+			break
+		}
+		if conversations, err = s.conversationAges(); err != nil {
+			return nil, err
+		}
+		id := oldestLeaf(conversations)
+		if id == "" {
+			break
+		}
+		if err := s.deleteForRetention(id, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Size, err = s.UsedSize(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// conversationAges returns every conversation, most recently updated first
+func (s *ConversationStore) conversationAges() ([]conversationAge, error) {
+	query := `
+		SELECT c.id, pm.conversation_id, c.updated_at
+		FROM conversations c
+		LEFT JOIN messages pm ON pm.id = c.parent_message_id
+		ORDER BY c.updated_at DESC, c.id
+	`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("query conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []conversationAge
+	for rows.Next() {
+		var conv conversationAge
+		var parentID sql.NullString
+		if err := rows.Scan(&conv.id, &parentID, &conv.updatedAt); err != nil {
+			return nil, fmt.Errorf("scan conversation: %w", err)
+		}
+		conv.parentID = parentID.String
+		conversations = append(conversations, conv)
+	}
+	return conversations, rows.Err()
+}
+
+// expiredConversations returns, oldest first, the conversations that exceed
+// the count or age limits of policy and that no kept conversation branches
+// from. conversations must be ordered newest first.
+func expiredConversations(conversations []conversationAge, policy RetentionPolicy, now time.Time) []string {
+	parents := make(map[string]string, len(conversations))
+	for _, conv := range conversations {
+		parents[conv.id] = conv.parentID
+	}
+
+	kept := make(map[string]bool, len(conversations))
+	for i, conv := range conversations {
+		if policy.MaxConversations > 0 && i >= policy.MaxConversations {
+			continue
+		}
+		if policy.MaxAge > 0 && now.Sub(conv.updatedAt) > policy.MaxAge {
+			continue
+		}
+		// Keep the conversations this one inherits messages from
+		for id := conv.id; id != "" && !kept[id]; id = parents[id] {
+			kept[id] = true
+		}
+	}
+
+	var expired []string
+	for i := len(conversations) - 1; i >= 0; i-- {
+		if !kept[conversations[i].id] {
+			expired = append(expired, conversations[i].id)
+		}
+	}
+	return expired
+}
+
+// oldestLeaf returns the oldest conversation, other than the newest, that no
+// branch continues from, or "" if there is none
+func oldestLeaf(conversations []conversationAge) string {
+	parents := make(map[string]bool)
+	for _, conv := range conversations {
+		parents[conv.parentID] = true
+	}
+	for i := len(conversations) - 1; i > 0; i-- {
+		if !parents[conversations[i].id] {
+			return conversations[i].id
+		}
+	}
+	return ""
+}
+
+// deleteForRetention deletes a conversation with its messages, embeddings, and
+// attachments, adding them to result. Messages are deleted explicitly so they can be counted.
+func (s *ConversationStore) deleteForRetention(id string, result *PruneResult) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"DELETE FROM message_embeddings WHERE message_id IN (SELECT id FROM messages WHERE conversation_id = ?)", id,
+	); err != nil {
+		return fmt.Errorf("delete embeddings: %w", err)
+	}
+	if _, err := tx.Exec(
+		"DELETE FROM attachments WHERE message_id IN (SELECT id FROM messages WHERE conversation_id = ?)", id,
+	); err != nil {
+		return fmt.Errorf("delete attachments: %w", err)
+	}
+	deleted, err := tx.Exec("DELETE FROM messages WHERE conversation_id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete messages: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM conversations WHERE id = ?", id); err != nil {
+		return fmt.Errorf("delete conversation: %w", err)
+	}
+	if err := deleteUnreferencedBlobs(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	messages, _ := deleted.RowsAffected()
+	result.Conversations++
+	result.Messages += int(messages)
+	return nil
+}
+
+// UsedSize returns the bytes of the database file holding data, leaving out
+// pages freed by deletions
+func (s *ConversationStore) UsedSize() (int64, error) {
+	var size int64
+	if err := s.db.QueryRow(
+		"SELECT (p.page_count - f.freelist_count) * s.page_size FROM pragma_page_count() p, pragma_freelist_count() f, pragma_page_size() s",
+	).Scan(&size); err != nil {
+		return 0, fmt.Errorf("measure database: %w", err)
+	}
+	return size, nil
+}
+
+// FreeSize returns the bytes of the database file freed by deletions, which
+// Vacuum gives back
+func (s *ConversationStore) FreeSize() (int64, error) {
+	var size int64
+	if err := s.db.QueryRow(
+		"SELECT f.freelist_count * s.page_size FROM pragma_freelist_count() f, pragma_page_size() s",
+	).Scan(&size); err != nil {
+		return 0, fmt.Errorf("measure free space: %w", err)
+	}
+	return size, nil
+}
+
+// IntegrityCheck runs SQLite's integrity check and returns the problems it
+// finds, or none when the database is intact
+func (s *ConversationStore) IntegrityCheck() ([]string, error) {
+	rows, err := s.db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("check database integrity: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var problem string
+		if err := rows.Scan(&problem); err != nil {
+			return nil, fmt.Errorf("check database integrity: %w", err)
+		}
+		if problem != "ok" {
+			problems = append(problems, problem)
+		}
+	}
+	return problems, rows.Err()
+}
+
+// Vacuum rebuilds the database file without its free pages and records when
+// it ran
+func (s *ConversationStore) Vacuum() error {
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("vacuum database: %w", err)
+	}
+	if _, err := s.db.Exec(
+		"INSERT OR REPLACE INTO store_metadata (key, value) VALUES (?, ?)",
+		lastVacuumKey, time.Now().UTC().Format(time.RFC3339),
+	); err != nil {
+		return fmt.Errorf("record vacuum: %w", err)
+	}
+	return nil
+}
+
+// VacuumIfDue vacuums the database when it has free pages and at least
+// interval has passed since the last vacuum. It reports whether it ran.
+func (s *ConversationStore) VacuumIfDue(interval time.Duration) (bool, error) {
+	free, err := s.FreeSize()
+	if err != nil || free == 0 {
+		return false, err
+	}
+
+	last, err := s.LastVacuum()
+	if err != nil {
+		return false, err
+	}
+	if !last.IsZero() && time.Since(last) < interval {
+		return false, nil
+	}
+	if err := s.Vacuum(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LastVacuum returns when Vacuum last ran, or the zero time if it never has
+func (s *ConversationStore) LastVacuum() (time.Time, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM store_metadata WHERE key = ?", lastVacuumKey).Scan(&value)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query last vacuum: %w", err)
+	}
+	last, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse last vacuum: %w", err)
+	}
+	return last, nil
+}
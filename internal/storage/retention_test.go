@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ageConversation moves a conversation's last update into the past
+func ageConversation(t *testing.T, store *ConversationStore, id string, age time.Duration) {
+	t.Helper()
+	_, err := store.db.Exec("UPDATE conversations SET updated_at = ? WHERE id = ?", time.Now().Add(-age), id)
+	require.NoError(t, err)
+}
+
+func conversationIDs(t *testing.T, store *ConversationStore) []string {
+	t.Helper()
+	conversations, err := store.ListConversations(100, 0)
+	require.NoError(t, err)
+	var ids []string
+	for _, conv := range conversations {
+		ids = append(ids, conv.ID)
+	}
+	return ids
+}
+
+func TestPrune_MaxConversationsAndAge(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	for i, id := range []string{"oldest", "old", "recent", "newest"} {
+		_, err := store.CreateConversation(id, id)
+		require.NoError(t, err)
+		addMessages(t, store, id, id+" message")
+		ageConversation(t, store, id, time.Duration(4-i)*24*time.Hour)
+	}
+
+	result, err := store.Prune(RetentionPolicy{MaxConversations: 3})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Conversations)
+	assert.Equal(t, 1, result.Messages)
+	assert.Equal(t, []string{"newest", "recent", "old"}, conversationIDs(t, store))
+
+	result, err = store.Prune(RetentionPolicy{MaxAge: 60 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Conversations)
+	assert.Equal(t, []string{"newest", "recent"}, conversationIDs(t, store))
+
+	messages, err := store.SearchMessages("old", 10)
+	require.NoError(t, err)
+	assert.Empty(t, messages, "Messages of pruned conversations are deleted too")
+
+	result, err = store.Prune(RetentionPolicy{})
+	require.NoError(t, err)
+	assert.Zero(t, result.Conversations, "An empty policy deletes nothing")
+}
+
+func TestPrune_KeepsConversationsBranchesContinueFrom(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	_, err := store.CreateConversation("root", "Root")
+	require.NoError(t, err)
+	root := addMessages(t, store, "root", "question", "answer")
+	_, err = store.CreateBranch("branch", "Branch", root[0].ID)
+	require.NoError(t, err)
+	addMessages(t, store, "branch", "rephrased answer")
+	ageConversation(t, store, "root", 48*time.Hour)
+
+	result, err := store.Prune(RetentionPolicy{MaxAge: 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Zero(t, result.Conversations)
+	assert.Equal(t, []string{"question", "rephrased answer"}, threadContents(t, store, "branch"))
+
+	ageConversation(t, store, "branch", 48*time.Hour)
+	result, err = store.Prune(RetentionPolicy{MaxAge: 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Conversations)
+	assert.Empty(t, conversationIDs(t, store))
+}
+
+func TestPrune_MaxSizeAndVacuum(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	content := strings.Repeat("lorem ipsum ", 4000)
+	for i, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		_, err := store.CreateConversation(id, id)
+		require.NoError(t, err)
+		addMessages(t, store, id, content)
+		ageConversation(t, store, id, time.Duration(6-i)*time.Hour)
+	}
+	size, err := store.UsedSize()
+	require.NoError(t, err)
+
+	result, err := store.Prune(RetentionPolicy{MaxSize: size / 2})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, result.Size, size/2)
+	assert.GreaterOrEqual(t, result.Conversations, 3)
+	assert.Equal(t, "f", conversationIDs(t, store)[0], "The newest conversation is kept")
+
+	free, err := store.FreeSize()
+	require.NoError(t, err)
+	assert.Positive(t, free)
+
+	vacuumed, err := store.VacuumIfDue(time.Hour)
+	require.NoError(t, err)
+	assert.True(t, vacuumed)
+	free, err = store.FreeSize()
+	require.NoError(t, err)
+	assert.Zero(t, free)
+	last, err := store.LastVacuum()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), last, time.Minute)
+
+	// Nothing to reclaim, and too soon after the last vacuum
+	vacuumed, err = store.VacuumIfDue(time.Hour)
+	require.NoError(t, err)
+	assert.False(t, vacuumed)
+}
+
+func TestIntegrityCheck(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	_, err := store.CreateConversation("conv", "Conversation")
+	require.NoError(t, err)
+	addMessages(t, store, "conv", "hello")
+
+	problems, err := store.IntegrityCheck()
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
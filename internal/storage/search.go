@@ -14,6 +14,8 @@ type SearchFilter struct {
 	EndDate         *time.Time `json:"end_date"`
 	MessageType     string     `json:"message_type"`     // "user", "assistant", "tool"
 	ConversationID  string     `json:"conversation_id"`
+	Tags            []string   `json:"tags"`             // messages must have every tag
+	Pinned          bool       `json:"pinned"`           // only pinned messages
 	Limit           int        `json:"limit"`
 	Offset          int        `json:"offset"`
 }
@@ -29,9 +31,11 @@ type SearchStatistics struct {
 
 // SearchManager handles conversation and message search operations
 type SearchManager struct {
-	store      ConversationStore
-	db         *sql.DB
-	statistics SearchStatistics
+	store          ConversationStore
+	db             *sql.DB
+	statistics     SearchStatistics
+	embedder       Embedder // Enables semantic search when set
+	embeddingModel string
 }
 
 // NewSearchManager creates a new search manager
@@ -45,7 +49,14 @@ func NewSearchManager(store ConversationStore, db *sql.DB) *SearchManager {
 	}
 }
 
-// SearchMessages performs full-text search on message content with filtering
+// SearchManager returns a search manager over the store's messages
+func (s *ConversationStore) SearchManager() *SearchManager {
+	return NewSearchManager(*s, s.db)
+}
+
+// SearchMessages performs full-text search on message content with filtering,
+// newest first. With the full-text index each word of the query matches as a
+// prefix; otherwise the query must appear as written.
 func (sm *SearchManager) SearchMessages(filter SearchFilter) ([]*Message, error) {
 	start := time.Now()
 	defer func() {
@@ -54,7 +65,7 @@ func (sm *SearchManager) SearchMessages(filter SearchFilter) ([]*Message, error)
 
 	// Build the SQL query
 	query := `
-		SELECT m.id, m.conversation_id, m.role, m.content, m.timestamp
+		SELECT m.id, m.conversation_id, m.role, m.content, m.timestamp, m.tags, m.pinned
 		FROM messages m
 		JOIN conversations c ON m.conversation_id = c.id
 		WHERE 1=1
@@ -63,12 +74,132 @@ func (sm *SearchManager) SearchMessages(filter SearchFilter) ([]*Message, error)
 	argIndex := 1
 
 	// Add search conditions
-	if filter.Query != "" {
+	if terms := ftsQuery(filter.Query); sm.store.fts && terms != "" {
+		query += fmt.Sprintf(" AND m.id IN (SELECT rowid FROM messages_fts WHERE messages_fts MATCH $%d)", argIndex)
+		args = append(args, terms)
+		argIndex++
+	} else if filter.Query != "" {
 		query += fmt.Sprintf(" AND LOWER(m.content) LIKE LOWER($%d)", argIndex)
 		args = append(args, "%"+filter.Query+"%")
 		argIndex++
 	}
 
+	conditions, args, argIndex := filterConditions(filter, args, argIndex)
+	query += conditions
+
+	// Add ordering and pagination
+	query += " ORDER BY m.timestamp DESC"
+	limits, args := pagination(filter, args, argIndex)
+	query += limits
+
+	// Execute query
+	rows, err := sm.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search query: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		message := &Message{}
+		var tags string
+		err := rows.Scan(
+			&message.ID,
+			&message.ConversationID,
+			&message.Role,
+			&message.Content,
+			&message.Timestamp,
+			&tags,
+			&message.Pinned,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		message.Tags = decodeTags(tags)
+		messages = append(messages, message)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// SearchRanked searches message content like SearchMessages but returns the
+// best matches first, each with a snippet around the matched terms. Without
+// the full-text index results are newest first and Rank is zero.
+func (sm *SearchManager) SearchRanked(filter SearchFilter) ([]*SearchResult, error) {
+	terms := ftsQuery(filter.Query)
+	if !sm.store.fts || terms == "" {
+		messages, err := sm.SearchMessages(filter)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]*SearchResult, len(messages))
+		for i, message := range messages {
+			results[i] = &SearchResult{Message: message, Snippet: likeSnippet(message.Content, filter.Query)}
+		}
+		return results, nil
+	}
+
+	start := time.Now()
+	defer func() {
+		sm.updateQueryStats(time.Since(start))
+	}()
+
+	query := fmt.Sprintf(`
+		SELECT m.id, m.conversation_id, m.role, m.content, m.timestamp,
+			snippet(messages_fts, 0, '%s', '%s', '...', %d), bm25(messages_fts)
+		FROM messages_fts
+		JOIN messages m ON m.id = messages_fts.rowid
+		JOIN conversations c ON m.conversation_id = c.id
+		WHERE messages_fts MATCH $1
+	`, SnippetStart, SnippetEnd, snippetTokens)
+	args := []interface{}{terms}
+
+	conditions, args, argIndex := filterConditions(filter, args, 2)
+	query += conditions
+	query += " ORDER BY bm25(messages_fts), m.timestamp DESC"
+	limits, args := pagination(filter, args, argIndex)
+	query += limits
+
+	rows, err := sm.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute ranked search query: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		result := &SearchResult{Message: &Message{}}
+		err := rows.Scan(
+			&result.Message.ID,
+			&result.Message.ConversationID,
+			&result.Message.Role,
+			&result.Message.Content,
+			&result.Message.Timestamp,
+			&result.Snippet,
+			&result.Rank,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// filterConditions appends the date, role, conversation, tag, and pin criteria
+// of filter to a query whose next placeholder is $argIndex
+func filterConditions(filter SearchFilter, args []interface{}, argIndex int) (string, []interface{}, int) {
+	var query string
+
 	if filter.StartDate != nil {
 		query += fmt.Sprintf(" AND m.timestamp >= $%d", argIndex)
 		args = append(args, *filter.StartDate)
@@ -93,9 +224,23 @@ func (sm *SearchManager) SearchMessages(filter SearchFilter) ([]*Message, error)
 		argIndex++
 	}
 
-	// Add ordering and pagination
-	query += " ORDER BY m.timestamp DESC"
-	
+	for _, tag := range filter.Tags {
+		query += fmt.Sprintf(" AND instr(m.tags, $%d) > 0", argIndex)
+		args = append(args, ","+NormalizeTag(tag)+",")
+		argIndex++
+	}
+
+	if filter.Pinned {
+		query += " AND m.pinned = 1"
+	}
+
+	return query, args, argIndex
+}
+
+// pagination returns the LIMIT and OFFSET clauses for filter
+func pagination(filter SearchFilter, args []interface{}, argIndex int) (string, []interface{}) {
+	var query string
+
 	if filter.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT $%d", argIndex)
 		args = append(args, filter.Limit)
@@ -107,34 +252,7 @@ func (sm *SearchManager) SearchMessages(filter SearchFilter) ([]*Message, error)
 		args = append(args, filter.Offset)
 	}
 
-	// Execute query
-	rows, err := sm.db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute search query: %w", err)
-	}
-	defer rows.Close()
-
-	var messages []*Message
-	for rows.Next() {
-		message := &Message{}
-		err := rows.Scan(
-			&message.ID,
-			&message.ConversationID,
-			&message.Role,
-			&message.Content,
-			&message.Timestamp,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan message: %w", err)
-		}
-		messages = append(messages, message)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over messages: %w", err)
-	}
-
-	return messages, nil
+	return query, args
 }
 
 // SearchConversations searches conversation titles and returns matching conversations
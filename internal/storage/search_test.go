@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
 	"time"
@@ -386,4 +387,57 @@ func TestSearchManager_GetSearchStatistics(t *testing.T) {
 // Helper function to create time pointers
 func timePtr(t time.Time) *time.Time {
 	return &t
-}
\ No newline at end of file
+}
+// benchmarkWords make up the messages of the search benchmarks. Each message
+// holds four of them, so common words match many messages and the
+// numbered ones match few.
+var benchmarkWords = []string{"deploy", "kubernetes", "invoice", "python", "backup", "latency", "schema", "rollback"}
+
+// newLargeStore returns a store of conversations conversations with
+// perConversation messages each
+func newLargeStore(b *testing.B, conversations, perConversation int) *ConversationStore {
+	b.Helper()
+	store, err := NewConversationStore(filepath.Join(b.TempDir(), "bench.db"))
+	require.NoError(b, err)
+	b.Cleanup(func() { store.Close() })
+
+	tx, err := store.db.Begin()
+	require.NoError(b, err)
+	for c := 0; c < conversations; c++ {
+		id := fmt.Sprintf("conv_%d", c)
+		_, err := tx.Exec("INSERT INTO conversations (id, title, created_at, updated_at) VALUES (?, ?, ?, ?)", id, id, time.Now(), time.Now())
+		require.NoError(b, err)
+		for m := 0; m < perConversation; m++ {
+			n := c*perConversation + m
+			content := fmt.Sprintf("Message %d about %s and %s, then %s with %s ticket%d",
+				n, benchmarkWords[n%8], benchmarkWords[n/8%8], benchmarkWords[n/64%8], benchmarkWords[n/512%8], n%1000)
+			require.NoError(b, insertMessage(tx, &Message{ConversationID: id, Role: "user", Content: content, Timestamp: time.Now()}))
+		}
+	}
+	require.NoError(b, tx.Commit())
+	return store
+}
+
+func BenchmarkSearchMessages_100kMessages(b *testing.B) {
+	store := newLargeStore(b, 100, 1000)
+	search := store.SearchManager()
+
+	for _, query := range []string{"deploy", "python rollback", "ticket42", "nothing-matches"} {
+		b.Run("store/"+query, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := store.SearchMessages(query, 20); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run("filtered/"+query, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := search.SearchMessages(SearchFilter{Query: query, MessageType: "user", Limit: 20}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
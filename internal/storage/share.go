@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/secrets"
+)
+
+// ShareDir is the directory, in the data directory, shared transcripts are
+// written to
+const ShareDir = "shares"
+
+// shareRedaction is a kind of secret masked in shared transcripts, which
+// Redact may not know of because it was pasted into the chat or returned by
+// a tool
+type shareRedaction struct {
+	pattern     *regexp.Regexp
+	replacement string // Template of what replaces a match; the secret itself is masked
+}
+
+// shareRedactions are what SanitizeForSharing masks beside registered secrets
+var shareRedactions = []shareRedaction{
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), secrets.Mask},
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`), secrets.Mask},
+	{regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{30,}|github_pat_[A-Za-z0-9_]{30,})`), secrets.Mask},
+	{regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`), secrets.Mask},
+	{regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`), secrets.Mask},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), secrets.Mask},
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]{16,}`), "${1} " + secrets.Mask},
+	{regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`), "${1}" + secrets.Mask + "@"},
+	{regexp.MustCompile(`(?i)\b((?:password|passwd|secret|api[_-]?key|access[_-]?key|auth[_-]?token|token)["']?\s*[:=]\s*)["']?[^\s"',;]+["']?`), "${1}" + secrets.Mask},
+}
+
+// SanitizeForSharing returns copies of messages fit to share with others.
+// Registered secrets, text shaped like API keys, tokens, and private keys,
+// and the values of password-like settings are masked in the content, tool
+// arguments, and tool results, and homeDir is shown as ~.
+func SanitizeForSharing(messages []*Message, homeDir string) []*Message {
+	sanitized := make([]*Message, 0, len(messages))
+	for _, msg := range messages {
+		copied := *msg
+		copied.Content = sanitizeText(msg.Content, homeDir)
+		if msg.ToolCall != nil {
+			call := *msg.ToolCall
+			call.Arguments, _ = sanitizeValue(msg.ToolCall.Arguments, homeDir).(map[string]interface{})
+			copied.ToolCall = &call
+		}
+		if msg.ToolResult != nil {
+			result := *msg.ToolResult
+			result.Content = sanitizeText(msg.ToolResult.Content, homeDir)
+			copied.ToolResult = &result
+		}
+		sanitized = append(sanitized, &copied)
+	}
+	return sanitized
+}
+
+// sanitizeText masks the secrets in text and shows homeDir as ~
+func sanitizeText(text, homeDir string) string {
+	text = secrets.Redact(text)
+	for _, redaction := range shareRedactions {
+		text = redaction.pattern.ReplaceAllString(text, redaction.replacement)
+	}
+	if len(homeDir) > 1 {
+		text = strings.ReplaceAll(text, homeDir, "~")
+	}
+	return text
+}
+
+// sanitizeValue sanitizes the strings in a tool argument, however deeply
+// they are nested
+func sanitizeValue(value interface{}, homeDir string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return sanitizeText(v, homeDir)
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		sanitized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			sanitized[key] = sanitizeValue(item, homeDir)
+		}
+		return sanitized
+	case []interface{}:
+		sanitized := make([]interface{}, len(v))
+		for i, item := range v {
+			sanitized[i] = sanitizeValue(item, homeDir)
+		}
+		return sanitized
+	}
+	return value
+}
+
+// ShareTranscript renders messages, sanitized for sharing, as a Markdown
+// transcript
+func ShareTranscript(conv *Conversation, messages []*Message, homeDir string) ([]byte, error) {
+	if conv != nil {
+		titled := *conv
+		titled.Title = sanitizeText(conv.Title, homeDir)
+		conv = &titled
+	}
+	var b bytes.Buffer
+	if err := (markdownExporter{}).Export(&b, conv, SanitizeForSharing(messages, homeDir)); err != nil {
+		return nil, fmt.Errorf("render transcript: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// SharePath returns a timestamped path for a shared transcript in the data
+// directory
+func SharePath(dataDir string, now time.Time) string {
+	return filepath.Join(dataDir, ShareDir, "othello-share-"+now.Format("20060102-150405")+ExportMarkdown.Extension())
+}
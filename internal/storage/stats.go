@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConversationStats summarizes how a conversation used the model and tools
+type ConversationStats struct {
+	ConversationID string        `json:"conversation_id"`
+	Title          string        `json:"title"`
+	Messages       int           `json:"messages"`
+	TokensIn       int           `json:"tokens_in"`  // prompt tokens sent to the model
+	TokensOut      int           `json:"tokens_out"` // tokens the model generated
+	ModelCalls     int           `json:"model_calls"`
+	ModelLatency   time.Duration `json:"model_latency"` // total time waiting for the model
+	ToolCalls      int           `json:"tool_calls"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// AverageLatency returns the mean time the model took to respond, or zero
+// before the first call
+func (cs *ConversationStats) AverageLatency() time.Duration {
+	if cs.ModelCalls == 0 {
+		return 0
+	}
+	return cs.ModelLatency / time.Duration(cs.ModelCalls)
+}
+
+// ToolStats summarizes how often a tool was called and the tokens spent on
+// the model calls that called it
+type ToolStats struct {
+	Tool      string `json:"tool"`
+	Calls     int    `json:"calls"`
+	TokensIn  int    `json:"tokens_in"`
+	TokensOut int    `json:"tokens_out"`
+}
+
+// StatsFilter narrows ListConversationStatistics; zero values match everything
+type StatsFilter struct {
+	Since time.Time // only conversations updated since then
+	Limit int       // most recently updated conversations returned; 0 returns all
+}
+
+// RecordModelCall adds a model response to the statistics of a conversation
+func (s *ConversationStore) RecordModelCall(conversationID string, tokensIn, tokensOut int, latency time.Duration) error {
+	if _, err := s.stmts.Exec(`
+		UPDATE conversations
+		SET prompt_tokens = prompt_tokens + ?, completion_tokens = completion_tokens + ?,
+			model_calls = model_calls + 1, model_latency_ms = model_latency_ms + ?
+		WHERE id = ?
+	`, tokensIn, tokensOut, latency.Milliseconds(), conversationID); err != nil {
+		return fmt.Errorf("record model call: %w", err)
+	}
+	return nil
+}
+
+// RecordToolCalls adds count tool calls to the statistics of a conversation
+func (s *ConversationStore) RecordToolCalls(conversationID string, count int) error {
+	if _, err := s.stmts.Exec(
+		"UPDATE conversations SET tool_calls = tool_calls + ? WHERE id = ?", count, conversationID,
+	); err != nil {
+		return fmt.Errorf("record tool calls: %w", err)
+	}
+	return nil
+}
+
+// RecordToolUsage adds calls to the tools named in tools, one per name, to the
+// statistics of a conversation. The tokens of the model call that made them
+// are split evenly between the calls, the first taking any remainder.
+func (s *ConversationStore) RecordToolUsage(conversationID string, tools []string, tokensIn, tokensOut int) error {
+	if len(tools) == 0 {
+		return nil
+	}
+	n := len(tools)
+	for i, tool := range tools {
+		in, out := tokensIn/n, tokensOut/n
+		if i == 0 {
+			in += tokensIn % n
+			out += tokensOut % n
+		}
+		if _, err := s.stmts.Exec(`
+			INSERT INTO tool_usage (conversation_id, tool, calls, prompt_tokens, completion_tokens)
+			VALUES (?, ?, 1, ?, ?)
+			ON CONFLICT (conversation_id, tool) DO UPDATE SET
+				calls = calls + 1, prompt_tokens = prompt_tokens + excluded.prompt_tokens,
+				completion_tokens = completion_tokens + excluded.completion_tokens
+		`, conversationID, tool, in, out); err != nil {
+			return fmt.Errorf("record tool usage: %w", err)
+		}
+	}
+	return nil
+}
+
+// statsColumns lists the columns read by scanStats
+const statsColumns = "id, title, message_count, prompt_tokens, completion_tokens, model_calls, model_latency_ms, tool_calls, updated_at"
+
+// scanStats reads a conversations row selected with statsColumns
+func scanStats(row interface{ Scan(...interface{}) error }) (*ConversationStats, error) {
+	stats := &ConversationStats{}
+	var latencyMS int64
+	if err := row.Scan(&stats.ConversationID, &stats.Title, &stats.Messages, &stats.TokensIn, &stats.TokensOut,
+		&stats.ModelCalls, &latencyMS, &stats.ToolCalls, &stats.UpdatedAt); err != nil {
+		return nil, err
+	}
+	stats.ModelLatency = time.Duration(latencyMS) * time.Millisecond
+	return stats, nil
+}
+
+// ConversationStatistics returns the statistics of one conversation, or nil
+// if there is no such conversation
+func (sm *SearchManager) ConversationStatistics(conversationID string) (*ConversationStats, error) {
+	stats, err := scanStats(sm.db.QueryRow("SELECT "+statsColumns+" FROM conversations WHERE id = ?", conversationID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query conversation statistics: %w", err)
+	}
+	return stats, nil
+}
+
+// ListConversationStatistics returns the statistics of the conversations
+// matching filter, most recently updated first
+func (sm *SearchManager) ListConversationStatistics(filter StatsFilter) ([]*ConversationStats, error) {
+	query := "SELECT " + statsColumns + " FROM conversations"
+	var args []interface{}
+	if !filter.Since.IsZero() {
+		query += " WHERE updated_at >= ?"
+		args = append(args, filter.Since)
+	}
+	query += " ORDER BY updated_at DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := sm.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query conversation statistics: %w", err)
+	}
+	defer rows.Close()
+
+	var all []*ConversationStats
+	for rows.Next() {
+		stats, err := scanStats(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan conversation statistics: %w", err)
+		}
+		all = append(all, stats)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate conversation statistics: %w", err)
+	}
+	return all, nil
+}
+
+// TotalStatistics adds up the statistics of several conversations
+func TotalStatistics(all []*ConversationStats) ConversationStats {
+	var total ConversationStats
+	for _, stats := range all {
+		total.Messages += stats.Messages
+		total.TokensIn += stats.TokensIn
+		total.TokensOut += stats.TokensOut
+		total.ModelCalls += stats.ModelCalls
+		total.ModelLatency += stats.ModelLatency
+		total.ToolCalls += stats.ToolCalls
+		if stats.UpdatedAt.After(total.UpdatedAt) {
+			total.UpdatedAt = stats.UpdatedAt
+		}
+	}
+	return total
+}
+
+// ToolStatistics returns the statistics of each tool called in the given
+// conversations, or in all of them when conversationIDs is empty, most
+// tokens first
+func (sm *SearchManager) ToolStatistics(conversationIDs []string) ([]*ToolStats, error) {
+	query := "SELECT tool, SUM(calls), SUM(prompt_tokens), SUM(completion_tokens) FROM tool_usage"
+	var args []interface{}
+	if len(conversationIDs) > 0 {
+		query += " WHERE conversation_id IN (?" + strings.Repeat(", ?", len(conversationIDs)-1) + ")"
+		for _, id := range conversationIDs {
+			args = append(args, id)
+		}
+	}
+	query += " GROUP BY tool ORDER BY SUM(prompt_tokens) + SUM(completion_tokens) DESC, tool"
+
+	rows, err := sm.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query tool statistics: %w", err)
+	}
+	defer rows.Close()
+
+	var all []*ToolStats
+	for rows.Next() {
+		stats := &ToolStats{}
+		if err := rows.Scan(&stats.Tool, &stats.Calls, &stats.TokensIn, &stats.TokensOut); err != nil {
+			return nil, fmt.Errorf("scan tool statistics: %w", err)
+		}
+		all = append(all, stats)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tool statistics: %w", err)
+	}
+	return all, nil
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordModelCallAndToolCalls(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+	_, err := store.CreateConversation("conv", "Planning")
+	require.NoError(t, err)
+	addTestMessage(t, store, "conv")
+
+	require.NoError(t, store.RecordModelCall("conv", 120, 30, 800*time.Millisecond))
+	require.NoError(t, store.RecordModelCall("conv", 200, 50, 1200*time.Millisecond))
+	require.NoError(t, store.RecordToolCalls("conv", 3))
+
+	stats, err := store.SearchManager().ConversationStatistics("conv")
+	require.NoError(t, err)
+	require.NotNil(t, stats)
+	assert.Equal(t, "Planning", stats.Title)
+	assert.Equal(t, 1, stats.Messages)
+	assert.Equal(t, 320, stats.TokensIn)
+	assert.Equal(t, 80, stats.TokensOut)
+	assert.Equal(t, 2, stats.ModelCalls)
+	assert.Equal(t, 2*time.Second, stats.ModelLatency)
+	assert.Equal(t, time.Second, stats.AverageLatency())
+	assert.Equal(t, 3, stats.ToolCalls)
+
+	missing, err := store.SearchManager().ConversationStatistics("missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestListConversationStatistics(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+	for _, id := range []string{"old", "new"} {
+		_, err := store.CreateConversation(id, id)
+		require.NoError(t, err)
+	}
+	_, err := store.db.Exec("UPDATE conversations SET updated_at = ? WHERE id = 'old'", time.Now().Add(-48*time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, store.RecordModelCall("old", 10, 5, time.Second))
+	require.NoError(t, store.RecordModelCall("new", 20, 10, 3*time.Second))
+	require.NoError(t, store.RecordToolCalls("new", 2))
+
+	all, err := store.SearchManager().ListConversationStatistics(StatsFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "new", all[0].ConversationID, "Most recently updated first")
+
+	recent, err := store.SearchManager().ListConversationStatistics(StatsFilter{Since: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, "new", recent[0].ConversationID)
+
+	limited, err := store.SearchManager().ListConversationStatistics(StatsFilter{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+
+	total := TotalStatistics(all)
+	assert.Equal(t, 30, total.TokensIn)
+	assert.Equal(t, 15, total.TokensOut)
+	assert.Equal(t, 2, total.ModelCalls)
+	assert.Equal(t, 2*time.Second, total.AverageLatency())
+	assert.Equal(t, 2, total.ToolCalls)
+}
+
+func TestRecordToolUsage(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+	for _, id := range []string{"first", "second"} {
+		_, err := store.CreateConversation(id, id)
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.RecordToolUsage("first", []string{"search", "read_file", "search"}, 100, 31))
+	require.NoError(t, store.RecordToolUsage("second", []string{"read_file"}, 40, 10))
+	require.NoError(t, store.RecordToolUsage("second", nil, 500, 500))
+
+	all, err := store.SearchManager().ToolStatistics(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*ToolStats{
+		{Tool: "read_file", Calls: 2, TokensIn: 73, TokensOut: 20},
+		{Tool: "search", Calls: 2, TokensIn: 67, TokensOut: 21},
+	}, all, "Tokens are split between the calls, the first taking the remainder; most tokens first")
+
+	second, err := store.SearchManager().ToolStatistics([]string{"second"})
+	require.NoError(t, err)
+	assert.Equal(t, []*ToolStats{{Tool: "read_file", Calls: 1, TokensIn: 40, TokensOut: 10}}, second)
+}
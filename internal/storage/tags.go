@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// NormalizeTag returns tag as it is stored: lower case, without a leading #,
+// and with spaces and commas replaced by hyphens
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	return strings.Join(strings.FieldsFunc(tag, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}), "-")
+}
+
+// encodeTags stores tags comma-separated with a comma at each end, so a tag
+// can be matched as ",tag,"
+func encodeTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "," + strings.Join(tags, ",") + ","
+}
+
+// decodeTags reverses encodeTags
+func decodeTags(encoded string) []string {
+	encoded = strings.Trim(encoded, ",")
+	if encoded == "" {
+		return nil
+	}
+	return strings.Split(encoded, ",")
+}
+
+// TagMessage adds tag to a message and returns its tags
+func (s *ConversationStore) TagMessage(messageID int64, tag string) ([]string, error) {
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return nil, fmt.Errorf("tag cannot be empty")
+	}
+	return s.updateTags(messageID, func(tags []string) []string {
+		for _, existing := range tags {
+			if existing == tag {
+				return tags
+			}
+		}
+		return append(tags, tag)
+	})
+}
+
+// UntagMessage removes tag from a message and returns its remaining tags
+func (s *ConversationStore) UntagMessage(messageID int64, tag string) ([]string, error) {
+	tag = NormalizeTag(tag)
+	return s.updateTags(messageID, func(tags []string) []string {
+		var kept []string
+		for _, existing := range tags {
+			if existing != tag {
+				kept = append(kept, existing)
+			}
+		}
+		return kept
+	})
+}
+
+// updateTags replaces the tags of a message with update's result
+func (s *ConversationStore) updateTags(messageID int64, update func([]string) []string) ([]string, error) {
+	var encoded string
+	if err := s.db.QueryRow("SELECT tags FROM messages WHERE id = ?", messageID).Scan(&encoded); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message %d not found", messageID)
+		}
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+
+	tags := update(decodeTags(encoded))
+	if _, err := s.db.Exec("UPDATE messages SET tags = ? WHERE id = ?", encodeTags(tags), messageID); err != nil {
+		return nil, fmt.Errorf("update tags: %w", err)
+	}
+	return tags, nil
+}
+
+// SetMessagePinned pins or unpins a message
+func (s *ConversationStore) SetMessagePinned(messageID int64, pinned bool) error {
+	result, err := s.db.Exec("UPDATE messages SET pinned = ? WHERE id = ?", pinned, messageID)
+	if err != nil {
+		return fmt.Errorf("update pinned: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("message %d not found", messageID)
+	}
+	return nil
+}
+
+// PinnedMessages returns up to limit pinned messages from every conversation,
+// newest first
+func (s *ConversationStore) PinnedMessages(limit int) ([]*Message, error) {
+	return s.SearchManager().SearchMessages(SearchFilter{Pinned: true, Limit: limit})
+}
+
+// ParseSearchQuery reads search text into a filter. Words written #tag
+// require that tag and is:pinned requires a pinned message; the rest is the
+// text to search for.
+func ParseSearchQuery(text string) SearchFilter {
+	var filter SearchFilter
+	var words []string
+	for _, word := range strings.Fields(text) {
+		switch {
+		case strings.EqualFold(word, "is:pinned"):
+			filter.Pinned = true
+		case len(word) > 1 && strings.HasPrefix(word, "#"):
+			filter.Tags = append(filter.Tags, NormalizeTag(word))
+		default:
+			words = append(words, word)
+		}
+	}
+	filter.Query = strings.Join(words, " ")
+	return filter
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	assert.Equal(t, "important", NormalizeTag("#Important"))
+	assert.Equal(t, "follow-up", NormalizeTag(" follow up "))
+	assert.Equal(t, "a-b", NormalizeTag("a,b"))
+	assert.Empty(t, NormalizeTag("#"))
+}
+
+func TestTagAndPinMessages(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	_, err := store.CreateConversation("conv", "Deploys")
+	require.NoError(t, err)
+	messages := addMessages(t, store, "conv", "deploy on fridays is banned", "deploy with make", "unrelated")
+
+	tags, err := store.TagMessage(messages[0].ID, "#Rules")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rules"}, tags)
+	_, err = store.TagMessage(messages[0].ID, "important")
+	require.NoError(t, err)
+	tags, err = store.TagMessage(messages[0].ID, "rules")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rules", "important"}, tags, "A tag is only added once")
+	_, err = store.TagMessage(messages[1].ID, "important")
+	require.NoError(t, err)
+
+	_, err = store.TagMessage(messages[0].ID, " ")
+	assert.Error(t, err)
+	_, err = store.TagMessage(999, "rules")
+	assert.Error(t, err)
+
+	require.NoError(t, store.SetMessagePinned(messages[1].ID, true))
+	assert.Error(t, store.SetMessagePinned(999, true))
+
+	saved, err := store.GetMessages("conv", -1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rules", "important"}, saved[0].Tags)
+	assert.False(t, saved[0].Pinned)
+	assert.True(t, saved[1].Pinned)
+	assert.Empty(t, saved[2].Tags)
+
+	search := store.SearchManager()
+	found, err := search.SearchMessages(SearchFilter{Tags: []string{"important"}})
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+	found, err = search.SearchMessages(SearchFilter{Tags: []string{"important", "#rules"}})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, messages[0].ID, found[0].ID)
+
+	pinned, err := store.PinnedMessages(10)
+	require.NoError(t, err)
+	require.Len(t, pinned, 1)
+	assert.Equal(t, "deploy with make", pinned[0].Content)
+	assert.True(t, pinned[0].Pinned)
+
+	tags, err = store.UntagMessage(messages[0].ID, "rules")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"important"}, tags)
+	require.NoError(t, store.SetMessagePinned(messages[1].ID, false))
+	pinned, err = store.PinnedMessages(10)
+	require.NoError(t, err)
+	assert.Empty(t, pinned)
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	filter := ParseSearchQuery("deploy #Rules is:pinned fridays #")
+	assert.Equal(t, "deploy fridays #", filter.Query)
+	assert.Equal(t, []string{"rules"}, filter.Tags)
+	assert.True(t, filter.Pinned)
+
+	assert.Equal(t, SearchFilter{Query: "plain text"}, ParseSearchQuery("plain  text"))
+}
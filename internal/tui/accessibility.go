@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// WithAccessibility adapts caps for screen readers: the TUI renders inline
+// instead of on the alternate screen, so announcements stay in the terminal's
+// scrollback, and output is limited to ASCII.
+func WithAccessibility(caps TerminalCapabilities) TerminalCapabilities {
+	caps.Accessible = true
+	caps.AltScreen = false
+	caps.Unicode = false
+	return caps
+}
+
+// accessibleStyles returns high-contrast styles. Text uses the terminal's own
+// foreground color, emphasis comes from bold, underline, and reverse video
+// rather than subtle colors, and borders are hidden so screen readers don't
+// read out box-drawing characters.
+func accessibleStyles() Styles {
+	reverse := lipgloss.NewStyle().Reverse(true).Bold(true)
+	return Styles{
+		Base:           lipgloss.NewStyle().Padding(0, 1),
+		StatusBar:      reverse.Padding(0, 1),
+		ViewHeader:     reverse.Padding(0, 1),
+		MessageUser:    lipgloss.NewStyle().Bold(true),
+		MessageBot:     lipgloss.NewStyle(),
+		MessageTool:    lipgloss.NewStyle().Underline(true),
+		InputBox:       lipgloss.NewStyle().Border(lipgloss.HiddenBorder()).Padding(0, 1),
+		InputPrompt:    lipgloss.NewStyle().Bold(true),
+		ServerList:     lipgloss.NewStyle().Border(lipgloss.HiddenBorder()).Padding(1),
+		ServerItem:     lipgloss.NewStyle().PaddingLeft(2),
+		ErrorStyle:     lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true).Underline(true),
+		SuccessStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true),
+		DimmedStyle:    lipgloss.NewStyle(),
+		HighlightStyle: reverse,
+		Toast:          reverse.Padding(0, 1),
+		ActivityPane:   lipgloss.NewStyle().Border(lipgloss.HiddenBorder(), false, false, false, true).PaddingLeft(1),
+	}
+}
+
+// announcer turns state changes into plain text lines that are printed above
+// the inline TUI, giving screen readers a linear transcript to follow
+type announcer struct {
+	messages int      // chat messages already announced
+	view     ViewType // last announced view
+	progress string   // last announced progress of a pending response
+	pending  []string // notifications queued since the last update
+}
+
+// notify queues a toast notification for announcement
+func (n *announcer) notify(t ToastMsg) {
+	var prefix string
+	switch t.Level {
+	case ToastSuccess:
+		prefix = "Done: "
+	case ToastWarning:
+		prefix = "Warning: "
+	case ToastError:
+		prefix = "Error: "
+	default:
+		prefix = "Notice: "
+	}
+	n.pending = append(n.pending, prefix+t.Text)
+}
+
+// collect returns the announcements for everything that changed in a since
+// the last call
+func (n *announcer) collect(a *Application) []string {
+	lines := n.pending
+	n.pending = nil
+
+	if a.currentView != n.view {
+		n.view = a.currentView
+		lines = append(lines, "View: "+a.currentView.String())
+	}
+
+	if chat := a.chatView; chat != nil {
+		if len(chat.messages) < n.messages {
+			// The conversation was cleared or branched
+			n.messages = len(chat.messages)
+		}
+		for _, msg := range chat.messages[n.messages:] {
+			if line := describeMessage(msg); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		n.messages = len(chat.messages)
+
+		var progress string
+		if chat.waitingForResponse {
+			progress = chat.progressText()
+		}
+		if progress != "" && progress != n.progress {
+			lines = append(lines, progress+"...")
+		}
+		n.progress = progress
+	}
+
+	for i, line := range lines {
+		lines[i] = toASCII(line)
+	}
+	return lines
+}
+
+// describeMessage returns the announcement for a chat message. The user's own
+// messages are not repeated back.
+func describeMessage(msg ChatMessage) string {
+	var parts []string
+	switch msg.Role {
+	case "user":
+		return ""
+	case "tool":
+		parts = append(parts, "Tool: "+msg.Content)
+	default:
+		if msg.Content != "" {
+			parts = append(parts, "Othello: "+msg.Content)
+		}
+	}
+	if msg.Error != "" {
+		parts = append(parts, "Error: "+msg.Error)
+	}
+	return strings.Join(parts, "\n")
+}
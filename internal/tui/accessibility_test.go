@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessibleApp() *Application {
+	caps := WithAccessibility(FullCapabilities())
+	app := NewApplication(nil)
+	app.styles = StylesForCapabilities(DefaultStyles(), caps)
+	app.SetCapabilities(caps)
+	return app
+}
+
+func TestWithAccessibility(t *testing.T) {
+	caps := WithAccessibility(FullCapabilities())
+	assert.True(t, caps.Accessible)
+	assert.False(t, caps.AltScreen)
+	assert.False(t, caps.Unicode)
+	assert.Equal(t, termenv.TrueColor, caps.Color)
+}
+
+func TestDetectTerminalCapabilities_HonorsNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	assert.Equal(t, termenv.Ascii, DetectTerminalCapabilities(RenderModeFull).Color)
+}
+
+func TestAccessibleStyles_HideBorders(t *testing.T) {
+	styles := StylesForCapabilities(DefaultStyles(), WithAccessibility(FullCapabilities()))
+
+	box := styles.InputBox.Render("hello")
+	assert.Equal(t, 3, renderedHeight(box), "hidden borders keep the layout height")
+	assert.NotContains(t, box, "│")
+	assert.NotContains(t, box, "|")
+	assert.NotContains(t, box, "+")
+}
+
+func TestApplication_AccessibleRendering(t *testing.T) {
+	app := newAccessibleApp()
+	app.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	view := app.View()
+	for _, r := range view {
+		if r >= 0x80 {
+			t.Fatalf("accessible rendering produced non-ASCII rune %q in:\n%s", r, view)
+		}
+	}
+}
+
+func TestAnnouncer_AnnouncesStateChanges(t *testing.T) {
+	app := newAccessibleApp()
+	require.NotNil(t, app.announcer)
+
+	// The welcome message is announced on the first update
+	lines := app.announcer.collect(app)
+	require.Len(t, lines, 1)
+	assert.True(t, strings.HasPrefix(lines[0], "Othello: Welcome to Othello AI Agent!"))
+	assert.Empty(t, app.announcer.collect(app))
+
+	app.chatView.AddMessage(ChatMessage{Role: "user", Content: "hello"})
+	app.chatView.waitingForResponse = true
+	assert.Equal(t, []string{"Waiting for a response..."}, app.announcer.collect(app))
+
+	app.chatView.waitingForResponse = false
+	app.chatView.AddMessage(ChatMessage{Role: "tool", Content: "Executing tool: search..."})
+	app.chatView.AddMessage(ChatMessage{Role: "assistant", Content: "Found 3 notes ✓"})
+	app.chatView.AddMessage(ChatMessage{Role: "assistant", Error: "connection refused"})
+	assert.Equal(t, []string{
+		"Tool: Executing tool: search...",
+		"Othello: Found 3 notes [ok]",
+		"Error: connection refused",
+	}, app.announcer.collect(app))
+
+	app.currentView = ServerViewType
+	app.pushToast(ToastMsg{Text: "Server memory connected", Level: ToastSuccess})
+	assert.Equal(t, []string{"Done: Server memory connected", "View: Servers"}, app.announcer.collect(app))
+}
+
+func TestAnnouncer_HandlesTruncatedConversation(t *testing.T) {
+	app := newAccessibleApp()
+	app.announcer.collect(app)
+
+	app.chatView.ClearMessages()
+	assert.Empty(t, app.announcer.collect(app))
+
+	app.chatView.AddMessage(ChatMessage{Role: "assistant", Content: "fresh start"})
+	assert.Equal(t, []string{"Othello: fresh start"}, app.announcer.collect(app))
+}
+
+func TestApplication_NoAnnouncerByDefault(t *testing.T) {
+	app := NewApplication(nil)
+	app.SetCapabilities(FullCapabilities())
+	assert.Nil(t, app.announcer)
+}
+
+// renderedHeight counts the rendered lines of s
+func renderedHeight(s string) int {
+	return strings.Count(s, "\n") + 1
+}
@@ -0,0 +1,227 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+)
+
+// maxActivityEntries bounds the activity pane's memory use
+const maxActivityEntries = 500
+
+// ActivityKind categorizes entries in the activity pane
+type ActivityKind int
+
+const (
+	ActivityTool ActivityKind = iota
+	ActivityResult
+	ActivityServer
+	ActivityMetadata
+	ActivityLog
+	ActivityOutput
+)
+
+// ActivityEntry is a single line in the activity pane
+type ActivityEntry struct {
+	Time   time.Time
+	Kind   ActivityKind
+	Text   string
+	Failed bool
+}
+
+// ActivityPane streams tool executions, server events, and agent logs
+// alongside the conversation in the split layout
+type ActivityPane struct {
+	width    int
+	height   int
+	styles   Styles
+	viewport viewport.Model
+	entries  []ActivityEntry
+	now      func() time.Time
+}
+
+// NewActivityPane creates an empty activity pane
+func NewActivityPane(styles Styles) *ActivityPane {
+	return &ActivityPane{
+		styles:   styles,
+		viewport: viewport.New(0, 0),
+		now:      time.Now,
+	}
+}
+
+// Record appends an entry and keeps the pane scrolled to the latest activity
+// unless the user has scrolled back
+func (p *ActivityPane) Record(kind ActivityKind, failed bool, format string, args ...interface{}) {
+	p.entries = append(p.entries, ActivityEntry{
+		Time:   p.now(),
+		Kind:   kind,
+		Text:   fmt.Sprintf(format, args...),
+		Failed: failed,
+	})
+	if len(p.entries) > maxActivityEntries {
+		p.entries = p.entries[len(p.entries)-maxActivityEntries:]
+	}
+
+	follow := p.viewport.AtBottom()
+	p.viewport.SetContent(p.renderEntries())
+	if follow {
+		p.viewport.GotoBottom()
+	}
+}
+
+// Entries returns the recorded activity, oldest first
+func (p *ActivityPane) Entries() []ActivityEntry {
+	return p.entries
+}
+
+// Observe records the activity carried by msg, if any
+func (p *ActivityPane) Observe(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case ToolCallDetectedMsg:
+		for _, call := range msg.ToolCalls {
+			p.Record(ActivityTool, false, "→ %s %s", call.Name, formatActivityArgs(call.Arguments))
+		}
+
+	case MCPToolExecutingMsg:
+		p.Record(ActivityTool, false, "→ %s %s", msg.ToolName, formatActivityArgs(msg.Params))
+
+	case MCPToolExecutedMsg:
+		switch {
+		case msg.Error != nil:
+			p.Record(ActivityResult, true, "✗ %s: %v", msg.ToolName, msg.Error)
+		case msg.Result != nil && msg.Result.Result != nil && msg.Result.Result.IsError:
+			p.Record(ActivityResult, true, "✗ %s returned an error", msg.ToolName)
+		case msg.Result != nil && msg.Result.Duration != "":
+			p.Record(ActivityResult, false, "✓ %s (%s)", msg.ToolName, msg.Result.Duration)
+		default:
+			p.Record(ActivityResult, false, "✓ %s", msg.ToolName)
+		}
+
+	case events.ToolExecutedEvent:
+		if msg.Success {
+			p.Record(ActivityResult, false, "✓ %s (%s)", msg.Tool, msg.Duration.Round(time.Millisecond))
+		} else {
+			p.Record(ActivityResult, true, "✗ %s: %s", msg.Tool, msg.Error)
+		}
+
+	case events.ToolOutputEvent:
+		// Streamed output is shown line by line as it arrives
+		for _, line := range strings.Split(strings.TrimRight(msg.Text, "\n"), "\n") {
+			p.Record(ActivityOutput, false, "%s │ %s", msg.Tool, line)
+		}
+
+	case events.TokenUsageEvent:
+		p.Record(ActivityMetadata, false, "%s: %d tokens in %s", msg.Source, msg.TotalTokens, msg.Duration.Round(time.Millisecond))
+
+	case ModelResponseMsg:
+		if msg.Error != nil {
+			p.Record(ActivityMetadata, true, "model error: %v", msg.Error)
+		} else if msg.Response != nil {
+			p.Record(ActivityMetadata, false, "model: %d tokens in %s",
+				msg.Response.Usage.TotalTokens, msg.Response.Duration.Round(time.Millisecond))
+		}
+
+	case ServerStatusUpdateMsg:
+		if msg.Connected {
+			p.Record(ActivityServer, false, "%s connected (%d tools)", msg.ServerName, msg.ToolCount)
+		} else if msg.Error != "" {
+			p.Record(ActivityServer, true, "%s disconnected: %s", msg.ServerName, msg.Error)
+		} else {
+			p.Record(ActivityServer, false, "%s disconnected", msg.ServerName)
+		}
+
+	case ToolUpdateMsg:
+		p.Record(ActivityServer, false, "%s tools: +%d -%d", msg.ServerName, len(msg.Added), len(msg.Removed))
+
+	case LogMsg:
+		p.Record(ActivityLog, false, "%s", msg.Text)
+	}
+}
+
+// formatActivityArgs renders tool arguments compactly on one line
+func formatActivityArgs(args map[string]interface{}) string {
+	if len(args) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprintf("%v", args)
+	}
+	return string(data)
+}
+
+// Update scrolls the pane with the mouse wheel
+func (p *ActivityPane) Update(msg tea.Msg) tea.Cmd {
+	if mouse, ok := msg.(tea.MouseMsg); ok && mouse.Action == tea.MouseActionPress {
+		switch mouse.Button {
+		case tea.MouseButtonWheelUp:
+			p.viewport.ScrollUp(3)
+		case tea.MouseButtonWheelDown:
+			p.viewport.ScrollDown(3)
+		}
+	}
+	return nil
+}
+
+// SetSize sets the size of the pane, including its border
+func (p *ActivityPane) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+
+	frameWidth, _ := p.styles.ActivityPane.GetFrameSize()
+	p.viewport.Width = max(width-frameWidth, 1)
+	p.viewport.Height = max(height-1, 1) // Account for the header
+	p.viewport.SetContent(p.renderEntries())
+	p.viewport.GotoBottom()
+}
+
+// View renders the pane
+func (p *ActivityPane) View() string {
+	frameWidth, _ := p.styles.ActivityPane.GetFrameSize()
+	header := p.styles.ViewHeader.
+		Width(max(p.width-frameWidth, 1)).
+		Render("⚡ Activity")
+
+	content := lipgloss.JoinVertical(lipgloss.Left, header, p.viewport.View())
+	return p.styles.ActivityPane.Height(p.height).Render(content)
+}
+
+// renderEntries renders every entry, wrapped to the pane width
+func (p *ActivityPane) renderEntries() string {
+	if len(p.entries) == 0 {
+		return p.styles.DimmedStyle.Render("No activity yet")
+	}
+
+	width := max(p.viewport.Width, 1)
+	lines := make([]string, 0, len(p.entries))
+	for _, entry := range p.entries {
+		style := p.styleFor(entry)
+		stamp := p.styles.DimmedStyle.Render(entry.Time.Format("15:04:05"))
+		line := stamp + " " + style.Render(entry.Text)
+		lines = append(lines, lipgloss.NewStyle().Width(width).Render(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// styleFor picks the style for an entry based on its kind and outcome
+func (p *ActivityPane) styleFor(entry ActivityEntry) lipgloss.Style {
+	if entry.Failed {
+		return p.styles.ErrorStyle
+	}
+	switch entry.Kind {
+	case ActivityTool:
+		return p.styles.MessageTool
+	case ActivityResult:
+		return p.styles.SuccessStyle
+	case ActivityLog, ActivityMetadata:
+		return p.styles.DimmedStyle
+	default:
+		return lipgloss.NewStyle()
+	}
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func activityTexts(p *ActivityPane) []string {
+	texts := make([]string, len(p.Entries()))
+	for i, entry := range p.Entries() {
+		texts[i] = entry.Text
+	}
+	return texts
+}
+
+func TestActivityPane_ObserveToolLifecycle(t *testing.T) {
+	pane := NewActivityPane(DefaultStyles())
+
+	pane.Observe(ToolCallDetectedMsg{ToolCalls: []model.ToolCall{
+		{Name: "search", Arguments: map[string]interface{}{"query": "go"}},
+	}})
+	pane.Observe(MCPToolExecutedMsg{ToolName: "search", Result: &mcp.ExecuteResult{Duration: "12ms"}})
+	pane.Observe(MCPToolExecutedMsg{ToolName: "stats", Error: errors.New("timeout")})
+	pane.Observe(ModelResponseMsg{Response: &model.Response{Usage: model.Usage{TotalTokens: 42}, Duration: 1500 * time.Millisecond}})
+	pane.Observe(LogMsg{Text: "Connected to local-memory"})
+
+	// Unrelated messages are ignored
+	pane.Observe(tea.KeyMsg{Type: tea.KeyEnter})
+
+	assert.Equal(t, []string{
+		`→ search {"query":"go"}`,
+		"✓ search (12ms)",
+		"✗ stats: timeout",
+		"model: 42 tokens in 1.5s",
+		"Connected to local-memory",
+	}, activityTexts(pane))
+	assert.True(t, pane.Entries()[2].Failed)
+}
+
+func TestApplication_RecordsAgentEvents(t *testing.T) {
+	app := NewApplication(nil)
+	for _, event := range []events.Event{
+		events.ToolOutputEvent{Tool: "remember", Text: "Saving to memory\nIndexed 1 fact\n"},
+		events.ToolExecutedEvent{Tool: "remember", Success: true, Duration: 8 * time.Millisecond},
+		events.ToolExecutedEvent{Tool: "recall", Error: "database is locked"},
+		events.TokenUsageEvent{Source: "sub-agent", TotalTokens: 530, Duration: 2 * time.Second},
+		events.ToolsChangedEvent{Server: "memory", Removed: []string{"search"}},
+		events.LogEvent{Text: "Connected to local-memory"},
+	} {
+		app.Update(agentEventMsg{event: event})
+	}
+
+	assert.Equal(t, []string{
+		"remember │ Saving to memory",
+		"remember │ Indexed 1 fact",
+		"✓ remember (8ms)",
+		"✗ recall: database is locked",
+		"sub-agent: 530 tokens in 2s",
+		"memory tools: +0 -1",
+		"Connected to local-memory",
+	}, activityTexts(app.activity))
+}
+
+func TestActivityPane_CapsEntries(t *testing.T) {
+	pane := NewActivityPane(DefaultStyles())
+	for i := 0; i < maxActivityEntries+10; i++ {
+		pane.Record(ActivityLog, false, "line %d", i)
+	}
+
+	entries := pane.Entries()
+	require.Len(t, entries, maxActivityEntries)
+	assert.Equal(t, "line 10", entries[0].Text)
+}
+
+func TestApplication_ToggleSplitLayout(t *testing.T) {
+	app := NewApplication(nil)
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	assert.NotContains(t, ansi.Strip(app.View()), "Activity")
+
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	app.Update(MCPToolExecutedMsg{ToolName: "search"})
+
+	view := ansi.Strip(app.View())
+	assert.Contains(t, view, "Activity")
+	assert.Contains(t, view, "✓ search")
+	assert.Equal(t, 60, app.chatView.width, "Conversation keeps the left pane")
+	for _, line := range strings.Split(view, "\n") {
+		assert.LessOrEqual(t, ansi.StringWidth(line), 100)
+	}
+
+	// The pane is only shown alongside the chat view
+	app.Update(ViewSwitchMsg{ViewType: HelpViewType})
+	assert.NotContains(t, ansi.Strip(app.View()), "⚡ Activity")
+
+	app.Update(ViewSwitchMsg{ViewType: ChatViewType})
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	assert.NotContains(t, ansi.Strip(app.View()), "⚡ Activity")
+	assert.Equal(t, 100, app.chatView.width)
+}
+
+func TestApplication_RecordsActivityWhileHidden(t *testing.T) {
+	app := NewApplication(nil)
+	app.Update(agentEventMsg{event: events.ServerStatusEvent{Server: "local-memory", Connected: true, ToolCount: 3}})
+
+	assert.Equal(t, []string{"local-memory connected (3 tools)"}, activityTexts(app.activity))
+}
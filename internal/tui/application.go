@@ -2,13 +2,17 @@ package tui
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
 	"github.com/danieleugenewilliams/othello-agent/internal/model"
 )
 
@@ -21,15 +25,42 @@ const (
 	ToolViewType
 	HelpViewType
 	HistoryViewType
+	AuditViewType
+	StatsViewType
 )
 
+// String returns the view's display name
+func (v ViewType) String() string {
+	switch v {
+	case ChatViewType:
+		return "Chat"
+	case ServerViewType:
+		return "Servers"
+	case ToolViewType:
+		return "Tools"
+	case HelpViewType:
+		return "Help"
+	case HistoryViewType:
+		return "History"
+	case AuditViewType:
+		return "Audit"
+	case StatsViewType:
+		return "Stats"
+	}
+	return "Unknown"
+}
+
 // KeyMap defines the keybindings for the application
 type KeyMap struct {
-	Quit       key.Binding
-	Back       key.Binding
-	Submit     key.Binding
-	SwitchView key.Binding
-	ClearInput key.Binding
+	Quit        key.Binding
+	Back        key.Binding
+	Submit      key.Binding
+	SwitchView  key.Binding
+	ClearInput  key.Binding
+	ToggleSplit key.Binding
+	Regenerate  key.Binding
+	EditMessage key.Binding
+	FollowUp    key.Binding
 }
 
 // DefaultKeyMap returns the default keybindings
@@ -55,18 +86,35 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("ctrl+l"),
 			key.WithHelp("ctrl+l", "clear input"),
 		),
+		ToggleSplit: key.NewBinding(
+			key.WithKeys("ctrl+o"),
+			key.WithHelp("ctrl+o", "toggle activity pane"),
+		),
+		Regenerate: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "regenerate last response"),
+		),
+		EditMessage: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "edit previous message"),
+		),
+		FollowUp: key.NewBinding(
+			key.WithKeys("alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9"),
+			key.WithHelp("alt+1-9", "use suggested follow-up"),
+		),
 	}
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view
 func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Submit, k.SwitchView}
+	return []key.Binding{k.Submit, k.SwitchView, k.ToggleSplit}
 }
 
 // FullHelp returns keybindings for the expanded help view
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Submit, k.SwitchView, k.ClearInput, k.Back},
+		{k.ToggleSplit, k.Regenerate, k.EditMessage, k.FollowUp},
 		{k.Quit},
 	}
 }
@@ -87,6 +135,8 @@ type Styles struct {
 	SuccessStyle  lipgloss.Style
 	DimmedStyle   lipgloss.Style
 	HighlightStyle lipgloss.Style
+	Toast          lipgloss.Style
+	ActivityPane   lipgloss.Style
 }
 
 // DefaultStyles returns the default styling
@@ -135,6 +185,14 @@ func DefaultStyles() Styles {
 		HighlightStyle: lipgloss.NewStyle().
 			Background(lipgloss.Color("62")).
 			Foreground(lipgloss.Color("230")),
+		Toast: lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Foreground(lipgloss.Color("230")).
+			Padding(0, 1),
+		ActivityPane: lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder(), false, false, false, true).
+			BorderForeground(lipgloss.Color("62")).
+			PaddingLeft(1),
 	}
 }
 
@@ -148,6 +206,7 @@ type Application struct {
 	help        help.Model
 	model       model.Model
 	agent       AgentInterface // Optional agent for MCP data
+	events      *events.Subscription // The agent's events, once the application listens for them
 	
 	// Views
 	chatView    *ChatView
@@ -155,6 +214,21 @@ type Application struct {
 	toolView    *ToolView
 	helpView    *HelpView
 	historyView *HistoryView
+	auditView   *AuditView
+	statsView   *StatsView
+	
+	// Transient notifications shown over the current view
+	toasts *ToastStack
+	
+	// Split layout: chat on the left, live tool activity on the right
+	activity  *ActivityPane
+	splitView bool
+	
+	// What the terminal can render; degraded terminals get ASCII-only output
+	caps TerminalCapabilities
+	
+	// Prints state changes as plain text lines in accessible mode (nil otherwise)
+	announcer *announcer
 	
 	// State
 	quitting bool
@@ -175,18 +249,55 @@ func NewApplication(m model.Model) *Application {
 		agent:       nil, // No agent, use mock data
 		chatView:    NewChatViewWithAgent(styles, keymap, m, nil),
 		serverView:  NewServerView(styles, keymap),
+		toolView:    NewToolView(),
 		helpView:    NewHelpView(styles, keymap),
 		historyView: NewHistoryView(styles, keymap),
+		auditView:   NewAuditView(styles),
+		statsView:   NewStatsView(styles),
+		toasts:      NewToastStack(),
+		activity:    NewActivityPane(styles),
+		caps:        FullCapabilities(),
 	}
 	
 	return app
 }
 
+// ModelSettings describes the model the chat view talks to
+type ModelSettings struct {
+	Host          string
+	Name          string
+	Temperature   float64
+	MaxTokens     int
+	ContextLength int
+	Transport     http.RoundTripper // Optional HTTP transport for model requests
+}
+
+// DefaultModelSettings returns the settings used when the agent doesn't provide any
+func DefaultModelSettings() ModelSettings {
+	return ModelSettings{
+		Host:        "http://localhost:11434",
+		Name:        "qwen2.5:3b",
+		Temperature: 0.7,
+		MaxTokens:   2048,
+	}
+}
+
 // NewApplicationWithAgent creates a new TUI application with agent support
 func NewApplicationWithAgent(keymap KeyMap, styles Styles, agent AgentInterface) *Application {
-	// Create a model for the ChatView (we can use a dummy model or create one from agent config)
-	// For now, create a basic Ollama model instance
-	m := model.NewOllamaModel("http://localhost:11434", "qwen2.5:3b")
+	// Use the agent's effective model settings (global config merged with any workspace overrides)
+	settings := DefaultModelSettings()
+	if provider, ok := agent.(interface{ ModelSettings() ModelSettings }); ok {
+		settings = provider.ModelSettings()
+	}
+	ollama := model.NewOllamaModel(settings.Host, settings.Name)
+	if settings.Transport != nil {
+		ollama.SetTransport(settings.Transport)
+	}
+	var m model.Model = ollama
+	// Run the agent's prompt and response hooks around every request
+	if wrapper, ok := agent.(interface{ WrapModel(model.Model) model.Model }); ok {
+		m = wrapper.WrapModel(m)
+	}
 	
 	// Set the model on the agent for LLM-based metadata extraction
 	if agentWithModel, ok := agent.(interface{ SetModel(model.Model) }); ok {
@@ -205,6 +316,95 @@ func NewApplicationWithAgent(keymap KeyMap, styles Styles, agent AgentInterface)
 		toolView:    NewToolViewWithAgent(agent),
 		helpView:    NewHelpView(styles, keymap),
 		historyView: NewHistoryView(styles, keymap),
+		auditView:   NewAuditView(styles),
+		statsView:   NewStatsView(styles),
+		toasts:      NewToastStack(),
+		activity:    NewActivityPane(styles),
+		caps:        FullCapabilities(),
+	}
+	app.chatView.SetGenerateOptions(model.GenerateOptions{
+		Temperature:   settings.Temperature,
+		MaxTokens:     settings.MaxTokens,
+		ContextLength: settings.ContextLength,
+	})
+	
+	// Recall prompts from earlier sessions with up and down
+	if provider, ok := agent.(interface{ PromptStore() PromptStore }); ok {
+		if store := provider.PromptStore(); store != nil {
+			app.chatView.SetPromptStore(store)
+		}
+	}
+	
+	// Save the conversation as it happens so it can be resumed after a restart
+	if provider, ok := agent.(interface{ ConversationStore() ConversationStore }); ok {
+		if store := provider.ConversationStore(); store != nil {
+			app.chatView.SetConversationStore(store)
+			app.historyView.SetConversationStore(store)
+		}
+	}
+	
+	// List tool executions in the audit view
+	if provider, ok := agent.(interface{ ToolAuditLog() ToolAuditLog }); ok {
+		if log := provider.ToolAuditLog(); log != nil {
+			app.auditView.SetAuditLog(log)
+		}
+	}
+	
+	// Show token use, latency, and tool calls per conversation in the stats view
+	if provider, ok := agent.(interface{ ConversationStatistics() ConversationStatistics }); ok {
+		if stats := provider.ConversationStatistics(); stats != nil {
+			app.statsView.SetStatistics(stats)
+		}
+	}
+	
+	// Remember facts across conversations and recall them with each message
+	if provider, ok := agent.(interface{ MemoryStore() MemoryStore }); ok {
+		if store := provider.MemoryStore(); store != nil {
+			recallLimit := 0
+			if limiter, ok := agent.(interface{ MemoryRecallLimit() int }); ok {
+				recallLimit = limiter.MemoryRecallLimit()
+			}
+			app.chatView.SetMemoryStore(store, recallLimit)
+		}
+	}
+	
+	// Send related parts of indexed project files with each message
+	if provider, ok := agent.(interface{ ProjectIndex() ProjectIndex }); ok {
+		if index := provider.ProjectIndex(); index != nil {
+			limit := 0
+			if limiter, ok := agent.(interface{ ProjectRetrieveLimit() int }); ok {
+				limit = limiter.ProjectRetrieveLimit()
+			}
+			app.chatView.SetProjectIndex(index, limit)
+		}
+	}
+	
+	// Search saved conversations with /search
+	if provider, ok := agent.(interface{ IncludePinnedMessages() bool }); ok {
+		app.chatView.SetPinnedContext(provider.IncludePinnedMessages())
+	}
+	if provider, ok := agent.(interface{ MaxIterations() int }); ok {
+		app.chatView.SetMaxIterations(provider.MaxIterations())
+	}
+	if provider, ok := agent.(interface{ TurnTimeout() time.Duration }); ok {
+		app.chatView.SetTurnTimeout(provider.TurnTimeout())
+	}
+	if provider, ok := agent.(interface{ ContextCompaction() (float64, int) }); ok {
+		app.chatView.SetContextCompaction(provider.ContextCompaction())
+	}
+	if provider, ok := agent.(interface{ SessionMode() string }); ok {
+		app.chatView.SetSessionMode(provider.SessionMode())
+	}
+	if provider, ok := agent.(interface{ Personas() ([]Persona, string) }); ok {
+		app.chatView.SetPersonas(provider.Personas())
+	}
+	if provider, ok := agent.(interface{ Pricing() model.Pricing }); ok {
+		app.chatView.SetPricing(provider.Pricing())
+	}
+	if provider, ok := agent.(interface{ ConversationSearcher() ConversationSearcher }); ok {
+		if searcher := provider.ConversationSearcher(); searcher != nil {
+			app.chatView.SetConversationSearcher(searcher)
+		}
 	}
 	
 	return app
@@ -230,21 +430,48 @@ func (a *Application) Init() tea.Cmd {
 
 // Update implements tea.Model
 func (a *Application) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	previous := a.currentView
+	m, cmd := a.update(msg)
+	
+	// Show the latest saved conversations whenever the history view opens
+	if a.currentView == HistoryViewType && previous != HistoryViewType && a.historyView != nil {
+		cmd = tea.Batch(cmd, a.historyView.Load())
+	}
+	if a.currentView == AuditViewType && previous != AuditViewType && a.auditView != nil {
+		cmd = tea.Batch(cmd, a.auditView.Load())
+	}
+	if a.currentView == StatsViewType && previous != StatsViewType && a.statsView != nil {
+		cmd = tea.Batch(cmd, a.statsView.Load())
+	}
+	
+	// Report failures to save the conversation
+	if a.chatView != nil {
+		if err := a.chatView.persistError(); err != nil {
+			cmd = tea.Batch(cmd, a.pushToast(ToastMsg{Text: "Failed to save conversation: " + err.Error(), Level: ToastError}))
+		}
+	}
+	
+	// Screen readers follow plain text lines printed above the inline TUI
+	if a.announcer != nil {
+		if lines := a.announcer.collect(a); len(lines) > 0 {
+			cmd = tea.Batch(cmd, tea.Println(strings.Join(lines, "\n")))
+		}
+	}
+	return m, cmd
+}
+
+// update applies msg to the application state
+func (a *Application) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	
+	// The activity pane records tool and model events no matter which view handles them
+	a.activity.Observe(msg)
+	
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		a.width = msg.Width
 		a.height = msg.Height
-		
-		// Update all views with new size
-		if a.chatView != nil {
-			a.chatView.SetSize(msg.Width, msg.Height-3) // Account for status bar
-		}
-		a.serverView.SetSize(msg.Width, msg.Height-3)
-		a.toolView.SetSize(msg.Width, msg.Height-3)
-		a.helpView.SetSize(msg.Width, msg.Height-3)
-		a.historyView.SetSize(msg.Width, msg.Height-3)
+		a.resizeViews()
 		
 		return a, nil
 
@@ -253,6 +480,36 @@ func (a *Application) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.currentView = msg.ViewType
 		return a, nil
 	
+	case agentEventMsg:
+		// Convert the agent event, surface it as a toast, and keep listening
+		if tuiMsg := messageForEvent(msg.event); tuiMsg != nil {
+			a.activity.Observe(tuiMsg)
+			if toastMsg, ok := toastForUpdate(tuiMsg); ok {
+				cmds = append(cmds, a.pushToast(toastMsg))
+			}
+			cmds = append(cmds, a.dispatchAgentUpdate(tuiMsg))
+		}
+		cmds = append(cmds, a.waitForNextUpdate())
+		return a, tea.Batch(cmds...)
+
+	case ToastMsg:
+		return a, a.pushToast(msg)
+
+	case spinner.TickMsg, earlierMessagesMsg:
+		// The chat's progress spinner keeps running while other views are
+		// shown, and earlier messages it loads still reach it
+		newModel, cmd := a.chatView.Update(msg)
+		a.chatView = newModel.(*ChatView)
+		return a, cmd
+
+	case toastExpiredMsg:
+		a.toasts.Dismiss(msg.id)
+		return a, nil
+
+	case scriptSnapshotMsg, scriptExpectMsg:
+		a.handleScriptMsg(msg)
+		return a, nil
+
 	case ServerSelectedMsg:
 		// Handle server selection from ServerView - navigate to ToolView for that server
 		if a.toolView != nil {
@@ -263,27 +520,30 @@ func (a *Application) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// ToolExecutedUnifiedMsg removed from application handler - chat view handles it directly
 
-	default:
-		// Handle agent updates by converting them to TUI messages and forwarding
-		if a.agent != nil {
-			if tuiMsg := a.convertAgentUpdate(msg); tuiMsg != nil {
-				// Forward to all relevant views
-				cmds = append(cmds, func() tea.Msg { return tuiMsg })
-				// Continue listening for more updates
-				cmds = append(cmds, a.waitForNextUpdate())
-				return a, tea.Batch(cmds...)
-			}
+	case tea.MouseMsg:
+		// Events over the activity pane scroll it instead of the conversation
+		if a.splitActive() && msg.X >= a.chatPaneWidth() {
+			return a, a.activity.Update(msg)
 		}
-		
+
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, a.keymap.Quit):
 			a.quitting = true
 			return a, tea.Quit
+		
+		case key.Matches(msg, a.keymap.ToggleSplit):
+			a.splitView = !a.splitView
+			a.resizeViews()
+			return a, nil
 			
 		// Removed global Back/Esc handler - let individual views handle their own back navigation
 		
 		case key.Matches(msg, a.keymap.SwitchView):
+			// Tab accepts an autocomplete suggestion instead of switching views
+			if a.currentView == ChatViewType && a.chatView.CompletionVisible() {
+				break
+			}
 			a.nextView()
 			return a, nil
 		}
@@ -315,6 +575,16 @@ func (a *Application) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		newModel, cmd := a.historyView.Update(msg)
 		a.historyView = newModel.(*HistoryView)
 		cmds = append(cmds, cmd)
+		
+	case AuditViewType:
+		newModel, cmd := a.auditView.Update(msg)
+		a.auditView = newModel.(*AuditView)
+		cmds = append(cmds, cmd)
+		
+	case StatsViewType:
+		newModel, cmd := a.statsView.Update(msg)
+		a.statsView = newModel.(*StatsView)
+		cmds = append(cmds, cmd)
 	}
 	
 	return a, tea.Batch(cmds...)
@@ -336,6 +606,9 @@ func (a *Application) View() string {
 	switch a.currentView {
 	case ChatViewType:
 		content = a.chatView.View()
+		if a.splitActive() {
+			content = lipgloss.JoinHorizontal(lipgloss.Top, content, a.activity.View())
+		}
 	case ServerViewType:
 		content = a.serverView.View()
 	case ToolViewType:
@@ -344,17 +617,30 @@ func (a *Application) View() string {
 		content = a.helpView.View()
 	case HistoryViewType:
 		content = a.historyView.View()
+	case AuditViewType:
+		content = a.auditView.View()
+	case StatsViewType:
+		content = a.statsView.View()
 	}
 	
+	// Draw any active toasts over the bottom of the view
+	content = a.toasts.Overlay(content, a.width, a.styles)
+	
 	// Render status bar
 	statusBar := a.renderStatusBar()
 	
 	// Combine everything
-	return lipgloss.JoinVertical(
+	view := lipgloss.JoinVertical(
 		lipgloss.Left,
 		content,
 		statusBar,
 	)
+	
+	if !a.caps.Unicode {
+		view = toASCII(view)
+	}
+	
+	return view
 }
 
 // nextView cycles to the next view
@@ -367,6 +653,10 @@ func (a *Application) nextView() {
 	case ToolViewType:
 		a.currentView = HistoryViewType
 	case HistoryViewType:
+		a.currentView = AuditViewType
+	case AuditViewType:
+		a.currentView = StatsViewType
+	case StatsViewType:
 		a.currentView = HelpViewType
 	case HelpViewType:
 		a.currentView = ChatViewType
@@ -375,24 +665,22 @@ func (a *Application) nextView() {
 
 // renderStatusBar renders the status bar
 func (a *Application) renderStatusBar() string {
-	var viewName string
-	switch a.currentView {
-	case ChatViewType:
-		viewName = "Chat"
-	case ServerViewType:
-		viewName = "Servers"
-	case ToolViewType:
-		viewName = "Tools"
-	case HelpViewType:
-		viewName = "Help"
-	case HistoryViewType:
-		viewName = "History"
+	status := a.styles.StatusBar.Render(fmt.Sprintf(" %s ", a.currentView))
+	if mode := a.chatView.Mode(); mode != defaultMode {
+		status += a.styles.HighlightStyle.Render(fmt.Sprintf(" 🧭 %s ", mode))
+	}
+	if persona := a.chatView.Persona(); persona != "" {
+		status += a.styles.HighlightStyle.Render(fmt.Sprintf(" 🎭 %s ", persona))
+	}
+	if a.chatView.DryRun() {
+		status += a.styles.ErrorStyle.Render(" 🧪 DRY RUN ")
+	}
+	if usage := a.chatView.UsageSummary(); usage != "" {
+		status += a.styles.DimmedStyle.Render(fmt.Sprintf(" 🪙 %s ", usage))
 	}
-	
-	status := fmt.Sprintf(" %s ", viewName)
 	helpText := a.help.ShortHelpView(a.keymap.ShortHelp())
 	
-	// Calculate spacing
+	// Calculate spacing from the rendered status so its padding is counted
 	gap := a.width - lipgloss.Width(status) - lipgloss.Width(helpText)
 	if gap < 0 {
 		gap = 0
@@ -400,7 +688,7 @@ func (a *Application) renderStatusBar() string {
 	
 	line := lipgloss.JoinHorizontal(
 		lipgloss.Top,
-		a.styles.StatusBar.Render(status),
+		status,
 		strings.Repeat(" ", gap),
 		a.styles.DimmedStyle.Render(helpText),
 	)
@@ -408,11 +696,64 @@ func (a *Application) renderStatusBar() string {
 	return line
 }
 
+// splitActive reports whether the activity pane is shown next to the conversation
+func (a *Application) splitActive() bool {
+	return a.splitView && a.currentView == ChatViewType
+}
+
+// chatPaneWidth returns the width given to the conversation in the split layout
+func (a *Application) chatPaneWidth() int {
+	if !a.splitView {
+		return a.width
+	}
+	return a.width * 3 / 5
+}
+
+// resizeViews sizes every view for the current window and layout
+func (a *Application) resizeViews() {
+	height := a.height - 3 // Account for status bar
+	
+	chatWidth := a.chatPaneWidth()
+	if a.chatView != nil {
+		a.chatView.SetSize(chatWidth, height)
+	}
+	if a.splitView {
+		a.activity.SetSize(a.width-chatWidth, height)
+	}
+	a.serverView.SetSize(a.width, height)
+	a.toolView.SetSize(a.width, height)
+	a.helpView.SetSize(a.width, height)
+	a.historyView.SetSize(a.width, height)
+	a.auditView.SetSize(a.width, height)
+	a.statsView.SetSize(a.width, height)
+}
+
+// SetCapabilities sets the detected terminal capabilities used for rendering
+func (a *Application) SetCapabilities(caps TerminalCapabilities) {
+	a.caps = caps
+	if caps.Accessible && a.announcer == nil {
+		a.announcer = &announcer{view: a.currentView}
+	}
+}
+
+// pushToast shows a toast and, in accessible mode, announces it
+func (a *Application) pushToast(t ToastMsg) tea.Cmd {
+	if a.announcer != nil {
+		a.announcer.notify(t)
+	}
+	return a.toasts.Push(t)
+}
+
 // SetError sets an error message to display
 func (a *Application) SetError(err error) {
 	a.err = err
 }
 
+// ResumeConversation restores the most recent saved conversation when the application starts
+func (a *Application) ResumeConversation() {
+	a.chatView.ResumeOnInit()
+}
+
 // GetCurrentView returns the current view type
 func (a *Application) GetCurrentView() ViewType {
 	return a.currentView
@@ -423,20 +764,53 @@ func (a *Application) GetServerView() *ServerView {
 	return a.serverView
 }
 
-// listenForAgentUpdates creates a command that listens for agent status updates
+// listenForAgentUpdates creates a command that waits for the agent's next
+// event, subscribing to them the first time
 func (a *Application) listenForAgentUpdates() tea.Cmd {
+	if a.agent == nil {
+		return nil
+	}
+	if a.events == nil {
+		a.events = a.agent.Subscribe()
+	}
+	sub := a.events
 	return func() tea.Msg {
-		if a.agent == nil {
+		if sub == nil {
 			return nil
 		}
-		
-		updateChan := a.agent.SubscribeToUpdates()
-		select {
-		case update := <-updateChan:
-			// For now, just return the raw update and handle it in Update method
-			return update
+		event, ok := <-sub.Events()
+		if !ok {
+			return nil
 		}
+		return agentEventMsg{event: event}
+	}
+}
+
+// agentEventMsg carries an event from the agent to Update
+type agentEventMsg struct {
+	event events.Event
+}
+
+// dispatchAgentUpdate delivers a converted agent update to the views that track it,
+// regardless of which view is currently visible
+func (a *Application) dispatchAgentUpdate(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	
+	if a.serverView != nil {
+		newModel, cmd := a.serverView.Update(msg)
+		a.serverView = newModel.(*ServerView)
+		cmds = append(cmds, cmd)
+	}
+	if a.toolView != nil {
+		newModel, cmd := a.toolView.Update(msg)
+		a.toolView = newModel.(*ToolView)
+		cmds = append(cmds, cmd)
+	}
+	if reload, ok := msg.(ConfigReloadedMsg); ok && reload.Error == "" && a.chatView != nil {
+		a.chatView.SetTemperature(reload.Temperature)
 	}
+	
+	return tea.Batch(cmds...)
 }
 
 // waitForNextUpdate creates a command to continue listening for updates
@@ -447,60 +821,30 @@ func (a *Application) waitForNextUpdate() tea.Cmd {
 	return a.listenForAgentUpdates()
 }
 
-// convertAgentUpdate converts raw agent updates to TUI messages
-func (a *Application) convertAgentUpdate(update interface{}) tea.Msg {
-	// Use reflection to check the type name since we can't import agent package
-	switch u := update.(type) {
-	case interface{}:
-		// Check if it's a ServerStatusUpdate by checking fields
-		if serverName, connected, toolCount, errStr, ok := a.extractServerUpdate(u); ok {
-			return ServerStatusUpdateMsg{
-				ServerName: serverName,
-				Connected:  connected,
-				ToolCount:  toolCount,
-				Error:      errStr,
-			}
-		}
-		// Check if it's a ToolUpdate by checking fields
-		if serverName, added, removed, ok := a.extractToolUpdate(u); ok {
-			return ToolUpdateMsg{
-				ServerName: serverName,
-				Tools:      []Tool{}, // Will trigger refresh
-				Added:      added,
-				Removed:    removed,
-			}
-		}
+// messageForEvent converts an agent event into the message the views handle.
+// Events only the activity pane records are returned as they are.
+func messageForEvent(event events.Event) tea.Msg {
+	switch e := event.(type) {
+	case events.ServerStatusEvent:
+		return ServerStatusUpdateMsg{ServerName: e.Server, Connected: e.Connected, ToolCount: e.ToolCount, Error: e.Error}
+	case events.ToolsChangedEvent:
+		return ToolUpdateMsg{ServerName: e.Server, Tools: []Tool{}, Added: e.Added, Removed: e.Removed}
+	case events.NotificationEvent:
+		return ToastMsg{Text: e.Text, Level: toastLevels[e.Level]}
+	case events.LogEvent:
+		return LogMsg{Text: e.Text}
+	case events.ConfigReloadedEvent:
+		return ConfigReloadedMsg{Changes: e.Changes, Restart: e.Restart, Temperature: e.Temperature, Error: e.Error}
+	case events.ToolExecutedEvent, events.ToolOutputEvent, events.TokenUsageEvent:
+		return e
 	}
 	return nil
 }
 
-// Helper methods to extract update data using type assertions
-func (a *Application) extractServerUpdate(update interface{}) (string, bool, int, string, bool) {
-	// Define a temporary struct that matches the agent's ServerStatusUpdate
-	type ServerStatusUpdate struct {
-		ServerName string
-		Connected  bool
-		ToolCount  int
-		Error      string
-	}
-	
-	if su, ok := update.(ServerStatusUpdate); ok {
-		return su.ServerName, su.Connected, su.ToolCount, su.Error, true
-	}
-	return "", false, 0, "", false
+// toastLevels maps notification levels to toast levels
+var toastLevels = map[events.Level]ToastLevel{
+	events.LevelInfo:    ToastInfo,
+	events.LevelSuccess: ToastSuccess,
+	events.LevelWarning: ToastWarning,
+	events.LevelError:   ToastError,
 }
-
-func (a *Application) extractToolUpdate(update interface{}) (string, []string, []string, bool) {
-	// Define a temporary struct that matches the agent's ToolUpdate
-	type ToolUpdate struct {
-		ServerName string
-		ToolCount  int
-		Added      []string
-		Removed    []string
-	}
-	
-	if tu, ok := update.(ToolUpdate); ok {
-		return tu.ServerName, tu.Added, tu.Removed, true
-	}
-	return "", nil, nil, false
-}
\ No newline at end of file
@@ -5,6 +5,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
 )
 
 func TestApplication_ESCKeyNavigation(t *testing.T) {
@@ -80,4 +81,38 @@ func TestKeyMap_ESCBinding(t *testing.T) {
 	if !key.Matches(ctrlCKey, keymap.Quit) {
 		t.Error("Ctrl+C should match Quit binding")
 	}
-}
\ No newline at end of file
+}
+
+// settingsAgent is a chat mock that also reports effective model settings
+type settingsAgent struct {
+	MockAgentForChat
+	settings ModelSettings
+}
+
+func (a *settingsAgent) ModelSettings() ModelSettings {
+	return a.settings
+}
+
+func TestApplication_UsesAgentModelSettings(t *testing.T) {
+	agent := &settingsAgent{settings: ModelSettings{
+		Host:          "http://localhost:11434",
+		Name:          "qwen2.5-coder:7b",
+		Temperature:   0.2,
+		MaxTokens:     4096,
+		ContextLength: 32768,
+	}}
+
+	app := NewApplicationWithAgent(DefaultKeyMap(), DefaultStyles(), agent)
+
+	ollama, ok := app.model.(*model.OllamaModel)
+	if !ok {
+		t.Fatalf("Expected an Ollama model, got %T", app.model)
+	}
+	if name := ollama.Name(); name != "qwen2.5-coder:7b" {
+		t.Errorf("Expected workspace model qwen2.5-coder:7b, got %s", name)
+	}
+	options := app.chatView.options
+	if options.Temperature != 0.2 || options.MaxTokens != 4096 || options.ContextLength != 32768 {
+		t.Errorf("Expected generation options from agent settings, got %+v", options)
+	}
+}
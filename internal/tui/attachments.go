@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+const (
+	// maxAttachmentSize is the largest file /attach accepts
+	maxAttachmentSize = 10 << 20
+
+	// maxAttachmentText caps how much of a text attachment is sent to the model
+	maxAttachmentText = 32 << 10
+
+	// maxInlineToolOutput is the longest tool output saved with its message;
+	// longer outputs are shortened and saved in full as an attachment
+	maxInlineToolOutput = 8 << 10
+)
+
+// Attachment is a file, image, or tool output kept with a chat message
+type Attachment struct {
+	Name      string
+	MediaType string
+	Size      int64
+	data      []byte // nil for a restored attachment until it is read from the store
+	storedID  int64  // ID of the saved copy; zero until saved
+}
+
+// isImage reports whether the attachment is sent to the model as an image
+func (a Attachment) isImage() bool {
+	return strings.HasPrefix(a.MediaType, "image/")
+}
+
+// isText reports whether the attachment's content can be shown to the model
+// as text
+func (a Attachment) isText() bool {
+	return !a.isImage() && utf8.Valid(a.data) && !bytes.ContainsRune(a.data, 0)
+}
+
+// describeAttachment names an attachment and its size on one line
+func describeAttachment(name string, size int64) string {
+	return fmt.Sprintf("📎 %s (%s)", name, formatBytes(int(size)))
+}
+
+// fromStorageAttachment converts a saved attachment back into a chat attachment
+func fromStorageAttachment(stored *storage.Attachment) Attachment {
+	return Attachment{Name: stored.Name, MediaType: stored.MediaType, Size: stored.Size, storedID: stored.ID}
+}
+
+// attach handles /attach <path>, adding the file to the next message
+func (v *ChatView) attach(path string) tea.Cmd {
+	if path == "" {
+		content := "Usage: /attach <path> - the file is sent with your next message"
+		if len(v.pending) > 0 {
+			names := make([]string, len(v.pending))
+			for i, a := range v.pending {
+				names[i] = describeAttachment(a.Name, a.Size)
+			}
+			content = "Attached to your next message:\n" + strings.Join(names, "\n") + "\n\nUse /detach to remove them."
+		}
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   content,
+			Timestamp: time.Now().Format("15:04:05"),
+			Transient: true,
+		})
+		return nil
+	}
+
+	attachment, err := readAttachment(strings.Trim(path, `"'`))
+	if err != nil {
+		return toastCmd(err.Error(), ToastError)
+	}
+	v.pending = append(v.pending, attachment)
+	return toastCmd(fmt.Sprintf("Attached %s to your next message", attachment.Name), ToastSuccess)
+}
+
+// detach handles /detach, dropping the files attached to the next message
+func (v *ChatView) detach() tea.Cmd {
+	if len(v.pending) == 0 {
+		return toastCmd("Nothing is attached", ToastInfo)
+	}
+	n := len(v.pending)
+	v.pending = nil
+	return toastCmd(fmt.Sprintf("Removed %d attachments", n), ToastInfo)
+}
+
+// takeAttachments returns the files attached to the next message and clears them
+func (v *ChatView) takeAttachments() []Attachment {
+	attachments := v.pending
+	v.pending = nil
+	return attachments
+}
+
+// readAttachment reads the file at path, expanding a leading ~
+func readAttachment(path string) (Attachment, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("cannot attach %s: %w", path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return Attachment{}, fmt.Errorf("cannot attach %s: not a file", path)
+	}
+	if info.Size() > maxAttachmentSize {
+		return Attachment{}, fmt.Errorf("cannot attach %s: larger than %s", path, formatBytes(maxAttachmentSize))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("cannot attach %s: %w", path, err)
+	}
+	return Attachment{
+		Name:      filepath.Base(path),
+		MediaType: storage.DetectMediaType(path, data),
+		Size:      int64(len(data)),
+		data:      data,
+	}, nil
+}
+
+// prepareAttachments copies attachments for a new message, reading the data
+// of restored ones from the store. Attachments that can't be read are dropped.
+func (v *ChatView) prepareAttachments(attachments []Attachment) []Attachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+	prepared := make([]Attachment, 0, len(attachments))
+	for _, a := range attachments {
+		if a.data == nil && a.storedID != 0 && v.log != nil {
+			data, err := v.log.store.ReadAttachment(a.storedID)
+			if err != nil {
+				continue
+			}
+			a.data = data
+		}
+		if a.data == nil {
+			continue
+		}
+		a.storedID = 0 // saved again with the new message
+		prepared = append(prepared, a)
+	}
+	return prepared
+}
+
+// attachmentPrompt adds the text attachments to a prompt, so the model reads
+// them along with it. Other files are only named; images are sent separately.
+func attachmentPrompt(text string, attachments []Attachment) string {
+	var b strings.Builder
+	b.WriteString(text)
+	for _, a := range attachments {
+		switch {
+		case a.isImage():
+			continue
+		case a.isText():
+			content := string(a.data)
+			truncated := ""
+			if len(content) > maxAttachmentText {
+				content = strings.ToValidUTF8(content[:maxAttachmentText], "")
+				truncated = "\n(truncated)"
+			}
+			fmt.Fprintf(&b, "\n\nAttached file %s:\n```\n%s\n```%s", a.Name, content, truncated)
+		default:
+			fmt.Fprintf(&b, "\n\nAttached file %s (%s, %s) cannot be shown as text.", a.Name, a.MediaType, formatBytes(int(a.Size)))
+		}
+	}
+	return b.String()
+}
+
+// withAttachments puts the attachments into the user message that ends messages
+func withAttachments(messages []model.Message, attachments []Attachment) []model.Message {
+	if len(attachments) == 0 || len(messages) == 0 {
+		return messages
+	}
+	updated := append([]model.Message(nil), messages...)
+	last := &updated[len(updated)-1]
+	last.Content = attachmentPrompt(last.Content, attachments)
+	for _, a := range attachments {
+		if a.isImage() {
+			last.Images = append(last.Images, base64.StdEncoding.EncodeToString(a.data))
+		}
+	}
+	return updated
+}
+
+// spillToolOutput shortens a tool output too long to save with its message,
+// attaching the full output instead
+func spillToolOutput(msg *ChatMessage) {
+	if msg.ToolCall == nil || len(msg.ToolCall.Result) <= maxInlineToolOutput {
+		return
+	}
+	output := msg.ToolCall.Result
+	name := msg.ToolCall.Name + "-output.txt"
+	msg.Attachments = append(msg.Attachments, Attachment{
+		Name:      name,
+		MediaType: "text/plain; charset=utf-8",
+		Size:      int64(len(output)),
+		data:      []byte(output),
+	})
+	msg.ToolCall.Result = strings.ToValidUTF8(output[:maxInlineToolOutput], "") +
+		fmt.Sprintf("\n... (full output attached as %s)", name)
+}
+
+// attachFullOutput attaches the tool output a message summarizes, so it is
+// saved with the message. It is attached once.
+func attachFullOutput(msg *ChatMessage) {
+	if msg.FullOutput == "" {
+		return
+	}
+	for _, a := range msg.Attachments {
+		if a.Name == fullOutputName {
+			return
+		}
+	}
+	msg.Attachments = append(msg.Attachments, Attachment{
+		Name:      fullOutputName,
+		MediaType: "text/plain; charset=utf-8",
+		Size:      int64(len(msg.FullOutput)),
+		data:      []byte(msg.FullOutput),
+	})
+}
+
+// fullOutputName names the attachment attachFullOutput adds
+const fullOutputName = "full-tool-output.txt"
+
+// attachmentCacheDir is where attachments are written to be opened
+var attachmentCacheDir = filepath.Join(os.TempDir(), "othello-attachments")
+
+// openPath opens a file with the system's default application
+var openPath = func(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// openAttachment writes a saved attachment to the cache, named after its
+// content so it is written once, and opens it
+func openAttachment(attachment *storage.Attachment, data []byte) (string, error) {
+	dir := filepath.Join(attachmentCacheDir, attachment.Hash[:min(16, len(attachment.Hash))])
+	path := filepath.Join(dir, filepath.Base(attachment.Name))
+	if _, err := os.Stat(path); err != nil {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return "", fmt.Errorf("create attachment cache: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			return "", fmt.Errorf("write attachment: %w", err)
+		}
+	}
+	if err := openPath(path); err != nil {
+		return path, fmt.Errorf("open %s: %w", path, err)
+	}
+	return path, nil
+}
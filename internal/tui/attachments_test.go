@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestChatView_AttachSendsAndSavesFiles(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetConversationStore(store)
+
+	cmd := chatView.handleCommand("/attach " + writeTestFile(t, "notes.txt", "ship on friday"))
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Attached notes.txt to your next message", cmd().(ToastMsg).Text)
+	require.Len(t, chatView.pending, 1)
+	assert.Contains(t, chatView.renderInput(), "📎1")
+
+	chatView.SetInput("when do we ship?")
+	chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Empty(t, chatView.pending, "Attachments go with one message")
+
+	sent := chatView.messages[chatView.lastUserMessage(1)]
+	require.Len(t, sent.Attachments, 1)
+	assert.NotZero(t, sent.Attachments[0].storedID)
+	assert.Contains(t, chatView.renderMessage(sent), "📎 notes.txt (14 B)")
+
+	saved, err := store.ListAttachments(storage.AttachmentFilter{})
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, "notes.txt", saved[0].Name)
+	data, err := store.ReadAttachment(saved[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ship on friday", string(data))
+
+	// A resumed conversation shows its attachments, and regenerating resends them
+	resumed := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	resumed.SetConversationStore(store)
+	resumed.ResumeOnInit()
+	deliver(t, resumed, resumed.Init())
+	restored := resumed.messages[resumed.lastUserMessage(1)]
+	require.Len(t, restored.Attachments, 1)
+	assert.Nil(t, restored.Attachments[0].data)
+
+	prepared := resumed.prepareAttachments(restored.Attachments)
+	require.Len(t, prepared, 1)
+	assert.Equal(t, "ship on friday", string(prepared[0].data))
+	assert.Zero(t, prepared[0].storedID)
+}
+
+func TestChatView_AttachErrors(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+
+	cmd := chatView.handleCommand("/attach " + filepath.Join(t.TempDir(), "missing.txt"))
+	require.NotNil(t, cmd)
+	assert.Contains(t, cmd().(ToastMsg).Text, "cannot attach")
+
+	cmd = chatView.handleCommand("/attach " + t.TempDir())
+	require.NotNil(t, cmd)
+	assert.Contains(t, cmd().(ToastMsg).Text, "not a file")
+
+	assert.Nil(t, chatView.handleCommand("/attach"))
+	assert.Contains(t, chatView.messages[len(chatView.messages)-1].Content, "Usage: /attach <path>")
+
+	chatView.handleCommand(`/attach "` + writeTestFile(t, "my notes.txt", "x") + `"`)
+	require.Len(t, chatView.pending, 1, "Quoted paths with spaces are accepted")
+	cmd = chatView.handleCommand("/detach")
+	assert.Equal(t, "Removed 1 attachments", cmd().(ToastMsg).Text)
+	assert.Empty(t, chatView.pending)
+}
+
+func TestWithAttachments(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	attachments := []Attachment{
+		{Name: "main.go", MediaType: "text/x-go", data: []byte("package main")},
+		{Name: "diagram.png", MediaType: "image/png", data: png},
+		{Name: "archive.zip", MediaType: "application/zip", Size: 2048, data: []byte{0x50, 0x4b, 0x00}},
+	}
+	messages := []model.Message{{Role: "system", Content: "context"}, {Role: "user", Content: "review this"}}
+
+	updated := withAttachments(messages, attachments)
+	assert.Equal(t, "review this", messages[1].Content, "The original messages are unchanged")
+	assert.Equal(t, "context", updated[0].Content)
+	assert.Contains(t, updated[1].Content, "Attached file main.go:\n```\npackage main\n```")
+	assert.Contains(t, updated[1].Content, "Attached file archive.zip (application/zip, 2.0 KB) cannot be shown as text.")
+	assert.NotContains(t, updated[1].Content, "diagram.png")
+	assert.Equal(t, []string{base64.StdEncoding.EncodeToString(png)}, updated[1].Images)
+
+	long := strings.Repeat("a", maxAttachmentText+10)
+	prompt := attachmentPrompt("read", []Attachment{{Name: "big.txt", MediaType: "text/plain", data: []byte(long)}})
+	assert.Contains(t, prompt, "(truncated)")
+	assert.Less(t, len(prompt), maxAttachmentText+100)
+}
+
+func TestConversationLog_SavesLongToolOutputsAsAttachments(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetConversationStore(store)
+
+	output := strings.Repeat("row\n", maxInlineToolOutput)
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "dump the table"})
+	chatView.AddMessage(ChatMessage{Role: "tool", ToolCall: &ToolCallInfo{Name: "query", Result: output}})
+	require.NoError(t, chatView.persistError())
+
+	msg := chatView.messages[len(chatView.messages)-1]
+	assert.Contains(t, msg.ToolCall.Result, "(full output attached as query-output.txt)")
+	assert.Less(t, len(msg.ToolCall.Result), maxInlineToolOutput+100)
+	require.Len(t, msg.Attachments, 1)
+
+	saved, err := store.ListAttachments(storage.AttachmentFilter{MessageIDs: []int64{msg.storedID}})
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	data, err := store.ReadAttachment(saved[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, output, string(data))
+}
+
+func TestChatView_KeepsFullOutputOfSummarizedResults(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetConversationStore(store)
+	chatView.ClearMessages()
+
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "list my orders"})
+	chatView.Update(ToolExecutedUnifiedMsg{Success: true, Result: "You have 40 orders.", FullOutput: "query:\norder 1\norder 2"})
+	require.NoError(t, chatView.persistError())
+
+	msg := chatView.messages[len(chatView.messages)-1]
+	assert.Equal(t, "You have 40 orders.", msg.Content)
+	assert.NotContains(t, chatView.renderSelected("", msg, false), "order 2")
+	assert.Contains(t, chatView.renderSelected("", msg, true), "order 2", "Expanding the message shows the full output")
+
+	saved, err := store.ListAttachments(storage.AttachmentFilter{MessageIDs: []int64{msg.storedID}})
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, fullOutputName, saved[0].Name)
+	data, err := store.ReadAttachment(saved[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "query:\norder 1\norder 2", string(data))
+}
+
+func TestHistoryView_OpensAttachments(t *testing.T) {
+	store := newTestConversationStore(t)
+	_, err := store.CreateConversation("conv", "Reports")
+	require.NoError(t, err)
+	msg := &storage.Message{ConversationID: "conv", Role: "user", Content: "see attached"}
+	require.NoError(t, store.AddMessage(msg))
+	_, err = store.AddAttachment(msg.ID, "q1.csv", "", []byte("a,b"))
+	require.NoError(t, err)
+	_, err = store.AddAttachment(msg.ID, "q2.csv", "", []byte("c,d"))
+	require.NoError(t, err)
+
+	cacheDir, open := attachmentCacheDir, openPath
+	t.Cleanup(func() { attachmentCacheDir, openPath = cacheDir, open })
+	attachmentCacheDir = t.TempDir()
+	var opened []string
+	openPath = func(path string) error {
+		opened = append(opened, path)
+		return nil
+	}
+
+	view := NewHistoryView(DefaultStyles(), DefaultKeyMap())
+	view.SetConversationStore(store)
+	view.SetSize(100, 30)
+	view.Update(view.Load()())
+	assert.Contains(t, view.View(), "  📎 q1.csv (3 B)")
+	assert.Nil(t, view.openSelected(), "Nothing is selected at first")
+
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	assert.Contains(t, view.View(), "▸ 📎 q2.csv", "p wraps around to the last attachment")
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.Contains(t, view.View(), "▸ 📎 q1.csv")
+
+	_, cmd := view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	_, cmd = view.Update(cmd())
+	require.Len(t, opened, 1)
+	assert.Equal(t, "q1.csv", filepath.Base(opened[0]))
+	data, err := os.ReadFile(opened[0])
+	require.NoError(t, err)
+	assert.Equal(t, "a,b", string(data))
+	assert.Equal(t, "Opened "+opened[0], cmd().(ToastMsg).Text)
+}
@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// auditLimit caps how many tool executions the audit view lists
+const auditLimit = 200
+
+// ToolAuditLog reads the recorded tool executions
+type ToolAuditLog interface {
+	ListToolExecutions(filter storage.ToolExecutionFilter) ([]*storage.ToolExecution, error)
+}
+
+// AuditView lists recent tool executions, newest first
+type AuditView struct {
+	width      int
+	height     int
+	styles     Styles
+	viewport   viewport.Model
+	log        ToolAuditLog // nil without storage
+	failedOnly bool
+}
+
+// auditLoadedMsg carries the executions shown in the audit view
+type auditLoadedMsg struct {
+	executions []*storage.ToolExecution
+	err        error
+}
+
+// NewAuditView creates a new audit view
+func NewAuditView(styles Styles) *AuditView {
+	vp := viewport.New(0, 0)
+	vp.SetContent("No tool executions recorded yet.")
+
+	return &AuditView{
+		styles:   styles,
+		viewport: vp,
+	}
+}
+
+// Init initializes the audit view
+func (v *AuditView) Init() tea.Cmd {
+	return nil
+}
+
+// SetAuditLog sets where tool executions are read from
+func (v *AuditView) SetAuditLog(log ToolAuditLog) {
+	v.log = log
+}
+
+// Load returns a command that reads the recorded executions
+func (v *AuditView) Load() tea.Cmd {
+	log := v.log
+	if log == nil {
+		return nil
+	}
+	filter := storage.ToolExecutionFilter{FailedOnly: v.failedOnly, Limit: auditLimit}
+	return func() tea.Msg {
+		executions, err := log.ListToolExecutions(filter)
+		return auditLoadedMsg{executions: executions, err: err}
+	}
+}
+
+// Update handles updates for the audit view
+func (v *AuditView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case auditLoadedMsg:
+		if msg.err != nil {
+			v.viewport.SetContent("Failed to load the tool audit log: " + msg.err.Error())
+			return v, nil
+		}
+		v.viewport.SetContent(renderToolExecutions(msg.executions, v.failedOnly))
+		return v, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return v, func() tea.Msg {
+				return ViewSwitchMsg{ViewType: ChatViewType}
+			}
+		case "f":
+			v.failedOnly = !v.failedOnly
+			return v, v.Load()
+		case "r":
+			return v, v.Load()
+		}
+	}
+
+	var cmd tea.Cmd
+	v.viewport, cmd = v.viewport.Update(msg)
+	return v, cmd
+}
+
+// View renders the audit view
+func (v *AuditView) View() string {
+	if v.width == 0 {
+		return "Loading audit log..."
+	}
+
+	title := "🧾 Tool Audit Log"
+	if v.failedOnly {
+		title += " (failures only)"
+	}
+	header := v.styles.ViewHeader.
+		Width(v.width).
+		Render(title)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		v.viewport.View(),
+		v.styles.DimmedStyle.Render("f: toggle failures only • r: refresh • esc: back"),
+	)
+}
+
+// SetSize sets the size of the audit view
+func (v *AuditView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+	v.viewport.Width = width
+	v.viewport.Height = height - 4 // Account for header and key hints
+}
+
+// renderToolExecutions lists executions one per line: when, which tool, how
+// long it took, and what came back
+func renderToolExecutions(executions []*storage.ToolExecution, failedOnly bool) string {
+	if len(executions) == 0 {
+		if failedOnly {
+			return "No failed tool executions."
+		}
+		return "No tool executions recorded yet."
+	}
+
+	failed := 0
+	for _, exec := range executions {
+		if !exec.Success {
+			failed++
+		}
+	}
+	lines := []string{fmt.Sprintf("%d executions, %d failed", len(executions), failed), ""}
+	for _, exec := range executions {
+		lines = append(lines, describeToolExecution(exec))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeToolExecution summarizes one execution on a line
+func describeToolExecution(exec *storage.ToolExecution) string {
+	status := "✓"
+	outcome := formatBytes(exec.ResultSize)
+	if !exec.Success {
+		status = "✗"
+		outcome = summaryLine(exec.Error)
+	}
+	name := exec.Tool
+	if exec.Server != "" {
+		name = exec.Server + "/" + exec.Tool
+	}
+	return fmt.Sprintf("%s %s %-32s %8s  %s  %s",
+		exec.ExecutedAt.Local().Format("2006-01-02 15:04:05"), status, name,
+		exec.Duration.Round(time.Millisecond), shortHash(exec.ArgumentsHash), outcome)
+}
+
+// shortHash abbreviates an arguments hash; equal prefixes mean repeated calls
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
+
+// formatBytes writes a result size for people
+func formatBytes(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderToolExecutions(t *testing.T) {
+	at := time.Date(2025, 3, 14, 9, 30, 0, 0, time.Local)
+	executions := []*storage.ToolExecution{
+		{Server: "files", Tool: "read_file", ArgumentsHash: "0123456789abcdef", Duration: 1500 * time.Millisecond, Error: "permission\ndenied", ExecutedAt: at.Add(time.Minute)},
+		{Server: "memory", Tool: "search", ArgumentsHash: "fedcba9876543210", Duration: 120 * time.Millisecond, Success: true, ResultSize: 2048, ExecutedAt: at},
+	}
+
+	rendered := renderToolExecutions(executions, false)
+	assert.Contains(t, rendered, "2 executions, 1 failed")
+	assert.Contains(t, rendered, "2025-03-14 09:31:00 ✗ files/read_file")
+	assert.Contains(t, rendered, "1.5s  01234567  permission denied")
+	assert.Contains(t, rendered, "2025-03-14 09:30:00 ✓ memory/search")
+	assert.Contains(t, rendered, "120ms  fedcba98  2.0 KB")
+
+	assert.Equal(t, "No tool executions recorded yet.", renderToolExecutions(nil, false))
+	assert.Equal(t, "No failed tool executions.", renderToolExecutions(nil, true))
+}
+
+func TestApplication_AuditViewLoadsOnOpen(t *testing.T) {
+	store := newTestConversationStore(t)
+	require.NoError(t, store.RecordToolExecution(&storage.ToolExecution{Server: "memory", Tool: "search", Success: true}))
+	require.NoError(t, store.RecordToolExecution(&storage.ToolExecution{Server: "files", Tool: "read_file", Error: "denied"}))
+
+	app := NewApplication(&MockModel{})
+	app.auditView.SetAuditLog(store)
+	app.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	_, cmd := app.Update(ViewSwitchMsg{ViewType: AuditViewType})
+	require.NotNil(t, cmd)
+	app.Update(cmd())
+	view := app.auditView.View()
+	assert.Contains(t, view, "memory/search")
+	assert.Contains(t, view, "files/read_file")
+
+	// f narrows the list to failures
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	require.NotNil(t, cmd)
+	app.Update(cmd())
+	view = app.auditView.View()
+	assert.Contains(t, view, "failures only")
+	assert.Contains(t, view, "files/read_file")
+	assert.NotContains(t, view, "memory/search")
+}
+
+func TestChatView_AuditCommandSwitchesView(t *testing.T) {
+	v := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	cmd := v.handleCommand("/audit")
+	require.NotNil(t, cmd)
+	assert.Equal(t, ViewSwitchMsg{ViewType: AuditViewType}, cmd())
+}
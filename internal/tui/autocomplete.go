@@ -0,0 +1,253 @@
+package tui
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxSuggestions limits how many completions are shown in the popup
+const maxSuggestions = 6
+
+// ChatCommand describes a slash command available in the chat input
+type ChatCommand struct {
+	Name        string
+	Description string
+}
+
+// ChatCommands is the registered list of slash commands, used for autocomplete
+var ChatCommands = []ChatCommand{
+	{Name: "/mcp", Description: "Switch to MCP servers view"},
+	{Name: "/servers", Description: "Switch to MCP servers view"},
+	{Name: "/tools", Description: "Switch to tools view"},
+	{Name: "/help", Description: "Switch to help view"},
+	{Name: "/history", Description: "Switch to history view"},
+	{Name: "/audit", Description: "Switch to the tool audit log"},
+	{Name: "/stats", Description: "Show token use and latency per conversation"},
+	{Name: "/usage", Description: "Show tokens and cost this session"},
+	{Name: "/export", Description: "Export the conversation (markdown, json, html)"},
+	{Name: "/share", Description: "Save a sanitized transcript to share, uploading it if configured"},
+	{Name: "/resume", Description: "Restore the most recent saved conversation"},
+	{Name: "/search", Description: "Search saved conversations by words and meaning"},
+	{Name: "/tag", Description: "Tag the latest message"},
+	{Name: "/untag", Description: "Remove a tag from the latest message"},
+	{Name: "/pin", Description: "Pin the latest message"},
+	{Name: "/unpin", Description: "Unpin the latest message"},
+	{Name: "/pinned", Description: "List pinned messages"},
+	{Name: "/remember", Description: "Remember a fact across conversations"},
+	{Name: "/memories", Description: "List remembered facts"},
+	{Name: "/forget", Description: "Delete a remembered fact"},
+	{Name: "/attach", Description: "Send a file or image with your next message"},
+	{Name: "/detach", Description: "Remove the files attached to your next message"},
+	{Name: "/regenerate", Description: "Regenerate the last response, optionally at another temperature"},
+	{Name: "/retry", Description: "Regenerate the last response"},
+	{Name: "/edit", Description: "Edit a previous message and branch from it"},
+	{Name: "/undo", Description: "Remove your last message and reverse its tool calls"},
+	{Name: "/approve", Description: "Run the plan waiting for approval"},
+	{Name: "/cancel", Description: "Discard the plan or question waiting for an answer"},
+	{Name: "/compact", Description: "Fold earlier messages into the conversation summary now"},
+	{Name: "/persona", Description: "List personas or switch to one"},
+	{Name: "/template", Description: "List saved prompt templates or fill one in and send it"},
+	{Name: "/mode", Description: "List session modes or switch to one"},
+	{Name: "/dryrun", Description: "Validate tool calls without running them"},
+	{Name: "/chat", Description: "Stay in chat view"},
+	{Name: "/commands", Description: "List all commands"},
+	{Name: "/exit", Description: "Exit the application"},
+	{Name: "/quit", Description: "Exit the application"},
+}
+
+// Suggestion is a single autocomplete candidate
+type Suggestion struct {
+	Value       string
+	Description string
+}
+
+// Autocomplete tracks the completion popup state for the chat input
+type Autocomplete struct {
+	suggestions []Suggestion
+	selected    int
+	token       string // the input token being completed
+	dismissed   string // input value for which the popup was dismissed
+
+	arguments map[string]func() []string // argument sources by command
+	argsFor   string                     // command whose arguments are loaded
+	args      []string
+}
+
+// CompleteArguments makes the first argument of command complete from the
+// names source returns. Source is called once each time the argument is
+// started, not on every keystroke.
+func (a *Autocomplete) CompleteArguments(command string, source func() []string) {
+	if a.arguments == nil {
+		a.arguments = make(map[string]func() []string)
+	}
+	a.arguments[command] = source
+}
+
+// Visible reports whether the popup has suggestions to show
+func (a *Autocomplete) Visible() bool {
+	return len(a.suggestions) > 0
+}
+
+// Suggestions returns the current suggestions
+func (a *Autocomplete) Suggestions() []Suggestion {
+	return a.suggestions
+}
+
+// Selected returns the highlighted suggestion
+func (a *Autocomplete) Selected() (Suggestion, bool) {
+	if !a.Visible() {
+		return Suggestion{}, false
+	}
+	return a.suggestions[a.selected], true
+}
+
+// Next moves the selection down, wrapping around
+func (a *Autocomplete) Next() {
+	if a.Visible() {
+		a.selected = (a.selected + 1) % len(a.suggestions)
+	}
+}
+
+// Prev moves the selection up, wrapping around
+func (a *Autocomplete) Prev() {
+	if a.Visible() {
+		a.selected = (a.selected - 1 + len(a.suggestions)) % len(a.suggestions)
+	}
+}
+
+// Dismiss hides the popup until the input changes
+func (a *Autocomplete) Dismiss(input string) {
+	a.dismissed = input
+	a.suggestions = nil
+	a.selected = 0
+}
+
+// Update recomputes suggestions for the given input. Slash commands complete
+// when the input starts with "/" and has no arguments yet, and so do the
+// arguments of commands with a source; tool mentions complete when the last
+// word starts with "@".
+func (a *Autocomplete) Update(input string, toolNames func() []string) {
+	if input == a.dismissed {
+		return
+	}
+	a.dismissed = ""
+
+	previous := a.token
+	a.token = ""
+	a.suggestions = nil
+	command, argument, hasArgument := strings.Cut(input, " ")
+	source := a.arguments[command]
+	if !hasArgument || source == nil {
+		a.argsFor = ""
+	}
+
+	switch {
+	case strings.HasPrefix(input, "/") && !strings.Contains(input, " "):
+		a.token = input
+		for _, cmd := range ChatCommands {
+			if strings.HasPrefix(cmd.Name, strings.ToLower(input)) && cmd.Name != input {
+				a.suggestions = append(a.suggestions, Suggestion{Value: cmd.Name, Description: cmd.Description})
+			}
+		}
+
+	case hasArgument && source != nil && !strings.Contains(argument, " "):
+		if a.argsFor != command {
+			a.argsFor, a.args = command, source()
+		}
+		a.token = input
+		for _, name := range a.args {
+			if strings.HasPrefix(strings.ToLower(name), strings.ToLower(argument)) && name != argument {
+				a.suggestions = append(a.suggestions, Suggestion{Value: command + " " + name})
+			}
+		}
+
+	case lastWord(input) != "" && strings.HasPrefix(lastWord(input), "@"):
+		a.token = lastWord(input)
+		prefix := strings.ToLower(strings.TrimPrefix(a.token, "@"))
+		names := toolNames()
+		sort.Strings(names)
+		for _, name := range names {
+			if strings.HasPrefix(strings.ToLower(name), prefix) && "@"+name != a.token {
+				a.suggestions = append(a.suggestions, Suggestion{Value: "@" + name, Description: "tool"})
+			}
+		}
+	}
+
+	if len(a.suggestions) > maxSuggestions {
+		a.suggestions = a.suggestions[:maxSuggestions]
+	}
+	if a.token != previous || a.selected >= len(a.suggestions) {
+		a.selected = 0
+	}
+}
+
+// Apply replaces the token being completed in input with the selected suggestion
+func (a *Autocomplete) Apply(input string) string {
+	selected, ok := a.Selected()
+	if !ok {
+		return input
+	}
+
+	completed := strings.TrimSuffix(input, a.token) + selected.Value
+	if !strings.HasPrefix(selected.Value, "/") {
+		completed += " "
+	}
+
+	a.Dismiss(completed)
+	return completed
+}
+
+// View renders the suggestion popup
+func (a *Autocomplete) View(styles Styles, width int) string {
+	if !a.Visible() {
+		return ""
+	}
+
+	lines := make([]string, len(a.suggestions))
+	for i, s := range a.suggestions {
+		line := s.Value
+		if s.Description != "" {
+			line += "  " + styles.DimmedStyle.Render(s.Description)
+		}
+		if i == a.selected {
+			line = styles.HighlightStyle.Render(s.Value)
+			if s.Description != "" {
+				line += "  " + s.Description
+			}
+		}
+		lines[i] = " " + line
+	}
+
+	return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
+
+// lastWord returns the final whitespace-separated word of s, or "" if s ends with a space
+func lastWord(s string) string {
+	if s == "" || strings.HasSuffix(s, " ") {
+		return ""
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// toolNamesFromAgent lists the names of tools registered with the agent
+func toolNamesFromAgent(agent AgentInterface) []string {
+	if agent == nil {
+		return nil
+	}
+	tools, err := agent.GetMCPTools(context.Background())
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return names
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noTools() []string { return nil }
+
+func TestAutocomplete_SlashCommands(t *testing.T) {
+	var ac Autocomplete
+
+	ac.Update("/h", noTools)
+	require.True(t, ac.Visible())
+	values := suggestionValues(ac.Suggestions())
+	assert.Equal(t, []string{"/help", "/history"}, values)
+
+	// Arguments close the popup
+	ac.Update("/help me", noTools)
+	assert.False(t, ac.Visible())
+
+	// An exact match needs no completion
+	ac.Update("/tools", noTools)
+	assert.False(t, ac.Visible())
+}
+
+func TestAutocomplete_ToolMentions(t *testing.T) {
+	var ac Autocomplete
+	tools := func() []string { return []string{"store_memory", "search", "stats"} }
+
+	ac.Update("please use @s", tools)
+	assert.Equal(t, []string{"@search", "@stats", "@store_memory"}, suggestionValues(ac.Suggestions()))
+
+	ac.Update("please use @st", tools)
+	assert.Equal(t, []string{"@stats", "@store_memory"}, suggestionValues(ac.Suggestions()))
+
+	ac.Next()
+	assert.Equal(t, "please use @store_memory ", ac.Apply("please use @st"))
+	assert.False(t, ac.Visible(), "Accepting a suggestion closes the popup")
+}
+
+func TestAutocomplete_NavigationWraps(t *testing.T) {
+	var ac Autocomplete
+	ac.Update("/h", noTools)
+
+	ac.Prev()
+	selected, ok := ac.Selected()
+	require.True(t, ok)
+	assert.Equal(t, "/history", selected.Value)
+
+	ac.Next()
+	selected, _ = ac.Selected()
+	assert.Equal(t, "/help", selected.Value)
+}
+
+func TestAutocomplete_DismissUntilInputChanges(t *testing.T) {
+	var ac Autocomplete
+	ac.Update("/h", noTools)
+	ac.Dismiss("/h")
+	assert.False(t, ac.Visible())
+
+	ac.Update("/h", noTools)
+	assert.False(t, ac.Visible(), "Popup stays dismissed for the same input")
+
+	ac.Update("/hi", noTools)
+	assert.True(t, ac.Visible())
+}
+
+func TestChatView_AutocompleteKeys(t *testing.T) {
+	mockAgent := &MockAgentForChat{tools: []Tool{{Name: "search"}}}
+	view := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), nil, mockAgent)
+
+	for _, r := range "find @se" {
+		view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	require.True(t, view.CompletionVisible())
+
+	view.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, "find @search ", view.GetInput())
+	assert.False(t, view.CompletionVisible())
+}
+
+func suggestionValues(suggestions []Suggestion) []string {
+	values := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		values[i] = s.Value
+	}
+	return values
+}
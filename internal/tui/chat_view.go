@@ -3,15 +3,24 @@ package tui
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
 	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
 )
 
 // ChatMessage represents a message in the chat
@@ -21,6 +30,13 @@ type ChatMessage struct {
 	Timestamp string
 	ToolCall  *ToolCallInfo
 	Error     string
+	Transient bool // shown but never saved, like /search results
+	Tags      []string // set with /tag
+	Pinned    bool     // set with /pin
+	Attachments []Attachment // files sent with the message, or a long tool output
+	FullOutput  string       // tool output Content summarizes, shown when the message is expanded
+	Cached      bool         // answered from the agent's response cache
+	storedID  int64 // ID of the saved copy; zero until saved
 }
 
 // ToolCallInfo contains information about a tool call
@@ -49,8 +65,101 @@ type ChatView struct {
 	conversationContext *model.ConversationContext // Persistent context with extracted metadata
 	currentUserMessage  string
 	availableTools      []model.ToolDefinition
+	// Tool results go back to the model, which may call more tools, up to
+	// maxIterations times per message; iterations counts them for the
+	// current message, which was sent with turnOptions
+	maxIterations int
+	iterations    int
+	turnOptions   model.GenerateOptions
+	// Once a turn's messages fill compactThreshold of the context window,
+	// all but the latest compactKeep are summarized
+	compactThreshold float64
+	compactKeep      int
+	// Tool calls shown as a plan, waiting for the user to approve them
+	pendingPlan *ToolCallDetectedMsg
+	// Tool calls waiting for the user to supply values the model left out
+	pendingQuestion *clarification
+	// Prompt template whose variables /template is asking for
+	pendingTemplate *templateFill
+	// Autocomplete popup for slash commands and @tool mentions
+	completion Autocomplete
+	// Mouse selection: index of the selected message (-1 for none), which
+	// messages show full details, and the first content line of each message
+	selected       int
+	expanded       map[int]bool
+	messageOffsets []int
+	// Generation parameters sent with every model request
+	options model.GenerateOptions
+	// Personas /persona switches between; persona is the active one (nil for none)
+	personas []Persona
+	persona  *Persona
+	// The current message's question and context, kept to cache its answer
+	// ("" when it isn't cached); refresh makes the next message skip the cache
+	cacheQuestion        string
+	cacheQuestionContext string
+	refresh              bool
+	// Session mode of the conversation ("" for chat), and the one new
+	// conversations start in
+	mode        string
+	defaultMode string
+	// Tokens used this session, in the current conversation, and per tool,
+	// and what the model charges for them
+	usage   sessionUsage
+	pricing model.Pricing
+	// The message the current turn responds to, with any interjections, and
+	// its attachments; the turn's model and tool calls stop when turnCancel
+	// is called or turnTimeout runs out (0 allows any time)
+	turnText        string
+	turnAttachments []Attachment
+	turnCtx         context.Context
+	turnCancel      context.CancelFunc
+	turnTimeout     time.Duration
+	// Index of the user message being edited (-1 for none); sending the edit
+	// branches the conversation from that message
+	editing int
+	// Suggested next prompts for the latest tool result, chosen with alt+1-9
+	followUps []model.FollowUp
+	// Earlier prompts, recalled with up and down
+	history PromptHistory
+	// Saves messages as they are added (nil without storage); resume restores
+	// the most recent conversation when the view starts
+	log    *conversationLog
+	resume bool
+	// Messages dropped from the start of the chat to bound its memory, and
+	// whether saved ones are being loaded again
+	hidden         int
+	loadingEarlier bool
+	// Rendered messages, reused until they change or the width does
+	renders renderCache
+	// Backs /search (nil without storage)
+	searcher ConversationSearcher
+	// Send pinned messages with every request
+	pinnedContext bool
+	// Built-in memory (nil when unavailable); up to recallLimit related
+	// memories are sent with each message
+	memory      MemoryStore
+	recallLimit int
+	// Indexed project files (nil when unavailable); up to retrieveLimit
+	// related parts are sent with each message
+	project       ProjectIndex
+	retrieveLimit int
+	// Files added with /attach, sent with the next message
+	pending []Attachment
+	// State before each message sent, which /undo goes back to, oldest first
+	turns []turnCheckpoint
+	// Progress indicator shown while waiting: what the agent is doing and since when
+	spinner     spinner.Model
+	phase       string
+	waitStarted time.Time
 }
 
+// Phases reported by the progress indicator while waiting for a response
+const (
+	phaseClassifying = "classifying intent"
+	phaseGenerating  = "generating response"
+	phaseReviewing   = "reviewing tool results"
+)
+
 // NewChatView creates a new chat view
 func NewChatView(styles Styles, keymap KeyMap, m model.Model) *ChatView {
 	return NewChatViewWithAgent(styles, keymap, m, nil)
@@ -75,11 +184,21 @@ func NewChatViewWithAgent(styles Styles, keymap KeyMap, m model.Model, agent Age
 		model:    m,
 		agent:    agent,
 		focused:  true,
+		selected: -1,
+		expanded: make(map[int]bool),
+		editing:  -1,
+		spinner:  spinner.New(spinner.WithSpinner(spinner.Dot)),
+		options:  model.GenerateOptions{Temperature: 0.7, MaxTokens: 2048},
 		conversationContext: &model.ConversationContext{
-			SessionType:       "chat",
-			ExtractedMetadata: make(map[string]interface{}),
+			SessionType: "chat",
+			Entities:    model.NewEntityStore(),
 		},
 	}
+	if library, ok := agent.(templateLibrary); ok {
+		chatView.completion.CompleteArguments("/template", func() []string {
+			return templateNames(library)
+		})
+	}
 	
 	// Add welcome message with command hints
 	welcomeMsg := ChatMessage{
@@ -94,7 +213,11 @@ func NewChatViewWithAgent(styles Styles, keymap KeyMap, m model.Model, agent Age
 
 // Init initializes the chat view
 func (v *ChatView) Init() tea.Cmd {
-	return textinput.Blink
+	cmds := []tea.Cmd{textinput.Blink, v.history.Load()}
+	if v.resume {
+		cmds = append(cmds, v.resumeConversation())
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update handles updates for the chat view
@@ -111,7 +234,7 @@ func (v *ChatView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Add error message
 				errorMsg := ChatMessage{
 					Role:      "assistant",
-					Content:   "",
+					Content:   turnRecoverySuggestion(v.turnContext(), msg.Error),
 					Error:     msg.Error.Error(),
 					Timestamp: time.Now().Format("15:04"),
 				}
@@ -124,41 +247,28 @@ func (v *ChatView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					Timestamp: time.Now().Format("15:04"),
 				}
 				v.AddMessage(assistantMsg)
+				v.recordUsage(msg.Response)
+				v.cacheAnswer(msg.Response.Content)
 			}
 		}
-		return v, nil
+		return v, v.summarize()
 		
 	case ToolCallDetectedMsg:
 		// Handle tool call detection
 		if msg.RequestID == v.requestID {
-			v.waitingForResponse = false
-			
 			// Store conversation context for tool result processing
 			v.conversationHistory = msg.ConversationHistory
 			v.currentUserMessage = msg.UserMessage
 			v.availableTools = msg.Tools
 			
-			// Add a more natural assistant message
-			var toolCallContent string
-			if len(msg.ToolCalls) == 1 {
-				toolCallContent = fmt.Sprintf("Let me help you with that using the %s tool...", msg.ToolCalls[0].Name)
-			} else {
-				toolNames := make([]string, len(msg.ToolCalls))
-				for i, tc := range msg.ToolCalls {
-					toolNames[i] = tc.Name
-				}
-				toolCallContent = fmt.Sprintf("I'll use several tools to help: %s", strings.Join(toolNames, ", "))
-			}
-				
-			assistantMsg := ChatMessage{
-				Role:      "assistant",
-				Content:   toolCallContent,
-				Timestamp: time.Now().Format("15:04"),
+			v.recordUsage(msg.Response)
+
+			// Ask the user for required values the model left out, and
+			// then for approval of plans that need it
+			if v.clarify(msg) {
+				return v, nil
 			}
-			v.AddMessage(assistantMsg)
-			
-			// Execute the tools using unified pathway
-			return v, v.executeToolCallsUnified(msg.ToolCalls, msg.RequestID, msg.UserMessage)
+			return v, v.toolCallsReady(msg)
 		}
 		return v, nil
 		
@@ -177,9 +287,13 @@ func (v *ChatView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle tool execution completion using intelligent result processing
 		if msg.Error != nil {
 			// Go error occurred during execution
+			content := recoverySuggestion(msg.Error)
+			if content == "" {
+				content = "I encountered an issue while executing that tool. Please try again."
+			}
 			errorMsg := ChatMessage{
 				Role:      "assistant",
-				Content:   "I encountered an issue while executing that tool. Please try again.",
+				Content:   content,
 				Timestamp: time.Now().Format("15:04:05"),
 				Error:     msg.Error.Error(),
 			}
@@ -217,12 +331,18 @@ func (v *ChatView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return v, nil
 
 	case ToolExecutedUnifiedMsg:
+		// Results of a turn the user interrupted are dropped
+		if msg.RequestID != "" && msg.RequestID != v.requestID {
+			return v, nil
+		}
 		// Handle unified tool execution results - these are already processed natural language
 		if msg.Success {
+			v.followUps = msg.FollowUps
 			resultMsg := ChatMessage{
-				Role:      "assistant",
-				Content:   msg.Result,
-				Timestamp: time.Now().Format("15:04:05"),
+				Role:       "assistant",
+				Content:    msg.Result,
+				Timestamp:  time.Now().Format("15:04:05"),
+				FullOutput: msg.FullOutput,
 			}
 			v.AddMessage(resultMsg)
 		} else {
@@ -233,51 +353,169 @@ func (v *ChatView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			v.AddMessage(errorMsg)
 		}
+		if msg.RequestID != "" && msg.RequestID == v.requestID && len(msg.Feedback) > 0 {
+			if v.iterations < v.maxIterations {
+				// Let the model check the results and decide what to do next
+				v.iterations++
+				messages := append(append([]model.Message{}, v.conversationHistory...), msg.Feedback...)
+				return v, tea.Batch(v.startWaiting(phaseReviewing), v.continueTurn(messages, msg.RequestID))
+			}
+			if v.maxIterations > 0 {
+				v.waitingForResponse = false
+				return v, tea.Batch(v.summarize(), toastCmd(fmt.Sprintf("Stopped after %d rounds of tool calls", v.maxIterations), ToastInfo))
+			}
+		}
 		v.waitingForResponse = false
+		return v, v.summarize()
+
+	case conversationRestoredMsg:
+		return v, v.restoreConversation(msg)
+
+	case earlierMessagesMsg:
+		return v, v.showEarlier(msg)
+
+	case conversationSummarizedMsg:
+		return v, v.summarized(msg)
+
+	case undoneMsg:
+		return v, v.undone(msg)
+
+	case sharedMsg:
+		return v, v.shared(msg)
+
+	case searchResultsMsg:
+		return v, v.showSearchResults(msg)
+
+	case promptHistoryLoadedMsg:
+		if msg.err != nil {
+			return v, toastCmd("Failed to load prompt history: "+msg.err.Error(), ToastWarning)
+		}
+		v.history.loaded(msg.prompts)
 		return v, nil
 
+	case spinner.TickMsg:
+		// Let the animation stop once the response has arrived
+		if !v.waitingForResponse {
+			return v, nil
+		}
+		v.spinner, cmd = v.spinner.Update(msg)
+		return v, cmd
+
+	case tea.MouseMsg:
+		return v, v.handleMouse(msg)
+
 	case tea.KeyMsg:
-		// Don't accept input if waiting for response
+		// Act on the message selected with the mouse
+		if v.selected >= 0 && key.Matches(msg, v.keymap.EditMessage) {
+			return v, v.startEdit(v.selected)
+		}
+		if v.selected >= 0 {
+			switch msg.String() {
+			case "ctrl+y":
+				return v, v.copySelectedMessage()
+			case "esc":
+				v.selectMessage(-1)
+				return v, nil
+			}
+		}
+
+		// Navigate and accept autocomplete suggestions while the popup is open
+		if v.completion.Visible() {
+			switch msg.String() {
+			case "up":
+				v.completion.Prev()
+				return v, nil
+			case "down":
+				v.completion.Next()
+				return v, nil
+			case "tab", "enter":
+				v.input.SetValue(v.completion.Apply(v.input.Value()))
+				v.input.CursorEnd()
+				return v, nil
+			case "esc":
+				v.completion.Dismiss(v.input.Value())
+				return v, nil
+			}
+		}
+
+		// While waiting, enter interrupts the response with the text typed
 		if v.waitingForResponse && msg.String() == "enter" {
-			return v, nil
+			return v, v.interjectInput()
 		}
 		
+		switch {
+		case key.Matches(msg, v.keymap.Regenerate):
+			return v, v.regenerate(v.requestOptions())
+		case key.Matches(msg, v.keymap.EditMessage):
+			return v, v.startEdit(v.lastUserMessage(1))
+		case key.Matches(msg, v.keymap.FollowUp):
+			n, _ := strconv.Atoi(strings.TrimPrefix(msg.String(), "alt+"))
+			v.useFollowUp(n)
+			return v, nil
+		}
+
 		switch msg.String() {
 		case "enter":
 			if v.focused {
 				userInput := strings.TrimSpace(v.input.Value())
 				if userInput == "" {
+					// An empty answer takes a template variable's default
+					if v.pendingTemplate != nil {
+						return v, v.answerTemplateVariable("")
+					}
 					return v, nil
 				}
+				saved := v.history.Add(userInput)
+
+				// A plan waiting for approval takes the answer to it
+				if v.pendingPlan != nil && !strings.HasPrefix(userInput, "/") {
+					v.input.SetValue("")
+					return v, tea.Batch(saved, v.answerPlan(userInput))
+				}
+				// as does a question about a missing tool parameter
+				if v.pendingQuestion != nil && !strings.HasPrefix(userInput, "/") {
+					v.input.SetValue("")
+					return v, tea.Batch(saved, v.answerQuestion(userInput))
+				}
+				// and a template's variable
+				if v.pendingTemplate != nil && !strings.HasPrefix(userInput, "/") {
+					v.input.SetValue("")
+					return v, tea.Batch(saved, v.answerTemplateVariable(userInput))
+				}
 
 				// Check if it's a command (starts with /)
 				if strings.HasPrefix(userInput, "/") {
-					return v, v.handleCommand(userInput)
+					return v, tea.Batch(saved, v.handleCommand(userInput))
 				}
 
-				// Regular chat message
-				userMsg := ChatMessage{
-					Role:      "user",
-					Content:   userInput,
-					Timestamp: time.Now().Format("15:04:05"),
+				// Sending an edited message branches the conversation from it
+				var branched tea.Cmd
+				if v.editing >= 0 {
+					branched = v.branchAt(v.editing)
 				}
-				v.AddMessage(userMsg)
-				
+
 				// Clear input
 				v.input.SetValue("")
 				
-				// Generate ID for this request
-				v.requestID = fmt.Sprintf("req_%d", time.Now().UnixNano())
-				v.waitingForResponse = true
-				
-				// Send to model
-				if v.agent != nil {
-					// Use tool-aware response generation
-					return v, v.generateResponseWithTools(userInput, v.requestID)
-				} else {
-					// Fallback to regular model response
-					return v, GenerateResponse(v.model, userInput, v.requestID)
-				}
+				return v, tea.Batch(saved, branched, v.sendMessage(userInput, v.takeAttachments(), v.requestOptions()))
+			}
+		case "up":
+			if prompt, ok := v.history.Prev(v.input.Value()); ok {
+				v.input.SetValue(prompt)
+				v.input.CursorEnd()
+			}
+			return v, nil
+		case "down":
+			if prompt, ok := v.history.Next(); ok {
+				v.input.SetValue(prompt)
+				v.input.CursorEnd()
+			}
+			return v, nil
+		case "esc":
+			if v.editing >= 0 {
+				v.editing = -1
+				v.input.SetValue("")
+				return v, nil
 			}
 		case "ctrl+l":
 			v.input.SetValue("")
@@ -289,9 +527,17 @@ func (v *ChatView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	v.input, cmd = v.input.Update(msg)
 	cmds = append(cmds, cmd)
 
+	// Refresh autocomplete suggestions for the new input
+	v.completion.Update(v.input.Value(), func() []string {
+		return toolNamesFromAgent(v.agent)
+	})
+
 	// Update viewport
 	v.viewport, cmd = v.viewport.Update(msg)
 	cmds = append(cmds, cmd)
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "pgup" {
+		cmds = append(cmds, v.loadEarlier())
+	}
 
 	return v, tea.Batch(cmds...)
 }
@@ -307,8 +553,11 @@ func (v *ChatView) View() string {
 		Width(v.width).
 		Render("💬 Chat")
 
-	// Input section
+	// Input section, with the autocomplete popup directly above it
 	inputSection := v.renderInput()
+	if popup := v.completion.View(v.styles, v.width); popup != "" {
+		inputSection = lipgloss.JoinVertical(lipgloss.Left, popup, inputSection)
+	}
 
 	// Calculate heights
 	headerHeight := lipgloss.Height(header)
@@ -340,6 +589,10 @@ func (v *ChatView) SetSize(width, height int) {
 // AddMessage adds a message to the chat
 func (v *ChatView) AddMessage(msg ChatMessage) {
 	v.messages = append(v.messages, msg)
+	if v.log != nil {
+		v.log.record(v.messages)
+	}
+	v.trimMessages()
 	v.viewport.SetContent(v.renderMessages())
 	v.viewport.GotoBottom()
 }
@@ -347,9 +600,131 @@ func (v *ChatView) AddMessage(msg ChatMessage) {
 // ClearMessages clears all messages
 func (v *ChatView) ClearMessages() {
 	v.messages = []ChatMessage{}
+	v.hidden = 0
+	v.selected = -1
+	v.expanded = make(map[int]bool)
+	v.editing = -1
+	v.followUps = nil
+	v.pendingPlan = nil
+	v.pendingQuestion = nil
+	v.pendingTemplate = nil
+	v.turns = nil
+	v.endTurn()
+	v.turnText, v.turnAttachments = "", nil
+	v.usage.conversation = usageTotals{}
+	if v.log != nil {
+		v.log.restart(0)
+	}
+	v.setMode(v.defaultMode)
 	v.viewport.SetContent("")
 }
 
+// SetConversationStore saves every message added from now on to store. Messages
+// already shown, such as the welcome, are not saved.
+func (v *ChatView) SetConversationStore(store ConversationStore) {
+	v.log = &conversationLog{store: store, skip: len(v.messages)}
+}
+
+// ResumeOnInit makes Init restore the most recent saved conversation
+func (v *ChatView) ResumeOnInit() {
+	v.resume = true
+}
+
+// resumeConversation returns a command that loads the most recent saved conversation
+func (v *ChatView) resumeConversation() tea.Cmd {
+	if v.log == nil {
+		return toastCmd("Conversation history is unavailable", ToastWarning)
+	}
+	if v.waitingForResponse {
+		return toastCmd("Wait for the current response before resuming", ToastWarning)
+	}
+	return v.log.latest()
+}
+
+// restoreConversation replaces the chat with a saved conversation and continues saving to it
+func (v *ChatView) restoreConversation(msg conversationRestoredMsg) tea.Cmd {
+	if msg.err != nil {
+		return toastCmd("Failed to resume conversation: "+msg.err.Error(), ToastError)
+	}
+	if msg.conversation == nil {
+		return toastCmd("No earlier conversation to resume", ToastInfo)
+	}
+
+	messages := fromStorageMessages(msg.messages, msg.attachments)
+	v.ClearMessages()
+	v.messages = messages
+	if mode := findMode(msg.conversation.Mode); mode != nil {
+		v.setMode(mode.Name)
+	}
+	v.log.id = msg.conversation.ID
+	v.log.summary = msg.conversation.Summary
+	v.dropMessages(len(messages) - messageWindow)
+	v.refreshMessages()
+	v.viewport.GotoBottom()
+	return toastCmd(fmt.Sprintf("Resumed %q (%d messages)", msg.conversation.Title, len(messages)), ToastSuccess)
+}
+
+// recordUsage adds a model response to the session's usage and the statistics
+// of the conversation, timing it from the start of the wait when the model
+// doesn't report a duration
+func (v *ChatView) recordUsage(response *model.Response) {
+	if response == nil {
+		return
+	}
+	v.usage.add(response)
+	if v.log == nil {
+		return
+	}
+	latency := response.Duration
+	if latency == 0 {
+		latency = time.Since(v.waitStarted)
+	}
+	v.log.recordUsage(response, latency)
+}
+
+// persistError returns a failure to save the conversation since the last call
+func (v *ChatView) persistError() error {
+	if v.log == nil {
+		return nil
+	}
+	return v.log.takeError()
+}
+
+// SetPromptStore sets where prompts are saved; Init loads the earlier ones
+// from it for up/down recall
+func (v *ChatView) SetPromptStore(store PromptStore) {
+	v.history.SetStore(store)
+}
+
+// SetGenerateOptions sets the generation parameters used for model requests
+func (v *ChatView) SetGenerateOptions(options model.GenerateOptions) {
+	v.options = options
+}
+
+// SetTemperature changes the temperature of later model requests, keeping
+// the other generation parameters
+func (v *ChatView) SetTemperature(temperature float64) {
+	v.options.Temperature = temperature
+}
+
+// SetMaxIterations sets how many times a message's tool results go back to
+// the model, which may call more tools each time, before they are shown as
+// the answer
+func (v *ChatView) SetMaxIterations(n int) {
+	v.maxIterations = n
+}
+
+// SetTurnTimeout sets the time a message's model and tool calls may take
+// together before they are stopped; 0 allows any time
+func (v *ChatView) SetTurnTimeout(timeout time.Duration) {
+	v.turnTimeout = timeout
+}
+
+// CompletionVisible reports whether the autocomplete popup is open
+func (v *ChatView) CompletionVisible() bool {
+	return v.completion.Visible()
+}
+
 // GetInput returns the current input value
 func (v *ChatView) GetInput() string {
 	return v.input.Value()
@@ -367,7 +742,7 @@ func (v *ChatView) handleCommand(input string) tea.Cmd {
 	}
 	
 	command := strings.ToLower(parts[0])
-	// args := parts[1:] // Reserved for future use with command arguments
+	args := parts[1:]
 	
 	// Add command to chat history
 	commandMsg := ChatMessage{
@@ -399,6 +774,91 @@ func (v *ChatView) handleCommand(input string) tea.Cmd {
 		return func() tea.Msg {
 			return ViewSwitchMsg{ViewType: HistoryViewType}
 		}
+	case "/audit":
+		return func() tea.Msg {
+			return ViewSwitchMsg{ViewType: AuditViewType}
+		}
+	case "/stats":
+		return func() tea.Msg {
+			return ViewSwitchMsg{ViewType: StatsViewType}
+		}
+	case "/export":
+		return v.exportConversation(args)
+	case "/share":
+		return v.shareConversation()
+	case "/resume":
+		return v.resumeConversation()
+	case "/search":
+		return v.searchConversations(strings.Join(args, " "))
+	case "/tag", "/untag", "/pin", "/unpin":
+		return v.markMessage(command, args)
+	case "/pinned":
+		return v.listPinned()
+	case "/remember":
+		return v.remember(strings.Join(args, " "))
+	case "/memories":
+		return v.listMemories(strings.Join(args, " "))
+	case "/forget":
+		return v.forget(strings.Join(args, " "))
+	case "/attach":
+		return v.attach(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input), parts[0])))
+	case "/detach":
+		return v.detach()
+	case "/approve":
+		return v.approvePlan()
+	case "/cancel":
+		if v.pendingQuestion != nil {
+			return v.cancelQuestion()
+		}
+		if v.pendingTemplate != nil {
+			return v.cancelTemplate()
+		}
+		return v.cancelPlan()
+	case "/compact":
+		return v.compact()
+	case "/persona":
+		return v.switchPersona(strings.Join(args, " "))
+	case "/template":
+		return v.useTemplate(strings.Join(args, " "))
+	case "/mode":
+		return v.switchMode(strings.Join(args, " "))
+	case "/dryrun":
+		return v.setDryRun(strings.Join(args, " "))
+	case "/usage":
+		v.showUsage()
+		return nil
+	case "/undo":
+		return v.undo()
+	case "/regenerate", "/retry":
+		options := v.requestOptions()
+		if len(args) > 0 {
+			temperature, err := strconv.ParseFloat(args[0], 64)
+			if err != nil || temperature < 0 || temperature > 2 {
+				v.AddMessage(ChatMessage{
+					Role:      "assistant",
+					Content:   "Usage: /regenerate [temperature between 0 and 2]",
+					Timestamp: time.Now().Format("15:04:05"),
+				})
+				return nil
+			}
+			options.Temperature = temperature
+		}
+		return v.regenerate(options)
+	case "/edit":
+		n := 1
+		if len(args) > 0 {
+			parsed, err := strconv.Atoi(args[0])
+			if err != nil || parsed < 1 {
+				v.AddMessage(ChatMessage{
+					Role:      "assistant",
+					Content:   "Usage: /edit [n] (n counts back from your latest message)",
+					Timestamp: time.Now().Format("15:04:05"),
+				})
+				return nil
+			}
+			n = parsed
+		}
+		return v.startEdit(v.lastUserMessage(n))
 	case "/exit", "/quit":
 		// Exit the application
 		return tea.Quit
@@ -415,7 +875,7 @@ func (v *ChatView) handleCommand(input string) tea.Cmd {
 		// List all commands
 		responseMsg := ChatMessage{
 			Role:      "assistant",
-			Content:   "Available commands:\n• /mcp, /servers - Switch to MCP servers view\n• /tools - Switch to tools view\n• /help - Switch to help view\n• /history - Switch to history view\n• /chat - Stay in chat view\n• /commands - Show this list\n\nTip: You can also use number keys 1-5 to switch views!",
+			Content:   "Available commands:\n• /mcp, /servers - Switch to MCP servers view\n• /tools - Switch to tools view\n• /help - Switch to help view\n• /history - Switch to history view\n• /audit - Switch to the tool audit log\n• /stats - Show token use and latency per conversation\n• /usage - Show tokens and cost this session, per conversation and per tool\n• /export markdown|json|html [path] - Save the conversation to a file\n• /share - Save a sanitized transcript to share, uploading it if configured\n• /resume - Restore the most recent saved conversation\n• /search <text> [#tag] [is:pinned] - Search saved conversations\n• /tag, /untag <tag> - Tag the latest message\n• /pin, /unpin - Pin the latest message\n• /pinned - List pinned messages\n• /remember [topic:] <fact> - Remember a fact across conversations\n• /memories [topic] - List remembered facts\n• /forget <id> - Delete a remembered fact\n• /attach <path> - Send a file or image with your next message\n• /detach - Remove the files attached to your next message\n• /regenerate [temperature] - Ask again for the last response\n• /edit [n] - Edit one of your messages and branch from it\n• /undo - Remove your last message and reverse its tool calls where possible\n• /approve, /cancel - Run or discard the plan waiting for approval\n• /compact - Fold earlier messages into the conversation summary now\n• /persona [name|off] - List personas or switch to one\n• /template [name] - List saved prompt templates or fill one in and send it\n• /mode [chat|analysis|automation] - List session modes or switch to one\n• /dryrun on|off - Validate tool calls without running them\n• /chat - Stay in chat view\n• /commands - Show this list\n\nTip: You can also use number keys 1-5 to switch views!",
 			Timestamp: time.Now().Format("15:04:05"),
 		}
 		v.AddMessage(responseMsg)
@@ -437,19 +897,478 @@ func (v *ChatView) SetInput(value string) {
 	v.input.SetValue(value)
 }
 
-// renderMessages renders all chat messages
+// renderMessages renders all chat messages. Messages rendered before at the
+// same width are taken from the render cache.
 func (v *ChatView) renderMessages() string {
 	if len(v.messages) == 0 {
 		return v.styles.DimmedStyle.Render("No messages yet. Start a conversation!")
 	}
 
-	var lines []string
-	for _, msg := range v.messages {
-		lines = append(lines, v.renderMessage(msg))
-		lines = append(lines, "") // Add spacing between messages
+	var b strings.Builder
+	b.Grow(v.renders.size)
+	v.messageOffsets = make([]int, len(v.messages))
+	lineCount := 0
+	if v.hidden > 0 {
+		b.WriteString(v.renderHiddenMessages())
+		b.WriteString("\n\n")
+		lineCount = 2
+	}
+
+	v.renders.begin(v.width)
+	for i, msg := range v.messages {
+		block := v.renders.get(msg, v.renderMessage)
+		rendered, lines := block.text, block.lines
+		if i == v.selected {
+			rendered = v.renderSelected(rendered, msg, v.expanded[i])
+			lines = strings.Count(rendered, "\n")
+		}
+		v.messageOffsets[i] = lineCount
+		lineCount += lines + 2 // message lines plus spacing
+
+		b.WriteString(rendered)
+		b.WriteString("\n\n") // Add spacing between messages
+	}
+
+	if len(v.followUps) > 0 {
+		b.WriteString(v.renderFollowUps())
+	}
+	content := b.String()
+	if len(v.followUps) == 0 {
+		content = strings.TrimSuffix(content, "\n")
+	}
+	v.renders.end(len(content))
+	return content
+}
+
+// renderFollowUps renders the suggested follow-ups as numbered quick actions
+func (v *ChatView) renderFollowUps() string {
+	lines := []string{v.styles.DimmedStyle.Render("Suggested next steps:")}
+	for i, followUp := range v.followUps {
+		number := v.styles.HighlightStyle.Render(fmt.Sprintf("[%d]", i+1))
+		lines = append(lines, fmt.Sprintf("  %s %s", number, followUp.Label))
+	}
+	hint := fmt.Sprintf("alt+1-%d: use a suggestion", len(v.followUps))
+	if len(v.followUps) == 1 {
+		hint = "alt+1: use the suggestion"
+	}
+	return strings.Join(append(lines, v.styles.DimmedStyle.Render(hint)), "\n")
+}
+
+// useFollowUp fills the input with the prompt of the nth suggested follow-up,
+// ready to send with enter or to edit first
+func (v *ChatView) useFollowUp(n int) {
+	if n < 1 || n > len(v.followUps) {
+		return
+	}
+	v.input.SetValue(v.followUps[n-1].Prompt)
+	v.input.CursorEnd()
+}
+
+// maxFollowUps matches the number of alt+digit shortcuts
+const maxFollowUps = 9
+
+// uniqueFollowUps drops repeated suggestions from several tool results and
+// keeps at most maxFollowUps
+func uniqueFollowUps(followUps []model.FollowUp) []model.FollowUp {
+	seen := make(map[string]bool)
+	var unique []model.FollowUp
+	for _, followUp := range followUps {
+		if seen[followUp.Prompt] || len(unique) == maxFollowUps {
+			continue
+		}
+		seen[followUp.Prompt] = true
+		unique = append(unique, followUp)
+	}
+	return unique
+}
+
+// renderSelected marks a selected message with a gutter and, when expanded,
+// appends the full tool call details
+func (v *ChatView) renderSelected(rendered string, msg ChatMessage, expanded bool) string {
+	if expanded && msg.ToolCall != nil && len(msg.ToolCall.Args) > 0 {
+		names := make([]string, 0, len(msg.ToolCall.Args))
+		for name := range msg.ToolCall.Args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		details := []string{v.styles.DimmedStyle.Render("Arguments:")}
+		for _, name := range names {
+			details = append(details, v.styles.DimmedStyle.Render(fmt.Sprintf("  %s: ", name))+fmt.Sprintf("%v", msg.ToolCall.Args[name]))
+		}
+		rendered += "\n" + strings.Join(details, "\n")
+	}
+	if expanded && msg.FullOutput != "" {
+		rendered += "\n" + v.styles.DimmedStyle.Render("Full output:") + "\n" + msg.FullOutput
+	}
+
+	gutter := v.styles.HighlightStyle.Render(" ")
+	lines := strings.Split(rendered, "\n")
+	for i, line := range lines {
+		lines[i] = gutter + " " + line
+	}
+	hint := "ctrl+y: copy • click: expand • esc: deselect"
+	return strings.Join(lines, "\n") + "\n" + v.styles.DimmedStyle.Render(hint)
+}
+
+// handleMouse scrolls the conversation with the wheel and selects messages on click
+func (v *ChatView) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if msg.Action != tea.MouseActionPress {
+		return nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		v.viewport.ScrollUp(3)
+		return v.loadEarlier()
+	case tea.MouseButtonWheelDown:
+		v.viewport.ScrollDown(3)
+	case tea.MouseButtonLeft:
+		index := v.messageAt(msg.Y)
+		if index >= 0 && index == v.selected {
+			// Clicking the selected message toggles its details
+			v.expanded[index] = !v.expanded[index]
+			v.refreshMessages()
+		} else {
+			v.selectMessage(index)
+		}
+	}
+	return nil
+}
+
+// messageAt maps a screen row to the index of the message rendered there, or -1
+func (v *ChatView) messageAt(y int) int {
+	// The viewport starts directly below the one-line header
+	row := y - 1
+	if row < 0 || row >= v.viewport.Height {
+		return -1
+	}
+	line := row + v.viewport.YOffset
+
+	index := -1
+	for i, offset := range v.messageOffsets {
+		if offset > line {
+			break
+		}
+		index = i
+	}
+	return index
+}
+
+// selectMessage selects the message at index, or clears the selection for -1
+func (v *ChatView) selectMessage(index int) {
+	if index >= len(v.messages) {
+		index = -1
+	}
+	v.selected = index
+	v.refreshMessages()
+}
+
+// SelectedMessage returns the message selected with the mouse, if any
+func (v *ChatView) SelectedMessage() (ChatMessage, bool) {
+	if v.selected < 0 || v.selected >= len(v.messages) {
+		return ChatMessage{}, false
+	}
+	return v.messages[v.selected], true
+}
+
+// refreshMessages re-renders the conversation without moving the scroll position
+func (v *ChatView) refreshMessages() {
+	offset := v.viewport.YOffset
+	v.viewport.SetContent(v.renderMessages())
+	v.viewport.SetYOffset(offset)
+}
+
+// copySelectedMessage copies the selected message's text to the system clipboard
+func (v *ChatView) copySelectedMessage() tea.Cmd {
+	msg, ok := v.SelectedMessage()
+	if !ok {
+		return nil
+	}
+
+	text := msg.Content
+	if msg.ToolCall != nil && msg.ToolCall.Result != "" {
+		text += "\n" + msg.ToolCall.Result
+	}
+	if msg.FullOutput != "" {
+		text += "\n" + msg.FullOutput
+	}
+	if msg.Error != "" {
+		text += "\nError: " + msg.Error
+	}
+
+	return func() tea.Msg {
+		if err := clipboard.WriteAll(text); err != nil {
+			return ToastMsg{Text: "Clipboard unavailable: " + err.Error(), Level: ToastError}
+		}
+		return ToastMsg{Text: "Message copied to clipboard", Level: ToastSuccess}
+	}
+}
+
+// sendMessage adds a user message with attachments to the chat and requests
+// a response with options
+func (v *ChatView) sendMessage(text string, attachments []Attachment, options model.GenerateOptions) tea.Cmd {
+	// Suggestions only apply to the response they followed
+	v.followUps = nil
+	attachments = v.prepareAttachments(attachments)
+	v.checkpoint(len(v.messages))
+	v.AddMessage(ChatMessage{
+		Role:        "user",
+		Content:     text,
+		Timestamp:   time.Now().Format("15:04:05"),
+		Attachments: attachments,
+	})
+
+	v.turnText, v.turnAttachments = text, attachments
+	v.pendingPlan, v.pendingQuestion = nil, nil
+	if cmd, ok := v.answerFromCache(text, attachments); ok {
+		return cmd
+	}
+	return v.request(text, attachments, options)
+}
+
+// request starts a turn that asks the model to respond to text with
+// attachments, with options
+func (v *ChatView) request(text string, attachments []Attachment, options model.GenerateOptions) tea.Cmd {
+	// Generate ID for this request; responses to earlier requests are ignored
+	v.requestID = fmt.Sprintf("req_%d", time.Now().UnixNano())
+	v.iterations, v.turnOptions = 0, options
+	v.beginTurn()
+
+	// Send to model
+	if v.agent != nil {
+		// Use tool-aware response generation; the model first decides whether tools are needed
+		return tea.Batch(v.startWaiting(phaseClassifying), v.generateResponseWithTools(text, attachments, v.requestID, options))
+	}
+	// Fallback to regular model response
+	if messages := withAttachments(v.contextMessages(text), attachments); len(messages) > 1 || len(attachments) > 0 {
+		return tea.Batch(v.startWaiting(phaseGenerating), ChatResponseWithOptions(v.model, messages, v.requestID, options))
+	}
+	return tea.Batch(v.startWaiting(phaseGenerating), GenerateResponseWithOptions(v.model, text, v.requestID, options))
+}
+
+// startWaiting shows the progress indicator for phase, keeping the start time
+// of a wait that is already under way, and starts the spinner
+func (v *ChatView) startWaiting(phase string) tea.Cmd {
+	if !v.waitingForResponse {
+		v.waitStarted = time.Now()
+	}
+	v.waitingForResponse = true
+	v.phase = phase
+	return v.spinner.Tick
+}
+
+// progressText describes the current phase of a pending response, e.g. "Classifying intent"
+func (v *ChatView) progressText() string {
+	phase := v.phase
+	if phase == "" {
+		phase = "waiting for a response"
+	}
+	return strings.ToUpper(phase[:1]) + phase[1:]
+}
+
+// renderProgress renders the spinner with the current phase and elapsed seconds
+func (v *ChatView) renderProgress() string {
+	elapsed := int(time.Since(v.waitStarted).Seconds())
+	return fmt.Sprintf("%s%s... %ds", v.spinner.View(), v.progressText(), elapsed)
+}
+
+// lastUserMessage returns the index of the nth most recent message typed by
+// the user (commands excluded), or -1 if there is none
+func (v *ChatView) lastUserMessage(n int) int {
+	for i := len(v.messages) - 1; i >= 0; i-- {
+		msg := v.messages[i]
+		if msg.Role != "user" || strings.HasPrefix(msg.Content, "/") {
+			continue
+		}
+		n--
+		if n == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// regenerate discards everything after the latest user message and asks the
+// model for a new response to it
+func (v *ChatView) regenerate(options model.GenerateOptions) tea.Cmd {
+	if v.waitingForResponse {
+		return toastCmd("Wait for the current response before regenerating", ToastWarning)
+	}
+
+	index := v.lastUserMessage(1)
+	if index < 0 {
+		return toastCmd("Nothing to regenerate yet", ToastInfo)
+	}
+
+	text, attachments := v.messages[index].Content, v.messages[index].Attachments
+	v.truncateMessages(index)
+	v.refresh = true
+	return v.sendMessage(text, attachments, options)
+}
+
+// startEdit loads the user message at index into the input for editing
+func (v *ChatView) startEdit(index int) tea.Cmd {
+	if index < 0 || index >= len(v.messages) || v.messages[index].Role != "user" || strings.HasPrefix(v.messages[index].Content, "/") {
+		return toastCmd("Only your own messages can be edited", ToastInfo)
+	}
+	if v.waitingForResponse {
+		return toastCmd("Wait for the current response before editing", ToastWarning)
+	}
+
+	v.editing = index
+	v.selectMessage(-1)
+	v.input.SetValue(v.messages[index].Content)
+	v.input.CursorEnd()
+	return toastCmd("Editing message: enter resends it from here, esc cancels", ToastInfo)
+}
+
+// branchAt drops the message at index and everything after it, so the edited
+// message continues the conversation from that point
+func (v *ChatView) branchAt(index int) tea.Cmd {
+	v.editing = -1
+	if index >= len(v.messages) {
+		return nil
+	}
+
+	// The edited message itself is replaced, so only later messages are discarded
+	discarded := len(v.messages) - index - 1
+	v.truncateMessages(index)
+	if discarded == 0 {
+		return nil
+	}
+	return toastCmd(fmt.Sprintf("Branched conversation: %d later messages discarded", discarded), ToastInfo)
+}
+
+// truncateMessages keeps only the messages before index
+func (v *ChatView) truncateMessages(index int) {
+	if v.log != nil {
+		// Keep the saved original intact; the branch is saved as a new conversation
+		v.log.branch(v.messages, index)
+	}
+	v.messages = v.messages[:index]
+	v.dropCheckpoints(index)
+	v.followUps = nil
+	if v.selected >= index {
+		v.selected = -1
+	}
+	for i := range v.expanded {
+		if i >= index {
+			delete(v.expanded, i)
+		}
+	}
+	v.refreshMessages()
+	v.viewport.GotoBottom()
+}
+
+// toastCmd returns a command that shows a toast notification
+func toastCmd(text string, level ToastLevel) tea.Cmd {
+	return func() tea.Msg {
+		return ToastMsg{Text: text, Level: level}
+	}
+}
+
+// exportConversation writes the conversation so far to a file. args holds the
+// format and an optional path, which defaults to a timestamped file in the
+// working directory.
+func (v *ChatView) exportConversation(args []string) tea.Cmd {
+	if len(args) == 0 || len(args) > 2 {
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   "Usage: /export markdown|json|html [path]",
+			Timestamp: time.Now().Format("15:04:05"),
+		})
+		return nil
+	}
+
+	format, err := storage.ParseExportFormat(args[0])
+	if err != nil {
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   err.Error(),
+			Timestamp: time.Now().Format("15:04:05"),
+		})
+		return nil
+	}
+
+	now := time.Now()
+	path := storage.DefaultExportPath(".", format, now)
+	if len(args) == 2 {
+		path = expandExportPath(args[1], format, now)
+	}
+
+	// Messages dropped from the chat are exported from storage when saved
+	if v.canLoadEarlier() {
+		store, id := v.log.store, v.log.id
+		return func() tea.Msg {
+			thread, err := store.GetThread(id)
+			if err == nil {
+				err = storage.ExportToFile(path, format, nil, thread)
+			}
+			if err != nil {
+				return ToastMsg{Text: "Export failed: " + err.Error(), Level: ToastError}
+			}
+			return ToastMsg{Text: fmt.Sprintf("Exported %d messages to %s", len(thread), path), Level: ToastSuccess}
+		}
 	}
 
-	return strings.Join(lines, "\n")
+	// Leave out the /export command itself, which was just added to the chat
+	messages := toStorageMessages(v.messages[:len(v.messages)-1], now)
+
+	return func() tea.Msg {
+		if err := storage.ExportToFile(path, format, nil, messages); err != nil {
+			return ToastMsg{Text: "Export failed: " + err.Error(), Level: ToastError}
+		}
+		return ToastMsg{Text: fmt.Sprintf("Exported %d messages to %s", len(messages), path), Level: ToastSuccess}
+	}
+}
+
+// expandExportPath resolves ~ and, when path is an existing directory, picks a
+// timestamped file name inside it
+func expandExportPath(path string, format storage.ExportFormat, now time.Time) string {
+	if strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[2:])
+		}
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return storage.DefaultExportPath(path, format, now)
+	}
+	return path
+}
+
+// toStorageMessages converts chat messages, including tool calls and results,
+// to storage messages. Chat timestamps only carry the time of day, so they are
+// placed on the date of now.
+func toStorageMessages(messages []ChatMessage, now time.Time) []*storage.Message {
+	converted := make([]*storage.Message, 0, len(messages))
+	for _, msg := range messages {
+		stored := toStorageMessage(msg)
+		if t, err := time.ParseInLocation("15:04:05", msg.Timestamp, now.Location()); err == nil {
+			stored.Timestamp = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+		}
+		converted = append(converted, stored)
+	}
+	return converted
+}
+
+// toStorageMessage converts a chat message, including any tool call, result,
+// or error, to a storage message without a timestamp
+func toStorageMessage(msg ChatMessage) *storage.Message {
+	stored := &storage.Message{
+		Role:    msg.Role,
+		Content: msg.Content,
+	}
+	if msg.ToolCall != nil {
+		stored.ToolCall = &storage.ToolCall{Name: msg.ToolCall.Name, Arguments: msg.ToolCall.Args}
+		if msg.ToolCall.Result != "" {
+			stored.ToolResult = &storage.ToolResult{Content: msg.ToolCall.Result}
+		}
+	}
+	if msg.Error != "" {
+		stored.ToolResult = &storage.ToolResult{Content: msg.Error, IsError: true}
+	}
+	return stored
 }
 
 // renderMessage renders a single message
@@ -480,9 +1399,18 @@ func (v *ChatView) renderMessage(msg ChatMessage) string {
 		timeStr,
 		style.Render(prefix),
 	)
+	if msg.Pinned {
+		header += " " + v.styles.HighlightStyle.Render("📌")
+	}
+	if len(msg.Tags) > 0 {
+		header += " " + v.styles.DimmedStyle.Render(formatTags(msg.Tags))
+	}
+	if msg.Cached {
+		header += " " + v.styles.DimmedStyle.Render("⚡ cached")
+	}
 
 	// Content - wrap long lines
-	content := v.wrapText(msg.Content, v.width-4)
+	content := wrapText(msg.Content, v.width-4)
 	
 	// Add error if present
 	if msg.Error != "" {
@@ -501,6 +1429,14 @@ func (v *ChatView) renderMessage(msg ChatMessage) string {
 		content += toolInfo
 	}
 
+	if msg.FullOutput != "" {
+		content += "\n" + v.styles.DimmedStyle.Render(fmt.Sprintf("Summarized from %s of tool output • click to see it all", formatBytes(len(msg.FullOutput))))
+	}
+
+	for _, a := range msg.Attachments {
+		content += "\n" + v.styles.DimmedStyle.Render(describeAttachment(a.Name, a.Size))
+	}
+
 	return header + "\n" + content
 }
 
@@ -508,9 +1444,13 @@ func (v *ChatView) renderMessage(msg ChatMessage) string {
 func (v *ChatView) renderInput() string {
 	prompt := v.styles.InputPrompt.Render("❯ ")
 	
-	// Show different prompt when waiting for response
+	// Show different prompt when waiting for response or editing a message
 	if v.waitingForResponse {
-		prompt = v.styles.DimmedStyle.Render("⏳ ")
+		prompt = v.styles.DimmedStyle.Render(v.renderProgress() + " ")
+	} else if v.editing >= 0 {
+		prompt = v.styles.HighlightStyle.Render("✎ ")
+	} else if len(v.pending) > 0 {
+		prompt = v.styles.InputPrompt.Render(fmt.Sprintf("📎%d ❯ ", len(v.pending)))
 	}
 	
 	input := v.styles.InputBox.
@@ -524,48 +1464,33 @@ func (v *ChatView) renderInput() string {
 	)
 }
 
-// wrapText wraps text to fit within the specified width
-func (v *ChatView) wrapText(text string, width int) string {
-	if width <= 0 {
-		return text
-	}
-
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return text
-	}
-
-	var lines []string
-	var currentLine string
-
-	for _, word := range words {
-		testLine := currentLine
-		if testLine != "" {
-			testLine += " "
-		}
-		testLine += word
-
-		if len(testLine) <= width {
-			currentLine = testLine
-		} else {
-			if currentLine != "" {
-				lines = append(lines, currentLine)
-			}
-			currentLine = word
-		}
+// generateResponseWithTools generates a response using intelligent tool calling via Universal Integration
+func (v *ChatView) generateResponseWithTools(message string, attachments []Attachment, id string, options model.GenerateOptions) tea.Cmd {
+	// Build messages with the conversation summary and metadata context if available
+	messages := withAttachments(v.contextMessages(message), attachments)
+	memory, recallLimit := v.memory, v.recallLimit
+	project, retrieveLimit := v.project, v.retrieveLimit
+	var preferred []string
+	if v.persona != nil {
+		preferred = v.persona.Tools
 	}
-
-	if currentLine != "" {
-		lines = append(lines, currentLine)
+	moder, _ := v.agent.(SessionModer)
+	mode := v.Mode()
+	turnCtx := v.turnContext()
+	// Tool policies limit calls per request, and repeated calls are only
+	// answered from earlier results within one, so both start over
+	if v.conversationContext != nil {
+		v.conversationContext.TurnCalls = nil
+		v.conversationContext.TurnResults = nil
 	}
 
-	return strings.Join(lines, "\n")
-}
-
-// generateResponseWithTools generates a response using intelligent tool calling via Universal Integration
-func (v *ChatView) generateResponseWithTools(message, id string) tea.Cmd {
 	return func() tea.Msg {
-		ctx := context.Background()
+		ctx := turnCtx
+
+		// Remind the model of what it remembers about this message
+		messages := withSystemContext(messages, memoryContext(memory, message, recallLimit))
+		// and of the parts of their project it may be about
+		messages = withSystemContext(messages, projectContext(project, message, retrieveLimit))
 
 		// Try to use the Universal Integration for intelligent tool calling
 		// TODO: Enable when import cycle is resolved
@@ -575,10 +1500,7 @@ func (v *ChatView) generateResponseWithTools(message, id string) tea.Cmd {
 		tools, err := v.agent.GetMCPToolsAsDefinitions(ctx)
 		if err != nil {
 			// Final fallback to regular generation
-			response, err := v.model.Generate(ctx, message, model.GenerateOptions{
-				Temperature: 0.7,
-				MaxTokens:   2048,
-			})
+			response, err := v.model.Generate(ctx, attachmentPrompt(message, attachments), options)
 			return ModelResponseMsg{
 				Response: response,
 				Error:    err,
@@ -586,25 +1508,11 @@ func (v *ChatView) generateResponseWithTools(message, id string) tea.Cmd {
 			}
 		}
 
-		// Build messages with metadata context if available
-		messages := []model.Message{
-			{Role: "user", Content: message},
+		if moder != nil {
+			tools = moder.ModeTools(mode, tools)
 		}
-
-		if v.conversationContext != nil && len(v.conversationContext.ExtractedMetadata) > 0 {
-			metadataContext := v.buildMetadataContextForModel()
-			if metadataContext != "" {
-				messages = []model.Message{
-					{Role: "system", Content: metadataContext},
-					{Role: "user", Content: message},
-				}
-			}
-		}
-
-		response, err := v.model.ChatWithTools(ctx, messages, tools, model.GenerateOptions{
-			Temperature: 0.7,
-			MaxTokens:   2048,
-		})
+		tools = preferTools(tools, preferred)
+		response, err := v.model.ChatWithTools(ctx, messages, tools, options)
 
 		// If tools were called, execute them
 		if response != nil && len(response.ToolCalls) > 0 {
@@ -649,53 +1557,142 @@ func (v *ChatView) processToolResultWithAgent(toolName string, result *mcp.Execu
 	}
 }
 
-// executeToolCalls executes the detected tool calls
-// executeToolCallsUnified executes tool calls using the unified pathway
-func (v *ChatView) executeToolCallsUnified(toolCalls []model.ToolCall, requestID string, userMessage string) tea.Cmd {
+// runToolCalls announces and runs the tool calls the model made in msg
+func (v *ChatView) runToolCalls(msg ToolCallDetectedMsg) tea.Cmd {
+	// Add a more natural assistant message
+	var toolCallContent string
+	if len(msg.ToolCalls) == 1 {
+		toolCallContent = fmt.Sprintf("Let me help you with that using the %s tool...", msg.ToolCalls[0].Name)
+	} else {
+		toolNames := make([]string, len(msg.ToolCalls))
+		for i, tc := range msg.ToolCalls {
+			toolNames[i] = tc.Name
+		}
+		toolCallContent = fmt.Sprintf("I'll use several tools to help: %s", strings.Join(toolNames, ", "))
+	}
+
+	assistantMsg := ChatMessage{
+		Role:      "assistant",
+		Content:   toolCallContent,
+		Timestamp: time.Now().Format("15:04"),
+	}
+	v.AddMessage(assistantMsg)
+
+	// Execute the tools using unified pathway
+	phase := fmt.Sprintf("calling %d tools", len(msg.ToolCalls))
+	if len(msg.ToolCalls) == 1 {
+		phase = fmt.Sprintf("calling %s tool", msg.ToolCalls[0].Name)
+	}
+	var reply string
+	if msg.Response != nil {
+		reply = msg.Response.Content
+	}
+	return tea.Batch(v.startWaiting(phase), v.executeToolCallsUnified(msg.ToolCalls, msg.RequestID, msg.UserMessage, reply, msg.Approved))
+}
+
+// executeToolCallsUnified executes tool calls using the unified pathway. reply
+// is the model's response that made the calls; approved is whether the user
+// approved them in a plan.
+func (v *ChatView) executeToolCallsUnified(toolCalls []model.ToolCall, requestID string, userMessage string, reply string, approved bool) tea.Cmd {
+	turnCtx := v.turnContext()
 	return func() tea.Msg {
-		ctx := context.Background()
+		ctx := turnCtx
+		if approved {
+			ctx = model.WithApprovedCalls(ctx, toolCalls)
+		}
 
-		// For multiple tool calls, we'll collect all results and format them
-		var allResults []string
+		// For multiple tool calls, we'll collect all results and format them.
+		// Failures are shown with what the user can do about them.
+		var allResults, shownResults []string
+		var fullOutputs []string
+		var followUps []model.FollowUp
 
 		// Update persistent conversation context for this interaction
 		if v.conversationContext == nil {
 			v.conversationContext = &model.ConversationContext{
-				SessionType:       "chat",
-				ExtractedMetadata: make(map[string]interface{}),
+				SessionType: v.Mode(),
+				Entities:    model.NewEntityStore(),
 			}
 		}
 		v.conversationContext.History = v.conversationHistory
 		v.conversationContext.UserQuery = userMessage
 
-		for _, toolCall := range toolCalls {
-			if v.agent != nil {
-				// Use the persistent conversation context (metadata accumulates across tool calls)
-				result, err := v.agent.ExecuteToolUnifiedWithContext(ctx, toolCall.Name, toolCall.Arguments, v.conversationContext)
-				if err != nil {
-					allResults = append(allResults, fmt.Sprintf("❌ Tool %s failed: %v", toolCall.Name, err))
-				} else {
-					// The result is already processed natural language - use it directly
-					allResults = append(allResults, result)
-				}
-			} else {
+		if v.agent == nil {
+			for _, toolCall := range toolCalls {
 				allResults = append(allResults, fmt.Sprintf("❌ Tool %s failed: no agent available", toolCall.Name))
 			}
+			shownResults = allResults
+		} else {
+			// Use the persistent conversation context (metadata accumulates across tool calls)
+			for i, outcome := range v.executeToolCalls(ctx, toolCalls) {
+				toolCall := toolCalls[i]
+				if outcome.Err != nil {
+					failure := fmt.Sprintf("❌ Tool %s failed: %v", toolCall.Name, outcome.Err)
+					allResults = append(allResults, failure)
+					if suggestion := turnRecoverySuggestion(turnCtx, outcome.Err); suggestion != "" {
+						failure += "\n" + suggestion
+					}
+					shownResults = append(shownResults, failure)
+					continue
+				}
+				// The result is already processed natural language - use it directly
+				allResults = append(allResults, outcome.Output)
+				shownResults = append(shownResults, outcome.Output)
+				followUps = append(followUps, outcome.FollowUps...)
+				if outcome.FullResult != "" {
+					fullOutputs = append(fullOutputs, fmt.Sprintf("%s:\n%s", toolCall.Name, outcome.FullResult))
+				}
+			}
 		}
 
 		// Combine all results into a cohesive response
 		var finalResult string
-		if len(allResults) == 1 {
-			finalResult = allResults[0]
+		if len(shownResults) == 1 {
+			finalResult = shownResults[0]
 		} else {
-			finalResult = "I've executed several tools to help you:\n\n" + strings.Join(allResults, "\n\n")
+			finalResult = "I've executed several tools to help you:\n\n" + strings.Join(shownResults, "\n\n")
 		}
 
 		// Return the unified message type
 		return ToolExecutedUnifiedMsg{
-			ToolName: fmt.Sprintf("%d tools", len(toolCalls)),
-			Result:   finalResult,
-			Success:  true,
+			ToolName:  fmt.Sprintf("%d tools", len(toolCalls)),
+			Result:    finalResult,
+			Success:   true,
+			FollowUps: uniqueFollowUps(followUps),
+			RequestID: requestID,
+			Feedback:  model.ToolResultMessages(reply, toolCalls, allResults),
+			FullOutput: strings.Join(fullOutputs, "\n\n"),
+		}
+	}
+}
+
+// continueTurn sends messages to the model for the current request, so it
+// can call more tools or answer
+func (v *ChatView) continueTurn(messages []model.Message, requestID string) tea.Cmd {
+	tools, options, userMessage := v.availableTools, v.turnOptions, v.currentUserMessage
+	compact := v.compactTurn()
+	turnCtx := v.turnContext()
+	return func() tea.Msg {
+		ctx := turnCtx
+		messages := compact(ctx, messages)
+		response, err := v.model.ChatWithTools(ctx, messages, tools, options)
+		if response != nil && len(response.ToolCalls) > 0 {
+			return ToolCallDetectedMsg{
+				ToolCalls:           response.ToolCalls,
+				RequestID:           requestID,
+				Response:            response,
+				UserMessage:         userMessage,
+				ConversationHistory: messages,
+				Tools:               tools,
+			}
+		}
+		if response == nil && err == nil {
+			err = fmt.Errorf("the model returned no response")
+		}
+		return ModelResponseMsg{
+			Response: response,
+			Error:    err,
+			ID:       requestID,
 		}
 	}
 }
@@ -770,36 +1767,44 @@ func (v *ChatView) formatAnalysisResult(result interface{}) string {
 }
 
 // buildMetadataContextForModel creates a system message with extracted metadata
-// This allows the model to reference IDs and other metadata in follow-up requests
+// This allows the model to reference IDs and other metadata in follow-up requests.
+// Each value names the tool it came from, and earlier values of the same kind
+// are listed so the model can tell "the first memory" from "the last one".
 func (v *ChatView) buildMetadataContextForModel() string {
-	if v.conversationContext == nil || len(v.conversationContext.ExtractedMetadata) == 0 {
+	if v.conversationContext == nil || v.conversationContext.Entities.Len() == 0 {
 		return ""
 	}
+	entities := v.conversationContext.Entities
 
 	var contextParts []string
 	contextParts = append(contextParts, "IMPORTANT: Context from previous tool executions that you MUST use when calling tools:")
 
-	// Include ALL extracted metadata fields (universal extraction)
-	// Priority fields first (most commonly needed)
+	// Priority fields first (most commonly needed), then the rest, most recent first
 	priorityKeys := []string{"memory_id", "id", "first_memory_id", "first_id"}
+	var ordered []model.Entity
 	for _, key := range priorityKeys {
-		if value, exists := v.conversationContext.ExtractedMetadata[key]; exists {
-			contextParts = append(contextParts, fmt.Sprintf("- %s: %v (use this value when tools require '%s' parameter)", key, value, key))
+		if entity, exists := entities.Latest(key); exists {
+			ordered = append(ordered, entity)
+		}
+	}
+	for _, entity := range entities.Recent(0) {
+		if !slices.Contains(priorityKeys, entity.Key) {
+			ordered = append(ordered, entity)
 		}
 	}
 
-	// Then include all other extracted metadata
-	for key, value := range v.conversationContext.ExtractedMetadata {
-		// Skip if already included in priority keys
-		alreadyIncluded := false
-		for _, priorityKey := range priorityKeys {
-			if key == priorityKey {
-				alreadyIncluded = true
-				break
-			}
+	for _, entity := range ordered {
+		source := ""
+		if entity.Tool != "" {
+			source = "from " + entity.Tool + "; "
 		}
-		if !alreadyIncluded {
-			contextParts = append(contextParts, fmt.Sprintf("- %s: %v (use this value when tools require '%s' parameter)", key, value, key))
+		contextParts = append(contextParts, fmt.Sprintf("- %s: %v (%suse this value when tools require '%s' parameter)", entity.Key, entity.Value, source, entity.Key))
+		if history := entities.History(entity.Key); len(history) > 1 {
+			earlier := make([]string, 0, len(history)-1)
+			for _, previous := range history[1:] {
+				earlier = append(earlier, fmt.Sprint(previous.Value))
+			}
+			contextParts = append(contextParts, fmt.Sprintf("  earlier %s values, newest first: %s", entity.Key, strings.Join(earlier, ", ")))
 		}
 	}
 
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBranchTestView returns a chat view with one finished exchange whose model
+// records the prompt and options of every request
+func newBranchTestView(t *testing.T) (*ChatView, *[]string, *[]model.GenerateOptions) {
+	t.Helper()
+	var prompts []string
+	var options []model.GenerateOptions
+	m := &MockModel{generateFunc: func(ctx context.Context, prompt string, opts model.GenerateOptions) (*model.Response, error) {
+		prompts = append(prompts, prompt)
+		options = append(options, opts)
+		return &model.Response{Content: "reply to " + prompt}, nil
+	}}
+
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), m)
+	chatView.SetGenerateOptions(model.GenerateOptions{Temperature: 0.7, MaxTokens: 2048})
+	chatView.ClearMessages()
+	sendAndReply(t, chatView, "first question")
+	sendAndReply(t, chatView, "second question")
+	return chatView, &prompts, &options
+}
+
+// sendAndReply types text, presses enter, and delivers the model's reply
+func sendAndReply(t *testing.T, v *ChatView, text string) {
+	t.Helper()
+	v.SetInput(text)
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	deliver(t, v, cmd)
+}
+
+// deliver runs cmd and feeds the resulting messages back into the view
+func deliver(t *testing.T, v *ChatView, cmd tea.Cmd) {
+	t.Helper()
+	require.NotNil(t, cmd)
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if c != nil {
+				deliver(t, v, c)
+			}
+		}
+		return
+	}
+	v.Update(msg)
+}
+
+func contents(v *ChatView) []string {
+	var result []string
+	for _, msg := range v.messages {
+		result = append(result, msg.Content)
+	}
+	return result
+}
+
+func TestChatView_RegenerateReplacesLastResponse(t *testing.T) {
+	chatView, prompts, options := newBranchTestView(t)
+
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	deliver(t, chatView, cmd)
+
+	assert.Equal(t, []string{"first question", "reply to first question", "second question", "reply to second question"}, contents(chatView))
+	assert.Equal(t, "second question", (*prompts)[len(*prompts)-1])
+	assert.Equal(t, 0.7, (*options)[len(*options)-1].Temperature)
+}
+
+func TestChatView_RegenerateCommandWithTemperature(t *testing.T) {
+	chatView, _, options := newBranchTestView(t)
+
+	cmd := chatView.handleCommand("/regenerate 1.3")
+	deliver(t, chatView, cmd)
+
+	// The command itself is dropped along with the old response
+	assert.Len(t, chatView.messages, 4)
+	assert.Equal(t, 1.3, (*options)[len(*options)-1].Temperature)
+	assert.Equal(t, 2048, (*options)[len(*options)-1].MaxTokens)
+	// Later messages use the configured temperature again
+	assert.Equal(t, 0.7, chatView.options.Temperature)
+
+	assert.Nil(t, chatView.handleCommand("/regenerate hot"))
+	assert.Contains(t, chatView.messages[len(chatView.messages)-1].Content, "Usage: /regenerate")
+}
+
+func TestChatView_RegenerateWithoutMessages(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.ClearMessages()
+
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Nothing to regenerate yet", cmd().(ToastMsg).Text)
+}
+
+func TestChatView_EditBranchesConversation(t *testing.T) {
+	chatView, prompts, _ := newBranchTestView(t)
+
+	cmd := chatView.handleCommand("/edit 2")
+	require.NotNil(t, cmd)
+	assert.Equal(t, "first question", chatView.GetInput())
+	assert.Equal(t, 0, chatView.editing)
+
+	chatView.SetInput("first question, rephrased")
+	_, cmd = chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	deliver(t, chatView, cmd)
+
+	assert.Equal(t, []string{"first question, rephrased", "reply to first question, rephrased"}, contents(chatView))
+	assert.Equal(t, "first question, rephrased", (*prompts)[len(*prompts)-1])
+	assert.Equal(t, -1, chatView.editing)
+}
+
+func TestChatView_EditSelectedMessage(t *testing.T) {
+	chatView, _, _ := newBranchTestView(t)
+	chatView.selectMessage(2)
+
+	chatView.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	assert.Equal(t, "second question", chatView.GetInput())
+	assert.Equal(t, 2, chatView.editing)
+	assert.Equal(t, -1, chatView.selected)
+
+	// Esc cancels the edit and leaves the conversation alone
+	chatView.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, -1, chatView.editing)
+	assert.Empty(t, chatView.GetInput())
+	assert.Len(t, chatView.messages, 4)
+}
+
+func TestChatView_EditRejectsAssistantMessages(t *testing.T) {
+	chatView, _, _ := newBranchTestView(t)
+	chatView.selectMessage(1)
+
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Only your own messages can be edited", cmd().(ToastMsg).Text)
+	assert.Equal(t, -1, chatView.editing)
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatView_ExportCommand(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	chatView.ClearMessages()
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "search for postgres", Timestamp: "10:15:00"})
+	chatView.AddMessage(ChatMessage{
+		Role:      "tool",
+		Content:   "Executing tool: search...",
+		Timestamp: "10:15:01",
+		ToolCall:  &ToolCallInfo{Name: "search", Args: map[string]interface{}{"query": "postgres"}, Result: "no results"},
+	})
+
+	path := filepath.Join(t.TempDir(), "chat.md")
+	cmd := chatView.handleCommand("/export markdown " + path)
+	require.NotNil(t, cmd)
+
+	toast, ok := cmd().(ToastMsg)
+	require.True(t, ok)
+	assert.Equal(t, ToastSuccess, toast.Level)
+	assert.Contains(t, toast.Text, "Exported 2 messages")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	out := string(data)
+	assert.Contains(t, out, "search for postgres")
+	assert.Contains(t, out, "**Tool call:** `search`")
+	assert.Contains(t, out, "no results")
+	assert.NotContains(t, out, "/export")
+}
+
+func TestChatView_ExportCommandUsage(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+
+	assert.Nil(t, chatView.handleCommand("/export"))
+	assert.True(t, strings.HasPrefix(chatView.messages[len(chatView.messages)-1].Content, "Usage: /export"))
+
+	assert.Nil(t, chatView.handleCommand("/export pdf"))
+	assert.Contains(t, chatView.messages[len(chatView.messages)-1].Content, "unknown export format")
+}
+
+func TestExpandExportPath_Directory(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 3, 14, 9, 30, 5, 0, time.UTC)
+
+	path := expandExportPath(dir, "json", now)
+	assert.Equal(t, filepath.Join(dir, "othello-conversation-20250314-093005.json"), path)
+}
+
+func TestToStorageMessages_Timestamps(t *testing.T) {
+	now := time.Date(2025, 3, 14, 23, 0, 0, 0, time.UTC)
+	messages := toStorageMessages([]ChatMessage{
+		{Role: "user", Content: "hi", Timestamp: "22:59:58"},
+		{Role: "assistant", Content: "failed", Error: "boom"},
+	}, now)
+
+	require.Len(t, messages, 2)
+	assert.Equal(t, time.Date(2025, 3, 14, 22, 59, 58, 0, time.UTC), messages[0].Timestamp)
+	assert.True(t, messages[1].Timestamp.IsZero())
+	require.NotNil(t, messages[1].ToolResult)
+	assert.True(t, messages[1].ToolResult.IsError)
+}
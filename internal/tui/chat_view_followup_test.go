@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func altDigit(n rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{n}, Alt: true}
+}
+
+func TestChatView_FollowUpsAreNumberedQuickActions(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetSize(80, 30)
+	chatView.Update(ToolExecutedUnifiedMsg{
+		ToolName: "search",
+		Result:   "I found 3 memories",
+		Success:  true,
+		FollowUps: []model.FollowUp{
+			{Label: "Store new insights", Prompt: "Store the key insights"},
+			{Label: "Analyze patterns", Prompt: "Analyze the patterns across my memories"},
+		},
+	})
+
+	rendered := chatView.renderMessages()
+	assert.Contains(t, rendered, "[1] Store new insights")
+	assert.Contains(t, rendered, "[2] Analyze patterns")
+	assert.Contains(t, rendered, "alt+1-2")
+
+	// Choosing a suggestion pre-fills the input so it can be edited or sent
+	chatView.Update(altDigit('2'))
+	assert.Equal(t, "Analyze the patterns across my memories", chatView.GetInput())
+
+	// Numbers without a suggestion are ignored
+	chatView.SetInput("")
+	chatView.Update(altDigit('5'))
+	assert.Empty(t, chatView.GetInput())
+
+	// Sending a message retires the suggestions
+	chatView.SetInput("something else")
+	chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Empty(t, chatView.followUps)
+	assert.NotContains(t, chatView.renderMessages(), "Suggested next steps")
+}
+
+func TestChatView_FollowUpsClearedWithConversation(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.Update(ToolExecutedUnifiedMsg{
+		Result:    "Memory stored",
+		Success:   true,
+		FollowUps: []model.FollowUp{{Label: "Find related memories", Prompt: "Find related memories"}},
+	})
+	assert.Len(t, chatView.followUps, 1)
+
+	chatView.ClearMessages()
+	assert.Empty(t, chatView.followUps)
+}
+
+func TestUniqueFollowUps(t *testing.T) {
+	var followUps []model.FollowUp
+	for i := 0; i < 12; i++ {
+		followUps = append(followUps, model.FollowUp{Label: "same", Prompt: "same"})
+		followUps = append(followUps, model.FollowUp{Label: "n", Prompt: strings.Repeat("x", i+1)})
+	}
+
+	unique := uniqueFollowUps(followUps)
+	assert.Len(t, unique, maxFollowUps)
+	assert.Equal(t, "same", unique[0].Prompt)
+	assert.Equal(t, "x", unique[1].Prompt)
+}
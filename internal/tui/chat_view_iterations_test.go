@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolLoopModel answers ChatWithTools with its responses in turn, then with
+// the last one, recording the messages of every request
+type toolLoopModel struct {
+	MockModel
+	responses []*model.Response
+	received  [][]model.Message
+}
+
+func (m *toolLoopModel) ChatWithTools(ctx context.Context, messages []model.Message, tools []model.ToolDefinition, opts model.GenerateOptions) (*model.Response, error) {
+	m.received = append(m.received, messages)
+	response := m.responses[0]
+	if len(m.responses) > 1 {
+		m.responses = m.responses[1:]
+	}
+	return response, nil
+}
+
+// settle runs cmd and the commands its messages lead to until the view has
+// nothing left to do
+func settle(t *testing.T, v *ChatView, cmd tea.Cmd) {
+	t.Helper()
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			settle(t, v, c)
+		}
+	case spinner.TickMsg:
+		// The animation runs until the response arrives
+	default:
+		_, next := v.Update(msg)
+		settle(t, v, next)
+	}
+}
+
+func newToolLoopView(m *toolLoopModel, maxIterations int) *ChatView {
+	agent := &MockAgentForChat{tools: []Tool{{Name: "search"}, {Name: "stats"}}}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), m, agent)
+	chatView.SetMaxIterations(maxIterations)
+	chatView.ClearMessages()
+	return chatView
+}
+
+func TestChatView_FeedsToolResultsBackUntilAnswered(t *testing.T) {
+	m := &toolLoopModel{responses: []*model.Response{
+		{ToolCalls: []model.ToolCall{{Name: "search", Arguments: map[string]interface{}{"query": "deploys"}}}},
+		{Content: "TOOL_CALL: stats", ToolCalls: []model.ToolCall{{Name: "stats"}}},
+		{Content: "Deploys happen on Tuesdays."},
+	}}
+	chatView := newToolLoopView(m, 3)
+
+	chatView.SetInput("when do deploys happen?")
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	settle(t, chatView, cmd)
+
+	require.Len(t, m.received, 3)
+	review := m.received[1]
+	assert.Equal(t, "Result of search:\nMock unified tool execution result with context", review[len(review)-2].Content)
+	assert.Equal(t, model.ToolReflectionPrompt, review[len(review)-1].Content)
+	assert.Equal(t, review, m.received[2][:len(review)], "Each round builds on the last")
+	assert.Equal(t, "TOOL_CALL: stats", m.received[2][len(review)].Content)
+
+	assert.False(t, chatView.waitingForResponse)
+	assert.Equal(t, "Deploys happen on Tuesdays.", chatView.messages[len(chatView.messages)-1].Content)
+	assert.Equal(t, 2, chatView.iterations)
+}
+
+func TestChatView_StopsFeedingToolResultsAtMaxIterations(t *testing.T) {
+	m := &toolLoopModel{responses: []*model.Response{{ToolCalls: []model.ToolCall{{Name: "search"}}}}}
+	chatView := newToolLoopView(m, 1)
+
+	chatView.SetInput("search forever")
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	settle(t, chatView, cmd)
+	assert.Len(t, m.received, 2)
+	assert.False(t, chatView.waitingForResponse)
+	assert.Equal(t, "Mock unified tool execution result with context", chatView.messages[len(chatView.messages)-1].Content)
+
+	// The count starts again with the next message
+	m.received = nil
+	chatView.SetInput("search again")
+	_, cmd = chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	settle(t, chatView, cmd)
+	assert.Len(t, m.received, 2)
+}
+
+func TestChatView_ShowsToolResultsWithoutIterations(t *testing.T) {
+	m := &toolLoopModel{responses: []*model.Response{{ToolCalls: []model.ToolCall{{Name: "search"}}}}}
+	chatView := newToolLoopView(m, 0)
+
+	chatView.SetInput("find deploy notes")
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	settle(t, chatView, cmd)
+	assert.Len(t, m.received, 1)
+	assert.False(t, chatView.waitingForResponse)
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
 	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
 	"github.com/danieleugenewilliams/othello-agent/internal/model"
 	"github.com/stretchr/testify/assert"
@@ -280,9 +281,12 @@ func (m *MockAgentForChat) GetMCPToolsAsDefinitions(ctx context.Context) ([]mode
 	return defs, nil
 }
 
-func (m *MockAgentForChat) SubscribeToUpdates() <-chan interface{} {
-	ch := make(chan interface{})
-	return ch
+func (m *MockAgentForChat) GetUniversalIntegration() interface{} {
+	return nil
+}
+
+func (m *MockAgentForChat) Subscribe() *events.Subscription {
+	return events.NewBus(0).Subscribe()
 }
 
 func (m *MockAgentForChat) ExecuteTool(ctx context.Context, toolName string, params map[string]interface{}) (*ToolExecutionResult, error) {
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMouseTestChatView(t *testing.T, count int) *ChatView {
+	t.Helper()
+	view := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	view.SetSize(80, 12)
+	view.ClearMessages()
+	for i := 0; i < count; i++ {
+		view.AddMessage(ChatMessage{Role: "user", Content: "message " + string(rune('a'+i)), Timestamp: "12:00"})
+	}
+	view.View()
+	return view
+}
+
+func click(y int) tea.MouseMsg {
+	return tea.MouseMsg{X: 5, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}
+}
+
+func TestChatView_ClickSelectsMessage(t *testing.T) {
+	view := newMouseTestChatView(t, 2)
+	view.viewport.GotoTop()
+
+	// Row 0 is the header; the first message occupies rows 1-2
+	view.Update(click(1))
+	selected, ok := view.SelectedMessage()
+	require.True(t, ok)
+	assert.Equal(t, "message a", selected.Content)
+
+	// Selection adds a hint line, so the second message now starts at row 5
+	view.Update(click(5))
+	selected, ok = view.SelectedMessage()
+	require.True(t, ok)
+	assert.Equal(t, "message b", selected.Content)
+
+	// Clicking the header selects nothing
+	view.Update(click(0))
+	_, ok = view.SelectedMessage()
+	assert.False(t, ok)
+}
+
+func TestChatView_ClickSelectedMessageExpandsToolArgs(t *testing.T) {
+	view := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	view.SetSize(80, 12)
+	view.ClearMessages()
+	view.AddMessage(ChatMessage{
+		Role:      "tool",
+		Content:   "done",
+		Timestamp: "12:00",
+		ToolCall:  &ToolCallInfo{Name: "search", Args: map[string]interface{}{"query": "othello", "limit": 5}},
+	})
+	view.View()
+	view.viewport.GotoTop()
+
+	view.Update(click(1))
+	assert.NotContains(t, view.renderMessages(), "query")
+
+	view.Update(click(1))
+	rendered := view.renderMessages()
+	assert.Contains(t, rendered, "query: othello")
+	assert.Less(t, strings.Index(rendered, "limit"), strings.Index(rendered, "query"), "Arguments are sorted")
+}
+
+func TestChatView_EscClearsSelection(t *testing.T) {
+	view := newMouseTestChatView(t, 1)
+	view.viewport.GotoTop()
+
+	view.Update(click(1))
+	_, ok := view.SelectedMessage()
+	require.True(t, ok)
+
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	_, ok = view.SelectedMessage()
+	assert.False(t, ok)
+}
+
+func TestChatView_MouseWheelScrolls(t *testing.T) {
+	view := newMouseTestChatView(t, 10)
+	view.viewport.GotoBottom()
+	bottom := view.viewport.YOffset
+	require.Greater(t, bottom, 0)
+
+	view.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp})
+	assert.Less(t, view.viewport.YOffset, bottom)
+
+	view.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	assert.Equal(t, bottom, view.viewport.YOffset)
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatView_ProgressShowsPhaseAndElapsedTime(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetSize(80, 30)
+
+	chatView.SetInput("hello")
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	assert.True(t, chatView.waitingForResponse)
+	assert.Equal(t, phaseGenerating, chatView.phase)
+	assert.Contains(t, chatView.renderInput(), "Generating response... 0s")
+
+	chatView.waitStarted = time.Now().Add(-5 * time.Second)
+	assert.Contains(t, chatView.renderInput(), "Generating response... 5s")
+	assert.NotContains(t, chatView.renderInput(), "⏳")
+}
+
+func TestChatView_ProgressFollowsToolCalls(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	started := time.Now().Add(-3 * time.Second)
+	chatView.requestID = "req_1"
+	chatView.waitingForResponse = true
+	chatView.phase = phaseClassifying
+	chatView.waitStarted = started
+
+	chatView.Update(ToolCallDetectedMsg{
+		RequestID: "req_1",
+		ToolCalls: []model.ToolCall{{Name: "search"}},
+	})
+	assert.True(t, chatView.waitingForResponse, "Still waiting while the tool runs")
+	assert.Equal(t, "calling search tool", chatView.phase)
+	assert.Equal(t, started, chatView.waitStarted, "Elapsed time covers the whole request")
+
+	chatView.Update(ToolExecutedUnifiedMsg{Result: "done", Success: true})
+	assert.False(t, chatView.waitingForResponse)
+}
+
+func TestChatView_SpinnerStopsWhenIdle(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+
+	_, cmd := chatView.Update(chatView.spinner.Tick())
+	assert.Nil(t, cmd)
+
+	chatView.waitingForResponse = true
+	_, cmd = chatView.Update(chatView.spinner.Tick())
+	assert.NotNil(t, cmd)
+}
+
+func TestToASCII_SpinnerFrames(t *testing.T) {
+	for _, frame := range spinner.Dot.Frames {
+		assert.False(t, strings.Contains(toASCII(frame), "?"), "frame %q", frame)
+	}
+}
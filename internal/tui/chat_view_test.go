@@ -92,8 +92,12 @@ func TestChatView_BuildMetadataContext(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Set up conversation context with metadata
 			if len(tt.metadata) > 0 {
+				entities := model.NewEntityStore()
+				for key, value := range tt.metadata {
+					entities.Record(key, value, "")
+				}
 				chatView.conversationContext = &model.ConversationContext{
-					ExtractedMetadata: tt.metadata,
+					Entities: entities,
 				}
 			} else {
 				chatView.conversationContext = nil
@@ -118,6 +122,22 @@ func TestChatView_BuildMetadataContext(t *testing.T) {
 	}
 }
 
+func TestChatView_BuildMetadataContextListsEarlierValues(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	entities := model.NewEntityStore()
+	entities.Record("memory_id", "uuid-1", "store_memory")
+	entities.Record("memory_id", "uuid-2", "store_memory")
+	chatView.conversationContext = &model.ConversationContext{Entities: entities}
+
+	result := chatView.buildMetadataContextForModel()
+	if !contains(result, "- memory_id: uuid-2 (from store_memory; use this value") {
+		t.Errorf("Expected the newest memory_id with its tool, got: %s", result)
+	}
+	if !contains(result, "earlier memory_id values, newest first: uuid-1") {
+		t.Errorf("Expected the earlier memory_id, got: %s", result)
+	}
+}
+
 // Helper function to check if string contains substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && containsHelper(s, substr))
@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// MissingParameter is a required tool parameter the model left out
+type MissingParameter struct {
+	Name        string
+	Type        string   // JSON schema type, such as string or integer; "" when unknown
+	Description string   // What the parameter is, from the tool's schema
+	Choices     []string // Allowed values; empty allows any
+}
+
+// ParameterChecker is implemented by agents that can tell which required
+// parameters of a tool call are missing, so the chat asks the user for them
+// instead of running a call that fails
+type ParameterChecker interface {
+	MissingParameters(call model.ToolCall) []MissingParameter
+}
+
+// clarification holds tool calls waiting for the user to supply the values
+// the model left out, asked for one at a time
+type clarification struct {
+	msg     ToolCallDetectedMsg
+	call    int                // Index of the call being completed
+	missing []MissingParameter // Parameters of that call still to ask for
+}
+
+// clarify asks the user for the first parameter the calls in msg lack and
+// holds them until every one is answered. It returns false when nothing is
+// missing.
+func (v *ChatView) clarify(msg ToolCallDetectedMsg) bool {
+	checker, ok := v.agent.(ParameterChecker)
+	if !ok {
+		return false
+	}
+	for i, call := range msg.ToolCalls {
+		if missing := checker.MissingParameters(call); len(missing) > 0 {
+			v.pendingQuestion = &clarification{msg: msg, call: i, missing: missing}
+			v.waitingForResponse = false
+			v.askForParameter("")
+			return true
+		}
+	}
+	return false
+}
+
+// askForParameter asks for the next missing parameter, after problem with
+// the previous answer if there was one
+func (v *ChatView) askForParameter(problem string) {
+	q := v.pendingQuestion
+	parameter := q.missing[0]
+	var b strings.Builder
+	if problem != "" {
+		fmt.Fprintf(&b, "Sorry, %s. ", problem)
+	}
+	fmt.Fprintf(&b, "To use %s I need the %s", q.msg.ToolCalls[q.call].Name, parameter.Name)
+	if parameter.Description != "" {
+		fmt.Fprintf(&b, " (%s)", strings.TrimSuffix(parameter.Description, "."))
+	}
+	b.WriteString(". What should it be?")
+	if len(parameter.Choices) > 0 {
+		fmt.Fprintf(&b, " Choose one of: %s.", strings.Join(parameter.Choices, ", "))
+	}
+	b.WriteString(" (/cancel to skip it)")
+	v.AddMessage(ChatMessage{
+		Role:      "assistant",
+		Content:   b.String(),
+		Timestamp: time.Now().Format("15:04:05"),
+	})
+}
+
+// answerQuestion fills in the parameter asked for with text, then asks for
+// the next one or goes on with the tool calls once none is missing
+func (v *ChatView) answerQuestion(text string) tea.Cmd {
+	v.AddMessage(ChatMessage{
+		Role:      "user",
+		Content:   text,
+		Timestamp: time.Now().Format("15:04:05"),
+	})
+	q := v.pendingQuestion
+	parameter := q.missing[0]
+	value, err := parameterValue(parameter, text)
+	if err != nil {
+		v.askForParameter(err.Error())
+		return nil
+	}
+
+	call := &q.msg.ToolCalls[q.call]
+	arguments := make(map[string]interface{}, len(call.Arguments)+1)
+	for name, existing := range call.Arguments {
+		arguments[name] = existing
+	}
+	arguments[parameter.Name] = value
+	call.Arguments = arguments
+
+	q.missing = q.missing[1:]
+	if len(q.missing) > 0 {
+		v.askForParameter("")
+		return nil
+	}
+	v.pendingQuestion = nil
+	v.beginTurn()
+	if v.clarify(q.msg) {
+		return nil
+	}
+	return v.toolCallsReady(q.msg)
+}
+
+// cancelQuestion discards the tool calls waiting for missing values
+func (v *ChatView) cancelQuestion() tea.Cmd {
+	v.pendingQuestion = nil
+	v.AddMessage(ChatMessage{
+		Role:      "assistant",
+		Content:   "Okay, I won't run that.",
+		Timestamp: time.Now().Format("15:04:05"),
+	})
+	return v.summarize()
+}
+
+// parameterValue converts the user's answer to the parameter's type
+func parameterValue(parameter MissingParameter, text string) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	if len(parameter.Choices) > 0 {
+		for _, choice := range parameter.Choices {
+			if strings.EqualFold(choice, text) {
+				return choice, nil
+			}
+		}
+		return nil, fmt.Errorf("%q isn't one of the choices", text)
+	}
+	switch parameter.Type {
+	case "integer":
+		n, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s needs a whole number", parameter.Name)
+		}
+		return n, nil
+	case "number":
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s needs a number", parameter.Name)
+		}
+		return n, nil
+	case "boolean":
+		switch strings.ToLower(text) {
+		case "y", "yes", "true":
+			return true, nil
+		case "n", "no", "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%s needs yes or no", parameter.Name)
+	case "array":
+		var items []interface{}
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+	return text, nil
+}
+
+// toolCallsReady runs the tool calls in msg, or proposes them as a plan when
+// the agent wants them approved first
+func (v *ChatView) toolCallsReady(msg ToolCallDetectedMsg) tea.Cmd {
+	if plan := v.planFor(msg.ToolCalls); plan != nil {
+		return v.proposePlan(plan, msg)
+	}
+	return v.runToolCalls(msg)
+}
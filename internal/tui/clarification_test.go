@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// askingAgent needs a content and an importance for remember, and records
+// the tools it runs
+type askingAgent struct {
+	MockAgentForChat
+	ran []model.ToolCall
+}
+
+func (a *askingAgent) MissingParameters(call model.ToolCall) []MissingParameter {
+	var missing []MissingParameter
+	if _, ok := call.Arguments["content"]; !ok {
+		missing = append(missing, MissingParameter{Name: "content", Type: "string", Description: "The fact to remember."})
+	}
+	if _, ok := call.Arguments["importance"]; !ok {
+		missing = append(missing, MissingParameter{Name: "importance", Type: "integer"})
+	}
+	return missing
+}
+
+func (a *askingAgent) ExecuteToolUnifiedWithContext(ctx context.Context, toolName string, params map[string]interface{}, convContext *model.ConversationContext) (string, error) {
+	a.ran = append(a.ran, model.ToolCall{Name: toolName, Arguments: params})
+	return "Remembered it", nil
+}
+
+func newAskingTestView() (*ChatView, *askingAgent) {
+	m := &toolLoopModel{responses: []*model.Response{{ToolCalls: []model.ToolCall{{Name: "remember"}}}}}
+	agent := &askingAgent{MockAgentForChat: MockAgentForChat{tools: []Tool{{Name: "remember"}}}}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), m, agent)
+	chatView.ClearMessages()
+	return chatView, agent
+}
+
+func TestChatView_AsksForMissingParameters(t *testing.T) {
+	chatView, agent := newAskingTestView()
+
+	typeAndSettle(t, chatView, "remember something for me")
+	require.NotNil(t, chatView.pendingQuestion)
+	assert.False(t, chatView.waitingForResponse)
+	assert.Equal(t, "To use remember I need the content (The fact to remember). What should it be? (/cancel to skip it)", lastContent(chatView))
+
+	typeAndSettle(t, chatView, "Deploys are on Tuesdays")
+	assert.Equal(t, "To use remember I need the importance. What should it be? (/cancel to skip it)", lastContent(chatView))
+	typeAndSettle(t, chatView, "very")
+	assert.Equal(t, "Sorry, importance needs a whole number. To use remember I need the importance. What should it be? (/cancel to skip it)", lastContent(chatView))
+	assert.Empty(t, agent.ran)
+
+	typeAndSettle(t, chatView, " 8 ")
+	assert.Nil(t, chatView.pendingQuestion)
+	assert.Equal(t, []model.ToolCall{{Name: "remember", Arguments: map[string]interface{}{"content": "Deploys are on Tuesdays", "importance": 8}}}, agent.ran)
+	assert.Equal(t, "Remembered it", lastContent(chatView))
+}
+
+func TestChatView_MissingParameterQuestionCanBeCancelled(t *testing.T) {
+	chatView, agent := newAskingTestView()
+
+	typeAndSettle(t, chatView, "remember something for me")
+	typeAndSettle(t, chatView, "/cancel")
+	assert.Nil(t, chatView.pendingQuestion)
+	assert.Equal(t, "Okay, I won't run that.", lastContent(chatView))
+	assert.Empty(t, agent.ran)
+}
+
+func TestParameterValue(t *testing.T) {
+	tests := []struct {
+		parameter MissingParameter
+		text      string
+		want      interface{}
+		wantErr   string
+	}{
+		{MissingParameter{Name: "query"}, " deploys ", "deploys", ""},
+		{MissingParameter{Name: "limit", Type: "number"}, "2.5", 2.5, ""},
+		{MissingParameter{Name: "append", Type: "boolean"}, "Yes", true, ""},
+		{MissingParameter{Name: "append", Type: "boolean"}, "maybe", nil, "append needs yes or no"},
+		{MissingParameter{Name: "tags", Type: "array"}, "work, ops,", []interface{}{"work", "ops"}, ""},
+		{MissingParameter{Name: "mode", Choices: []string{"append", "overwrite"}}, "APPEND", "append", ""},
+		{MissingParameter{Name: "mode", Choices: []string{"append", "overwrite"}}, "merge", nil, `"merge" isn't one of the choices`},
+	}
+	for _, tt := range tests {
+		got, err := parameterValue(tt.parameter, tt.text)
+		if tt.wantErr != "" {
+			assert.EqualError(t, err, tt.wantErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
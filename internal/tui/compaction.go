@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// SetContextCompaction sets when the messages of a turn are compacted: once
+// they fill threshold of the context window, all but the latest keepRecent
+// are replaced with a summary by the model. A threshold of 0 never compacts.
+func (v *ChatView) SetContextCompaction(threshold float64, keepRecent int) {
+	v.compactThreshold = threshold
+	v.compactKeep = keepRecent
+}
+
+// contextCompactor returns the compactor for the messages of a turn
+func (v *ChatView) contextCompactor() *model.ContextCompactor {
+	return &model.ContextCompactor{
+		Model:      v.model,
+		Limit:      v.turnOptions.ContextLength,
+		Threshold:  v.compactThreshold,
+		KeepRecent: v.compactKeep,
+	}
+}
+
+// compactTurn returns a function that compacts messages when they come close
+// to the context window. Messages that can't be compacted are sent as they are.
+func (v *ChatView) compactTurn() func(ctx context.Context, messages []model.Message) []model.Message {
+	compactor := v.contextCompactor()
+	var entities *model.EntityStore
+	if v.conversationContext != nil {
+		entities = v.conversationContext.Entities
+	}
+	return func(ctx context.Context, messages []model.Message) []model.Message {
+		compacted, err := compactor.CompactIfNeeded(ctx, messages, entities.Values())
+		if err != nil {
+			return messages
+		}
+		return compacted
+	}
+}
+
+// compact folds the saved messages not yet in the conversation summary into
+// it now, rather than waiting for the next summary, so they reach the model
+// only as the summary
+func (v *ChatView) compact() tea.Cmd {
+	l := v.log
+	switch {
+	case l == nil || l.id == "" || v.model == nil:
+		return toastCmd("Nothing to compact: this conversation isn't saved", ToastInfo)
+	case v.waitingForResponse:
+		return toastCmd("Wait for the response before compacting", ToastInfo)
+	case l.summarizing:
+		return toastCmd("The conversation is already being summarized", ToastInfo)
+	case l.unsummarized == 0:
+		return toastCmd("The conversation is already compact", ToastInfo)
+	}
+	return v.writeSummary(l.unsummarized)
+}
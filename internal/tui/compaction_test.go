@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatView_CompactsLongTurns(t *testing.T) {
+	m := &toolLoopModel{responses: []*model.Response{
+		{ToolCalls: []model.ToolCall{{Name: "search"}}},
+		{Content: "Deploys happen on Tuesdays."},
+	}}
+	chatView := newToolLoopView(m, 3)
+	chatView.SetGenerateOptions(model.GenerateOptions{ContextLength: 40})
+	chatView.SetContextCompaction(0.5, 2)
+
+	chatView.SetInput("when do deploys happen?")
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	settle(t, chatView, cmd)
+
+	require.Len(t, m.received, 2)
+	review := m.received[1]
+	require.Len(t, review, 4)
+	assert.Equal(t, model.Message{Role: "system", Content: "Summary of earlier messages:\nMock response"}, review[0])
+	assert.Equal(t, "TOOL_CALL: search\nARGUMENTS: {}", review[1].Content, "The tool call stays with its result")
+	assert.Equal(t, "Deploys happen on Tuesdays.", lastContent(chatView))
+}
+
+func TestChatView_CompactCommand(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{
+		generateFunc: func(ctx context.Context, p string, opts model.GenerateOptions) (*model.Response, error) {
+			return &model.Response{Content: "TITLE: Deploy schedule\nSUMMARY: Deploys happen on Tuesdays."}, nil
+		},
+	})
+	assert.Equal(t, "Nothing to compact: this conversation isn't saved", chatView.compact()().(ToastMsg).Text)
+
+	chatView.SetConversationStore(store)
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "when do deploys happen?"})
+	chatView.AddMessage(ChatMessage{Role: "assistant", Content: "On Tuesdays."})
+
+	_, cmd := chatView.Update(chatView.compact()())
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Compacted 2 messages into the conversation summary", cmd().(ToastMsg).Text)
+	assert.Equal(t, "Deploys happen on Tuesdays.", chatView.conversationSummary())
+	assert.Equal(t, "The conversation is already compact", chatView.compact()().(ToastMsg).Text)
+}
@@ -0,0 +1,289 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// maxTitleLength limits the title taken from a conversation's first prompt
+const maxTitleLength = 60
+
+// ConversationStore persists chat messages so conversations survive restarts
+type ConversationStore interface {
+	CreateConversation(id, title string) (*storage.Conversation, error)
+	CreateBranch(id, title string, parentMessageID int64) (*storage.Conversation, error)
+	AddMessage(msg *storage.Message) error
+	ListConversations(limit, offset int) ([]*storage.Conversation, error)
+	GetThread(conversationID string) ([]*storage.Message, error)
+	UpdateConversationSummary(id, title, summary string) error
+	TagMessage(messageID int64, tag string) ([]string, error)
+	UntagMessage(messageID int64, tag string) ([]string, error)
+	SetMessagePinned(messageID int64, pinned bool) error
+	PinnedMessages(limit int) ([]*storage.Message, error)
+	AddAttachment(messageID int64, name, mediaType string, data []byte) (*storage.Attachment, error)
+	ListAttachments(filter storage.AttachmentFilter) ([]*storage.Attachment, error)
+	ReadAttachment(id int64) ([]byte, error)
+	RecordModelCall(conversationID string, tokensIn, tokensOut int, latency time.Duration) error
+	RecordToolCalls(conversationID string, count int) error
+	RecordToolUsage(conversationID string, tools []string, tokensIn, tokensOut int) error
+	SetConversationMode(id, mode string) error
+}
+
+// conversationRestoredMsg carries the conversation loaded by /resume
+type conversationRestoredMsg struct {
+	conversation *storage.Conversation
+	messages     []*storage.Message
+	attachments  []*storage.Attachment
+	err          error
+}
+
+// conversationLog writes chat messages to a ConversationStore as they are
+// added. The conversation record is created with the first message, and a new
+// one is started whenever the chat is cleared or branched. A branch records
+// the message it continues from rather than copying the earlier messages.
+type conversationLog struct {
+	store  ConversationStore
+	id     string // current conversation; empty until the next message starts one
+	skip   int    // leading messages, such as the welcome or a branch's history, that are not saved again
+	parent int64  // saved message the next conversation branches from; zero for a fresh start
+	mode   string // session mode saved with the conversation; "" saves none
+	err    error  // first write failure not yet reported
+
+	summary      string // rolling summary of the conversation, sent in place of earlier turns
+	unsummarized int    // messages saved since the summary was last updated
+	summarizing  bool   // a summary request is in flight
+}
+
+// record saves the newest of messages, first writing the earlier ones when a
+// new conversation has to be started
+func (l *conversationLog) record(messages []ChatMessage) {
+	if l.err != nil || len(messages) <= l.skip {
+		return
+	}
+
+	pending := messages[len(messages)-1:]
+	if l.id == "" {
+		pending = messages[l.skip:]
+		if !hasSavedMessage(pending) {
+			return
+		}
+		id := fmt.Sprintf("conv_%d", time.Now().UnixNano())
+		var err error
+		if l.parent != 0 {
+			_, err = l.store.CreateBranch(id, conversationTitle(pending), l.parent)
+		} else {
+			_, err = l.store.CreateConversation(id, conversationTitle(pending))
+		}
+		if err != nil {
+			l.err = err
+			return
+		}
+		l.id = id
+		if l.mode != "" {
+			if err := l.store.SetConversationMode(id, l.mode); err != nil {
+				l.err = err
+				return
+			}
+		}
+	}
+
+	// pending shares messages' backing array, so the saved IDs reach the chat
+	for i := range pending {
+		if !savedMessage(pending[i]) {
+			continue
+		}
+		spillToolOutput(&pending[i])
+		attachFullOutput(&pending[i])
+		stored := toStorageMessage(pending[i])
+		stored.ConversationID = l.id
+		stored.Timestamp = time.Now()
+		if err := l.store.AddMessage(stored); err != nil {
+			l.err = err
+			return
+		}
+		pending[i].storedID = stored.ID
+		l.unsummarized++
+		if err := l.saveAttachments(stored.ID, pending[i].Attachments); err != nil {
+			l.err = err
+			return
+		}
+	}
+}
+
+// saveAttachments saves the attachments of the message with messageID
+func (l *conversationLog) saveAttachments(messageID int64, attachments []Attachment) error {
+	for i := range attachments {
+		a := &attachments[i]
+		if a.storedID != 0 || a.data == nil {
+			continue
+		}
+		saved, err := l.store.AddAttachment(messageID, a.Name, a.MediaType, a.data)
+		if err != nil {
+			return err
+		}
+		a.storedID = saved.ID
+	}
+	return nil
+}
+
+// setMode records the session mode of the conversation, saving it now if the
+// conversation has been started
+func (l *conversationLog) setMode(mode string) {
+	l.mode = mode
+	if l.err != nil || l.id == "" || mode == "" {
+		return
+	}
+	if err := l.store.SetConversationMode(l.id, mode); err != nil {
+		l.err = err
+	}
+}
+
+// recordUsage adds a model response, and the tools it called, to the
+// statistics of the current conversation
+func (l *conversationLog) recordUsage(response *model.Response, latency time.Duration) {
+	if l.err != nil || l.id == "" {
+		return
+	}
+	if err := l.store.RecordModelCall(l.id, response.Usage.PromptTokens, response.Usage.CompletionTokens, latency); err != nil {
+		l.err = err
+		return
+	}
+	if n := len(response.ToolCalls); n > 0 {
+		if err := l.store.RecordToolCalls(l.id, n); err != nil {
+			l.err = err
+			return
+		}
+		tools := make([]string, n)
+		for i, call := range response.ToolCalls {
+			tools[i] = call.Name
+		}
+		if err := l.store.RecordToolUsage(l.id, tools, response.Usage.PromptTokens, response.Usage.CompletionTokens); err != nil {
+			l.err = err
+		}
+	}
+}
+
+// restart makes the next message start a new conversation
+func (l *conversationLog) restart(skip int) {
+	l.id = ""
+	l.skip = skip
+	l.parent = 0
+	l.summary = ""
+	l.unsummarized = 0
+}
+
+// branch makes the next message start a conversation that continues from the
+// first index messages. Those already saved are referenced through the last
+// of them instead of being saved again, and count towards the branch's first
+// summary since the parent's may cover messages after the fork.
+func (l *conversationLog) branch(messages []ChatMessage, index int) {
+	l.restart(min(l.skip, index))
+	for i := index - 1; i >= 0; i-- {
+		if messages[i].storedID != 0 {
+			l.parent = messages[i].storedID
+			l.skip = index
+			break
+		}
+	}
+	if l.parent != 0 {
+		l.unsummarized = len(recentSavedMessages(messages[:index], index))
+	}
+}
+
+// takeError returns and clears the last write failure. Saving resumes afterwards.
+func (l *conversationLog) takeError() error {
+	err := l.err
+	l.err = nil
+	return err
+}
+
+// latest returns a command that loads the most recent conversation other than
+// the one being written
+func (l *conversationLog) latest() tea.Cmd {
+	store, current := l.store, l.id
+	return func() tea.Msg {
+		conversations, err := store.ListConversations(2, 0)
+		if err != nil {
+			return conversationRestoredMsg{err: err}
+		}
+		for _, conv := range conversations {
+			if conv.ID == current {
+				continue
+			}
+			messages, err := store.GetThread(conv.ID)
+			if err != nil {
+				return conversationRestoredMsg{err: err}
+			}
+			ids := make([]int64, len(messages))
+			for i, msg := range messages {
+				ids[i] = msg.ID
+			}
+			var attachments []*storage.Attachment
+			if len(ids) > 0 {
+				attachments, err = store.ListAttachments(storage.AttachmentFilter{MessageIDs: ids})
+			}
+			return conversationRestoredMsg{conversation: conv, messages: messages, attachments: attachments, err: err}
+		}
+		return conversationRestoredMsg{}
+	}
+}
+
+// savedMessage reports whether msg belongs in the saved conversation. Slash
+// commands only drive the interface and are left out, as are transient
+// messages.
+func savedMessage(msg ChatMessage) bool {
+	return !msg.Transient && !(msg.Role == "user" && strings.HasPrefix(msg.Content, "/"))
+}
+
+// hasSavedMessage reports whether any of messages would be saved
+func hasSavedMessage(messages []ChatMessage) bool {
+	for _, msg := range messages {
+		if savedMessage(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// conversationTitle names a conversation after its first prompt
+func conversationTitle(messages []ChatMessage) string {
+	for _, msg := range messages {
+		if msg.Role != "user" || !savedMessage(msg) {
+			continue
+		}
+		title := []rune(strings.Join(strings.Fields(msg.Content), " "))
+		if len(title) > maxTitleLength {
+			return string(title[:maxTitleLength-3]) + "..."
+		}
+		return string(title)
+	}
+	return "Conversation"
+}
+
+// fromStorageMessage converts a stored message back into a chat message
+func fromStorageMessage(stored *storage.Message) ChatMessage {
+	msg := ChatMessage{
+		Role:      stored.Role,
+		Content:   stored.Content,
+		Timestamp: stored.Timestamp.Format("15:04:05"),
+		Tags:      stored.Tags,
+		Pinned:    stored.Pinned,
+		storedID:  stored.ID,
+	}
+	if stored.ToolCall != nil {
+		msg.ToolCall = &ToolCallInfo{Name: stored.ToolCall.Name, Args: stored.ToolCall.Arguments}
+	}
+	if stored.ToolResult != nil {
+		if stored.ToolResult.IsError {
+			msg.Error = stored.ToolResult.Content
+		} else if msg.ToolCall != nil {
+			msg.ToolCall.Result = stored.ToolResult.Content
+		}
+	}
+	return msg
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConversationStore(t *testing.T) *storage.ConversationStore {
+	t.Helper()
+	store, err := storage.NewConversationStore(filepath.Join(t.TempDir(), storage.DatabaseFile))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func savedConversations(t *testing.T, store *storage.ConversationStore) []*storage.Conversation {
+	t.Helper()
+	conversations, err := store.ListConversations(10, 0)
+	require.NoError(t, err)
+	return conversations
+}
+
+func savedContents(t *testing.T, store *storage.ConversationStore, id string) []string {
+	t.Helper()
+	messages, err := store.GetMessages(id, -1, 0)
+	require.NoError(t, err)
+	var contents []string
+	for _, msg := range messages {
+		contents = append(contents, msg.Content)
+	}
+	return contents
+}
+
+func TestChatView_SavesMessagesAsTheyHappen(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetConversationStore(store)
+
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "/tools"})
+	assert.Empty(t, savedConversations(t, store), "Commands alone don't start a conversation")
+
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "find my notes about Go"})
+	chatView.AddMessage(ChatMessage{
+		Role:     "tool",
+		Content:  "Executing tool: search...",
+		ToolCall: &ToolCallInfo{Name: "search", Args: map[string]interface{}{"query": "Go"}, Result: "3 notes"},
+	})
+	chatView.AddMessage(ChatMessage{Role: "assistant", Error: "model unavailable"})
+	require.NoError(t, chatView.persistError())
+
+	conversations := savedConversations(t, store)
+	require.Len(t, conversations, 1)
+	assert.Equal(t, "find my notes about Go", conversations[0].Title)
+
+	messages, err := store.GetMessages(conversations[0].ID, -1, 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 3, "The welcome message and commands are not saved")
+	require.NotNil(t, messages[1].ToolCall)
+	assert.Equal(t, "search", messages[1].ToolCall.Name)
+	assert.Equal(t, "Go", messages[1].ToolCall.Arguments["query"])
+	assert.Equal(t, "3 notes", messages[1].ToolResult.Content)
+	assert.True(t, messages[2].ToolResult.IsError)
+}
+
+func TestChatView_ResumeRestoresLatestConversation(t *testing.T) {
+	store := newTestConversationStore(t)
+	first := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	first.SetConversationStore(store)
+	first.AddMessage(ChatMessage{Role: "user", Content: "hello"})
+	first.AddMessage(ChatMessage{
+		Role:     "tool",
+		ToolCall: &ToolCallInfo{Name: "search", Result: "found"},
+	})
+	first.AddMessage(ChatMessage{Role: "assistant", Error: "timeout"})
+
+	// A restarted session picks the conversation back up
+	second := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	second.SetConversationStore(store)
+	second.ResumeOnInit()
+	deliver(t, second, second.Init())
+
+	require.Len(t, second.messages, 3)
+	assert.Equal(t, "hello", second.messages[0].Content)
+	assert.Equal(t, "found", second.messages[1].ToolCall.Result)
+	assert.Equal(t, "timeout", second.messages[2].Error)
+
+	second.AddMessage(ChatMessage{Role: "user", Content: "and again"})
+	conversations := savedConversations(t, store)
+	require.Len(t, conversations, 1, "New messages continue the resumed conversation")
+	assert.Equal(t, []string{"hello", "", "", "and again"}, savedContents(t, store, conversations[0].ID))
+}
+
+func TestChatView_ResumeWithoutHistory(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	cmd := chatView.handleCommand("/resume")
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Conversation history is unavailable", cmd().(ToastMsg).Text)
+
+	chatView.SetConversationStore(newTestConversationStore(t))
+	cmd = chatView.handleCommand("/resume")
+	require.NotNil(t, cmd)
+	_, cmd = chatView.Update(cmd())
+	require.NotNil(t, cmd)
+	assert.Equal(t, "No earlier conversation to resume", cmd().(ToastMsg).Text)
+}
+
+func TestChatView_BranchSavesNewConversation(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetConversationStore(store)
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "first"})
+	chatView.AddMessage(ChatMessage{Role: "assistant", Content: "reply"})
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "second"})
+
+	// Branch from "second": the welcome message is at index 0
+	chatView.truncateMessages(3)
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "second, rephrased"})
+
+	conversations := savedConversations(t, store)
+	require.Len(t, conversations, 2)
+	original, branch := conversations[1], conversations[0]
+	if branch.ParentConversationID == "" {
+		original, branch = branch, original
+	}
+	assert.Equal(t, original.ID, branch.ParentConversationID)
+	assert.Equal(t, []string{"first", "reply", "second"}, savedContents(t, store, original.ID))
+	assert.Equal(t, []string{"second, rephrased"}, savedContents(t, store, branch.ID),
+		"The branch refers to the shared messages instead of copying them")
+
+	// Resuming the branch restores the messages it continues from
+	resumed := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	resumed.SetConversationStore(store)
+	resumed.ResumeOnInit()
+	deliver(t, resumed, resumed.Init())
+	assert.Equal(t, []string{"first", "reply", "second, rephrased"}, contents(resumed))
+
+	// Branching again within the shared messages forks from the original
+	resumed.truncateMessages(1)
+	resumed.AddMessage(ChatMessage{Role: "user", Content: "reply, rephrased"})
+	conversations = savedConversations(t, store)
+	require.Len(t, conversations, 3)
+	assert.Equal(t, original.ID, conversations[0].ParentConversationID)
+	thread, err := store.GetThread(conversations[0].ID)
+	require.NoError(t, err)
+	require.Len(t, thread, 2)
+	assert.Equal(t, "first", thread[0].Content)
+	assert.Equal(t, "reply, rephrased", thread[1].Content)
+}
+
+func TestChatView_BranchBeforeAnythingSavedStartsFresh(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetConversationStore(store)
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "first"})
+
+	chatView.truncateMessages(1)
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "first, rephrased"})
+
+	conversations := savedConversations(t, store)
+	require.Len(t, conversations, 2)
+	assert.Empty(t, conversations[0].ParentConversationID)
+	assert.Equal(t, []string{"first, rephrased"}, savedContents(t, store, conversations[0].ID))
+}
+
+func TestConversationTitle(t *testing.T) {
+	long := "please summarize every single note I have written about distributed systems"
+	title := conversationTitle([]ChatMessage{{Role: "user", Content: long}})
+	assert.Len(t, []rune(title), maxTitleLength)
+	assert.Equal(t, "...", title[len(title)-3:])
+	assert.Equal(t, "Conversation", conversationTitle(nil))
+}
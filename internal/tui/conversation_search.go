@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+const (
+	// searchResultLimit caps how many matches /search lists
+	searchResultLimit = 10
+
+	// searchTimeout bounds a search, including embedding any messages saved
+	// since the last one
+	searchTimeout = 60 * time.Second
+)
+
+// ConversationSearcher finds saved messages by their words and meaning
+type ConversationSearcher interface {
+	Search(ctx context.Context, query string, limit int) ([]*storage.SearchResult, error)
+}
+
+// searchResultsMsg carries the matches found by /search
+type searchResultsMsg struct {
+	query   string
+	results []*storage.SearchResult
+	err     error
+}
+
+// SetConversationSearcher enables the /search command
+func (v *ChatView) SetConversationSearcher(searcher ConversationSearcher) {
+	v.searcher = searcher
+}
+
+// searchConversations returns a command that searches saved conversations for query
+func (v *ChatView) searchConversations(query string) tea.Cmd {
+	if query == "" {
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   "Usage: /search <text>",
+			Timestamp: time.Now().Format("15:04:05"),
+		})
+		return nil
+	}
+	if v.searcher == nil {
+		return toastCmd("Conversation search is unavailable", ToastWarning)
+	}
+
+	searcher := v.searcher
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), searchTimeout)
+		defer cancel()
+		results, err := searcher.Search(ctx, query, searchResultLimit)
+		return searchResultsMsg{query: query, results: results, err: err}
+	}
+}
+
+// showSearchResults lists the matches found by /search in the chat
+func (v *ChatView) showSearchResults(msg searchResultsMsg) tea.Cmd {
+	if msg.err != nil {
+		return toastCmd("Search failed: "+msg.err.Error(), ToastError)
+	}
+	v.AddMessage(ChatMessage{
+		Role:      "assistant",
+		Content:   formatSearchResults(msg.query, msg.results),
+		Timestamp: time.Now().Format("15:04:05"),
+		// Saving results would make later searches find them
+		Transient: true,
+	})
+	return nil
+}
+
+// formatSearchResults describes each match on one line with when it was said,
+// by whom, and a snippet around the match
+func formatSearchResults(query string, results []*storage.SearchResult) string {
+	if len(results) == 0 {
+		return fmt.Sprintf("No saved messages match %q.", query)
+	}
+
+	noun := "messages"
+	if len(results) == 1 {
+		noun = "message"
+	}
+	lines := []string{fmt.Sprintf("Found %d %s matching %q:", len(results), noun, query)}
+	for i, result := range results {
+		lines = append(lines, fmt.Sprintf("%d. (%s) %s: %s",
+			i+1,
+			result.Message.Timestamp.Local().Format("2006-01-02 15:04"),
+			result.Message.Role,
+			result.Snippet,
+		))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rankedSearcher searches a real store by text, standing in for the agent's
+// hybrid search
+type rankedSearcher struct {
+	manager *storage.SearchManager
+}
+
+func (s rankedSearcher) Search(ctx context.Context, query string, limit int) ([]*storage.SearchResult, error) {
+	return s.manager.SearchRanked(storage.SearchFilter{Query: query, Limit: limit})
+}
+
+type failingSearcher struct{}
+
+func (failingSearcher) Search(ctx context.Context, query string, limit int) ([]*storage.SearchResult, error) {
+	return nil, errors.New("database is locked")
+}
+
+func TestChatView_SearchCommand(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetConversationStore(store)
+	chatView.SetConversationSearcher(rankedSearcher{manager: store.SearchManager()})
+
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "how do I rotate my postgres logs?"})
+	chatView.AddMessage(ChatMessage{Role: "assistant", Content: "Use logrotate with copytruncate."})
+
+	deliver(t, chatView, chatView.handleCommand("/search postgres"))
+
+	last := chatView.messages[len(chatView.messages)-1]
+	assert.True(t, last.Transient)
+	assert.True(t, strings.HasPrefix(last.Content, `Found 1 message matching "postgres":`))
+	assert.Contains(t, last.Content, "user: how do I rotate my [postgres] logs?")
+
+	// Results are not saved, so they never turn up in later searches
+	conversations := savedConversations(t, store)
+	require.Len(t, conversations, 1)
+	assert.Len(t, savedContents(t, store, conversations[0].ID), 2)
+
+	deliver(t, chatView, chatView.handleCommand("/search kubernetes"))
+	assert.Equal(t, `No saved messages match "kubernetes".`, chatView.messages[len(chatView.messages)-1].Content)
+}
+
+func TestChatView_SearchCommandUsage(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+
+	assert.Nil(t, chatView.handleCommand("/search"))
+	assert.Equal(t, "Usage: /search <text>", chatView.messages[len(chatView.messages)-1].Content)
+
+	cmd := chatView.handleCommand("/search anything")
+	require.NotNil(t, cmd)
+	toast, ok := cmd().(ToastMsg)
+	require.True(t, ok)
+	assert.Equal(t, ToastWarning, toast.Level)
+	assert.Contains(t, toast.Text, "unavailable")
+}
+
+func TestChatView_SearchCommandFailure(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	chatView.SetConversationSearcher(failingSearcher{})
+
+	cmd := chatView.handleCommand("/search anything")
+	require.NotNil(t, cmd)
+	_, next := chatView.Update(cmd())
+	require.NotNil(t, next)
+	toast, ok := next().(ToastMsg)
+	require.True(t, ok)
+	assert.Equal(t, ToastError, toast.Level)
+	assert.Equal(t, "Search failed: database is locked", toast.Text)
+}
+
+func TestFormatSearchResults(t *testing.T) {
+	at := time.Date(2025, 3, 14, 9, 30, 0, 0, time.Local)
+	results := []*storage.SearchResult{
+		{Message: &storage.Message{Role: "user", Timestamp: at}, Snippet: "my [car] broke down"},
+		{Message: &storage.Message{Role: "assistant", Timestamp: at}, Snippet: "Which automobile..."},
+	}
+
+	assert.Equal(t, `Found 2 messages matching "car":
+1. (2025-03-14 09:30) user: my [car] broke down
+2. (2025-03-14 09:30) assistant: Which automobile...`, formatSearchResults("car", results))
+}
@@ -0,0 +1,210 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+const (
+	// summaryInterval is how many saved messages trigger a new title and
+	// rolling summary
+	summaryInterval = 6
+
+	// summaryMessageLength truncates each message quoted in a summary request
+	summaryMessageLength = 500
+
+	// summaryTimeout bounds a summary request
+	summaryTimeout = 2 * time.Minute
+)
+
+// conversationSummarizedMsg carries the title and summary generated for a
+// saved conversation
+type conversationSummarizedMsg struct {
+	id        string
+	title     string
+	summary   string
+	compacted int // Messages folded in at the user's request with /compact
+	err       error
+}
+
+// summarize returns a command that asks the model to title and summarize the
+// conversation once enough messages have been saved since the last summary.
+// The new summary folds the recent messages into the previous one.
+func (v *ChatView) summarize() tea.Cmd {
+	l := v.log
+	if l == nil || v.model == nil || l.id == "" || l.summarizing || l.unsummarized < summaryInterval {
+		return nil
+	}
+	return v.writeSummary(0)
+}
+
+// writeSummary asks the model for a new title and summary that fold the
+// unsummarized messages into the previous summary. compacted is reported
+// back when the user asked for the summary.
+func (v *ChatView) writeSummary(compacted int) tea.Cmd {
+	l := v.log
+	prompt := summaryPrompt(l.summary, recentSavedMessages(v.messages, l.unsummarized))
+	l.unsummarized = 0
+	l.summarizing = true
+
+	id, m := l.id, v.model
+	options := v.options
+	options.Temperature = 0.2
+	options.MaxTokens = 300
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), summaryTimeout)
+		defer cancel()
+
+		response, err := m.Generate(ctx, prompt, options)
+		if err != nil {
+			return conversationSummarizedMsg{id: id, err: err}
+		}
+		title, summary, err := parseSummary(response.Content)
+		if err != nil {
+			return conversationSummarizedMsg{id: id, err: err}
+		}
+		return conversationSummarizedMsg{id: id, title: title, summary: summary, compacted: compacted}
+	}
+}
+
+// summarized saves a generated summary and keeps it as context for the
+// conversation it belongs to
+func (v *ChatView) summarized(msg conversationSummarizedMsg) tea.Cmd {
+	if v.log == nil {
+		return nil
+	}
+	v.log.summarizing = false
+	if msg.err != nil {
+		return toastCmd("Failed to summarize conversation: "+msg.err.Error(), ToastWarning)
+	}
+	// Saved here rather than with the request, so no write is left running
+	// when the TUI exits and the store is closed
+	if err := v.log.store.UpdateConversationSummary(msg.id, msg.title, msg.summary); err != nil {
+		return toastCmd("Failed to summarize conversation: "+err.Error(), ToastWarning)
+	}
+	if msg.id == v.log.id {
+		v.log.summary = msg.summary
+	}
+	if msg.compacted > 0 {
+		return toastCmd(fmt.Sprintf("Compacted %d messages into the conversation summary", msg.compacted), ToastSuccess)
+	}
+	return nil
+}
+
+// conversationSummary returns the rolling summary of the current conversation
+func (v *ChatView) conversationSummary() string {
+	if v.log == nil {
+		return ""
+	}
+	return v.log.summary
+}
+
+// contextMessages returns the messages sent to the model for text. Earlier
+// turns reach the model compacted into the conversation summary, which is
+// sent with the active persona's prompt, the session mode's instructions, any
+// pinned messages, and metadata from tool results as a system message.
+func (v *ChatView) contextMessages(text string) []model.Message {
+	var context []string
+	if persona := v.personaPrompt(); persona != "" {
+		context = append(context, persona)
+	}
+	if mode := v.modePrompt(); mode != "" {
+		context = append(context, mode)
+	}
+	if pinned := v.pinnedContextMessage(); pinned != "" {
+		context = append(context, pinned)
+	}
+	if summary := v.conversationSummary(); summary != "" {
+		context = append(context, "Summary of the conversation so far:\n"+summary)
+	}
+	if v.conversationContext != nil && v.conversationContext.Entities.Len() > 0 {
+		if metadata := v.buildMetadataContextForModel(); metadata != "" {
+			context = append(context, metadata)
+		}
+	}
+
+	messages := []model.Message{{Role: "user", Content: text}}
+	if len(context) > 0 {
+		messages = append([]model.Message{{Role: "system", Content: strings.Join(context, "\n\n")}}, messages...)
+	}
+	return messages
+}
+
+// recentSavedMessages returns the last n messages of the conversation that
+// are saved
+func recentSavedMessages(messages []ChatMessage, n int) []ChatMessage {
+	var recent []ChatMessage
+	for i := len(messages) - 1; i >= 0 && len(recent) < n; i-- {
+		if messages[i].storedID != 0 {
+			recent = append([]ChatMessage{messages[i]}, recent...)
+		}
+	}
+	return recent
+}
+
+// summaryPrompt asks for a title and a summary covering previous, the summary
+// so far, and the recent messages
+func summaryPrompt(previous string, recent []ChatMessage) string {
+	var b strings.Builder
+	b.WriteString("Summarize this conversation between a user and an AI assistant.\n\n")
+	if previous != "" {
+		b.WriteString("Summary of the earlier conversation:\n")
+		b.WriteString(previous)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Recent messages:\n")
+	for _, msg := range recent {
+		content := msg.Content
+		if msg.ToolCall != nil {
+			content = fmt.Sprintf("called %s: %s", msg.ToolCall.Name, msg.ToolCall.Result)
+		}
+		if msg.Error != "" {
+			content = strings.TrimSpace(content + " (error: " + msg.Error + ")")
+		}
+		if runes := []rune(content); len(runes) > summaryMessageLength {
+			content = string(runes[:summaryMessageLength]) + "..."
+		}
+		fmt.Fprintf(&b, "%s: %s\n", msg.Role, content)
+	}
+	b.WriteString("\nReply in exactly this format:\n")
+	b.WriteString("TITLE: <a title of at most six words>\n")
+	b.WriteString("SUMMARY: <two to four sentences covering the whole conversation, including facts and decisions worth remembering>\n")
+	return b.String()
+}
+
+// parseSummary reads the title and summary from a reply to summaryPrompt
+func parseSummary(reply string) (string, string, error) {
+	var title, summary string
+	var inSummary bool
+	for _, line := range strings.Split(reply, "\n") {
+		// Models often mark the labels up as bold or headings
+		trimmed := strings.TrimLeft(strings.TrimSpace(line), "*# ")
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasPrefix(upper, "TITLE:"):
+			title = strings.Trim(trimmed[len("TITLE:"):], "* ")
+			inSummary = false
+		case strings.HasPrefix(upper, "SUMMARY:"):
+			summary = strings.Trim(trimmed[len("SUMMARY:"):], "* ")
+			inSummary = true
+		case inSummary && trimmed != "":
+			// The model wrapped the summary over several lines
+			summary += " " + trimmed
+		}
+	}
+
+	title = strings.Trim(title, `"*' `)
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = string(runes[:maxTitleLength-3]) + "..."
+	}
+	if title == "" || summary == "" {
+		return "", "", errors.New("model reply did not include a title and summary")
+	}
+	return title, summary, nil
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatView_SummarizesConversation(t *testing.T) {
+	store := newTestConversationStore(t)
+	var prompt string
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{
+		generateFunc: func(ctx context.Context, p string, opts model.GenerateOptions) (*model.Response, error) {
+			prompt = p
+			return &model.Response{Content: "TITLE: Planning a Go refactor\nSUMMARY: The user is splitting the storage\npackage and chose SQLite."}, nil
+		},
+	})
+	chatView.SetConversationStore(store)
+
+	for _, content := range []string{"let's refactor storage", "sure", "use SQLite", "agreed", "split the package"} {
+		chatView.AddMessage(ChatMessage{Role: "user", Content: content})
+	}
+	assert.Nil(t, chatView.summarize(), "Too few messages to summarize yet")
+
+	chatView.requestID = "req_1"
+	_, cmd := chatView.Update(ModelResponseMsg{ID: "req_1", Response: &model.Response{Content: "done"}})
+	require.NotNil(t, cmd)
+	assert.Nil(t, chatView.summarize(), "Only one summary is requested at a time")
+
+	_, cmd = chatView.Update(cmd())
+	assert.Nil(t, cmd)
+	assert.Contains(t, prompt, "user: use SQLite")
+	assert.Contains(t, prompt, "assistant: done")
+
+	conversations := savedConversations(t, store)
+	require.Len(t, conversations, 1)
+	assert.Equal(t, "Planning a Go refactor", conversations[0].Title)
+	assert.Equal(t, "The user is splitting the storage package and chose SQLite.", conversations[0].Summary)
+
+	// Earlier turns reach the model through the summary
+	messages := chatView.contextMessages("what next?")
+	require.Len(t, messages, 2)
+	assert.Equal(t, "system", messages[0].Role)
+	assert.Contains(t, messages[0].Content, "chose SQLite")
+	assert.Equal(t, model.Message{Role: "user", Content: "what next?"}, messages[1])
+
+	// A cleared chat starts without the summary
+	chatView.log.restart(0)
+	assert.Len(t, chatView.contextMessages("hello"), 1)
+}
+
+func TestChatView_ResumeRestoresSummary(t *testing.T) {
+	store := newTestConversationStore(t)
+	first := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	first.SetConversationStore(store)
+	first.AddMessage(ChatMessage{Role: "user", Content: "hello"})
+	require.NoError(t, store.UpdateConversationSummary(first.log.id, "Greeting", "The user said hello."))
+
+	second := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	second.SetConversationStore(store)
+	second.ResumeOnInit()
+	deliver(t, second, second.Init())
+
+	assert.Equal(t, "The user said hello.", second.conversationSummary())
+}
+
+func TestParseSummary(t *testing.T) {
+	title, summary, err := parseSummary("Sure!\n**Title:** \"Trip to Lisbon\"\nSummary: Booked flights.\nHotel is pending.\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Trip to Lisbon", title)
+	assert.Equal(t, "Booked flights. Hotel is pending.", summary)
+
+	_, _, err = parseSummary("I can't summarize this.")
+	assert.Error(t, err)
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DryRunner is implemented by agents that can validate tool calls without
+// running them
+type DryRunner interface {
+	SetDryRun(on bool)
+	DryRun() bool
+}
+
+// DryRun reports whether the agent only validates tool calls, not runs them
+func (v *ChatView) DryRun() bool {
+	runner, ok := v.agent.(DryRunner)
+	return ok && runner.DryRun()
+}
+
+// setDryRun handles /dryrun: "on" and "off" switch dry-run mode, and without
+// an argument it says whether it is on
+func (v *ChatView) setDryRun(arg string) tea.Cmd {
+	runner, ok := v.agent.(DryRunner)
+	if !ok {
+		return toastCmd("Dry runs need an agent that runs tools", ToastWarning)
+	}
+	switch strings.ToLower(arg) {
+	case "on":
+		runner.SetDryRun(true)
+		return toastCmd("Dry run on: tool calls are validated but not run", ToastWarning)
+	case "off":
+		runner.SetDryRun(false)
+		return toastCmd("Dry run off: tools run again", ToastSuccess)
+	case "":
+		if runner.DryRun() {
+			return toastCmd("Dry run is on. Type /dryrun off to run tools again.", ToastInfo)
+		}
+		return toastCmd("Dry run is off. Type /dryrun on to validate tool calls without running them.", ToastInfo)
+	}
+	return toastCmd(fmt.Sprintf("Unknown /dryrun option %s. Use on or off.", arg), ToastWarning)
+}
@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dryRunAgent records whether dry-run mode is on
+type dryRunAgent struct {
+	MockAgentForChat
+	dryRun bool
+}
+
+func (a *dryRunAgent) SetDryRun(on bool) { a.dryRun = on }
+func (a *dryRunAgent) DryRun() bool      { return a.dryRun }
+
+func TestChatView_DryRunCommand(t *testing.T) {
+	agent := &dryRunAgent{}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), &MockModel{}, agent)
+
+	assert.Equal(t, "Dry run is off. Type /dryrun on to validate tool calls without running them.", chatView.setDryRun("")().(ToastMsg).Text)
+	assert.Equal(t, "Dry run on: tool calls are validated but not run", chatView.setDryRun("ON")().(ToastMsg).Text)
+	assert.True(t, agent.dryRun)
+	assert.True(t, chatView.DryRun())
+	assert.Equal(t, "Unknown /dryrun option maybe. Use on or off.", chatView.setDryRun("maybe")().(ToastMsg).Text)
+	assert.True(t, agent.dryRun)
+	assert.Equal(t, "Dry run off: tools run again", chatView.setDryRun("off")().(ToastMsg).Text)
+	assert.False(t, chatView.DryRun())
+
+	withoutAgent := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	assert.Equal(t, "Dry runs need an agent that runs tools", withoutAgent.setDryRun("on")().(ToastMsg).Text)
+	assert.False(t, withoutAgent.DryRun())
+}
+
+func TestApplication_StatusBarShowsDryRun(t *testing.T) {
+	agent := &dryRunAgent{}
+	app := NewApplication(&MockModel{})
+	app.width = 120
+	app.chatView = NewChatViewWithAgent(app.styles, app.keymap, &MockModel{}, agent)
+	assert.NotContains(t, app.renderStatusBar(), "DRY RUN")
+
+	agent.SetDryRun(true)
+	assert.Contains(t, app.renderStatusBar(), "🧪 DRY RUN")
+}
@@ -71,9 +71,44 @@ func (v *HelpView) View() string {
   /tools      Switch to tools view  
   /help       Switch to help view
   /history    Switch to history view
+  /audit      Switch to the tool audit log (f shows failures only)
+  /stats      Show token use, latency, and tool calls per conversation
+  /usage      Show tokens and cost this session, per conversation and per tool
+  /export     Save the conversation: /export markdown|json|html [path]
+  /share      Save a transcript with secrets masked, uploaded if share.upload is set
+  /resume     Restore the most recent saved conversation
+  /search     Find saved messages by words and meaning: /search <text>
+              Add #tag to require a tag, or is:pinned for pinned messages
+  /tag        Tag the latest message: /tag <tag> (/untag removes it)
+  /pin        Pin the latest message (/unpin, /pinned lists them)
+  /remember   Remember a fact across conversations: /remember [topic:] <fact>
+  /memories   List remembered facts, optionally for one topic
+  /forget     Delete a remembered fact: /forget <id>
+  /attach     Send a file or image with your next message: /attach <path>
+  /detach     Remove the files attached to your next message
+  /regenerate Ask again for the last response: /regenerate [temperature]
+  /edit       Edit your latest (or nth latest) message and branch from it
+  /undo       Remove your last message and reverse its tool calls where possible
+  /compact    Fold earlier messages into the conversation summary now
+  /persona    List personas, or switch: /persona <name> (/persona off for none)
+  /template   List saved prompts, or fill one in and send it: /template <name>
+  /mode       List session modes, or switch: /mode chat|analysis|automation
+  /dryrun     Validate tool calls without running them: /dryrun on|off
   /chat       Stay in chat view
   /exit       Exit the application
 
+  While a response is being worked on, type more and press Enter to
+  interrupt it: the agent starts over with your update added
+
+  Typing / or @ opens suggestions for commands and tools:
+  ↑/↓ to select, Tab or Enter to accept, Esc to dismiss
+
+🖱️  Mouse:
+  Wheel        Scroll the conversation
+  Click        Select a message (click again to expand)
+  Ctrl+Y       Copy the selected message
+  Esc          Clear the selection
+
 🔧 Tool Execution:
   - Navigate to Tools view (press 3 or /tools)
   - Use arrow keys to select a tool
@@ -87,6 +122,7 @@ func (v *HelpView) View() string {
   4    Help (this view) 
   5    Conversation history
   Tab  Cycle through views
+  Ctrl+O  Toggle the activity pane (tool calls, logs) beside the chat
   Esc  Back to chat view
   Ctrl+C  Exit application`)
 	
@@ -1,11 +1,22 @@
 package tui
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
 )
 
+// historyLimit caps how many conversations the history view lists
+const historyLimit = 200
+
+// summaryLineLength limits the summary shown under each conversation
+const summaryLineLength = 80
+
 // HistoryView handles the conversation history interface
 type HistoryView struct {
 	width    int
@@ -13,6 +24,26 @@ type HistoryView struct {
 	styles   Styles
 	keymap   KeyMap
 	viewport viewport.Model
+	store    ConversationStore // nil without storage
+
+	conversations []*storage.Conversation
+	attachments   map[string][]*storage.Attachment // by conversation ID
+	listed        []*storage.Attachment            // attachments in the order shown
+	selected      int                              // index into listed, -1 for none
+}
+
+// historyLoadedMsg carries the saved conversations shown in the history view
+type historyLoadedMsg struct {
+	conversations []*storage.Conversation
+	attachments   []*storage.Attachment
+	err           error
+}
+
+// attachmentOpenedMsg reports the outcome of opening an attachment
+type attachmentOpenedMsg struct {
+	name string
+	path string
+	err  error
 }
 
 // NewHistoryView creates a new history view
@@ -24,6 +55,7 @@ func NewHistoryView(styles Styles, keymap KeyMap) *HistoryView {
 		styles:   styles,
 		keymap:   keymap,
 		viewport: vp,
+		selected: -1,
 	}
 }
 
@@ -32,11 +64,116 @@ func (v *HistoryView) Init() tea.Cmd {
 	return nil
 }
 
+// SetConversationStore sets where the saved conversations are read from
+func (v *HistoryView) SetConversationStore(store ConversationStore) {
+	v.store = store
+}
+
+// Load returns a command that reads the saved conversations
+func (v *HistoryView) Load() tea.Cmd {
+	store := v.store
+	if store == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		conversations, err := store.ListConversations(historyLimit, 0)
+		if err != nil || len(conversations) == 0 {
+			return historyLoadedMsg{conversations: conversations, err: err}
+		}
+		ids := make([]string, len(conversations))
+		for i, conv := range conversations {
+			ids[i] = conv.ID
+		}
+		attachments, err := store.ListAttachments(storage.AttachmentFilter{ConversationIDs: ids})
+		return historyLoadedMsg{conversations: conversations, attachments: attachments, err: err}
+	}
+}
+
+// openSelected returns a command that opens the selected attachment
+func (v *HistoryView) openSelected() tea.Cmd {
+	if v.store == nil || v.selected < 0 || v.selected >= len(v.listed) {
+		return nil
+	}
+	store, attachment := v.store, v.listed[v.selected]
+	return func() tea.Msg {
+		data, err := store.ReadAttachment(attachment.ID)
+		if err != nil {
+			return attachmentOpenedMsg{name: attachment.Name, err: err}
+		}
+		path, err := openAttachment(attachment, data)
+		return attachmentOpenedMsg{name: attachment.Name, path: path, err: err}
+	}
+}
+
+// selectAttachment moves the selection by delta, wrapping around, and scrolls
+// the selected attachment into view
+func (v *HistoryView) selectAttachment(delta int) {
+	if len(v.listed) == 0 {
+		return
+	}
+	if v.selected < 0 {
+		if delta > 0 {
+			v.selected = 0
+		} else {
+			v.selected = len(v.listed) - 1
+		}
+	} else {
+		v.selected = (v.selected + delta + len(v.listed)) % len(v.listed)
+	}
+	v.render()
+}
+
+// render shows the loaded conversations, keeping the selected attachment in view
+func (v *HistoryView) render() {
+	var selectedID int64
+	if v.selected >= 0 && v.selected < len(v.listed) {
+		selectedID = v.listed[v.selected].ID
+	}
+	tree := renderConversationTree(v.conversations, v.attachments, selectedID)
+	v.listed = tree.attachments
+	v.viewport.SetContent(tree.content)
+	if tree.selectedLine >= 0 {
+		if tree.selectedLine < v.viewport.YOffset {
+			v.viewport.SetYOffset(tree.selectedLine)
+		} else if tree.selectedLine >= v.viewport.YOffset+v.viewport.Height {
+			v.viewport.SetYOffset(tree.selectedLine - v.viewport.Height + 1)
+		}
+	}
+}
+
 // Update handles updates for the history view
 func (v *HistoryView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case historyLoadedMsg:
+		if msg.err != nil {
+			v.viewport.SetContent("Failed to load conversation history: " + msg.err.Error())
+			return v, nil
+		}
+		v.conversations = msg.conversations
+		v.attachments = make(map[string][]*storage.Attachment)
+		for _, a := range msg.attachments {
+			v.attachments[a.ConversationID] = append(v.attachments[a.ConversationID], a)
+		}
+		v.selected = -1
+		v.render()
+		return v, nil
+
+	case attachmentOpenedMsg:
+		if msg.err != nil {
+			return v, toastCmd("Failed to open "+msg.name+": "+msg.err.Error(), ToastError)
+		}
+		return v, toastCmd("Opened "+msg.path, ToastSuccess)
+
 	case tea.KeyMsg:
 		switch msg.String() {
+		case "n":
+			v.selectAttachment(1)
+			return v, nil
+		case "p":
+			v.selectAttachment(-1)
+			return v, nil
+		case "enter", "o":
+			return v, v.openSelected()
 		case "esc":
 			// Go back to chat view
 			return v, func() tea.Msg {
@@ -66,6 +203,7 @@ func (v *HistoryView) View() string {
 		lipgloss.Left,
 		header,
 		v.viewport.View(),
+		v.styles.DimmedStyle.Render("n/p: select attachment • enter: open • esc: back"),
 	)
 }
 
@@ -74,5 +212,98 @@ func (v *HistoryView) SetSize(width, height int) {
 	v.width = width
 	v.height = height
 	v.viewport.Width = width
-	v.viewport.Height = height - 3 // Account for header
-}
\ No newline at end of file
+	v.viewport.Height = height - 4 // Account for header and key hints
+}
+
+// conversationTree is the rendered history view content
+type conversationTree struct {
+	content      string
+	attachments  []*storage.Attachment // in the order shown
+	selectedLine int                   // line of the selected attachment, -1 for none
+}
+
+// renderConversationTree lists conversations with each branch indented under
+// the conversation it continues from, and their attachments beneath them.
+// Top-level conversations keep their order, most recently updated first;
+// branches are listed oldest first. The attachment with selectedID is marked.
+func renderConversationTree(conversations []*storage.Conversation, attachments map[string][]*storage.Attachment, selectedID int64) conversationTree {
+	tree := conversationTree{selectedLine: -1}
+	if len(conversations) == 0 {
+		tree.content = "No conversation history yet."
+		return tree
+	}
+
+	listed := make(map[string]bool, len(conversations))
+	for _, conv := range conversations {
+		listed[conv.ID] = true
+	}
+	children := make(map[string][]*storage.Conversation)
+	var roots []*storage.Conversation
+	for _, conv := range conversations {
+		// A branch whose parent fell outside the list is shown at the top level
+		if conv.ParentConversationID != "" && listed[conv.ParentConversationID] {
+			children[conv.ParentConversationID] = append(children[conv.ParentConversationID], conv)
+		} else {
+			roots = append(roots, conv)
+		}
+	}
+	for _, branches := range children {
+		sort.SliceStable(branches, func(i, j int) bool {
+			return branches[i].CreatedAt.Before(branches[j].CreatedAt)
+		})
+	}
+
+	var lines []string
+	var walk func(conv *storage.Conversation, prefix, connector, indent string)
+	walk = func(conv *storage.Conversation, prefix, connector, indent string) {
+		lines = append(lines, prefix+connector+describeConversation(conv))
+		branches := children[conv.ID]
+		// Keep the line to the first branch unbroken
+		gutter := "   "
+		if len(branches) > 0 {
+			gutter = "│  "
+		}
+		if conv.Summary != "" {
+			lines = append(lines, prefix+indent+gutter+summaryLine(conv.Summary))
+		}
+		for _, a := range attachments[conv.ID] {
+			marker := "  "
+			if a.ID == selectedID {
+				marker = "▸ "
+				tree.selectedLine = len(lines)
+			}
+			lines = append(lines, prefix+indent+gutter+marker+describeAttachment(a.Name, a.Size))
+			tree.attachments = append(tree.attachments, a)
+		}
+		for i, branch := range branches {
+			if i == len(branches)-1 {
+				walk(branch, prefix+indent, "└─ ", "   ")
+			} else {
+				walk(branch, prefix+indent, "├─ ", "│  ")
+			}
+		}
+	}
+	for _, root := range roots {
+		walk(root, "", "• ", "  ")
+	}
+	tree.content = strings.Join(lines, "\n")
+	return tree
+}
+
+// summaryLine shortens a conversation summary to fit on one line
+func summaryLine(summary string) string {
+	line := []rune(strings.Join(strings.Fields(summary), " "))
+	if len(line) > summaryLineLength {
+		return string(line[:summaryLineLength-3]) + "..."
+	}
+	return string(line)
+}
+
+// describeConversation summarizes a conversation on one line
+func describeConversation(conv *storage.Conversation) string {
+	noun := "messages"
+	if conv.MessageCount == 1 {
+		noun = "message"
+	}
+	return fmt.Sprintf("%s (%d %s, %s)", conv.Title, conv.MessageCount, noun, conv.UpdatedAt.Local().Format("2006-01-02 15:04"))
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderConversationTree(t *testing.T) {
+	at := time.Date(2025, 3, 14, 9, 30, 0, 0, time.Local)
+	conversations := []*storage.Conversation{
+		{ID: "b1", Title: "Branch one", MessageCount: 1, UpdatedAt: at, CreatedAt: at, ParentConversationID: "root"},
+		{ID: "root", Title: "Root", MessageCount: 4, UpdatedAt: at, CreatedAt: at.Add(-time.Hour)},
+		{ID: "b2", Title: "Branch two", MessageCount: 2, UpdatedAt: at, CreatedAt: at.Add(time.Minute), ParentConversationID: "root"},
+		{ID: "b1a", Title: "Nested", MessageCount: 2, UpdatedAt: at, CreatedAt: at, ParentConversationID: "b1"},
+		{ID: "orphan", Title: "Orphan", MessageCount: 2, UpdatedAt: at, CreatedAt: at, ParentConversationID: "unlisted"},
+	}
+
+	assert.Equal(t, `• Root (4 messages, 2025-03-14 09:30)
+  ├─ Branch one (1 message, 2025-03-14 09:30)
+  │  └─ Nested (2 messages, 2025-03-14 09:30)
+  └─ Branch two (2 messages, 2025-03-14 09:30)
+• Orphan (2 messages, 2025-03-14 09:30)`, renderConversationTree(conversations, nil, 0).content)
+
+	assert.Equal(t, "No conversation history yet.", renderConversationTree(nil, nil, 0).content)
+}
+
+func TestApplication_HistoryViewLoadsOnOpen(t *testing.T) {
+	store := newTestConversationStore(t)
+	_, err := store.CreateConversation("root", "Notes about Go")
+	require.NoError(t, err)
+	msg := &storage.Message{ConversationID: "root", Role: "user", Content: "hi", Timestamp: time.Now()}
+	require.NoError(t, store.AddMessage(msg))
+	_, err = store.CreateBranch("branch", "Notes, rephrased", msg.ID)
+	require.NoError(t, err)
+
+	app := NewApplication(&MockModel{})
+	app.historyView.SetConversationStore(store)
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	_, cmd := app.Update(ViewSwitchMsg{ViewType: HistoryViewType})
+	require.NotNil(t, cmd)
+	app.Update(cmd())
+
+	view := app.historyView.View()
+	assert.Contains(t, view, "• Notes about Go (1 message")
+	assert.Contains(t, view, "└─ Notes, rephrased (0 messages")
+}
+
+func TestRenderConversationTree_ShowsSummaries(t *testing.T) {
+	at := time.Date(2025, 3, 14, 9, 30, 0, 0, time.Local)
+	conversations := []*storage.Conversation{
+		{ID: "root", Title: "Root", MessageCount: 4, UpdatedAt: at, CreatedAt: at, Summary: "Planned the\nrelease."},
+		{ID: "branch", Title: "Branch", MessageCount: 1, UpdatedAt: at, CreatedAt: at, ParentConversationID: "root", Summary: "Tried another date."},
+	}
+
+	assert.Equal(t, `• Root (4 messages, 2025-03-14 09:30)
+  │  Planned the release.
+  └─ Branch (1 message, 2025-03-14 09:30)
+        Tried another date.`, renderConversationTree(conversations, nil, 0).content)
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// interjectionPrompt adds what the user typed while the agent was working to
+// the message the turn responds to
+const interjectionPrompt = "%s\n\nThe user interrupted to add: %s"
+
+// beginTurn starts the context a new turn's model and tool calls run under,
+// stopping the turn before. The turn ends when its time budget runs out. The
+// budget starts again when the user answers the turn's plan or question,
+// since the turn was waiting on them.
+func (v *ChatView) beginTurn() {
+	v.endTurn()
+	v.turnCtx, v.turnCancel = model.WithTurnBudget(context.Background(), v.turnTimeout)
+}
+
+// endTurn stops the model and tool calls of the current turn
+func (v *ChatView) endTurn() {
+	if v.turnCancel != nil {
+		v.turnCancel()
+	}
+	v.turnCtx, v.turnCancel = nil, nil
+}
+
+// turnContext returns the context the current turn's calls run under
+func (v *ChatView) turnContext() context.Context {
+	if v.turnCtx == nil {
+		return context.Background()
+	}
+	return v.turnCtx
+}
+
+// interjectInput interrupts the response being worked on with the text in
+// the input. Commands wait until the response is done.
+func (v *ChatView) interjectInput() tea.Cmd {
+	text := strings.TrimSpace(v.input.Value())
+	if text == "" || strings.HasPrefix(text, "/") || v.turnText == "" {
+		return nil
+	}
+	v.input.SetValue("")
+	return tea.Batch(v.history.Add(text), v.interject(text))
+}
+
+// interject stops the current turn, model call or tool calls alike, and
+// starts it again with text added to the message it responds to, so the
+// user can steer the agent while it works. Tool calls that already finished
+// are not reversed.
+func (v *ChatView) interject(text string) tea.Cmd {
+	v.AddMessage(ChatMessage{
+		Role:      "user",
+		Content:   text,
+		Timestamp: time.Now().Format("15:04:05"),
+	})
+	v.turnText = fmt.Sprintf(interjectionPrompt, v.turnText, text)
+	v.cacheQuestion = ""
+	v.followUps = nil
+	return tea.Batch(
+		toastCmd("Interrupted: starting over with your update", ToastInfo),
+		v.request(v.turnText, v.turnAttachments, v.turnOptions),
+	)
+}
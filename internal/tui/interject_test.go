@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatView_InterjectionRestartsTheTurn(t *testing.T) {
+	m := &toolLoopModel{responses: []*model.Response{{Content: "Last week you wrote two notes."}}}
+	chatView := newToolLoopView(m, 3)
+
+	chatView.SetInput("search my notes")
+	_, first := chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.True(t, chatView.waitingForResponse)
+	interrupted, staleID := chatView.turnContext(), chatView.requestID
+
+	chatView.SetInput("/undo")
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd, "Commands wait for the response")
+
+	chatView.SetInput("actually, only last week")
+	_, steer := chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Error(t, interrupted.Err(), "The interrupted turn's calls are cancelled")
+	assert.Empty(t, chatView.GetInput())
+
+	settle(t, chatView, steer)
+	require.Len(t, m.received, 1)
+	prompt := m.received[0][len(m.received[0])-1]
+	assert.Equal(t, "search my notes\n\nThe user interrupted to add: actually, only last week", prompt.Content)
+	assert.Equal(t, []string{"search my notes", "actually, only last week", "Last week you wrote two notes."}, contents(chatView))
+
+	// What the interrupted turn was doing arrives too late to be shown
+	settle(t, chatView, first)
+	chatView.Update(ToolExecutedUnifiedMsg{ToolName: "search", Result: "12 notes", Success: true, RequestID: staleID})
+	assert.Equal(t, []string{"search my notes", "actually, only last week", "Last week you wrote two notes."}, contents(chatView))
+	assert.False(t, chatView.waitingForResponse)
+}
+
+// stalledChatModel never answers, returning only once the request is
+// cancelled
+type stalledChatModel struct {
+	MockModel
+}
+
+func (m *stalledChatModel) ChatWithTools(ctx context.Context, messages []model.Message, tools []model.ToolDefinition, opts model.GenerateOptions) (*model.Response, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestChatView_TurnStopsWhenOutOfTime(t *testing.T) {
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), &stalledChatModel{}, &MockAgentForChat{})
+	chatView.SetTurnTimeout(20 * time.Millisecond)
+	chatView.ClearMessages()
+
+	chatView.SetInput("search my notes")
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	settle(t, chatView, cmd)
+	assert.False(t, chatView.waitingForResponse)
+	assert.Contains(t, lastContent(chatView), "agent.turn_timeout", "The chat says the request ran out of time")
+}
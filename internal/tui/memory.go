@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+const (
+	// memoryListLimit caps how many memories /memories lists
+	memoryListLimit = 30
+
+	// recallTimeout bounds recalling memories before a request, so a slow
+	// embedding model doesn't hold up the response
+	recallTimeout = 10 * time.Second
+
+	// maxTopicWords is the longest "topic:" prefix /remember treats as a topic
+	maxTopicWords = 3
+)
+
+// MemoryStore keeps facts across conversations
+type MemoryStore interface {
+	Remember(ctx context.Context, topic, content string, importance int) (*storage.Memory, error)
+	Recall(ctx context.Context, query string, limit int) ([]*storage.RecalledMemory, error)
+	List(topic string, limit int) ([]*storage.Memory, error)
+	Forget(id int64) error
+}
+
+// SetMemoryStore enables /remember, /memories, and /forget. When recallLimit
+// is positive, up to that many memories related to each message are sent
+// with it.
+func (v *ChatView) SetMemoryStore(store MemoryStore, recallLimit int) {
+	v.memory = store
+	v.recallLimit = recallLimit
+}
+
+// remember handles /remember [topic:] <fact>
+func (v *ChatView) remember(text string) tea.Cmd {
+	if v.memory == nil {
+		return toastCmd("Memory is unavailable", ToastWarning)
+	}
+	topic, content := splitTopic(text)
+	if content == "" {
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   "Usage: /remember [topic:] <fact>",
+			Timestamp: time.Now().Format("15:04:05"),
+		})
+		return nil
+	}
+	memory, err := v.memory.Remember(context.Background(), topic, content, 0)
+	if err != nil {
+		return toastCmd("Failed to remember: "+err.Error(), ToastError)
+	}
+	return toastCmd(fmt.Sprintf("Remembered #%d under %s", memory.ID, memory.Topic), ToastSuccess)
+}
+
+// listMemories handles /memories [topic]
+func (v *ChatView) listMemories(topic string) tea.Cmd {
+	if v.memory == nil {
+		return toastCmd("Memory is unavailable", ToastWarning)
+	}
+	memories, err := v.memory.List(topic, memoryListLimit)
+	if err != nil {
+		return toastCmd("Failed to load memories: "+err.Error(), ToastError)
+	}
+
+	content := "Nothing remembered yet. Use /remember [topic:] <fact>."
+	if topic != "" {
+		content = "Nothing remembered about " + storage.NormalizeTopic(topic) + "."
+	}
+	if len(memories) > 0 {
+		lines := []string{"Memories (most important first):"}
+		for _, memory := range memories {
+			lines = append(lines, fmt.Sprintf("#%d [%s] %s (importance %d)", memory.ID, memory.Topic, memory.Content, memory.Importance))
+		}
+		content = strings.Join(lines, "\n")
+	}
+	v.AddMessage(ChatMessage{
+		Role:      "assistant",
+		Content:   content,
+		Timestamp: time.Now().Format("15:04:05"),
+		Transient: true,
+	})
+	return nil
+}
+
+// forget handles /forget <id>
+func (v *ChatView) forget(arg string) tea.Cmd {
+	if v.memory == nil {
+		return toastCmd("Memory is unavailable", ToastWarning)
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+	if err != nil {
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   "Usage: /forget <id> (see /memories for IDs)",
+			Timestamp: time.Now().Format("15:04:05"),
+		})
+		return nil
+	}
+	if err := v.memory.Forget(id); err != nil {
+		return toastCmd("Failed to forget: "+err.Error(), ToastError)
+	}
+	return toastCmd(fmt.Sprintf("Forgot #%d", id), ToastSuccess)
+}
+
+// splitTopic separates a leading "topic:" of a few words from a fact
+func splitTopic(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	before, after, found := strings.Cut(text, ": ")
+	if !found || len(strings.Fields(before)) == 0 || len(strings.Fields(before)) > maxTopicWords {
+		return "", text
+	}
+	return before, strings.TrimSpace(after)
+}
+
+// memoryContext recalls up to limit memories related to text and describes
+// them for the model, or returns "" when none are related
+func memoryContext(store MemoryStore, text string, limit int) string {
+	if store == nil || limit <= 0 {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), recallTimeout)
+	defer cancel()
+	recalled, err := store.Recall(ctx, text, limit)
+	if err != nil || len(recalled) == 0 {
+		return ""
+	}
+	lines := []string{"Facts you remember that may be relevant:"}
+	for _, r := range recalled {
+		lines = append(lines, fmt.Sprintf("- [%s] %s", r.Memory.Topic, r.Memory.Content))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withSystemContext adds context to the system message at the start of
+// messages, adding one if there is none
+func withSystemContext(messages []model.Message, context string) []model.Message {
+	if context == "" {
+		return messages
+	}
+	if len(messages) > 0 && messages[0].Role == "system" {
+		updated := append([]model.Message(nil), messages...)
+		updated[0].Content = context + "\n\n" + updated[0].Content
+		return updated
+	}
+	return append([]model.Message{{Role: "system", Content: context}}, messages...)
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMemoryStore is a MemoryStore over a test database that recalls by shared words
+type testMemoryStore struct {
+	store *storage.ConversationStore
+}
+
+func (m *testMemoryStore) Remember(ctx context.Context, topic, content string, importance int) (*storage.Memory, error) {
+	memory := &storage.Memory{Topic: topic, Content: content, Importance: importance}
+	return memory, m.store.SaveMemory(memory)
+}
+
+func (m *testMemoryStore) Recall(ctx context.Context, query string, limit int) ([]*storage.RecalledMemory, error) {
+	return m.store.MemoryManager().RecallText(query, limit)
+}
+
+func (m *testMemoryStore) List(topic string, limit int) ([]*storage.Memory, error) {
+	return m.store.ListMemories(storage.MemoryFilter{Topic: topic, Limit: limit})
+}
+
+func (m *testMemoryStore) Forget(id int64) error {
+	return m.store.DeleteMemory(id)
+}
+
+func TestSplitTopic(t *testing.T) {
+	topic, content := splitTopic("Home office: the printer is on the second floor")
+	assert.Equal(t, "Home office", topic)
+	assert.Equal(t, "the printer is on the second floor", content)
+
+	topic, content = splitTopic("The standup moved to 10: 30 from now on")
+	assert.Empty(t, topic, "Long prefixes are part of the fact")
+	assert.Equal(t, "The standup moved to 10: 30 from now on", content)
+
+	topic, content = splitTopic("Prefers tabs")
+	assert.Empty(t, topic)
+	assert.Equal(t, "Prefers tabs", content)
+}
+
+func TestChatView_RememberListAndForget(t *testing.T) {
+	memory := &testMemoryStore{store: newTestConversationStore(t)}
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+
+	cmd := chatView.handleCommand("/remember prefers tabs")
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Memory is unavailable", cmd().(ToastMsg).Text)
+
+	chatView.SetMemoryStore(memory, 3)
+	cmd = chatView.handleCommand("/remember Editor: prefers tabs over spaces")
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Remembered #1 under editor", cmd().(ToastMsg).Text)
+
+	assert.Nil(t, chatView.handleCommand("/memories"))
+	listed := chatView.messages[len(chatView.messages)-1]
+	assert.True(t, listed.Transient)
+	assert.Contains(t, listed.Content, "#1 [editor] prefers tabs over spaces (importance 5)")
+
+	chatView.handleCommand("/memories travel")
+	assert.Equal(t, "Nothing remembered about travel.", chatView.messages[len(chatView.messages)-1].Content)
+
+	assert.Nil(t, chatView.handleCommand("/forget x"))
+	assert.Contains(t, chatView.messages[len(chatView.messages)-1].Content, "Usage: /forget <id>")
+	cmd = chatView.handleCommand("/forget #1")
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Forgot #1", cmd().(ToastMsg).Text)
+	memories, err := memory.List("", 0)
+	require.NoError(t, err)
+	assert.Empty(t, memories)
+}
+
+func TestMemoryContext(t *testing.T) {
+	memory := &testMemoryStore{store: newTestConversationStore(t)}
+	_, err := memory.Remember(context.Background(), "editor", "Prefers tabs over spaces", 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Facts you remember that may be relevant:\n- [editor] Prefers tabs over spaces",
+		memoryContext(memory, "Should I use tabs?", 5))
+	assert.Empty(t, memoryContext(memory, "What is the weather?", 5))
+	assert.Empty(t, memoryContext(memory, "Should I use tabs?", 0), "Recall is off")
+	assert.Empty(t, memoryContext(nil, "Should I use tabs?", 5))
+}
+
+func TestWithSystemContext(t *testing.T) {
+	user := model.Message{Role: "user", Content: "hi"}
+	assert.Equal(t, []model.Message{user}, withSystemContext([]model.Message{user}, ""))
+	assert.Equal(t, []model.Message{{Role: "system", Content: "facts"}, user},
+		withSystemContext([]model.Message{user}, "facts"))
+
+	original := []model.Message{{Role: "system", Content: "summary"}, user}
+	updated := withSystemContext(original, "facts")
+	assert.Equal(t, "facts\n\nsummary", updated[0].Content)
+	assert.Equal(t, "summary", original[0].Content, "The original messages are left alone")
+}
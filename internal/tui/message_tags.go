@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+const (
+	// pinnedListLimit caps how many pinned messages /pinned lists
+	pinnedListLimit = 20
+
+	// pinnedContextLimit caps how many pinned messages are sent with each request
+	pinnedContextLimit = 10
+
+	// pinnedContentLength truncates each pinned message listed or sent as context
+	pinnedContentLength = 300
+)
+
+// SetPinnedContext makes every request include the pinned messages, so what
+// the user pinned stays in context across conversations
+func (v *ChatView) SetPinnedContext(enabled bool) {
+	v.pinnedContext = enabled
+}
+
+// lastSavedMessage returns the index of the most recent saved message, or -1
+func (v *ChatView) lastSavedMessage() int {
+	for i := len(v.messages) - 1; i >= 0; i-- {
+		if v.messages[i].storedID != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// markMessage handles /tag, /untag, /pin, and /unpin, which apply to the most
+// recent saved message
+func (v *ChatView) markMessage(command string, args []string) tea.Cmd {
+	if v.log == nil {
+		return toastCmd("Conversation history is unavailable", ToastWarning)
+	}
+	tag := strings.Join(args, " ")
+	if (command == "/tag" || command == "/untag") && storage.NormalizeTag(tag) == "" {
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   fmt.Sprintf("Usage: %s <tag>", command),
+			Timestamp: time.Now().Format("15:04:05"),
+		})
+		return nil
+	}
+	index := v.lastSavedMessage()
+	if index < 0 {
+		return toastCmd("No saved message to "+strings.TrimPrefix(command, "/")+" yet", ToastInfo)
+	}
+
+	msg := &v.messages[index]
+	var err error
+	var toast string
+	switch command {
+	case "/tag":
+		if msg.Tags, err = v.log.store.TagMessage(msg.storedID, tag); err == nil {
+			toast = "Tagged #" + storage.NormalizeTag(tag)
+		}
+	case "/untag":
+		if msg.Tags, err = v.log.store.UntagMessage(msg.storedID, tag); err == nil {
+			toast = "Removed #" + storage.NormalizeTag(tag)
+		}
+	case "/pin", "/unpin":
+		pinned := command == "/pin"
+		if err = v.log.store.SetMessagePinned(msg.storedID, pinned); err == nil {
+			msg.Pinned = pinned
+			toast = "Unpinned message"
+			if pinned {
+				toast = "Pinned message"
+			}
+		}
+	}
+	if err != nil {
+		return toastCmd("Failed to update message: "+err.Error(), ToastError)
+	}
+	v.refreshMessages()
+	return toastCmd(toast, ToastSuccess)
+}
+
+// listPinned shows the pinned messages from every conversation
+func (v *ChatView) listPinned() tea.Cmd {
+	if v.log == nil {
+		return toastCmd("Conversation history is unavailable", ToastWarning)
+	}
+	pinned, err := v.log.store.PinnedMessages(pinnedListLimit)
+	if err != nil {
+		return toastCmd("Failed to load pinned messages: "+err.Error(), ToastError)
+	}
+
+	content := "No pinned messages. Use /pin to pin the latest message."
+	if len(pinned) > 0 {
+		lines := []string{"Pinned messages:"}
+		for i, msg := range pinned {
+			lines = append(lines, fmt.Sprintf("%d. (%s) %s: %s",
+				i+1, msg.Timestamp.Local().Format("2006-01-02 15:04"), msg.Role, describePinned(msg)))
+		}
+		content = strings.Join(lines, "\n")
+	}
+	v.AddMessage(ChatMessage{
+		Role:      "assistant",
+		Content:   content,
+		Timestamp: time.Now().Format("15:04:05"),
+		Transient: true,
+	})
+	return nil
+}
+
+// pinnedContextMessage describes the pinned messages for the model, or
+// returns "" when pinned context is off or nothing is pinned
+func (v *ChatView) pinnedContextMessage() string {
+	if !v.pinnedContext || v.log == nil {
+		return ""
+	}
+	pinned, err := v.log.store.PinnedMessages(pinnedContextLimit)
+	if err != nil || len(pinned) == 0 {
+		return ""
+	}
+	lines := []string{"Messages the user pinned as important:"}
+	for _, msg := range pinned {
+		lines = append(lines, fmt.Sprintf("- %s: %s", msg.Role, describePinned(msg)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describePinned shortens a pinned message to one line with its tags
+func describePinned(msg *storage.Message) string {
+	content := []rune(strings.Join(strings.Fields(msg.Content), " "))
+	text := string(content)
+	if len(content) > pinnedContentLength {
+		text = string(content[:pinnedContentLength-3]) + "..."
+	}
+	if len(msg.Tags) > 0 {
+		text += " " + formatTags(msg.Tags)
+	}
+	return text
+}
+
+// formatTags writes tags as #tag words
+func formatTags(tags []string) string {
+	marked := make([]string, len(tags))
+	for i, tag := range tags {
+		marked[i] = "#" + tag
+	}
+	return strings.Join(marked, " ")
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatView_TagAndPinLatestMessage(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetConversationStore(store)
+	chatView.SetSize(100, 30)
+
+	cmd := chatView.handleCommand("/pin")
+	require.NotNil(t, cmd)
+	assert.Equal(t, "No saved message to pin yet", cmd().(ToastMsg).Text)
+
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "never deploy on fridays"})
+	cmd = chatView.handleCommand("/tag #Rules")
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Tagged #rules", cmd().(ToastMsg).Text)
+	chatView.handleCommand("/pin")
+
+	latest := chatView.messages[chatView.lastSavedMessage()]
+	assert.Equal(t, []string{"rules"}, latest.Tags)
+	assert.True(t, latest.Pinned)
+	assert.Contains(t, chatView.renderMessage(latest), "#rules")
+
+	conversations := savedConversations(t, store)
+	require.Len(t, conversations, 1)
+	saved, err := store.GetMessages(conversations[0].ID, -1, 0)
+	require.NoError(t, err)
+	require.Len(t, saved, 1, "Commands are not saved")
+	assert.Equal(t, []string{"rules"}, saved[0].Tags)
+	assert.True(t, saved[0].Pinned)
+
+	chatView.handleCommand("/pinned")
+	listed := chatView.messages[len(chatView.messages)-1]
+	assert.True(t, listed.Transient)
+	assert.Contains(t, listed.Content, "never deploy on fridays #rules")
+
+	chatView.handleCommand("/untag rules")
+	chatView.handleCommand("/unpin")
+	saved, err = store.GetMessages(conversations[0].ID, -1, 0)
+	require.NoError(t, err)
+	assert.Empty(t, saved[0].Tags)
+	assert.False(t, saved[0].Pinned)
+}
+
+func TestChatView_TagUsage(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	cmd := chatView.handleCommand("/tag important")
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Conversation history is unavailable", cmd().(ToastMsg).Text)
+
+	chatView.SetConversationStore(newTestConversationStore(t))
+	assert.Nil(t, chatView.handleCommand("/tag"))
+	assert.Equal(t, "Usage: /tag <tag>", chatView.messages[len(chatView.messages)-1].Content)
+}
+
+func TestChatView_PinnedMessagesInContext(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetConversationStore(store)
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "my name is Ada"})
+	chatView.handleCommand("/pin")
+
+	assert.Len(t, chatView.contextMessages("what's my name?"), 1, "Pinned messages are only sent when enabled")
+
+	chatView.SetPinnedContext(true)
+	messages := chatView.contextMessages("what's my name?")
+	require.Len(t, messages, 2)
+	assert.Equal(t, "system", messages[0].Role)
+	assert.Contains(t, messages[0].Content, "user: my name is Ada")
+}
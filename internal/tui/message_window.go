@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// The chat keeps the latest messageWindow messages in memory. Once
+// messageSlack more have been added, the oldest are dropped; saved ones are
+// loaded again, earlierMessagesPage at a time, when scrolling up to them.
+const (
+	messageWindow       = 300
+	messageSlack        = 50
+	earlierMessagesPage = 100
+)
+
+// earlierMessagesMsg carries the saved messages before the one with ID before
+type earlierMessagesMsg struct {
+	before      int64
+	messages    []*storage.Message
+	attachments []*storage.Attachment
+	remaining   int // Saved messages before these
+	err         error
+}
+
+// trimMessages drops the oldest messages once the chat holds more than the
+// window and its slack. The message being edited is kept.
+func (v *ChatView) trimMessages() {
+	if len(v.messages) <= messageWindow+messageSlack {
+		return
+	}
+	n := len(v.messages) - messageWindow
+	if v.editing >= 0 {
+		n = min(n, v.editing)
+	}
+	v.dropMessages(n)
+}
+
+// dropMessages drops the oldest n messages
+func (v *ChatView) dropMessages(n int) {
+	if n <= 0 {
+		return
+	}
+	// Copy the rest, so the dropped messages can be freed
+	v.messages = append([]ChatMessage(nil), v.messages[n:]...)
+	v.hidden += n
+	v.shiftMessages(-n)
+}
+
+// shiftMessages moves what refers to messages by index by delta, after
+// messages were dropped from or added to the start of the chat. Checkpoints
+// of dropped messages are forgotten, so they can't be undone.
+func (v *ChatView) shiftMessages(delta int) {
+	shift := func(index int) int {
+		if index < 0 || index+delta < 0 {
+			return -1
+		}
+		return index + delta
+	}
+	v.selected = shift(v.selected)
+	v.editing = shift(v.editing)
+
+	expanded := make(map[int]bool, len(v.expanded))
+	for index, open := range v.expanded {
+		if index = shift(index); index >= 0 && open {
+			expanded[index] = true
+		}
+	}
+	v.expanded = expanded
+
+	turns := v.turns[:0]
+	for _, turn := range v.turns {
+		if turn.index = shift(turn.index); turn.index >= 0 {
+			turns = append(turns, turn)
+		}
+	}
+	v.turns = turns
+
+	if v.log != nil {
+		v.log.skip = max(v.log.skip+delta, 0)
+	}
+}
+
+// renderHiddenMessages notes the messages dropped from the start of the chat
+func (v *ChatView) renderHiddenMessages() string {
+	noun := "messages"
+	if v.hidden == 1 {
+		noun = "message"
+	}
+	if v.canLoadEarlier() {
+		return v.styles.DimmedStyle.Render(fmt.Sprintf("↑ %d earlier %s; scroll up to load them", v.hidden, noun))
+	}
+	return v.styles.DimmedStyle.Render(fmt.Sprintf("%d earlier %s not shown", v.hidden, noun))
+}
+
+// canLoadEarlier reports whether messages dropped from the chat can be loaded
+// again, which needs them to have been saved
+func (v *ChatView) canLoadEarlier() bool {
+	return v.hidden > 0 && v.log != nil && v.log.id != "" && v.firstSavedMessage() >= 0
+}
+
+// firstSavedMessage returns the index of the oldest saved message, or -1
+func (v *ChatView) firstSavedMessage() int {
+	for i, msg := range v.messages {
+		if msg.storedID != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadEarlier returns a command that loads the saved messages before those
+// shown, when the chat is scrolled to the top and some were dropped
+func (v *ChatView) loadEarlier() tea.Cmd {
+	if v.loadingEarlier || !v.viewport.AtTop() || !v.canLoadEarlier() {
+		return nil
+	}
+	v.loadingEarlier = true
+	store, id := v.log.store, v.log.id
+	before := v.messages[v.firstSavedMessage()].storedID
+	return func() tea.Msg {
+		thread, err := store.GetThread(id)
+		if err != nil {
+			return earlierMessagesMsg{before: before, err: err}
+		}
+		end := len(thread)
+		for i, msg := range thread {
+			if msg.ID == before {
+				end = i
+				break
+			}
+		}
+		start := max(end-earlierMessagesPage, 0)
+		messages := thread[start:end]
+		var attachments []*storage.Attachment
+		if len(messages) > 0 {
+			ids := make([]int64, len(messages))
+			for i, msg := range messages {
+				ids[i] = msg.ID
+			}
+			attachments, err = store.ListAttachments(storage.AttachmentFilter{MessageIDs: ids})
+		}
+		return earlierMessagesMsg{before: before, messages: messages, attachments: attachments, remaining: start, err: err}
+	}
+}
+
+// showEarlier adds loaded messages to the start of the chat, keeping what is
+// on screen in place
+func (v *ChatView) showEarlier(msg earlierMessagesMsg) tea.Cmd {
+	v.loadingEarlier = false
+	if msg.err != nil {
+		return toastCmd("Failed to load earlier messages: "+msg.err.Error(), ToastError)
+	}
+	// The chat was cleared or changed conversation while loading
+	first := v.firstSavedMessage()
+	if first < 0 || v.messages[first].storedID != msg.before {
+		return nil
+	}
+
+	earlier := fromStorageMessages(msg.messages, msg.attachments)
+	v.messages = append(earlier, v.messages...)
+	v.hidden = msg.remaining
+	v.shiftMessages(len(earlier))
+
+	lines := v.viewport.TotalLineCount()
+	offset := v.viewport.YOffset
+	v.viewport.SetContent(v.renderMessages())
+	v.viewport.SetYOffset(offset + v.viewport.TotalLineCount() - lines)
+	return nil
+}
+
+// fromStorageMessages converts stored messages and their attachments back
+// into chat messages
+func fromStorageMessages(stored []*storage.Message, attachments []*storage.Attachment) []ChatMessage {
+	byMessage := make(map[int64][]Attachment)
+	for _, attachment := range attachments {
+		byMessage[attachment.MessageID] = append(byMessage[attachment.MessageID], fromStorageAttachment(attachment))
+	}
+	messages := make([]ChatMessage, len(stored))
+	for i, msg := range stored {
+		messages[i] = fromStorageMessage(msg)
+		messages[i].Attachments = byMessage[msg.ID]
+	}
+	return messages
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatView_DropsOldestMessages(t *testing.T) {
+	view := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	view.SetSize(80, 12)
+	view.ClearMessages()
+	for i := 0; i < messageWindow+messageSlack; i++ {
+		view.AddMessage(ChatMessage{Role: "user", Content: fmt.Sprintf("message %d", i)})
+	}
+	require.Len(t, view.messages, messageWindow+messageSlack, "Messages are dropped only past the slack")
+	view.selectMessage(messageWindow + messageSlack - 1)
+	view.checkpoint(10)
+	view.checkpoint(messageWindow + 10)
+
+	view.AddMessage(ChatMessage{Role: "user", Content: "latest"})
+	require.Len(t, view.messages, messageWindow)
+	assert.Equal(t, messageSlack+1, view.hidden)
+	assert.Equal(t, fmt.Sprintf("message %d", messageSlack+1), view.messages[0].Content)
+
+	selected, ok := view.SelectedMessage()
+	require.True(t, ok, "The selection follows its message")
+	assert.Equal(t, fmt.Sprintf("message %d", messageWindow+messageSlack-1), selected.Content)
+	require.Len(t, view.turns, 1, "Turns whose messages were dropped can't be undone")
+	assert.Equal(t, messageWindow+10-messageSlack-1, view.turns[0].index)
+
+	assert.Contains(t, view.renderMessages(), fmt.Sprintf("%d earlier messages not shown", messageSlack+1))
+}
+
+func TestChatView_LoadsEarlierMessagesWhenScrolledToTop(t *testing.T) {
+	store := newTestConversationStore(t)
+	view := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	view.SetSize(80, 12)
+	view.SetConversationStore(store)
+	total := messageWindow + messageSlack + earlierMessagesPage + 10
+	for i := 0; i < total; i++ {
+		view.AddMessage(ChatMessage{Role: "user", Content: fmt.Sprintf("message %d", i)})
+	}
+	require.NoError(t, view.persistError())
+	shown := len(view.messages)
+	require.Greater(t, view.hidden, earlierMessagesPage)
+	first := view.messages[0].Content
+	assert.Contains(t, view.renderMessages(), "scroll up to load them")
+
+	assert.Nil(t, view.loadEarlier(), "Messages are loaded only at the top")
+	view.viewport.GotoTop()
+	_, cmd := view.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	msg := findMsg[earlierMessagesMsg](t, cmd)
+	require.NoError(t, msg.err)
+	view.Update(msg)
+
+	require.Len(t, view.messages, shown+earlierMessagesPage)
+	assert.Equal(t, fmt.Sprintf("message %d", total-shown-earlierMessagesPage), view.messages[0].Content)
+	assert.Equal(t, first, view.messages[earlierMessagesPage].Content)
+	assert.Equal(t, total-shown-earlierMessagesPage, view.hidden, "Only saved messages are counted once loaded, not the welcome")
+	assert.False(t, view.viewport.AtTop(), "The messages shown before stay on screen")
+
+	// Loading the rest finds the start of the conversation
+	view.viewport.GotoTop()
+	view.Update(findMsg[earlierMessagesMsg](t, view.loadEarlier()))
+	assert.Equal(t, "message 0", view.messages[0].Content)
+	assert.Zero(t, view.hidden)
+	assert.NotContains(t, view.renderMessages(), "earlier message")
+}
+
+// findMsg runs cmd, and the commands of any batch it returns, and returns the
+// first message of type T
+func findMsg[T any](t *testing.T, cmd tea.Cmd) T {
+	t.Helper()
+	var found []T
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		switch msg := cmd().(type) {
+		case T:
+			found = append(found, msg)
+		case tea.BatchMsg:
+			for _, cmd := range msg {
+				run(cmd)
+			}
+		}
+	}
+	run(cmd)
+	require.NotEmpty(t, found)
+	return found[0]
+}
@@ -37,6 +37,20 @@ type ToolUpdateMsg struct {
 	Removed    []string // names of removed tools
 }
 
+// LogMsg carries an agent log line to the activity pane
+type LogMsg struct {
+	Text string
+}
+
+// ConfigReloadedMsg reports the settings the agent applied after its config
+// files changed, and those that take effect on restart
+type ConfigReloadedMsg struct {
+	Changes     []string
+	Restart     []string
+	Temperature float64
+	Error       string // Why the changed files weren't applied
+}
+
 // RefreshDataMsg signals views to refresh their data
 type RefreshDataMsg struct {
 	ViewType string // "servers", "tools", or "all"
@@ -63,6 +77,7 @@ type ToolCallDetectedMsg struct {
 	UserMessage         string              // Original user message
 	ConversationHistory []model.Message     // Conversation history up to this point
 	Tools               []model.ToolDefinition // Available tools
+	Approved            bool                // The user approved the calls in a plan
 }
 
 // ToolExecutionResultMsg removed - replaced with ToolExecutedUnifiedMsg
@@ -83,8 +98,12 @@ type MCPToolExecutedMsg struct {
 // ToolExecutedUnifiedMsg represents a unified tool execution result
 type ToolExecutedUnifiedMsg struct {
 	ToolName string
-	Result   string // Already processed natural language result
-	Success  bool
+	Result    string // Already processed natural language result
+	Success   bool
+	FollowUps []model.FollowUp // Suggested next prompts, offered as quick actions
+	RequestID string           // Request whose tool calls these are, if the model made them
+	Feedback  []model.Message  // The calls and their results, to send back to the model
+	FullOutput string          // The results before the model summarized them; empty when none were
 }
 
 // ServerSelectedMsg represents a server being selected in the ServerView
@@ -94,13 +113,18 @@ type ServerSelectedMsg struct {
 
 // GenerateResponse sends a message to the model and returns a command
 func GenerateResponse(m model.Model, message, id string) tea.Cmd {
+	return GenerateResponseWithOptions(m, message, id, model.GenerateOptions{
+		Temperature: 0.7,
+		MaxTokens:   2048,
+	})
+}
+
+// GenerateResponseWithOptions sends a message to the model with the given generation parameters
+func GenerateResponseWithOptions(m model.Model, message, id string, options model.GenerateOptions) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
 		
-		response, err := m.Generate(ctx, message, model.GenerateOptions{
-			Temperature: 0.7,
-			MaxTokens:   2048,
-		})
+		response, err := m.Generate(ctx, message, options)
 		
 		return ModelResponseMsg{
 			Response: response,
@@ -110,6 +134,21 @@ func GenerateResponse(m model.Model, message, id string) tea.Cmd {
 	}
 }
 
+// ChatResponseWithOptions sends a conversation to the model with the given generation parameters
+func ChatResponseWithOptions(m model.Model, messages []model.Message, id string, options model.GenerateOptions) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		response, err := m.Chat(ctx, messages, options)
+
+		return ModelResponseMsg{
+			Response: response,
+			Error:    err,
+			ID:       id,
+		}
+	}
+}
+
 // GenerateResponseWithTools sends a message to the model with tool support
 func GenerateResponseWithTools(m model.Model, message string, tools []model.ToolDefinition, id string) tea.Cmd {
 	return func() tea.Msg {
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// defaultMode is the session mode used when none is configured
+const defaultMode = "chat"
+
+// SessionMode is a way of working the conversation can switch to with
+// /mode, which shapes the prompt, the tools offered, and the temperature
+type SessionMode struct {
+	Name        string
+	Description string
+	Temperature *float64 // Replaces the configured temperature; nil keeps it
+}
+
+// SessionModes are the modes /mode can switch between
+var SessionModes = []SessionMode{
+	{Name: "chat", Description: "General help, with every tool"},
+	{Name: "analysis", Description: "Data-driven insights, with tools that search and analyze", Temperature: floatPtr(0.3)},
+	{Name: "automation", Description: "Getting tasks done, with tools that create, update, and transform", Temperature: floatPtr(0.2)},
+}
+
+// SessionModer is implemented by agents that shape requests to the
+// conversation's session mode
+type SessionModer interface {
+	// ModePrompt returns instructions for the mode, or "" for none
+	ModePrompt(mode string) string
+	// ModeTools returns the tools offered to the model in the mode
+	ModeTools(mode string, tools []model.ToolDefinition) []model.ToolDefinition
+}
+
+// findMode returns the session mode named name, or nil
+func findMode(name string) *SessionMode {
+	for i := range SessionModes {
+		if strings.EqualFold(SessionModes[i].Name, name) {
+			return &SessionModes[i]
+		}
+	}
+	return nil
+}
+
+// floatPtr returns a pointer to f, for optional settings
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+// SetSessionMode sets the mode new conversations start in and switches the
+// current one to it
+func (v *ChatView) SetSessionMode(name string) {
+	if mode := findMode(name); mode != nil {
+		v.defaultMode = mode.Name
+		v.setMode(mode.Name)
+	}
+}
+
+// Mode returns the name of the conversation's session mode
+func (v *ChatView) Mode() string {
+	if v.mode == "" {
+		return defaultMode
+	}
+	return v.mode
+}
+
+// setMode switches the conversation to the mode named name and saves it
+// with the conversation
+func (v *ChatView) setMode(name string) {
+	v.mode = name
+	if v.conversationContext != nil {
+		v.conversationContext.SessionType = v.Mode()
+	}
+	if v.log != nil {
+		v.log.setMode(name)
+	}
+}
+
+// switchMode handles /mode: without a name it lists the modes, and a name
+// switches the conversation to that mode
+func (v *ChatView) switchMode(name string) tea.Cmd {
+	if name == "" {
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   v.describeModes(),
+			Timestamp: time.Now().Format("15:04:05"),
+			Transient: true,
+		})
+		return nil
+	}
+
+	mode := findMode(name)
+	if mode == nil {
+		return toastCmd(fmt.Sprintf("Unknown mode %s. Type /mode to list them.", name), ToastWarning)
+	}
+	v.setMode(mode.Name)
+	return toastCmd("Mode: "+mode.Name, ToastSuccess)
+}
+
+// describeModes lists the session modes and marks the current one
+func (v *ChatView) describeModes() string {
+	lines := []string{"Modes (/mode <name> to switch):"}
+	for _, mode := range SessionModes {
+		marker := "  "
+		if mode.Name == v.Mode() {
+			marker = "* "
+		}
+		lines = append(lines, marker+mode.Name+" - "+mode.Description)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// modePrompt returns the agent's instructions for the session mode, or ""
+// when it has none
+func (v *ChatView) modePrompt() string {
+	moder, ok := v.agent.(SessionModer)
+	if !ok {
+		return ""
+	}
+	return moder.ModePrompt(v.Mode())
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// modeAgent offers only the search tool in analysis mode
+type modeAgent struct {
+	MockAgentForChat
+}
+
+func (a *modeAgent) ModePrompt(mode string) string {
+	if mode == "chat" {
+		return ""
+	}
+	return "Work in " + mode + " mode."
+}
+
+func (a *modeAgent) ModeTools(mode string, tools []model.ToolDefinition) []model.ToolDefinition {
+	if mode == "analysis" {
+		return tools[:1]
+	}
+	return tools
+}
+
+func TestChatView_ModeShapesRequests(t *testing.T) {
+	m := &personaModel{toolLoopModel: toolLoopModel{responses: []*model.Response{{Content: "Signups fell on Monday."}}}}
+	agent := &modeAgent{MockAgentForChat{tools: []Tool{{Name: "search"}, {Name: "write_file"}}}}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), m, agent)
+	chatView.ClearMessages()
+	chatView.SetGenerateOptions(model.GenerateOptions{Temperature: 0.7})
+
+	typeAndSettle(t, chatView, "/mode analysis")
+	assert.Equal(t, "analysis", chatView.Mode())
+	assert.Equal(t, "analysis", chatView.conversationContext.SessionType)
+
+	typeAndSettle(t, chatView, "why did signups drop?")
+	require.Len(t, m.received, 1)
+	assert.Equal(t, model.Message{Role: "system", Content: "Work in analysis mode."}, m.received[0][0])
+	assert.Equal(t, 0.3, m.options[0].Temperature)
+	require.Len(t, m.tools[0], 1)
+	assert.Equal(t, "search", m.tools[0][0].Name)
+
+	typeAndSettle(t, chatView, "/mode chat")
+	typeAndSettle(t, chatView, "thanks")
+	require.Len(t, m.received, 2)
+	assert.Equal(t, "user", m.received[1][0].Role, "Chat mode sends no instructions")
+	assert.Equal(t, 0.7, m.options[1].Temperature)
+	assert.Len(t, m.tools[1], 2)
+}
+
+func TestChatView_ModeCommand(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	assert.Nil(t, chatView.handleCommand("/mode"))
+	assert.Equal(t, "Modes (/mode <name> to switch):\n* chat - General help, with every tool\n  analysis - Data-driven insights, with tools that search and analyze\n  automation - Getting tasks done, with tools that create, update, and transform", lastContent(chatView))
+
+	assert.Equal(t, "Unknown mode research. Type /mode to list them.", chatView.handleCommand("/mode research")().(ToastMsg).Text)
+	assert.Equal(t, "Mode: automation", chatView.handleCommand("/mode Automation")().(ToastMsg).Text)
+	assert.Equal(t, "automation", chatView.Mode())
+
+	chatView.SetSessionMode("analysis")
+	chatView.handleCommand("/mode chat")
+	chatView.ClearMessages()
+	assert.Equal(t, "analysis", chatView.Mode(), "A cleared chat starts in the configured mode")
+}
+
+func TestChatView_ModeIsSavedWithTheConversation(t *testing.T) {
+	store := newTestConversationStore(t)
+	first := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	first.SetConversationStore(store)
+	first.handleCommand("/mode analysis")
+	first.AddMessage(ChatMessage{Role: "user", Content: "why did signups drop?"})
+	first.handleCommand("/mode automation")
+	require.NoError(t, first.persistError())
+
+	conversations := savedConversations(t, store)
+	require.Len(t, conversations, 1)
+	assert.Equal(t, "automation", conversations[0].Mode)
+
+	second := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	second.SetConversationStore(store)
+	second.restoreConversation(second.log.latest()().(conversationRestoredMsg))
+	assert.Equal(t, "automation", second.Mode())
+}
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// Persona is a system prompt profile the chat can switch to with /persona
+type Persona struct {
+	Name         string
+	SystemPrompt string
+	Tools        []string // Tools offered to the model first and recommended to it
+	Temperature  *float64 // Replaces the configured temperature; nil keeps it
+}
+
+// SetPersonas sets the personas /persona can switch between and activates
+// the one named active, if any
+func (v *ChatView) SetPersonas(personas []Persona, active string) {
+	v.personas = personas
+	v.persona = v.findPersona(active)
+}
+
+// Persona returns the name of the active persona, or "" when none is active
+func (v *ChatView) Persona() string {
+	if v.persona == nil {
+		return ""
+	}
+	return v.persona.Name
+}
+
+// findPersona returns the persona named name, or nil
+func (v *ChatView) findPersona(name string) *Persona {
+	for i := range v.personas {
+		if strings.EqualFold(v.personas[i].Name, name) {
+			return &v.personas[i]
+		}
+	}
+	return nil
+}
+
+// switchPersona handles /persona: without a name it lists the personas,
+// "off" goes back to none, and a name makes that persona active
+func (v *ChatView) switchPersona(name string) tea.Cmd {
+	switch strings.ToLower(name) {
+	case "":
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   v.describePersonas(),
+			Timestamp: time.Now().Format("15:04:05"),
+			Transient: true,
+		})
+		return nil
+	case "off", "none":
+		v.persona = nil
+		return toastCmd("No persona", ToastInfo)
+	}
+
+	persona := v.findPersona(name)
+	if persona == nil {
+		return toastCmd(fmt.Sprintf("Unknown persona %s. Type /persona to list them.", name), ToastWarning)
+	}
+	v.persona = persona
+	return toastCmd("Persona: "+persona.Name, ToastSuccess)
+}
+
+// describePersonas lists the personas and marks the active one
+func (v *ChatView) describePersonas() string {
+	if len(v.personas) == 0 {
+		return "No personas are configured. Add them under agent.personas in the config file."
+	}
+	lines := []string{"Personas (/persona <name> to switch, /persona off for none):"}
+	for _, persona := range v.personas {
+		marker := "  "
+		if v.persona != nil && v.persona.Name == persona.Name {
+			marker = "* "
+		}
+		line := marker + persona.Name
+		if prompt := firstLine(persona.SystemPrompt); prompt != "" {
+			line += " - " + prompt
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// personaPrompt returns the active persona's system prompt, with the tools it
+// prefers, or "" when no persona is active
+func (v *ChatView) personaPrompt() string {
+	if v.persona == nil {
+		return ""
+	}
+	prompt := strings.TrimSpace(v.persona.SystemPrompt)
+	if len(v.persona.Tools) > 0 {
+		prompt = strings.TrimSpace(prompt + "\n\nPrefer these tools when they fit: " + strings.Join(v.persona.Tools, ", ") + ".")
+	}
+	return prompt
+}
+
+// requestOptions returns the generation parameters for a new request, with
+// the session mode's temperature, or the active persona's when it sets one
+func (v *ChatView) requestOptions() model.GenerateOptions {
+	options := v.options
+	if mode := findMode(v.Mode()); mode != nil && mode.Temperature != nil {
+		options.Temperature = *mode.Temperature
+	}
+	if v.persona != nil && v.persona.Temperature != nil {
+		options.Temperature = *v.persona.Temperature
+	}
+	return options
+}
+
+// preferTools moves the tools named in preferred to the front of tools,
+// in the order preferred names them
+func preferTools(tools []model.ToolDefinition, preferred []string) []model.ToolDefinition {
+	if len(preferred) == 0 {
+		return tools
+	}
+	rank := make(map[string]int, len(preferred))
+	for i, name := range preferred {
+		rank[name] = i
+	}
+	first := make([]model.ToolDefinition, len(preferred))
+	found := make([]bool, len(preferred))
+	var rest []model.ToolDefinition
+	for _, tool := range tools {
+		if i, ok := rank[tool.Name]; ok && !found[i] {
+			first[i], found[i] = tool, true
+		} else {
+			rest = append(rest, tool)
+		}
+	}
+	ordered := make([]model.ToolDefinition, 0, len(tools))
+	for i, tool := range first {
+		if found[i] {
+			ordered = append(ordered, tool)
+		}
+	}
+	return append(ordered, rest...)
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// personaModel records the tools and options each request is sent with
+type personaModel struct {
+	toolLoopModel
+	tools   [][]model.ToolDefinition
+	options []model.GenerateOptions
+}
+
+func (m *personaModel) ChatWithTools(ctx context.Context, messages []model.Message, tools []model.ToolDefinition, opts model.GenerateOptions) (*model.Response, error) {
+	m.tools = append(m.tools, tools)
+	m.options = append(m.options, opts)
+	return m.toolLoopModel.ChatWithTools(ctx, messages, tools, opts)
+}
+
+func testPersonas() []Persona {
+	precise := 0.1
+	return []Persona{
+		{Name: "coder", SystemPrompt: "You are a careful Go reviewer.\nKeep answers short.", Tools: []string{"read_file"}, Temperature: &precise},
+		{Name: "writer", SystemPrompt: "You help with prose."},
+	}
+}
+
+func TestChatView_PersonaShapesRequests(t *testing.T) {
+	m := &personaModel{toolLoopModel: toolLoopModel{responses: []*model.Response{{Content: "Looks good."}}}}
+	agent := &MockAgentForChat{tools: []Tool{{Name: "search"}, {Name: "read_file"}}}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), m, agent)
+	chatView.ClearMessages()
+	chatView.SetGenerateOptions(model.GenerateOptions{Temperature: 0.7})
+	chatView.SetPersonas(testPersonas(), "coder")
+
+	typeAndSettle(t, chatView, "review this")
+	require.Len(t, m.received, 1)
+	assert.Equal(t, model.Message{Role: "system", Content: "You are a careful Go reviewer.\nKeep answers short.\n\nPrefer these tools when they fit: read_file."}, m.received[0][0])
+	assert.Equal(t, 0.1, m.options[0].Temperature)
+	require.Len(t, m.tools[0], 2)
+	assert.Equal(t, "read_file", m.tools[0][0].Name, "The persona's tools are offered first")
+
+	typeAndSettle(t, chatView, "/persona off")
+	typeAndSettle(t, chatView, "and this?")
+	require.Len(t, m.received, 2)
+	assert.Equal(t, "user", m.received[1][0].Role, "No persona prompt without a persona")
+	assert.Equal(t, 0.7, m.options[1].Temperature)
+	assert.Equal(t, "search", m.tools[1][0].Name)
+}
+
+func TestChatView_PersonaCommand(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	assert.Equal(t, "No personas are configured. Add them under agent.personas in the config file.", chatView.describePersonas())
+
+	chatView.SetPersonas(testPersonas(), "")
+	assert.Equal(t, "", chatView.Persona())
+
+	assert.Equal(t, "Persona: writer", chatView.switchPersona("Writer")().(ToastMsg).Text)
+	assert.Equal(t, "writer", chatView.Persona())
+	assert.Equal(t, "Unknown persona pirate. Type /persona to list them.", chatView.switchPersona("pirate")().(ToastMsg).Text)
+	assert.Equal(t, "writer", chatView.Persona())
+
+	assert.Nil(t, chatView.switchPersona(""))
+	assert.Equal(t, "Personas (/persona <name> to switch, /persona off for none):\n  coder - You are a careful Go reviewer.\n* writer - You help with prose.", lastContent(chatView))
+}
+
+func TestPreferTools(t *testing.T) {
+	tools := []model.ToolDefinition{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	ordered := preferTools(tools, []string{"d", "missing", "b"})
+	names := make([]string, len(ordered))
+	for i, tool := range ordered {
+		names[i] = tool.Name
+	}
+	assert.Equal(t, []string{"d", "b", "a", "c"}, names)
+	assert.Equal(t, tools, preferTools(tools, nil))
+}
+
+func TestApplication_StatusBarShowsPersona(t *testing.T) {
+	app := NewApplication(&MockModel{})
+	app.width = 120
+	assert.NotContains(t, app.renderStatusBar(), "coder")
+
+	app.chatView.SetPersonas(testPersonas(), "coder")
+	assert.Contains(t, app.renderStatusBar(), "🎭 coder")
+}
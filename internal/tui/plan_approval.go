@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// ToolPlan is the tool calls the model wants to make, shown to the user for
+// approval before any of them run
+type ToolPlan struct {
+	Description string
+	Steps       []ToolPlanStep
+}
+
+// ToolPlanStep is one tool call in a ToolPlan
+type ToolPlanStep struct {
+	Tool        string
+	Arguments   map[string]interface{}
+	Reasoning   string // What the tool does
+	Destructive bool   // Whether the tool deletes or changes data
+}
+
+// ToolPlanner is implemented by agents that want some tool calls approved
+// before they run. ToolPlan returns nil for calls that can run straight away.
+type ToolPlanner interface {
+	ToolPlan(calls []model.ToolCall) *ToolPlan
+}
+
+// planFor returns the plan for calls if the agent wants it approved first
+func (v *ChatView) planFor(calls []model.ToolCall) *ToolPlan {
+	planner, ok := v.agent.(ToolPlanner)
+	if !ok {
+		return nil
+	}
+	return planner.ToolPlan(calls)
+}
+
+// proposePlan shows plan and holds the tool calls in msg until the user
+// approves, cancels, or revises it
+func (v *ChatView) proposePlan(plan *ToolPlan, msg ToolCallDetectedMsg) tea.Cmd {
+	v.pendingPlan = &msg
+	v.waitingForResponse = false
+	v.AddMessage(ChatMessage{
+		Role:      "assistant",
+		Content:   describePlan(plan),
+		Timestamp: time.Now().Format("15:04:05"),
+	})
+	return nil
+}
+
+// describePlan lists the steps of plan and how to answer it
+func describePlan(plan *ToolPlan) string {
+	lines := []string{fmt.Sprintf("Here's my plan (%s):", plan.Description)}
+	for i, step := range plan.Steps {
+		arguments := "{}"
+		if len(step.Arguments) > 0 {
+			data, _ := json.Marshal(step.Arguments)
+			arguments = string(data)
+		}
+		line := fmt.Sprintf("%d. %s %s", i+1, step.Tool, arguments)
+		if step.Destructive {
+			line += " ⚠ deletes or changes data"
+		}
+		lines = append(lines, line)
+		if reasoning := firstLine(step.Reasoning); reasoning != "" {
+			lines = append(lines, "   "+reasoning)
+		}
+	}
+	lines = append(lines, "Reply y to run it, n to cancel, or describe what to change.")
+	return strings.Join(lines, "\n")
+}
+
+// firstLine returns the first line of text, shortened to fit on one row
+func firstLine(text string) string {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+	if len(line) > 100 {
+		line = line[:97] + "..."
+	}
+	return line
+}
+
+// answerPlan handles what the user typed while a plan waits for approval
+func (v *ChatView) answerPlan(text string) tea.Cmd {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "y", "yes":
+		return v.approvePlan()
+	case "n", "no":
+		return v.cancelPlan()
+	}
+	return v.revisePlan(text)
+}
+
+// approvePlan runs the tool calls of the pending plan
+func (v *ChatView) approvePlan() tea.Cmd {
+	if v.pendingPlan == nil {
+		return toastCmd("No plan is waiting for approval", ToastInfo)
+	}
+	msg := *v.pendingPlan
+	msg.Approved = true
+	v.pendingPlan = nil
+	v.beginTurn()
+	return v.runToolCalls(msg)
+}
+
+// cancelPlan discards the pending plan without running any of it
+func (v *ChatView) cancelPlan() tea.Cmd {
+	if v.pendingPlan == nil {
+		return toastCmd("No plan is waiting for approval", ToastInfo)
+	}
+	v.pendingPlan = nil
+	v.AddMessage(ChatMessage{
+		Role:      "assistant",
+		Content:   "Okay, I won't run that plan.",
+		Timestamp: time.Now().Format("15:04:05"),
+	})
+	return v.summarize()
+}
+
+// revisePlan sends the user's changes to the pending plan to the model,
+// which answers with a new plan or a reply
+func (v *ChatView) revisePlan(text string) tea.Cmd {
+	msg := *v.pendingPlan
+	v.pendingPlan = nil
+	v.beginTurn()
+	v.AddMessage(ChatMessage{
+		Role:      "user",
+		Content:   text,
+		Timestamp: time.Now().Format("15:04:05"),
+	})
+
+	reply := model.FormatToolCalls(msg.ToolCalls)
+	if msg.Response != nil && strings.TrimSpace(msg.Response.Content) != "" {
+		reply = msg.Response.Content
+	}
+	messages := append(append([]model.Message{}, msg.ConversationHistory...),
+		model.Message{Role: "assistant", Content: reply},
+		model.Message{Role: "user", Content: "Change your plan before running it: " + text},
+	)
+	return tea.Batch(v.startWaiting(phaseGenerating), v.continueTurn(messages, msg.RequestID))
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// planningAgent asks for approval of every plan and records the tools it runs
+type planningAgent struct {
+	MockAgentForChat
+	ran []model.ToolCall
+}
+
+func (a *planningAgent) ToolPlan(calls []model.ToolCall) *ToolPlan {
+	plan := &ToolPlan{Description: "Single tool operation: forget"}
+	for _, call := range calls {
+		plan.Steps = append(plan.Steps, ToolPlanStep{Tool: call.Name, Arguments: call.Arguments, Reasoning: "Delete a remembered fact", Destructive: true})
+	}
+	return plan
+}
+
+func (a *planningAgent) ExecuteToolUnifiedWithContext(ctx context.Context, toolName string, params map[string]interface{}, convContext *model.ConversationContext) (string, error) {
+	a.ran = append(a.ran, model.ToolCall{Name: toolName, Arguments: params})
+	return "Forgot it", nil
+}
+
+func newPlanTestView(responses ...*model.Response) (*ChatView, *planningAgent, *toolLoopModel) {
+	m := &toolLoopModel{responses: responses}
+	agent := &planningAgent{MockAgentForChat: MockAgentForChat{tools: []Tool{{Name: "forget"}}}}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), m, agent)
+	chatView.ClearMessages()
+	return chatView, agent, m
+}
+
+func typeAndSettle(t *testing.T, v *ChatView, text string) {
+	t.Helper()
+	v.SetInput(text)
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	settle(t, v, cmd)
+}
+
+func lastContent(v *ChatView) string {
+	return v.messages[len(v.messages)-1].Content
+}
+
+func TestChatView_PlanRunsOnlyOnceApproved(t *testing.T) {
+	chatView, agent, m := newPlanTestView(
+		&model.Response{ToolCalls: []model.ToolCall{{Name: "forget", Arguments: map[string]interface{}{"id": "mem_1"}}}},
+		&model.Response{ToolCalls: []model.ToolCall{{Name: "forget", Arguments: map[string]interface{}{"id": "mem_2"}}}},
+	)
+
+	typeAndSettle(t, chatView, "forget my old address")
+	require.NotNil(t, chatView.pendingPlan)
+	assert.False(t, chatView.waitingForResponse)
+	assert.Equal(t, "Here's my plan (Single tool operation: forget):\n1. forget {\"id\":\"mem_1\"} ⚠ deletes or changes data\n   Delete a remembered fact\nReply y to run it, n to cancel, or describe what to change.", lastContent(chatView))
+	assert.Empty(t, agent.ran)
+
+	// Anything but yes or no revises the plan
+	typeAndSettle(t, chatView, "the address is mem_2")
+	require.Len(t, m.received, 2)
+	revision := m.received[1]
+	assert.Equal(t, "TOOL_CALL: forget\nARGUMENTS: {\"id\":\"mem_1\"}", revision[len(revision)-2].Content)
+	assert.Equal(t, "Change your plan before running it: the address is mem_2", revision[len(revision)-1].Content)
+	assert.Contains(t, lastContent(chatView), `forget {"id":"mem_2"}`)
+	assert.Empty(t, agent.ran)
+
+	typeAndSettle(t, chatView, "y")
+	assert.Nil(t, chatView.pendingPlan)
+	assert.Equal(t, []model.ToolCall{{Name: "forget", Arguments: map[string]interface{}{"id": "mem_2"}}}, agent.ran)
+	assert.Equal(t, "Forgot it", lastContent(chatView))
+}
+
+func TestChatView_PlanCanBeCancelled(t *testing.T) {
+	chatView, agent, _ := newPlanTestView(&model.Response{ToolCalls: []model.ToolCall{{Name: "forget"}}})
+
+	typeAndSettle(t, chatView, "forget everything")
+	require.NotNil(t, chatView.pendingPlan)
+	typeAndSettle(t, chatView, "/cancel")
+	assert.Nil(t, chatView.pendingPlan)
+	assert.Equal(t, "Okay, I won't run that plan.", lastContent(chatView))
+	assert.Empty(t, agent.ran)
+
+	typeAndSettle(t, chatView, "/approve")
+	assert.Empty(t, agent.ran, "There is nothing left to approve")
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// retrieveTimeout bounds finding related project files before a request, so
+// a slow embedding model doesn't hold up the response
+const retrieveTimeout = 10 * time.Second
+
+// ProjectIndex finds the parts of indexed project files related to a message
+type ProjectIndex interface {
+	Retrieve(ctx context.Context, query string, limit int) ([]*storage.ProjectChunk, error)
+}
+
+// SetProjectIndex sends up to limit related parts of indexed project files
+// with each message
+func (v *ChatView) SetProjectIndex(index ProjectIndex, limit int) {
+	v.project = index
+	v.retrieveLimit = limit
+}
+
+// projectContext returns the parts of project files related to text for the
+// system message, or "" when there are none or they can't be found
+func projectContext(index ProjectIndex, text string, limit int) string {
+	if index == nil || limit <= 0 {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), retrieveTimeout)
+	defer cancel()
+	chunks, err := index.Retrieve(ctx, text, limit)
+	if err != nil || len(chunks) == 0 {
+		return ""
+	}
+	parts := []string{"Parts of the user's project files that may be relevant:"}
+	for _, chunk := range chunks {
+		path := filepath.Join(chunk.Root, filepath.FromSlash(chunk.Path))
+		parts = append(parts, fmt.Sprintf("%s (lines %d-%d):\n```\n%s\n```", path, chunk.StartLine, chunk.EndLine, chunk.Content))
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProjectIndex returns the same chunks for every query
+type fakeProjectIndex struct {
+	chunks []*storage.ProjectChunk
+	err    error
+}
+
+func (f *fakeProjectIndex) Retrieve(ctx context.Context, query string, limit int) ([]*storage.ProjectChunk, error) {
+	return f.chunks, f.err
+}
+
+func TestProjectContext(t *testing.T) {
+	index := &fakeProjectIndex{chunks: []*storage.ProjectChunk{
+		{Root: "/code/api", Path: "db/store.go", StartLine: 3, EndLine: 4, Content: "// Open connects to the database\nfunc Open() {}"},
+	}}
+
+	context := projectContext(index, "How do I open the database?", 4)
+	assert.Contains(t, context, "Parts of the user's project files that may be relevant:")
+	assert.Contains(t, context, "/code/api/db/store.go (lines 3-4):\n```\n// Open connects to the database\nfunc Open() {}\n```")
+
+	assert.Empty(t, projectContext(index, "How do I open the database?", 0), "Retrieval is off")
+	assert.Empty(t, projectContext(nil, "How do I open the database?", 4))
+	assert.Empty(t, projectContext(&fakeProjectIndex{}, "What is the weather?", 4))
+	assert.Empty(t, projectContext(&fakeProjectIndex{err: errors.New("connection refused")}, "database", 4))
+}
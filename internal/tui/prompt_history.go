@@ -0,0 +1,106 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptHistorySize limits how many earlier prompts are loaded at startup
+const promptHistorySize = 500
+
+// PromptStore persists the prompts typed into the chat input across sessions
+type PromptStore interface {
+	AddPrompt(prompt string) error
+	RecentPrompts(limit int) ([]string, error)
+}
+
+// PromptHistory lets the user step through earlier prompts with up and down,
+// like a shell. The text being typed when navigation starts is kept as a draft
+// and restored after stepping past the newest entry.
+type PromptHistory struct {
+	entries []string
+	index   int // position while navigating; len(entries) when not navigating
+	draft   string
+	store   PromptStore
+}
+
+// promptHistoryLoadedMsg carries the prompts read from the store at startup
+type promptHistoryLoadedMsg struct {
+	prompts []string
+	err     error
+}
+
+// SetStore sets where prompts are persisted
+func (h *PromptHistory) SetStore(store PromptStore) {
+	h.store = store
+}
+
+// Load returns a command that reads the most recent prompts from the store
+func (h *PromptHistory) Load() tea.Cmd {
+	store := h.store
+	if store == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		prompts, err := store.RecentPrompts(promptHistorySize)
+		return promptHistoryLoadedMsg{prompts: prompts, err: err}
+	}
+}
+
+// loaded places the stored prompts before any typed since startup
+func (h *PromptHistory) loaded(prompts []string) {
+	h.entries = append(prompts, h.entries...)
+	h.Reset()
+}
+
+// Add appends a submitted prompt, skipping an immediate repeat, and returns a
+// command that persists it
+func (h *PromptHistory) Add(prompt string) tea.Cmd {
+	defer h.Reset()
+	if n := len(h.entries); n > 0 && h.entries[n-1] == prompt {
+		return nil
+	}
+	h.entries = append(h.entries, prompt)
+
+	store := h.store
+	if store == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := store.AddPrompt(prompt); err != nil {
+			return ToastMsg{Text: "Failed to save prompt history: " + err.Error(), Level: ToastWarning}
+		}
+		return nil
+	}
+}
+
+// Prev returns the prompt before the current position. current is the input
+// value, saved as the draft when navigation starts.
+func (h *PromptHistory) Prev(current string) (string, bool) {
+	if h.index == 0 {
+		return "", false
+	}
+	if h.index == len(h.entries) {
+		h.draft = current
+	}
+	h.index--
+	return h.entries[h.index], true
+}
+
+// Next returns the prompt after the current position, or the draft once past
+// the newest entry
+func (h *PromptHistory) Next() (string, bool) {
+	if h.index >= len(h.entries) {
+		return "", false
+	}
+	h.index++
+	if h.index == len(h.entries) {
+		return h.draft, true
+	}
+	return h.entries[h.index], true
+}
+
+// Reset ends navigation
+func (h *PromptHistory) Reset() {
+	h.index = len(h.entries)
+	h.draft = ""
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPromptStore is an in-memory PromptStore
+type memoryPromptStore struct {
+	prompts []string
+	err     error
+}
+
+func (s *memoryPromptStore) AddPrompt(prompt string) error {
+	s.prompts = append(s.prompts, prompt)
+	return s.err
+}
+
+func (s *memoryPromptStore) RecentPrompts(limit int) ([]string, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.prompts, nil
+}
+
+func TestPromptHistory_Navigation(t *testing.T) {
+	var h PromptHistory
+	h.Add("first")
+	h.Add("second")
+
+	prompt, ok := h.Prev("draft text")
+	require.True(t, ok)
+	assert.Equal(t, "second", prompt)
+	prompt, _ = h.Prev("second")
+	assert.Equal(t, "first", prompt)
+	_, ok = h.Prev("first")
+	assert.False(t, ok, "Stops at the oldest prompt")
+
+	prompt, _ = h.Next()
+	assert.Equal(t, "second", prompt)
+	prompt, ok = h.Next()
+	require.True(t, ok)
+	assert.Equal(t, "draft text", prompt, "Stepping past the newest prompt restores the draft")
+	_, ok = h.Next()
+	assert.False(t, ok)
+}
+
+func TestChatView_RecallsPromptsFromStore(t *testing.T) {
+	store := &memoryPromptStore{prompts: []string{"from last session"}}
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetPromptStore(store)
+	chatView.Update(chatView.history.Load()())
+
+	chatView.SetInput("/tools")
+	_, cmd := chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	deliver(t, chatView, cmd)
+	assert.Equal(t, []string{"from last session", "/tools"}, store.prompts, "Submitted input is saved")
+
+	chatView.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "/tools", chatView.GetInput())
+	chatView.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "from last session", chatView.GetInput())
+	chatView.Update(tea.KeyMsg{Type: tea.KeyDown})
+	chatView.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Empty(t, chatView.GetInput())
+}
+
+func TestChatView_PromptHistoryLoadError(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetPromptStore(&memoryPromptStore{err: errors.New("disk full")})
+
+	_, cmd := chatView.Update(chatView.history.Load()())
+	require.NotNil(t, cmd)
+	toast := cmd().(ToastMsg)
+	assert.Equal(t, ToastWarning, toast.Level)
+	assert.Contains(t, toast.Text, "disk full")
+}
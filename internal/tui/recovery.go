@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// recoveries suggest what the user can do about the errors tools and the
+// model fail with
+var recoveries = []struct {
+	err        error
+	suggestion string
+}{
+	{mcp.ErrToolNotFound, "That tool isn't available. Type /tools to see the tools you can use, or /servers to check the server that provides it."},
+	{mcp.ErrServerUnavailable, "The tool's server isn't responding. Type /servers to check that it is running and reconnect it, then try again."},
+	{mcp.ErrInvalidArguments, "The tool didn't accept the values it was given. Try asking again with the details it needs spelled out."},
+	{model.ErrTurnTimeout, "The request ran out of time before it finished. Try a narrower question, or raise agent.turn_timeout if the tools need longer."},
+	{model.ErrModelTimeout, "The model took too long to answer. Try a shorter question, or check that the model server isn't overloaded."},
+}
+
+// recoverySuggestion returns what the user can do about err, or "" when
+// there is nothing more specific than trying again
+func recoverySuggestion(err error) string {
+	for _, recovery := range recoveries {
+		if errors.Is(err, recovery.err) {
+			return recovery.suggestion
+		}
+	}
+	return ""
+}
+
+// turnRecoverySuggestion is recoverySuggestion for err, which a call made in
+// the turn running under ctx failed with, noting when the turn ran out of
+// time rather than the call itself
+func turnRecoverySuggestion(ctx context.Context, err error) string {
+	if model.TurnTimedOut(ctx) {
+		return recoverySuggestion(model.ErrTurnTimeout)
+	}
+	return recoverySuggestion(err)
+}
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatView_ErrorsSuggestHowToRecover(t *testing.T) {
+	chatView, _, _ := newBranchTestView(t)
+
+	chatView.requestID = "req_1"
+	chatView.Update(ModelResponseMsg{ID: "req_1", Error: fmt.Errorf("%w: send request: context deadline exceeded", model.ErrModelTimeout)})
+	assert.Contains(t, lastContent(chatView), "took too long")
+	assert.Contains(t, chatView.messages[len(chatView.messages)-1].Error, "model timed out")
+
+	chatView.Update(MCPToolExecutedMsg{ToolName: "search", Error: fmt.Errorf("%w: not connected to server", mcp.ErrServerUnavailable)})
+	assert.Contains(t, lastContent(chatView), "/servers")
+
+	chatView.Update(MCPToolExecutedMsg{ToolName: "search", Error: errors.New("boom")})
+	assert.Contains(t, lastContent(chatView), "Please try again", "Other errors keep the generic advice")
+}
+
+func TestRecoverySuggestion(t *testing.T) {
+	assert.Contains(t, recoverySuggestion(fmt.Errorf("%w: stats", mcp.ErrToolNotFound)), "/tools")
+	assert.Contains(t, recoverySuggestion(fmt.Errorf("retry: %w", fmt.Errorf("%w: missing content", mcp.ErrInvalidArguments))), "values")
+	assert.Empty(t, recoverySuggestion(errors.New("boom")))
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"hash/maphash"
+	"strconv"
+	"strings"
+)
+
+// renderCache keeps the rendered blocks of chat messages, so re-rendering the
+// chat only renders the messages that are new or changed. Blocks are keyed by
+// a hash of everything renderMessage shows, so a message changed in place,
+// such as by /tag, is rendered again; a new width starts the cache over.
+type renderCache struct {
+	seed   maphash.Seed
+	width  int
+	blocks map[uint64]renderedBlock // Used by the latest render
+	next   map[uint64]renderedBlock // Used by the render in progress
+	size   int                      // Length of the latest render, to size the next one
+}
+
+// renderedBlock is a rendered message and how many line breaks it has
+type renderedBlock struct {
+	text  string
+	lines int
+}
+
+// begin starts a render of the chat at width. Blocks the render doesn't use
+// are dropped at end.
+func (c *renderCache) begin(width int) {
+	if c.blocks == nil {
+		c.seed = maphash.MakeSeed()
+	}
+	if width != c.width {
+		c.blocks = nil
+		c.width = width
+	}
+	c.next = make(map[uint64]renderedBlock, len(c.blocks))
+}
+
+// get returns the block of msg, rendering it with render when it isn't cached
+func (c *renderCache) get(msg ChatMessage, render func(ChatMessage) string) renderedBlock {
+	key := c.key(msg)
+	block, ok := c.next[key]
+	if !ok {
+		block, ok = c.blocks[key]
+	}
+	if !ok {
+		text := render(msg)
+		block = renderedBlock{text: text, lines: strings.Count(text, "\n")}
+	}
+	c.next[key] = block
+	return block
+}
+
+// end finishes a render of size bytes
+func (c *renderCache) end(size int) {
+	c.blocks, c.next = c.next, nil
+	c.size = size
+}
+
+// key hashes the fields of msg that renderMessage shows
+func (c *renderCache) key(msg ChatMessage) uint64 {
+	var h maphash.Hash
+	h.SetSeed(c.seed)
+	field := func(s string) {
+		h.WriteString(s)
+		h.WriteByte(0)
+	}
+	field(msg.Role)
+	field(msg.Timestamp)
+	field(msg.Content)
+	field(msg.Error)
+	if msg.ToolCall != nil {
+		field("tool")
+		field(msg.ToolCall.Name)
+		field(msg.ToolCall.Result)
+	}
+	field(strconv.Itoa(len(msg.Tags)))
+	for _, tag := range msg.Tags {
+		field(tag)
+	}
+	field(strconv.FormatBool(msg.Pinned) + strconv.FormatBool(msg.Cached) + strconv.Itoa(len(msg.FullOutput)))
+	field(strconv.Itoa(len(msg.Attachments)))
+	for _, a := range msg.Attachments {
+		field(a.Name)
+		field(strconv.FormatInt(a.Size, 10))
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderCache(t *testing.T) {
+	var cache renderCache
+	renders := 0
+	render := func(msg ChatMessage) string {
+		renders++
+		return msg.Content + "\nrendered"
+	}
+	renderAll := func(width int, messages ...ChatMessage) {
+		cache.begin(width)
+		for _, msg := range messages {
+			cache.get(msg, render)
+		}
+		cache.end(0)
+	}
+
+	first := ChatMessage{Role: "user", Content: "first"}
+	second := ChatMessage{Role: "assistant", Content: "second", ToolCall: &ToolCallInfo{Name: "search"}}
+	renderAll(80, first)
+	renderAll(80, first, second)
+	assert.Equal(t, 2, renders, "Only the new message is rendered")
+	cache.begin(80)
+	assert.Equal(t, renderedBlock{text: "first\nrendered", lines: 1}, cache.get(first, render))
+	cache.end(0)
+
+	second.Tags = []string{"backup"}
+	renderAll(80, first, second)
+	assert.Equal(t, 3, renders, "A message changed in place is rendered again")
+	second.ToolCall.Result = "3 notes"
+	renderAll(80, first, second)
+	assert.Equal(t, 4, renders)
+
+	renderAll(100, first, second)
+	assert.Equal(t, 6, renders, "A new width renders everything again")
+	assert.Len(t, cache.blocks, 2, "Blocks no longer shown are dropped")
+}
+
+func TestChatView_RenderMessagesAfterChanges(t *testing.T) {
+	view := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	view.SetSize(80, 12)
+	view.ClearMessages()
+	view.AddMessage(ChatMessage{Role: "user", Content: "find my notes about the backup schedule", Timestamp: "12:00"})
+	view.AddMessage(ChatMessage{Role: "assistant", Content: "Found 3 notes", Timestamp: "12:00"})
+
+	view.messages[1].Pinned = true
+	assert.Contains(t, view.renderMessages(), "📌")
+
+	view.SetSize(20, 12)
+	view.AddMessage(ChatMessage{Role: "assistant", Content: strings.Repeat("word ", 10), Timestamp: "12:01"})
+
+	fresh := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	fresh.SetSize(20, 12)
+	fresh.messages = view.messages
+	assert.Equal(t, fresh.renderMessages(), view.renderMessages(), "Messages are wrapped again at the new width")
+}
+
+func BenchmarkChatView_AddMessage(b *testing.B) {
+	view := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	view.SetSize(120, 40)
+	for i := 0; i < messageWindow; i++ {
+		view.AddMessage(ChatMessage{Role: "assistant", Content: fmt.Sprintf("Message %d: %s", i, strings.Repeat("lorem ipsum dolor sit amet ", 20)), Timestamp: "12:00"})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		view.AddMessage(ChatMessage{Role: "user", Content: "Another question", Timestamp: "12:01"})
+	}
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ResponseCache is implemented by agents that keep recent answers, so a
+// question asked again shortly after is answered without running the model
+// and its tools
+type ResponseCache interface {
+	// CachedResponse returns the answer kept for question asked with context
+	CachedResponse(question, context string) (string, bool)
+	// CacheResponse keeps answer to question asked with context
+	CacheResponse(question, context, answer string)
+}
+
+// cacheContext is what is sent to the model with text besides text itself:
+// the persona, session mode, pinned messages, conversation summary, and tool
+// result metadata
+func (v *ChatView) cacheContext(text string) string {
+	var context []string
+	for _, msg := range v.contextMessages(text) {
+		if msg.Role != "user" {
+			context = append(context, msg.Content)
+		}
+	}
+	return v.Mode() + "\x00" + strings.Join(context, "\x00")
+}
+
+// answerFromCache answers text with the answer the agent kept for it, if
+// any. Messages with attachments, and those asked again with /regenerate,
+// are always sent to the model.
+func (v *ChatView) answerFromCache(text string, attachments []Attachment) (tea.Cmd, bool) {
+	v.cacheQuestion = ""
+	refresh := v.refresh
+	v.refresh = false
+	cache, ok := v.agent.(ResponseCache)
+	if !ok || len(attachments) > 0 {
+		return nil, false
+	}
+	context := v.cacheContext(text)
+	if answer, found := cache.CachedResponse(text, context); found && !refresh {
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   answer,
+			Timestamp: time.Now().Format("15:04"),
+			Cached:    true,
+		})
+		return v.summarize(), true
+	}
+	v.cacheQuestion, v.cacheQuestionContext = text, context
+	return nil, false
+}
+
+// cacheAnswer keeps answer to the current message's question, when it may
+// be answered from the cache
+func (v *ChatView) cacheAnswer(answer string) {
+	cache, ok := v.agent.(ResponseCache)
+	if ok && v.cacheQuestion != "" {
+		cache.CacheResponse(v.cacheQuestion, v.cacheQuestionContext, answer)
+	}
+	v.cacheQuestion = ""
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cachingAgent keeps answers by question and context
+type cachingAgent struct {
+	MockAgentForChat
+	answers map[string]string
+}
+
+func (a *cachingAgent) CachedResponse(question, context string) (string, bool) {
+	answer, ok := a.answers[question+"|"+context]
+	return answer, ok
+}
+
+func (a *cachingAgent) CacheResponse(question, context, answer string) {
+	a.answers[question+"|"+context] = answer
+}
+
+func TestChatView_AnswersRepeatedQuestionsFromCache(t *testing.T) {
+	m := &toolLoopModel{responses: []*model.Response{{Content: "You have 3 memories."}}}
+	agent := &cachingAgent{answers: make(map[string]string)}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), m, agent)
+	chatView.ClearMessages()
+
+	typeAndSettle(t, chatView, "how many memories do I have?")
+	require.Len(t, m.received, 1)
+	assert.False(t, chatView.messages[len(chatView.messages)-1].Cached)
+
+	typeAndSettle(t, chatView, "how many memories do I have?")
+	assert.Len(t, m.received, 1, "The model isn't asked again")
+	latest := chatView.messages[len(chatView.messages)-1]
+	assert.Equal(t, "You have 3 memories.", latest.Content)
+	assert.True(t, latest.Cached)
+	assert.False(t, chatView.waitingForResponse)
+	assert.Contains(t, chatView.renderMessage(latest), "cached")
+
+	typeAndSettle(t, chatView, "/mode analysis")
+	typeAndSettle(t, chatView, "how many memories do I have?")
+	assert.Len(t, m.received, 2, "A question asked in another mode is answered again")
+
+	typeAndSettle(t, chatView, "/regenerate")
+	assert.Len(t, m.received, 3, "Regenerating skips the cache")
+}
@@ -0,0 +1,224 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ScriptAction identifies what a script step does
+type ScriptAction string
+
+const (
+	ScriptType     ScriptAction = "type"     // type text one rune at a time
+	ScriptKey      ScriptAction = "key"      // press a named key (enter, tab, esc, ctrl+c, ...)
+	ScriptSend     ScriptAction = "send"     // type text and press enter
+	ScriptWait     ScriptAction = "wait"     // pause for a duration
+	ScriptResize   ScriptAction = "resize"   // simulate a terminal resize (WIDTHxHEIGHT)
+	ScriptSnapshot ScriptAction = "snapshot" // write the rendered view to a file
+	ScriptExpect   ScriptAction = "expect"   // fail unless the rendered view contains text
+	ScriptDelay    ScriptAction = "delay"    // set the per-keystroke typing delay
+	ScriptQuit     ScriptAction = "quit"     // quit the application
+)
+
+// ScriptStep is a single instruction in a TUI automation script
+type ScriptStep struct {
+	Line     int
+	Action   ScriptAction
+	Arg      string
+	Duration time.Duration
+	Width    int
+	Height   int
+}
+
+// Script is a parsed sequence of simulated keystrokes and messages with timing
+type Script struct {
+	Steps []ScriptStep
+}
+
+// scriptSnapshotMsg asks the application to write its rendered view to a file
+type scriptSnapshotMsg struct {
+	path   string
+	result chan error
+}
+
+// scriptExpectMsg asks the application to check its rendered view for some text
+type scriptExpectMsg struct {
+	text   string
+	result chan error
+}
+
+// namedKeys maps script key names to bubbletea key types
+var namedKeys = map[string]tea.KeyType{
+	"enter":     tea.KeyEnter,
+	"tab":       tea.KeyTab,
+	"shift+tab": tea.KeyShiftTab,
+	"esc":       tea.KeyEsc,
+	"space":     tea.KeySpace,
+	"backspace": tea.KeyBackspace,
+	"delete":    tea.KeyDelete,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"ctrl+c":    tea.KeyCtrlC,
+	"ctrl+l":    tea.KeyCtrlL,
+}
+
+// LoadScript reads and parses a script file
+func LoadScript(path string) (*Script, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open script: %w", err)
+	}
+	defer f.Close()
+
+	return ParseScript(f)
+}
+
+// ParseScript parses a script from a reader. Each non-empty line is an action
+// followed by its argument; lines starting with # are comments.
+//
+//	resize 100x30
+//	send /tools
+//	wait 500ms
+//	key esc
+//	type hello
+//	key enter
+//	expect Assistant
+//	snapshot demo.txt
+func ParseScript(r io.Reader) (*Script, error) {
+	script := &Script{}
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		action, arg, _ := strings.Cut(line, " ")
+		step := ScriptStep{
+			Line:   lineNum,
+			Action: ScriptAction(strings.ToLower(action)),
+			Arg:    strings.TrimSpace(arg),
+		}
+
+		switch step.Action {
+		case ScriptType, ScriptSend:
+			// Text may be empty for send (just presses enter)
+		case ScriptKey:
+			if _, ok := namedKeys[strings.ToLower(step.Arg)]; !ok {
+				return nil, fmt.Errorf("line %d: unknown key %q", lineNum, step.Arg)
+			}
+			step.Arg = strings.ToLower(step.Arg)
+		case ScriptWait, ScriptDelay:
+			d, err := time.ParseDuration(step.Arg)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid duration %q: %w", lineNum, step.Arg, err)
+			}
+			step.Duration = d
+		case ScriptResize:
+			w, h, ok := strings.Cut(strings.ToLower(step.Arg), "x")
+			width, errW := strconv.Atoi(w)
+			height, errH := strconv.Atoi(h)
+			if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+				return nil, fmt.Errorf("line %d: invalid size %q, expected WIDTHxHEIGHT", lineNum, step.Arg)
+			}
+			step.Width, step.Height = width, height
+		case ScriptSnapshot, ScriptExpect:
+			if step.Arg == "" {
+				return nil, fmt.Errorf("line %d: %s requires an argument", lineNum, step.Action)
+			}
+		case ScriptQuit:
+		default:
+			return nil, fmt.Errorf("line %d: unknown action %q", lineNum, action)
+		}
+
+		script.Steps = append(script.Steps, step)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read script: %w", err)
+	}
+
+	return script, nil
+}
+
+// Run plays the script by sending messages through send, which is usually
+// (*tea.Program).Send. It returns the first failed expectation or snapshot error.
+func (s *Script) Run(send func(tea.Msg)) error {
+	typingDelay := time.Duration(0)
+
+	typeText := func(text string) {
+		for _, r := range text {
+			send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			if typingDelay > 0 {
+				time.Sleep(typingDelay)
+			}
+		}
+	}
+
+	for _, step := range s.Steps {
+		switch step.Action {
+		case ScriptType:
+			typeText(step.Arg)
+		case ScriptSend:
+			typeText(step.Arg)
+			send(tea.KeyMsg{Type: tea.KeyEnter})
+		case ScriptKey:
+			send(tea.KeyMsg{Type: namedKeys[step.Arg]})
+		case ScriptWait:
+			time.Sleep(step.Duration)
+		case ScriptDelay:
+			typingDelay = step.Duration
+		case ScriptResize:
+			send(tea.WindowSizeMsg{Width: step.Width, Height: step.Height})
+		case ScriptSnapshot:
+			result := make(chan error, 1)
+			send(scriptSnapshotMsg{path: step.Arg, result: result})
+			if err := <-result; err != nil {
+				return fmt.Errorf("line %d: snapshot: %w", step.Line, err)
+			}
+		case ScriptExpect:
+			result := make(chan error, 1)
+			send(scriptExpectMsg{text: step.Arg, result: result})
+			if err := <-result; err != nil {
+				return fmt.Errorf("line %d: %w", step.Line, err)
+			}
+		case ScriptQuit:
+			send(tea.Quit())
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// handleScriptMsg services snapshot and expectation requests against the rendered view
+func (a *Application) handleScriptMsg(msg tea.Msg) {
+	switch m := msg.(type) {
+	case scriptSnapshotMsg:
+		view := ansi.Strip(a.View())
+		m.result <- os.WriteFile(m.path, []byte(view), 0644)
+	case scriptExpectMsg:
+		if strings.Contains(ansi.Strip(a.View()), m.text) {
+			m.result <- nil
+		} else {
+			m.result <- fmt.Errorf("expected view to contain %q", m.text)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScript(t *testing.T) {
+	input := `
+# demo script
+resize 100x30
+delay 10ms
+send /tools
+wait 250ms
+key ESC
+type hello
+expect Chat
+snapshot out.txt
+quit
+`
+	script, err := ParseScript(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, script.Steps, 9)
+
+	assert.Equal(t, ScriptResize, script.Steps[0].Action)
+	assert.Equal(t, 100, script.Steps[0].Width)
+	assert.Equal(t, 30, script.Steps[0].Height)
+	assert.Equal(t, 10*time.Millisecond, script.Steps[1].Duration)
+	assert.Equal(t, "/tools", script.Steps[2].Arg)
+	assert.Equal(t, 250*time.Millisecond, script.Steps[3].Duration)
+	assert.Equal(t, "esc", script.Steps[4].Arg, "Key names are case-insensitive")
+	assert.Equal(t, 4, script.Steps[1].Line)
+}
+
+func TestParseScript_Errors(t *testing.T) {
+	tests := map[string]string{
+		"unknown action":   "jump 3",
+		"unknown key":      "key hyperspace",
+		"invalid duration": "wait soon",
+		"invalid size":     "resize big",
+		"missing expect":   "expect",
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseScript(strings.NewReader(input))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "line 1")
+		})
+	}
+}
+
+func TestScript_RunDrivesApplication(t *testing.T) {
+	mockAgent := &MockAgent{}
+	mockAgent.On("GetMCPServers").Return([]ServerInfo{})
+	mockAgent.On("GetMCPTools", mock.Anything).Return([]Tool{}, nil)
+
+	app := NewApplicationWithAgent(DefaultKeyMap(), DefaultStyles(), mockAgent)
+	send := func(msg tea.Msg) {
+		model, _ := app.Update(msg)
+		app = model.(*Application)
+		// Render after each update like the real program loop does
+		app.View()
+	}
+
+	snapshotPath := filepath.Join(t.TempDir(), "snapshot.txt")
+	input := "resize 100x30\nsend /commands\nexpect /commands - Show this list\ntype hello\nsnapshot " + snapshotPath
+	script, err := ParseScript(strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.NoError(t, script.Run(send))
+
+	assert.Equal(t, "hello", app.chatView.GetInput())
+
+	snapshot, err := os.ReadFile(snapshotPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(snapshot), "Chat")
+	assert.NotContains(t, string(snapshot), "\x1b[", "Snapshots should be plain text")
+}
+
+func TestScript_RunFailsOnMissingExpectation(t *testing.T) {
+	app := NewApplication(nil)
+	app.width = 80
+	send := func(msg tea.Msg) {
+		app.Update(msg)
+	}
+
+	script, err := ParseScript(strings.NewReader("expect definitely-not-rendered"))
+	require.NoError(t, err)
+
+	err = script.Run(send)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "definitely-not-rendered")
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
 	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
 	"github.com/danieleugenewilliams/othello-agent/internal/model"
 )
@@ -18,7 +19,7 @@ type AgentInterface interface {
 	GetMCPTools(ctx context.Context) ([]Tool, error)
 	GetMCPToolsAsDefinitions(ctx context.Context) ([]model.ToolDefinition, error)
 	GetUniversalIntegration() interface{} // Returns *UniversalAgentIntegration but using interface{} to avoid import cycle
-	SubscribeToUpdates() <-chan interface{} // Channel for receiving status updates
+	Subscribe() *events.Subscription // Events from the agent, starting with the latest ones
 	ExecuteTool(ctx context.Context, toolName string, params map[string]interface{}) (*ToolExecutionResult, error)
 	ProcessToolResult(ctx context.Context, toolName string, result *mcp.ExecuteResult, userQuery string) (string, error)
 	ExecuteToolUnified(ctx context.Context, toolName string, params map[string]interface{}, userContext string) (string, error)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
 	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
 	"github.com/danieleugenewilliams/othello-agent/internal/model"
 	"github.com/stretchr/testify/assert"
@@ -26,12 +27,16 @@ func (m *MockAgent) GetMCPTools(ctx context.Context) ([]Tool, error) {
 	return args.Get(0).([]Tool), args.Error(1)
 }
 
-func (m *MockAgent) SubscribeToUpdates() <-chan interface{} {
+func (m *MockAgent) GetUniversalIntegration() interface{} {
+	return nil
+}
+
+func (m *MockAgent) Subscribe() *events.Subscription {
 	args := m.Called()
-	if ch := args.Get(0); ch != nil {
-		return ch.(<-chan interface{})
+	if sub := args.Get(0); sub != nil {
+		return sub.(*events.Subscription)
 	}
-	// Return a nil channel for tests that don't need it
+	// Return no subscription for tests that don't need it
 	return nil
 }
 
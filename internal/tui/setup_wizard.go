@@ -0,0 +1,367 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// ollamaDetectTimeout bounds how long the wizard waits for Ollama to answer
+const ollamaDetectTimeout = 5 * time.Second
+
+// SetupServer is an optional MCP server offered by the setup wizard
+type SetupServer struct {
+	Name        string
+	Description string
+}
+
+// SetupResult holds the choices made in the setup wizard
+type SetupResult struct {
+	Model   string
+	Servers []string // names of the selected servers
+}
+
+// setupStep is a page of the setup wizard
+type setupStep int
+
+const (
+	setupStepDetect setupStep = iota
+	setupStepModel
+	setupStepServers
+	setupStepConfirm
+	setupStepDone
+)
+
+// ollamaDetectedMsg reports the models found in Ollama, or why it could not be reached
+type ollamaDetectedMsg struct {
+	models []string
+	err    error
+}
+
+// setupSavedMsg reports the outcome of writing the configuration
+type setupSavedMsg struct {
+	path string
+	err  error
+}
+
+// SetupWizard is shown on first run, when no configuration file exists. It
+// detects Ollama, lets the user pick an installed model and recommended MCP
+// servers, and hands the choices to save, which writes the configuration.
+type SetupWizard struct {
+	styles     Styles
+	caps       TerminalCapabilities
+	host       string
+	step       setupStep
+	width      int
+	height     int
+	models     []string
+	detectErr  error
+	cursor     int
+	modelInput textinput.Model
+	model      string
+	servers    []SetupServer
+	selected   map[int]bool
+	savedPath  string
+	saveErr    error
+	completed  bool
+	save       func(SetupResult) (string, error)
+	listModels func(ctx context.Context) ([]string, error)
+}
+
+// NewSetupWizard creates a setup wizard that detects Ollama at host and offers servers
+func NewSetupWizard(styles Styles, host string, servers []SetupServer, save func(SetupResult) (string, error)) *SetupWizard {
+	input := textinput.New()
+	input.Placeholder = DefaultModelSettings().Name
+	input.Prompt = "Model: "
+	input.CharLimit = 100
+
+	ollama := model.NewOllamaModel(host, "")
+	return &SetupWizard{
+		styles:     styles,
+		caps:       FullCapabilities(),
+		host:       host,
+		step:       setupStepDetect,
+		modelInput: input,
+		servers:    servers,
+		selected:   make(map[int]bool),
+		save:       save,
+		listModels: ollama.ListModels,
+	}
+}
+
+// Init implements tea.Model
+func (w *SetupWizard) Init() tea.Cmd {
+	return w.detectOllama()
+}
+
+// detectOllama asks Ollama for its installed models
+func (w *SetupWizard) detectOllama() tea.Cmd {
+	listModels := w.listModels
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), ollamaDetectTimeout)
+		defer cancel()
+		models, err := listModels(ctx)
+		return ollamaDetectedMsg{models: models, err: err}
+	}
+}
+
+// SetCapabilities sets the detected terminal capabilities
+func (w *SetupWizard) SetCapabilities(caps TerminalCapabilities) {
+	w.caps = caps
+}
+
+// Completed reports whether the configuration was written
+func (w *SetupWizard) Completed() bool {
+	return w.completed
+}
+
+// Result returns the choices made so far
+func (w *SetupWizard) Result() SetupResult {
+	result := SetupResult{Model: w.model}
+	for i, server := range w.servers {
+		if w.selected[i] {
+			result.Servers = append(result.Servers, server.Name)
+		}
+	}
+	return result
+}
+
+// Update implements tea.Model
+func (w *SetupWizard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		w.width = msg.Width
+		w.height = msg.Height
+		return w, nil
+
+	case ollamaDetectedMsg:
+		w.models = msg.models
+		w.detectErr = msg.err
+		w.step = setupStepModel
+		w.cursor = 0
+		for i, name := range w.models {
+			if name == DefaultModelSettings().Name {
+				w.cursor = i
+			}
+		}
+		if len(w.models) == 0 {
+			return w, w.modelInput.Focus()
+		}
+		w.modelInput.Blur()
+		return w, nil
+
+	case setupSavedMsg:
+		if msg.err != nil {
+			w.saveErr = msg.err
+			return w, nil
+		}
+		w.savedPath = msg.path
+		w.completed = true
+		w.step = setupStepDone
+		return w, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return w, tea.Quit
+		}
+		return w.handleKey(msg)
+	}
+
+	return w, nil
+}
+
+// handleKey handles a key press on the current step
+func (w *SetupWizard) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch w.step {
+	case setupStepModel:
+		if msg.String() == "ctrl+r" {
+			w.step = setupStepDetect
+			return w, w.detectOllama()
+		}
+		if len(w.models) == 0 {
+			if msg.String() == "enter" {
+				w.model = strings.TrimSpace(w.modelInput.Value())
+				if w.model == "" {
+					w.model = w.modelInput.Placeholder
+				}
+				w.nextStep()
+				return w, nil
+			}
+			var cmd tea.Cmd
+			w.modelInput, cmd = w.modelInput.Update(msg)
+			return w, cmd
+		}
+		switch msg.String() {
+		case "up", "k":
+			w.cursor = (w.cursor - 1 + len(w.models)) % len(w.models)
+		case "down", "j":
+			w.cursor = (w.cursor + 1) % len(w.models)
+		case "enter":
+			w.model = w.models[w.cursor]
+			w.nextStep()
+		}
+
+	case setupStepServers:
+		switch msg.String() {
+		case "up", "k":
+			if len(w.servers) > 0 {
+				w.cursor = (w.cursor - 1 + len(w.servers)) % len(w.servers)
+			}
+		case "down", "j":
+			if len(w.servers) > 0 {
+				w.cursor = (w.cursor + 1) % len(w.servers)
+			}
+		case " ", "x":
+			if len(w.servers) > 0 {
+				w.selected[w.cursor] = !w.selected[w.cursor]
+			}
+		case "enter":
+			w.step = setupStepConfirm
+		case "esc":
+			w.step = setupStepModel
+			w.cursor = 0
+		}
+
+	case setupStepConfirm:
+		switch msg.String() {
+		case "enter":
+			w.saveErr = nil
+			result := w.Result()
+			save := w.save
+			return w, func() tea.Msg {
+				path, err := save(result)
+				return setupSavedMsg{path: path, err: err}
+			}
+		case "esc":
+			w.step = setupStepServers
+			w.cursor = 0
+		}
+
+	case setupStepDone:
+		if msg.String() == "enter" || msg.String() == "q" {
+			return w, tea.Quit
+		}
+	}
+
+	return w, nil
+}
+
+// nextStep leaves model selection, skipping the server step when none are offered
+func (w *SetupWizard) nextStep() {
+	w.modelInput.Blur()
+	w.cursor = 0
+	if len(w.servers) == 0 {
+		w.step = setupStepConfirm
+		return
+	}
+	w.step = setupStepServers
+}
+
+// View implements tea.Model
+func (w *SetupWizard) View() string {
+	header := w.styles.ViewHeader.Width(w.width).Render("🚀 Welcome to Othello")
+
+	var body, hint string
+	switch w.step {
+	case setupStepDetect:
+		body = fmt.Sprintf("Looking for Ollama at %s...", w.host)
+	case setupStepModel:
+		body, hint = w.viewModelStep()
+	case setupStepServers:
+		body, hint = w.viewServerStep()
+	case setupStepConfirm:
+		body, hint = w.viewConfirmStep()
+	case setupStepDone:
+		body = w.styles.SuccessStyle.Render("✓ Configuration saved to "+w.savedPath) +
+			"\n\nReview the settings with `othello config show`, or edit the file to change them."
+		hint = "enter start Othello"
+	}
+
+	sections := []string{header, w.styles.Base.Render(body)}
+	if hint != "" {
+		sections = append(sections, w.styles.Base.Render(w.styles.DimmedStyle.Render(hint+" • ctrl+c quit")))
+	}
+	view := lipgloss.JoinVertical(lipgloss.Left, sections...)
+	if !w.caps.Unicode {
+		view = toASCII(view)
+	}
+	return view
+}
+
+// viewModelStep renders the Ollama status and model choice
+func (w *SetupWizard) viewModelStep() (string, string) {
+	var b strings.Builder
+	b.WriteString("Step 1 of 3: Choose a model\n\n")
+
+	switch {
+	case w.detectErr != nil:
+		b.WriteString(w.styles.ErrorStyle.Render("✗ Ollama is not reachable at "+w.host) + "\n")
+		b.WriteString(w.styles.DimmedStyle.Render(w.detectErr.Error()) + "\n\n")
+		b.WriteString("Install Ollama from https://ollama.com and start it with `ollama serve`,\n")
+		b.WriteString("or enter the model you plan to use:\n\n")
+	case len(w.models) == 0:
+		b.WriteString(w.styles.SuccessStyle.Render("✓ Ollama is running at "+w.host) + "\n")
+		b.WriteString("No models are installed yet. Pull one with `ollama pull " + w.modelInput.Placeholder + "`,\n")
+		b.WriteString("or enter the model you plan to use:\n\n")
+	default:
+		b.WriteString(w.styles.SuccessStyle.Render(fmt.Sprintf("✓ Ollama is running at %s with %d models", w.host, len(w.models))) + "\n\n")
+		for i, name := range w.models {
+			if i == w.cursor {
+				b.WriteString(w.styles.HighlightStyle.Render("> "+name) + "\n")
+			} else {
+				b.WriteString("  " + name + "\n")
+			}
+		}
+		return b.String(), "↑/↓ select • enter continue • ctrl+r detect again"
+	}
+
+	b.WriteString(w.modelInput.View())
+	return b.String(), "enter continue • ctrl+r detect again"
+}
+
+// viewServerStep renders the recommended MCP servers with checkboxes
+func (w *SetupWizard) viewServerStep() (string, string) {
+	var b strings.Builder
+	b.WriteString("Step 2 of 3: Add recommended MCP servers (optional)\n\n")
+	for i, server := range w.servers {
+		check := "[ ]"
+		if w.selected[i] {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %s", check, server.Name)
+		if i == w.cursor {
+			line = w.styles.HighlightStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+		b.WriteString("      " + w.styles.DimmedStyle.Render(server.Description) + "\n")
+	}
+	return b.String(), "↑/↓ move • space toggle • enter continue • esc back"
+}
+
+// viewConfirmStep summarizes the choices before they are written
+func (w *SetupWizard) viewConfirmStep() (string, string) {
+	var b strings.Builder
+	b.WriteString("Step 3 of 3: Save configuration\n\n")
+	b.WriteString(fmt.Sprintf("  Ollama host:  %s\n", w.host))
+	b.WriteString(fmt.Sprintf("  Model:        %s\n", w.model))
+
+	servers := w.Result().Servers
+	if len(servers) == 0 {
+		b.WriteString("  MCP servers:  none\n")
+	} else {
+		b.WriteString(fmt.Sprintf("  MCP servers:  %s\n", strings.Join(servers, ", ")))
+	}
+
+	if w.saveErr != nil {
+		b.WriteString("\n" + w.styles.ErrorStyle.Render("Failed to save: "+w.saveErr.Error()) + "\n")
+	}
+	return b.String(), "enter save • esc back"
+}
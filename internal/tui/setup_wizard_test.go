@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWizard(models []string, detectErr error, save func(SetupResult) (string, error)) *SetupWizard {
+	servers := []SetupServer{
+		{Name: "filesystem", Description: "Files"},
+		{Name: "local-memory", Description: "Memories"},
+	}
+	wizard := NewSetupWizard(DefaultStyles(), "http://localhost:11434", servers, save)
+	wizard.listModels = func(ctx context.Context) ([]string, error) {
+		return models, detectErr
+	}
+	return wizard
+}
+
+// runCmd executes cmd and feeds its message back into the wizard
+func runCmd(t *testing.T, w *SetupWizard, cmd tea.Cmd) {
+	t.Helper()
+	require.NotNil(t, cmd)
+	w.Update(cmd())
+}
+
+func wizardKey(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestSetupWizard_PicksModelAndServers(t *testing.T) {
+	var saved SetupResult
+	wizard := newTestWizard([]string{"llama3.1:8b", "qwen2.5:3b"}, nil, func(result SetupResult) (string, error) {
+		saved = result
+		return "/home/user/.othello/config.yaml", nil
+	})
+
+	runCmd(t, wizard, wizard.Init())
+	assert.Contains(t, wizard.View(), "Ollama is running")
+	// The default model is preselected when installed
+	assert.Contains(t, wizard.View(), "> qwen2.5:3b")
+
+	wizard.Update(wizardKey("enter"))
+	assert.Contains(t, wizard.View(), "Add recommended MCP servers")
+
+	wizard.Update(wizardKey("down"))
+	wizard.Update(wizardKey(" "))
+	assert.Contains(t, wizard.View(), "[x] local-memory")
+	wizard.Update(wizardKey("enter"))
+
+	view := wizard.View()
+	assert.Contains(t, view, "qwen2.5:3b")
+	assert.Contains(t, view, "MCP servers:  local-memory")
+
+	_, cmd := wizard.Update(wizardKey("enter"))
+	runCmd(t, wizard, cmd)
+	assert.True(t, wizard.Completed())
+	assert.Equal(t, SetupResult{Model: "qwen2.5:3b", Servers: []string{"local-memory"}}, saved)
+	assert.Contains(t, wizard.View(), "Configuration saved to /home/user/.othello/config.yaml")
+}
+
+func TestSetupWizard_OllamaUnavailable(t *testing.T) {
+	var saved SetupResult
+	wizard := newTestWizard(nil, errors.New("connection refused"), func(result SetupResult) (string, error) {
+		saved = result
+		return "config.yaml", nil
+	})
+
+	runCmd(t, wizard, wizard.Init())
+	view := wizard.View()
+	assert.Contains(t, view, "Ollama is not reachable")
+	assert.Contains(t, view, "connection refused")
+
+	// An empty entry falls back to the default model
+	wizard.Update(wizardKey("enter"))
+	wizard.Update(wizardKey("enter"))
+	_, cmd := wizard.Update(wizardKey("enter"))
+	runCmd(t, wizard, cmd)
+
+	assert.True(t, wizard.Completed())
+	assert.Equal(t, DefaultModelSettings().Name, saved.Model)
+	assert.Empty(t, saved.Servers)
+}
+
+func TestSetupWizard_TypedModelName(t *testing.T) {
+	wizard := newTestWizard([]string{}, nil, nil)
+	runCmd(t, wizard, wizard.Init())
+	assert.Contains(t, wizard.View(), "No models are installed")
+
+	wizard.Update(wizardKey("mistral"))
+	wizard.Update(wizardKey("enter"))
+	assert.Equal(t, "mistral", wizard.Result().Model)
+}
+
+func TestSetupWizard_SaveErrorStaysOnConfirm(t *testing.T) {
+	wizard := newTestWizard([]string{"qwen2.5:3b"}, nil, func(SetupResult) (string, error) {
+		return "", errors.New("config file already exists")
+	})
+	runCmd(t, wizard, wizard.Init())
+	wizard.Update(wizardKey("enter"))
+	wizard.Update(wizardKey("enter"))
+
+	_, cmd := wizard.Update(wizardKey("enter"))
+	runCmd(t, wizard, cmd)
+	assert.False(t, wizard.Completed())
+	assert.Contains(t, wizard.View(), "Failed to save: config file already exists")
+
+	// Esc returns to the previous step
+	wizard.Update(wizardKey("esc"))
+	assert.Contains(t, wizard.View(), "Add recommended MCP servers")
+}
+
+func TestSetupWizard_CtrlCQuitsWithoutSaving(t *testing.T) {
+	wizard := newTestWizard([]string{"qwen2.5:3b"}, nil, nil)
+	runCmd(t, wizard, wizard.Init())
+
+	_, cmd := wizard.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+	assert.False(t, wizard.Completed())
+}
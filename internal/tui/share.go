@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+// shareTimeout bounds writing and uploading a shared transcript
+const shareTimeout = 2 * time.Minute
+
+// sharedMsg reports where /share put the transcript
+type sharedMsg struct {
+	path string
+	link string // URL of the uploaded transcript; "" when it wasn't uploaded
+	err  error
+}
+
+// sharer is implemented by agents that can share a conversation as a
+// sanitized transcript
+type sharer interface {
+	Share(ctx context.Context, messages []*storage.Message) (path, link string, err error)
+}
+
+// shareConversation handles /share: it writes the conversation so far as a
+// transcript with secrets masked, uploaded when the config says where to
+func (v *ChatView) shareConversation() tea.Cmd {
+	agent, ok := v.agent.(sharer)
+	if !ok {
+		return toastCmd("Sharing needs an agent", ToastWarning)
+	}
+
+	// Messages dropped from the chat are shared from storage when saved
+	var load func() ([]*storage.Message, error)
+	if v.canLoadEarlier() {
+		store, id := v.log.store, v.log.id
+		load = func() ([]*storage.Message, error) { return store.GetThread(id) }
+	} else {
+		// Leave out the /share command itself, which was just added to the chat
+		messages := toStorageMessages(v.messages[:len(v.messages)-1], time.Now())
+		load = func() ([]*storage.Message, error) { return messages, nil }
+	}
+
+	return tea.Batch(v.startWaiting("sharing the conversation"), func() tea.Msg {
+		messages, err := load()
+		if err != nil {
+			return sharedMsg{err: err}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), shareTimeout)
+		defer cancel()
+		path, link, err := agent.Share(ctx, messages)
+		return sharedMsg{path: path, link: link, err: err}
+	})
+}
+
+// shared tells the user where the transcript is
+func (v *ChatView) shared(msg sharedMsg) tea.Cmd {
+	v.waitingForResponse = false
+	if msg.path == "" {
+		return toastCmd("Share failed: "+msg.err.Error(), ToastError)
+	}
+
+	content := "Saved a sanitized transcript to " + msg.path
+	if msg.link != "" {
+		content += "\nUploaded to " + msg.link
+	}
+	result := ChatMessage{
+		Role:      "assistant",
+		Content:   content,
+		Timestamp: time.Now().Format("15:04:05"),
+		Transient: true,
+	}
+	if msg.err != nil {
+		result.Error = msg.err.Error()
+	}
+	v.AddMessage(result)
+	if msg.err != nil {
+		return toastCmd(fmt.Sprintf("Saved to %s but not uploaded", msg.path), ToastWarning)
+	}
+	return toastCmd("Shared the conversation", ToastSuccess)
+}
@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shareAgent records the messages it is asked to share
+type shareAgent struct {
+	MockAgentForChat
+	shared    []*storage.Message
+	uploadErr error
+}
+
+func (a *shareAgent) Share(ctx context.Context, messages []*storage.Message) (string, string, error) {
+	a.shared = messages
+	if a.uploadErr != nil {
+		return "/data/shares/chat.md", "", a.uploadErr
+	}
+	return "/data/shares/chat.md", "https://paste.example.com/abc", nil
+}
+
+func TestChatView_ShareCommand(t *testing.T) {
+	agent := &shareAgent{}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), &MockModel{}, agent)
+	chatView.ClearMessages()
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "what's in my notes?", Timestamp: "10:15:00"})
+	chatView.AddMessage(ChatMessage{Role: "assistant", Content: "Three notes about Postgres.", Timestamp: "10:15:02"})
+
+	deliver(t, chatView, chatView.handleCommand("/share"))
+	require.Len(t, agent.shared, 2, "The /share command itself isn't shared")
+	assert.Equal(t, "what's in my notes?", agent.shared[0].Content)
+	assert.False(t, chatView.waitingForResponse)
+
+	last := chatView.messages[len(chatView.messages)-1]
+	assert.Equal(t, "Saved a sanitized transcript to /data/shares/chat.md\nUploaded to https://paste.example.com/abc", last.Content)
+	assert.True(t, last.Transient)
+}
+
+func TestChatView_ShareCommandUploadFailed(t *testing.T) {
+	agent := &shareAgent{uploadErr: errors.New("upload transcript: 401 Unauthorized")}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), &MockModel{}, agent)
+	chatView.ClearMessages()
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "hello"})
+
+	deliver(t, chatView, chatView.handleCommand("/share"))
+	last := chatView.messages[len(chatView.messages)-1]
+	assert.Equal(t, "Saved a sanitized transcript to /data/shares/chat.md", last.Content)
+	assert.Equal(t, "upload transcript: 401 Unauthorized", last.Error)
+}
+
+func TestChatView_ShareCommandNeedsAgent(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), nil)
+	cmd := chatView.handleCommand("/share")
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Sharing needs an agent", cmd().(ToastMsg).Text)
+}
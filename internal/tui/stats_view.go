@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+)
+
+const (
+	// statsLimit caps how many conversations the stats view lists
+	statsLimit = 100
+
+	// statsTitleWidth is the width of the conversation column
+	statsTitleWidth = 32
+)
+
+// ConversationStatistics reads the usage recorded for saved conversations
+type ConversationStatistics interface {
+	ListConversationStatistics(filter storage.StatsFilter) ([]*storage.ConversationStats, error)
+}
+
+// StatsView shows token use, model latency, and tool calls per conversation
+type StatsView struct {
+	width    int
+	height   int
+	styles   Styles
+	viewport viewport.Model
+	stats    ConversationStatistics // nil without storage
+	lastWeek bool
+}
+
+// statsLoadedMsg carries the statistics shown in the stats view
+type statsLoadedMsg struct {
+	stats []*storage.ConversationStats
+	err   error
+}
+
+// NewStatsView creates a new stats view
+func NewStatsView(styles Styles) *StatsView {
+	vp := viewport.New(0, 0)
+	vp.SetContent("No conversations recorded yet.")
+
+	return &StatsView{
+		styles:   styles,
+		viewport: vp,
+	}
+}
+
+// Init initializes the stats view
+func (v *StatsView) Init() tea.Cmd {
+	return nil
+}
+
+// SetStatistics sets where conversation statistics are read from
+func (v *StatsView) SetStatistics(stats ConversationStatistics) {
+	v.stats = stats
+}
+
+// Load returns a command that reads the conversation statistics
+func (v *StatsView) Load() tea.Cmd {
+	stats := v.stats
+	if stats == nil {
+		return nil
+	}
+	filter := storage.StatsFilter{Limit: statsLimit}
+	if v.lastWeek {
+		filter.Since = time.Now().AddDate(0, 0, -7)
+	}
+	return func() tea.Msg {
+		all, err := stats.ListConversationStatistics(filter)
+		return statsLoadedMsg{stats: all, err: err}
+	}
+}
+
+// Update handles updates for the stats view
+func (v *StatsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case statsLoadedMsg:
+		if msg.err != nil {
+			v.viewport.SetContent("Failed to load conversation statistics: " + msg.err.Error())
+			return v, nil
+		}
+		v.viewport.SetContent(renderConversationStats(msg.stats))
+		return v, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return v, func() tea.Msg {
+				return ViewSwitchMsg{ViewType: ChatViewType}
+			}
+		case "w":
+			v.lastWeek = !v.lastWeek
+			return v, v.Load()
+		case "r":
+			return v, v.Load()
+		}
+	}
+
+	var cmd tea.Cmd
+	v.viewport, cmd = v.viewport.Update(msg)
+	return v, cmd
+}
+
+// View renders the stats view
+func (v *StatsView) View() string {
+	if v.width == 0 {
+		return "Loading statistics..."
+	}
+
+	title := "📊 Conversation Statistics"
+	if v.lastWeek {
+		title += " (last 7 days)"
+	}
+	header := v.styles.ViewHeader.
+		Width(v.width).
+		Render(title)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		v.viewport.View(),
+		v.styles.DimmedStyle.Render("w: toggle last 7 days • r: refresh • esc: back"),
+	)
+}
+
+// SetSize sets the size of the stats view
+func (v *StatsView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+	v.viewport.Width = width
+	v.viewport.Height = height - 4 // Account for header and key hints
+}
+
+// renderConversationStats writes a table of the statistics, one conversation
+// per row under a row of totals
+func renderConversationStats(all []*storage.ConversationStats) string {
+	if len(all) == 0 {
+		return "No conversations recorded yet."
+	}
+
+	total := storage.TotalStatistics(all)
+	lines := []string{
+		fmt.Sprintf("%-*s %6s %10s %10s %6s %8s %6s", statsTitleWidth, "Conversation", "Msgs", "Tokens in", "Tokens out", "Calls", "Latency", "Tools"),
+		describeConversationStats(fmt.Sprintf("Total (%d conversations)", len(all)), &total),
+		"",
+	}
+	for _, stats := range all {
+		lines = append(lines, describeConversationStats(stats.Title, stats))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeConversationStats writes one row of the stats table; latency is
+// the average per model call
+func describeConversationStats(title string, stats *storage.ConversationStats) string {
+	name := []rune(title)
+	if len(name) > statsTitleWidth {
+		name = append(name[:statsTitleWidth-3], []rune("...")...)
+	}
+	return fmt.Sprintf("%-*s %6d %10d %10d %6d %8s %6d", statsTitleWidth, string(name),
+		stats.Messages, stats.TokensIn, stats.TokensOut, stats.ModelCalls,
+		stats.AverageLatency().Round(100*time.Millisecond), stats.ToolCalls)
+}
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/danieleugenewilliams/othello-agent/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatView_RecordsModelUsage(t *testing.T) {
+	store := newTestConversationStore(t)
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.SetConversationStore(store)
+
+	chatView.SetInput("list the files")
+	chatView.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	toolCalls := []model.ToolCall{{Name: "list"}, {Name: "stat"}}
+	chatView.Update(ToolCallDetectedMsg{
+		RequestID: chatView.requestID,
+		ToolCalls: toolCalls,
+		Response:  &model.Response{ToolCalls: toolCalls, Usage: model.Usage{PromptTokens: 100, CompletionTokens: 20}, Duration: time.Second},
+	})
+	chatView.Update(ModelResponseMsg{
+		ID:       chatView.requestID,
+		Response: &model.Response{Content: "Two files", Usage: model.Usage{PromptTokens: 150, CompletionTokens: 30}, Duration: 3 * time.Second},
+	})
+	chatView.Update(ModelResponseMsg{ID: "stale", Response: &model.Response{Content: "ignored", Usage: model.Usage{PromptTokens: 999}}})
+	require.NoError(t, chatView.persistError())
+
+	all, err := store.SearchManager().ListConversationStatistics(storage.StatsFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	stats := all[0]
+	assert.Equal(t, "list the files", stats.Title)
+	assert.Equal(t, 250, stats.TokensIn)
+	assert.Equal(t, 50, stats.TokensOut)
+	assert.Equal(t, 2, stats.ModelCalls)
+	assert.Equal(t, 2*time.Second, stats.AverageLatency())
+	assert.Equal(t, 2, stats.ToolCalls)
+
+	tools, err := store.SearchManager().ToolStatistics([]string{stats.ConversationID})
+	require.NoError(t, err)
+	assert.Equal(t, []*storage.ToolStats{
+		{Tool: "list", Calls: 1, TokensIn: 50, TokensOut: 10},
+		{Tool: "stat", Calls: 1, TokensIn: 50, TokensOut: 10},
+	}, tools)
+}
+
+func TestRenderConversationStats(t *testing.T) {
+	all := []*storage.ConversationStats{
+		{Title: "A very long conversation title that will not fit", Messages: 4, TokensIn: 1200, TokensOut: 300, ModelCalls: 2, ModelLatency: 3 * time.Second, ToolCalls: 1},
+		{Title: "Short", Messages: 2, TokensIn: 100, TokensOut: 50, ModelCalls: 1, ModelLatency: 500 * time.Millisecond},
+	}
+
+	rendered := renderConversationStats(all)
+	assert.Contains(t, rendered, "Tokens in")
+	assert.Contains(t, rendered, "Total (2 conversations)")
+	assert.Regexp(t, `Total \(2 conversations\)\s+6\s+1300\s+350\s+3\s+1.2s\s+1`, rendered)
+	assert.Contains(t, rendered, "A very long conversation titl...")
+	assert.Regexp(t, `Short\s+2\s+100\s+50\s+1\s+500ms\s+0`, rendered)
+
+	assert.Equal(t, "No conversations recorded yet.", renderConversationStats(nil))
+}
+
+func TestApplication_StatsViewLoadsOnOpen(t *testing.T) {
+	store := newTestConversationStore(t)
+	_, err := store.CreateConversation("conv", "Release planning")
+	require.NoError(t, err)
+	require.NoError(t, store.RecordModelCall("conv", 42, 7, time.Second))
+
+	app := NewApplication(&MockModel{})
+	app.statsView.SetStatistics(store.SearchManager())
+	app.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	_, cmd := app.Update(ViewSwitchMsg{ViewType: StatsViewType})
+	require.NotNil(t, cmd)
+	app.Update(cmd())
+	view := app.statsView.View()
+	assert.Contains(t, view, "Release planning")
+	assert.Regexp(t, `Release planning\s+0\s+42\s+7\s+1\s+1s`, view)
+
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	require.NotNil(t, cmd)
+	app.Update(cmd())
+	assert.Contains(t, app.statsView.View(), "last 7 days")
+}
+
+func TestChatView_StatsCommandSwitchesView(t *testing.T) {
+	v := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	cmd := v.handleCommand("/stats")
+	require.NotNil(t, cmd)
+	assert.Equal(t, ViewSwitchMsg{ViewType: StatsViewType}, cmd())
+}
@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PromptTemplate is a saved prompt /template sends once its variables are
+// filled in
+type PromptTemplate struct {
+	Name        string
+	Description string
+	Variables   []TemplateVariable // Asked for in this order
+}
+
+// TemplateVariable is a value a prompt template asks for
+type TemplateVariable struct {
+	Name        string
+	Description string
+	Default     string // Used when the answer is empty; "" makes an answer required
+}
+
+// templateLibrary is implemented by agents with saved prompt templates
+type templateLibrary interface {
+	PromptTemplates() ([]PromptTemplate, error)
+	FillTemplate(name string, values map[string]string) (string, error)
+}
+
+// templateFill holds a template while the user answers its variables, one at
+// a time
+type templateFill struct {
+	template PromptTemplate
+	values   map[string]string
+}
+
+// useTemplate handles /template: without a name it lists the templates, and
+// with one it asks for the template's variables, then sends its prompt
+func (v *ChatView) useTemplate(name string) tea.Cmd {
+	library, ok := v.agent.(templateLibrary)
+	if !ok {
+		return toastCmd("Templates need an agent", ToastWarning)
+	}
+	templates, err := library.PromptTemplates()
+	var warning tea.Cmd
+	if err != nil {
+		warning = toastCmd("Some templates couldn't be read; see the log", ToastWarning)
+	}
+
+	if name == "" {
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   describeTemplates(templates),
+			Timestamp: time.Now().Format("15:04:05"),
+			Transient: true,
+		})
+		return warning
+	}
+	for _, tmpl := range templates {
+		if strings.EqualFold(tmpl.Name, name) {
+			v.pendingTemplate = &templateFill{template: tmpl, values: make(map[string]string)}
+			return tea.Batch(warning, v.nextTemplateVariable(""))
+		}
+	}
+	return tea.Batch(warning, toastCmd(fmt.Sprintf("Unknown template %s. Type /template to list them.", name), ToastWarning))
+}
+
+// templateNames returns the names of library's templates, for completing
+// /template
+func templateNames(library templateLibrary) []string {
+	templates, _ := library.PromptTemplates()
+	names := make([]string, len(templates))
+	for i, tmpl := range templates {
+		names[i] = tmpl.Name
+	}
+	return names
+}
+
+// describeTemplates lists templates with their variables
+func describeTemplates(templates []PromptTemplate) string {
+	if len(templates) == 0 {
+		return "No templates yet. Save prompts as Markdown files in the templates directory of the data directory, such as ~/.othello/templates/review.md."
+	}
+	lines := []string{"Templates (/template <name> to use one):"}
+	for _, tmpl := range templates {
+		line := "  " + tmpl.Name
+		if tmpl.Description != "" {
+			line += " - " + tmpl.Description
+		}
+		if len(tmpl.Variables) > 0 {
+			names := make([]string, len(tmpl.Variables))
+			for i, variable := range tmpl.Variables {
+				names[i] = variable.Name
+			}
+			line += " (" + strings.Join(names, ", ") + ")"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// nextTemplateVariable asks for the first variable without a value, after
+// problem with the previous answer if there was one, or sends the filled-in
+// prompt once every variable has one
+func (v *ChatView) nextTemplateVariable(problem string) tea.Cmd {
+	fill := v.pendingTemplate
+	for _, variable := range fill.template.Variables {
+		if _, answered := fill.values[variable.Name]; answered {
+			continue
+		}
+		var b strings.Builder
+		if problem != "" {
+			fmt.Fprintf(&b, "Sorry, %s. ", problem)
+		}
+		fmt.Fprintf(&b, "Template %s: what should %s be?", fill.template.Name, variable.Name)
+		if variable.Description != "" {
+			fmt.Fprintf(&b, " (%s)", strings.TrimSuffix(variable.Description, "."))
+		}
+		if variable.Default != "" {
+			fmt.Fprintf(&b, " Press Enter for %q.", variable.Default)
+		}
+		b.WriteString(" (/cancel to stop)")
+		v.AddMessage(ChatMessage{
+			Role:      "assistant",
+			Content:   b.String(),
+			Timestamp: time.Now().Format("15:04:05"),
+			Transient: true,
+		})
+		return nil
+	}
+
+	v.pendingTemplate = nil
+	prompt, err := v.agent.(templateLibrary).FillTemplate(fill.template.Name, fill.values)
+	if err != nil {
+		return toastCmd("Template failed: "+err.Error(), ToastError)
+	}
+	return v.sendMessage(prompt, v.takeAttachments(), v.requestOptions())
+}
+
+// answerTemplateVariable takes text as the value of the variable asked for;
+// an empty answer takes its default
+func (v *ChatView) answerTemplateVariable(text string) tea.Cmd {
+	fill := v.pendingTemplate
+	var variable TemplateVariable
+	for _, candidate := range fill.template.Variables {
+		if _, answered := fill.values[candidate.Name]; !answered {
+			variable = candidate
+			break
+		}
+	}
+	if text != "" {
+		v.AddMessage(ChatMessage{
+			Role:      "user",
+			Content:   text,
+			Timestamp: time.Now().Format("15:04:05"),
+			Transient: true,
+		})
+	}
+	if text == "" && variable.Default == "" {
+		return v.nextTemplateVariable(variable.Name + " has no default")
+	}
+	fill.values[variable.Name] = text
+	return v.nextTemplateVariable("")
+}
+
+// cancelTemplate discards the template being filled in
+func (v *ChatView) cancelTemplate() tea.Cmd {
+	v.pendingTemplate = nil
+	return toastCmd("Template cancelled", ToastInfo)
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// templateAgent is a chat mock with saved prompt templates, whose bodies are
+// "Review <repo> for <focus>."
+type templateAgent struct {
+	MockAgentForChat
+	filled []map[string]string
+}
+
+func (a *templateAgent) PromptTemplates() ([]PromptTemplate, error) {
+	return []PromptTemplate{{
+		Name:        "review",
+		Description: "Review a repository",
+		Variables: []TemplateVariable{
+			{Name: "repo", Description: "Repository to review."},
+			{Name: "focus", Default: "bugs"},
+		},
+	}}, nil
+}
+
+func (a *templateAgent) FillTemplate(name string, values map[string]string) (string, error) {
+	a.filled = append(a.filled, values)
+	return "Review " + values["repo"] + " for " + values["focus"] + ".", nil
+}
+
+func TestChatView_TemplateAsksForVariables(t *testing.T) {
+	m := &toolLoopModel{responses: []*model.Response{{Content: "Looks good."}}}
+	agent := &templateAgent{}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), m, agent)
+	chatView.ClearMessages()
+
+	typeAndSettle(t, chatView, "/template")
+	assert.Equal(t, "Templates (/template <name> to use one):\n  review - Review a repository (repo, focus)", lastContent(chatView))
+
+	typeAndSettle(t, chatView, "/template Review")
+	assert.Equal(t, "Template review: what should repo be? (Repository to review) (/cancel to stop)", lastContent(chatView))
+
+	// repo has no default, so it needs an answer
+	typeAndSettle(t, chatView, "")
+	assert.True(t, strings.HasPrefix(lastContent(chatView), "Sorry, repo has no default. Template review: what should repo be?"))
+
+	typeAndSettle(t, chatView, "othello")
+	assert.Equal(t, `Template review: what should focus be? Press Enter for "bugs". (/cancel to stop)`, lastContent(chatView))
+	assert.Empty(t, m.received)
+
+	typeAndSettle(t, chatView, "")
+	require.Len(t, agent.filled, 1)
+	assert.Equal(t, map[string]string{"repo": "othello", "focus": ""}, agent.filled[0])
+	require.Len(t, m.received, 1)
+	sent := m.received[0]
+	assert.Equal(t, "Review othello for .", sent[len(sent)-1].Content)
+	assert.Nil(t, chatView.pendingTemplate)
+}
+
+func TestChatView_TemplateCancel(t *testing.T) {
+	m := &toolLoopModel{}
+	agent := &templateAgent{}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), m, agent)
+
+	typeAndSettle(t, chatView, "/template review")
+	require.NotNil(t, chatView.pendingTemplate)
+	typeAndSettle(t, chatView, "/cancel")
+	assert.Nil(t, chatView.pendingTemplate)
+	assert.Empty(t, agent.filled)
+	assert.Empty(t, m.received)
+
+	assert.Equal(t, "Unknown template missing. Type /template to list them.", chatView.useTemplate("missing")().(ToastMsg).Text)
+}
+
+func TestAutocomplete_CompletesTemplateNames(t *testing.T) {
+	loads := 0
+	var ac Autocomplete
+	ac.CompleteArguments("/template", func() []string {
+		loads++
+		return []string{"review", "release-notes", "summary"}
+	})
+
+	ac.Update("/template ", noTools)
+	assert.Equal(t, []string{"/template review", "/template release-notes", "/template summary"}, suggestionValues(ac.Suggestions()))
+	ac.Update("/template re", noTools)
+	assert.Equal(t, []string{"/template review", "/template release-notes"}, suggestionValues(ac.Suggestions()))
+	assert.Equal(t, 1, loads, "Names are loaded once while the argument is typed")
+
+	ac.Next()
+	assert.Equal(t, "/template release-notes", ac.Apply("/template re"))
+
+	ac.Update("/template review extra", noTools)
+	assert.False(t, ac.Visible())
+	ac.Update("/templ", noTools)
+	ac.Update("/template ", noTools)
+	assert.Equal(t, 2, loads, "Names are reloaded when the command is typed again")
+}
@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+// Render modes accepted by the tui.render_mode setting
+const (
+	RenderModeAuto  = "auto"  // detect terminal capabilities
+	RenderModeFull  = "full"  // assume a modern terminal
+	RenderModePlain = "plain" // no color, no alt screen, ASCII only
+)
+
+// TerminalCapabilities describes what the attached terminal can render
+type TerminalCapabilities struct {
+	Interactive bool            // stdout is a TTY
+	AltScreen   bool            // alternate screen buffer and mouse reporting are safe to use
+	Unicode     bool            // box drawing characters and emoji render correctly
+	Color       termenv.Profile // richest color profile supported
+	Accessible  bool            // screen-reader friendly output with high-contrast styles
+}
+
+// Degraded reports whether any capability is missing and rendering should be simplified
+func (c TerminalCapabilities) Degraded() bool {
+	return !c.AltScreen || !c.Unicode || c.Color == termenv.Ascii
+}
+
+// FullCapabilities returns the capabilities of a modern terminal
+func FullCapabilities() TerminalCapabilities {
+	return TerminalCapabilities{
+		Interactive: true,
+		AltScreen:   true,
+		Unicode:     true,
+		Color:       termenv.TrueColor,
+	}
+}
+
+// PlainCapabilities returns the capabilities of the most minimal terminal
+func PlainCapabilities() TerminalCapabilities {
+	return TerminalCapabilities{
+		Interactive: term.IsTerminal(os.Stdout.Fd()),
+		Color:       termenv.Ascii,
+	}
+}
+
+// DetectTerminalCapabilities inspects the environment for the given render mode.
+// In auto mode it checks whether stdout is a TTY, the TERM and locale variables,
+// and the color profile reported by termenv (which honors NO_COLOR and COLORTERM).
+// NO_COLOR disables color in every mode.
+func DetectTerminalCapabilities(mode string) TerminalCapabilities {
+	var caps TerminalCapabilities
+	switch strings.ToLower(mode) {
+	case RenderModeFull:
+		caps = FullCapabilities()
+	case RenderModePlain:
+		caps = PlainCapabilities()
+	default:
+		caps = detectCapabilities(os.Getenv, term.IsTerminal(os.Stdout.Fd()), termenv.EnvColorProfile())
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		caps.Color = termenv.Ascii
+	}
+	return caps
+}
+
+// detectCapabilities derives capabilities from environment lookups so it can be tested
+func detectCapabilities(getenv func(string) string, interactive bool, profile termenv.Profile) TerminalCapabilities {
+	caps := TerminalCapabilities{
+		Interactive: interactive,
+		AltScreen:   interactive,
+		Unicode:     localeSupportsUnicode(getenv),
+		Color:       profile,
+	}
+
+	termName := strings.ToLower(getenv("TERM"))
+	switch {
+	case termName == "" || termName == "dumb":
+		// Serial consoles, CI logs, and editors' embedded shells
+		caps.AltScreen = false
+		caps.Color = termenv.Ascii
+	case termName == "linux" || strings.HasPrefix(termName, "vt"):
+		// Kernel virtual consoles and hardware terminals lack emoji and most box glyphs
+		caps.Unicode = false
+	}
+
+	if getenv("CI") != "" {
+		caps.AltScreen = false
+	}
+
+	if !interactive {
+		caps.Color = termenv.Ascii
+	}
+
+	return caps
+}
+
+// localeSupportsUnicode reports whether the locale variables select a UTF-8 charset
+func localeSupportsUnicode(getenv func(string) string) bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	// No locale configured (common on macOS and Windows terminals): assume UTF-8
+	return true
+}
+
+// asciiBorder is a border that renders on terminals without box drawing glyphs
+var asciiBorder = lipgloss.Border{
+	Top:          "-",
+	Bottom:       "-",
+	Left:         "|",
+	Right:        "|",
+	TopLeft:      "+",
+	TopRight:     "+",
+	BottomLeft:   "+",
+	BottomRight:  "+",
+	MiddleLeft:   "+",
+	MiddleRight:  "+",
+	Middle:       "+",
+	MiddleTop:    "+",
+	MiddleBottom: "+",
+}
+
+// StylesForCapabilities adapts styles to what the terminal can render
+func StylesForCapabilities(styles Styles, caps TerminalCapabilities) Styles {
+	if caps.Accessible {
+		return accessibleStyles()
+	}
+	if caps.Color == termenv.Ascii {
+		// Highlighting must survive without color, so fall back to reverse video
+		styles.HighlightStyle = lipgloss.NewStyle().Reverse(true)
+		styles.StatusBar = lipgloss.NewStyle().Reverse(true).Padding(0, 1)
+		styles.ViewHeader = lipgloss.NewStyle().Reverse(true).Bold(true).Padding(0, 1)
+	}
+	if !caps.Unicode {
+		styles.InputBox = styles.InputBox.Border(asciiBorder)
+		styles.ServerList = styles.ServerList.Border(asciiBorder)
+		styles.ActivityPane = styles.ActivityPane.Border(asciiBorder, false, false, false, true)
+	}
+	return styles
+}
+
+// ApplyColorProfile makes lipgloss render with the detected color profile
+func ApplyColorProfile(caps TerminalCapabilities) {
+	lipgloss.SetColorProfile(caps.Color)
+}
+
+// asciiReplacer maps the glyphs used across the views to ASCII equivalents
+var asciiReplacer = strings.NewReplacer(
+	"💬", "[chat]",
+	"🖥️", "[servers]",
+	"🖱️", "[mouse]",
+	"📚", "[history]",
+	"❓", "[?]",
+	"🤖", "",
+	"🚀", "",
+	"🔧", "[tool]",
+	"📌", "[pinned]",
+	"✅", "[ok]",
+	"❌", "[x]",
+	"⚠️", "[!]",
+	"ℹ️", "[i]",
+	"⏳", "...",
+	"⚡", "[activity]",
+	"→", "->",
+	"✓", "[ok]",
+	"✗", "[x]",
+	"❯", ">",
+	"✎", "~",
+	"•", "*",
+	"…", "...",
+	"╭", "+", "╮", "+", "╰", "+", "╯", "+",
+	"┌", "+", "┐", "+", "└", "+", "┘", "+",
+	"─", "-", "│", "|", "├", "+",
+	"↑", "^", "↓", "v",
+	// Frames of the progress spinner
+	"⣾", "|", "⣽", "/", "⣻", "-", "⢿", "\\", "⡿", "|", "⣟", "/", "⣯", "-", "⣷", "\\",
+)
+
+// toASCII replaces known glyphs with ASCII and drops any other non-ASCII runes
+func toASCII(s string) string {
+	s = asciiReplacer.Replace(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r < 0x80 {
+			b.WriteRune(r)
+		} else if r != 0xFE0F {
+			// Unknown glyph: keep the column count stable with a placeholder
+			b.WriteRune('?')
+		}
+	}
+	return b.String()
+}
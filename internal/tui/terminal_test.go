@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func envFrom(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestDetectCapabilities(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		interactive bool
+		expected    TerminalCapabilities
+	}{
+		{
+			name:        "modern terminal",
+			env:         map[string]string{"TERM": "xterm-256color", "LANG": "en_US.UTF-8"},
+			interactive: true,
+			expected:    TerminalCapabilities{Interactive: true, AltScreen: true, Unicode: true, Color: termenv.TrueColor},
+		},
+		{
+			name:        "dumb terminal",
+			env:         map[string]string{"TERM": "dumb", "LANG": "en_US.UTF-8"},
+			interactive: true,
+			expected:    TerminalCapabilities{Interactive: true, AltScreen: false, Unicode: true, Color: termenv.Ascii},
+		},
+		{
+			name:        "linux console",
+			env:         map[string]string{"TERM": "linux", "LANG": "C.UTF-8"},
+			interactive: true,
+			expected:    TerminalCapabilities{Interactive: true, AltScreen: true, Unicode: false, Color: termenv.TrueColor},
+		},
+		{
+			name:        "non-UTF-8 locale",
+			env:         map[string]string{"TERM": "xterm", "LC_ALL": "POSIX"},
+			interactive: true,
+			expected:    TerminalCapabilities{Interactive: true, AltScreen: true, Unicode: false, Color: termenv.TrueColor},
+		},
+		{
+			name:        "CI log",
+			env:         map[string]string{"TERM": "xterm", "CI": "true"},
+			interactive: false,
+			expected:    TerminalCapabilities{Interactive: false, AltScreen: false, Unicode: true, Color: termenv.Ascii},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := detectCapabilities(envFrom(tt.env), tt.interactive, termenv.TrueColor)
+			assert.Equal(t, tt.expected, caps)
+		})
+	}
+}
+
+func TestTerminalCapabilities_Degraded(t *testing.T) {
+	assert.False(t, FullCapabilities().Degraded())
+	assert.True(t, TerminalCapabilities{AltScreen: true, Unicode: false, Color: termenv.ANSI256}.Degraded())
+	assert.True(t, TerminalCapabilities{AltScreen: true, Unicode: true, Color: termenv.Ascii}.Degraded())
+}
+
+func TestToASCII(t *testing.T) {
+	assert.Equal(t, "[chat] Chat", toASCII("💬 Chat"))
+	assert.Equal(t, "+--+\n|ok|\n+--+", toASCII("╭──╮\n│ok│\n╰──╯"))
+	assert.Equal(t, "caf?", toASCII("café"))
+}
+
+func TestApplication_PlainRendering(t *testing.T) {
+	caps := TerminalCapabilities{Interactive: true, Color: termenv.Ascii}
+	app := NewApplication(nil)
+	app.styles = StylesForCapabilities(DefaultStyles(), caps)
+	app.SetCapabilities(caps)
+	app.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	view := app.View()
+	for _, r := range view {
+		if r >= 0x80 {
+			t.Fatalf("plain rendering produced non-ASCII rune %q in:\n%s", r, view)
+		}
+	}
+	assert.True(t, strings.Contains(view, "[chat]"))
+}
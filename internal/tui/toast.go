@@ -0,0 +1,195 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ToastLevel indicates the severity of a toast notification
+type ToastLevel int
+
+const (
+	ToastInfo ToastLevel = iota
+	ToastSuccess
+	ToastWarning
+	ToastError
+)
+
+const (
+	// DefaultToastDuration is how long a toast stays visible when no duration is given
+	DefaultToastDuration = 4 * time.Second
+	// maxVisibleToasts caps how many toasts are stacked on screen at once
+	maxVisibleToasts = 3
+)
+
+// ToastMsg requests a transient notification to be shown over the current view.
+// The agent publishes an events.NotificationEvent for background events.
+type ToastMsg struct {
+	Text     string
+	Level    ToastLevel
+	Duration time.Duration
+}
+
+// toastExpiredMsg signals that the toast with the given ID should be dismissed
+type toastExpiredMsg struct {
+	id int
+}
+
+// toast is a single visible notification
+type toast struct {
+	id    int
+	text  string
+	level ToastLevel
+}
+
+// ToastStack holds the currently visible toasts, newest last
+type ToastStack struct {
+	toasts []toast
+	nextID int
+}
+
+// NewToastStack creates an empty toast stack
+func NewToastStack() *ToastStack {
+	return &ToastStack{}
+}
+
+// Push adds a toast and returns a command that dismisses it after its duration
+func (s *ToastStack) Push(msg ToastMsg) tea.Cmd {
+	if strings.TrimSpace(msg.Text) == "" {
+		return nil
+	}
+
+	s.nextID++
+	id := s.nextID
+	s.toasts = append(s.toasts, toast{id: id, text: msg.Text, level: msg.Level})
+
+	// Drop the oldest toasts if too many are stacked
+	if len(s.toasts) > maxVisibleToasts {
+		s.toasts = s.toasts[len(s.toasts)-maxVisibleToasts:]
+	}
+
+	duration := msg.Duration
+	if duration <= 0 {
+		duration = DefaultToastDuration
+	}
+
+	return tea.Tick(duration, func(time.Time) tea.Msg {
+		return toastExpiredMsg{id: id}
+	})
+}
+
+// Dismiss removes the toast with the given ID
+func (s *ToastStack) Dismiss(id int) {
+	for i, t := range s.toasts {
+		if t.id == id {
+			s.toasts = append(s.toasts[:i], s.toasts[i+1:]...)
+			return
+		}
+	}
+}
+
+// Len returns the number of visible toasts
+func (s *ToastStack) Len() int {
+	return len(s.toasts)
+}
+
+// Texts returns the text of each visible toast, oldest first
+func (s *ToastStack) Texts() []string {
+	texts := make([]string, len(s.toasts))
+	for i, t := range s.toasts {
+		texts[i] = t.text
+	}
+	return texts
+}
+
+// Overlay draws the visible toasts right-aligned over the bottom lines of content
+func (s *ToastStack) Overlay(content string, width int, styles Styles) string {
+	if len(s.toasts) == 0 || width <= 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := len(s.toasts) - 1; i >= 0; i-- {
+		row := len(lines) - (len(s.toasts) - i)
+		if row < 0 {
+			break
+		}
+		lines[row] = lipgloss.PlaceHorizontal(width, lipgloss.Right, s.render(s.toasts[i], styles))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// render renders a single toast line
+func (s *ToastStack) render(t toast, styles Styles) string {
+	var icon string
+	style := styles.Toast
+
+	switch t.level {
+	case ToastSuccess:
+		icon = "✅"
+		style = style.Foreground(styles.SuccessStyle.GetForeground())
+	case ToastWarning:
+		icon = "⚠️ "
+		style = style.Foreground(styles.MessageTool.GetForeground())
+	case ToastError:
+		icon = "❌"
+		style = style.Foreground(styles.ErrorStyle.GetForeground())
+	default:
+		icon = "ℹ️ "
+	}
+
+	return style.Render(icon + " " + t.text)
+}
+
+// toastForUpdate derives a toast from a converted agent update, if the update is worth surfacing
+func toastForUpdate(msg tea.Msg) (ToastMsg, bool) {
+	switch m := msg.(type) {
+	case ToastMsg:
+		return m, true
+	case ServerStatusUpdateMsg:
+		if m.Error != "" {
+			return ToastMsg{Text: fmt.Sprintf("Server %s failed: %s", m.ServerName, m.Error), Level: ToastError}, true
+		}
+		if m.Connected {
+			return ToastMsg{Text: fmt.Sprintf("Server %s connected (%d tools)", m.ServerName, m.ToolCount), Level: ToastSuccess}, true
+		}
+		return ToastMsg{Text: fmt.Sprintf("Server %s disconnected", m.ServerName), Level: ToastWarning}, true
+	case ToolUpdateMsg:
+		if len(m.Added) == 0 {
+			return ToastMsg{}, false
+		}
+		text := "1 new tool available"
+		if len(m.Added) > 1 {
+			text = fmt.Sprintf("%d new tools available", len(m.Added))
+		}
+		if m.ServerName != "" {
+			text += " from " + m.ServerName
+		}
+		return ToastMsg{Text: text, Level: ToastInfo}, true
+	case ConfigReloadedMsg:
+		if m.Error != "" {
+			return ToastMsg{Text: "Config not reloaded: " + m.Error, Level: ToastError}, true
+		}
+		var parts []string
+		if len(m.Changes) > 0 {
+			parts = append(parts, "Config reloaded: "+strings.Join(m.Changes, ", "))
+		}
+		if len(m.Restart) > 0 {
+			parts = append(parts, "Restart to apply "+strings.Join(m.Restart, ", "))
+		}
+		if len(parts) == 0 {
+			return ToastMsg{}, false
+		}
+		level := ToastInfo
+		if len(m.Restart) > 0 {
+			level = ToastWarning
+		}
+		return ToastMsg{Text: strings.Join(parts, "; "), Level: level}, true
+	}
+	return ToastMsg{}, false
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToastStack_PushAndDismiss(t *testing.T) {
+	stack := NewToastStack()
+
+	cmd := stack.Push(ToastMsg{Text: "Server filesystem reconnected", Level: ToastSuccess})
+	require.NotNil(t, cmd, "Push should schedule an expiry")
+	assert.Equal(t, 1, stack.Len())
+
+	// Empty toasts are ignored
+	assert.Nil(t, stack.Push(ToastMsg{Text: "   "}))
+	assert.Equal(t, 1, stack.Len())
+
+	stack.Dismiss(stack.toasts[0].id)
+	assert.Equal(t, 0, stack.Len())
+}
+
+func TestToastStack_CapsVisibleToasts(t *testing.T) {
+	stack := NewToastStack()
+	for i := 0; i < maxVisibleToasts+2; i++ {
+		stack.Push(ToastMsg{Text: strings.Repeat("x", i+1)})
+	}
+
+	assert.Equal(t, maxVisibleToasts, stack.Len())
+	// Oldest toasts are dropped first
+	assert.Equal(t, strings.Repeat("x", 3), stack.Texts()[0])
+}
+
+func TestToastStack_Overlay(t *testing.T) {
+	stack := NewToastStack()
+	content := "line1\nline2\nline3"
+
+	// No toasts leaves content untouched
+	assert.Equal(t, content, stack.Overlay(content, 40, DefaultStyles()))
+
+	stack.Push(ToastMsg{Text: "3 new tools available"})
+	overlaid := stack.Overlay(content, 40, DefaultStyles())
+	lines := strings.Split(overlaid, "\n")
+
+	require.Len(t, lines, 3)
+	assert.Equal(t, "line1", lines[0])
+	assert.Contains(t, lines[2], "3 new tools available")
+}
+
+func TestToastForUpdate(t *testing.T) {
+	toast, ok := toastForUpdate(ServerStatusUpdateMsg{ServerName: "filesystem", Connected: true, ToolCount: 4})
+	require.True(t, ok)
+	assert.Equal(t, ToastSuccess, toast.Level)
+	assert.Contains(t, toast.Text, "filesystem connected")
+
+	toast, ok = toastForUpdate(ServerStatusUpdateMsg{ServerName: "memory", Error: "broken pipe"})
+	require.True(t, ok)
+	assert.Equal(t, ToastError, toast.Level)
+
+	toast, ok = toastForUpdate(ToolUpdateMsg{ServerName: "memory", Added: []string{"a", "b", "c"}})
+	require.True(t, ok)
+	assert.Equal(t, "3 new tools available from memory", toast.Text)
+
+	_, ok = toastForUpdate(ToolUpdateMsg{ServerName: "memory", Removed: []string{"a"}})
+	assert.False(t, ok, "Tool removals alone should not raise a toast")
+
+	toast, ok = toastForUpdate(ConfigReloadedMsg{Changes: []string{"temperature 0.7 → 0.3"}, Restart: []string{"model.name"}})
+	require.True(t, ok)
+	assert.Equal(t, ToastWarning, toast.Level)
+	assert.Equal(t, "Config reloaded: temperature 0.7 → 0.3; Restart to apply model.name", toast.Text)
+
+	toast, ok = toastForUpdate(ConfigReloadedMsg{Error: "model.temperature must be between 0 and 2"})
+	require.True(t, ok)
+	assert.Equal(t, ToastError, toast.Level)
+}
+
+func TestApplication_AgentUpdateShowsToast(t *testing.T) {
+	mockAgent := &MockAgent{}
+	mockAgent.On("GetMCPServers").Return([]ServerInfo{})
+	mockAgent.On("Subscribe").Return(events.NewBus(0).Subscribe())
+
+	app := &Application{
+		agent:      mockAgent,
+		serverView: NewServerViewWithAgent(DefaultStyles(), DefaultKeyMap(), mockAgent),
+		toasts:     NewToastStack(),
+	}
+
+	_, cmd := app.Update(agentEventMsg{event: events.NotificationEvent{Text: "context 90% full", Level: events.LevelWarning}})
+
+	assert.NotNil(t, cmd, "Application should keep listening for agent updates")
+	assert.Equal(t, []string{"context 90% full"}, app.toasts.Texts())
+
+	_, _ = app.Update(toastExpiredMsg{id: app.toasts.toasts[0].id})
+	assert.Equal(t, 0, app.toasts.Len())
+}
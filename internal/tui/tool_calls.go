@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// ToolCallOutcome is what one of the tool calls of a turn produced
+type ToolCallOutcome struct {
+	Output     string
+	Err        error
+	FollowUps  []model.FollowUp // Suggested next prompts for the result
+	FullResult string           // The result before it was summarized; empty when it wasn't
+	Duration   time.Duration    // Time taken to run the tool and process its result
+}
+
+// ToolBatchRunner is implemented by agents that run the tool calls of a turn
+// together, processing their results concurrently. The outcomes are in the
+// order of calls, and convContext is updated as if the calls were made one
+// at a time.
+type ToolBatchRunner interface {
+	ExecuteToolCallsWithContext(ctx context.Context, calls []model.ToolCall, convContext *model.ConversationContext) []ToolCallOutcome
+}
+
+// executeToolCalls runs the tool calls of a turn with the agent, together
+// when it can and otherwise one at a time
+func (v *ChatView) executeToolCalls(ctx context.Context, calls []model.ToolCall) []ToolCallOutcome {
+	if runner, ok := v.agent.(ToolBatchRunner); ok {
+		return runner.ExecuteToolCallsWithContext(ctx, calls, v.conversationContext)
+	}
+	outcomes := make([]ToolCallOutcome, len(calls))
+	for i, call := range calls {
+		started := time.Now()
+		output, err := v.agent.ExecuteToolUnifiedWithContext(ctx, call.Name, call.Arguments, v.conversationContext)
+		outcomes[i] = ToolCallOutcome{Output: output, Err: err, Duration: time.Since(started)}
+		if err == nil {
+			outcomes[i].FollowUps = v.conversationContext.FollowUps
+			outcomes[i].FullResult = v.conversationContext.FullResult
+		}
+	}
+	return outcomes
+}
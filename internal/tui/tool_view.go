@@ -9,6 +9,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
 )
 
 // ToolView represents the tools display view
@@ -340,11 +341,13 @@ func (tv *ToolView) executeSelectedTool() tea.Cmd {
 	}
 	
 	return func() tea.Msg {
-		ctx := context.Background()
+		// Choosing the tool approves the call
+		params := make(map[string]interface{})
+		ctx := model.WithApprovedCalls(context.Background(), []model.ToolCall{{Name: selectedTool.Name, Arguments: params}})
 		
 		// For now, execute with empty parameters using unified pathway
 		// In a more sophisticated implementation, we would prompt for parameters
-		result, err := tv.agent.ExecuteToolUnified(ctx, selectedTool.Name, make(map[string]interface{}), "Manual tool execution")
+		result, err := tv.agent.ExecuteToolUnified(ctx, selectedTool.Name, params, "Manual tool execution")
 		if err != nil {
 			return ToolExecutedUnifiedMsg{
 				ToolName: selectedTool.Name,
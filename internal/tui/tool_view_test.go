@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/events"
 	"github.com/danieleugenewilliams/othello-agent/internal/mcp"
 	"github.com/danieleugenewilliams/othello-agent/internal/model"
 	"github.com/stretchr/testify/assert"
@@ -26,12 +27,16 @@ func (m *MockAgentForTools) GetMCPTools(ctx context.Context) ([]Tool, error) {
 	return args.Get(0).([]Tool), args.Error(1)
 }
 
-func (m *MockAgentForTools) SubscribeToUpdates() <-chan interface{} {
+func (m *MockAgentForTools) GetUniversalIntegration() interface{} {
+	return nil
+}
+
+func (m *MockAgentForTools) Subscribe() *events.Subscription {
 	args := m.Called()
-	if ch := args.Get(0); ch != nil {
-		return ch.(<-chan interface{})
+	if sub := args.Get(0); sub != nil {
+		return sub.(*events.Subscription)
 	}
-	// Return a nil channel for tests that don't need it
+	// Return no subscription for tests that don't need it
 	return nil
 }
 
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// undoTimeout bounds the calls that reverse a request's tool calls
+const undoTimeout = 2 * time.Minute
+
+// turnCheckpoint is the state of the chat before a message was sent, which
+// /undo goes back to
+type turnCheckpoint struct {
+	index    int                // Index of the message sent
+	entities *model.EntityStore // Values extracted from tool results before it
+	undo     int                // Reversing calls recorded before it
+	calls    int                // Tool calls made before it
+}
+
+// undoneMsg reports the calls /undo made to reverse a request's tool calls
+type undoneMsg struct {
+	done string
+	err  error
+}
+
+// undoer is implemented by agents that can reverse tool calls
+type undoer interface {
+	Undo(ctx context.Context, calls []model.ToolCall) (string, error)
+}
+
+// checkpoint records the state /undo returns to before the message at index
+// is sent
+func (v *ChatView) checkpoint(index int) {
+	checkpoint := turnCheckpoint{index: index}
+	if c := v.conversationContext; c != nil {
+		checkpoint.entities = c.Entities.Clone()
+		checkpoint.undo = len(c.Undo)
+		checkpoint.calls = len(c.PreviousTools)
+	}
+	v.turns = append(v.turns, checkpoint)
+}
+
+// dropCheckpoints forgets the checkpoints of messages from index on, which
+// are no longer in the chat
+func (v *ChatView) dropCheckpoints(index int) {
+	for len(v.turns) > 0 && v.turns[len(v.turns)-1].index >= index {
+		v.turns = v.turns[:len(v.turns)-1]
+	}
+}
+
+// undo removes the latest message sent and everything after it, forgets the
+// values extracted from its tool results, and reverses the tool calls it made
+// that can be reversed
+func (v *ChatView) undo() tea.Cmd {
+	if v.waitingForResponse {
+		return toastCmd("Wait for the current response before undoing", ToastWarning)
+	}
+	if len(v.turns) == 0 {
+		return toastCmd("Nothing to undo", ToastInfo)
+	}
+	turn := v.turns[len(v.turns)-1]
+	v.truncateMessages(turn.index)
+	v.pendingPlan, v.pendingQuestion = nil, nil
+	v.turnText = ""
+
+	var calls []model.ToolCall
+	made := 0
+	if c := v.conversationContext; c != nil {
+		if turn.undo <= len(c.Undo) {
+			calls = append(calls, c.Undo[turn.undo:]...)
+			c.Undo = c.Undo[:turn.undo]
+		}
+		made = len(c.PreviousTools) - turn.calls
+		if made > 0 {
+			c.PreviousTools = c.PreviousTools[:turn.calls]
+		}
+		c.Entities = turn.entities
+	}
+
+	lines := []string{"Removed your last message and its response from the conversation."}
+	agent, ok := v.agent.(undoer)
+	if !ok {
+		calls = nil
+	}
+	if kept := made - len(calls); kept > 0 {
+		lines = append(lines, fmt.Sprintf("%d of its tool calls can't be reversed, so their changes remain.", kept))
+	}
+	if len(calls) > 0 {
+		lines = append(lines, fmt.Sprintf("Reversing %d tool calls...", len(calls)))
+	}
+	v.AddMessage(ChatMessage{
+		Role:      "assistant",
+		Content:   strings.Join(lines, "\n"),
+		Timestamp: time.Now().Format("15:04:05"),
+		Transient: true,
+	})
+	if len(calls) == 0 {
+		return nil
+	}
+	return tea.Batch(v.startWaiting(fmt.Sprintf("undoing %d tool calls", len(calls))), func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), undoTimeout)
+		defer cancel()
+		// Asking for /undo approves the calls that reverse the request
+		done, err := agent.Undo(model.WithApprovedCalls(ctx, calls), calls)
+		return undoneMsg{done: done, err: err}
+	})
+}
+
+// undone reports the reversed tool calls
+func (v *ChatView) undone(msg undoneMsg) tea.Cmd {
+	v.waitingForResponse = false
+	result := ChatMessage{
+		Role:      "assistant",
+		Timestamp: time.Now().Format("15:04:05"),
+		Transient: true,
+	}
+	if msg.done != "" {
+		result.Content = "Reversed:\n" + msg.done
+	}
+	if msg.err != nil {
+		result.Error = msg.err.Error()
+	}
+	v.AddMessage(result)
+	if msg.err != nil {
+		return toastCmd("Undo incomplete: "+msg.err.Error(), ToastError)
+	}
+	return toastCmd("Undone", ToastSuccess)
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// undoAgent records the calls it is asked to reverse
+type undoAgent struct {
+	MockAgentForChat
+	undone []model.ToolCall
+}
+
+func (a *undoAgent) Undo(ctx context.Context, calls []model.ToolCall) (string, error) {
+	a.undone = append(a.undone, calls...)
+	return "forget: Forgot memory #7", nil
+}
+
+func TestChatView_UndoRemovesLastTurn(t *testing.T) {
+	chatView, _, _ := newBranchTestView(t)
+
+	assert.Nil(t, chatView.handleCommand("/undo"), "Without tool calls there is nothing to reverse")
+	require.Len(t, chatView.messages, 3)
+	assert.Equal(t, []string{"first question", "reply to first question"}, contents(chatView)[:2])
+	assert.Contains(t, chatView.messages[2].Content, "Removed your last message")
+	assert.True(t, chatView.messages[2].Transient)
+
+	chatView.handleCommand("/undo")
+	assert.Contains(t, contents(chatView), "Removed your last message and its response from the conversation.")
+	assert.NotContains(t, contents(chatView), "first question")
+
+	cmd := chatView.handleCommand("/undo")
+	assert.Equal(t, "Nothing to undo", cmd().(ToastMsg).Text)
+}
+
+func TestChatView_UndoReversesToolCalls(t *testing.T) {
+	agent := &undoAgent{}
+	chatView := NewChatViewWithAgent(DefaultStyles(), DefaultKeyMap(), &MockModel{}, agent)
+	chatView.ClearMessages()
+	c := chatView.conversationContext
+	c.Entities.Record("memory_id", "mem-1", "remember")
+
+	// A turn that remembered a fact and ran a tool that can't be reversed
+	chatView.checkpoint(len(chatView.messages))
+	chatView.AddMessage(ChatMessage{Role: "user", Content: "remember that deploys are on Tuesdays"})
+	c.Entities.Record("memory_id", "mem-2", "remember")
+	c.Undo = append(c.Undo, model.ToolCall{Name: "forget", Arguments: map[string]interface{}{"id": 7}})
+	c.PreviousTools = append(c.PreviousTools, "remember", "run_command")
+	chatView.AddMessage(ChatMessage{Role: "assistant", Content: "Remembered #7"})
+
+	deliver(t, chatView, chatView.handleCommand("/undo"))
+	assert.Equal(t, []model.ToolCall{{Name: "forget", Arguments: map[string]interface{}{"id": 7}}}, agent.undone)
+	assert.Empty(t, c.Undo)
+	assert.Empty(t, c.PreviousTools)
+	latest, _ := chatView.conversationContext.Entities.Latest("memory_id")
+	assert.Equal(t, "mem-1", latest.Value, "Values from the undone turn are forgotten")
+	assert.False(t, chatView.waitingForResponse)
+
+	require.Len(t, chatView.messages, 2)
+	assert.Equal(t, "Removed your last message and its response from the conversation.\n1 of its tool calls can't be reversed, so their changes remain.\nReversing 1 tool calls...", chatView.messages[0].Content)
+	assert.Equal(t, "Reversed:\nforget: Forgot memory #7", chatView.messages[1].Content)
+}
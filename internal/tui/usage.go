@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+)
+
+// usageTotals counts the model requests made and the tokens they used
+type usageTotals struct {
+	requests         int
+	promptTokens     int
+	completionTokens int
+}
+
+// tokens returns the prompt and completion tokens together
+func (u usageTotals) tokens() int {
+	return u.promptTokens + u.completionTokens
+}
+
+// toolUsage counts a tool's calls and its share of the tokens of the
+// requests that called it
+type toolUsage struct {
+	calls            int
+	promptTokens     int
+	completionTokens int
+}
+
+// sessionUsage accumulates token use since the TUI started, for the current
+// conversation, and per tool
+type sessionUsage struct {
+	session      usageTotals
+	conversation usageTotals
+	tools        map[string]*toolUsage
+}
+
+// add counts a model response. The tokens of a response that called tools
+// are split evenly between the calls, as the conversation store does.
+func (u *sessionUsage) add(response *model.Response) {
+	for _, totals := range []*usageTotals{&u.session, &u.conversation} {
+		totals.requests++
+		totals.promptTokens += response.Usage.PromptTokens
+		totals.completionTokens += response.Usage.CompletionTokens
+	}
+
+	n := len(response.ToolCalls)
+	if n == 0 {
+		return
+	}
+	if u.tools == nil {
+		u.tools = make(map[string]*toolUsage)
+	}
+	for i, call := range response.ToolCalls {
+		tool := u.tools[call.Name]
+		if tool == nil {
+			tool = &toolUsage{}
+			u.tools[call.Name] = tool
+		}
+		tool.calls++
+		tool.promptTokens += response.Usage.PromptTokens / n
+		tool.completionTokens += response.Usage.CompletionTokens / n
+		if i == 0 {
+			tool.promptTokens += response.Usage.PromptTokens % n
+			tool.completionTokens += response.Usage.CompletionTokens % n
+		}
+	}
+}
+
+// SetPricing sets what the model charges, so /usage and the status bar can
+// show what requests cost
+func (v *ChatView) SetPricing(pricing model.Pricing) {
+	v.pricing = pricing
+}
+
+// UsageSummary returns the tokens used this session, with their cost for a
+// priced model, or "" before the first response
+func (v *ChatView) UsageSummary() string {
+	tokens := v.usage.session.tokens()
+	if tokens == 0 {
+		return ""
+	}
+	summary := formatTokens(tokens) + " tokens"
+	if !v.pricing.Free() {
+		summary += " · " + formatCost(v.pricing.Cost(v.usage.session.promptTokens, v.usage.session.completionTokens))
+	}
+	return summary
+}
+
+// showUsage handles /usage: it lists the tokens used this session and in the
+// current conversation, then the share of each tool
+func (v *ChatView) showUsage() {
+	v.AddMessage(ChatMessage{
+		Role:      "assistant",
+		Content:   v.describeUsage(),
+		Timestamp: time.Now().Format("15:04:05"),
+		Transient: true,
+	})
+}
+
+// describeUsage writes the report shown by /usage
+func (v *ChatView) describeUsage() string {
+	if v.usage.session.requests == 0 {
+		return "No model requests yet this session."
+	}
+
+	lines := []string{
+		"Token usage:",
+		"• This session: " + v.describeTotals(v.usage.session),
+		"• This conversation: " + v.describeTotals(v.usage.conversation),
+	}
+	if len(v.usage.tools) == 0 {
+		return strings.Join(lines, "\n")
+	}
+
+	names := make([]string, 0, len(v.usage.tools))
+	for name := range v.usage.tools {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := v.usage.tools[names[i]], v.usage.tools[names[j]]
+		if a.promptTokens+a.completionTokens != b.promptTokens+b.completionTokens {
+			return a.promptTokens+a.completionTokens > b.promptTokens+b.completionTokens
+		}
+		return names[i] < names[j]
+	})
+	lines = append(lines, "", "By tool (share of the requests that called it):")
+	for _, name := range names {
+		tool := v.usage.tools[name]
+		line := fmt.Sprintf("• %s: %d calls, %s tokens", name, tool.calls, formatTokens(tool.promptTokens+tool.completionTokens))
+		if !v.pricing.Free() {
+			line += ", " + formatCost(v.pricing.Cost(tool.promptTokens, tool.completionTokens))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeTotals writes one line of the /usage report
+func (v *ChatView) describeTotals(totals usageTotals) string {
+	line := fmt.Sprintf("%d requests, %s in + %s out = %s tokens", totals.requests,
+		formatTokens(totals.promptTokens), formatTokens(totals.completionTokens), formatTokens(totals.tokens()))
+	if !v.pricing.Free() {
+		line += ", " + formatCost(v.pricing.Cost(totals.promptTokens, totals.completionTokens))
+	}
+	return line
+}
+
+// formatTokens shortens large token counts, as in "12.3k"
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	}
+	return fmt.Sprint(n)
+}
+
+// formatCost writes a cost in dollars, with more places for small amounts
+func formatCost(cost float64) string {
+	if cost < 0.01 {
+		return fmt.Sprintf("$%.4f", cost)
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/danieleugenewilliams/othello-agent/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatView_UsageCommand(t *testing.T) {
+	chatView := NewChatView(DefaultStyles(), DefaultKeyMap(), &MockModel{})
+	chatView.ClearMessages()
+	typeAndSettle(t, chatView, "/usage")
+	assert.Equal(t, "No model requests yet this session.", lastContent(chatView))
+	assert.Equal(t, "", chatView.UsageSummary())
+
+	chatView.SetPricing(model.Pricing{InputPerMillion: 3, OutputPerMillion: 15})
+	toolCalls := []model.ToolCall{{Name: "search"}, {Name: "read_file"}, {Name: "search"}}
+	chatView.recordUsage(&model.Response{ToolCalls: toolCalls, Usage: model.Usage{PromptTokens: 3001, CompletionTokens: 300}})
+	chatView.ClearMessages()
+	chatView.recordUsage(&model.Response{Content: "Done", Usage: model.Usage{PromptTokens: 1000, CompletionTokens: 200}})
+
+	typeAndSettle(t, chatView, "/usage")
+	assert.Equal(t, "Token usage:\n"+
+		"• This session: 2 requests, 4.0k in + 500 out = 4.5k tokens, $0.02\n"+
+		"• This conversation: 1 requests, 1.0k in + 200 out = 1.2k tokens, $0.0060\n"+
+		"\n"+
+		"By tool (share of the requests that called it):\n"+
+		"• search: 2 calls, 2.2k tokens, $0.0090\n"+
+		"• read_file: 1 calls, 1.1k tokens, $0.0045", lastContent(chatView))
+	assert.Equal(t, "4.5k tokens · $0.02", chatView.UsageSummary())
+
+	chatView.SetPricing(model.Pricing{})
+	assert.Equal(t, "4.5k tokens", chatView.UsageSummary(), "Local models show no cost")
+}
+
+func TestApplication_StatusBarShowsUsage(t *testing.T) {
+	app := NewApplication(&MockModel{})
+	app.width = 120
+	assert.NotContains(t, app.renderStatusBar(), "tokens")
+
+	app.chatView.recordUsage(&model.Response{Usage: model.Usage{PromptTokens: 900, CompletionTokens: 40}})
+	assert.Contains(t, app.renderStatusBar(), "🪙 940 tokens")
+}
+
+func TestFormatTokensAndCost(t *testing.T) {
+	assert.Equal(t, "999", formatTokens(999))
+	assert.Equal(t, "12.3k", formatTokens(12345))
+	assert.Equal(t, "2.5M", formatTokens(2_500_000))
+	assert.Equal(t, "$0.0012", formatCost(0.00123))
+	assert.Equal(t, "$1.50", formatCost(1.5))
+}
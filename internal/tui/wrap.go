@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// wrapText wraps text to fit within width terminal cells. Lines are wrapped
+// at spaces, and words wider than width are broken, measuring wide
+// characters such as CJK and emoji by the cells they take and skipping ANSI
+// escape sequences. Line breaks in text are kept, and fenced code blocks and
+// table rows are left as they are, since wrapping them breaks their layout.
+func wrapText(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(ansi.Strip(line))
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case strings.HasPrefix(trimmed, "|"):
+		case ansi.StringWidth(line) > width:
+			lines[i] = strings.TrimRight(ansi.Wrap(line, width, ""), " ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{
+			name:  "wraps at spaces",
+			text:  "the backup runs every night at two",
+			width: 12,
+			want:  "the backup\nruns every\nnight at two",
+		},
+		{
+			name:  "keeps line breaks and indentation",
+			text:  "Steps:\n  1. open\n\n  2. save",
+			width: 20,
+			want:  "Steps:\n  1. open\n\n  2. save",
+		},
+		{
+			name:  "measures wide characters by cells",
+			text:  "日本語のテキスト",
+			width: 6,
+			want:  "日本語\nのテキ\nスト",
+		},
+		{
+			name:  "breaks words wider than the line",
+			text:  "see /var/lib/othello/conversations",
+			width: 10,
+			want:  "see\n/var/lib/o\nthello/con\nversations",
+		},
+		{
+			name:  "leaves fenced code alone",
+			text:  "Run:\n```sh\nothello mcp add notes --command notes-server --args serve\n```\nthen restart it",
+			width: 12,
+			want:  "Run:\n```sh\nothello mcp add notes --command notes-server --args serve\n```\nthen restart\nit",
+		},
+		{
+			name:  "leaves table rows alone",
+			text:  "| tool | calls | errors |\n|------|-------|--------|",
+			width: 10,
+			want:  "| tool | calls | errors |\n|------|-------|--------|",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, wrapText(tt.text, tt.width))
+		})
+	}
+}
+
+func TestWrapText_SkipsEscapeSequences(t *testing.T) {
+	text := "\x1b[1mbold words\x1b[0m and plain words"
+	wrapped := wrapText(text, 10)
+
+	assert.Equal(t, "bold words\nand plain\nwords", ansi.Strip(wrapped))
+	for _, line := range strings.Split(wrapped, "\n") {
+		assert.LessOrEqual(t, ansi.StringWidth(line), 10)
+	}
+}